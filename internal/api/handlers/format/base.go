@@ -3,16 +3,22 @@ package format
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/files"
 	"github.com/nghyane/llm-mux/internal/interfaces"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
 	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
 )
 
 type ErrorResponse struct {
@@ -23,6 +29,7 @@ type ErrorDetail struct {
 	Message string `json:"message"`
 	Type    string `json:"type"`
 	Code    string `json:"code,omitempty"`
+	Detail  string `json:"detail,omitempty"`
 }
 
 type BaseAPIHandler struct {
@@ -30,18 +37,39 @@ type BaseAPIHandler struct {
 	Cfg                   *config.SDKConfig
 	Routing               *config.RoutingConfig
 	OpenAICompatProviders []string
+	// Files maps uploaded file IDs to provider files; nil disables /v1/files.
+	Files *files.Registry
+
+	responseCacheMu  sync.Mutex // guards the response cache and idempotency store
+	responseCacheCfg config.ResponseCacheConfig
+	responses        *responseCache
+	idempotencyCfg   config.IdempotencyConfig
+	idempotency      *responseCache
+
+	toolRounds        *toolRoundStore
+	thoughtSignatures *stream.ThoughtSignatureStore
+	inflight          inflightRequests
 }
 
 func NewBaseAPIHandlers(cfg *config.SDKConfig, routing *config.RoutingConfig, authManager *provider.Manager, openAICompatProviders []string) *BaseAPIHandler {
-	return &BaseAPIHandler{
+	h := &BaseAPIHandler{
 		Cfg:                   cfg,
 		Routing:               routing,
 		AuthManager:           authManager,
 		OpenAICompatProviders: openAICompatProviders,
+		toolRounds:            newToolRoundStore(),
+		thoughtSignatures:     stream.NewThoughtSignatureStore(),
 	}
+	h.setResponseCache(cfg)
+	h.setIdempotencyStore(cfg)
+	return h
 }
 
-func (h *BaseAPIHandler) UpdateClients(cfg *config.SDKConfig) { h.Cfg = cfg }
+func (h *BaseAPIHandler) UpdateClients(cfg *config.SDKConfig) {
+	h.Cfg = cfg
+	h.setResponseCache(cfg)
+	h.setIdempotencyStore(cfg)
+}
 
 func (h *BaseAPIHandler) UpdateRouting(routing *config.RoutingConfig) { h.Routing = routing }
 
@@ -57,6 +85,85 @@ func (h *BaseAPIHandler) Models() []map[string]any {
 	return registry.GetGlobalRegistry().GetAvailableModels("openai")
 }
 
+// AppendModelAliases adds an entry for each configured alias whose target is
+// in models, copied from the target with its id replaced and "alias_for" set.
+func (h *BaseAPIHandler) AppendModelAliases(models []map[string]any) []map[string]any {
+	aliases := h.Routing.ModelAliases()
+	if len(aliases) == 0 {
+		return models
+	}
+	byID := make(map[string]map[string]any, len(models))
+	for _, model := range models {
+		if id, ok := model["id"].(string); ok {
+			byID[id] = model
+		}
+	}
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		if _, listed := byID[alias]; !listed {
+			names = append(names, alias)
+		}
+	}
+	sort.Strings(names)
+	for _, alias := range names {
+		target := byID[aliases[alias]]
+		if target == nil {
+			target = byID[util.NormalizeIncomingModelID(aliases[alias])]
+		}
+		if target == nil {
+			continue
+		}
+		entry := make(map[string]any, len(target)+1)
+		for key, value := range target {
+			entry[key] = value
+		}
+		entry["id"] = alias
+		entry["alias_for"] = target["id"]
+		models = append(models, entry)
+	}
+	return models
+}
+
+// requestedMinTokens returns the min_tokens of a request, top level or in
+// Ollama options, or 0.
+func requestedMinTokens(rawJSON []byte) int {
+	for _, path := range []string{"min_tokens", "options.min_tokens"} {
+		if v := gjson.GetBytes(rawJSON, path); v.Exists() {
+			return int(v.Int())
+		}
+	}
+	return 0
+}
+
+// requestedCachedContent returns the Gemini cachedContent name a request
+// reuses, in native, CLI-envelope or OpenAI-compatible form, or "".
+func requestedCachedContent(rawJSON []byte) string {
+	for _, path := range []string{"cachedContent", "request.cachedContent", "cached_content", "extra_body.google.cached_content"} {
+		if v := gjson.GetBytes(rawJSON, path).String(); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ModelListOptions reads the optional model list views from the query:
+// ?availability=true annotates models with live availability and
+// ?available_only=true hides models that cannot be served right now.
+func ModelListOptions(c *gin.Context) registry.ModelListOptions {
+	return registry.ModelListOptions{
+		Availability:  queryBool(c, "availability"),
+		AvailableOnly: queryBool(c, "available_only"),
+	}
+}
+
+func queryBool(c *gin.Context, key string) bool {
+	switch strings.ToLower(strings.TrimSpace(c.Query(key))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
 func (h *BaseAPIHandler) GetAlt(c *gin.Context) string {
 	alt, hasAlt := c.GetQuery("alt")
 	if !hasAlt {
@@ -118,6 +225,12 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 func buildRequestOpts(normalizedModel string, rawJSON []byte, metadata map[string]any, handlerType string, alt string, stream bool) (provider.Request, provider.Options) {
 	payload := cloneBytes(rawJSON)
 	meta := cloneMetadata(metadata)
+	if name := requestedCachedContent(rawJSON); name != "" {
+		if meta == nil {
+			meta = make(map[string]any, 1)
+		}
+		meta[provider.CachedContentMetadataKey] = name
+	}
 
 	sourceFormat := provider.Format(handlerType)
 
@@ -154,104 +267,291 @@ func extractErrorDetails(err error) (int, http.Header) {
 }
 
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	execute := func(ctx context.Context) ([]byte, *interfaces.ErrorMessage) {
+		return h.executeWithAuthManager(ctx, handlerType, modelName, rawJSON, alt)
+	}
+	if key, ok := h.idempotencyKey(ctx, handlerType); ok {
+		return h.idempotent(ctx, key, execute)
+	}
+	key, ok := h.coalesceKey(ctx, handlerType, modelName, rawJSON, alt)
+	if !ok {
+		return execute(ctx)
+	}
+	return h.coalesce(ctx, key, headerCoalesced, execute)
+}
+
+func (h *BaseAPIHandler) executeWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	ctx, rawJSON, errMsg := h.resolveFiles(ctx, rawJSON)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	providers, normalizedModel, metadata, errMsg := h.resolveOrSubstitute(ctx, modelName, rawJSON)
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	toolRounds, stop := h.checkToolLoop(ctx, handlerType, rawJSON)
+	if stop {
+		resp, err := h.toolLoopStopResponse(handlerType, modelName)
+		if err != nil {
+			return nil, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: err}
+		}
+		h.trackToolRounds(handlerType, toolRounds, resp)
+		return resp, nil
+	}
+	chaos, fault := h.chaosFault(ctx)
+	if errMsg = h.injectChaos(ctx, chaos, fault); errMsg != nil {
+		return nil, errMsg
+	}
+	ctx = provider.WithRequestAnalytics(ctx, modelName)
+	cacheKey, cached, cacheable := h.cachedResponse(ctx, handlerType, modelName, rawJSON, alt, false)
+	if cached != nil {
+		return bytes.Clone(cached[0]), nil
+	}
+	ctx, dbg := h.startRequestDebug(ctx)
+	ctx, sigs := h.startThoughtSignatures(ctx, handlerType, rawJSON)
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, false)
+	dbg.attach(&req, &opts)
+	attachThoughtSignatures(sigs, &req, &opts)
 	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
 	if err == nil {
+		dbg.writeHeaders(ctx)
+		if cacheable {
+			h.storeResponse(cacheKey, [][]byte{bytes.Clone(resp.Payload)})
+		}
+		h.trackToolRounds(handlerType, toolRounds, resp.Payload)
+		commitThoughtSignatures(sigs, handlerType, resp.Payload)
 		return resp.Payload, nil
 	}
 
-	fallbacks := h.getFallbackChain(normalizedModel)
+	var fallbacks []string
+	if fallbackApplies(ctx, err) {
+		fallbacks = h.getFallbackChain(normalizedModel)
+	}
 	for _, fallbackModel := range fallbacks {
-		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel)
+		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(ctx, fallbackModel)
 		if len(fbProviders) == 0 {
 			continue
 		}
 		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, fbMetadata, handlerType, alt, false)
+		dbg.attach(&fbReq, &fbOpts)
+		attachThoughtSignatures(sigs, &fbReq, &fbOpts)
 		fbResp, fbErr := h.AuthManager.Execute(ctx, fbProviders, fbReq, fbOpts)
 		if fbErr == nil {
+			annotateFallback(ctx, modelName, fallbackModel)
+			dbg.writeHeaders(ctx)
+			if cacheable {
+				h.storeResponse(cacheKey, [][]byte{bytes.Clone(fbResp.Payload)})
+			}
+			h.trackToolRounds(handlerType, toolRounds, fbResp.Payload)
+			commitThoughtSignatures(sigs, handlerType, fbResp.Payload)
 			return fbResp.Payload, nil
 		}
+		if !fallbackApplies(ctx, fbErr) {
+			break
+		}
+	}
+
+	if provider.NoAccountAvailable(err) {
+		if sub, ok := h.capabilityFallback(ctx, modelName, rawJSON); ok && sub.normalizedModel != normalizedModel {
+			subReq, subOpts := buildRequestOpts(sub.normalizedModel, rawJSON, sub.metadata, handlerType, alt, false)
+			dbg.attach(&subReq, &subOpts)
+			attachThoughtSignatures(sigs, &subReq, &subOpts)
+			if subResp, subErr := h.AuthManager.Execute(ctx, sub.providers, subReq, subOpts); subErr == nil {
+				annotateSubstitution(ctx, modelName, sub.model)
+				dbg.writeHeaders(ctx)
+				h.trackToolRounds(handlerType, toolRounds, subResp.Payload)
+				commitThoughtSignatures(sigs, handlerType, subResp.Payload)
+				return subResp.Payload, nil
+			}
+		}
 	}
 
-	status, addon := extractErrorDetails(err)
-	return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	return nil, withContextLength(h.newErrorMessage(err, providers, normalizedModel), handlerType, normalizedModel, rawJSON)
 }
 
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	ctx, rawJSON, errMsg := h.resolveFiles(ctx, rawJSON)
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	ctx, dbg := h.startRequestDebug(ctx)
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, false)
+	dbg.attach(&req, &opts)
 	resp, err := h.AuthManager.ExecuteCount(ctx, providers, req, opts)
 	if err != nil {
-		status, addon := extractErrorDetails(err)
-		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+		return nil, h.newErrorMessage(err, providers, normalizedModel)
+	}
+	dbg.writeHeaders(ctx)
+	if source, ok := resp.Metadata[provider.TokenCountSourceKey].(string); ok && source != "" {
+		if c, okGin := ctx.Value(ctxKeyGin).(*gin.Context); okGin {
+			c.Header(provider.HeaderTokenCount, source)
+		}
 	}
 	return resp.Payload, nil
 }
 
+// ExecuteEmbedWithAuthManager routes an OpenAI embeddings request to a provider
+// that supports embeddings and returns the OpenAI embeddings response.
+func (h *BaseAPIHandler) ExecuteEmbedWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	return h.executeUnaryWithAuthManager(ctx, handlerType, modelName, rawJSON, h.AuthManager.ExecuteEmbed)
+}
+
+// ExecuteRerankWithAuthManager routes a rerank request to a provider with
+// native rerank support and returns the rerank response.
+func (h *BaseAPIHandler) ExecuteRerankWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	return h.executeUnaryWithAuthManager(ctx, handlerType, modelName, rawJSON, h.AuthManager.ExecuteRerank)
+}
+
+func (h *BaseAPIHandler) executeUnaryWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, execute func(context.Context, []string, provider.Request, provider.Options) (provider.Response, error)) ([]byte, *interfaces.ErrorMessage) {
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	ctx, dbg := h.startRequestDebug(ctx)
+	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, "", false)
+	dbg.attach(&req, &opts)
+	resp, err := execute(ctx, providers, req, opts)
+	if err != nil {
+		return nil, h.newErrorMessage(err, providers, normalizedModel)
+	}
+	dbg.writeHeaders(ctx)
+	return resp.Payload, nil
+}
+
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	ctx, rawJSON, errMsg := h.resolveFiles(ctx, rawJSON)
+	var providers []string
+	var normalizedModel string
+	var metadata map[string]any
+	if errMsg == nil {
+		providers, normalizedModel, metadata, errMsg = h.resolveOrSubstitute(ctx, modelName, rawJSON)
+	}
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
 		close(errChan)
 		return nil, errChan
 	}
+	toolRounds, stop := h.checkToolLoop(ctx, handlerType, rawJSON)
+	if stop {
+		return h.toolLoopStopStream(handlerType, modelName)
+	}
+	chaos, fault := h.chaosFault(ctx)
+	if data, errs, injected := h.injectChaosStream(ctx, chaos, fault, handlerType, modelName); injected {
+		return data, errs
+	}
+	observe := h.toolRoundsObserver(handlerType, toolRounds)
+	ctx = provider.WithRequestAnalytics(ctx, modelName)
+	cacheKey, cached, cacheable := h.cachedResponse(ctx, handlerType, modelName, rawJSON, alt, true)
+	if cached != nil {
+		return replayStream(cached)
+	}
+	var record func([][]byte)
+	if cacheable {
+		record = func(chunks [][]byte) { h.storeResponse(cacheKey, chunks) }
+	}
+	ctx = stream.WithMinTokens(ctx, requestedMinTokens(rawJSON))
+	ctx = stream.WithHiddenThoughts(ctx, rawJSON)
+	ctx = stream.WithUsageRequest(ctx, rawJSON)
+	ctx, dbg := h.startRequestDebug(ctx)
+	ctx, sigs := h.startThoughtSignatures(ctx, handlerType, rawJSON)
+	observe = chainObservers(observe, thoughtSignaturesObserver(sigs, handlerType))
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, true)
+	dbg.attach(&req, &opts)
+	attachThoughtSignatures(sigs, &req, &opts)
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 	if err == nil {
-		return h.wrapStreamChannel(ctx, chunks)
+		dbg.writeHeaders(ctx)
+		return h.wrapStreamChannel(ctx, chunks, record, observe)
 	}
 
-	fallbacks := h.getFallbackChain(normalizedModel)
+	var fallbacks []string
+	if fallbackApplies(ctx, err) {
+		fallbacks = h.getFallbackChain(normalizedModel)
+	}
 	for _, fallbackModel := range fallbacks {
-		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel)
+		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(ctx, fallbackModel)
 		if len(fbProviders) == 0 {
 			continue
 		}
 		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, fbMetadata, handlerType, alt, true)
+		dbg.attach(&fbReq, &fbOpts)
+		attachThoughtSignatures(sigs, &fbReq, &fbOpts)
 		fbChunks, fbErr := h.AuthManager.ExecuteStream(ctx, fbProviders, fbReq, fbOpts)
 		if fbErr == nil {
-			return h.wrapStreamChannel(ctx, fbChunks)
+			annotateFallback(ctx, modelName, fallbackModel)
+			dbg.writeHeaders(ctx)
+			return h.wrapStreamChannel(ctx, fbChunks, record, observe)
+		}
+		if !fallbackApplies(ctx, fbErr) {
+			break
+		}
+	}
+
+	if provider.NoAccountAvailable(err) {
+		if sub, ok := h.capabilityFallback(ctx, modelName, rawJSON); ok && sub.normalizedModel != normalizedModel {
+			subReq, subOpts := buildRequestOpts(sub.normalizedModel, rawJSON, sub.metadata, handlerType, alt, true)
+			dbg.attach(&subReq, &subOpts)
+			attachThoughtSignatures(sigs, &subReq, &subOpts)
+			if subChunks, subErr := h.AuthManager.ExecuteStream(ctx, sub.providers, subReq, subOpts); subErr == nil {
+				annotateSubstitution(ctx, modelName, sub.model)
+				dbg.writeHeaders(ctx)
+				return h.wrapStreamChannel(ctx, subChunks, nil, observe)
+			}
 		}
 	}
 
 	errChan := make(chan *interfaces.ErrorMessage, 1)
-	status, addon := extractErrorDetails(err)
-	errChan <- &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	errChan <- withContextLength(h.newErrorMessage(err, providers, normalizedModel), handlerType, normalizedModel, rawJSON)
 	close(errChan)
 	return nil, errChan
 }
 
-func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan provider.StreamChunk) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+// wrapStreamChannel forwards upstream chunks to the handler. When record is
+// set, a stream that completes without error is passed to it chunk by chunk,
+// unless it grew beyond maxCachedResponseBytes. observe, when set, sees every
+// chunk as it is forwarded.
+func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan provider.StreamChunk, record func([][]byte), observe func([]byte)) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
 	dataChan := make(chan []byte, 128)
 	errChan := make(chan *interfaces.ErrorMessage, 1)
 	go func() {
 		defer close(dataChan)
 		defer close(errChan)
+		var recorded [][]byte
+		recordedBytes := 0
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case chunk, ok := <-chunks:
 				if !ok {
+					if record != nil {
+						record(recorded)
+					}
 					return
 				}
 				if chunk.Err != nil {
-					status, addon := extractErrorDetails(chunk.Err)
 					select {
-					case errChan <- &interfaces.ErrorMessage{StatusCode: status, Error: chunk.Err, Addon: addon}:
+					case errChan <- h.newErrorMessage(chunk.Err, nil, ""):
 					case <-ctx.Done():
 					}
 					return
 				}
 				if len(chunk.Payload) > 0 {
+					if observe != nil {
+						observe(chunk.Payload)
+					}
+					if record != nil {
+						if recordedBytes += len(chunk.Payload); recordedBytes > maxCachedResponseBytes {
+							record, recorded = nil, nil
+						} else {
+							recorded = append(recorded, bytes.Clone(chunk.Payload))
+						}
+					}
 					select {
 					case dataChan <- chunk.Payload:
 					case <-ctx.Done():
@@ -264,8 +564,14 @@ func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan pr
 	return dataChan, errChan
 }
 
-func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
-	resolvedModelName := util.ResolveAutoModel(modelName)
+func (h *BaseAPIHandler) getRequestDetails(ctx context.Context, modelName string) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
+	if modelName, err = h.requestedModel(modelName); err != nil {
+		return nil, "", nil, err
+	}
+
+	// Configured aliases are resolved first so they may name an auto model,
+	// a provider-prefixed ID or a canonical family.
+	resolvedModelName := util.ResolveAutoModel(h.Routing.ResolveModelAlias(modelName))
 	specifiedProvider := util.ExtractProviderFromPrefixedModelID(resolvedModelName)
 	cleanModelName := util.NormalizeIncomingModelID(resolvedModelName)
 
@@ -288,11 +594,67 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	}
 
 	if len(providers) == 0 {
-		return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("unknown provider for model %s", modelName)}
+		return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("unknown model %q: no provider serves it; GET /v1/models lists the available models", modelName)}
+	}
+
+	if scope := h.apiKeyScope(ctx); scope != nil {
+		var canonicalID string
+		if info := registry.GetGlobalRegistry().GetModelInfo(normalizedModel); info != nil {
+			canonicalID = info.CanonicalID
+		}
+		if !scope.AllowsModel(modelName, cleanModelName, normalizedModel, canonicalID) {
+			return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("API key is not allowed to use model %s", modelName)}
+		}
+		if providers = scope.FilterProviders(providers); len(providers) == 0 {
+			return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("API key is not allowed to use any provider serving model %s", modelName)}
+		}
 	}
 	return providers, normalizedModel, metadata, nil
 }
 
+// requestedModel trims modelName and substitutes routing.default-model when
+// it is empty. Without a default, an empty or whitespace-only name is a 400.
+func (h *BaseAPIHandler) requestedModel(modelName string) (string, *interfaces.ErrorMessage) {
+	trimmed := strings.TrimSpace(modelName)
+	if trimmed != "" {
+		return trimmed, nil
+	}
+	if h.Routing != nil && h.Routing.DefaultModel != "" {
+		return h.Routing.DefaultModel, nil
+	}
+	msg := "model is required"
+	if modelName != "" {
+		msg = "model is required: the model field contains only whitespace"
+	}
+	return "", &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: errors.New(msg)}
+}
+
+// apiKeyScope returns the scope configured for the request's authenticated API
+// key, or nil when the key is unrestricted.
+func (h *BaseAPIHandler) apiKeyScope(ctx context.Context) *config.APIKeyScope {
+	if ctx == nil || h.Cfg == nil || len(h.Cfg.APIKeyScopes) == 0 {
+		return nil
+	}
+	c, ok := ctx.Value(ctxKeyGin).(*gin.Context)
+	if !ok || c == nil {
+		return nil
+	}
+	key, _ := c.Get("apiKey")
+	principal, _ := key.(string)
+	return h.Cfg.APIKeyScopeFor(principal)
+}
+
+// AuthorizeModel checks modelName against the API key scope of the request in
+// c, returning the 403 error to send when the key may not use it. Callers that
+// run requests outside the request context, like batches, check up front.
+func (h *BaseAPIHandler) AuthorizeModel(c *gin.Context, modelName string) *interfaces.ErrorMessage {
+	ctx := context.WithValue(c.Request.Context(), ctxKeyGin, c)
+	if _, _, _, errMsg := h.getRequestDetails(ctx, modelName); errMsg != nil && errMsg.StatusCode == http.StatusForbidden {
+		return errMsg
+	}
+	return nil
+}
+
 func (h *BaseAPIHandler) parseDynamicModel(modelName string) (providerName, model string, isDynamic bool) {
 	if parts := strings.SplitN(modelName, "://", 2); len(parts) == 2 {
 		for _, pName := range h.OpenAICompatProviders {
@@ -338,12 +700,11 @@ func (h *BaseAPIHandler) WriteErrorResponse(c *gin.Context, msg *interfaces.Erro
 			}
 		}
 	}
-	c.Status(status)
-	if msg != nil && msg.Error != nil {
-		_, _ = c.Writer.Write([]byte(msg.Error.Error()))
-	} else {
-		_, _ = c.Writer.Write([]byte(http.StatusText(status)))
+	if msg == nil || msg.Addon.Get("Content-Type") == "" {
+		c.Writer.Header().Set("Content-Type", "application/json")
 	}
+	c.Status(status)
+	_, _ = c.Writer.Write(ErrorBody(msg))
 }
 
 func (h *BaseAPIHandler) LoggingAPIResponseError(ctx context.Context, err *interfaces.ErrorMessage) {
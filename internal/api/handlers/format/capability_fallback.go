@@ -0,0 +1,151 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// headerCapabilityFallback turns capability fallback on or off for a
+	// single request, overriding routing.capability-fallback.
+	headerCapabilityFallback = "X-LLM-Mux-Capability-Fallback"
+	// headerSubstitutedModel names the model that served the request in
+	// place of the requested one.
+	headerSubstitutedModel = "X-LLM-Mux-Substituted-Model"
+)
+
+// imagePartTypes are the content part types that carry an image in the
+// OpenAI, Responses and Claude formats.
+var imagePartTypes = map[string]struct{}{"image_url": {}, "input_image": {}, "image": {}}
+
+// capabilityFallbackEnabled reports whether the request may be served by a
+// substitute model, from its header or else the routing config.
+func (h *BaseAPIHandler) capabilityFallbackEnabled(c *gin.Context) bool {
+	if c != nil && c.Request != nil {
+		switch strings.ToLower(strings.TrimSpace(c.GetHeader(headerCapabilityFallback))) {
+		case "1", "true", "yes", "on":
+			return true
+		case "0", "false", "no", "off":
+			return false
+		}
+	}
+	return h.Routing != nil && h.Routing.CapabilityFallback
+}
+
+// capabilitySubstitute is a model standing in for one that cannot be served.
+type capabilitySubstitute struct {
+	model           string
+	providers       []string
+	normalizedModel string
+	metadata        map[string]any
+}
+
+// capabilityFallback finds the closest available model that meets the needs
+// of rawJSON when capability fallback is enabled for the request. ok is false
+// when it is disabled or no model qualifies.
+func (h *BaseAPIHandler) capabilityFallback(ctx context.Context, modelName string, rawJSON []byte) (sub capabilitySubstitute, ok bool) {
+	c, _ := ctx.Value(ctxKeyGin).(*gin.Context)
+	if !h.capabilityFallbackEnabled(c) {
+		return sub, false
+	}
+	requested := util.NormalizeIncomingModelID(h.Routing.ResolveModelAlias(modelName))
+	sub.model = registry.GetGlobalRegistry().ClosestAvailableModel(requested, requestCapabilities(rawJSON))
+	if sub.model == "" {
+		return sub, false
+	}
+	var errMsg *interfaces.ErrorMessage
+	sub.providers, sub.normalizedModel, sub.metadata, errMsg = h.getRequestDetails(ctx, sub.model)
+	if errMsg != nil {
+		return sub, false
+	}
+	return sub, true
+}
+
+// resolveOrSubstitute returns the request details of modelName, or those of a
+// capability substitute when modelName resolves to no provider.
+func (h *BaseAPIHandler) resolveOrSubstitute(ctx context.Context, modelName string, rawJSON []byte) ([]string, string, map[string]any, *interfaces.ErrorMessage) {
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(ctx, modelName)
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest || strings.TrimSpace(modelName) == "" {
+		return providers, normalizedModel, metadata, errMsg
+	}
+	sub, ok := h.capabilityFallback(ctx, modelName, rawJSON)
+	if !ok {
+		return nil, "", nil, errMsg
+	}
+	annotateSubstitution(ctx, modelName, sub.model)
+	return sub.providers, sub.normalizedModel, sub.metadata, nil
+}
+
+// annotateSubstitution tells the client which model served the request.
+func annotateSubstitution(ctx context.Context, requested, substitute string) {
+	log.Infof("capability fallback: serving %s with %s", requested, substitute)
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Header(headerSubstitutedModel, substitute)
+	}
+}
+
+// requestCapabilities derives what the request body needs from a model, in
+// any of the supported request formats.
+func requestCapabilities(rawJSON []byte) registry.CapabilityRequirements {
+	root := gjson.ParseBytes(rawJSON)
+	var need registry.CapabilityRequirements
+	need.Tools = len(root.Get("tools").Array()) > 0 || len(root.Get("functions").Array()) > 0
+
+	switch effort := strings.ToLower(root.Get("reasoning_effort").String()); {
+	case effort != "" && effort != "none":
+		need.Thinking = true
+	case root.Get("reasoning.effort").Exists() && !strings.EqualFold(root.Get("reasoning.effort").String(), "none"):
+		need.Thinking = true
+	case root.Get("thinking.type").String() == "enabled":
+		need.Thinking = true
+	case root.Get("generationConfig.thinkingConfig.thinkingBudget").Int() > 0,
+		root.Get("generationConfig.thinkingConfig.includeThoughts").Bool():
+		need.Thinking = true
+	}
+
+	for _, field := range []string{"messages", "input", "contents"} {
+		if v := root.Get(field); v.IsArray() && containsImage(v) {
+			need.Vision = true
+			break
+		}
+	}
+	return need
+}
+
+// containsImage reports whether v holds an image content part or inline data
+// with an image MIME type at any depth.
+func containsImage(v gjson.Result) bool {
+	if v.IsArray() {
+		for _, item := range v.Array() {
+			if containsImage(item) {
+				return true
+			}
+		}
+		return false
+	}
+	if !v.IsObject() {
+		return false
+	}
+	if _, ok := imagePartTypes[v.Get("type").String()]; ok {
+		return true
+	}
+	for _, path := range []string{"mimeType", "mime_type"} {
+		if strings.HasPrefix(v.Get(path).String(), "image/") {
+			return true
+		}
+	}
+	found := false
+	v.ForEach(func(_, child gjson.Result) bool {
+		found = containsImage(child)
+		return !found
+	})
+	return found
+}
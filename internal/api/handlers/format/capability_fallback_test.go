@@ -0,0 +1,59 @@
+package format
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestRequestCapabilities(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want registry.CapabilityRequirements
+	}{
+		"plain":         {`{"messages":[{"role":"user","content":"hi"}]}`, registry.CapabilityRequirements{}},
+		"openai tools":  {`{"tools":[{"type":"function","function":{"name":"f"}}],"messages":[]}`, registry.CapabilityRequirements{Tools: true}},
+		"openai image":  {`{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"https://x/y.png"}}]}]}`, registry.CapabilityRequirements{Vision: true}},
+		"claude image":  {`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64"}}]}]}`, registry.CapabilityRequirements{Vision: true}},
+		"gemini image":  {`{"contents":[{"parts":[{"inlineData":{"mimeType":"image/png","data":"AA"}}]}]}`, registry.CapabilityRequirements{Vision: true}},
+		"gemini audio":  {`{"contents":[{"parts":[{"inlineData":{"mimeType":"audio/wav","data":"AA"}}]}]}`, registry.CapabilityRequirements{}},
+		"effort":        {`{"reasoning_effort":"high"}`, registry.CapabilityRequirements{Thinking: true}},
+		"effort none":   {`{"reasoning_effort":"none"}`, registry.CapabilityRequirements{}},
+		"claude think":  {`{"thinking":{"type":"enabled","budget_tokens":2048}}`, registry.CapabilityRequirements{Thinking: true}},
+		"gemini budget": {`{"generationConfig":{"thinkingConfig":{"thinkingBudget":1024}}}`, registry.CapabilityRequirements{Thinking: true}},
+	}
+	for name, tt := range tests {
+		if got := requestCapabilities([]byte(tt.body)); got != tt.want {
+			t.Errorf("%s: requestCapabilities() = %+v, want %+v", name, got, tt.want)
+		}
+	}
+}
+
+func TestCapabilityFallbackEnabled(t *testing.T) {
+	newContext := func(header string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		if header != "" {
+			c.Request.Header.Set(headerCapabilityFallback, header)
+		}
+		return c
+	}
+
+	off := &BaseAPIHandler{Routing: &config.RoutingConfig{}}
+	on := &BaseAPIHandler{Routing: &config.RoutingConfig{CapabilityFallback: true}}
+	if off.capabilityFallbackEnabled(newContext("")) {
+		t.Error("enabled without config or header")
+	}
+	if !off.capabilityFallbackEnabled(newContext("true")) {
+		t.Error("header did not opt in")
+	}
+	if !on.capabilityFallbackEnabled(newContext("")) {
+		t.Error("config did not enable")
+	}
+	if on.capabilityFallbackEnabled(newContext("off")) {
+		t.Error("header did not opt out")
+	}
+}
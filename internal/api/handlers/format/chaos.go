@@ -0,0 +1,162 @@
+package format
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/metrics"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+)
+
+// headerChaos names the fault for one request ("off" to skip injection) and,
+// on responses, reports the fault that was injected.
+const headerChaos = "X-LLM-Mux-Chaos"
+
+// chaosPartialText is the assistant text streamed before an injected disconnect.
+const chaosPartialText = "This response was cut off by llm-mux chaos mode"
+
+// chaosFault picks the fault to inject into a chat request, or "" for none.
+// Nothing is injected unless chaos mode is enabled in the config; a valid
+// X-LLM-Mux-Chaos header then forces that fault, otherwise one is picked at
+// random for the configured fraction of requests.
+func (h *BaseAPIHandler) chaosFault(ctx context.Context) (config.ChaosConfig, string) {
+	if h.Cfg == nil || !h.Cfg.Chaos.Enabled {
+		return config.ChaosConfig{}, ""
+	}
+	cfg := h.Cfg.Chaos
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c.Request != nil {
+		if requested := strings.ToLower(strings.TrimSpace(c.GetHeader(headerChaos))); requested != "" {
+			if slices.Contains(config.ChaosFaults, requested) {
+				return cfg, requested
+			}
+			return cfg, ""
+		}
+	}
+	if cfg.Rate <= 0 || rand.Float64() >= cfg.Rate {
+		return cfg, ""
+	}
+	kinds := cfg.FaultKinds()
+	return cfg, kinds[rand.IntN(len(kinds))]
+}
+
+// injectChaos applies fault to a non-streaming request. It returns the error
+// to answer with, or nil when the request should go on to the upstream, which
+// is the case for slow-first-byte once its delay has passed.
+func (h *BaseAPIHandler) injectChaos(ctx context.Context, cfg config.ChaosConfig, fault string) *interfaces.ErrorMessage {
+	if fault == "" {
+		return nil
+	}
+	markChaos(ctx, fault)
+	switch fault {
+	case config.ChaosFaultSlowFirstByte:
+		timer := time.NewTimer(cfg.FirstByteDelayDuration())
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return &interfaces.ErrorMessage{StatusCode: http.StatusRequestTimeout, Error: ctx.Err()}
+		case <-timer.C:
+			return nil
+		}
+	case config.ChaosFaultRateLimit:
+		addon := http.Header{}
+		addon.Set("Retry-After", strconv.Itoa(cfg.RetryAfterSeconds()))
+		return &interfaces.ErrorMessage{
+			StatusCode: http.StatusTooManyRequests,
+			Error:      errors.New("chaos: simulated upstream rate limit"),
+			Addon:      addon,
+		}
+	case config.ChaosFaultDisconnect:
+		return &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: errors.New("chaos: simulated upstream disconnect")}
+	default:
+		return &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: errors.New("chaos: simulated upstream server error")}
+	}
+}
+
+// injectChaosStream applies fault to a streaming request. Errors are delivered
+// the way upstream failures are: on the error channel before any data, or
+// after a partial answer for disconnect. ok is false when the request should
+// go on to the upstream.
+func (h *BaseAPIHandler) injectChaosStream(ctx context.Context, cfg config.ChaosConfig, fault, handlerType, modelName string) (<-chan []byte, <-chan *interfaces.ErrorMessage, bool) {
+	if fault != config.ChaosFaultDisconnect {
+		errMsg := h.injectChaos(ctx, cfg, fault)
+		if errMsg == nil {
+			return nil, nil, false
+		}
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, errChan, true
+	}
+
+	markChaos(ctx, fault)
+	chunks, err := chaosPartialChunks(handlerType, modelName)
+	dataChan := make(chan []byte, len(chunks))
+	errChan := make(chan *interfaces.ErrorMessage, 1)
+	for _, chunk := range chunks {
+		dataChan <- chunk
+	}
+	close(dataChan)
+	if err == nil {
+		err = errors.New("chaos: simulated upstream disconnect mid-stream")
+	}
+	errChan <- &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: err}
+	close(errChan)
+	return dataChan, errChan, true
+}
+
+// markChaos records an injected fault on the response and in metrics.
+func markChaos(ctx context.Context, fault string) {
+	metrics.ChaosFaults.Inc(metrics.L(fault))
+	log.Debugf("chaos: injecting %s", fault)
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok {
+		c.Header(headerChaos, fault)
+	}
+}
+
+// chaosPartialChunks is the start of an answer in the handler's stream
+// format, without the chunks that would finish it.
+func chaosPartialChunks(handlerType, modelName string) ([][]byte, error) {
+	if handlerType == constant.OpenaiResponse {
+		events, err := responsesStopEvents(modelName, chaosPartialText)
+		if err != nil {
+			return nil, err
+		}
+		// Drop response.completed, the last event/data pair.
+		return events[:len(events)-2], nil
+	}
+	id := fmt.Sprintf("chatcmpl-chaos-%d", time.Now().UnixNano())
+	created := time.Now().Unix()
+	processor := stream.NewOpenAIStreamProcessor(nil, provider.FromString(handlerType), modelName, id)
+	// Gemini output holds back the latest chunk until the next one arrives,
+	// so the text is followed by a second delta that is never delivered there.
+	var chunks [][]byte
+	for _, delta := range []string{chaosPartialText, "..."} {
+		line, err := json.Marshal(map[string]any{
+			"id": id, "object": "chat.completion.chunk", "created": created, "model": modelName,
+			"choices": []any{map[string]any{"index": 0, "delta": map[string]any{"role": "assistant", "content": delta}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		out, _, err := processor.ProcessLine(append([]byte("data: "), line...))
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, out...)
+	}
+	return chunks, nil
+}
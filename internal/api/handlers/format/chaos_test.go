@@ -0,0 +1,87 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+)
+
+func TestChaosFaultRequiresEnabled(t *testing.T) {
+	h := NewBaseAPIHandlers(&config.SDKConfig{Chaos: config.ChaosConfig{Rate: 1}}, nil, nil, nil)
+	ctx, _ := newCacheTestContext(map[string]string{headerChaos: config.ChaosFaultServerError})
+	if _, fault := h.chaosFault(ctx); fault != "" {
+		t.Fatalf("disabled chaos injected %q", fault)
+	}
+
+	h.UpdateClients(&config.SDKConfig{Chaos: config.ChaosConfig{Enabled: true}})
+	if _, fault := h.chaosFault(ctx); fault != config.ChaosFaultServerError {
+		t.Errorf("header fault = %q, want %s", fault, config.ChaosFaultServerError)
+	}
+	plain, _ := newCacheTestContext(nil)
+	if _, fault := h.chaosFault(plain); fault != "" {
+		t.Errorf("rate 0 injected %q", fault)
+	}
+
+	h.UpdateClients(&config.SDKConfig{Chaos: config.ChaosConfig{Enabled: true, Rate: 1, Faults: []string{config.ChaosFaultRateLimit}}})
+	if _, fault := h.chaosFault(plain); fault != config.ChaosFaultRateLimit {
+		t.Errorf("rate 1 fault = %q, want %s", fault, config.ChaosFaultRateLimit)
+	}
+	off, _ := newCacheTestContext(map[string]string{headerChaos: "off"})
+	if _, fault := h.chaosFault(off); fault != "" {
+		t.Errorf("header off injected %q", fault)
+	}
+}
+
+func TestInjectChaos(t *testing.T) {
+	h := NewBaseAPIHandlers(&config.SDKConfig{}, nil, nil, nil)
+	cfg := config.ChaosConfig{Enabled: true, RetryAfter: 7}
+
+	ctx, c := newCacheTestContext(nil)
+	msg := h.injectChaos(ctx, cfg, config.ChaosFaultRateLimit)
+	if msg == nil || msg.StatusCode != http.StatusTooManyRequests || msg.Addon.Get("Retry-After") != "7" {
+		t.Fatalf("rate-limit = %+v, want 429 with Retry-After 7", msg)
+	}
+	if got := c.Writer.Header().Get(headerChaos); got != config.ChaosFaultRateLimit {
+		t.Errorf("%s = %q, want %s", headerChaos, got, config.ChaosFaultRateLimit)
+	}
+	if msg = h.injectChaos(ctx, cfg, config.ChaosFaultServerError); msg == nil || msg.StatusCode != http.StatusInternalServerError {
+		t.Errorf("server-error = %+v, want 500", msg)
+	}
+	if msg = h.injectChaos(ctx, cfg, ""); msg != nil {
+		t.Errorf("no fault = %+v, want nil", msg)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if msg = h.injectChaos(cancelled, cfg, config.ChaosFaultSlowFirstByte); msg == nil || msg.StatusCode != http.StatusRequestTimeout {
+		t.Errorf("slow-first-byte on a cancelled request = %+v, want 408", msg)
+	}
+}
+
+func TestInjectChaosStreamDisconnect(t *testing.T) {
+	h := NewBaseAPIHandlers(&config.SDKConfig{}, nil, nil, nil)
+	for _, handlerType := range []string{constant.OpenAI, constant.Claude, constant.Gemini, constant.OpenaiResponse} {
+		ctx, _ := newCacheTestContext(nil)
+		data, errs, injected := h.injectChaosStream(ctx, config.ChaosConfig{Enabled: true}, config.ChaosFaultDisconnect, handlerType, "m")
+		if !injected {
+			t.Fatalf("%s: disconnect not injected", handlerType)
+		}
+		var out strings.Builder
+		for chunk := range data {
+			out.Write(chunk)
+		}
+		if !strings.Contains(out.String(), chaosPartialText) {
+			t.Errorf("%s: partial answer missing: %s", handlerType, out.String())
+		}
+		if strings.Contains(out.String(), "[DONE]") || strings.Contains(out.String(), "response.completed") || strings.Contains(out.String(), "message_stop") {
+			t.Errorf("%s: partial stream was terminated: %s", handlerType, out.String())
+		}
+		if msg := <-errs; msg == nil || msg.StatusCode != http.StatusBadGateway {
+			t.Errorf("%s: error = %+v, want 502", handlerType, msg)
+		}
+	}
+}
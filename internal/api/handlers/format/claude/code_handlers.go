@@ -13,8 +13,11 @@ import (
 	"github.com/nghyane/llm-mux/internal/interfaces"
 	"github.com/nghyane/llm-mux/internal/json"
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/runtime/executor"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+	"github.com/nghyane/llm-mux/internal/util"
 	"github.com/tidwall/gjson"
 )
 
@@ -76,18 +79,66 @@ func (h *ClaudeCodeAPIHandler) ClaudeCountTokens(c *gin.Context) {
 	modelName := gjson.GetBytes(rawJSON, "model").String()
 
 	resp, errMsg := h.ExecuteCountWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, alt)
-	if errMsg != nil {
+	if errMsg != nil && !countErrorAllowsEstimate(errMsg) {
 		h.WriteErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
-	_, _ = c.Writer.Write(resp)
+	if errMsg == nil {
+		if gjson.GetBytes(resp, "input_tokens").Int() > 0 {
+			_, _ = c.Writer.Write(resp)
+			cliCancel()
+			return
+		}
+		if count := countFromProviderPayload(resp); count > 0 {
+			_, _ = c.Writer.Write(inputTokensPayload(count))
+			cliCancel()
+			return
+		}
+	}
+
+	// The provider has no native counter; estimate locally.
+	irReq, errIR := stream.ConvertRequestToIR(provider.FormatClaude, modelName, rawJSON, nil)
+	if errIR != nil {
+		if errMsg == nil {
+			errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: errIR}
+		}
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	c.Header(provider.HeaderTokenCount, provider.TokenCountEstimated)
+	_, _ = c.Writer.Write(inputTokensPayload(util.CountTokensFromIR(modelName, irReq)))
 	cliCancel()
 }
 
+// countErrorAllowsEstimate reports whether a failed provider count may be
+// replaced by a local estimate, which is only when the provider has no count
+// endpoint. Other failures, such as rate limits or upstream errors, are
+// returned to the client with their status. The Claude executor estimates on
+// its own upstream failures and reports those counts as estimated.
+func countErrorAllowsEstimate(msg *interfaces.ErrorMessage) bool {
+	return msg.StatusCode == http.StatusNotImplemented
+}
+
+// countFromProviderPayload extracts an input token count from the count
+// responses of non-Anthropic providers (Gemini countTokens, OpenAI-style usage).
+func countFromProviderPayload(payload []byte) int64 {
+	for _, path := range []string{"totalTokens", "response.totalTokens", "total_tokens", "usage.prompt_tokens", "usage.input_tokens"} {
+		if n := gjson.GetBytes(payload, path).Int(); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func inputTokensPayload(count int64) []byte {
+	return []byte(fmt.Sprintf(`{"input_tokens":%d}`, count))
+}
+
 func (h *ClaudeCodeAPIHandler) ClaudeModels(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"data": h.Models(),
+		"data": h.AppendModelAliases(registry.GetGlobalRegistry().ListModels("claude", format.ModelListOptions(c))),
 	})
 }
 
@@ -198,6 +249,7 @@ func (h *ClaudeCodeAPIHandler) forwardClaudeStream(c *gin.Context, flusher http.
 type claudeErrorDetail struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
 }
 
 type claudeErrorResponse struct {
@@ -206,11 +258,13 @@ type claudeErrorResponse struct {
 }
 
 func (h *ClaudeCodeAPIHandler) toClaudeError(msg *interfaces.ErrorMessage) claudeErrorResponse {
+	canonical := format.CanonicalError(msg)
 	return claudeErrorResponse{
 		Type: "error",
 		Error: claudeErrorDetail{
-			Type:    "api_error",
-			Message: msg.Error.Error(),
+			Type:    canonical.Type,
+			Message: canonical.Message,
+			Detail:  canonical.Detail,
 		},
 	}
 }
@@ -0,0 +1,23 @@
+package claude
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+func TestCountErrorAllowsEstimate(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusNotImplemented:      true,
+		http.StatusBadRequest:          false,
+		http.StatusUnauthorized:        false,
+		http.StatusTooManyRequests:     false,
+		http.StatusInternalServerError: false,
+		http.StatusServiceUnavailable:  false,
+	} {
+		if got := countErrorAllowsEstimate(&interfaces.ErrorMessage{StatusCode: status}); got != want {
+			t.Errorf("countErrorAllowsEstimate(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
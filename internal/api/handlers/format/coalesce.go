@@ -0,0 +1,86 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// headerCoalesced marks a response shared from an identical request that was
+// already in flight.
+const headerCoalesced = "X-LLM-Mux-Coalesced"
+
+// inflightCall is a request being executed on behalf of every identical
+// request that arrives before it completes.
+type inflightCall struct {
+	done    chan struct{}
+	payload []byte
+	errMsg  *interfaces.ErrorMessage
+}
+
+// inflightRequests tracks the coalesced requests in flight by key.
+type inflightRequests struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+// coalesceKey returns the key under which the request shares an upstream
+// call with identical requests in flight. The key includes the client's API
+// key ID, so a request only joins one that passed the same scope check. ok is
+// false when coalescing is disabled, the request is not deterministic or the
+// client bypassed the cache.
+func (h *BaseAPIHandler) coalesceKey(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (key string, ok bool) {
+	if h.Cfg == nil || !h.Cfg.CoalesceRequests || !deterministicRequest(rawJSON) {
+		return "", false
+	}
+	c, _ := ctx.Value(ctxKeyGin).(*gin.Context)
+	if responseCacheBypassed(c) {
+		return "", false
+	}
+	sum := requestKey(handlerType, modelName, rawJSON, alt, false)
+	return usage.APIKeyIDFromContext(ctx) + "\x00" + string(sum[:]), true
+}
+
+// coalesce runs execute for the first request with key and hands a copy of
+// its result to every identical request that arrives while it runs, marking
+// their responses with sharedHeader. When the first request is canceled by
+// its client, the waiting ones elect a new first request among themselves.
+func (h *BaseAPIHandler) coalesce(ctx context.Context, key, sharedHeader string, execute func(context.Context) ([]byte, *interfaces.ErrorMessage)) ([]byte, *interfaces.ErrorMessage) {
+	h.inflight.mu.Lock()
+	if call, ok := h.inflight.calls[key]; ok {
+		h.inflight.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: ctx.Err()}
+		}
+		if call.errMsg != nil && errors.Is(call.errMsg.Error, context.Canceled) {
+			return h.coalesce(ctx, key, sharedHeader, execute)
+		}
+		if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+			c.Header(sharedHeader, "true")
+		}
+		return bytes.Clone(call.payload), call.errMsg
+	}
+	if h.inflight.calls == nil {
+		h.inflight.calls = make(map[string]*inflightCall)
+	}
+	call := &inflightCall{done: make(chan struct{})}
+	h.inflight.calls[key] = call
+	h.inflight.mu.Unlock()
+
+	defer func() {
+		h.inflight.mu.Lock()
+		delete(h.inflight.calls, key)
+		h.inflight.mu.Unlock()
+		close(call.done)
+	}()
+	call.payload, call.errMsg = execute(ctx)
+	return bytes.Clone(call.payload), call.errMsg
+}
@@ -0,0 +1,104 @@
+package format
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// blockingExecutor holds every call until release is closed.
+type blockingExecutor struct {
+	chainExecutor
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *blockingExecutor) Identifier() string { return "coalesce" }
+func (e *blockingExecutor) Execute(ctx context.Context, _ *provider.Auth, req provider.Request, _ provider.Options) (provider.Response, error) {
+	if e.calls.Add(1) == 1 {
+		close(e.started)
+	}
+	select {
+	case <-e.release:
+	case <-ctx.Done():
+		return provider.Response{}, ctx.Err()
+	}
+	return provider.Response{Payload: []byte(`{"model":"` + req.Model + `"}`)}, nil
+}
+
+func TestCoalesceIdenticalRequests(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("coalesce-1", "coalesce", []*registry.ModelInfo{{ID: "coalesce-model"}})
+	t.Cleanup(func() { reg.UnregisterClient("coalesce-1") })
+
+	m := provider.NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	exec := &blockingExecutor{started: make(chan struct{}), release: make(chan struct{})}
+	m.RegisterExecutor(exec)
+	if _, err := m.Register(context.Background(), &provider.Auth{ID: "coalesce-1", Provider: "coalesce", Status: provider.StatusActive}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	h := NewBaseAPIHandlers(&config.SDKConfig{CoalesceRequests: true}, &config.RoutingConfig{}, m, nil)
+	body := []byte(`{"model":"coalesce-model","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)
+
+	const n = 8
+	var wg sync.WaitGroup
+	payloads := make([]string, n)
+	coalesced := make([]bool, n)
+	run := func(i int) {
+		defer wg.Done()
+		ctx, c := newCacheTestContext(nil)
+		resp, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "coalesce-model", body, "")
+		if errMsg != nil {
+			t.Errorf("request %d: %v", i, errMsg.Error)
+			return
+		}
+		payloads[i] = string(resp)
+		coalesced[i] = c.Writer.Header().Get(headerCoalesced) == "true"
+	}
+
+	wg.Add(1)
+	go run(0)
+	<-exec.started
+	for i := 1; i < n; i++ {
+		wg.Add(1)
+		go run(i)
+	}
+	// Let the identical requests join the one in flight before it completes.
+	time.Sleep(50 * time.Millisecond)
+	close(exec.release)
+	wg.Wait()
+
+	if got := exec.calls.Load(); got != 1 {
+		t.Fatalf("upstream calls = %d, want 1", got)
+	}
+	for i := range payloads {
+		if payloads[i] != `{"model":"coalesce-model"}` {
+			t.Errorf("request %d payload = %q", i, payloads[i])
+		}
+		if coalesced[i] != (i > 0) {
+			t.Errorf("request %d coalesced = %v, want %v", i, coalesced[i], i > 0)
+		}
+	}
+
+	// Sampled requests are never coalesced.
+	ctx, _ := newCacheTestContext(nil)
+	if _, ok := h.coalesceKey(ctx, "openai", "coalesce-model", []byte(`{"temperature":0.7}`), ""); ok {
+		t.Error("sampled request coalesced")
+	}
+
+	// Requests from different API keys never share a call.
+	keyA, _ := h.coalesceKey(usage.WithAPIKeyID(ctx, "key-a"), "openai", "coalesce-model", body, "")
+	keyB, _ := h.coalesceKey(usage.WithAPIKeyID(ctx, "key-b"), "openai", "coalesce-model", body, "")
+	if keyA == keyB {
+		t.Error("requests from different API keys share a coalesce key")
+	}
+}
@@ -0,0 +1,59 @@
+package format
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// contextLengthError is an upstream context overflow annotated with what the
+// client needs to trim the request: the model's context window and the
+// request's estimated input tokens. Either is zero when unknown.
+type contextLengthError struct {
+	err    error
+	model  string
+	limit  int
+	tokens int64
+}
+
+func (e *contextLengthError) Error() string { return e.err.Error() }
+func (e *contextLengthError) Unwrap() error { return e.err }
+
+// message is the canonical error.message for the overflow.
+func (e *contextLengthError) message() string {
+	switch {
+	case e.limit > 0 && e.tokens > 0:
+		return fmt.Sprintf("This request has about %d input tokens, more than the %d-token context window of %s. Shorten the messages or history and retry.", e.tokens, e.limit, e.model)
+	case e.limit > 0:
+		return fmt.Sprintf("This request exceeds the %d-token context window of %s. Shorten the messages or history and retry.", e.limit, e.model)
+	case e.tokens > 0:
+		return fmt.Sprintf("This request has about %d input tokens, more than the context window of %s. Shorten the messages or history and retry.", e.tokens, e.model)
+	default:
+		return fmt.Sprintf("This request exceeds the context window of %s. Shorten the messages or history and retry.", e.model)
+	}
+}
+
+// withContextLength annotates msg when the upstream rejected the request for
+// exceeding model's context window, estimating the input tokens of rawJSON
+// with the tokenizer for model. Providers answer overflows with assorted
+// statuses; the client always gets 400.
+func withContextLength(msg *interfaces.ErrorMessage, handlerType, model string, rawJSON []byte) *interfaces.ErrorMessage {
+	if msg == nil || msg.Error == nil || !provider.IsContextLengthError(msg.Error.Error()) {
+		return msg
+	}
+	cle := &contextLengthError{err: msg.Error, model: model}
+	if info := registry.GetGlobalRegistry().GetModelInfo(model); info != nil {
+		cle.limit = info.ContextWindow()
+	}
+	if req, err := translator.ParseRequest(handlerType, rawJSON); err == nil {
+		cle.tokens = util.CountTokensFromIR(model, req)
+	}
+	msg.Error = cle
+	msg.StatusCode = http.StatusBadRequest
+	return msg
+}
@@ -0,0 +1,105 @@
+package format
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+)
+
+const (
+	// headerRouteDebug lets a client opt into route trace headers for a single request.
+	headerRouteDebug = "X-LLM-Mux-Debug"
+	// headerRequestID is echoed when thinking capture is enabled so the trace
+	// can later be fetched from the management API.
+	headerRequestID = "X-LLM-Mux-Request-Id"
+)
+
+// requestDebug holds the optional per-request debug carriers.
+type requestDebug struct {
+	trace   *provider.RouteTrace
+	capture *stream.ThinkingCapture
+}
+
+// startRequestDebug attaches a route trace when debug headers are enabled in
+// config or requested by the client, and a thinking capture when trace capture
+// is enabled. Both are nil in the default configuration.
+func (h *BaseAPIHandler) startRequestDebug(ctx context.Context) (context.Context, requestDebug) {
+	var dbg requestDebug
+	c, _ := ctx.Value(ctxKeyGin).(*gin.Context)
+
+	enabled := h.Cfg != nil && h.Cfg.DebugHeaders
+	if !enabled && c != nil && c.Request != nil {
+		switch strings.ToLower(strings.TrimSpace(c.GetHeader(headerRouteDebug))) {
+		case "1", "true", "yes", "on":
+			enabled = true
+		}
+	}
+	if enabled {
+		dbg.trace = &provider.RouteTrace{}
+		ctx = provider.WithRouteTrace(ctx, dbg.trace)
+	}
+
+	if stream.ThinkingTraces() != nil && c != nil {
+		requestID, _ := c.Get(headerRequestID)
+		id, _ := requestID.(string)
+		if id == "" {
+			id = clientRequestID(c)
+			c.Set(headerRequestID, id)
+			c.Header(headerRequestID, id)
+		}
+		if dbg.capture = stream.NewThinkingCapture(id); dbg.capture != nil {
+			ctx = stream.WithThinkingCapture(ctx, dbg.capture)
+		}
+	}
+	return ctx, dbg
+}
+
+// clientRequestID returns the request's X-Request-ID, as assigned by the
+// request ID middleware, or a sanitized client value when the middleware is absent.
+func clientRequestID(c *gin.Context) string {
+	if c.Request == nil {
+		return log.NewRequestID("")
+	}
+	if id := log.RequestIDFromContext(c.Request.Context()); id != "" {
+		return id
+	}
+	return log.NewRequestID(c.GetHeader(log.RequestIDHeader))
+}
+
+// attach exposes the debug carriers to translators through request metadata.
+func (d requestDebug) attach(req *provider.Request, opts *provider.Options) {
+	if d.trace == nil && d.capture == nil {
+		return
+	}
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]any, 2)
+		opts.Metadata = req.Metadata
+	}
+	if d.trace != nil {
+		req.Metadata[provider.RouteTraceMetadataKey] = d.trace
+	}
+	if d.capture != nil {
+		req.Metadata[stream.ThinkingCaptureMetadataKey] = d.capture
+	}
+}
+
+// writeHeaders copies the recorded route onto the response headers.
+// It must run before the handler writes the response body.
+func (d requestDebug) writeHeaders(ctx context.Context) {
+	if d.trace == nil {
+		return
+	}
+	c, ok := ctx.Value(ctxKeyGin).(*gin.Context)
+	if !ok {
+		return
+	}
+	for key, values := range d.trace.Headers() {
+		for _, value := range values {
+			c.Header(key, value)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package format
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// upstreamMessagePaths are tried in order to pull a human-readable message out
+// of an upstream JSON error body (OpenAI/Claude, Gemini arrays, plain objects).
+var upstreamMessagePaths = []string{"error.message", "0.error.message", "message", "error", "detail"}
+
+// newErrorMessage wraps an execution error for the client. Rate limit errors
+// get a Retry-After header from the upstream retry hint or, failing that, from
+// the earliest NextRetryAfter among the auths that serve model, spread by the
+// configured retry jitter.
+func (h *BaseAPIHandler) newErrorMessage(err error, providers []string, model string) *interfaces.ErrorMessage {
+	status, addon := extractErrorDetails(err)
+	msg := &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	if errorCategory(msg) != provider.CategoryQuotaError || addon.Get("Retry-After") != "" {
+		return msg
+	}
+	var wait time.Duration
+	if d := provider.RetryAfterFromError(err); d != nil {
+		wait = provider.JitterRetryAfter(*d, true)
+	} else if d, ok := h.AuthManager.CooldownWait(providers, model); ok {
+		wait = provider.JitterRetryAfter(d, false)
+	} else {
+		return msg
+	}
+	if msg.Addon == nil {
+		msg.Addon = make(http.Header)
+	}
+	msg.Addon.Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(wait.Seconds())))))
+	return msg
+}
+
+// errorCategory classifies msg, preferring the category carried by the error
+// and falling back to the response status.
+func errorCategory(msg *interfaces.ErrorMessage) provider.ErrorCategory {
+	var text string
+	if msg.Error != nil {
+		if c := provider.CategorizeErr(msg.Error); c != provider.CategoryUnknown {
+			return c
+		}
+		text = msg.Error.Error()
+	}
+	return provider.CategorizeError(msg.StatusCode, text)
+}
+
+// upstreamMessage extracts the message from an upstream error text, which may
+// be a JSON error body, possibly behind a short prefix.
+func upstreamMessage(text string) string {
+	if i := strings.IndexAny(text, "{["); i >= 0 && gjson.Valid(text[i:]) {
+		root := gjson.Parse(text[i:])
+		for _, path := range upstreamMessagePaths {
+			if v := root.Get(path); v.Type == gjson.String && v.String() != "" {
+				return v.String()
+			}
+		}
+	}
+	return text
+}
+
+// CanonicalError maps msg to the error object every endpoint returns:
+// error.type and error.code follow the error category regardless of which
+// upstream failed, and error.detail keeps the upstream error text unchanged.
+func CanonicalError(msg *interfaces.ErrorMessage) ErrorDetail {
+	status := http.StatusInternalServerError
+	if msg != nil && msg.StatusCode > 0 {
+		status = msg.StatusCode
+	}
+	if msg == nil || msg.Error == nil {
+		category := provider.CategorizeHTTPStatus(status)
+		return ErrorDetail{Message: http.StatusText(status), Type: category.ErrorType(), Code: category.ErrorCode()}
+	}
+	category := errorCategory(&interfaces.ErrorMessage{StatusCode: status, Error: msg.Error})
+	detail := msg.Error.Error()
+	message := upstreamMessage(detail)
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	code := category.ErrorCode()
+	if category == provider.CategoryUserError && provider.IsContextLengthError(detail) {
+		code = provider.ErrCodeContextLengthExceeded
+		var cle *contextLengthError
+		if errors.As(msg.Error, &cle) {
+			message = cle.message()
+		}
+	}
+	return ErrorDetail{Message: message, Type: category.ErrorType(), Code: code, Detail: detail}
+}
+
+// ErrorBody renders msg as a JSON {"error": {...}} body.
+func ErrorBody(msg *interfaces.ErrorMessage) []byte {
+	body, _ := json.Marshal(ErrorResponse{Error: CanonicalError(msg)})
+	return body
+}
@@ -0,0 +1,177 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+type upstreamError struct {
+	status     int
+	body       string
+	retryAfter *time.Duration
+}
+
+func (e upstreamError) Error() string              { return e.body }
+func (e upstreamError) StatusCode() int            { return e.status }
+func (e upstreamError) RetryAfter() *time.Duration { return e.retryAfter }
+
+func TestCanonicalErrorMapping(t *testing.T) {
+	wait := 1500 * time.Millisecond
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantType   string
+		wantCode   string
+		wantMsg    string
+		wantRetry  string
+	}{
+		{
+			name:       "claude rate limit",
+			err:        upstreamError{status: 429, body: `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`, retryAfter: &wait},
+			wantStatus: 429, wantType: "rate_limit_error", wantCode: "rate_limit_exceeded",
+			wantMsg: "Number of requests has exceeded your rate limit", wantRetry: "2",
+		},
+		{
+			name:       "gemini resource exhausted",
+			err:        upstreamError{status: 429, body: `[{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED"}}]`},
+			wantStatus: 429, wantType: "rate_limit_error", wantCode: "rate_limit_exceeded",
+			wantMsg: "Resource has been exhausted (e.g. check quota).",
+		},
+		{
+			name:       "openai invalid key",
+			err:        upstreamError{status: 401, body: `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`},
+			wantStatus: 401, wantType: "authentication_error", wantCode: "upstream_authentication_failed",
+			wantMsg: "Incorrect API key provided",
+		},
+		{
+			name:       "gemini invalid argument",
+			err:        upstreamError{status: 400, body: `{"error":{"code":400,"message":"Request contains an invalid argument.","status":"INVALID_ARGUMENT"}}`},
+			wantStatus: 400, wantType: "invalid_request_error", wantCode: "invalid_request",
+			wantMsg: "Request contains an invalid argument.",
+		},
+		{
+			name:       "plain text overload",
+			err:        upstreamError{status: 503, body: "upstream connect error"},
+			wantStatus: 503, wantType: "api_error", wantCode: "upstream_unavailable",
+			wantMsg: "upstream connect error",
+		},
+		{
+			name:       "anthropic prompt too long",
+			err:        upstreamError{status: 400, body: `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 215000 tokens > 200000 maximum"}}`},
+			wantStatus: 400, wantType: "invalid_request_error", wantCode: "context_length_exceeded",
+			wantMsg: "prompt is too long: 215000 tokens > 200000 maximum",
+		},
+		{
+			name:       "revoked oauth token",
+			err:        &provider.Error{Message: "invalid_grant: Token has been expired or revoked.", HTTPStatus: 401, ErrCategory: provider.CategoryAuthRevoked},
+			wantStatus: 401, wantType: "authentication_error", wantCode: "upstream_credentials_revoked",
+			wantMsg: "invalid_grant: Token has been expired or revoked.",
+		},
+	}
+
+	h := NewBaseAPIHandlers(nil, nil, nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := h.newErrorMessage(tt.err, nil, "")
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			h.WriteErrorResponse(c, msg)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetry)
+			}
+			var body ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s: %v", rec.Body.String(), err)
+			}
+			if body.Error.Type != tt.wantType || body.Error.Code != tt.wantCode || body.Error.Message != tt.wantMsg {
+				t.Errorf("error = %+v, want type %q code %q message %q", body.Error, tt.wantType, tt.wantCode, tt.wantMsg)
+			}
+			if body.Error.Detail != tt.err.Error() {
+				t.Errorf("detail = %q, want upstream text %q", body.Error.Detail, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestCanonicalErrorWithoutUpstream(t *testing.T) {
+	got := CanonicalError(&interfaces.ErrorMessage{StatusCode: http.StatusNotFound, Error: errors.New("unknown provider for model x")})
+	if got.Type != "not_found_error" || got.Code != "not_found" || got.Message != "unknown provider for model x" {
+		t.Errorf("CanonicalError = %+v", got)
+	}
+	if got := CanonicalError(nil); got.Type != "api_error" || got.Message != "Internal Server Error" {
+		t.Errorf("CanonicalError(nil) = %+v", got)
+	}
+}
+
+func TestNewErrorMessageRetryAfterFromCooldown(t *testing.T) {
+	manager := provider.NewManager(nil, nil, nil)
+	auth := &provider.Auth{
+		ID:       "cooldown-auth",
+		Provider: "cooldowntest",
+		ModelStates: map[string]*provider.ModelState{
+			"cooldown-model": {Unavailable: true, NextRetryAfter: time.Now().Add(42 * time.Second)},
+		},
+	}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "cooldown-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	h := NewBaseAPIHandlers(&config.SDKConfig{}, nil, manager, nil)
+	msg := h.newErrorMessage(upstreamError{status: 429, body: "Too Many Requests"}, []string{"cooldowntest"}, "cooldown-model")
+	if got := msg.Addon.Get("Retry-After"); got != "42" && got != "41" {
+		t.Errorf("Retry-After = %q, want about 42", got)
+	}
+
+	msg = h.newErrorMessage(upstreamError{status: 400, body: "bad"}, []string{"cooldowntest"}, "cooldown-model")
+	if got := msg.Addon.Get("Retry-After"); got != "" {
+		t.Errorf("Retry-After on a non-rate-limit error: %q", got)
+	}
+}
+
+func TestWithContextLength(t *testing.T) {
+	registry.GetGlobalRegistry().RegisterClient("ctxlen-1", "ctxlen", []*registry.ModelInfo{{ID: "ctxlen-model", ContextLength: 1000}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient("ctxlen-1") })
+
+	upstream := upstreamError{status: 413, body: `{"error":{"message":"Input is too long for requested model.","reason":"CONTENT_LENGTH_EXCEEDS_THRESHOLD"}}`}
+	body := []byte(`{"model":"ctxlen-model","messages":[{"role":"user","content":"` + strings.Repeat("hello world ", 200) + `"}]}`)
+	msg := withContextLength(&interfaces.ErrorMessage{StatusCode: upstream.status, Error: upstream}, "openai", "ctxlen-model", body)
+
+	if msg.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", msg.StatusCode)
+	}
+	got := CanonicalError(msg)
+	if got.Code != "context_length_exceeded" || got.Type != "invalid_request_error" {
+		t.Errorf("CanonicalError = %+v", got)
+	}
+	if !strings.Contains(got.Message, "input tokens, more than the 1000-token context window of ctxlen-model") {
+		t.Errorf("message = %q, want the limit and token estimate", got.Message)
+	}
+	if got.Detail != upstream.body {
+		t.Errorf("detail = %q, want upstream text", got.Detail)
+	}
+
+	other := &interfaces.ErrorMessage{StatusCode: 429, Error: upstreamError{status: 429, body: "Too Many Requests"}}
+	if withContextLength(other, "openai", "ctxlen-model", body).StatusCode != 429 {
+		t.Error("withContextLength changed an unrelated error")
+	}
+}
@@ -0,0 +1,41 @@
+package format
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// headerFallbackModel names the routing.fallbacks model that served the
+// request in place of the requested one.
+const headerFallbackModel = "X-LLM-Mux-Fallback-Model"
+
+// fallbackApplies reports whether err means no member of the requested
+// model's family could serve it, so its fallback chain is tried. A request
+// the upstream rejected would fail the same way on another model, and a
+// canceled one has nobody waiting for the answer.
+func fallbackApplies(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if provider.NoAccountAvailable(err) {
+		return true
+	}
+	status, _ := extractErrorDetails(err)
+	switch errorCategory(&interfaces.ErrorMessage{StatusCode: status, Error: err}) {
+	case provider.CategoryUserError, provider.CategoryClientCanceled:
+		return false
+	}
+	return true
+}
+
+// annotateFallback tells the client which fallback model served the request.
+func annotateFallback(ctx context.Context, requested, fallback string) {
+	log.Infof("fallback chain: serving %s with %s", requested, fallback)
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Header(headerFallbackModel, fallback)
+	}
+}
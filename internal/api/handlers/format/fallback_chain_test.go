@@ -0,0 +1,132 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+type upstreamStatusError struct{ code int }
+
+func (e upstreamStatusError) Error() string   { return http.StatusText(e.code) }
+func (e upstreamStatusError) StatusCode() int { return e.code }
+
+// chainExecutor answers with the status configured for each upstream model,
+// or a success when none is set, and records the models it was called for.
+type chainExecutor struct {
+	status map[string]int
+
+	mu     sync.Mutex
+	called []string
+}
+
+func (e *chainExecutor) Identifier() string { return "fbchain" }
+func (e *chainExecutor) Execute(_ context.Context, _ *provider.Auth, req provider.Request, _ provider.Options) (provider.Response, error) {
+	e.mu.Lock()
+	e.called = append(e.called, req.Model)
+	e.mu.Unlock()
+	if code := e.status[req.Model]; code != 0 {
+		return provider.Response{}, upstreamStatusError{code}
+	}
+	return provider.Response{Payload: []byte(`{"model":"` + req.Model + `"}`)}, nil
+}
+func (e *chainExecutor) ExecuteStream(context.Context, *provider.Auth, provider.Request, provider.Options) (<-chan provider.StreamChunk, error) {
+	return nil, upstreamStatusError{http.StatusNotImplemented}
+}
+func (e *chainExecutor) Refresh(_ context.Context, a *provider.Auth) (*provider.Auth, error) {
+	return a, nil
+}
+func (e *chainExecutor) CountTokens(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+
+func TestFallbackChain(t *testing.T) {
+	models := []*registry.ModelInfo{{ID: "fbchain-opus"}, {ID: "fbchain-sonnet"}, {ID: "fbchain-mini"}, {ID: "fbchain-last"}}
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("fbchain-1", "fbchain", models)
+	t.Cleanup(func() {
+		reg.UnregisterClient("fbchain-1")
+		reg.SetProviderDownUntil("fbchain", time.Time{})
+	})
+
+	routing := &config.RoutingConfig{Fallbacks: map[string][]string{
+		"fbchain-opus": {"fbchain-sonnet", "fbchain-unknown", "fbchain-mini", "fbchain-last"},
+	}}
+	routing.Init()
+
+	tests := []struct {
+		name       string
+		status     map[string]int
+		wantModel  string
+		wantStatus int
+		wantCalled []string
+	}{
+		{
+			name:       "exhausted down to the last option",
+			status:     map[string]int{"fbchain-opus": 503, "fbchain-sonnet": 429, "fbchain-mini": 500},
+			wantModel:  "fbchain-last",
+			wantCalled: []string{"fbchain-opus", "fbchain-sonnet", "fbchain-mini", "fbchain-last"},
+		},
+		{
+			name:       "every option fails",
+			status:     map[string]int{"fbchain-opus": 503, "fbchain-sonnet": 503, "fbchain-mini": 503, "fbchain-last": 503},
+			wantStatus: 503,
+			wantCalled: []string{"fbchain-opus", "fbchain-sonnet", "fbchain-mini", "fbchain-last"},
+		},
+		{
+			name:       "rejected request is not retried on fallbacks",
+			status:     map[string]int{"fbchain-opus": 400},
+			wantStatus: 400,
+			wantCalled: []string{"fbchain-opus"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := provider.NewManager(nil, nil, nil)
+			t.Cleanup(m.Stop)
+			exec := &chainExecutor{status: tt.status}
+			m.RegisterExecutor(exec)
+			if _, err := m.Register(context.Background(), &provider.Auth{ID: "fbchain-1", Provider: "fbchain", Status: provider.StatusActive}); err != nil {
+				t.Fatalf("Register failed: %v", err)
+			}
+			h := NewBaseAPIHandlers(&config.SDKConfig{}, routing, m, nil)
+
+			ctx, c := newCacheTestContext(nil)
+			body := []byte(`{"model":"fbchain-opus","messages":[{"role":"user","content":"hi"}]}`)
+			resp, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "fbchain-opus", body, "")
+
+			if tt.wantModel != "" {
+				if errMsg != nil {
+					t.Fatalf("unexpected error: %v", errMsg.Error)
+				}
+				if want := `{"model":"` + tt.wantModel + `"}`; string(resp) != want {
+					t.Errorf("response = %s, want %s", resp, want)
+				}
+				if got := c.Writer.Header().Get(headerFallbackModel); got != tt.wantModel {
+					t.Errorf("%s = %q, want %q", headerFallbackModel, got, tt.wantModel)
+				}
+			} else {
+				if errMsg == nil || errMsg.StatusCode != tt.wantStatus {
+					t.Fatalf("error = %+v, want status %d", errMsg, tt.wantStatus)
+				}
+				if got := c.Writer.Header().Get(headerFallbackModel); got != "" {
+					t.Errorf("%s set on a failed request: %q", headerFallbackModel, got)
+				}
+			}
+			if len(exec.called) != len(tt.wantCalled) {
+				t.Fatalf("called %v, want %v", exec.called, tt.wantCalled)
+			}
+			for i := range tt.wantCalled {
+				if exec.called[i] != tt.wantCalled[i] {
+					t.Fatalf("called %v, want %v", exec.called, tt.wantCalled)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package format
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// UploadFileWithAuthManager stores file with a provider serving modelName, or
+// with any provider supporting files when modelName is empty.
+func (h *BaseAPIHandler) UploadFileWithAuthManager(ctx context.Context, modelName string, file provider.FileUpload) (provider.UploadedFile, *interfaces.ErrorMessage) {
+	var providers []string
+	normalizedModel := ""
+	if modelName != "" {
+		var errMsg *interfaces.ErrorMessage
+		providers, normalizedModel, _, errMsg = h.getRequestDetails(ctx, modelName)
+		if errMsg != nil {
+			return provider.UploadedFile{}, errMsg
+		}
+	}
+	uploaded, err := h.AuthManager.UploadFile(ctx, providers, normalizedModel, file)
+	if err != nil {
+		return provider.UploadedFile{}, h.newErrorMessage(err, providers, normalizedModel)
+	}
+	return uploaded, nil
+}
+
+// resolveFiles replaces the llm-mux file IDs referenced in rawJSON with the
+// provider references and pins ctx to the account holding those files.
+func (h *BaseAPIHandler) resolveFiles(ctx context.Context, rawJSON []byte) (context.Context, []byte, *interfaces.ErrorMessage) {
+	if h.Files == nil {
+		return ctx, rawJSON, nil
+	}
+	out, owner, err := h.Files.Resolve(rawJSON, usage.APIKeyIDFromContext(ctx))
+	if err != nil {
+		return ctx, nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: err}
+	}
+	if owner == nil {
+		return ctx, rawJSON, nil
+	}
+	return provider.WithPinnedAuth(ctx, owner.AuthID), out, nil
+}
@@ -0,0 +1,109 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+const (
+	// headerIdempotencyKey names a non-streaming request so that a retry of it
+	// gets the first response instead of a new generation.
+	headerIdempotencyKey = "Idempotency-Key"
+
+	// headerIdempotentReplayed marks a response returned for a repeated
+	// Idempotency-Key, whether kept or shared from the request in flight.
+	headerIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// setIdempotencyStore rebuilds the idempotency store when its settings change,
+// dropping every kept response. Unchanged settings keep them across reloads.
+func (h *BaseAPIHandler) setIdempotencyStore(cfg *config.SDKConfig) {
+	var want config.IdempotencyConfig
+	if cfg != nil {
+		want = cfg.Idempotency
+	}
+	h.responseCacheMu.Lock()
+	defer h.responseCacheMu.Unlock()
+	if h.idempotency != nil && h.idempotencyCfg == want {
+		return
+	}
+	h.idempotencyCfg = want
+	h.idempotency = nil
+	if ttl := want.TTLDuration(); ttl > 0 {
+		h.idempotency = newResponseStore(ttl, want.Entries())
+	}
+}
+
+func (h *BaseAPIHandler) idempotencyStore() *responseCache {
+	h.responseCacheMu.Lock()
+	defer h.responseCacheMu.Unlock()
+	return h.idempotency
+}
+
+// idempotencyKey returns the key under which the response to the request is
+// kept: its Idempotency-Key header, scoped to the endpoint and the client's
+// API key so clients cannot read each other's responses. The request body is
+// not part of the key. ok is false without the header or when idempotency is
+// disabled.
+func (h *BaseAPIHandler) idempotencyKey(ctx context.Context, handlerType string) (key [sha256.Size]byte, ok bool) {
+	if h.idempotencyStore() == nil {
+		return key, false
+	}
+	c, _ := ctx.Value(ctxKeyGin).(*gin.Context)
+	if c == nil || c.Request == nil {
+		return key, false
+	}
+	idempotencyKey := strings.TrimSpace(c.GetHeader(headerIdempotencyKey))
+	if idempotencyKey == "" {
+		return key, false
+	}
+	principal, _ := c.Get("apiKey")
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%v\x00%s", handlerType, principal, idempotencyKey)
+	hash.Sum(key[:0])
+	return key, true
+}
+
+// idempotent returns the response kept for key, or runs execute and keeps its
+// response when it succeeds. Requests with the same key that arrive while
+// execute runs wait for it and share its result. Failed requests are not
+// kept, so a retry after an error runs again.
+func (h *BaseAPIHandler) idempotent(ctx context.Context, key [sha256.Size]byte, execute func(context.Context) ([]byte, *interfaces.ErrorMessage)) ([]byte, *interfaces.ErrorMessage) {
+	store := h.idempotencyStore()
+	if store == nil {
+		return execute(ctx)
+	}
+	replay := func() ([]byte, bool) {
+		chunks, ok := store.get(key, time.Now())
+		if !ok {
+			return nil, false
+		}
+		if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+			c.Header(headerIdempotentReplayed, "true")
+		}
+		return bytes.Clone(chunks[0]), true
+	}
+	if payload, ok := replay(); ok {
+		return payload, nil
+	}
+	return h.coalesce(ctx, "idempotency\x00"+string(key[:]), headerIdempotentReplayed, func(ctx context.Context) ([]byte, *interfaces.ErrorMessage) {
+		// The previous request with this key may have completed between the
+		// lookup above and this one taking over.
+		if payload, ok := replay(); ok {
+			return payload, nil
+		}
+		payload, errMsg := execute(ctx)
+		if errMsg == nil && len(payload) <= maxCachedResponseBytes {
+			store.put(key, [][]byte{bytes.Clone(payload)}, time.Now())
+		}
+		return payload, errMsg
+	})
+}
@@ -0,0 +1,189 @@
+package format
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// newIdempotencyTestHandler serves idempotency-model through exec.
+func newIdempotencyTestHandler(t *testing.T, exec *blockingExecutor) *BaseAPIHandler {
+	t.Helper()
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("idempotency-1", "coalesce", []*registry.ModelInfo{{ID: "idempotency-model"}})
+	t.Cleanup(func() { reg.UnregisterClient("idempotency-1") })
+
+	m := provider.NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.RegisterExecutor(exec)
+	if _, err := m.Register(context.Background(), &provider.Auth{ID: "idempotency-1", Provider: "coalesce", Status: provider.StatusActive}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	return NewBaseAPIHandlers(&config.SDKConfig{}, &config.RoutingConfig{}, m, nil)
+}
+
+// Sampled output, so only the Idempotency-Key can make requests share it.
+var idempotencyBody = []byte(`{"model":"idempotency-model","temperature":0.7,"messages":[{"role":"user","content":"hi"}]}`)
+
+func TestIdempotencyKeyRetry(t *testing.T) {
+	exec := &blockingExecutor{started: make(chan struct{}), release: make(chan struct{})}
+	close(exec.release)
+	h := newIdempotencyTestHandler(t, exec)
+
+	send := func(idempotencyKey, apiKey string) (replayed bool) {
+		t.Helper()
+		headers := map[string]string{}
+		if idempotencyKey != "" {
+			headers[headerIdempotencyKey] = idempotencyKey
+		}
+		ctx, c := newCacheTestContext(headers)
+		if apiKey != "" {
+			c.Set("apiKey", apiKey)
+		}
+		resp, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "idempotency-model", idempotencyBody, "")
+		if errMsg != nil {
+			t.Fatalf("request: %v", errMsg.Error)
+		}
+		if string(resp) != `{"model":"idempotency-model"}` {
+			t.Fatalf("payload = %s", resp)
+		}
+		return c.Writer.Header().Get(headerIdempotentReplayed) == "true"
+	}
+
+	if send("retry-1", "") {
+		t.Error("first request marked as replayed")
+	}
+	if !send("retry-1", "") {
+		t.Error("retry not marked as replayed")
+	}
+	if got := exec.calls.Load(); got != 1 {
+		t.Fatalf("upstream calls after a retry = %d, want 1", got)
+	}
+
+	// Another key, another client's use of the same key, or no key at all
+	// each generate anew.
+	send("retry-2", "")
+	send("retry-1", "other-client")
+	send("", "")
+	send("", "")
+	if got := exec.calls.Load(); got != 5 {
+		t.Errorf("upstream calls = %d, want 5", got)
+	}
+
+	// Disabled, the header is ignored.
+	h.UpdateClients(&config.SDKConfig{Idempotency: config.IdempotencyConfig{Disable: true}})
+	if send("retry-1", "") {
+		t.Error("replayed with idempotency disabled")
+	}
+	if got := exec.calls.Load(); got != 6 {
+		t.Errorf("upstream calls with idempotency disabled = %d, want 6", got)
+	}
+}
+
+func TestIdempotencyKeyConcurrent(t *testing.T) {
+	exec := &blockingExecutor{started: make(chan struct{}), release: make(chan struct{})}
+	h := newIdempotencyTestHandler(t, exec)
+
+	const n = 8
+	var wg sync.WaitGroup
+	payloads := make([]string, n)
+	replayed := make([]bool, n)
+	run := func(i int) {
+		defer wg.Done()
+		ctx, c := newCacheTestContext(map[string]string{headerIdempotencyKey: "concurrent-1"})
+		resp, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "idempotency-model", idempotencyBody, "")
+		if errMsg != nil {
+			t.Errorf("request %d: %v", i, errMsg.Error)
+			return
+		}
+		payloads[i] = string(resp)
+		replayed[i] = c.Writer.Header().Get(headerIdempotentReplayed) == "true"
+	}
+
+	wg.Add(1)
+	go run(0)
+	<-exec.started
+	for i := 1; i < n; i++ {
+		wg.Add(1)
+		go run(i)
+	}
+	// Let the other requests wait on the first before it completes.
+	time.Sleep(50 * time.Millisecond)
+	close(exec.release)
+	wg.Wait()
+
+	if got := exec.calls.Load(); got != 1 {
+		t.Fatalf("upstream calls = %d, want 1", got)
+	}
+	for i := range payloads {
+		if payloads[i] != `{"model":"idempotency-model"}` {
+			t.Errorf("request %d payload = %q", i, payloads[i])
+		}
+		if replayed[i] != (i > 0) {
+			t.Errorf("request %d replayed = %v, want %v", i, replayed[i], i > 0)
+		}
+	}
+}
+
+func TestIdempotencyKeyCanceledFirstRequest(t *testing.T) {
+	exec := &blockingExecutor{started: make(chan struct{}), release: make(chan struct{})}
+	h := newIdempotencyTestHandler(t, exec)
+	headers := map[string]string{headerIdempotencyKey: "canceled-1"}
+
+	firstCtx, _ := newCacheTestContext(headers)
+	firstCtx, cancel := context.WithCancel(firstCtx)
+	firstDone := make(chan struct{})
+	go func() {
+		defer close(firstDone)
+		h.ExecuteWithAuthManager(firstCtx, "openai", "idempotency-model", idempotencyBody, "")
+	}()
+	<-exec.started
+
+	const n = 6
+	var wg sync.WaitGroup
+	payloads := make([]string, n)
+	replayed := make([]bool, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, c := newCacheTestContext(headers)
+			resp, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "idempotency-model", idempotencyBody, "")
+			if errMsg != nil {
+				t.Errorf("request %d: %v", i, errMsg.Error)
+				return
+			}
+			payloads[i] = string(resp)
+			replayed[i] = c.Writer.Header().Get(headerIdempotentReplayed) == "true"
+		}()
+	}
+	// Let the requests wait on the first, then drop its client.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-firstDone
+	// Let one waiting request take over before the upstream answers.
+	time.Sleep(50 * time.Millisecond)
+	close(exec.release)
+	wg.Wait()
+
+	if got := exec.calls.Load(); got != 2 {
+		t.Fatalf("upstream calls = %d, want 2", got)
+	}
+	var shared int
+	for i := range payloads {
+		if payloads[i] != `{"model":"idempotency-model"}` {
+			t.Errorf("request %d payload = %q", i, payloads[i])
+		}
+		if replayed[i] {
+			shared++
+		}
+	}
+	if shared != n-1 {
+		t.Errorf("replayed responses = %d, want %d", shared, n-1)
+	}
+}
@@ -0,0 +1,33 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestAppendModelAliases(t *testing.T) {
+	routing := &config.RoutingConfig{Aliases: map[string]string{
+		"fast":    "gemini-2.5-flash",
+		"smart":   "fast",
+		"missing": "not-served",
+	}}
+	routing.Init()
+	h := NewBaseAPIHandlers(&config.SDKConfig{}, routing, nil, nil)
+
+	models := h.AppendModelAliases([]map[string]any{{"id": "gemini-2.5-flash", "object": "model", "owned_by": "google"}})
+	if len(models) != 3 {
+		t.Fatalf("got %d models, want the target and two aliases: %v", len(models), models)
+	}
+	for _, m := range models[1:] {
+		if m["alias_for"] != "gemini-2.5-flash" || m["owned_by"] != "google" {
+			t.Errorf("alias entry = %v", m)
+		}
+	}
+	if models[1]["id"] != "fast" || models[2]["id"] != "smart" {
+		t.Errorf("alias ids = %v, %v", models[1]["id"], models[2]["id"])
+	}
+	if models[0]["id"] != "gemini-2.5-flash" {
+		t.Error("target entry was modified")
+	}
+}
@@ -0,0 +1,51 @@
+package openai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIModels_Capabilities(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("caps-1", "caps", []*registry.ModelInfo{
+		{ID: "caps-vision", OwnedBy: "caps", SupportedParameters: []string{"tools", "vision", "response_format"}, Thinking: &registry.ThinkingSupport{Min: 128, Max: 8192}},
+		{ID: "caps-text", OwnedBy: "caps", SupportedParameters: []string{"tools"}},
+	})
+	t.Cleanup(func() { reg.UnregisterClient("caps-1") })
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	h := NewOpenAIAPIHandler(&format.BaseAPIHandler{Cfg: &config.SDKConfig{}, Routing: &config.RoutingConfig{}})
+	h.OpenAIModels(c)
+
+	tests := []struct {
+		id                                        string
+		vision, tools, thinking, structuredOutput bool
+	}{
+		{"caps-vision", true, true, true, true},
+		{"caps-text", false, true, false, false},
+	}
+	for _, tt := range tests {
+		model := gjson.Get(w.Body.String(), `data.#(id=="`+tt.id+`")`)
+		if !model.Exists() {
+			t.Fatalf("%s missing from %s", tt.id, w.Body.String())
+		}
+		if model.Get("object").String() != "model" || model.Get("owned_by").String() != "caps" {
+			t.Errorf("%s core fields = %s", tt.id, model.Raw)
+		}
+		caps := model.Get("capabilities")
+		if caps.Get("vision").Bool() != tt.vision || caps.Get("tools").Bool() != tt.tools ||
+			caps.Get("thinking").Bool() != tt.thinking || caps.Get("structured_output").Bool() != tt.structuredOutput {
+			t.Errorf("%s capabilities = %s", tt.id, caps.Raw)
+		}
+	}
+}
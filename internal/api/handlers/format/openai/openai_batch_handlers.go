@@ -0,0 +1,140 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/batch"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/usage"
+	"github.com/tidwall/gjson"
+)
+
+// OpenAIBatchAPIHandler serves the OpenAI compatible /v1/batches endpoints.
+type OpenAIBatchAPIHandler struct {
+	*format.BaseAPIHandler
+	runner *batch.Runner
+}
+
+// NewOpenAIBatchAPIHandler creates a batch handler backed by runner.
+func NewOpenAIBatchAPIHandler(apiHandlers *format.BaseAPIHandler, runner *batch.Runner) *OpenAIBatchAPIHandler {
+	return &OpenAIBatchAPIHandler{
+		BaseAPIHandler: apiHandlers,
+		runner:         runner,
+	}
+}
+
+// BatchExecutor returns the function batch jobs use to run a single chat
+// completion through the auth manager, so quota cooldowns and fallbacks apply
+// exactly as they do for interactive requests.
+func BatchExecutor(apiHandlers *format.BaseAPIHandler) batch.ExecuteFunc {
+	return func(ctx context.Context, model string, body []byte) ([]byte, int, error) {
+		resp, errMsg := apiHandlers.ExecuteWithAuthManager(ctx, constant.OpenAI, model, body, "")
+		if errMsg == nil {
+			return resp, http.StatusOK, nil
+		}
+		err := errMsg.Error
+		if err == nil {
+			err = fmt.Errorf("%s", http.StatusText(errMsg.StatusCode))
+		}
+		return nil, errMsg.StatusCode, err
+	}
+}
+
+// CreateBatch handles POST /v1/batches. The request body is the JSONL input
+// itself, one request per line.
+func (h *OpenAIBatchAPIHandler) CreateBatch(c *gin.Context) {
+	input, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		writeBatchError(c, http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	job, err := batch.NewJob(input)
+	if err != nil {
+		writeBatchError(c, http.StatusBadRequest, fmt.Sprintf("Invalid batch input: %v", err))
+		return
+	}
+	// Items run detached from this request, so the API key scope is checked now.
+	for _, item := range job.Items {
+		if errMsg := h.AuthorizeModel(c, gjson.GetBytes(item.Body, "model").String()); errMsg != nil {
+			writeBatchError(c, errMsg.StatusCode, fmt.Sprintf("custom_id %s: %v", item.CustomID, errMsg.Error))
+			return
+		}
+	}
+	job.APIKeyID = usage.APIKeyIDFromContext(c.Request.Context())
+	view, err := h.runner.Submit(job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Failed to store batch: %v", err),
+				Type:    "server_error",
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// GetBatch handles GET /v1/batches/:id.
+func (h *OpenAIBatchAPIHandler) GetBatch(c *gin.Context) {
+	job, ok := h.runner.Get(c.Param("id"), usage.APIKeyIDFromContext(c.Request.Context()))
+	if !ok {
+		writeBatchNotFound(c)
+		return
+	}
+	c.JSON(http.StatusOK, job.View())
+}
+
+// GetBatchResults handles GET /v1/batches/:id/results, returning the finished
+// requests as JSONL in the OpenAI batch output format.
+func (h *OpenAIBatchAPIHandler) GetBatchResults(c *gin.Context) {
+	job, ok := h.runner.Get(c.Param("id"), usage.APIKeyIDFromContext(c.Request.Context()))
+	if !ok {
+		writeBatchNotFound(c)
+		return
+	}
+	c.Header("Content-Type", "application/jsonl")
+	c.Status(http.StatusOK)
+	for _, line := range job.ResultLines() {
+		data, err := json.Marshal(line)
+		if err != nil {
+			continue
+		}
+		_, _ = c.Writer.Write(data)
+		_, _ = c.Writer.Write([]byte("\n"))
+	}
+}
+
+// CancelBatch handles POST /v1/batches/:id/cancel.
+func (h *OpenAIBatchAPIHandler) CancelBatch(c *gin.Context) {
+	view, ok := h.runner.Cancel(c.Param("id"), usage.APIKeyIDFromContext(c.Request.Context()))
+	if !ok {
+		writeBatchNotFound(c)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+func writeBatchNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, format.ErrorResponse{
+		Error: format.ErrorDetail{
+			Message: fmt.Sprintf("No batch found with id '%s'", c.Param("id")),
+			Type:    "invalid_request_error",
+			Code:    "not_found",
+		},
+	})
+}
+
+func writeBatchError(c *gin.Context, status int, message string) {
+	c.JSON(status, format.ErrorResponse{
+		Error: format.ErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+		},
+	})
+}
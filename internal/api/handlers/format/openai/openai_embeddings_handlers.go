@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+)
+
+// Embeddings handles the /v1/embeddings endpoint.
+// The request is validated up front, routed to a provider that supports
+// embeddings and answered in the OpenAI embeddings response shape.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) Embeddings(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	embedReq, err := to_ir.ParseOpenAIEmbeddingRequest(rawJSON)
+	if err == nil && embedReq.Model == "" {
+		err = fmt.Errorf("model is required")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/json")
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	resp, errMsg := h.ExecuteEmbedWithAuthManager(cliCtx, h.HandlerType(), embedReq.Model, rawJSON)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
@@ -0,0 +1,203 @@
+package openai
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/files"
+	"github.com/nghyane/llm-mux/internal/misc"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// OpenAIFilesAPIHandler serves the OpenAI compatible /v1/files endpoints,
+// storing each upload with a provider's Files API.
+type OpenAIFilesAPIHandler struct {
+	*format.BaseAPIHandler
+	files *files.Registry
+}
+
+// NewOpenAIFilesAPIHandler creates a files handler backed by registry.
+func NewOpenAIFilesAPIHandler(apiHandlers *format.BaseAPIHandler, registry *files.Registry) *OpenAIFilesAPIHandler {
+	return &OpenAIFilesAPIHandler{
+		BaseAPIHandler: apiHandlers,
+		files:          registry,
+	}
+}
+
+// UploadFile handles POST /v1/files. The multipart form carries the file and
+// its purpose, optionally expires_after[seconds] and, as an llm-mux extension,
+// the model whose providers should store the file.
+func (h *OpenAIFilesAPIHandler) UploadFile(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		writeFileError(c, http.StatusBadRequest, "Invalid request: 'file' is required")
+		return
+	}
+	purpose := strings.TrimSpace(c.PostForm("purpose"))
+	if purpose == "" {
+		writeFileError(c, http.StatusBadRequest, "Invalid request: 'purpose' is required")
+		return
+	}
+	var ttl time.Duration
+	if v := c.PostForm("expires_after[seconds]"); v != "" {
+		seconds, errParse := strconv.ParseInt(v, 10, 64)
+		if errParse != nil || seconds <= 0 {
+			writeFileError(c, http.StatusBadRequest, "Invalid request: 'expires_after[seconds]' must be a positive integer")
+			return
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+	model := strings.TrimSpace(c.PostForm("model"))
+	if model != "" {
+		if errMsg := h.AuthorizeModel(c, model); errMsg != nil {
+			h.WriteErrorResponse(c, errMsg)
+			return
+		}
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		writeFileError(c, http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	data, err := io.ReadAll(src)
+	_ = src.Close()
+	if err != nil {
+		writeFileError(c, http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+
+	upload := provider.FileUpload{
+		Filename: header.Filename,
+		MimeType: uploadMimeType(header.Header.Get("Content-Type"), header.Filename),
+		Purpose:  purpose,
+		TTL:      ttl,
+		Data:     data,
+	}
+	uploaded, errMsg := h.UploadFileWithAuthManager(c.Request.Context(), model, upload)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		return
+	}
+
+	now := time.Now()
+	record := &files.File{
+		ID:         files.NewID(),
+		Filename:   upload.Filename,
+		Purpose:    purpose,
+		MimeType:   upload.MimeType,
+		Bytes:      int64(len(data)),
+		CreatedAt:  now,
+		ExpiresAt:  uploaded.ExpiresAt,
+		Provider:   uploaded.Provider,
+		AuthID:     uploaded.AuthID,
+		UpstreamID: uploaded.ID,
+		Ref:        uploaded.Ref,
+		APIKeyID:   usage.APIKeyIDFromContext(c.Request.Context()),
+	}
+	if ttl > 0 && (record.ExpiresAt.IsZero() || now.Add(ttl).Before(record.ExpiresAt)) {
+		record.ExpiresAt = now.Add(ttl)
+	}
+	if err := h.files.Add(record); err != nil {
+		c.JSON(http.StatusInternalServerError, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Failed to store file: %v", err),
+				Type:    "server_error",
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, record.View())
+}
+
+// ListFiles handles GET /v1/files, optionally filtered by ?purpose=.
+func (h *OpenAIFilesAPIHandler) ListFiles(c *gin.Context) {
+	records := h.files.List(usage.APIKeyIDFromContext(c.Request.Context()), c.Query("purpose"))
+	data := make([]files.View, 0, len(records))
+	for _, f := range records {
+		data = append(data, f.View())
+	}
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": data, "has_more": false})
+}
+
+// GetFile handles GET /v1/files/:id.
+func (h *OpenAIFilesAPIHandler) GetFile(c *gin.Context) {
+	f, ok := h.files.Get(c.Param("id"), usage.APIKeyIDFromContext(c.Request.Context()))
+	if !ok {
+		writeFileNotFound(c)
+		return
+	}
+	c.JSON(http.StatusOK, f.View())
+}
+
+// DeleteFile handles DELETE /v1/files/:id, deleting the upstream copy first.
+// A file already gone upstream, or whose account was removed, is still
+// forgotten.
+func (h *OpenAIFilesAPIHandler) DeleteFile(c *gin.Context) {
+	f, ok := h.files.Get(c.Param("id"), usage.APIKeyIDFromContext(c.Request.Context()))
+	if !ok {
+		writeFileNotFound(c)
+		return
+	}
+	if err := h.AuthManager.DeleteFile(c.Request.Context(), f.AuthID, f.UpstreamID); err != nil {
+		if se, okStatus := err.(interface{ StatusCode() int }); !okStatus || se.StatusCode() != http.StatusNotFound {
+			c.JSON(http.StatusBadGateway, format.ErrorResponse{
+				Error: format.ErrorDetail{
+					Message: fmt.Sprintf("Failed to delete file upstream: %v", err),
+					Type:    "server_error",
+				},
+			})
+			return
+		}
+	}
+	if err := h.files.Remove(f.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Failed to delete file: %v", err),
+				Type:    "server_error",
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": f.ID, "object": "file", "deleted": true})
+}
+
+// uploadMimeType returns the declared part type unless it is the generic
+// octet-stream, in which case the filename extension decides.
+func uploadMimeType(declared, filename string) string {
+	declared, _, _ = strings.Cut(declared, ";")
+	if declared = strings.TrimSpace(declared); declared != "" && declared != "application/octet-stream" {
+		return declared
+	}
+	if byExt := misc.MimeTypes[strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))]; byExt != "" {
+		return byExt
+	}
+	return "application/octet-stream"
+}
+
+func writeFileNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, format.ErrorResponse{
+		Error: format.ErrorDetail{
+			Message: fmt.Sprintf("No such File object: %s", c.Param("id")),
+			Type:    "invalid_request_error",
+			Code:    "not_found",
+		},
+	})
+}
+
+func writeFileError(c *gin.Context, status int, message string) {
+	c.JSON(status, format.ErrorResponse{
+		Error: format.ErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+		},
+	})
+}
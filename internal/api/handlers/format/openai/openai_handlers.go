@@ -2,6 +2,8 @@ package openai
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -11,6 +13,8 @@ import (
 	"github.com/nghyane/llm-mux/internal/interfaces"
 	"github.com/nghyane/llm-mux/internal/json"
 	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -56,9 +60,10 @@ func (h *OpenAIAPIHandler) Models() []map[string]any {
 
 // OpenAIModels handles the /v1/models endpoint.
 // It returns a list of available AI models with their capabilities
-// and specifications in OpenAI-compatible format.
+// and specifications in OpenAI-compatible format. The availability and
+// available_only query parameters add live availability (see format.ModelListOptions).
 func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
-	allModels := h.Models()
+	allModels := h.AppendModelAliases(registry.GetGlobalRegistry().ListModels("openai", format.ModelListOptions(c)))
 
 	// Filter to only include the 4 required fields: id, object, created, owned_by
 	filteredModels := make([]map[string]any, len(allModels))
@@ -75,6 +80,13 @@ func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 
 		// Add owned_by
 		filteredModel["owned_by"] = model["owned_by"]
+
+		// Capabilities, alias targets, and availability annotations when requested
+		for _, key := range []string{"capabilities", "alias_for", "availability", "family", "canonical_id"} {
+			if value, exists := model[key]; exists {
+				filteredModel[key] = value
+			}
+		}
 		filteredModels[i] = filteredModel
 	}
 
@@ -425,7 +437,7 @@ func (h *OpenAIAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byt
 	modelName := gjson.GetBytes(rawJSON, "model").String()
 	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
 	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, h.GetAlt(c))
-	h.handleStreamResult(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan)
+	h.handleStreamResult(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan, newStreamUsage(rawJSON))
 }
 
 // handleCompletionsNonStreamingResponse handles non-streaming completions responses.
@@ -484,6 +496,20 @@ func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, ra
 	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, chatCompletionsJSON, "")
 
 	sw := format.NewSSEWriter(c.Writer)
+	var last []byte
+	writeChunk := func(chunk []byte) {
+		if isDoneChunk(chunk) {
+			return
+		}
+		converted := convertChatCompletionsStreamChunkToCompletions(chunk)
+		if converted == nil {
+			return
+		}
+		sw.Write(sseDataPrefix)
+		sw.Write(converted)
+		sw.Write(sseNewline)
+		last = chunk
+	}
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -491,30 +517,35 @@ func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, ra
 			return
 		case chunk, isOk := <-dataChan:
 			if !isOk {
-				sw.Write(sseDoneMarker)
+				writeStreamEnd(sw, nil)
 				flusher.Flush()
 				cliCancel()
 				return
 			}
-			converted := convertChatCompletionsStreamChunkToCompletions(chunk)
-			if converted != nil {
-				sw.Write(sseDataPrefix)
-				sw.Write(converted)
-				sw.Write(sseNewline)
-				if !sw.Ok() {
-					cliCancel(sw.Err())
-					return
-				}
-				flusher.Flush()
+			writeChunk(chunk)
+			if !sw.Ok() {
+				cliCancel(sw.Err())
+				return
 			}
+			flusher.Flush()
 		case errMsg, isOk := <-errChan:
 			if !isOk {
 				continue
 			}
-			if errMsg != nil {
+			// Deliver output that was buffered before the error.
+			if !drainStream(c.Request.Context(), dataChan, writeChunk) {
+				cliCancel(c.Request.Context().Err())
+				return
+			}
+			switch {
+			case errMsg == nil:
+				writeStreamEnd(sw, nil)
+			case last != nil:
+				h.writeStreamFailure(sw, streamChunkJSON(last), errMsg, completionsErrorChunk)
+			default:
 				h.WriteErrorResponse(c, errMsg)
-				flusher.Flush()
 			}
+			flusher.Flush()
 			var execErr error
 			if errMsg != nil {
 				execErr = errMsg.Error
@@ -524,8 +555,25 @@ func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, ra
 		}
 	}
 }
-func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage, usage *streamUsage) {
 	sw := format.NewSSEWriter(c.Writer)
+	var last []byte
+	writeChunk := func(chunk []byte) {
+		if isDoneChunk(chunk) {
+			return
+		}
+		if chunk = usage.filter(chunk); chunk == nil {
+			return
+		}
+		if len(chunk) > 6 && (bytes.HasPrefix(chunk, sseEventPrefix) || bytes.HasPrefix(chunk, sseDataPrefix)) {
+			sw.Write(chunk)
+		} else {
+			sw.Write(sseDataPrefix)
+			sw.Write(chunk)
+			sw.Write(sseNewline)
+		}
+		last = chunk
+	}
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -533,18 +581,12 @@ func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flush
 			return
 		case chunk, ok := <-data:
 			if !ok {
-				sw.Write(sseDoneMarker)
+				writeStreamEnd(sw, usage)
 				flusher.Flush()
 				cancel(nil)
 				return
 			}
-			if len(chunk) > 6 && (bytes.HasPrefix(chunk, sseEventPrefix) || bytes.HasPrefix(chunk, sseDataPrefix)) {
-				sw.Write(chunk)
-			} else {
-				sw.Write(sseDataPrefix)
-				sw.Write(chunk)
-				sw.Write(sseNewline)
-			}
+			writeChunk(chunk)
 			if !sw.Ok() {
 				cancel(sw.Err())
 				return
@@ -554,10 +596,20 @@ func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flush
 			if !ok {
 				continue
 			}
-			if errMsg != nil {
+			// Deliver output that was buffered before the error.
+			if !drainStream(c.Request.Context(), data, writeChunk) {
+				cancel(c.Request.Context().Err())
+				return
+			}
+			switch {
+			case errMsg == nil:
+				writeStreamEnd(sw, usage)
+			case last != nil:
+				h.writeStreamFailure(sw, streamChunkJSON(last), errMsg, nil)
+			default:
 				h.WriteErrorResponse(c, errMsg)
-				flusher.Flush()
 			}
+			flusher.Flush()
 			var execErr error
 			if errMsg != nil {
 				execErr = errMsg.Error
@@ -567,3 +619,98 @@ func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flush
 		}
 	}
 }
+
+// drainStream writes the chunks still buffered in data after the stream
+// reported an error. data is nil when the request failed before reaching the
+// upstream. It returns false when the client went away first.
+func drainStream(ctx context.Context, data <-chan []byte, write func([]byte)) bool {
+	if data == nil {
+		return true
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case chunk, ok := <-data:
+			if !ok {
+				return true
+			}
+			write(chunk)
+		}
+	}
+}
+
+// writeStreamEnd finishes a stream that completed: the usage chunk, when the
+// client asked for one, then the [DONE] terminator. It is the only place a
+// successful stream writes [DONE]; terminators sent by the upstream are
+// dropped so it is never duplicated or followed by more data.
+func writeStreamEnd(sw *format.SSEWriter, usage *streamUsage) {
+	if final := usage.final(); final != nil {
+		sw.Write(sseDataPrefix)
+		sw.Write(final)
+		sw.Write(sseNewline)
+	}
+	sw.Write(sseDoneMarker)
+}
+
+// isDoneChunk reports whether chunk is a [DONE] terminator passed through
+// from the upstream.
+func isDoneChunk(chunk []byte) bool {
+	return bytes.Equal(streamChunkJSON(chunk), []byte("[DONE]"))
+}
+
+// writeStreamFailure ends a stream that failed upstream after output was sent.
+// With stream-error-recovery enabled it closes the stream cleanly with a final
+// chunk whose finish_reason is "error", followed by [DONE]; otherwise it emits
+// an error event and leaves the stream unterminated. convert, when set, maps
+// the chat completion chunk to the client's format.
+func (h *OpenAIAPIHandler) writeStreamFailure(sw *format.SSEWriter, last []byte, errMsg *interfaces.ErrorMessage, convert func([]byte) []byte) {
+	message := "upstream stream error"
+	if errMsg.Error != nil {
+		message = errMsg.Error.Error()
+	}
+	if h.Cfg == nil || !h.Cfg.StreamErrorRecovery {
+		sw.Write(sseDataPrefix)
+		sw.Write(format.ErrorBody(errMsg))
+		sw.Write(sseNewline)
+		return
+	}
+	final := streamErrorChunk(last, errors.New(message))
+	if convert != nil {
+		final = convert(final)
+	}
+	sw.Write(sseDataPrefix)
+	sw.Write(final)
+	sw.Write(sseNewline)
+	sw.Write(sseDoneMarker)
+}
+
+// streamErrorChunk builds a chat.completion.chunk that finishes the stream with
+// finish_reason "error", reusing the id and model of the last chunk sent.
+func streamErrorChunk(last []byte, err error) []byte {
+	root := gjson.ParseBytes(last)
+	event := ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonError, Error: err}
+	chunk, _ := from_ir.ToOpenAIChunk(event, root.Get("model").String(), root.Get("id").String(), 0)
+	return streamChunkJSON(chunk)
+}
+
+// completionsErrorChunk converts a final error chunk to the completions format,
+// keeping its error object.
+func completionsErrorChunk(chunk []byte) []byte {
+	out := convertChatCompletionsStreamChunkToCompletions(chunk)
+	if errObj := gjson.GetBytes(chunk, "error"); errObj.Exists() {
+		out, _ = sjson.SetRawBytes(out, "error", []byte(errObj.Raw))
+	}
+	return out
+}
+
+// streamChunkJSON strips SSE framing from a chunk, returning its JSON payload.
+func streamChunkJSON(chunk []byte) []byte {
+	chunk = bytes.TrimSpace(chunk)
+	if bytes.HasPrefix(chunk, sseEventPrefix) {
+		if idx := bytes.Index(chunk, []byte("\n")); idx >= 0 {
+			chunk = bytes.TrimSpace(chunk[idx+1:])
+		}
+	}
+	return bytes.TrimSpace(bytes.TrimPrefix(chunk, []byte("data:")))
+}
@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+)
+
+// Rerank handles the /v1/rerank endpoint.
+// The request uses the Cohere/Jina rerank shape and is routed to a provider
+// with native rerank support; other providers answer 501 Not Implemented.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) Rerank(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	rerankReq, err := to_ir.ParseRerankRequest(rawJSON)
+	if err == nil && rerankReq.Model == "" {
+		err = fmt.Errorf("model is required")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/json")
+	cliCtx, cliCancel := h.GetContextWithCancel(c.Request.Context(), h, c)
+	resp, errMsg := h.ExecuteRerankWithAuthManager(cliCtx, h.HandlerType(), rerankReq.Model, rawJSON)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
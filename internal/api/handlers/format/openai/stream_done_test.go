@@ -0,0 +1,143 @@
+package openai
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/tidwall/gjson"
+)
+
+const doneFrame = "data: [DONE]\n\n"
+
+func chatChunk(delta, finish string) []byte {
+	chunk := `{"id":"chatcmpl-1","object":"chat.completion.chunk","created":7,"model":"m","choices":[{"index":0,"delta":` + delta
+	if finish != "" {
+		chunk += `,"finish_reason":"` + finish + `"`
+	}
+	return []byte(chunk + `}]}`)
+}
+
+// runStream feeds chunks, then errMsg when set, through handleStreamResult and
+// returns the SSE body written to the client.
+func runStream(t *testing.T, recovery bool, request string, chunks [][]byte, errMsg *interfaces.ErrorMessage) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	h := &OpenAIAPIHandler{BaseAPIHandler: &format.BaseAPIHandler{Cfg: &config.SDKConfig{StreamErrorRecovery: recovery}}}
+
+	// Unbuffered channels keep the order of an executor that sends its output,
+	// then an error if any, and closes the data channel last.
+	data := make(chan []byte)
+	errs := make(chan *interfaces.ErrorMessage)
+	go func() {
+		defer close(data)
+		for _, chunk := range chunks {
+			data <- chunk
+		}
+		if errMsg != nil {
+			errs <- errMsg
+		}
+	}()
+	h.handleStreamResult(c, w, func(error) {}, data, errs, newStreamUsage([]byte(request)))
+	return w.Body.String()
+}
+
+func TestHandleStreamResult_DoneAfterFinish(t *testing.T) {
+	content := chatChunk(`{"role":"assistant","content":"Hi"}`, "")
+	toolCall := chatChunk(`{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"f","arguments":"{}"}}]}`, "")
+
+	for _, tc := range []struct {
+		finish string
+		first  []byte
+	}{
+		{"stop", content},
+		{"length", content},
+		{"tool_calls", toolCall},
+	} {
+		t.Run(tc.finish, func(t *testing.T) {
+			body := runStream(t, false, `{"stream":true}`, [][]byte{tc.first, chatChunk(`{}`, tc.finish)}, nil)
+
+			if !strings.HasSuffix(body, doneFrame) || strings.Count(body, "[DONE]") != 1 {
+				t.Fatalf("Expected exactly one trailing [DONE], got %q", body)
+			}
+			frames := strings.Split(strings.TrimSuffix(body, doneFrame), "\n\n")
+			last := strings.TrimPrefix(frames[len(frames)-2], "data: ")
+			if fr := gjson.Get(last, "choices.0.finish_reason").String(); fr != tc.finish {
+				t.Errorf("Expected the %s finish chunk right before [DONE], got %s", tc.finish, last)
+			}
+		})
+	}
+}
+
+func TestHandleStreamResult_UpstreamDoneNotDuplicated(t *testing.T) {
+	chunks := [][]byte{
+		chatChunk(`{"content":"Hi"}`, ""),
+		[]byte(`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":7,"model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`),
+		[]byte(doneFrame),
+	}
+	body := runStream(t, false, `{"stream":true,"stream_options":{"include_usage":true}}`, chunks, nil)
+
+	if strings.Count(body, "[DONE]") != 1 || !strings.HasSuffix(body, doneFrame) {
+		t.Fatalf("Expected the upstream [DONE] to be replaced by one trailing [DONE], got %q", body)
+	}
+	if usageAt, doneAt := strings.Index(body, `"usage"`), strings.Index(body, "[DONE]"); usageAt < 0 || usageAt > doneAt {
+		t.Errorf("Expected the usage chunk before [DONE], got %q", body)
+	}
+}
+
+func TestHandleStreamResult_ErrorFinish(t *testing.T) {
+	errMsg := &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: errors.New("connection reset by peer")}
+	partial := [][]byte{chatChunk(`{"content":"Hi"}`, "")}
+
+	t.Run("recovered error finish ends with done", func(t *testing.T) {
+		body := runStream(t, true, `{"stream":true}`, partial, errMsg)
+		if !strings.HasSuffix(body, doneFrame) || !strings.Contains(body, `"finish_reason":"error"`) {
+			t.Errorf("Expected an error finish chunk followed by [DONE], got %q", body)
+		}
+	})
+	t.Run("unrecovered error has no done", func(t *testing.T) {
+		body := runStream(t, false, `{"stream":true}`, partial, errMsg)
+		if strings.Contains(body, "[DONE]") {
+			t.Errorf("Expected no [DONE] after an unrecovered error, got %q", body)
+		}
+	})
+	t.Run("error before output has no done", func(t *testing.T) {
+		body := runStream(t, true, `{"stream":true}`, nil, errMsg)
+		if strings.Contains(body, "[DONE]") {
+			t.Errorf("Expected a plain error response without [DONE], got %q", body)
+		}
+	})
+}
+
+func TestChatCompletions_StreamUnknownModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := `{"model":"no-such-model","stream":true,"messages":[{"role":"user","content":"Hi"}]}`
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	h := NewOpenAIAPIHandler(format.NewBaseAPIHandlers(&config.SDKConfig{}, &config.RoutingConfig{}, nil, nil))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ChatCompletions(c)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ChatCompletions did not return for an unknown model")
+	}
+	if !strings.Contains(w.Body.String(), `"error"`) {
+		t.Errorf("Expected an error response, got %d %q", w.Code, w.Body.String())
+	}
+}
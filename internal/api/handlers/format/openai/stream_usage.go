@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"bytes"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+var usageKey = []byte(`"usage"`)
+
+// streamUsage moves token usage out of chat.completion.chunk frames. Like
+// OpenAI, usage is only reported when the request sets
+// stream_options.include_usage, and then in one final chunk with empty
+// choices sent just before [DONE].
+type streamUsage struct {
+	include     bool
+	usage       string
+	id          string
+	model       string
+	created     int64
+	fingerprint string
+}
+
+func newStreamUsage(rawJSON []byte) *streamUsage {
+	return &streamUsage{include: gjson.GetBytes(rawJSON, "stream_options.include_usage").Bool()}
+}
+
+// filter removes usage from chunk, remembering the latest value. It returns
+// nil when nothing of chunk is left to send.
+func (u *streamUsage) filter(chunk []byte) []byte {
+	if !bytes.Contains(chunk, usageKey) {
+		return chunk
+	}
+	if !bytes.HasPrefix(chunk, sseDataPrefix) && !bytes.HasPrefix(chunk, sseEventPrefix) {
+		return u.filterJSON(chunk)
+	}
+	var out []byte
+	for _, frame := range bytes.SplitAfter(chunk, sseNewline) {
+		data := bytes.TrimSpace(frame)
+		if !bytes.HasPrefix(data, []byte("data:")) || !bytes.Contains(data, usageKey) {
+			out = append(out, frame...)
+			continue
+		}
+		if filtered := u.filterJSON(bytes.TrimSpace(data[5:])); filtered != nil {
+			out = append(out, sseDataPrefix...)
+			out = append(out, filtered...)
+			out = append(out, sseNewline...)
+		}
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil
+	}
+	return out
+}
+
+func (u *streamUsage) filterJSON(data []byte) []byte {
+	root := gjson.ParseBytes(data)
+	usage := root.Get("usage")
+	if !usage.IsObject() {
+		return data
+	}
+	u.usage = usage.Raw
+	u.id = root.Get("id").String()
+	u.model = root.Get("model").String()
+	u.created = root.Get("created").Int()
+	if fp := root.Get("system_fingerprint").String(); fp != "" {
+		u.fingerprint = fp
+	}
+	if len(root.Get("choices").Array()) == 0 {
+		return nil
+	}
+	out, err := sjson.DeleteBytes(data, "usage")
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// final returns the usage chunk JSON to send before [DONE], or nil when the
+// client did not ask for usage or none was reported.
+func (u *streamUsage) final() []byte {
+	if u == nil || !u.include || u.usage == "" {
+		return nil
+	}
+	out := []byte(`{"id":"","object":"chat.completion.chunk","created":0,"model":"","choices":[]}`)
+	out, _ = sjson.SetBytes(out, "id", u.id)
+	out, _ = sjson.SetBytes(out, "created", u.created)
+	out, _ = sjson.SetBytes(out, "model", u.model)
+	if u.fingerprint != "" {
+		out, _ = sjson.SetBytes(out, "system_fingerprint", u.fingerprint)
+	}
+	out, _ = sjson.SetRawBytes(out, "usage", []byte(u.usage))
+	return out
+}
@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestStreamUsage_IncludeUsage(t *testing.T) {
+	// Claude and Gemini streams carry usage on the finish chunk; OpenAI
+	// upstreams send it in a trailing chunk with empty choices.
+	finishWithUsage := [][]byte{
+		[]byte("data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":7,\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"Hi\"}}]}\n\n"),
+		[]byte("data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":7,\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":1,\"total_tokens\":4}}\n\n"),
+	}
+	trailingUsage := [][]byte{
+		[]byte(`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":7,"model":"m","choices":[{"index":0,"delta":{"content":"Hi"}}]}`),
+		[]byte(`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":7,"model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`),
+		[]byte(`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":7,"model":"m","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`),
+	}
+
+	for _, tc := range []struct {
+		name    string
+		request string
+		chunks  [][]byte
+	}{
+		{"finish usage included", `{"stream":true,"stream_options":{"include_usage":true}}`, finishWithUsage},
+		{"finish usage omitted", `{"stream":true}`, finishWithUsage},
+		{"trailing usage included", `{"stream":true,"stream_options":{"include_usage":true}}`, trailingUsage},
+		{"trailing usage disabled", `{"stream":true,"stream_options":{"include_usage":false}}`, trailingUsage},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			u := newStreamUsage([]byte(tc.request))
+			var sent [][]byte
+			for _, chunk := range tc.chunks {
+				if out := u.filter(chunk); out != nil {
+					sent = append(sent, out)
+				}
+			}
+			if len(sent) != 2 {
+				t.Fatalf("sent %d chunks, want content and finish only", len(sent))
+			}
+			for _, chunk := range sent {
+				if bytes.Contains(chunk, usageKey) {
+					t.Errorf("chunk still carries usage: %s", chunk)
+				}
+			}
+			if fr := gjson.GetBytes(streamChunkJSON(sent[1]), "choices.0.finish_reason").String(); fr != "stop" {
+				t.Errorf("finish chunk lost finish_reason: %s", sent[1])
+			}
+
+			final := u.final()
+			if !u.include {
+				if final != nil {
+					t.Errorf("usage chunk sent without include_usage: %s", final)
+				}
+				return
+			}
+			root := gjson.ParseBytes(final)
+			if !root.Get("choices").IsArray() || len(root.Get("choices").Array()) != 0 {
+				t.Errorf("usage chunk choices = %s, want []", root.Get("choices").Raw)
+			}
+			if root.Get("usage.total_tokens").Int() != 4 || root.Get("id").String() != "chatcmpl-1" || root.Get("model").String() != "m" {
+				t.Errorf("usage chunk = %s", final)
+			}
+		})
+	}
+}
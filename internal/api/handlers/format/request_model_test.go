@@ -0,0 +1,60 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestRequestDetailsModelErrors(t *testing.T) {
+	h := NewBaseAPIHandlers(&config.SDKConfig{}, &config.RoutingConfig{}, nil, nil)
+
+	tests := map[string]struct {
+		model string
+		want  string
+	}{
+		"empty":      {"", "model is required"},
+		"whitespace": {"  \t", "model is required: the model field contains only whitespace"},
+		"unknown":    {"no-such-model", `unknown model "no-such-model"`},
+	}
+	for name, tt := range tests {
+		_, _, _, errMsg := h.getRequestDetails(context.Background(), tt.model)
+		if errMsg == nil {
+			t.Errorf("%s: no error", name)
+			continue
+		}
+		if errMsg.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, errMsg.StatusCode)
+		}
+		if got := errMsg.Error.Error(); got != tt.want && !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%s: error = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestRequestDetailsDefaultModel(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("default-model-test", "gemini", []*registry.ModelInfo{{ID: "default-model-test-flash", Object: "model"}})
+	t.Cleanup(func() { reg.UnregisterClient("default-model-test") })
+
+	routing := &config.RoutingConfig{
+		DefaultModel: "fast",
+		Aliases:      map[string]string{"fast": "default-model-test-flash"},
+	}
+	routing.Init()
+	h := NewBaseAPIHandlers(&config.SDKConfig{}, routing, nil, nil)
+
+	for _, model := range []string{"", "   "} {
+		providers, normalized, _, errMsg := h.getRequestDetails(context.Background(), model)
+		if errMsg != nil {
+			t.Fatalf("model %q: %v", model, errMsg.Error)
+		}
+		if normalized != "default-model-test-flash" || len(providers) != 1 || providers[0] != "gemini" {
+			t.Errorf("model %q: got %s via %v, want the default model through its alias", model, normalized, providers)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package format
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/metrics"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// headerResponseCache reports "hit" or "miss" on cacheable requests. A
+	// client sends it with "off" to skip the cache for a single request.
+	headerResponseCache = "X-LLM-Mux-Cache"
+
+	// maxCachedResponseBytes caps a single cached response so a few long
+	// outputs cannot hold much memory.
+	maxCachedResponseBytes = 4 << 20
+)
+
+// temperaturePaths and seedPaths locate sampling settings in the request
+// formats served by the handlers: OpenAI and Claude at the top level, Gemini
+// under generationConfig and Ollama under options.
+var (
+	temperaturePaths = []string{"temperature", "generationConfig.temperature", "options.temperature"}
+	seedPaths        = []string{"seed", "generationConfig.seed", "options.seed"}
+)
+
+// responseCache keeps complete upstream responses for deterministic
+// requests. A non-streaming response is one chunk; a streamed response keeps
+// every chunk so it can be replayed as a stream. Entries expire after ttl; the
+// least recently used is evicted once maxEntries is reached.
+type responseCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // front is most recently used
+}
+
+type responseEntry struct {
+	key     [sha256.Size]byte
+	chunks  [][]byte
+	expires time.Time
+}
+
+func newResponseCache(cfg config.ResponseCacheConfig) *responseCache {
+	ttl := cfg.TTLDuration()
+	if ttl <= 0 {
+		return nil
+	}
+	return newResponseStore(ttl, cfg.Entries())
+}
+
+// newResponseStore returns an empty responseCache. The idempotency store uses
+// the same structure with its own settings.
+func newResponseStore(ttl time.Duration, maxEntries int) *responseCache {
+	return &responseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[[sha256.Size]byte]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *responseCache) get(key [sha256.Size]byte, now time.Time) ([][]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*responseEntry)
+	if now.After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.chunks, true
+}
+
+func (c *responseCache) put(key [sha256.Size]byte, chunks [][]byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*responseEntry)
+		entry.chunks = chunks
+		entry.expires = now.Add(c.ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&responseEntry{key: key, chunks: chunks, expires: now.Add(c.ttl)})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*responseEntry).key)
+	}
+}
+
+// setResponseCache rebuilds the cache when its settings change, dropping
+// every entry. Unchanged settings keep the cached responses across reloads.
+func (h *BaseAPIHandler) setResponseCache(cfg *config.SDKConfig) {
+	var want config.ResponseCacheConfig
+	if cfg != nil {
+		want = cfg.ResponseCache
+	}
+	h.responseCacheMu.Lock()
+	defer h.responseCacheMu.Unlock()
+	if h.responses != nil && h.responseCacheCfg == want {
+		return
+	}
+	h.responseCacheCfg = want
+	h.responses = newResponseCache(want)
+}
+
+func (h *BaseAPIHandler) responseCacheStore() *responseCache {
+	h.responseCacheMu.Lock()
+	defer h.responseCacheMu.Unlock()
+	return h.responses
+}
+
+// deterministicRequest reports whether the request asks for reproducible
+// output: an explicit temperature of 0 or a seed.
+func deterministicRequest(rawJSON []byte) bool {
+	for _, path := range seedPaths {
+		if seed := gjson.GetBytes(rawJSON, path); seed.Exists() && seed.Type != gjson.Null {
+			return true
+		}
+	}
+	for _, path := range temperaturePaths {
+		if temp := gjson.GetBytes(rawJSON, path); temp.Type == gjson.Number && temp.Float() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// responseCacheBypassed reports whether the client asked to skip the cache
+// with "X-LLM-Mux-Cache: off" or "Cache-Control: no-cache" / "no-store".
+func responseCacheBypassed(c *gin.Context) bool {
+	if c == nil || c.Request == nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(c.GetHeader(headerResponseCache))) {
+	case "off", "0", "false", "no", "bypass":
+		return true
+	}
+	cacheControl := strings.ToLower(c.GetHeader("Cache-Control"))
+	return strings.Contains(cacheControl, "no-cache") || strings.Contains(cacheControl, "no-store")
+}
+
+// cachedResponse looks the request up in the response cache. It reports
+// cacheable=false when the cache is disabled, the request is not
+// deterministic or the client bypassed the cache; otherwise key identifies the
+// request for a later store and chunks holds the cached response on a hit.
+func (h *BaseAPIHandler) cachedResponse(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string, stream bool) (key [sha256.Size]byte, chunks [][]byte, cacheable bool) {
+	cache := h.responseCacheStore()
+	if cache == nil || !deterministicRequest(rawJSON) {
+		return key, nil, false
+	}
+	c, _ := ctx.Value(ctxKeyGin).(*gin.Context)
+	if responseCacheBypassed(c) {
+		return key, nil, false
+	}
+
+	key = requestKey(handlerType, modelName, rawJSON, alt, stream)
+	chunks, hit := cache.get(key, time.Now())
+	result := "miss"
+	if hit {
+		result = "hit"
+		if h.AuthManager != nil {
+			h.AuthManager.RecordCacheHit(ctx, modelName)
+		}
+	}
+	metrics.ResponseCacheLookups.Inc(metrics.L(result))
+	if c != nil {
+		c.Header(headerResponseCache, result)
+	}
+	return key, chunks, true
+}
+
+// requestKey identifies a request by endpoint, model, body and whether it
+// streams.
+func requestKey(handlerType, modelName string, rawJSON []byte, alt string, stream bool) (key [sha256.Size]byte) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%t\x00", handlerType, modelName, alt, stream)
+	hash.Write(rawJSON)
+	hash.Sum(key[:0])
+	return key
+}
+
+// storeResponse caches a complete response unless it exceeds
+// maxCachedResponseBytes. The chunks must not be modified afterwards.
+func (h *BaseAPIHandler) storeResponse(key [sha256.Size]byte, chunks [][]byte) {
+	cache := h.responseCacheStore()
+	if cache == nil || len(chunks) == 0 {
+		return
+	}
+	size := 0
+	for _, chunk := range chunks {
+		size += len(chunk)
+	}
+	if size > maxCachedResponseBytes {
+		return
+	}
+	cache.put(key, chunks, time.Now())
+}
+
+// replayStream serves a cached streamed response as a synthetic stream.
+func replayStream(chunks [][]byte) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	dataChan := make(chan []byte, len(chunks))
+	errChan := make(chan *interfaces.ErrorMessage)
+	for _, chunk := range chunks {
+		dataChan <- bytes.Clone(chunk)
+	}
+	close(dataChan)
+	close(errChan)
+	return dataChan, errChan
+}
@@ -0,0 +1,126 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestDeterministicRequest(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{`{"temperature":0}`, true},
+		{`{"temperature":0.0,"messages":[]}`, true},
+		{`{"temperature":0.7}`, false},
+		{`{"seed":42,"temperature":1}`, true},
+		{`{"seed":null}`, false},
+		{`{"generationConfig":{"temperature":0}}`, true},
+		{`{"options":{"seed":7}}`, true},
+		{`{"messages":[{"role":"user","content":"temperature 0"}]}`, false},
+		{`{}`, false},
+	}
+	for _, tt := range tests {
+		if got := deterministicRequest([]byte(tt.body)); got != tt.want {
+			t.Errorf("deterministicRequest(%s) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func newCacheTestContext(headers map[string]string) (context.Context, *gin.Context) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	for k, v := range headers {
+		c.Request.Header.Set(k, v)
+	}
+	return context.WithValue(context.Background(), ctxKeyGin, c), c
+}
+
+func TestResponseCacheLookup(t *testing.T) {
+	h := NewBaseAPIHandlers(&config.SDKConfig{ResponseCache: config.ResponseCacheConfig{Enable: true}}, nil, nil, nil)
+	body := []byte(`{"model":"m","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)
+
+	ctx, c := newCacheTestContext(nil)
+	key, cached, cacheable := h.cachedResponse(ctx, "openai", "m", body, "", false)
+	if !cacheable || cached != nil || c.Writer.Header().Get(headerResponseCache) != "miss" {
+		t.Fatalf("first lookup: cacheable=%v cached=%q header=%q", cacheable, cached, c.Writer.Header().Get(headerResponseCache))
+	}
+	h.storeResponse(key, [][]byte{[]byte(`{"id":"1"}`)})
+
+	ctx, c = newCacheTestContext(nil)
+	_, cached, _ = h.cachedResponse(ctx, "openai", "m", body, "", false)
+	if len(cached) != 1 || string(cached[0]) != `{"id":"1"}` || c.Writer.Header().Get(headerResponseCache) != "hit" {
+		t.Fatalf("second lookup: cached=%q header=%q", cached, c.Writer.Header().Get(headerResponseCache))
+	}
+	if _, cached, _ = h.cachedResponse(ctx, "openai", "m", body, "", true); cached != nil {
+		t.Error("non-streaming response served to a streaming request")
+	}
+
+	for _, headers := range []map[string]string{{headerResponseCache: "off"}, {"Cache-Control": "no-cache"}} {
+		ctx, _ = newCacheTestContext(headers)
+		if _, _, cacheable = h.cachedResponse(ctx, "openai", "m", body, "", false); cacheable {
+			t.Errorf("cache not bypassed with %v", headers)
+		}
+	}
+	ctx, _ = newCacheTestContext(nil)
+	if _, _, cacheable = h.cachedResponse(ctx, "openai", "m", []byte(`{"temperature":1}`), "", false); cacheable {
+		t.Error("sampled request treated as cacheable")
+	}
+
+	h.UpdateClients(&config.SDKConfig{ResponseCache: config.ResponseCacheConfig{Enable: true}})
+	if _, cached, _ = h.cachedResponse(ctx, "openai", "m", body, "", false); cached == nil {
+		t.Error("reload with unchanged settings dropped the cache")
+	}
+	h.UpdateClients(&config.SDKConfig{})
+	if _, _, cacheable = h.cachedResponse(ctx, "openai", "m", body, "", false); cacheable {
+		t.Error("cache still active after it was disabled")
+	}
+}
+
+func TestWrapStreamChannelRecordsCompleteStream(t *testing.T) {
+	h := NewBaseAPIHandlers(&config.SDKConfig{}, nil, nil, nil)
+	upstream := make(chan provider.StreamChunk, 3)
+	upstream <- provider.StreamChunk{Payload: []byte("data: a\n\n")}
+	upstream <- provider.StreamChunk{Payload: []byte("data: b\n\n")}
+	close(upstream)
+
+	var recorded [][]byte
+	data, errs := h.wrapStreamChannel(context.Background(), upstream, func(chunks [][]byte) { recorded = chunks }, nil)
+	for range data {
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(recorded) != 2 || string(recorded[1]) != "data: b\n\n" {
+		t.Fatalf("recorded = %q", recorded)
+	}
+
+	data, errs = replayStream(recorded)
+	var replayed []string
+	for chunk := range data {
+		replayed = append(replayed, string(chunk))
+	}
+	if _, ok := <-errs; ok || len(replayed) != 2 || replayed[0] != "data: a\n\n" {
+		t.Fatalf("replayed = %q", replayed)
+	}
+
+	failing := make(chan provider.StreamChunk, 2)
+	failing <- provider.StreamChunk{Payload: []byte("data: a\n\n")}
+	failing <- provider.StreamChunk{Err: context.DeadlineExceeded}
+	close(failing)
+	recorded = nil
+	data, errs = h.wrapStreamChannel(context.Background(), failing, func(chunks [][]byte) { recorded = chunks }, nil)
+	for range data {
+	}
+	<-errs
+	if recorded != nil {
+		t.Fatalf("failed stream was recorded: %q", recorded)
+	}
+}
@@ -0,0 +1,98 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+	"github.com/tidwall/gjson"
+)
+
+// headerSessionID identifies a conversation across requests for the thought
+// signature store.
+const headerSessionID = "X-Session-ID"
+
+// startThoughtSignatures returns a thought signature session for the request,
+// or nil when the store is disabled or the request cannot be tied to a
+// conversation.
+func (h *BaseAPIHandler) startThoughtSignatures(ctx context.Context, handlerType string, rawJSON []byte) (context.Context, *stream.ThoughtSignatureSession) {
+	if h.Cfg == nil || !h.Cfg.ThoughtSignatures.Enabled {
+		return ctx, nil
+	}
+	var sessionID string
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil && c.Request != nil {
+		sessionID = strings.TrimSpace(c.GetHeader(headerSessionID))
+	}
+	responses := handlerType == constant.OpenaiResponse
+	var previous string
+	if responses {
+		previous = gjson.GetBytes(rawJSON, "previous_response_id").String()
+	}
+	sigs := stream.NewThoughtSignatureSession(h.thoughtSignatures, h.Cfg.ThoughtSignatures.TTLDuration(), sessionID, previous, responses)
+	return stream.WithThoughtSignatures(ctx, sigs), sigs
+}
+
+// attachThoughtSignatures exposes the session to request translation.
+func attachThoughtSignatures(sigs *stream.ThoughtSignatureSession, req *provider.Request, opts *provider.Options) {
+	if sigs == nil {
+		return
+	}
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]any, 1)
+		opts.Metadata = req.Metadata
+	}
+	req.Metadata[stream.ThoughtSignatureMetadataKey] = sigs
+}
+
+// commitThoughtSignatures saves the signatures of a Responses API response
+// under its ID for requests continuing it through previous_response_id.
+func commitThoughtSignatures(sigs *stream.ThoughtSignatureSession, handlerType string, response []byte) {
+	if sigs == nil || handlerType != constant.OpenaiResponse {
+		return
+	}
+	sigs.Commit(gjson.GetBytes(response, "id").String())
+}
+
+// thoughtSignaturesObserver returns a stream observer that commits the
+// signatures once the completed Responses API response arrives, or nil when
+// nothing needs committing.
+func thoughtSignaturesObserver(sigs *stream.ThoughtSignatureSession, handlerType string) func([]byte) {
+	if sigs == nil || handlerType != constant.OpenaiResponse {
+		return nil
+	}
+	return func(chunk []byte) {
+		data := chunk
+		if i := bytes.Index(data, []byte("data:")); i >= 0 {
+			data = bytes.TrimSpace(data[i+5:])
+		}
+		if gjson.GetBytes(data, "type").String() != "response.completed" {
+			return
+		}
+		sigs.Commit(gjson.GetBytes(data, "response.id").String())
+	}
+}
+
+// chainObservers returns an observer calling each non-nil observer in turn.
+func chainObservers(observers ...func([]byte)) func([]byte) {
+	var set []func([]byte)
+	for _, o := range observers {
+		if o != nil {
+			set = append(set, o)
+		}
+	}
+	switch len(set) {
+	case 0:
+		return nil
+	case 1:
+		return set[0]
+	}
+	return func(chunk []byte) {
+		for _, o := range set {
+			o(chunk)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/metrics"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// headerToolLoopGuard is set to "stopped" on responses produced by the
+	// tool loop guard instead of an upstream.
+	headerToolLoopGuard = "X-LLM-Mux-Tool-Guard"
+
+	// maxToolRoundEntries bounds the Responses API round counts kept for
+	// previous_response_id lookups.
+	maxToolRoundEntries = 10000
+)
+
+// toolRoundStore remembers how many consecutive tool-call rounds led up to
+// each Responses API response, so a request that continues a conversation
+// through previous_response_id is counted from where the last one stopped.
+type toolRoundStore struct {
+	mu      sync.Mutex
+	entries map[string]toolRoundEntry
+}
+
+type toolRoundEntry struct {
+	rounds  int
+	expires time.Time
+}
+
+func newToolRoundStore() *toolRoundStore {
+	return &toolRoundStore{entries: make(map[string]toolRoundEntry)}
+}
+
+func (s *toolRoundStore) get(responseID string, now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[responseID]
+	if !ok {
+		return 0
+	}
+	if now.After(entry.expires) {
+		delete(s.entries, responseID)
+		return 0
+	}
+	return entry.rounds
+}
+
+func (s *toolRoundStore) put(responseID string, rounds int, ttl time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[responseID]; !ok && len(s.entries) >= maxToolRoundEntries {
+		for id, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, id)
+			}
+		}
+		for id := range s.entries {
+			if len(s.entries) < maxToolRoundEntries {
+				break
+			}
+			delete(s.entries, id)
+		}
+	}
+	s.entries[responseID] = toolRoundEntry{rounds: rounds, expires: now.Add(ttl)}
+}
+
+// toolLoopGuard returns the guard settings, or false when it is disabled.
+func (h *BaseAPIHandler) toolLoopGuard() (config.ToolLoopGuardConfig, bool) {
+	if h.Cfg == nil || !h.Cfg.ToolLoopGuard.Enabled() {
+		return config.ToolLoopGuardConfig{}, false
+	}
+	return h.Cfg.ToolLoopGuard, true
+}
+
+// checkToolLoop counts the consecutive tool-call rounds of the conversation
+// in rawJSON and reports whether the guard stops it. rounds is -1 when the
+// guard is disabled.
+func (h *BaseAPIHandler) checkToolLoop(ctx context.Context, handlerType string, rawJSON []byte) (rounds int, stop bool) {
+	guard, ok := h.toolLoopGuard()
+	if !ok {
+		return -1, false
+	}
+	rounds, continued := toolCallRounds(handlerType, rawJSON)
+	if continued && handlerType == constant.OpenaiResponse {
+		if prev := gjson.GetBytes(rawJSON, "previous_response_id").String(); prev != "" {
+			rounds += h.toolRounds.get(prev, time.Now())
+		}
+	}
+	if rounds < guard.MaxRounds {
+		return rounds, false
+	}
+	log.Warnf("tool loop guard: stopping conversation after %d consecutive tool-call rounds", rounds)
+	metrics.ToolLoopGuardStops.Inc(metrics.Labels{})
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Header(headerToolLoopGuard, "stopped")
+	}
+	return rounds, true
+}
+
+// trackToolRounds records the round count reached by a Responses API
+// response for later previous_response_id lookups. A response that calls
+// tools adds a round.
+func (h *BaseAPIHandler) trackToolRounds(handlerType string, rounds int, response []byte) {
+	if rounds < 0 || handlerType != constant.OpenaiResponse {
+		return
+	}
+	guard, ok := h.toolLoopGuard()
+	if !ok {
+		return
+	}
+	id := gjson.GetBytes(response, "id").String()
+	if id == "" {
+		return
+	}
+	if gjson.GetBytes(response, `output.#(type=="function_call")`).Exists() {
+		rounds++
+	}
+	h.toolRounds.put(id, rounds, guard.TTLDuration(), time.Now())
+}
+
+// toolRoundsObserver returns a stream observer that tracks the completed
+// Responses API response, or nil when nothing needs tracking.
+func (h *BaseAPIHandler) toolRoundsObserver(handlerType string, rounds int) func([]byte) {
+	if rounds < 0 || handlerType != constant.OpenaiResponse {
+		return nil
+	}
+	return func(chunk []byte) {
+		data := chunk
+		if i := bytes.Index(data, []byte("data:")); i >= 0 {
+			data = bytes.TrimSpace(data[i+5:])
+		}
+		if gjson.GetBytes(data, "type").String() != "response.completed" {
+			return
+		}
+		h.trackToolRounds(handlerType, rounds, []byte(gjson.GetBytes(data, "response").Raw))
+	}
+}
+
+// toolCallRounds counts the consecutive assistant tool-call rounds at the end
+// of a request, back to the last user message or assistant answer without
+// tool calls. Tool results do not end a streak. continued reports that the
+// request holds no such message, as when a Responses API request only sends
+// tool outputs for previous_response_id.
+func toolCallRounds(handlerType string, rawJSON []byte) (rounds int, continued bool) {
+	switch handlerType {
+	case constant.OpenAI:
+		return countRounds(gjson.GetBytes(rawJSON, "messages").Array(), openAIRound)
+	case constant.Claude:
+		return countRounds(gjson.GetBytes(rawJSON, "messages").Array(), claudeRound)
+	case constant.Gemini:
+		return countRounds(gjson.GetBytes(rawJSON, "contents").Array(), geminiRound)
+	case constant.GeminiCLI:
+		return countRounds(gjson.GetBytes(rawJSON, "request.contents").Array(), geminiRound)
+	case constant.OpenaiResponse:
+		input := gjson.GetBytes(rawJSON, "input")
+		if !input.IsArray() {
+			return 0, !input.Exists()
+		}
+		return responsesRounds(input.Array())
+	}
+	return 0, false
+}
+
+// roundKind classifies a conversation turn for round counting.
+type roundKind int
+
+const (
+	turnSkip     roundKind = iota // system prompts and tool results
+	turnToolCall                  // an assistant turn calling tools
+	turnBoundary                  // a user message or a plain assistant answer
+)
+
+func countRounds(turns []gjson.Result, classify func(gjson.Result) roundKind) (rounds int, continued bool) {
+	for i := len(turns) - 1; i >= 0; i-- {
+		switch classify(turns[i]) {
+		case turnToolCall:
+			rounds++
+		case turnBoundary:
+			return rounds, false
+		}
+	}
+	return rounds, true
+}
+
+func openAIRound(msg gjson.Result) roundKind {
+	switch msg.Get("role").String() {
+	case "user":
+		return turnBoundary
+	case "assistant":
+		if len(msg.Get("tool_calls").Array()) > 0 || msg.Get("function_call").Exists() {
+			return turnToolCall
+		}
+		return turnBoundary
+	}
+	return turnSkip
+}
+
+func claudeRound(msg gjson.Result) roundKind {
+	content := msg.Get("content")
+	switch msg.Get("role").String() {
+	case "user":
+		if content.Get(`#(type=="tool_result")`).Exists() {
+			return turnSkip
+		}
+		return turnBoundary
+	case "assistant":
+		if content.Get(`#(type=="tool_use")`).Exists() {
+			return turnToolCall
+		}
+		return turnBoundary
+	}
+	return turnSkip
+}
+
+func geminiRound(content gjson.Result) roundKind {
+	parts := content.Get("parts")
+	switch content.Get("role").String() {
+	case "model":
+		if parts.Get("#.functionCall").Get("#").Int() > 0 {
+			return turnToolCall
+		}
+		return turnBoundary
+	case "function":
+		return turnSkip
+	}
+	if parts.Get("#.functionResponse").Get("#").Int() > 0 {
+		return turnSkip
+	}
+	return turnBoundary
+}
+
+// responsesRounds counts rounds in Responses API input items, where the
+// parallel calls of one round are consecutive function_call items, possibly
+// preceded by the assistant message and reasoning of the same response.
+func responsesRounds(items []gjson.Result) (rounds int, continued bool) {
+	inCalls := false
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		switch item.Get("type").String() {
+		case "function_call", "custom_tool_call":
+			if !inCalls {
+				rounds++
+			}
+			inCalls = true
+			continue
+		case "reasoning":
+			continue
+		case "", "message":
+			switch item.Get("role").String() {
+			case "user":
+				return rounds, false
+			case "assistant":
+				if !inCalls {
+					return rounds, false
+				}
+				continue
+			}
+		}
+		inCalls = false
+	}
+	return rounds, true
+}
+
+// toolLoopStopResponse builds the assistant answer that replaces an upstream
+// response once the guard stops a conversation, in the handler's format.
+func (h *BaseAPIHandler) toolLoopStopResponse(handlerType, modelName string) ([]byte, error) {
+	guard, _ := h.toolLoopGuard()
+	return stream.TranslateResponseNonStream(nil, provider.FormatOpenAI, provider.FromString(handlerType), nil, toolLoopStopCompletion(modelName, guard.Note()), modelName)
+}
+
+// toolLoopStopStream is toolLoopStopResponse for streaming requests.
+func (h *BaseAPIHandler) toolLoopStopStream(handlerType, modelName string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	guard, _ := h.toolLoopGuard()
+	chunks, err := toolLoopStopChunks(handlerType, modelName, guard.Note())
+	if err != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- &interfaces.ErrorMessage{StatusCode: 500, Error: err}
+		close(errChan)
+		return nil, errChan
+	}
+	return replayStream(chunks)
+}
+
+func toolLoopStopChunks(handlerType, modelName, note string) ([][]byte, error) {
+	if handlerType == constant.OpenaiResponse {
+		return responsesStopEvents(modelName, note)
+	}
+	created := time.Now().Unix()
+	id := fmt.Sprintf("chatcmpl-guard-%d", time.Now().UnixNano())
+	lines := []map[string]any{
+		{"id": id, "object": "chat.completion.chunk", "created": created, "model": modelName,
+			"choices": []any{map[string]any{"index": 0, "delta": map[string]any{"role": "assistant", "content": note}}}},
+		{"id": id, "object": "chat.completion.chunk", "created": created, "model": modelName,
+			"choices": []any{map[string]any{"index": 0, "delta": map[string]any{}, "finish_reason": "stop"}}},
+	}
+	processor := stream.NewOpenAIStreamProcessor(nil, provider.FromString(handlerType), modelName, id)
+	var chunks [][]byte
+	for _, line := range lines {
+		payload, err := json.Marshal(line)
+		if err != nil {
+			return nil, err
+		}
+		out, _, err := processor.ProcessLine(append([]byte("data: "), payload...))
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, out...)
+	}
+	out, err := processor.ProcessDone()
+	if err != nil {
+		return nil, err
+	}
+	return append(chunks, out...), nil
+}
+
+// responsesStopEvents streams the guard answer as Responses API events. The
+// stream translators do not produce this format, so the events are built from
+// the non-streaming response.
+func responsesStopEvents(modelName, note string) ([][]byte, error) {
+	completed, err := stream.TranslateResponseNonStream(nil, provider.FormatOpenAI, provider.FromString(constant.OpenaiResponse), nil, toolLoopStopCompletion(modelName, note), modelName)
+	if err != nil {
+		return nil, err
+	}
+	response := gjson.ParseBytes(completed)
+	inProgress := map[string]any{
+		"id": response.Get("id").String(), "object": "response", "created_at": response.Get("created_at").Int(),
+		"status": "in_progress", "model": modelName, "output": []any{},
+	}
+	events := []struct {
+		name string
+		data any
+	}{
+		{"response.created", map[string]any{"type": "response.created", "response": inProgress}},
+		{"response.output_text.delta", map[string]any{"type": "response.output_text.delta", "item_id": response.Get("output.0.id").String(), "output_index": 0, "content_index": 0, "delta": note}},
+		{"response.completed", map[string]any{"type": "response.completed", "response": json.RawMessage(completed)}},
+	}
+	chunks := make([][]byte, 0, 2*len(events))
+	for _, event := range events {
+		data, err := json.Marshal(event.data)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, []byte("event: "+event.name), append([]byte("data: "), data...))
+	}
+	return chunks, nil
+}
+
+// toolLoopStopCompletion is the guard answer as an OpenAI chat completion.
+func toolLoopStopCompletion(modelName, note string) []byte {
+	completion, _ := json.Marshal(map[string]any{
+		"id":      fmt.Sprintf("chatcmpl-guard-%d", time.Now().UnixNano()),
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   modelName,
+		"choices": []any{map[string]any{
+			"index":         0,
+			"message":       map[string]any{"role": "assistant", "content": note},
+			"finish_reason": "stop",
+		}},
+		"usage": map[string]any{"prompt_tokens": 0, "completion_tokens": 0, "total_tokens": 0},
+	})
+	return completion
+}
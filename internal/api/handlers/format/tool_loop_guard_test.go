@@ -0,0 +1,172 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	"github.com/tidwall/gjson"
+)
+
+func TestToolCallRounds(t *testing.T) {
+	tests := []struct {
+		name          string
+		handlerType   string
+		body          string
+		wantRounds    int
+		wantContinued bool
+	}{
+		{
+			name:        "openai",
+			handlerType: constant.OpenAI,
+			body: `{"messages":[{"role":"system","content":"s"},{"role":"user","content":"go"},
+				{"role":"assistant","tool_calls":[{"id":"1"}]},{"role":"tool","content":"r"},
+				{"role":"assistant","content":"x","tool_calls":[{"id":"2"},{"id":"3"}]},{"role":"tool","content":"r"},{"role":"tool","content":"r"}]}`,
+			wantRounds: 2,
+		},
+		{
+			name:        "openai answer ends streak",
+			handlerType: constant.OpenAI,
+			body: `{"messages":[{"role":"user","content":"go"},{"role":"assistant","tool_calls":[{"id":"1"}]},{"role":"tool","content":"r"},
+				{"role":"assistant","content":"done"},{"role":"assistant","tool_calls":[{"id":"2"}]},{"role":"tool","content":"r"}]}`,
+			wantRounds: 1,
+		},
+		{
+			name:        "claude",
+			handlerType: constant.Claude,
+			body: `{"messages":[{"role":"user","content":"go"},
+				{"role":"assistant","content":[{"type":"text","text":"t"},{"type":"tool_use","id":"1"}]},
+				{"role":"user","content":[{"type":"tool_result","tool_use_id":"1"},{"type":"text","text":"reminder"}]},
+				{"role":"assistant","content":[{"type":"tool_use","id":"2"}]},
+				{"role":"user","content":[{"type":"tool_result","tool_use_id":"2"}]}]}`,
+			wantRounds: 2,
+		},
+		{
+			name:        "gemini",
+			handlerType: constant.Gemini,
+			body: `{"contents":[{"role":"user","parts":[{"text":"go"}]},
+				{"role":"model","parts":[{"functionCall":{"name":"f"}}]},{"role":"user","parts":[{"functionResponse":{"name":"f"}}]}]}`,
+			wantRounds: 1,
+		},
+		{
+			name:        "gemini cli",
+			handlerType: constant.GeminiCLI,
+			body: `{"request":{"contents":[{"role":"user","parts":[{"text":"go"}]},
+				{"role":"model","parts":[{"functionCall":{"name":"f"}}]},{"role":"function","parts":[{"functionResponse":{"name":"f"}}]}]}}`,
+			wantRounds: 1,
+		},
+		{
+			name:        "responses",
+			handlerType: constant.OpenaiResponse,
+			body: `{"input":[{"role":"user","content":"go"},
+				{"type":"reasoning"},{"type":"message","role":"assistant","content":"t"},
+				{"type":"function_call","call_id":"1"},{"type":"function_call","call_id":"2"},
+				{"type":"function_call_output","call_id":"1"},{"type":"function_call_output","call_id":"2"},
+				{"type":"function_call","call_id":"3"},{"type":"function_call_output","call_id":"3"}]}`,
+			wantRounds: 2,
+		},
+		{
+			name:          "responses continuation",
+			handlerType:   constant.OpenaiResponse,
+			body:          `{"previous_response_id":"resp_1","input":[{"type":"function_call_output","call_id":"1"}]}`,
+			wantContinued: true,
+		},
+		{
+			name:        "responses string input",
+			handlerType: constant.OpenaiResponse,
+			body:        `{"input":"hi"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rounds, continued := toolCallRounds(tt.handlerType, []byte(tt.body))
+			if rounds != tt.wantRounds || continued != tt.wantContinued {
+				t.Errorf("toolCallRounds = %d, %v; want %d, %v", rounds, continued, tt.wantRounds, tt.wantContinued)
+			}
+		})
+	}
+}
+
+func TestCheckToolLoop(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":"go"},
+		{"role":"assistant","tool_calls":[{"id":"1"}]},{"role":"tool","content":"r"},
+		{"role":"assistant","tool_calls":[{"id":"2"}]},{"role":"tool","content":"r"}]}`)
+
+	h := NewBaseAPIHandlers(&config.SDKConfig{}, nil, nil, nil)
+	ctx, c := newCacheTestContext(nil)
+	if rounds, stop := h.checkToolLoop(ctx, constant.OpenAI, body); stop || rounds != -1 {
+		t.Fatalf("disabled guard: rounds=%d stop=%v", rounds, stop)
+	}
+
+	h.UpdateClients(&config.SDKConfig{ToolLoopGuard: config.ToolLoopGuardConfig{MaxRounds: 3}})
+	if _, stop := h.checkToolLoop(ctx, constant.OpenAI, body); stop {
+		t.Fatal("stopped below the limit")
+	}
+	h.UpdateClients(&config.SDKConfig{ToolLoopGuard: config.ToolLoopGuardConfig{MaxRounds: 2}})
+	if _, stop := h.checkToolLoop(ctx, constant.OpenAI, body); !stop {
+		t.Fatal("not stopped at the limit")
+	}
+	if got := c.Writer.Header().Get(headerToolLoopGuard); got != "stopped" {
+		t.Errorf("%s = %q, want stopped", headerToolLoopGuard, got)
+	}
+}
+
+func TestCheckToolLoopFollowsPreviousResponse(t *testing.T) {
+	h := NewBaseAPIHandlers(&config.SDKConfig{ToolLoopGuard: config.ToolLoopGuardConfig{MaxRounds: 2}}, nil, nil, nil)
+	ctx, _ := newCacheTestContext(nil)
+
+	first := []byte(`{"input":[{"role":"user","content":"go"}]}`)
+	rounds, stop := h.checkToolLoop(ctx, constant.OpenaiResponse, first)
+	if stop {
+		t.Fatal("stopped the first request")
+	}
+	h.trackToolRounds(constant.OpenaiResponse, rounds, []byte(`{"id":"resp_1","output":[{"type":"function_call","call_id":"1"}]}`))
+
+	second := []byte(`{"previous_response_id":"resp_1","input":[{"type":"function_call_output","call_id":"1"}]}`)
+	rounds, stop = h.checkToolLoop(ctx, constant.OpenaiResponse, second)
+	if stop || rounds != 1 {
+		t.Fatalf("second request: rounds=%d stop=%v", rounds, stop)
+	}
+	observe := h.toolRoundsObserver(constant.OpenaiResponse, rounds)
+	observe([]byte(`data: {"type":"response.completed","response":{"id":"resp_2","output":[{"type":"function_call","call_id":"2"}]}}`))
+
+	third := []byte(`{"previous_response_id":"resp_2","input":[{"type":"function_call_output","call_id":"2"}]}`)
+	if rounds, stop = h.checkToolLoop(ctx, constant.OpenaiResponse, third); !stop || rounds != 2 {
+		t.Fatalf("third request: rounds=%d stop=%v", rounds, stop)
+	}
+}
+
+func TestToolLoopStopResponse(t *testing.T) {
+	h := NewBaseAPIHandlers(&config.SDKConfig{ToolLoopGuard: config.ToolLoopGuardConfig{MaxRounds: 1, Message: "halted"}}, nil, nil, nil)
+
+	resp, err := h.toolLoopStopResponse(constant.OpenAI, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(resp, "choices.0.finish_reason").String(); got != "stop" {
+		t.Errorf("finish_reason = %q, want stop", got)
+	}
+	if got := gjson.GetBytes(resp, "choices.0.message.content").String(); got != "halted" {
+		t.Errorf("content = %q, want halted", got)
+	}
+
+	resp, err = h.toolLoopStopResponse(constant.Claude, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(resp, "stop_reason").String(); got != "end_turn" {
+		t.Errorf("claude stop_reason = %q, want end_turn", got)
+	}
+
+	for _, handlerType := range []string{constant.OpenAI, constant.Claude, constant.Gemini, constant.OpenaiResponse} {
+		data, _ := h.toolLoopStopStream(handlerType, "m")
+		var out strings.Builder
+		for chunk := range data {
+			out.Write(chunk)
+		}
+		if !strings.Contains(out.String(), "halted") {
+			t.Errorf("%s stream does not carry the note: %s", handlerType, out.String())
+		}
+	}
+}
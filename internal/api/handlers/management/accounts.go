@@ -0,0 +1,243 @@
+package management
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// GetAccount returns the detail of one account: its sanitized metadata, token
+// expiry and scopes, per-model states, quota and backoff, recent latency
+// percentiles and the last error. The account is looked up by ID, falling back
+// to a unique file name, label or email match. Tokens, keys and other secrets
+// are replaced with provider.RedactedValue.
+func (h *Handler) GetAccount(c *gin.Context) {
+	if h == nil {
+		respondInternalError(c, "handler not initialized")
+		return
+	}
+	if h.authManager == nil {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeInternalError, "core auth manager unavailable")
+		return
+	}
+	id := strings.TrimSpace(strings.TrimPrefix(c.Param("id"), "/"))
+	if id == "" {
+		respondBadRequest(c, "account id is required")
+		return
+	}
+	auth, matches := h.findAccount(id)
+	if auth == nil {
+		if matches > 1 {
+			respondBadRequest(c, "account id is ambiguous; use the full id")
+			return
+		}
+		respondNotFound(c, "account not found")
+		return
+	}
+	detail := buildAccountDetail(auth, time.Now())
+	if latency, ok := h.authManager.AccountLatency(auth.ID); ok {
+		detail["latency"] = latency
+	}
+	h.enrichWithQuotaState(detail, auth.ID, h.authManager.GetQuotaManager(), time.Now())
+	respondOK(c, detail)
+}
+
+// resetQuotaAction is the account path suffix of PostAccountAction that clears
+// an account's quota state.
+const resetQuotaAction = "/reset-quota"
+
+// PostAccountAction runs an action on one account. The only action is
+// reset-quota, which clears the quota, backoff and retry state of the account,
+// or of a single model given by the model query parameter or JSON body field,
+// so it can be selected again without waiting for its cooldown.
+func (h *Handler) PostAccountAction(c *gin.Context) {
+	if h == nil {
+		respondInternalError(c, "handler not initialized")
+		return
+	}
+	if h.authManager == nil {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeInternalError, "core auth manager unavailable")
+		return
+	}
+	path := strings.TrimSuffix(c.Param("id"), "/")
+	if !strings.HasSuffix(path, resetQuotaAction) {
+		respondNotFound(c, "unknown account action")
+		return
+	}
+	id := strings.TrimSpace(strings.TrimPrefix(strings.TrimSuffix(path, resetQuotaAction), "/"))
+	if id == "" {
+		respondBadRequest(c, "account id is required")
+		return
+	}
+	model := strings.TrimSpace(c.Query("model"))
+	if model == "" && c.Request.ContentLength > 0 {
+		var body struct {
+			Model string `json:"model"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondBadRequest(c, "invalid body")
+			return
+		}
+		model = strings.TrimSpace(body.Model)
+	}
+	auth, matches := h.findAccount(id)
+	if auth == nil {
+		if matches > 1 {
+			respondBadRequest(c, "account id is ambiguous; use the full id")
+			return
+		}
+		respondNotFound(c, "account not found")
+		return
+	}
+	models, err := h.authManager.ResetQuota(c.Request.Context(), auth.ID, model)
+	if err != nil {
+		respondNotFound(c, err.Error())
+		return
+	}
+	respondOK(c, gin.H{"id": auth.ID, "reset_models": models})
+}
+
+// findAccount resolves key to an account. When no ID matches, it returns the
+// number of accounts whose file name, label or email matched instead.
+func (h *Handler) findAccount(key string) (*provider.Auth, int) {
+	if auth, ok := h.authManager.GetByID(key); ok {
+		return auth, 1
+	}
+	var found *provider.Auth
+	matches := 0
+	for _, auth := range h.authManager.List() {
+		if strings.EqualFold(auth.FileName, key) || strings.EqualFold(auth.Label, key) || strings.EqualFold(authEmail(auth), key) {
+			found = auth
+			matches++
+		}
+	}
+	if matches != 1 {
+		return nil, matches
+	}
+	return found, 1
+}
+
+func buildAccountDetail(auth *provider.Auth, now time.Time) gin.H {
+	detail := gin.H{
+		"id":             auth.ID,
+		"provider":       strings.TrimSpace(auth.Provider),
+		"label":          auth.Label,
+		"file_name":      auth.FileName,
+		"status":         auth.Status,
+		"status_message": auth.StatusMessage,
+		"disabled":       auth.Disabled,
+		"unavailable":    auth.Unavailable,
+		"quota":          auth.Quota,
+		"model_states":   accountModelStates(auth.ModelStates),
+		"attributes":     redactAttributes(auth.Attributes),
+		"metadata":       provider.RedactSecrets(auth.Metadata),
+	}
+	if email := authEmail(auth); email != "" {
+		detail["email"] = email
+	}
+	if proxyURL := redactProxyURL(auth.ProxyURL); proxyURL != "" {
+		detail["proxy_url"] = proxyURL
+	}
+	if auth.LastError != nil {
+		detail["last_error"] = auth.LastError
+	}
+	if billingExhausted(auth) {
+		detail["billing_exhausted"] = true
+		detail["action_required"] = "add credits or enable billing on the upstream account"
+	}
+	if expiry, ok := auth.ExpirationTime(); ok {
+		detail["token_expires_at"] = expiry
+		detail["token_expired"] = !expiry.After(now)
+	}
+	if scopes := accountScopes(auth.Metadata); len(scopes) > 0 {
+		detail["scopes"] = scopes
+	}
+	for key, ts := range map[string]time.Time{
+		"created_at":         auth.CreatedAt,
+		"updated_at":         auth.UpdatedAt,
+		"last_refresh":       auth.LastRefreshedAt,
+		"next_refresh_after": auth.NextRefreshAfter,
+		"next_retry_after":   auth.NextRetryAfter,
+	} {
+		if !ts.IsZero() {
+			detail[key] = ts
+		}
+	}
+	return detail
+}
+
+// billingExhausted reports whether the account, or one of its models, was
+// suspended because the upstream account ran out of credits.
+func billingExhausted(auth *provider.Auth) bool {
+	if auth.StatusMessage == provider.BillingStatusMessage {
+		return true
+	}
+	for _, state := range auth.ModelStates {
+		if state != nil && state.StatusMessage == provider.BillingStatusMessage {
+			return true
+		}
+	}
+	return false
+}
+
+// accountModelStates returns the model states keyed by model, dropping
+// entries that were never set.
+func accountModelStates(states map[string]*provider.ModelState) map[string]*provider.ModelState {
+	out := make(map[string]*provider.ModelState, len(states))
+	for model, state := range states {
+		if state != nil {
+			out[model] = state
+		}
+	}
+	return out
+}
+
+func redactAttributes(attrs map[string]string) any {
+	generic := make(map[string]any, len(attrs))
+	for k, v := range attrs {
+		generic[k] = v
+	}
+	return provider.RedactSecrets(generic)
+}
+
+// redactProxyURL hides the password in a proxy URL.
+func redactProxyURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return provider.RedactedValue
+	}
+	return u.Redacted()
+}
+
+// accountScopes reads OAuth scopes stored as a space separated "scope" string
+// or a "scopes" list.
+func accountScopes(md map[string]any) []string {
+	var scopes []string
+	if v, ok := md["scope"].(string); ok {
+		scopes = strings.Fields(v)
+	}
+	switch v := md["scopes"].(type) {
+	case string:
+		scopes = append(scopes, strings.Fields(v)...)
+	case []any:
+		for _, s := range v {
+			if str, ok := s.(string); ok && strings.TrimSpace(str) != "" {
+				scopes = append(scopes, strings.TrimSpace(str))
+			}
+		}
+	case []string:
+		scopes = append(scopes, v...)
+	}
+	sort.Strings(scopes)
+	return slices.Compact(scopes)
+}
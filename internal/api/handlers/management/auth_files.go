@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/json"
 	log "github.com/nghyane/llm-mux/internal/logging"
@@ -189,7 +190,7 @@ func (h *Handler) listAuthFilesFromDisk(c *gin.Context) {
 
 			// Read file to get type field
 			full := filepath.Join(h.cfg.AuthDir, name)
-			if data, errRead := os.ReadFile(full); errRead == nil {
+			if data, errRead := atrest.ReadFile(full); errRead == nil {
 				typeValue := gjson.GetBytes(data, "type").String()
 				emailValue := gjson.GetBytes(data, "email").String()
 				fileData["type"] = typeValue
@@ -320,7 +321,7 @@ func (h *Handler) DownloadAuthFile(c *gin.Context) {
 		return
 	}
 	full := filepath.Join(h.cfg.AuthDir, name)
-	data, err := os.ReadFile(full)
+	data, err := atrest.ReadFile(full)
 	if err != nil {
 		if os.IsNotExist(err) {
 			respondNotFound(c, "file not found")
@@ -390,13 +391,17 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 		respondBadRequest(c, "failed to read body")
 		return
 	}
+	if data, err = atrest.Open(data); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
 	dst := filepath.Join(h.cfg.AuthDir, filepath.Base(name))
 	if !filepath.IsAbs(dst) {
 		if abs, errAbs := filepath.Abs(dst); errAbs == nil {
 			dst = abs
 		}
 	}
-	if errWrite := os.WriteFile(dst, data, 0o600); errWrite != nil {
+	if errWrite := atrest.WriteFile(dst, data, 0o600); errWrite != nil {
 		respondInternalError(c, fmt.Sprintf("failed to write file: %v", errWrite))
 		return
 	}
@@ -434,6 +439,10 @@ func (h *Handler) processOneUpload(ctx context.Context, fileHeader *multipart.Fi
 		return uploadResult{Name: name, Status: "error", Message: fmt.Sprintf("failed to read: %v", err)}
 	}
 
+	if data, err = atrest.Open(data); err != nil {
+		return uploadResult{Name: name, Status: "error", Message: err.Error()}
+	}
+
 	dst := filepath.Join(h.cfg.AuthDir, name)
 	if !filepath.IsAbs(dst) {
 		if abs, errAbs := filepath.Abs(dst); errAbs == nil {
@@ -441,7 +450,7 @@ func (h *Handler) processOneUpload(ctx context.Context, fileHeader *multipart.Fi
 		}
 	}
 
-	if errWrite := os.WriteFile(dst, data, 0o600); errWrite != nil {
+	if errWrite := atrest.WriteFile(dst, data, 0o600); errWrite != nil {
 		return uploadResult{Name: name, Status: "error", Message: fmt.Sprintf("failed to write: %v", errWrite)}
 	}
 
@@ -540,7 +549,7 @@ func (h *Handler) registerAuthFromFile(ctx context.Context, path string, data []
 	}
 	if data == nil {
 		var err error
-		data, err = os.ReadFile(path)
+		data, err = atrest.ReadFile(path)
 		if err != nil {
 			return fmt.Errorf("failed to read auth file: %w", err)
 		}
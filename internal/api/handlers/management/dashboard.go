@@ -0,0 +1,41 @@
+package management
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// ctxKeyBrowserAuth marks requests whose 401 responses ask the browser for
+// Basic credentials.
+const ctxKeyBrowserAuth = "management.browser_auth"
+
+// BrowserAuth makes Middleware answer unauthenticated requests with a Basic
+// challenge, so a browser prompts for the management key and resends it to
+// every path under the same prefix.
+func (h *Handler) BrowserAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ctxKeyBrowserAuth, true)
+		c.Next()
+	}
+}
+
+func challengeBrowser(c *gin.Context) {
+	if c.GetBool(ctxKeyBrowserAuth) {
+		c.Header("WWW-Authenticate", `Basic realm="llm-mux management", charset="UTF-8"`)
+	}
+}
+
+// GetDashboard serves the self-contained HTML dashboard. It renders usage,
+// provider health and account quota state by polling the read-only
+// management endpoints next to it; the page itself carries no data.
+func (h *Handler) GetDashboard(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", dashboardHTML)
+}
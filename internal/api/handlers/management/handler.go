@@ -173,12 +173,15 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			return
 		}
 
-		// Accept either Authorization: Bearer <key> or X-Management-Key
+		// Accept Authorization: Bearer <key>, Basic credentials with the key
+		// as password (browsers), or X-Management-Key
 		var provided string
 		if ah := c.GetHeader("Authorization"); ah != "" {
 			parts := strings.SplitN(ah, " ", 2)
 			if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
 				provided = parts[1]
+			} else if _, password, ok := c.Request.BasicAuth(); ok {
+				provided = password
 			} else {
 				provided = ah
 			}
@@ -191,6 +194,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			if !localClient {
 				fail()
 			}
+			challengeBrowser(c)
 			respondUnauthorized(c, "missing management key")
 			c.Abort()
 			return
@@ -211,6 +215,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			if !localClient {
 				fail()
 			}
+			challengeBrowser(c)
 			respondUnauthorized(c, "invalid management key")
 			c.Abort()
 			return
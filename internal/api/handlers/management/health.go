@@ -0,0 +1,38 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// GetHealth reports per-provider availability: account counts, circuit
+// breaker state and whether the provider is hidden from model resolution.
+// The overall status is "degraded" when any provider is unavailable and
+// "unavailable" when none can serve requests.
+func (h *Handler) GetHealth(c *gin.Context) {
+	if h == nil {
+		respondInternalError(c, "handler not initialized")
+		return
+	}
+	if h.authManager == nil {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeInternalError, "core auth manager unavailable")
+		return
+	}
+	providers := h.authManager.ProviderHealth()
+	available := 0
+	for _, p := range providers {
+		if p.Status == provider.ProviderAvailable {
+			available++
+		}
+	}
+	status := "ok"
+	switch {
+	case len(providers) > 0 && available == 0:
+		status = "unavailable"
+	case available < len(providers):
+		status = "degraded"
+	}
+	respondOK(c, gin.H{"status": status, "providers": providers})
+}
@@ -0,0 +1,16 @@
+package management
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/metrics"
+)
+
+// GetMetrics serves the Prometheus scrape endpoint when metrics.prometheus is enabled.
+func (h *Handler) GetMetrics(c *gin.Context) {
+	handler := metrics.Handler()
+	if handler == nil {
+		respondNotFound(c, "prometheus metrics are not enabled")
+		return
+	}
+	handler.ServeHTTP(c.Writer, c.Request)
+}
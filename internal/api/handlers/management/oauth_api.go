@@ -2,6 +2,7 @@ package management
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/nghyane/llm-mux/internal/json"
 	"io"
@@ -94,8 +95,9 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 		return
 	}
 
-	// Build auth URL for OAuth providers
-	authURL, state, codeVerifier, err := h.buildProviderAuthURL(providerName)
+	// Build auth URL for OAuth providers with the advertised redirect URI
+	redirectURI := oauth.GetRedirectURI(providerName)
+	authURL, state, codeVerifier, err := h.buildProviderAuthURL(providerName, redirectURI)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, OAuthStartResponse{
 			Status: "error",
@@ -105,8 +107,12 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 	}
 
 	// Register OAuth request with codeVerifier for PKCE providers
-	oauthReq := oauthService.Registry().Create(state, providerName, oauth.ModeWebUI)
+	oauthReq, ok := registerOAuthRequest(c, state, providerName)
+	if !ok {
+		return
+	}
 	oauthReq.CodeVerifier = codeVerifier
+	oauthReq.AuthURL = authURL
 
 	// Start callback forwarder for WebUI mode
 	if targetURL, errTarget := h.managementCallbackURL("/" + providerName + "/callback"); errTarget == nil {
@@ -217,18 +223,36 @@ func (h *Handler) waitForCallbackFile(ctx context.Context, providerName, state s
 func (h *Handler) exchangeOAuthCode(ctx context.Context, providerName, state string, callback *oauthCallbackData) (*provider.Auth, error) {
 	switch providerName {
 	case "gemini", "antigravity":
-		return h.exchangeGoogleCode(ctx, providerName, callback.Code)
+		return h.exchangeGoogleCode(ctx, providerName, state, callback.Code)
 	case "claude":
 		return h.exchangeClaudeCode(ctx, state, callback.Code)
 	case "codex":
 		return h.exchangeCodexCode(ctx, state, callback.Code)
 	case "iflow":
-		return h.exchangeIFlowCode(ctx, callback)
+		return h.exchangeIFlowCode(ctx, state, callback)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", providerName)
 	}
 }
 
+// registerOAuthRequest adds a pending WebUI request to the registry. When a
+// pending request limit is reached it writes a 429 response and returns false.
+func registerOAuthRequest(c *gin.Context, state, providerName string) (*oauth.OAuthRequest, bool) {
+	req, err := oauthService.Registry().Create(state, providerName, oauth.ModeWebUI)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, oauth.ErrTooManyPendingRequests) {
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, OAuthStartResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return nil, false
+	}
+	return req, true
+}
+
 // startQwenDeviceFlow initiates Qwen device authorization flow.
 func (h *Handler) startQwenDeviceFlow(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), deviceFlowTimeout)
@@ -245,7 +269,10 @@ func (h *Handler) startQwenDeviceFlow(c *gin.Context) {
 	}
 
 	state := fmt.Sprintf("qwen-%d", time.Now().UnixNano())
-	oauthService.Registry().Create(state, "qwen", oauth.ModeWebUI)
+	if _, ok := registerOAuthRequest(c, state, "qwen"); !ok {
+		cancel()
+		return
+	}
 
 	go h.pollQwenToken(ctx, cancel, qwenAuth, deviceFlow, state)
 
@@ -304,7 +331,10 @@ func (h *Handler) startCopilotDeviceFlow(c *gin.Context) {
 	}
 
 	state := fmt.Sprintf("copilot-%s", deviceCode.DeviceCode[:8])
-	oauthService.Registry().Create(state, "copilot", oauth.ModeWebUI)
+	if _, ok := registerOAuthRequest(c, state, "copilot"); !ok {
+		cancel()
+		return
+	}
 
 	go h.pollCopilotToken(ctx, cancel, copilotAuth, deviceCode, state)
 
@@ -424,8 +454,9 @@ func GetOAuthService() *oauth.Service {
 // Provider Auth URL Builders
 // =============================================================================
 
-// buildProviderAuthURL builds the authorization URL for a provider.
-func (h *Handler) buildProviderAuthURL(providerName string) (authURL, state, codeVerifier string, err error) {
+// buildProviderAuthURL builds the authorization URL for a provider, sending
+// redirectURI as the callback.
+func (h *Handler) buildProviderAuthURL(providerName, redirectURI string) (authURL, state, codeVerifier string, err error) {
 	state, err = misc.GenerateRandomState()
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to generate state: %w", err)
@@ -433,25 +464,26 @@ func (h *Handler) buildProviderAuthURL(providerName string) (authURL, state, cod
 
 	switch providerName {
 	case "claude":
-		return h.buildClaudeAuthURL(state)
+		return h.buildClaudeAuthURL(state, redirectURI)
 	case "codex":
-		return h.buildCodexAuthURL(state)
+		return h.buildCodexAuthURL(state, redirectURI)
 	case "gemini", "antigravity":
-		return h.buildGoogleAuthURL(providerName, state)
+		return h.buildGoogleAuthURL(providerName, state, redirectURI)
 	case "iflow":
-		return h.buildIFlowAuthURL(state)
+		return h.buildIFlowAuthURL(state, redirectURI)
 	default:
 		return "", "", "", fmt.Errorf("unsupported OAuth provider: %s", providerName)
 	}
 }
 
-func (h *Handler) buildClaudeAuthURL(state string) (string, string, string, error) {
+func (h *Handler) buildClaudeAuthURL(state, redirectURI string) (string, string, string, error) {
 	pkceCodes, err := claude.GeneratePKCECodes()
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to generate PKCE codes: %w", err)
 	}
 
 	claudeAuth := claude.NewClaudeAuth(h.cfg)
+	claudeAuth.SetRedirectURI(redirectURI)
 	authURL, _, err := claudeAuth.GenerateAuthURL(state, pkceCodes)
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to generate auth URL: %w", err)
@@ -460,13 +492,14 @@ func (h *Handler) buildClaudeAuthURL(state string) (string, string, string, erro
 	return authURL, state, pkceCodes.CodeVerifier, nil
 }
 
-func (h *Handler) buildCodexAuthURL(state string) (string, string, string, error) {
+func (h *Handler) buildCodexAuthURL(state, redirectURI string) (string, string, string, error) {
 	pkceCodes, err := codex.GeneratePKCECodes()
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to generate PKCE codes: %w", err)
 	}
 
 	codexAuth := codex.NewCodexAuth(h.cfg)
+	codexAuth.SetRedirectURI(redirectURI)
 	authURL, err := codexAuth.GenerateAuthURL(state, pkceCodes)
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to generate auth URL: %w", err)
@@ -475,14 +508,12 @@ func (h *Handler) buildCodexAuthURL(state string) (string, string, string, error
 	return authURL, state, pkceCodes.CodeVerifier, nil
 }
 
-func (h *Handler) buildGoogleAuthURL(providerName, state string) (string, string, string, error) {
+func (h *Handler) buildGoogleAuthURL(providerName, state, redirectURI string) (string, string, string, error) {
 	cfg, ok := googleOAuthConfigs[providerName]
 	if !ok {
 		return "", "", "", fmt.Errorf("unknown Google OAuth provider: %s", providerName)
 	}
 
-	redirectURI := fmt.Sprintf("http://localhost:%d/%s", oauth.GetCallbackPort(providerName), cfg.CallbackPath)
-
 	conf := &oauth2.Config{
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
@@ -495,10 +526,9 @@ func (h *Handler) buildGoogleAuthURL(providerName, state string) (string, string
 	return authURL, state, "", nil
 }
 
-func (h *Handler) buildIFlowAuthURL(state string) (string, string, string, error) {
+func (h *Handler) buildIFlowAuthURL(state, redirectURI string) (string, string, string, error) {
 	iflowAuth := iflow.NewIFlowAuth(h.cfg)
-	authURL, _ := iflowAuth.AuthorizationURL(state, iflow.CallbackPort)
-	return authURL, state, "", nil
+	return iflowAuth.AuthorizationURLFor(state, redirectURI), state, "", nil
 }
 
 // =============================================================================
@@ -508,7 +538,6 @@ func (h *Handler) buildIFlowAuthURL(state string) (string, string, string, error
 type googleOAuthConfig struct {
 	ClientID     string
 	ClientSecret string
-	CallbackPath string
 	FetchProject bool
 	Scopes       []string
 }
@@ -517,14 +546,12 @@ var googleOAuthConfigs = map[string]googleOAuthConfig{
 	"gemini": {
 		ClientID:     oauth.GeminiClientID,
 		ClientSecret: oauth.GeminiClientSecret,
-		CallbackPath: "oauth2callback",
 		FetchProject: false,
 		Scopes:       []string{"openid", "https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/cloud-platform"},
 	},
 	"antigravity": {
 		ClientID:     oauth.AntigravityClientID,
 		ClientSecret: oauth.AntigravityClientSecret,
-		CallbackPath: "oauth-callback",
 		FetchProject: true,
 		Scopes: []string{
 			"https://www.googleapis.com/auth/cloud-platform",
@@ -536,13 +563,13 @@ var googleOAuthConfigs = map[string]googleOAuthConfig{
 	},
 }
 
-func (h *Handler) exchangeGoogleCode(ctx context.Context, providerName, code string) (*provider.Auth, error) {
+func (h *Handler) exchangeGoogleCode(ctx context.Context, providerName, state, code string) (*provider.Auth, error) {
 	cfg, ok := googleOAuthConfigs[providerName]
 	if !ok {
 		return nil, fmt.Errorf("unknown Google OAuth provider: %s", providerName)
 	}
 
-	redirectURI := fmt.Sprintf("http://localhost:%d/%s", oauth.GetCallbackPort(providerName), cfg.CallbackPath)
+	redirectURI := requestRedirectURI(providerName, state)
 	httpClient := h.getHTTPClient()
 
 	tokenResp, err := exchangeGoogleOAuthCode(ctx, code, redirectURI, cfg.ClientID, cfg.ClientSecret, httpClient)
@@ -574,6 +601,7 @@ func (h *Handler) exchangeClaudeCode(ctx context.Context, state, code string) (*
 	}
 
 	claudeAuth := claude.NewClaudeAuth(h.cfg)
+	claudeAuth.SetRedirectURI(oauthReq.RedirectURI)
 	pkceCodes := &claude.PKCECodes{CodeVerifier: oauthReq.CodeVerifier}
 
 	bundle, err := claudeAuth.ExchangeCodeForTokens(ctx, code, state, pkceCodes)
@@ -594,6 +622,7 @@ func (h *Handler) exchangeCodexCode(ctx context.Context, state, code string) (*p
 	}
 
 	codexAuth := codex.NewCodexAuth(h.cfg)
+	codexAuth.SetRedirectURI(oauthReq.RedirectURI)
 	pkceCodes := &codex.PKCECodes{CodeVerifier: oauthReq.CodeVerifier}
 
 	bundle, err := codexAuth.ExchangeCodeForTokens(ctx, code, pkceCodes)
@@ -607,10 +636,10 @@ func (h *Handler) exchangeCodexCode(ctx context.Context, state, code string) (*p
 	return buildAuthRecordWithEmail("codex", email, storage, map[string]any{"account_id": storage.AccountID}), nil
 }
 
-func (h *Handler) exchangeIFlowCode(ctx context.Context, callback *oauthCallbackData) (*provider.Auth, error) {
+func (h *Handler) exchangeIFlowCode(ctx context.Context, state string, callback *oauthCallbackData) (*provider.Auth, error) {
 	redirectURI := callback.RedirectURI
 	if redirectURI == "" {
-		redirectURI = fmt.Sprintf("http://localhost:%d/oauth2callback", iflow.CallbackPort)
+		redirectURI = requestRedirectURI("iflow", state)
 	}
 
 	iflowAuth := iflow.NewIFlowAuth(h.cfg)
@@ -629,6 +658,15 @@ func (h *Handler) exchangeIFlowCode(ctx context.Context, callback *oauthCallback
 // Helper Functions
 // =============================================================================
 
+// requestRedirectURI returns the redirect URI the flow for state was started
+// with, which the token exchange must repeat.
+func requestRedirectURI(providerName, state string) string {
+	if req := oauthService.Registry().Get(state); req != nil && req.RedirectURI != "" {
+		return req.RedirectURI
+	}
+	return oauth.GetRedirectURI(providerName)
+}
+
 func buildAuthRecordWithEmail(providerName, email string, storage auth.TokenStorage, extraMeta map[string]any) *provider.Auth {
 	fileName := providerName + ".json"
 	label := providerName
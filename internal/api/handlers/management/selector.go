@@ -0,0 +1,51 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// GetSelector reports the account selection strategy in effect.
+func (h *Handler) GetSelector(c *gin.Context) {
+	respondOK(c, gin.H{"strategy": h.authManager.SelectionStrategy()})
+}
+
+// PostSelector switches the account selection strategy without a restart.
+// Requests already past account selection keep the strategy they started
+// with. The strategy is saved to account-selection.strategy so config
+// reloads keep it.
+func (h *Handler) PostSelector(c *gin.Context) {
+	// A JSON content type cannot be sent cross-site without a CORS preflight,
+	// so a page elsewhere cannot replay the dashboard's Basic credentials here.
+	if c.ContentType() != "application/json" {
+		respondError(c, http.StatusUnsupportedMediaType, ErrCodeInvalidRequest, "expected Content-Type: application/json")
+		return
+	}
+	var body struct {
+		Strategy *string `json:"strategy"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Strategy == nil {
+		respondBadRequest(c, "invalid body: expected {\"strategy\": string}")
+		return
+	}
+	name, err := provider.ParseSelectionStrategy(*body.Strategy)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidation, err.Error())
+		return
+	}
+
+	if err := h.authManager.SetSelectionStrategy(name, h.getConfig().AccountSelection.ExplorationFraction()); err != nil {
+		respondError(c, http.StatusConflict, ErrCodeValidation, err.Error())
+		return
+	}
+	h.cfgMu.Lock()
+	h.cfg.AccountSelection.Strategy = name
+	h.cfgMu.Unlock()
+	if !h.persistSilent() {
+		respondInternalError(c, "failed to save config")
+		return
+	}
+	respondOK(c, gin.H{"strategy": h.authManager.SelectionStrategy()})
+}
@@ -0,0 +1,39 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+)
+
+// ListThinkingTraces returns the request IDs of captured thinking traces, oldest first.
+func (h *Handler) ListThinkingTraces(c *gin.Context) {
+	traces := stream.ThinkingTraces()
+	if traces == nil {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeNotFound, "thinking capture disabled; set thinking-capture in config")
+		return
+	}
+	respondOK(c, gin.H{"request_ids": traces.IDs()})
+}
+
+// GetThinkingTrace returns the captured thinking trace for a request ID.
+func (h *Handler) GetThinkingTrace(c *gin.Context) {
+	traces := stream.ThinkingTraces()
+	if traces == nil {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeNotFound, "thinking capture disabled; set thinking-capture in config")
+		return
+	}
+	requestID := strings.TrimSpace(c.Param("requestID"))
+	if requestID == "" {
+		respondBadRequest(c, "request id is required")
+		return
+	}
+	trace, ok := traces.Get(requestID)
+	if !ok {
+		respondNotFound(c, "no thinking trace for request "+requestID)
+		return
+	}
+	respondOK(c, trace)
+}
@@ -1,15 +1,27 @@
 package management
 
-import "time"
+import (
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
 
 // UsageStatsResponse represents the structured usage statistics response.
 type UsageStatsResponse struct {
-	Summary    UsageSummary                  `json:"summary"`
-	ByProvider map[string]UsageProviderStats `json:"by_provider,omitempty"`
-	ByAccount  map[string]UsageAccountStats  `json:"by_account,omitempty"`
-	ByModel    map[string]UsageModelStats    `json:"by_model,omitempty"`
-	Timeline   *UsageTimeline                `json:"timeline,omitempty"`
-	Period     UsagePeriod                   `json:"period"`
+	Summary     UsageSummary                   `json:"summary"`
+	ByProvider  map[string]UsageProviderStats  `json:"by_provider,omitempty"`
+	ByAccount   map[string]UsageAccountStats   `json:"by_account,omitempty"`
+	ByModel     map[string]UsageModelStats     `json:"by_model,omitempty"`
+	ByOperation map[string]UsageOperationStats `json:"by_operation,omitempty"`
+	ByKey       map[string]UsageKeyStats       `json:"by_key,omitempty"`
+	Timeline    *UsageTimeline                 `json:"timeline,omitempty"`
+	Period      UsagePeriod                    `json:"period"`
+
+	// Concurrency lists live in-flight counts for models with a concurrency limit.
+	Concurrency []provider.ConcurrencyStats `json:"concurrency,omitempty"`
+	// Latency holds rolling latency percentiles of recent successful requests,
+	// keyed by auth ID.
+	Latency map[string]provider.AccountLatency `json:"latency,omitempty"`
 }
 
 // UsageSummary holds the aggregate usage summary.
@@ -55,6 +67,27 @@ type UsageModelStats struct {
 	Success  int64        `json:"success"`
 	Failure  int64        `json:"failure"`
 	Tokens   TokenSummary `json:"tokens"`
+
+	// Operations splits the model's totals by operation type.
+	Operations map[string]UsageOperationStats `json:"operations,omitempty"`
+}
+
+// UsageOperationStats represents per-operation statistics (chat, count_tokens,
+// embeddings, rerank).
+type UsageOperationStats struct {
+	Requests int64        `json:"requests"`
+	Success  int64        `json:"success"`
+	Failure  int64        `json:"failure"`
+	Tokens   TokenSummary `json:"tokens"`
+}
+
+// UsageKeyStats represents per-client-API-key statistics. Keys are reported by
+// their hashed ID, never in the clear.
+type UsageKeyStats struct {
+	Requests int64        `json:"requests"`
+	Success  int64        `json:"success"`
+	Failure  int64        `json:"failure"`
+	Tokens   TokenSummary `json:"tokens"`
 }
 
 // UsageTimeline holds time-series usage data.
@@ -9,10 +9,17 @@ import (
 )
 
 func (h *Handler) GetUsageStatistics(c *gin.Context) {
-	if h == nil || h.usagePlugin == nil {
+	if h == nil {
 		respondOK(c, UsageStatsResponse{})
 		return
 	}
+	if h.usagePlugin == nil {
+		respondOK(c, UsageStatsResponse{
+			Concurrency: h.authManager.ConcurrencySnapshot(),
+			Latency:     h.authManager.AccountLatencies(),
+		})
+		return
+	}
 
 	retentionDays := 30
 	if cfg := h.getConfig(); cfg != nil && cfg.Usage.RetentionDays > 0 {
@@ -37,6 +44,8 @@ func (h *Handler) GetUsageStatistics(c *gin.Context) {
 			To:            to,
 			RetentionDays: retentionDays,
 		},
+		Concurrency: h.authManager.ConcurrencySnapshot(),
+		Latency:     h.authManager.AccountLatencies(),
 	}
 
 	backend := h.usagePlugin.GetBackend()
@@ -104,20 +113,72 @@ func (h *Handler) GetUsageStatistics(c *gin.Context) {
 	} else if len(modelStats) > 0 {
 		byModel := make(map[string]UsageModelStats, len(modelStats))
 		for _, ms := range modelStats {
-			byModel[ms.Model] = UsageModelStats{
-				Provider: ms.Provider,
-				Requests: ms.Requests,
-				Success:  ms.SuccessCount,
-				Failure:  ms.FailureCount,
+			// Rows are ordered by request count, so the first row of a model
+			// names its busiest provider.
+			entry, ok := byModel[ms.Model]
+			if !ok {
+				entry = UsageModelStats{Provider: ms.Provider, Operations: make(map[string]UsageOperationStats)}
+			}
+			entry.Requests += ms.Requests
+			entry.Success += ms.SuccessCount
+			entry.Failure += ms.FailureCount
+			entry.Tokens.Total += ms.TotalTokens
+			entry.Tokens.Input += ms.InputTokens
+			entry.Tokens.Output += ms.OutputTokens
+			entry.Tokens.Reasoning += ms.ReasoningTokens
+
+			op := entry.Operations[ms.Operation]
+			op.Requests += ms.Requests
+			op.Success += ms.SuccessCount
+			op.Failure += ms.FailureCount
+			op.Tokens.Total += ms.TotalTokens
+			op.Tokens.Input += ms.InputTokens
+			op.Tokens.Output += ms.OutputTokens
+			op.Tokens.Reasoning += ms.ReasoningTokens
+			entry.Operations[ms.Operation] = op
+
+			byModel[ms.Model] = entry
+		}
+		response.ByModel = byModel
+	}
+
+	if operationStats, err := backend.QueryOperationStats(ctx, from); err != nil {
+		log.Warnf("usage: failed to query operation stats: %v", err)
+	} else if len(operationStats) > 0 {
+		byOperation := make(map[string]UsageOperationStats, len(operationStats))
+		for _, op := range operationStats {
+			byOperation[op.Operation] = UsageOperationStats{
+				Requests: op.Requests,
+				Success:  op.SuccessCount,
+				Failure:  op.FailureCount,
 				Tokens: TokenSummary{
-					Total:     ms.TotalTokens,
-					Input:     ms.InputTokens,
-					Output:    ms.OutputTokens,
-					Reasoning: ms.ReasoningTokens,
+					Total:  op.TotalTokens,
+					Input:  op.InputTokens,
+					Output: op.OutputTokens,
 				},
 			}
 		}
-		response.ByModel = byModel
+		response.ByOperation = byOperation
+	}
+
+	if keyStats, err := backend.QueryKeyStats(ctx, from); err != nil {
+		log.Warnf("usage: failed to query key stats: %v", err)
+	} else if len(keyStats) > 0 {
+		byKey := make(map[string]UsageKeyStats, len(keyStats))
+		for _, ks := range keyStats {
+			byKey[ks.APIKeyID] = UsageKeyStats{
+				Requests: ks.Requests,
+				Success:  ks.SuccessCount,
+				Failure:  ks.FailureCount,
+				Tokens: TokenSummary{
+					Total:     ks.TotalTokens,
+					Input:     ks.InputTokens,
+					Output:    ks.OutputTokens,
+					Reasoning: ks.ReasoningTokens,
+				},
+			}
+		}
+		response.ByKey = byKey
 	}
 
 	timeline := &UsageTimeline{}
@@ -22,6 +22,8 @@ func (s *Server) registerManagementRoutes() {
 	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware())
 	{
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
+		mgmt.GET("/metrics", s.mgmt.GetMetrics)
+		mgmt.GET("/health", s.mgmt.GetHealth)
 		mgmt.GET("/config", s.mgmt.GetConfig)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
@@ -29,6 +31,8 @@ func (s *Server) registerManagementRoutes() {
 
 		mgmt.GET("/debug", s.mgmt.GetDebug)
 		mgmt.PUT("/debug", s.mgmt.PutDebug)
+		mgmt.GET("/debug/thinking", s.mgmt.ListThinkingTraces)
+		mgmt.GET("/debug/thinking/:requestID", s.mgmt.GetThinkingTrace)
 
 		mgmt.GET("/logging-to-file", s.mgmt.GetLoggingToFile)
 		mgmt.PUT("/logging-to-file", s.mgmt.PutLoggingToFile)
@@ -78,6 +82,8 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
 		mgmt.DELETE("/auth-files", s.mgmt.DeleteAuthFile)
+		mgmt.GET("/accounts/*id", s.mgmt.GetAccount)
+		mgmt.POST("/accounts/*id", s.mgmt.PostAccountAction)
 		mgmt.POST("/vertex/import", s.mgmt.ImportVertexCredential)
 
 		// Unified OAuth API endpoints
@@ -85,4 +91,18 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/oauth/status/:state", s.mgmt.OAuthStatus)
 		mgmt.POST("/oauth/cancel/:state", s.mgmt.OAuthCancel)
 	}
+
+	// The dashboard, the read-only endpoints it polls and runtime controls
+	// share the /manage/ prefix, so a browser reuses the Basic credentials
+	// entered for the page.
+	manage := s.engine.Group("/manage")
+	manage.Use(s.managementAvailabilityMiddleware(), s.mgmt.BrowserAuth(), s.mgmt.Middleware())
+	{
+		manage.GET("/dashboard", s.mgmt.GetDashboard)
+		manage.GET("/usage", s.mgmt.GetUsageStatistics)
+		manage.GET("/health", s.mgmt.GetHealth)
+		manage.GET("/auth-files", s.mgmt.ListAuthFiles)
+		manage.GET("/selector", s.mgmt.GetSelector)
+		manage.POST("/selector", s.mgmt.PostSelector)
+	}
 }
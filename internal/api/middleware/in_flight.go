@@ -0,0 +1,26 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the in-flight request counter used to drain on shutdown.
+package middleware
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InFlightMiddleware keeps counter at the number of requests currently being
+// served, streams included, so shutdown can report how many it is waiting on.
+// WebSocket upgrades are not counted: the HTTP server does not wait for
+// hijacked connections when it shuts down.
+func InFlightMiddleware(counter *atomic.Int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+			c.Next()
+			return
+		}
+		counter.Add(1)
+		defer counter.Add(-1)
+		c.Next()
+	}
+}
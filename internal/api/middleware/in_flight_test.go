@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInFlightMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var counter atomic.Int64
+	var seen int64
+	engine := gin.New()
+	engine.Use(InFlightMiddleware(&counter))
+	engine.GET("/v1/models", func(c *gin.Context) {
+		seen = counter.Load()
+		c.Status(http.StatusOK)
+	})
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	if seen != 1 {
+		t.Errorf("in-flight during request = %d, want 1", seen)
+	}
+	if got := counter.Load(); got != 0 {
+		t.Errorf("in-flight after request = %d, want 0", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("Upgrade", "websocket")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	if seen != 0 {
+		t.Errorf("websocket upgrade counted as in-flight: %d", seen)
+	}
+}
@@ -0,0 +1,160 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the inbound rate limiter for the public API routes.
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitSweepInterval bounds how often idle per-key buckets are evicted.
+const rateLimitSweepInterval = time.Minute
+
+type keyBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter applies a global and a per-API-key token bucket to inbound
+// requests. Limits can be changed at runtime with Update.
+type RateLimiter struct {
+	mu        sync.Mutex
+	cfg       config.RateLimitConfig
+	global    *rate.Limiter
+	keys      map[string]*keyBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewRateLimiter returns a limiter enforcing cfg.
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{now: time.Now}
+	l.Update(cfg)
+	return l
+}
+
+// Update replaces the configured limits. Buckets are reset only when the
+// limits actually change, so a config reload does not refill them.
+func (l *RateLimiter) Update(cfg config.RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.keys != nil && l.cfg == cfg {
+		return
+	}
+	l.cfg = cfg
+	l.global = nil
+	if cfg.GlobalRPS > 0 {
+		l.global = rate.NewLimiter(rate.Limit(cfg.GlobalRPS), burstFor(cfg.GlobalRPS, cfg.GlobalBurst))
+	}
+	l.keys = make(map[string]*keyBucket)
+}
+
+// Middleware returns a Gin handler that rejects requests over the limit with
+// 429 and a Retry-After header. It must run after authentication so the
+// per-key bucket can use the authenticated key.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if wait, ok := l.allow(rateLimitKey(c)); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": gin.H{
+					"message": fmt.Sprintf("Rate limit exceeded, retry after %ds", seconds),
+					"type":    "rate_limit_error",
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// allow takes a token from the key's bucket and the global bucket. When either
+// is empty no token is consumed and the wait until one is available is returned.
+func (l *RateLimiter) allow(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.cfg.Enabled() {
+		return 0, true
+	}
+	now := l.now()
+
+	var keyRes *rate.Reservation
+	if key != "" && l.cfg.PerKeyRPS > 0 {
+		l.sweep(now)
+		b := l.keys[key]
+		if b == nil {
+			b = &keyBucket{limiter: rate.NewLimiter(rate.Limit(l.cfg.PerKeyRPS), burstFor(l.cfg.PerKeyRPS, l.cfg.PerKeyBurst))}
+			l.keys[key] = b
+		}
+		b.lastSeen = now
+		keyRes = b.limiter.ReserveN(now, 1)
+		if delay := keyRes.DelayFrom(now); delay > 0 {
+			keyRes.CancelAt(now)
+			return delay, false
+		}
+	}
+	if l.global != nil {
+		res := l.global.ReserveN(now, 1)
+		if delay := res.DelayFrom(now); delay > 0 {
+			res.CancelAt(now)
+			if keyRes != nil {
+				keyRes.CancelAt(now)
+			}
+			return delay, false
+		}
+	}
+	return 0, true
+}
+
+// sweep drops per-key buckets idle long enough to have refilled completely,
+// which makes them indistinguishable from new ones.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	idle := time.Duration(float64(burstFor(l.cfg.PerKeyRPS, l.cfg.PerKeyBurst)) / l.cfg.PerKeyRPS * float64(time.Second))
+	if idle < rateLimitSweepInterval {
+		idle = rateLimitSweepInterval
+	}
+	for key, b := range l.keys {
+		if now.Sub(b.lastSeen) > idle {
+			delete(l.keys, key)
+		}
+	}
+}
+
+// rateLimitKey identifies the caller for the per-key bucket: the principal set
+// by the auth middleware, or the client IP when authentication is disabled.
+// Unverified key headers are not used, since a client could send a new value
+// with every request to get a fresh bucket.
+func rateLimitKey(c *gin.Context) string {
+	if v, ok := c.Get("apiKey"); ok {
+		if s := fmt.Sprint(v); s != "" {
+			return "key:" + s
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func burstFor(rps float64, burst int) int {
+	if burst > 0 {
+		return burst
+	}
+	if b := int(math.Ceil(rps)); b > 1 {
+		return b
+	}
+	return 1
+}
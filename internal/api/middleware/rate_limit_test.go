@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+// newRateLimitedEngine stands in for the auth middleware by taking the bearer
+// token as the authenticated key.
+func newRateLimitedEngine(l *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		if key := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); key != "" {
+			c.Set("apiKey", key)
+		}
+	})
+	engine.Use(l.Middleware())
+	engine.GET("/v1/models", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return engine
+}
+
+func doRateLimited(engine *gin.Engine, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimiter_PerKeyBucket(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewRateLimiter(config.RateLimitConfig{PerKeyRPS: 0.5, PerKeyBurst: 2})
+	l.now = func() time.Time { return now }
+	engine := newRateLimitedEngine(l)
+
+	for i := 0; i < 2; i++ {
+		if rec := doRateLimited(engine, "a"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200 within burst, got %d", i, rec.Code)
+		}
+	}
+	rec := doRateLimited(engine, "a")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once the key's bucket is empty, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if rec := doRateLimited(engine, "b"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another key to be unaffected, got %d", rec.Code)
+	}
+
+	now = now.Add(2 * time.Second)
+	if rec := doRateLimited(engine, "a"); rec.Code != http.StatusOK {
+		t.Errorf("Expected the bucket to refill, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter_UnauthenticatedUsesClientIP(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewRateLimiter(config.RateLimitConfig{PerKeyRPS: 1, PerKeyBurst: 1})
+	l.now = func() time.Time { return now }
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(l.Middleware())
+	engine.GET("/v1/models", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(remoteAddr, apiKey string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Api-Key", apiKey)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := do("192.0.2.1:1000", "first"); code != http.StatusOK {
+		t.Fatalf("Expected the first request to pass, got %d", code)
+	}
+	if code := do("192.0.2.1:1001", "second"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected a new key header from the same IP to share its bucket, got %d", code)
+	}
+	if code := do("192.0.2.2:1000", "first"); code != http.StatusOK {
+		t.Errorf("Expected another IP to have its own bucket, got %d", code)
+	}
+}
+
+func TestRateLimiter_GlobalBucket(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	// Per-key buckets hold one token and practically never refill.
+	l := NewRateLimiter(config.RateLimitConfig{GlobalRPS: 1, PerKeyRPS: 0.001, PerKeyBurst: 1})
+	l.now = func() time.Time { return now }
+	engine := newRateLimitedEngine(l)
+
+	if rec := doRateLimited(engine, "a"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected first request to pass, got %d", rec.Code)
+	}
+	if rec := doRateLimited(engine, "b"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the global bucket to reject another key, got %d", rec.Code)
+	}
+
+	// The rejected request must not have spent b's only token.
+	now = now.Add(time.Second)
+	if rec := doRateLimited(engine, "b"); rec.Code != http.StatusOK {
+		t.Errorf("Expected b to pass once the global bucket refills, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter_DisabledByDefault(t *testing.T) {
+	engine := newRateLimitedEngine(NewRateLimiter(config.RateLimitConfig{}))
+	for i := 0; i < 100; i++ {
+		if rec := doRateLimited(engine, "a"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200 with no limits, got %d", i, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the request ID middleware that correlates a request across
+// client logs, llm-mux logs, request logs and usage records.
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/logging"
+)
+
+// RequestIDMiddleware accepts a client supplied X-Request-ID or generates one,
+// echoes it on the response and attaches it to the request context so logs,
+// execution results and usage records carry the same ID.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := logging.NewRequestID(c.GetHeader(logging.RequestIDHeader))
+		c.Request.Header.Set(logging.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(logging.RequestIDHeader, id)
+		c.Next()
+	}
+}
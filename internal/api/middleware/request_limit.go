@@ -19,8 +19,8 @@ const (
 	// - Multi-modal requests with multiple images + context
 	DefaultMaxChatRequestSize = 50 * 1024 * 1024 // 50MB
 
-	// DefaultMaxEmbedRequestSize is the maximum request body size for embedding endpoints.
-	// Embeddings are text-only, so a smaller limit is appropriate.
+	// DefaultMaxEmbedRequestSize is the maximum request body size for embedding and rerank endpoints.
+	// These requests are text-only, so a smaller limit is appropriate.
 	DefaultMaxEmbedRequestSize = 10 * 1024 * 1024 // 10MB
 
 	// DefaultMaxResponseSize is the maximum response body size to read into memory.
@@ -19,6 +19,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/api/middleware"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/oauth"
+	"github.com/nghyane/llm-mux/internal/usage"
 )
 
 // setupRoutes configures the API routes for the server.
@@ -35,19 +36,37 @@ func (s *Server) setupRoutes() {
 	v1 := s.engine.Group("/v1")
 	v1.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
 	v1.Use(s.conditionalAuthMiddleware())
+	v1.Use(s.rateLimiter.Middleware())
 	{
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
+		v1.POST("/embeddings", middleware.RequestSizeLimitMiddleware(middleware.DefaultMaxEmbedRequestSize), openaiHandlers.Embeddings)
+		v1.POST("/rerank", middleware.RequestSizeLimitMiddleware(middleware.DefaultMaxEmbedRequestSize), openaiHandlers.Rerank)
 		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
 		v1.POST("/messages/count_tokens", claudeCodeHandlers.ClaudeCountTokens)
 		v1.POST("/responses", openaiResponsesHandlers.Responses)
+		if s.batches != nil {
+			batchHandlers := openai.NewOpenAIBatchAPIHandler(s.handlers, s.batches)
+			v1.POST("/batches", batchHandlers.CreateBatch)
+			v1.GET("/batches/:id", batchHandlers.GetBatch)
+			v1.GET("/batches/:id/results", batchHandlers.GetBatchResults)
+			v1.POST("/batches/:id/cancel", batchHandlers.CancelBatch)
+		}
+		if s.files != nil {
+			filesHandlers := openai.NewOpenAIFilesAPIHandler(s.handlers, s.files)
+			v1.POST("/files", filesHandlers.UploadFile)
+			v1.GET("/files", filesHandlers.ListFiles)
+			v1.GET("/files/:id", filesHandlers.GetFile)
+			v1.DELETE("/files/:id", filesHandlers.DeleteFile)
+		}
 	}
 
 	// Gemini compatible API routes
 	v1beta := s.engine.Group("/v1beta")
 	v1beta.Use(middleware.RequestSizeLimitMiddleware(s.cfg.MaxRequestSize))
 	v1beta.Use(s.conditionalAuthMiddleware())
+	v1beta.Use(s.rateLimiter.Middleware())
 	{
 		v1beta.GET("/models", geminiHandlers.GeminiModels)
 		v1beta.POST("/models/:action", geminiHandlers.GeminiHandler)
@@ -120,6 +139,7 @@ func (s *Server) setupRoutes() {
 	}
 
 	s.engine.GET("/anthropic/callback", oauthCallbackHandler("anthropic"))
+	s.engine.GET("/claude/callback", oauthCallbackHandler("claude"))
 	s.engine.GET("/codex/callback", oauthCallbackHandler("codex"))
 	s.engine.GET("/google/callback", oauthCallbackHandler("gemini"))
 	s.engine.GET("/gemini/callback", oauthCallbackHandler("gemini")) // alias
@@ -213,6 +233,11 @@ func AuthMiddleware(manager *access.Manager) gin.HandlerFunc {
 		if err == nil {
 			if result != nil {
 				c.Set("apiKey", result.Principal)
+				// Usage is attributed to a hash of the key; the raw key never
+				// leaves the gin context.
+				if id := usage.APIKeyID(result.Principal); id != "" {
+					c.Request = c.Request.WithContext(usage.WithAPIKeyID(c.Request.Context(), id))
+				}
 				c.Set("accessProvider", result.Provider)
 				if len(result.Metadata) > 0 {
 					c.Set("accessMetadata", result.Metadata)
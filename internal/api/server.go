@@ -19,14 +19,18 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/access"
 	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format/openai"
 	managementHandlers "github.com/nghyane/llm-mux/internal/api/handlers/management"
 	"github.com/nghyane/llm-mux/internal/api/middleware"
 	"github.com/nghyane/llm-mux/internal/api/modules"
 	ampmodule "github.com/nghyane/llm-mux/internal/api/modules/amp"
+	"github.com/nghyane/llm-mux/internal/batch"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/files"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/telemetry"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
 	"gopkg.in/yaml.v3"
@@ -124,6 +128,14 @@ type Server struct {
 
 	mgmt      *managementHandlers.Handler
 	ampModule *ampmodule.AmpModule
+	batches   *batch.Runner
+	files     *files.Registry
+
+	// rateLimiter throttles the public API routes; a no-op unless configured.
+	rateLimiter *middleware.RateLimiter
+
+	// inFlight counts requests being served, for shutdown draining.
+	inFlight *atomic.Int64
 
 	managementRoutesRegistered atomic.Bool
 	managementRoutesEnabled    atomic.Bool
@@ -161,6 +173,10 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 		optionState.engineConfigurator(engine)
 	}
 
+	inFlight := new(atomic.Int64)
+	engine.Use(middleware.InFlightMiddleware(inFlight))
+	engine.Use(middleware.RequestIDMiddleware())
+	engine.Use(telemetry.Middleware())
 	engine.Use(log.GinLogrusLogger())
 	engine.Use(log.GinLogrusRecovery())
 	for _, mw := range optionState.extraMiddleware {
@@ -195,6 +211,7 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 	}
 	s := &Server{
 		engine:         engine,
+		inFlight:       inFlight,
 		handlers:       format.NewBaseAPIHandlers(&cfg.SDKConfig, &cfg.Routing, authManager, providerNames),
 		cfg:            cfg,
 		accessManager:  accessManager,
@@ -210,6 +227,7 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 	s.applyAccessConfig(nil, cfg)
 	if authManager != nil {
 		authManager.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second)
+		authManager.SetQueueConfig(cfg.RequestQueue.Limits())
 	}
 	provider.SetQuotaCooldownDisabled(cfg.DisableCooling)
 
@@ -227,6 +245,11 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 	s.mgmt.SetLogDirectory(logDir)
 	s.localPassword = optionState.localPassword
 
+	s.batches = newBatchRunner(cfg, s.handlers)
+	s.files = newFileRegistry(cfg)
+	s.handlers.Files = s.files
+	s.rateLimiter = middleware.NewRateLimiter(cfg.RateLimit)
+
 	// Setup routes
 	s.setupRoutes()
 
@@ -301,10 +324,22 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// Stop gracefully shuts down the API server without interrupting any
-// active connections.
+// shutdownProgressInterval is how often Stop logs the requests it is still waiting on.
+const shutdownProgressInterval = 5 * time.Second
+
+// InFlight returns the number of requests currently being served.
+func (s *Server) InFlight() int64 {
+	if s == nil || s.inFlight == nil {
+		return 0
+	}
+	return s.inFlight.Load()
+}
+
+// Stop gracefully shuts down the API server. It stops accepting connections
+// at once, then waits for in-flight requests, streams included, until ctx is
+// done; connections still open at that point are closed.
 // Parameters:
-//   - ctx: The context for graceful shutdown
+//   - ctx: The context bounding the grace period
 //
 // Returns:
 //   - error: An error if the server fails to stop
@@ -318,20 +353,96 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
-	// Shutdown the HTTP server.
-	if err := s.server.Shutdown(ctx); err != nil {
-		return fmt.Errorf("failed to shutdown HTTP server: %v", err)
+	if n := s.InFlight(); n > 0 {
+		log.Infof("draining %d in-flight request(s)", n)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.server.Shutdown(ctx) }()
+	ticker := time.NewTicker(shutdownProgressInterval)
+	defer ticker.Stop()
+	var errShutdown error
+wait:
+	for {
+		select {
+		case errShutdown = <-done:
+			break wait
+		case <-ticker.C:
+			log.Infof("waiting for %d in-flight request(s) to finish", s.InFlight())
+		}
+	}
+	if errShutdown != nil {
+		log.Warnf("shutdown grace period expired with %d request(s) in flight, closing connections", s.InFlight())
+		_ = s.server.Close()
+	}
+
+	// Stop batch workers; unfinished batches resume on the next start
+	if s.batches != nil {
+		s.batches.Stop()
 	}
 
-	// Stop usage persistence and flush pending writes
+	// Deliver queued usage records, then stop persistence and flush pending writes
+	usage.StopDefault()
 	if err := usage.Stop(); err != nil {
 		log.Warnf("Failed to stop usage persistence: %v", err)
 	}
 
+	if errShutdown != nil {
+		return fmt.Errorf("failed to shutdown HTTP server: %v", errShutdown)
+	}
 	log.Debug("API server stopped")
 	return nil
 }
 
+// newBatchRunner starts the /v1/batches job runner. It returns nil, leaving the
+// batch endpoints unregistered, when the state directory cannot be used.
+func newBatchRunner(cfg *config.Config, handlers *format.BaseAPIHandler) *batch.Runner {
+	dir := cfg.Batch.Dir
+	if dir == "" {
+		base := config.CredentialsDir()
+		if base == "" {
+			log.Warn("Batch API disabled: no credentials directory for batch state")
+			return nil
+		}
+		dir = filepath.Join(base, "batches")
+	}
+	store, err := batch.NewStore(dir)
+	if err != nil {
+		log.Warnf("Batch API disabled: %v", err)
+		return nil
+	}
+	runner := batch.NewRunner(store, openai.BatchExecutor(handlers), cfg.Batch.Concurrency)
+	if err := runner.Start(); err != nil {
+		log.Warnf("Batch API disabled: %v", err)
+		return nil
+	}
+	return runner
+}
+
+// newFileRegistry loads the /v1/files records. It returns nil, leaving the
+// files endpoints unregistered, when the state directory cannot be used.
+func newFileRegistry(cfg *config.Config) *files.Registry {
+	dir := cfg.Files.Dir
+	if dir == "" {
+		base := config.CredentialsDir()
+		if base == "" {
+			log.Warn("Files API disabled: no credentials directory for file records")
+			return nil
+		}
+		dir = filepath.Join(base, "files")
+	}
+	store, err := files.NewStore(dir)
+	if err != nil {
+		log.Warnf("Files API disabled: %v", err)
+		return nil
+	}
+	registry, err := files.NewRegistry(store)
+	if err != nil {
+		log.Warnf("Files API disabled: %v", err)
+		return nil
+	}
+	return registry
+}
+
 func (s *Server) applyAccessConfig(oldCfg, newCfg *config.Config) {
 	if s == nil || s.accessManager == nil || newCfg == nil {
 		return
@@ -400,6 +511,10 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	}
 	if s.handlers != nil && s.handlers.AuthManager != nil {
 		s.handlers.AuthManager.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second)
+		s.handlers.AuthManager.SetQueueConfig(cfg.RequestQueue.Limits())
+	}
+	if s.rateLimiter != nil {
+		s.rateLimiter.Update(cfg.RateLimit)
 	}
 
 	// Update log level dynamically when debug flag changes
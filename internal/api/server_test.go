@@ -1,17 +1,22 @@
 package api
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	gin "github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/access"
+	configaccess "github.com/nghyane/llm-mux/internal/access/config_access"
 	proxyconfig "github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
 )
 
 func newTestServer(t *testing.T) *Server {
@@ -34,6 +39,8 @@ func newTestServer(t *testing.T) *Server {
 		Debug:         true,
 		LoggingToFile: false,
 		Usage:         proxyconfig.UsageConfig{DSN: ""},
+		Batch:         proxyconfig.BatchConfig{Dir: filepath.Join(tmpDir, "batches")},
+		Files:         proxyconfig.FilesConfig{Dir: filepath.Join(tmpDir, "files")},
 	}
 
 	authManager := provider.NewManager(nil, nil, nil)
@@ -108,3 +115,206 @@ func TestAmpProviderModelRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestIDEcho(t *testing.T) {
+	server := newTestServer(t)
+
+	send := func(requestID string) string {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		req.Header.Set("Authorization", "Bearer test-key")
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		rr := httptest.NewRecorder()
+		server.engine.ServeHTTP(rr, req)
+		return rr.Header().Get("X-Request-ID")
+	}
+
+	if got := send("trace-123"); got != "trace-123" {
+		t.Errorf("Expected client request ID to be echoed, got %q", got)
+	}
+	generated := send("")
+	if generated == "" {
+		t.Fatal("Expected a generated request ID when none is supplied")
+	}
+	if other := send(""); other == generated {
+		t.Errorf("Expected a fresh request ID per request, got %q twice", other)
+	}
+	if got := send("bad/id"); got == "bad/id" || got == "" {
+		t.Errorf("Expected an unsafe client ID to be replaced, got %q", got)
+	}
+}
+
+func TestAPIKeyScopeRejectsDisallowedModels(t *testing.T) {
+	server := newTestServer(t)
+	configaccess.Register()
+	server.cfg.Access.Providers = []proxyconfig.AccessProvider{*proxyconfig.MakeInlineAPIKeyProvider([]string{"test-key"})}
+	if _, err := access.ApplyAccessProviders(server.accessManager, nil, server.cfg); err != nil {
+		t.Fatalf("failed to apply access providers: %v", err)
+	}
+	for _, id := range []string{"scope-cheap-1", "scope-premium-1"} {
+		clientID := "scope-client-" + id
+		registry.GetGlobalRegistry().RegisterClient(clientID, "scopetest", []*registry.ModelInfo{{ID: id}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(clientID) })
+	}
+	server.handlers.Cfg.APIKeyScopes = []proxyconfig.APIKeyScope{{APIKey: "test-key", Models: []string{"scope-cheap-*"}}}
+
+	send := func(model string) int {
+		body := `{"model":"` + model + `","messages":[{"role":"user","content":"hi"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-key")
+		rr := httptest.NewRecorder()
+		server.engine.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := send("scope-premium-1"); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a model outside the key's scope, got %d", code)
+	}
+	if code := send("scope-cheap-1"); code == http.StatusForbidden {
+		t.Errorf("Expected an allowed model to pass the scope check, got %d", code)
+	}
+
+	server.handlers.Cfg.APIKeyScopes[0].Providers = []string{"gemini"}
+	if code := send("scope-cheap-1"); code != http.StatusForbidden {
+		t.Errorf("Expected 403 when no allowed provider serves the model, got %d", code)
+	}
+}
+
+func TestServerStopDrainsInFlightRequests(t *testing.T) {
+	server := newTestServer(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	server.engine.GET("/test/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.String(http.StatusOK, "done")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = server.server.Serve(ln) }()
+
+	respCh := make(chan int, 1)
+	go func() {
+		resp, errGet := http.Get("http://" + ln.Addr().String() + "/test/slow")
+		if errGet != nil {
+			respCh <- 0
+			return
+		}
+		resp.Body.Close()
+		respCh <- resp.StatusCode
+	}()
+	<-started
+	if got := server.InFlight(); got != 1 {
+		t.Errorf("InFlight = %d, want 1", got)
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stopped <- server.Stop(ctx)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a request was in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if code := <-respCh; code != http.StatusOK {
+		t.Errorf("in-flight request finished with %d, want 200", code)
+	}
+}
+
+func TestManagementDashboard(t *testing.T) {
+	t.Setenv("LLM_MUX_MANAGEMENT_KEY", "dashboard-key")
+	server := newTestServer(t)
+
+	send := func(path string, auth func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "127.0.0.1:40000"
+		if auth != nil {
+			auth(req)
+		}
+		rr := httptest.NewRecorder()
+		server.engine.ServeHTTP(rr, req)
+		return rr
+	}
+	basic := func(req *http.Request) { req.SetBasicAuth("admin", "dashboard-key") }
+
+	rr := send("/manage/dashboard", nil)
+	if rr.Code != http.StatusUnauthorized || !strings.HasPrefix(rr.Header().Get("WWW-Authenticate"), "Basic ") {
+		t.Fatalf("Expected a Basic challenge without credentials, got %d %q", rr.Code, rr.Header().Get("WWW-Authenticate"))
+	}
+	if rr := send("/manage/dashboard", func(req *http.Request) { req.SetBasicAuth("admin", "wrong") }); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 for a wrong key, got %d", rr.Code)
+	}
+
+	rr = send("/manage/dashboard", basic)
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("Expected the dashboard page, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if body := rr.Body.String(); !strings.Contains(body, "<title>llm-mux dashboard</title>") || strings.Contains(body, "src=\"http") {
+		t.Error("Expected a self-contained dashboard page")
+	}
+	for _, path := range []string{"/manage/health", "/manage/usage", "/manage/auth-files"} {
+		if rr := send(path, basic); rr.Code != http.StatusOK {
+			t.Errorf("%s: expected 200 with Basic credentials, got %d", path, rr.Code)
+		}
+	}
+
+	// API clients are not challenged.
+	if rr := send("/v1/management/health", nil); rr.Code != http.StatusUnauthorized || rr.Header().Get("WWW-Authenticate") != "" {
+		t.Errorf("Expected a plain 401 from the management API, got %d %q", rr.Code, rr.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestManagementSelector(t *testing.T) {
+	t.Setenv("LLM_MUX_MANAGEMENT_KEY", "selector-key")
+	server := newTestServer(t)
+	// The new strategy is saved to the config file.
+	if err := os.WriteFile(server.configFilePath, []byte("port: 0\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	send := func(method, body, contentType string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/manage/selector", strings.NewReader(body))
+		req.RemoteAddr = "127.0.0.1:40000"
+		req.Header.Set("Authorization", "Bearer selector-key")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		server.engine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send(http.MethodGet, "", ""); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"strategy":"default"`) {
+		t.Fatalf("GET selector: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := send(http.MethodPost, `{"strategy":"lru"}`, "application/json"); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"strategy":"lru"`) {
+		t.Fatalf("POST lru: %d %s", rr.Code, rr.Body.String())
+	}
+	if got := server.handlers.AuthManager.SelectionStrategy(); got != "lru" {
+		t.Errorf("manager strategy = %q, want lru", got)
+	}
+	if got := server.cfg.AccountSelection.Strategy; got != "lru" {
+		t.Errorf("config strategy = %q, want lru", got)
+	}
+
+	if rr := send(http.MethodPost, `{"strategy":"fastest"}`, "application/json"); rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown strategy: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := send(http.MethodPost, `{"strategy":"round-robin"}`, "text/plain"); rr.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("non-JSON body: %d %s", rr.Code, rr.Body.String())
+	}
+	if got := server.handlers.AuthManager.SelectionStrategy(); got != "lru" {
+		t.Errorf("manager strategy after rejected requests = %q, want lru", got)
+	}
+}
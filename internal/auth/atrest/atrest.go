@@ -0,0 +1,252 @@
+// Package atrest encrypts auth files at rest. Files are sealed with AES-256-GCM
+// under a key derived from a passphrase with Argon2id, and stored as a small
+// JSON envelope that carries the salt and nonce, so each file can be opened on
+// its own. Plaintext files are always read unchanged, which keeps stores
+// written before encryption was enabled working.
+package atrest
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"golang.org/x/crypto/argon2"
+)
+
+// PassphraseEnv names the environment variable holding the passphrase.
+const PassphraseEnv = "LLM_MUX_AUTH_PASSPHRASE"
+
+const (
+	envelopeVersion = 1
+	kdfArgon2id     = "argon2id"
+
+	// Argon2id parameters, the second recommended option of RFC 9106.
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	keyLen       = 32
+	saltLen      = 16
+)
+
+var (
+	// ErrNoPassphrase is returned when an encrypted file is read without a passphrase.
+	ErrNoPassphrase = errors.New("auth file is encrypted but no passphrase is configured (set " + PassphraseEnv + " or auth-encryption.passphrase)")
+	// ErrWrongPassphrase is returned when an encrypted file cannot be opened
+	// with the configured passphrase.
+	ErrWrongPassphrase = errors.New("auth file cannot be decrypted: wrong passphrase or corrupted file")
+)
+
+// envelope is the on-disk form of an encrypted auth file.
+type envelope struct {
+	Version int    `json:"llm-mux-encrypted"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+type keyring struct {
+	passphrase []byte
+	salt       []byte // used for every file sealed by this process
+	mu         sync.Mutex
+	keys       map[string][]byte // derived keys by salt
+}
+
+var (
+	ringMu sync.RWMutex
+	ring   *keyring
+)
+
+// Configure sets the passphrase used to seal and open auth files. An empty
+// passphrase turns encryption off; encrypted files then fail to open.
+func Configure(passphrase string) error {
+	if passphrase == "" {
+		ringMu.Lock()
+		ring = nil
+		ringMu.Unlock()
+		return nil
+	}
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("auth encryption: generate salt: %w", err)
+	}
+	ringMu.Lock()
+	ring = &keyring{passphrase: []byte(passphrase), salt: salt, keys: make(map[string][]byte)}
+	ringMu.Unlock()
+	return nil
+}
+
+// Enabled reports whether new files are written encrypted.
+func Enabled() bool {
+	ringMu.RLock()
+	defer ringMu.RUnlock()
+	return ring != nil
+}
+
+func currentRing() *keyring {
+	ringMu.RLock()
+	defer ringMu.RUnlock()
+	return ring
+}
+
+func (r *keyring) key(salt []byte) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if k, ok := r.keys[string(salt)]; ok {
+		return k
+	}
+	k := argon2.IDKey(r.passphrase, salt, argonTime, argonMemory, argonThreads, keyLen)
+	r.keys[string(salt)] = k
+	return k
+}
+
+// IsEncrypted reports whether data is an encrypted envelope.
+func IsEncrypted(data []byte) bool {
+	if !bytes.Contains(data, []byte(`"llm-mux-encrypted"`)) {
+		return false
+	}
+	var env envelope
+	return json.Unmarshal(data, &env) == nil && env.Version > 0
+}
+
+// Seal encrypts plain when encryption is enabled and returns it unchanged otherwise.
+func Seal(plain []byte) ([]byte, error) {
+	r := currentRing()
+	if r == nil {
+		return plain, nil
+	}
+	return r.seal(plain)
+}
+
+func (r *keyring) seal(plain []byte) ([]byte, error) {
+	gcm, err := newGCM(r.key(r.salt))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("auth encryption: generate nonce: %w", err)
+	}
+	return json.Marshal(envelope{
+		Version: envelopeVersion,
+		KDF:     kdfArgon2id,
+		Salt:    r.salt,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plain, nil),
+	})
+}
+
+// Open decrypts data when it is an encrypted envelope and returns it unchanged
+// otherwise. It fails with ErrNoPassphrase or ErrWrongPassphrase when an
+// encrypted file cannot be opened.
+func Open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("auth encryption: parse envelope: %w", err)
+	}
+	if env.Version != envelopeVersion || env.KDF != kdfArgon2id {
+		return nil, fmt.Errorf("auth encryption: unsupported envelope version %d (%s)", env.Version, env.KDF)
+	}
+	r := currentRing()
+	if r == nil {
+		return nil, ErrNoPassphrase
+	}
+	gcm, err := newGCM(r.key(env.Salt))
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("auth encryption: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// ReadFile reads an auth file, decrypting it if needed.
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plain, nil
+}
+
+// WriteFile seals data when encryption is enabled and writes it to path.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	sealed, err := Seal(data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, perm)
+}
+
+// WriteJSON encodes v as JSON and writes it to path with owner-only permissions.
+func WriteJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data, 0o600)
+}
+
+// CheckDir opens the encrypted auth files in dir until one succeeds, so a
+// missing or wrong passphrase is reported at startup rather than as accounts
+// that silently fail to load. It returns the number of plaintext files found.
+func CheckDir(dir string) (plaintext int, err error) {
+	opened := false
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, errWalk error) error {
+		if errWalk != nil {
+			return errWalk
+		}
+		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".json") {
+			return nil
+		}
+		data, errRead := os.ReadFile(path)
+		if errRead != nil {
+			return nil
+		}
+		if !IsEncrypted(data) {
+			plaintext++
+			return nil
+		}
+		if opened {
+			return nil
+		}
+		if _, errOpen := Open(data); errOpen != nil {
+			return fmt.Errorf("%s: %w", path, errOpen)
+		}
+		opened = true
+		return nil
+	})
+	if walkErr != nil && !errors.Is(walkErr, fs.ErrNotExist) {
+		return plaintext, walkErr
+	}
+	return plaintext, nil
+}
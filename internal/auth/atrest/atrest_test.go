@@ -0,0 +1,80 @@
+package atrest
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func configure(t *testing.T, passphrase string) {
+	t.Helper()
+	if err := Configure(passphrase); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	t.Cleanup(func() { _ = Configure("") })
+}
+
+func TestSealOpen(t *testing.T) {
+	plain := []byte(`{"type":"claude","refresh_token":"rt"}`)
+
+	configure(t, "")
+	if out, err := Seal(plain); err != nil || !bytes.Equal(out, plain) {
+		t.Fatalf("Seal() without passphrase = %q, %v; want plaintext", out, err)
+	}
+
+	configure(t, "correct horse")
+	sealed, err := Seal(plain)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !IsEncrypted(sealed) || bytes.Contains(sealed, []byte("refresh_token")) {
+		t.Fatalf("sealed data is not encrypted: %s", sealed)
+	}
+	opened, err := Open(sealed)
+	if err != nil || !bytes.Equal(opened, plain) {
+		t.Fatalf("Open() = %q, %v; want %q", opened, err, plain)
+	}
+	if opened, err = Open(plain); err != nil || !bytes.Equal(opened, plain) {
+		t.Fatalf("Open() plaintext = %q, %v; want passthrough", opened, err)
+	}
+
+	configure(t, "wrong horse")
+	if _, err = Open(sealed); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("Open() with wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+
+	configure(t, "")
+	if _, err = Open(sealed); !errors.Is(err, ErrNoPassphrase) {
+		t.Fatalf("Open() without passphrase error = %v, want ErrNoPassphrase", err)
+	}
+}
+
+func TestCheckDir(t *testing.T) {
+	dir := t.TempDir()
+	configure(t, "correct horse")
+	if err := WriteJSON(filepath.Join(dir, "claude-a.json"), map[string]any{"type": "claude"}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "codex-b.json"), []byte(`{"type":"codex"}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	plaintext, err := CheckDir(dir)
+	if err != nil || plaintext != 1 {
+		t.Fatalf("CheckDir() = %d, %v; want 1, nil", plaintext, err)
+	}
+	data, err := ReadFile(filepath.Join(dir, "claude-a.json"))
+	if err != nil || string(data) != `{"type":"claude"}` {
+		t.Fatalf("ReadFile() = %q, %v", data, err)
+	}
+
+	configure(t, "wrong horse")
+	if _, err = CheckDir(dir); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("CheckDir() with wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+	if _, err = CheckDir(filepath.Join(dir, "missing")); err != nil {
+		t.Fatalf("CheckDir() on missing dir error = %v", err)
+	}
+}
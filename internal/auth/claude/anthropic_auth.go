@@ -46,7 +46,8 @@ type tokenResponse struct {
 // It provides methods for generating authorization URLs, exchanging codes for tokens,
 // and refreshing expired tokens using PKCE for enhanced security.
 type ClaudeAuth struct {
-	httpClient *http.Client
+	httpClient  *http.Client
+	redirectURI string
 }
 
 // NewClaudeAuth creates a new Anthropic authentication service.
@@ -58,7 +59,17 @@ type ClaudeAuth struct {
 //   - *ClaudeAuth: A new Claude authentication service instance
 func NewClaudeAuth(cfg *config.Config) *ClaudeAuth {
 	return &ClaudeAuth{
-		httpClient: util.SetProxy(&cfg.SDKConfig, &http.Client{}),
+		httpClient:  util.SetProxy(&cfg.SDKConfig, &http.Client{}),
+		redirectURI: redirectURI,
+	}
+}
+
+// SetRedirectURI replaces the redirect URI sent when building the authorization
+// URL and exchanging the code, for callbacks reached through a reverse proxy.
+// An empty uri keeps the default local callback.
+func (o *ClaudeAuth) SetRedirectURI(uri string) {
+	if uri != "" {
+		o.redirectURI = uri
 	}
 }
 
@@ -82,7 +93,7 @@ func (o *ClaudeAuth) GenerateAuthURL(state string, pkceCodes *PKCECodes) (string
 		"code":                  {"true"},
 		"client_id":             {anthropicClientID},
 		"response_type":         {"code"},
-		"redirect_uri":          {redirectURI},
+		"redirect_uri":          {o.redirectURI},
 		"scope":                 {"org:create_api_key user:profile user:inference"},
 		"code_challenge":        {pkceCodes.CodeChallenge},
 		"code_challenge_method": {"S256"},
@@ -134,7 +145,7 @@ func (o *ClaudeAuth) ExchangeCodeForTokens(ctx context.Context, code, state stri
 		"state":         state,
 		"grant_type":    "authorization_code",
 		"client_id":     anthropicClientID,
-		"redirect_uri":  redirectURI,
+		"redirect_uri":  o.redirectURI,
 		"code_verifier": pkceCodes.CodeVerifier,
 	}
 
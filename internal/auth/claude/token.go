@@ -5,7 +5,7 @@ package claude
 
 import (
 	"fmt"
-	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	"os"
 	"path/filepath"
 
@@ -39,15 +39,7 @@ func (ts *ClaudeTokenStorage) SaveTokenToFile(authFilePath string) error {
 		return fmt.Errorf("failed to create directory: %v", err)
 	}
 
-	f, err := os.OpenFile(authFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("failed to create token file: %w", err)
-	}
-	defer func() {
-		_ = f.Close()
-	}()
-
-	if err = json.NewEncoder(f).Encode(ts); err != nil {
+	if err := atrest.WriteJSON(authFilePath, ts); err != nil {
 		return fmt.Errorf("failed to write token to file: %w", err)
 	}
 	return nil
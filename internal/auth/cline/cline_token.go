@@ -10,7 +10,7 @@ package cline
 
 import (
 	"fmt"
-	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	"os"
 	"path/filepath"
 
@@ -42,15 +42,7 @@ func (ts *ClineTokenStorage) SaveTokenToFile(authFilePath string) error {
 		return fmt.Errorf("failed to create directory: %v", err)
 	}
 
-	f, err := os.OpenFile(authFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("failed to create token file: %w", err)
-	}
-	defer func() {
-		_ = f.Close()
-	}()
-
-	if err = json.NewEncoder(f).Encode(ts); err != nil {
+	if err := atrest.WriteJSON(authFilePath, ts); err != nil {
 		return fmt.Errorf("failed to write token to file: %w", err)
 	}
 	return nil
@@ -30,14 +30,25 @@ const (
 // It manages the HTTP client and provides methods for generating authorization URLs,
 // exchanging authorization codes for tokens, and refreshing access tokens.
 type CodexAuth struct {
-	httpClient *http.Client
+	httpClient  *http.Client
+	redirectURI string
 }
 
 // NewCodexAuth creates a new CodexAuth service instance.
 // It initializes an HTTP client with proxy settings from the provided configuration.
 func NewCodexAuth(cfg *config.Config) *CodexAuth {
 	return &CodexAuth{
-		httpClient: util.SetProxy(&cfg.SDKConfig, &http.Client{}),
+		httpClient:  util.SetProxy(&cfg.SDKConfig, &http.Client{}),
+		redirectURI: redirectURI,
+	}
+}
+
+// SetRedirectURI replaces the redirect URI sent when building the authorization
+// URL and exchanging the code, for callbacks reached through a reverse proxy.
+// An empty uri keeps the default local callback.
+func (o *CodexAuth) SetRedirectURI(uri string) {
+	if uri != "" {
+		o.redirectURI = uri
 	}
 }
 
@@ -52,7 +63,7 @@ func (o *CodexAuth) GenerateAuthURL(state string, pkceCodes *PKCECodes) (string,
 	params := url.Values{
 		"client_id":                  {openaiClientID},
 		"response_type":              {"code"},
-		"redirect_uri":               {redirectURI},
+		"redirect_uri":               {o.redirectURI},
 		"scope":                      {"openid email profile offline_access"},
 		"state":                      {state},
 		"code_challenge":             {pkceCodes.CodeChallenge},
@@ -79,7 +90,7 @@ func (o *CodexAuth) ExchangeCodeForTokens(ctx context.Context, code string, pkce
 		"grant_type":    {"authorization_code"},
 		"client_id":     {openaiClientID},
 		"code":          {code},
-		"redirect_uri":  {redirectURI},
+		"redirect_uri":  {o.redirectURI},
 		"code_verifier": {pkceCodes.CodeVerifier},
 	}
 
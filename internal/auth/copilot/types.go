@@ -3,10 +3,10 @@ package copilot
 
 import (
 	"fmt"
-	"github.com/nghyane/llm-mux/internal/json"
 	"os"
 	"path/filepath"
 
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	"github.com/nghyane/llm-mux/internal/misc"
 )
 
@@ -48,13 +48,7 @@ func (c *CopilotCredentials) SaveToFile(authFilePath string) error {
 		return fmt.Errorf("failed to create directory: %v", err)
 	}
 
-	f, err := os.OpenFile(authFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("failed to create token file: %w", err)
-	}
-	defer func() { _ = f.Close() }()
-
-	if err = json.NewEncoder(f).Encode(c); err != nil {
+	if err := atrest.WriteJSON(authFilePath, c); err != nil {
 		return fmt.Errorf("failed to write token: %w", err)
 	}
 	return nil
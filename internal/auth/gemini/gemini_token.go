@@ -5,13 +5,12 @@ package gemini
 
 import (
 	"fmt"
-	"github.com/nghyane/llm-mux/internal/json"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	"github.com/nghyane/llm-mux/internal/misc"
-	log "github.com/nghyane/llm-mux/internal/logging"
 )
 
 // GeminiTokenStorage stores OAuth2 token information for Google Gemini API authentication.
@@ -39,17 +38,7 @@ func (ts *GeminiTokenStorage) SaveTokenToFile(authFilePath string) error {
 		return fmt.Errorf("failed to create directory: %v", err)
 	}
 
-	f, err := os.OpenFile(authFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("failed to create token file: %w", err)
-	}
-	defer func() {
-		if errClose := f.Close(); errClose != nil {
-			log.Errorf("failed to close file: %v", errClose)
-		}
-	}()
-
-	if err = json.NewEncoder(f).Encode(ts); err != nil {
+	if err := atrest.WriteJSON(authFilePath, ts); err != nil {
 		return fmt.Errorf("failed to write token to file: %w", err)
 	}
 	return nil
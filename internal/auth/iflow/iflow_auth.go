@@ -55,14 +55,18 @@ func NewIFlowAuth(cfg *config.Config) *IFlowAuth {
 // AuthorizationURL builds the authorization URL and matching redirect URI.
 func (ia *IFlowAuth) AuthorizationURL(state string, port int) (authURL, redirectURI string) {
 	redirectURI = fmt.Sprintf("http://localhost:%d/oauth2callback", port)
+	return ia.AuthorizationURLFor(state, redirectURI), redirectURI
+}
+
+// AuthorizationURLFor builds the authorization URL for an explicit redirect URI.
+func (ia *IFlowAuth) AuthorizationURLFor(state, redirectURI string) string {
 	values := url.Values{}
 	values.Set("loginMethod", "phone")
 	values.Set("type", "phone")
 	values.Set("redirect", redirectURI)
 	values.Set("state", state)
 	values.Set("client_id", iFlowOAuthClientID)
-	authURL = fmt.Sprintf("%s?%s", iFlowOAuthAuthorizeEndpoint, values.Encode())
-	return authURL, redirectURI
+	return fmt.Sprintf("%s?%s", iFlowOAuthAuthorizeEndpoint, values.Encode())
 }
 
 // ExchangeCodeForTokens exchanges an authorization code for access and refresh tokens.
@@ -2,7 +2,7 @@ package iflow
 
 import (
 	"fmt"
-	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	"os"
 	"path/filepath"
 
@@ -31,13 +31,7 @@ func (ts *IFlowTokenStorage) SaveTokenToFile(authFilePath string) error {
 		return fmt.Errorf("iflow token: create directory failed: %w", err)
 	}
 
-	f, err := os.OpenFile(authFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("iflow token: create file failed: %w", err)
-	}
-	defer func() { _ = f.Close() }()
-
-	if err = json.NewEncoder(f).Encode(ts); err != nil {
+	if err := atrest.WriteJSON(authFilePath, ts); err != nil {
 		return fmt.Errorf("iflow token: encode token failed: %w", err)
 	}
 	return nil
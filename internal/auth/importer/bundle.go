@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func init() {
+	Register(bundleSource{})
+}
+
+// Export bundle identification. ReadBundle rejects other formats and newer versions.
+const (
+	BundleFormat  = "llm-mux-export"
+	BundleVersion = 1
+)
+
+// redactedValue replaces secrets in bundles exported without them.
+const redactedValue = provider.RedactedValue
+
+// Bundle is the portable backup written by `llm-mux export` and read back by
+// `llm-mux import --from llm-mux`.
+type Bundle struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// ContainsSecrets reports whether tokens and keys are included in plain
+	// text. When false every secret value is replaced with "<redacted>".
+	ContainsSecrets bool            `json:"contains_secrets"`
+	Config          map[string]any  `json:"config,omitempty"`
+	Accounts        []BundleAccount `json:"accounts"`
+}
+
+// BundleAccount is one auth record in a bundle.
+type BundleAccount struct {
+	ID       string         `json:"id"`
+	Provider string         `json:"provider"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// NewBundle builds a bundle from auth records and the parsed config file.
+// Without secrets, token, key and password values are redacted so the bundle
+// only documents the structure of the deployment.
+func NewBundle(auths []*provider.Auth, cfg map[string]any, secrets bool) *Bundle {
+	b := &Bundle{
+		Format:          BundleFormat,
+		Version:         BundleVersion,
+		CreatedAt:       time.Now().UTC(),
+		ContainsSecrets: secrets,
+		Config:          cfg,
+		Accounts:        make([]BundleAccount, 0, len(auths)),
+	}
+	if !secrets && cfg != nil {
+		b.Config = provider.RedactSecrets(cfg).(map[string]any)
+	}
+	for _, a := range auths {
+		if a == nil || a.Metadata == nil {
+			continue
+		}
+		metadata := a.Metadata
+		if !secrets {
+			metadata = provider.RedactSecrets(metadata).(map[string]any)
+		}
+		b.Accounts = append(b.Accounts, BundleAccount{ID: a.ID, Provider: a.Provider, Metadata: metadata})
+	}
+	sort.Slice(b.Accounts, func(i, j int) bool { return b.Accounts[i].ID < b.Accounts[j].ID })
+	return b
+}
+
+// ReadBundle loads and checks an export bundle.
+func ReadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Bundle
+	if err = json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if b.Format != BundleFormat {
+		return nil, fmt.Errorf("%s is not an llm-mux export", path)
+	}
+	if b.Version > BundleVersion {
+		return nil, fmt.Errorf("%s has export version %d, this build reads up to %d", path, b.Version, BundleVersion)
+	}
+	return &b, nil
+}
+
+// bundleSource reads bundles written by `llm-mux export`.
+type bundleSource struct{}
+
+func (bundleSource) Name() string { return "llm-mux" }
+
+func (bundleSource) Description() string { return "Backup written by llm-mux export" }
+
+func (bundleSource) DefaultPath() string { return "" }
+
+func (bundleSource) Read(path string) ([]Account, error) {
+	b, err := ReadBundle(path)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]Account, 0, len(b.Accounts))
+	for _, acc := range b.Accounts {
+		origin := acc.ID
+		if origin == "" {
+			origin = filepath.Base(path)
+		}
+		if !b.ContainsSecrets {
+			accounts = append(accounts, Account{Origin: origin, SkipReason: "exported without secrets"})
+			continue
+		}
+		if acc.ID == "" || acc.Provider == "" || acc.Metadata == nil {
+			accounts = append(accounts, Account{Origin: origin, SkipReason: "incomplete account entry"})
+			continue
+		}
+		accounts = append(accounts, Account{
+			Origin: origin,
+			Auth:   &provider.Auth{ID: acc.ID, Provider: acc.Provider, Metadata: acc.Metadata},
+		})
+	}
+	return accounts, nil
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// ErrValidationUnsupported is returned by a ValidateFunc for providers it
+// cannot check. The account is still imported and reported as not validated.
+var ErrValidationUnsupported = errors.New("validation not supported for this provider")
+
+// ValidateFunc checks an account against its provider before it is saved and
+// returns the auth to persist, e.g. with refreshed tokens.
+type ValidateFunc func(ctx context.Context, auth *provider.Auth) (*provider.Auth, error)
+
+// Status is the result of importing one account.
+type Status string
+
+const (
+	StatusImported Status = "imported"
+	StatusSkipped  Status = "skipped"
+	StatusFailed   Status = "failed"
+)
+
+// Outcome records what happened to one account.
+type Outcome struct {
+	Origin   string
+	ID       string
+	Provider string
+	Status   Status
+	// Detail is the skip or failure reason, or a note on an imported account.
+	Detail string
+}
+
+// Summary lists the outcome of every account in an import run.
+type Summary struct {
+	Outcomes []Outcome
+}
+
+// Count returns the number of accounts with the given status.
+func (s Summary) Count(status Status) int {
+	n := 0
+	for _, o := range s.Outcomes {
+		if o.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Options controls an import run.
+type Options struct {
+	// Validate is called before each account is saved. Nil skips validation.
+	Validate ValidateFunc
+	// Overwrite replaces auth records that already exist with the same ID.
+	// By default they are skipped, so re-running an import is harmless.
+	Overwrite bool
+}
+
+// Import validates and saves accounts into store. It only fails as a whole
+// when the existing records cannot be listed; per-account problems are
+// reported in the summary.
+func Import(ctx context.Context, store provider.Store, accounts []Account, opts Options) (Summary, error) {
+	var summary Summary
+	if store == nil {
+		return summary, fmt.Errorf("import: token store is nil")
+	}
+	existing := make(map[string]struct{})
+	if !opts.Overwrite {
+		list, err := store.List(ctx)
+		if err != nil {
+			return summary, fmt.Errorf("import: list existing auths: %w", err)
+		}
+		for _, a := range list {
+			existing[a.ID] = struct{}{}
+		}
+	}
+
+	for _, acc := range accounts {
+		outcome := importAccount(ctx, store, acc, existing, opts)
+		if outcome.Status == StatusImported {
+			existing[outcome.ID] = struct{}{}
+		}
+		summary.Outcomes = append(summary.Outcomes, outcome)
+	}
+	return summary, nil
+}
+
+func importAccount(ctx context.Context, store provider.Store, acc Account, existing map[string]struct{}, opts Options) Outcome {
+	out := Outcome{Origin: acc.Origin, Status: StatusSkipped, Detail: acc.SkipReason}
+	auth := acc.Auth
+	if auth == nil {
+		if out.Detail == "" {
+			out.Detail = "no usable credentials"
+		}
+		return out
+	}
+	out.Provider = auth.Provider
+	out.ID = auth.ID
+	if _, ok := existing[out.ID]; ok && out.ID != "" {
+		out.Detail = "already exists"
+		return out
+	}
+
+	if opts.Validate != nil {
+		validated, err := opts.Validate(ctx, auth)
+		switch {
+		case errors.Is(err, ErrValidationUnsupported):
+			out.Detail = "not validated"
+		case err != nil:
+			out.Status = StatusFailed
+			out.Detail = fmt.Sprintf("validation failed: %v", err)
+			return out
+		case validated != nil:
+			auth = validated
+		}
+	}
+
+	if auth.ID == "" {
+		auth.ID = fileNameFor(auth, acc.Origin)
+		out.ID = auth.ID
+		if _, ok := existing[out.ID]; ok {
+			out.Detail = "already exists"
+			return out
+		}
+	}
+	if auth.FileName == "" {
+		auth.FileName = auth.ID
+	}
+	if auth.Label == "" {
+		auth.Label = metadataString(auth.Metadata, "email")
+	}
+	if auth.Status == "" {
+		auth.Status = provider.StatusActive
+	}
+
+	if _, err := store.Save(ctx, auth); err != nil {
+		out.Status = StatusFailed
+		out.Detail = fmt.Sprintf("save failed: %v", err)
+		return out
+	}
+	out.Status = StatusImported
+	return out
+}
+
+// fileNameFor names an auth the way the login flows do, <provider>-<email>.json,
+// falling back to the origin when the email is unknown.
+func fileNameFor(auth *provider.Auth, origin string) string {
+	part := metadataString(auth.Metadata, "email")
+	if part == "" {
+		part = strings.TrimSuffix(origin, ".json")
+	}
+	return fmt.Sprintf("%s-%s.json", auth.Provider, util.SanitizeFilePart(part))
+}
+
+func metadataString(metadata map[string]any, key string) string {
+	if metadata == nil {
+		return ""
+	}
+	v, _ := metadata[key].(string)
+	return strings.TrimSpace(v)
+}
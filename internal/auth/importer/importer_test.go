@@ -0,0 +1,242 @@
+package importer
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+type memoryStore struct {
+	saved map[string]*provider.Auth
+}
+
+func newMemoryStore(ids ...string) *memoryStore {
+	s := &memoryStore{saved: make(map[string]*provider.Auth)}
+	for _, id := range ids {
+		s.saved[id] = &provider.Auth{ID: id}
+	}
+	return s
+}
+
+func (s *memoryStore) List(context.Context) ([]*provider.Auth, error) {
+	out := make([]*provider.Auth, 0, len(s.saved))
+	for _, a := range s.saved {
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, auth *provider.Auth) (string, error) {
+	s.saved[auth.ID] = auth
+	return auth.ID, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, id string) error {
+	delete(s.saved, id)
+	return nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestCLIProxyAPISourceRead(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "claude-a@example.com.json"), `{"type":"claude","email":"a@example.com","refresh_token":"rt"}`)
+	writeFile(t, filepath.Join(dir, "unknown.json"), `{"type":"mystery"}`)
+	writeFile(t, filepath.Join(dir, "broken.json"), `{`)
+	writeFile(t, filepath.Join(dir, "notes.txt"), `ignored`)
+
+	src, err := Lookup("cliproxyapi")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	accounts, err := src.Read(dir)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(accounts) != 3 {
+		t.Fatalf("len(accounts) = %d, want 3", len(accounts))
+	}
+	byOrigin := make(map[string]Account)
+	for _, a := range accounts {
+		byOrigin[a.Origin] = a
+	}
+	claude := byOrigin["claude-a@example.com.json"]
+	if claude.Auth == nil || claude.Auth.Provider != "claude" || claude.Auth.ID != "claude-a@example.com.json" {
+		t.Fatalf("claude account = %+v", claude)
+	}
+	if byOrigin["unknown.json"].Auth != nil || byOrigin["unknown.json"].SkipReason == "" {
+		t.Fatalf("unknown type should be skipped: %+v", byOrigin["unknown.json"])
+	}
+	if byOrigin["broken.json"].Auth != nil {
+		t.Fatalf("invalid JSON should be skipped: %+v", byOrigin["broken.json"])
+	}
+}
+
+func TestClaudeCodeSourceRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".credentials.json")
+	writeFile(t, path, `{"claudeAiOauth":{"accessToken":"at","refreshToken":"rt","expiresAt":1748658860401}}`)
+
+	accounts, err := claudeCodeSource{}.Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Auth == nil {
+		t.Fatalf("accounts = %+v", accounts)
+	}
+	md := accounts[0].Auth.Metadata
+	if md["type"] != "claude" || md["refresh_token"] != "rt" || md["expired"] == nil {
+		t.Fatalf("metadata = %v", md)
+	}
+	if accounts[0].Auth.ID != "" {
+		t.Fatalf("ID = %q, want empty until the email is known", accounts[0].Auth.ID)
+	}
+}
+
+func TestCodexCLISourceRead(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"email":"dev@example.com","https://api.openai.com/auth":{"chatgpt_account_id":"acct-1"}}`))
+	idToken := "e30." + payload + ".sig"
+	dir := t.TempDir()
+	path := filepath.Join(dir, "auth.json")
+	writeFile(t, path, `{"OPENAI_API_KEY":null,"tokens":{"id_token":"`+idToken+`","access_token":"at","refresh_token":"rt"}}`)
+
+	accounts, err := codexCLISource{}.Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	auth := accounts[0].Auth
+	if auth == nil || auth.ID != "codex-dev@example.com.json" {
+		t.Fatalf("auth = %+v", auth)
+	}
+	if auth.Metadata["account_id"] != "acct-1" || auth.Metadata["email"] != "dev@example.com" {
+		t.Fatalf("metadata = %v", auth.Metadata)
+	}
+
+	apiKeyPath := filepath.Join(dir, "apikey.json")
+	writeFile(t, apiKeyPath, `{"OPENAI_API_KEY":"sk-test"}`)
+	accounts, err = codexCLISource{}.Read(apiKeyPath)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if accounts[0].Auth != nil || accounts[0].SkipReason == "" {
+		t.Fatalf("API key login should be skipped: %+v", accounts[0])
+	}
+}
+
+func TestImportSummary(t *testing.T) {
+	store := newMemoryStore("claude-old@example.com.json")
+	accounts := []Account{
+		{Origin: "old.json", Auth: &provider.Auth{ID: "claude-old@example.com.json", Provider: "claude", Metadata: map[string]any{}}},
+		{Origin: ".credentials.json", Auth: &provider.Auth{Provider: "claude", Metadata: map[string]any{"refresh_token": "good"}}},
+		{Origin: "bad.json", Auth: &provider.Auth{ID: "claude-bad.json", Provider: "claude", Metadata: map[string]any{"refresh_token": "bad"}}},
+		{Origin: "vertex.json", Auth: &provider.Auth{ID: "vertex-p.json", Provider: "vertex", Metadata: map[string]any{}}},
+		{Origin: "unknown.json", SkipReason: "unsupported type"},
+	}
+	validate := func(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+		switch auth.Metadata["refresh_token"] {
+		case "good":
+			auth.Metadata["email"] = "new@example.com"
+			return auth, nil
+		case "bad":
+			return nil, errors.New("invalid_grant")
+		}
+		return nil, ErrValidationUnsupported
+	}
+
+	summary, err := Import(context.Background(), store, accounts, Options{Validate: validate})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if got := summary.Count(StatusImported); got != 2 {
+		t.Fatalf("imported = %d, want 2 (%+v)", got, summary.Outcomes)
+	}
+	if got := summary.Count(StatusSkipped); got != 2 {
+		t.Fatalf("skipped = %d, want 2 (%+v)", got, summary.Outcomes)
+	}
+	if got := summary.Count(StatusFailed); got != 1 {
+		t.Fatalf("failed = %d, want 1 (%+v)", got, summary.Outcomes)
+	}
+	saved := store.saved["claude-new@example.com.json"]
+	if saved == nil || saved.Label != "new@example.com" || saved.FileName != saved.ID {
+		t.Fatalf("validated account not saved under its email: %+v", store.saved)
+	}
+	if _, ok := store.saved["claude-bad.json"]; ok {
+		t.Fatal("account that failed validation was saved")
+	}
+	if summary.Outcomes[3].Detail != "not validated" {
+		t.Fatalf("vertex outcome = %+v", summary.Outcomes[3])
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	auths := []*provider.Auth{{
+		ID:       "claude-a@example.com.json",
+		Provider: "claude",
+		Metadata: map[string]any{"type": "claude", "email": "a@example.com", "refresh_token": "rt", "token_type": "Bearer"},
+	}}
+	cfg := map[string]any{
+		"port":     8317,
+		"api-keys": []any{"sk-1", "sk-2"},
+		"routing":  map[string]any{"aliases": map[string]any{"fast": "gemini-2.5-flash"}},
+		"payload":  map[string]any{"params": map[string]any{"max-tokens": 1024}},
+	}
+
+	redacted := NewBundle(auths, cfg, false)
+	md := redacted.Accounts[0].Metadata
+	if md["refresh_token"] != redactedValue || md["email"] != "a@example.com" || md["token_type"] != "Bearer" {
+		t.Fatalf("redacted metadata = %v", md)
+	}
+	keys := redacted.Config["api-keys"].([]any)
+	if len(keys) != 2 || keys[0] != redactedValue {
+		t.Fatalf("redacted api-keys = %v", keys)
+	}
+	if params := redacted.Config["payload"].(map[string]any)["params"].(map[string]any); params["max-tokens"] != 1024 {
+		t.Fatalf("max-tokens should not be redacted: %v", params)
+	}
+	if auths[0].Metadata["refresh_token"] != "rt" {
+		t.Fatal("NewBundle modified the source metadata")
+	}
+
+	dir := t.TempDir()
+	for name, b := range map[string]*Bundle{"full.json": NewBundle(auths, cfg, true), "redacted.json": redacted} {
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		writeFile(t, filepath.Join(dir, name), string(data))
+	}
+
+	src, err := Lookup("llm-mux")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	accounts, err := src.Read(filepath.Join(dir, "full.json"))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Auth == nil || accounts[0].Auth.Metadata["refresh_token"] != "rt" {
+		t.Fatalf("full bundle accounts = %+v", accounts)
+	}
+	accounts, err = src.Read(filepath.Join(dir, "redacted.json"))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if accounts[0].Auth != nil {
+		t.Fatalf("redacted bundle account should be skipped: %+v", accounts[0])
+	}
+
+	writeFile(t, filepath.Join(dir, "other.json"), `{"format":"something-else"}`)
+	if _, err = ReadBundle(filepath.Join(dir, "other.json")); err == nil {
+		t.Fatal("ReadBundle() accepted a foreign file")
+	}
+}
@@ -0,0 +1,85 @@
+// Package importer maps credentials stored by other LLM proxies and CLI tools
+// into llm-mux auth records, so accounts can be migrated without logging in again.
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// Account is a single credential read from a source.
+type Account struct {
+	// Origin identifies where the account was found, e.g. a file name.
+	Origin string
+	// Auth is the mapped auth record. It is nil when the account cannot be
+	// imported, in which case SkipReason explains why. Auth.ID may be empty
+	// when the source does not know the account email; it is then derived
+	// after validation.
+	Auth *provider.Auth
+	// SkipReason is set when Auth is nil.
+	SkipReason string
+}
+
+// Source reads credentials in a foreign store format.
+type Source interface {
+	// Name is the value accepted by `llm-mux import --from`.
+	Name() string
+	// Description is a one-line summary shown in the command help.
+	Description() string
+	// DefaultPath is read when no path is given. Empty means a path is required.
+	DefaultPath() string
+	// Read returns the accounts found at path, which may be a file or a directory.
+	Read(path string) ([]Account, error)
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]Source)
+)
+
+// Register makes a source available by name, replacing any previous one.
+func Register(src Source) {
+	if src == nil {
+		return
+	}
+	sourcesMu.Lock()
+	sources[strings.ToLower(src.Name())] = src
+	sourcesMu.Unlock()
+}
+
+// Lookup returns the source registered under name.
+func Lookup(name string) (Source, error) {
+	sourcesMu.RLock()
+	src, ok := sources[strings.ToLower(strings.TrimSpace(name))]
+	sourcesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown import source %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return src, nil
+}
+
+// Sources returns all registered sources sorted by name.
+func Sources() []Source {
+	sourcesMu.RLock()
+	out := make([]Source, 0, len(sources))
+	for _, src := range sources {
+		out = append(out, src)
+	}
+	sourcesMu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// Names returns the names of all registered sources, sorted.
+func Names() []string {
+	list := Sources()
+	names := make([]string, len(list))
+	for i, src := range list {
+		names[i] = src.Name()
+	}
+	return names
+}
@@ -0,0 +1,232 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/auth/codex"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func init() {
+	Register(cliProxyAPISource{})
+	Register(claudeCodeSource{})
+	Register(codexCLISource{})
+}
+
+// cliProxyAPITypes are the auth file types shared with CLIProxyAPI, whose
+// on-disk format llm-mux reads unchanged.
+var cliProxyAPITypes = map[string]struct{}{
+	"antigravity": {},
+	"claude":      {},
+	"codex":       {},
+	"gemini":      {},
+	"iflow":       {},
+	"qwen":        {},
+	"vertex":      {},
+}
+
+// cliProxyAPISource reads the auth directory of CLIProxyAPI and its forks.
+type cliProxyAPISource struct{}
+
+func (cliProxyAPISource) Name() string { return "cliproxyapi" }
+
+func (cliProxyAPISource) Description() string {
+	return "CLIProxyAPI auth directory (one JSON file per account)"
+}
+
+func (cliProxyAPISource) DefaultPath() string { return homePath(".cli-proxy-api") }
+
+func (cliProxyAPISource) Read(path string) ([]Account, error) {
+	files, err := jsonFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]Account, 0, len(files))
+	for _, file := range files {
+		origin := filepath.Base(file)
+		metadata, errRead := readJSONObject(file)
+		if errRead != nil {
+			accounts = append(accounts, Account{Origin: origin, SkipReason: errRead.Error()})
+			continue
+		}
+		typ := strings.ToLower(metadataString(metadata, "type"))
+		if _, ok := cliProxyAPITypes[typ]; !ok {
+			accounts = append(accounts, Account{Origin: origin, SkipReason: fmt.Sprintf("unsupported type %q", typ)})
+			continue
+		}
+		metadata["type"] = typ
+		accounts = append(accounts, Account{
+			Origin: origin,
+			Auth: &provider.Auth{
+				ID:       origin,
+				Provider: typ,
+				Metadata: metadata,
+			},
+		})
+	}
+	return accounts, nil
+}
+
+// claudeCodeSource reads the OAuth login of the Claude Code CLI.
+type claudeCodeSource struct{}
+
+func (claudeCodeSource) Name() string { return "claude-code" }
+
+func (claudeCodeSource) Description() string {
+	return "Claude Code CLI login (~/.claude/.credentials.json)"
+}
+
+func (claudeCodeSource) DefaultPath() string {
+	return homePath(filepath.Join(".claude", ".credentials.json"))
+}
+
+func (claudeCodeSource) Read(path string) ([]Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var creds struct {
+		OAuth *struct {
+			AccessToken  string `json:"accessToken"`
+			RefreshToken string `json:"refreshToken"`
+			ExpiresAt    int64  `json:"expiresAt"`
+		} `json:"claudeAiOauth"`
+	}
+	if err = json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	origin := filepath.Base(path)
+	if creds.OAuth == nil || creds.OAuth.RefreshToken == "" {
+		return []Account{{Origin: origin, SkipReason: "no Claude OAuth login found"}}, nil
+	}
+	metadata := map[string]any{
+		"type":          "claude",
+		"access_token":  creds.OAuth.AccessToken,
+		"refresh_token": creds.OAuth.RefreshToken,
+	}
+	if creds.OAuth.ExpiresAt > 0 {
+		metadata["expired"] = time.UnixMilli(creds.OAuth.ExpiresAt).Format(time.RFC3339)
+	}
+	return []Account{{
+		Origin: origin,
+		Auth:   &provider.Auth{Provider: "claude", Metadata: metadata},
+	}}, nil
+}
+
+// codexCLISource reads the ChatGPT login of the OpenAI Codex CLI.
+type codexCLISource struct{}
+
+func (codexCLISource) Name() string { return "codex-cli" }
+
+func (codexCLISource) Description() string {
+	return "OpenAI Codex CLI login ($CODEX_HOME/auth.json)"
+}
+
+func (codexCLISource) DefaultPath() string {
+	if home := strings.TrimSpace(os.Getenv("CODEX_HOME")); home != "" {
+		return filepath.Join(home, "auth.json")
+	}
+	return homePath(filepath.Join(".codex", "auth.json"))
+}
+
+func (codexCLISource) Read(path string) ([]Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var creds struct {
+		APIKey *string `json:"OPENAI_API_KEY"`
+		Tokens *struct {
+			IDToken      string `json:"id_token"`
+			AccessToken  string `json:"access_token"`
+			RefreshToken string `json:"refresh_token"`
+			AccountID    string `json:"account_id"`
+		} `json:"tokens"`
+		LastRefresh string `json:"last_refresh"`
+	}
+	if err = json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	origin := filepath.Base(path)
+	if creds.Tokens == nil || creds.Tokens.RefreshToken == "" {
+		reason := "no ChatGPT login found"
+		if creds.APIKey != nil && *creds.APIKey != "" {
+			reason = "API key login; add the key as an openai provider in config.yaml instead"
+		}
+		return []Account{{Origin: origin, SkipReason: reason}}, nil
+	}
+	metadata := map[string]any{
+		"type":          "codex",
+		"id_token":      creds.Tokens.IDToken,
+		"access_token":  creds.Tokens.AccessToken,
+		"refresh_token": creds.Tokens.RefreshToken,
+	}
+	if creds.Tokens.AccountID != "" {
+		metadata["account_id"] = creds.Tokens.AccountID
+	}
+	if creds.LastRefresh != "" {
+		metadata["last_refresh"] = creds.LastRefresh
+	}
+	auth := &provider.Auth{Provider: "codex", Metadata: metadata}
+	if claims, errParse := codex.ParseJWTToken(creds.Tokens.IDToken); errParse == nil {
+		if email := claims.GetUserEmail(); email != "" {
+			metadata["email"] = email
+			auth.ID = fmt.Sprintf("codex-%s.json", email)
+		}
+		if _, ok := metadata["account_id"]; !ok && claims.GetAccountID() != "" {
+			metadata["account_id"] = claims.GetAccountID()
+		}
+	}
+	return []Account{{Origin: origin, Auth: auth}}, nil
+}
+
+// jsonFiles returns path itself when it is a file, or the *.json files
+// directly inside it when it is a directory.
+func jsonFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".json") {
+			continue
+		}
+		files = append(files, filepath.Join(path, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func readJSONObject(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]any)
+	if err = json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return metadata, nil
+}
+
+func homePath(rel string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, rel)
+}
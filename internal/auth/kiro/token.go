@@ -5,6 +5,8 @@ import (
 	"github.com/nghyane/llm-mux/internal/json"
 	"os"
 	"path/filepath"
+
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 )
 
 // KiroTokenStorage implements the TokenStorage interface for Kiro credentials.
@@ -35,7 +37,7 @@ func (s *KiroTokenStorage) SaveTokenToFile(authFilePath string) error {
 		return fmt.Errorf("failed to marshal token data: %w", err)
 	}
 
-	if err := os.WriteFile(authFilePath, data, 0600); err != nil {
+	if err := atrest.WriteFile(authFilePath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write token file: %w", err)
 	}
 
@@ -66,6 +66,17 @@ func (AntigravityAuthenticator) Login(ctx context.Context, cfg *config.Config, o
 		return nil, fmt.Errorf("antigravity: failed to generate state: %w", err)
 	}
 
+	redirectURI := fmt.Sprintf("http://localhost:%d/oauth-callback", antigravityCallbackPort)
+	authURL := buildAntigravityAuthURL(redirectURI, state)
+
+	if opts.Headless {
+		pasted, errPaste := WaitForPastedCallback(ctx, opts, "antigravity", "antigravity", authURL, state, 5*time.Minute)
+		if errPaste != nil {
+			return nil, fmt.Errorf("antigravity: %w", errPaste)
+		}
+		return finishAntigravityLogin(ctx, httpClient, callbackResult{Code: pasted.Code, State: pasted.State, Error: pasted.Error}, state, redirectURI)
+	}
+
 	srv, port, cbChan, errServer := startAntigravityCallbackServer()
 	if errServer != nil {
 		return nil, fmt.Errorf("antigravity: failed to start callback server: %w", errServer)
@@ -76,9 +87,6 @@ func (AntigravityAuthenticator) Login(ctx context.Context, cfg *config.Config, o
 		_ = srv.Shutdown(shutdownCtx)
 	}()
 
-	redirectURI := fmt.Sprintf("http://localhost:%d/oauth-callback", port)
-	authURL := buildAntigravityAuthURL(redirectURI, state)
-
 	if !opts.NoBrowser {
 		fmt.Println("Opening browser for antigravity authentication")
 		if !browser.IsAvailable() {
@@ -104,6 +112,12 @@ func (AntigravityAuthenticator) Login(ctx context.Context, cfg *config.Config, o
 	case <-time.After(5 * time.Minute):
 		return nil, fmt.Errorf("antigravity: authentication timed out")
 	}
+	return finishAntigravityLogin(ctx, httpClient, cbRes, state, redirectURI)
+}
+
+// finishAntigravityLogin validates the callback result, exchanges its code for
+// tokens and builds the auth record.
+func finishAntigravityLogin(ctx context.Context, httpClient *http.Client, cbRes callbackResult, state, redirectURI string) (*provider.Auth, error) {
 
 	if cbRes.Error != "" {
 		return nil, fmt.Errorf("antigravity: authentication failed: %s", cbRes.Error)
@@ -56,6 +56,22 @@ func (a *ClaudeAuthenticator) Login(ctx context.Context, cfg *config.Config, opt
 		return nil, fmt.Errorf("claude state generation failed: %w", err)
 	}
 
+	authSvc := claude.NewClaudeAuth(cfg)
+
+	authURL, returnedState, err := authSvc.GenerateAuthURL(state, pkceCodes)
+	if err != nil {
+		return nil, fmt.Errorf("claude authorization url generation failed: %w", err)
+	}
+	state = returnedState
+
+	if opts.Headless {
+		pasted, errPaste := WaitForPastedCallback(ctx, opts, "Claude", a.Provider(), authURL, state, 5*time.Minute)
+		if errPaste != nil {
+			return nil, errPaste
+		}
+		return a.finishLogin(ctx, authSvc, &claude.OAuthResult{Code: pasted.Code, State: pasted.State, Error: pasted.Error}, state, pkceCodes)
+	}
+
 	oauthServer := claude.NewOAuthServer(a.CallbackPort)
 	if err = oauthServer.Start(); err != nil {
 		if strings.Contains(err.Error(), "already in use") {
@@ -71,14 +87,6 @@ func (a *ClaudeAuthenticator) Login(ctx context.Context, cfg *config.Config, opt
 		}
 	}()
 
-	authSvc := claude.NewClaudeAuth(cfg)
-
-	authURL, returnedState, err := authSvc.GenerateAuthURL(state, pkceCodes)
-	if err != nil {
-		return nil, fmt.Errorf("claude authorization url generation failed: %w", err)
-	}
-	state = returnedState
-
 	if !opts.NoBrowser {
 		fmt.Println("Opening browser for Claude authentication")
 		if !browser.IsAvailable() {
@@ -104,7 +112,11 @@ func (a *ClaudeAuthenticator) Login(ctx context.Context, cfg *config.Config, opt
 		}
 		return nil, err
 	}
+	return a.finishLogin(ctx, authSvc, result, state, pkceCodes)
+}
 
+// finishLogin validates the callback result and exchanges its code for tokens.
+func (a *ClaudeAuthenticator) finishLogin(ctx context.Context, authSvc *claude.ClaudeAuth, result *claude.OAuthResult, state string, pkceCodes *claude.PKCECodes) (*provider.Auth, error) {
 	if result.Error != "" {
 		return nil, claude.NewOAuthError(result.Error, "", http.StatusBadRequest)
 	}
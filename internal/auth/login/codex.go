@@ -56,6 +56,21 @@ func (a *CodexAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 		return nil, fmt.Errorf("codex state generation failed: %w", err)
 	}
 
+	authSvc := codex.NewCodexAuth(cfg)
+
+	authURL, err := authSvc.GenerateAuthURL(state, pkceCodes)
+	if err != nil {
+		return nil, fmt.Errorf("codex authorization url generation failed: %w", err)
+	}
+
+	if opts.Headless {
+		pasted, errPaste := WaitForPastedCallback(ctx, opts, "Codex", a.Provider(), authURL, state, 5*time.Minute)
+		if errPaste != nil {
+			return nil, errPaste
+		}
+		return a.finishLogin(ctx, authSvc, &codex.OAuthResult{Code: pasted.Code, State: pasted.State, Error: pasted.Error}, state, pkceCodes)
+	}
+
 	oauthServer := codex.NewOAuthServer(a.CallbackPort)
 	if err = oauthServer.Start(); err != nil {
 		if strings.Contains(err.Error(), "already in use") {
@@ -71,13 +86,6 @@ func (a *CodexAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 		}
 	}()
 
-	authSvc := codex.NewCodexAuth(cfg)
-
-	authURL, err := authSvc.GenerateAuthURL(state, pkceCodes)
-	if err != nil {
-		return nil, fmt.Errorf("codex authorization url generation failed: %w", err)
-	}
-
 	if !opts.NoBrowser {
 		fmt.Println("Opening browser for Codex authentication")
 		if !browser.IsAvailable() {
@@ -103,7 +111,11 @@ func (a *CodexAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 		}
 		return nil, err
 	}
+	return a.finishLogin(ctx, authSvc, result, state, pkceCodes)
+}
 
+// finishLogin validates the callback result and exchanges its code for tokens.
+func (a *CodexAuthenticator) finishLogin(ctx context.Context, authSvc *codex.CodexAuth, result *codex.OAuthResult, state string, pkceCodes *codex.PKCECodes) (*provider.Auth, error) {
 	if result.Error != "" {
 		return nil, codex.NewOAuthError(result.Error, "", http.StatusBadRequest)
 	}
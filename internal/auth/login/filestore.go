@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	"github.com/nghyane/llm-mux/internal/json"
 	"github.com/nghyane/llm-mux/internal/provider"
 )
@@ -68,15 +69,19 @@ func (s *FileTokenStore) Save(ctx context.Context, auth *provider.Auth) (string,
 			return "", fmt.Errorf("auth filestore: marshal metadata failed: %w", errMarshal)
 		}
 		if existing, errRead := os.ReadFile(path); errRead == nil {
-			if jsonEqual(existing, raw) {
-				return path, nil
+			// Rewrite unchanged files whose encryption state differs from the
+			// current setting, so turning encryption on converges the store.
+			if atrest.IsEncrypted(existing) == atrest.Enabled() {
+				if plain, errOpen := atrest.Open(existing); errOpen == nil && jsonEqual(plain, raw) {
+					return path, nil
+				}
 			}
 		} else if !os.IsNotExist(errRead) {
 			return "", fmt.Errorf("auth filestore: read existing failed: %w", errRead)
 		}
 		NotifyPendingWrite(path)
 		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
+		if errWrite := atrest.WriteFile(tmp, raw, 0o600); errWrite != nil {
 			return "", fmt.Errorf("auth filestore: write temp failed: %w", errWrite)
 		}
 		if errRename := os.Rename(tmp, path); errRename != nil {
@@ -86,6 +91,10 @@ func (s *FileTokenStore) Save(ctx context.Context, auth *provider.Auth) (string,
 		return "", fmt.Errorf("auth filestore: nothing to persist for %s", auth.ID)
 	}
 
+	if errState := s.saveState(path, auth.SnapshotState(time.Now())); errState != nil {
+		return "", errState
+	}
+
 	if auth.Attributes == nil {
 		auth.Attributes = make(map[string]string)
 	}
@@ -141,9 +150,58 @@ func (s *FileTokenStore) Delete(ctx context.Context, id string) error {
 	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("auth filestore: delete failed: %w", err)
 	}
+	if err = os.Remove(statePath(path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("auth filestore: delete state failed: %w", err)
+	}
 	return nil
 }
 
+// statePath returns the sidecar file holding persisted cooldown state for an auth file.
+// The suffix keeps it out of the *.json scans performed by List and the watcher.
+func statePath(path string) string {
+	return path + ".state"
+}
+
+// saveState writes the auth's cooldown state next to its token file,
+// removing the sidecar once no active cooldown remains.
+func (s *FileTokenStore) saveState(path string, state *provider.PersistedState) error {
+	sidecar := statePath(path)
+	if state == nil {
+		if err := os.Remove(sidecar); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("auth filestore: remove state failed: %w", err)
+		}
+		return nil
+	}
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("auth filestore: marshal state failed: %w", err)
+	}
+	if existing, errRead := os.ReadFile(sidecar); errRead == nil && jsonEqual(existing, raw) {
+		return nil
+	}
+	tmp := sidecar + ".tmp"
+	if err = os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("auth filestore: write state failed: %w", err)
+	}
+	if err = os.Rename(tmp, sidecar); err != nil {
+		return fmt.Errorf("auth filestore: rename state failed: %w", err)
+	}
+	return nil
+}
+
+// loadState reads the persisted cooldown state for an auth file, if any.
+func (s *FileTokenStore) loadState(path string) *provider.PersistedState {
+	data, err := os.ReadFile(statePath(path))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var state provider.PersistedState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	return &state
+}
+
 func (s *FileTokenStore) resolveDeletePath(id string) (string, error) {
 	if strings.ContainsRune(id, os.PathSeparator) || filepath.IsAbs(id) {
 		return id, nil
@@ -156,7 +214,7 @@ func (s *FileTokenStore) resolveDeletePath(id string) (string, error) {
 }
 
 func (s *FileTokenStore) readAuthFile(path, baseDir string) (*provider.Auth, error) {
-	data, err := os.ReadFile(path)
+	data, err := atrest.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
 	}
@@ -192,6 +250,7 @@ func (s *FileTokenStore) readAuthFile(path, baseDir string) (*provider.Auth, err
 	if email, ok := metadata["email"].(string); ok && email != "" {
 		auth.Attributes["email"] = email
 	}
+	auth.RestoreState(s.loadState(path), time.Now())
 	return auth, nil
 }
 
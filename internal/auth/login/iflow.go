@@ -39,8 +39,23 @@ func (a *IFlowAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 
 	authSvc := iflow.NewIFlowAuth(cfg)
 
+	state, err := misc.GenerateRandomState()
+	if err != nil {
+		return nil, fmt.Errorf("iflow auth: failed to generate state: %w", err)
+	}
+
+	authURL, redirectURI := authSvc.AuthorizationURL(state, iflow.CallbackPort)
+
+	if opts.Headless {
+		pasted, errPaste := WaitForPastedCallback(ctx, opts, "iFlow", a.Provider(), authURL, state, 5*time.Minute)
+		if errPaste != nil {
+			return nil, errPaste
+		}
+		return a.finishLogin(ctx, authSvc, &iflow.OAuthResult{Code: pasted.Code, State: pasted.State, Error: pasted.Error}, state, redirectURI)
+	}
+
 	oauthServer := iflow.NewOAuthServer(iflow.CallbackPort)
-	if err := oauthServer.Start(); err != nil {
+	if err = oauthServer.Start(); err != nil {
 		if strings.Contains(err.Error(), "already in use") {
 			return nil, fmt.Errorf("iflow authentication server port in use: %w", err)
 		}
@@ -54,13 +69,6 @@ func (a *IFlowAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 		}
 	}()
 
-	state, err := misc.GenerateRandomState()
-	if err != nil {
-		return nil, fmt.Errorf("iflow auth: failed to generate state: %w", err)
-	}
-
-	authURL, redirectURI := authSvc.AuthorizationURL(state, iflow.CallbackPort)
-
 	if !opts.NoBrowser {
 		fmt.Println("Opening browser for iFlow authentication")
 		if !browser.IsAvailable() {
@@ -83,6 +91,11 @@ func (a *IFlowAuthenticator) Login(ctx context.Context, cfg *config.Config, opts
 	if err != nil {
 		return nil, fmt.Errorf("iflow auth: callback wait failed: %w", err)
 	}
+	return a.finishLogin(ctx, authSvc, result, state, redirectURI)
+}
+
+// finishLogin validates the callback result and exchanges its code for tokens.
+func (a *IFlowAuthenticator) finishLogin(ctx context.Context, authSvc *iflow.IFlowAuth, result *iflow.OAuthResult, state, redirectURI string) (*provider.Auth, error) {
 	if result.Error != "" {
 		return nil, fmt.Errorf("iflow auth: provider returned error %s", result.Error)
 	}
@@ -13,6 +13,7 @@ var ErrRefreshNotSupported = errors.New("cliproxy auth: refresh not supported")
 
 type LoginOptions struct {
 	NoBrowser bool
+	Headless  bool
 	ProjectID string
 	Metadata  map[string]string
 	Prompt    func(prompt string) (string, error)
@@ -1,13 +1,19 @@
 package login
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/nghyane/llm-mux/internal/browser"
 	"github.com/nghyane/llm-mux/internal/config"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/misc"
+	"github.com/nghyane/llm-mux/internal/oauth"
 	"github.com/nghyane/llm-mux/internal/util"
 )
 
@@ -95,3 +101,83 @@ func PrintWaitingMessage(providerName string) {
 func PrintAuthSuccess(providerName string) {
 	fmt.Printf("%s authentication successful\n", providerName)
 }
+
+var (
+	headlessRegistryOnce sync.Once
+	headlessRegistry     *oauth.Registry
+)
+
+// WaitForPastedCallback runs the consent step of a headless login. It prints
+// authURL for the user to open on any machine, then reads the redirect URL or
+// code they paste back and completes the pending request through the OAuth
+// registry, as the callback server would. The returned result carries the
+// pasted state, or the expected state when only a bare code was entered, so
+// callers keep their usual state and error checks.
+func WaitForPastedCallback(ctx context.Context, opts *LoginOptions, providerName, providerKey, authURL, state string, timeout time.Duration) (*oauth.OAuthResult, error) {
+	headlessRegistryOnce.Do(func() { headlessRegistry = oauth.NewRegistry() })
+	registry := headlessRegistry
+
+	req, err := registry.Create(state, providerKey, oauth.ModeCLI)
+	if err != nil {
+		return nil, err
+	}
+	defer registry.Remove(state)
+
+	fmt.Printf("Open the following URL in a browser on any machine to continue %s authentication:\n%s\n", providerName, authURL)
+	fmt.Println("After you approve access the browser is sent to a localhost page that will not load.")
+	fmt.Println("Copy the full URL from its address bar (or the code shown) and paste it below.")
+
+	prompt := pastePrompt(opts)
+	go func() {
+		for {
+			input, err := prompt("Redirect URL or code: ")
+			if err != nil {
+				registry.Fail(state, fmt.Sprintf("reading input: %v", err))
+				return
+			}
+			result, err := oauth.ParseCallbackInput(input)
+			if err != nil {
+				fmt.Printf("%v, try again\n", err)
+				continue
+			}
+			if result.State == "" {
+				result.State = state
+			}
+			registry.Complete(state, result)
+			return
+		}
+	}()
+
+	select {
+	case result, ok := <-req.ResultChan:
+		if !ok || result == nil {
+			return nil, fmt.Errorf("%s authentication cancelled", providerName)
+		}
+		if status, _ := registry.GetStatus(state); status == oauth.StatusFailed {
+			return nil, fmt.Errorf("%s authentication failed: %s", providerName, result.Error)
+		}
+		return result, nil
+	case <-time.After(timeout):
+		registry.Fail(state, "timeout")
+		return nil, fmt.Errorf("%s authentication timed out after %v", providerName, timeout)
+	case <-ctx.Done():
+		registry.Cancel(state)
+		return nil, ctx.Err()
+	}
+}
+
+// pastePrompt returns the caller's prompt, or one reading a line from stdin.
+func pastePrompt(opts *LoginOptions) func(string) (string, error) {
+	if opts != nil && opts.Prompt != nil {
+		return opts.Prompt
+	}
+	reader := bufio.NewReader(os.Stdin)
+	return func(prompt string) (string, error) {
+		fmt.Print(prompt)
+		line, err := reader.ReadString('\n')
+		if err != nil && strings.TrimSpace(line) == "" {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+}
@@ -0,0 +1,228 @@
+// Package batch runs OpenAI /v1/batches style jobs: a JSONL list of requests
+// executed in the background through the provider manager, with state kept on
+// disk so unfinished jobs resume after a restart.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// EndpointChatCompletions is the only endpoint batch lines may target.
+const EndpointChatCompletions = "/v1/chat/completions"
+
+// MaxItems caps the number of requests accepted in a single batch.
+const MaxItems = 10000
+
+// Status values follow the OpenAI batch object.
+type Status string
+
+const (
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+	StatusCancelling Status = "cancelling"
+	StatusCancelled  Status = "cancelled"
+)
+
+// Job is the persisted state of a batch, including every request and result.
+type Job struct {
+	ID          string    `json:"id"`
+	Endpoint    string    `json:"endpoint"`
+	Status      Status    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	CancelledAt time.Time `json:"cancelled_at,omitempty"`
+	Items       []*Item   `json:"items"`
+	// APIKeyID attributes the job's usage to the submitting client key.
+	APIKeyID string `json:"api_key_id,omitempty"`
+}
+
+// Item is a single request line and, once finished, its outcome.
+type Item struct {
+	CustomID string          `json:"custom_id"`
+	Body     json.RawMessage `json:"body"`
+	Attempts int             `json:"attempts,omitempty"`
+	Result   *Result         `json:"result,omitempty"`
+}
+
+// Result is the outcome of an item. Body holds the upstream response on
+// success; Error is set when the request failed permanently.
+type Result struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// RequestCounts mirrors the OpenAI batch request_counts object.
+type RequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// View is the client-facing batch object.
+type View struct {
+	ID            string        `json:"id"`
+	Object        string        `json:"object"`
+	Endpoint      string        `json:"endpoint"`
+	Status        Status        `json:"status"`
+	CreatedAt     int64         `json:"created_at"`
+	CompletedAt   *int64        `json:"completed_at"`
+	CancelledAt   *int64        `json:"cancelled_at"`
+	RequestCounts RequestCounts `json:"request_counts"`
+}
+
+// View summarizes the job for API responses.
+func (j *Job) View() View {
+	v := View{
+		ID:        j.ID,
+		Object:    "batch",
+		Endpoint:  j.Endpoint,
+		Status:    j.Status,
+		CreatedAt: j.CreatedAt.Unix(),
+	}
+	if !j.CompletedAt.IsZero() {
+		ts := j.CompletedAt.Unix()
+		v.CompletedAt = &ts
+	}
+	if !j.CancelledAt.IsZero() {
+		ts := j.CancelledAt.Unix()
+		v.CancelledAt = &ts
+	}
+	v.RequestCounts.Total = len(j.Items)
+	for _, item := range j.Items {
+		if item.Result == nil {
+			continue
+		}
+		if item.Result.Error != "" {
+			v.RequestCounts.Failed++
+		} else {
+			v.RequestCounts.Completed++
+		}
+	}
+	return v
+}
+
+// clone copies the job deeply enough that callers can read it while the
+// runner keeps updating the original.
+func (j *Job) clone() *Job {
+	cp := *j
+	cp.Items = make([]*Item, len(j.Items))
+	for i, item := range j.Items {
+		itemCopy := *item
+		if item.Result != nil {
+			result := *item.Result
+			itemCopy.Result = &result
+		}
+		cp.Items[i] = &itemCopy
+	}
+	return &cp
+}
+
+// ResultLine is one line of the JSONL results download, in the OpenAI batch
+// output format.
+type ResultLine struct {
+	ID       string          `json:"id"`
+	CustomID string          `json:"custom_id"`
+	Response *ResultResponse `json:"response"`
+	Error    *ResultError    `json:"error"`
+}
+
+// ResultResponse wraps a successful upstream response.
+type ResultResponse struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// ResultError describes a failed request.
+type ResultError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ResultLines renders the finished items of the job.
+func (j *Job) ResultLines() []ResultLine {
+	lines := make([]ResultLine, 0, len(j.Items))
+	for i, item := range j.Items {
+		if item.Result == nil {
+			continue
+		}
+		line := ResultLine{ID: fmt.Sprintf("%s_req_%d", j.ID, i), CustomID: item.CustomID}
+		if item.Result.Error != "" {
+			line.Error = &ResultError{Code: item.Result.StatusCode, Message: item.Result.Error}
+		} else {
+			line.Response = &ResultResponse{StatusCode: item.Result.StatusCode, Body: item.Result.Body}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// NewJob creates a job from a JSONL body where each line is
+// {"custom_id": "...", "method": "POST", "url": "/v1/chat/completions", "body": {...}}.
+// Streaming is forced off for every request.
+func NewJob(jsonl []byte) (*Job, error) {
+	var items []*Item
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(jsonl))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !gjson.ValidBytes(line) {
+			return nil, fmt.Errorf("line %d: invalid JSON", lineNo)
+		}
+		parsed := gjson.ParseBytes(line)
+		customID := strings.TrimSpace(parsed.Get("custom_id").String())
+		if customID == "" {
+			return nil, fmt.Errorf("line %d: custom_id is required", lineNo)
+		}
+		if _, dup := seen[customID]; dup {
+			return nil, fmt.Errorf("line %d: duplicate custom_id %q", lineNo, customID)
+		}
+		seen[customID] = struct{}{}
+		if method := parsed.Get("method").String(); method != "" && !strings.EqualFold(method, "POST") {
+			return nil, fmt.Errorf("line %d: method must be POST", lineNo)
+		}
+		if url := parsed.Get("url").String(); url != "" && url != EndpointChatCompletions {
+			return nil, fmt.Errorf("line %d: unsupported url %q, only %s is supported", lineNo, url, EndpointChatCompletions)
+		}
+		body := parsed.Get("body")
+		if !body.IsObject() {
+			return nil, fmt.Errorf("line %d: body must be a JSON object", lineNo)
+		}
+		if strings.TrimSpace(body.Get("model").String()) == "" {
+			return nil, fmt.Errorf("line %d: body.model is required", lineNo)
+		}
+		raw, _ := sjson.SetBytes([]byte(body.Raw), "stream", false)
+		items = append(items, &Item{CustomID: customID, Body: raw})
+		if len(items) > MaxItems {
+			return nil, fmt.Errorf("batch exceeds %d requests", MaxItems)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read batch input: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("batch input contains no requests")
+	}
+	return &Job{
+		ID:        "batch_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Endpoint:  EndpointChatCompletions,
+		Status:    StatusInProgress,
+		CreatedAt: time.Now(),
+		Items:     items,
+	}, nil
+}
@@ -0,0 +1,325 @@
+package batch
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/usage"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// DefaultConcurrency bounds in-flight batch requests across all jobs.
+	DefaultConcurrency = 4
+
+	// maxItemAttempts bounds retries of a request failing with a transient status.
+	maxItemAttempts = 5
+	// flushInterval is how often progress of running jobs is written to disk.
+	flushInterval = 2 * time.Second
+)
+
+// ExecuteFunc runs one chat completion request body for model. On failure it
+// returns the HTTP status the error maps to.
+type ExecuteFunc func(ctx context.Context, model string, body []byte) (resp []byte, status int, err error)
+
+// Runner executes batch jobs in the background. Requests go through exec, so
+// account selection, quota cooldowns and backoff are handled by the provider
+// manager; the runner only bounds concurrency and retries transient failures.
+type Runner struct {
+	store *Store
+	exec  ExecuteFunc
+	sem   chan struct{}
+
+	// retryDelay returns the wait before the given retry attempt.
+	retryDelay func(attempt int) time.Duration
+
+	mu    sync.Mutex
+	jobs  map[string]*jobState
+	dirty map[string]struct{}
+
+	// saveMu orders disk writes so an older snapshot never overwrites a newer one.
+	saveMu sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type jobState struct {
+	job    *Job
+	cancel context.CancelFunc
+}
+
+// NewRunner creates a runner persisting to store. A non-positive concurrency
+// uses DefaultConcurrency.
+func NewRunner(store *Store, exec ExecuteFunc, concurrency int) *Runner {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		store:      store,
+		exec:       exec,
+		sem:        make(chan struct{}, concurrency),
+		retryDelay: defaultRetryDelay,
+		jobs:       make(map[string]*jobState),
+		dirty:      make(map[string]struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// defaultRetryDelay backs off exponentially from 5s, capped at 2 minutes.
+func defaultRetryDelay(attempt int) time.Duration {
+	delay := 5 * time.Second << (attempt - 1)
+	if delay > 2*time.Minute || delay <= 0 {
+		delay = 2 * time.Minute
+	}
+	return delay
+}
+
+// Start loads persisted jobs and resumes the unfinished ones.
+func (r *Runner) Start() error {
+	jobs, err := r.store.LoadAll()
+	if err != nil {
+		return err
+	}
+	resumed := 0
+	r.mu.Lock()
+	for _, job := range jobs {
+		if job.Status == StatusCancelling {
+			job.Status = StatusCancelled
+			job.CancelledAt = time.Now()
+			r.dirty[job.ID] = struct{}{}
+		}
+		r.jobs[job.ID] = &jobState{job: job}
+		if job.Status == StatusInProgress {
+			r.launchLocked(job.ID)
+			resumed++
+		}
+	}
+	r.mu.Unlock()
+	if resumed > 0 {
+		log.Infof("batch: resumed %d unfinished batch(es)", resumed)
+	}
+
+	r.wg.Add(1)
+	go r.flushLoop()
+	return nil
+}
+
+// Stop halts processing and writes the latest progress. Unfinished jobs stay
+// in progress and resume on the next Start.
+func (r *Runner) Stop() {
+	r.cancel()
+	r.wg.Wait()
+	r.flush()
+}
+
+// Submit persists a new job and starts executing it.
+func (r *Runner) Submit(job *Job) (View, error) {
+	if err := r.store.Save(job); err != nil {
+		return View{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = &jobState{job: job}
+	r.launchLocked(job.ID)
+	return job.View(), nil
+}
+
+// Get returns a snapshot of the job. Jobs submitted under another API key
+// are reported as missing.
+func (r *Runner) Get(id, apiKeyID string) (*Job, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	js, ok := r.jobs[id]
+	if !ok || js.job.APIKeyID != apiKeyID {
+		return nil, false
+	}
+	return js.job.clone(), true
+}
+
+// Cancel stops a running job. Requests already in flight finish, but their
+// results are kept; pending requests are not sent. Like Get, it only sees
+// jobs submitted under apiKeyID.
+func (r *Runner) Cancel(id, apiKeyID string) (View, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	js, ok := r.jobs[id]
+	if !ok || js.job.APIKeyID != apiKeyID {
+		return View{}, false
+	}
+	if js.job.Status == StatusInProgress {
+		js.job.Status = StatusCancelling
+		r.dirty[id] = struct{}{}
+		if js.cancel != nil {
+			js.cancel()
+		}
+	}
+	return js.job.View(), true
+}
+
+func (r *Runner) launchLocked(id string) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.jobs[id].cancel = cancel
+	r.wg.Add(1)
+	go r.run(ctx, id)
+}
+
+func (r *Runner) run(ctx context.Context, id string) {
+	defer r.wg.Done()
+
+	r.mu.Lock()
+	job := r.jobs[id].job
+	total := len(job.Items)
+	ctx = usage.WithAPIKeyID(ctx, job.APIKeyID)
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+dispatch:
+	for i := 0; i < total; i++ {
+		r.mu.Lock()
+		item := job.Items[i]
+		done, body := item.Result != nil, item.Body
+		r.mu.Unlock()
+		if done {
+			continue
+		}
+		select {
+		case r.sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		wg.Add(1)
+		go func(i int, body []byte) {
+			defer wg.Done()
+			defer func() { <-r.sem }()
+			r.runItem(ctx, id, i, body)
+		}(i, body)
+	}
+	wg.Wait()
+	r.finish(id)
+}
+
+func (r *Runner) runItem(ctx context.Context, id string, index int, body []byte) {
+	model := gjson.GetBytes(body, "model").String()
+	for {
+		resp, status, err := r.exec(ctx, model, body)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			if !json.Valid(resp) {
+				resp, _ = json.Marshal(string(resp))
+			}
+			r.complete(id, index, &Result{StatusCode: http.StatusOK, Body: resp})
+			return
+		}
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		attempts := r.recordAttempt(id, index)
+		if retryableStatus(status) && attempts < maxItemAttempts {
+			select {
+			case <-time.After(r.retryDelay(attempts)):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		r.complete(id, index, &Result{StatusCode: status, Error: err.Error()})
+		return
+	}
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusRequestTimeout || status >= http.StatusInternalServerError
+}
+
+func (r *Runner) recordAttempt(id string, index int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item := r.jobs[id].job.Items[index]
+	item.Attempts++
+	r.dirty[id] = struct{}{}
+	return item.Attempts
+}
+
+func (r *Runner) complete(id string, index int, result *Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[id].job.Items[index].Result = result
+	r.dirty[id] = struct{}{}
+}
+
+// finish settles the job status once its workers have returned and saves it.
+func (r *Runner) finish(id string) {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	r.mu.Lock()
+	job := r.jobs[id].job
+	switch job.Status {
+	case StatusCancelling:
+		job.Status = StatusCancelled
+		job.CancelledAt = time.Now()
+	case StatusInProgress:
+		pending := false
+		for _, item := range job.Items {
+			if item.Result == nil {
+				pending = true
+				break
+			}
+		}
+		if !pending {
+			job.Status = StatusCompleted
+			job.CompletedAt = time.Now()
+		}
+	}
+	snapshot := job.clone()
+	delete(r.dirty, id)
+	r.mu.Unlock()
+
+	if err := r.store.Save(snapshot); err != nil {
+		log.Errorf("batch: %v", err)
+	}
+}
+
+func (r *Runner) flushLoop() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.flush()
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// flush saves every job changed since the last flush.
+func (r *Runner) flush() {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	r.mu.Lock()
+	snapshots := make([]*Job, 0, len(r.dirty))
+	for id := range r.dirty {
+		snapshots = append(snapshots, r.jobs[id].job.clone())
+	}
+	r.dirty = make(map[string]struct{})
+	r.mu.Unlock()
+
+	for _, job := range snapshots {
+		if err := r.store.Save(job); err != nil {
+			log.Errorf("batch: flush: %v", err)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func waitForStatus(t *testing.T, r *Runner, id string, want Status) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := r.Get(id, ""); ok && job.Status == want {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := r.Get(id, "")
+	t.Fatalf("Expected batch %s to reach %s, got %+v", id, want, job)
+	return nil
+}
+
+func newTestRunner(t *testing.T, dir string, exec ExecuteFunc) *Runner {
+	t.Helper()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	r := NewRunner(store, exec, 2)
+	r.retryDelay = func(int) time.Duration { return time.Millisecond }
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	return r
+}
+
+func TestNewJob_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty", input: "\n\n", wantErr: "no requests"},
+		{name: "invalid json", input: `{"custom_id":`, wantErr: "invalid JSON"},
+		{name: "missing custom_id", input: `{"body":{"model":"m"}}`, wantErr: "custom_id is required"},
+		{name: "duplicate custom_id", input: `{"custom_id":"a","body":{"model":"m"}}` + "\n" + `{"custom_id":"a","body":{"model":"m"}}`, wantErr: "duplicate"},
+		{name: "wrong url", input: `{"custom_id":"a","url":"/v1/embeddings","body":{"model":"m"}}`, wantErr: "unsupported url"},
+		{name: "wrong method", input: `{"custom_id":"a","method":"GET","body":{"model":"m"}}`, wantErr: "method must be POST"},
+		{name: "missing model", input: `{"custom_id":"a","body":{"messages":[]}}`, wantErr: "body.model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJob([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	job, err := NewJob([]byte(`{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"m","stream":true}}`))
+	if err != nil {
+		t.Fatalf("NewJob failed: %v", err)
+	}
+	if !strings.HasPrefix(job.ID, "batch_") || job.Status != StatusInProgress {
+		t.Errorf("Unexpected job header: %s %s", job.ID, job.Status)
+	}
+	if gjson.GetBytes(job.Items[0].Body, "stream").Bool() {
+		t.Error("Expected stream to be forced off")
+	}
+}
+
+func TestRunner_CompletesWithRetriesAndFailures(t *testing.T) {
+	var calls sync.Map
+	exec := func(_ context.Context, model string, body []byte) ([]byte, int, error) {
+		id := gjson.GetBytes(body, "messages.0.content").String()
+		n, _ := calls.LoadOrStore(id, new(atomic.Int32))
+		attempt := n.(*atomic.Int32).Add(1)
+		switch id {
+		case "bad":
+			return nil, http.StatusBadRequest, errors.New("invalid request")
+		case "flaky":
+			if attempt < 3 {
+				return nil, http.StatusTooManyRequests, errors.New("quota exhausted")
+			}
+		}
+		return []byte(`{"model":"` + model + `","choices":[]}`), http.StatusOK, nil
+	}
+
+	r := newTestRunner(t, t.TempDir(), exec)
+	defer r.Stop()
+
+	job, err := NewJob([]byte(strings.Join([]string{
+		`{"custom_id":"r1","body":{"model":"m","messages":[{"role":"user","content":"ok"}]}}`,
+		`{"custom_id":"r2","body":{"model":"m","messages":[{"role":"user","content":"bad"}]}}`,
+		`{"custom_id":"r3","body":{"model":"m","messages":[{"role":"user","content":"flaky"}]}}`,
+	}, "\n")))
+	if err != nil {
+		t.Fatalf("NewJob failed: %v", err)
+	}
+	if _, err := r.Submit(job); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	done := waitForStatus(t, r, job.ID, StatusCompleted)
+	view := done.View()
+	if view.RequestCounts != (RequestCounts{Total: 3, Completed: 2, Failed: 1}) {
+		t.Errorf("Unexpected request counts: %+v", view.RequestCounts)
+	}
+	if view.CompletedAt == nil {
+		t.Error("Expected completed_at to be set")
+	}
+	if got := done.Items[2].Attempts; got != 2 {
+		t.Errorf("Expected 2 failed attempts before success, got %d", got)
+	}
+
+	lines := done.ResultLines()
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 result lines, got %d", len(lines))
+	}
+	if lines[0].Response == nil || lines[0].Response.StatusCode != http.StatusOK {
+		t.Errorf("Expected r1 to succeed, got %+v", lines[0])
+	}
+	if lines[1].Error == nil || lines[1].Error.Code != http.StatusBadRequest {
+		t.Errorf("Expected r2 to fail with 400, got %+v", lines[1])
+	}
+}
+
+func TestRunner_ResumesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	release := make(chan struct{})
+	var blocked atomic.Int32
+	blocking := func(ctx context.Context, _ string, body []byte) ([]byte, int, error) {
+		if gjson.GetBytes(body, "messages.0.content").String() == "slow" {
+			blocked.Add(1)
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, 0, ctx.Err()
+			}
+		}
+		return []byte(`{"ok":true}`), http.StatusOK, nil
+	}
+
+	first := newTestRunner(t, dir, blocking)
+	job, err := NewJob([]byte(`{"custom_id":"fast","body":{"model":"m","messages":[{"role":"user","content":"fast"}]}}` + "\n" +
+		`{"custom_id":"slow","body":{"model":"m","messages":[{"role":"user","content":"slow"}]}}`))
+	if err != nil {
+		t.Fatalf("NewJob failed: %v", err)
+	}
+	if _, err := first.Submit(job); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for blocked.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	first.Stop()
+
+	var resumed atomic.Int32
+	second := newTestRunner(t, dir, func(_ context.Context, _ string, body []byte) ([]byte, int, error) {
+		resumed.Add(1)
+		return []byte(`{"ok":true}`), http.StatusOK, nil
+	})
+	defer second.Stop()
+
+	done := waitForStatus(t, second, job.ID, StatusCompleted)
+	if done.View().RequestCounts.Completed != 2 {
+		t.Errorf("Expected both requests completed, got %+v", done.View().RequestCounts)
+	}
+	if got := resumed.Load(); got != 1 {
+		t.Errorf("Expected only the unfinished request to run after restart, got %d calls", got)
+	}
+	close(release)
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	exec := func(ctx context.Context, _ string, _ []byte) ([]byte, int, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return nil, 0, ctx.Err()
+	}
+	r := newTestRunner(t, t.TempDir(), exec)
+	defer r.Stop()
+
+	job, err := NewJob([]byte(`{"custom_id":"a","body":{"model":"m"}}`))
+	if err != nil {
+		t.Fatalf("NewJob failed: %v", err)
+	}
+	if _, err := r.Submit(job); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	<-started
+	if view, ok := r.Cancel(job.ID, ""); !ok || view.Status != StatusCancelling {
+		t.Fatalf("Expected cancelling, got %+v ok=%v", view, ok)
+	}
+	cancelled := waitForStatus(t, r, job.ID, StatusCancelled)
+	if cancelled.View().CancelledAt == nil {
+		t.Error("Expected cancelled_at to be set")
+	}
+	if _, ok := r.Cancel("batch_missing", ""); ok {
+		t.Error("Expected unknown batch to be reported missing")
+	}
+}
+
+func TestRunner_JobsScopedToAPIKey(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "batches")
+	release := make(chan struct{})
+	r := newTestRunner(t, dir, func(ctx context.Context, model string, body []byte) ([]byte, int, error) {
+		<-release
+		return []byte(`{}`), http.StatusOK, nil
+	})
+	t.Cleanup(r.Stop)
+	defer close(release)
+
+	job, err := NewJob([]byte(`{"custom_id":"a","body":{"model":"m"}}`))
+	if err != nil {
+		t.Fatalf("NewJob failed: %v", err)
+	}
+	job.APIKeyID = "key-a"
+	if _, err := r.Submit(job); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected the batch directory to be created on submit: %v", err)
+	}
+
+	if _, ok := r.Get(job.ID, "key-b"); ok {
+		t.Error("Expected another key's batch to be reported missing")
+	}
+	if _, ok := r.Cancel(job.ID, "key-b"); ok {
+		t.Error("Expected another key to be unable to cancel the batch")
+	}
+	if got, ok := r.Get(job.ID, "key-a"); !ok || got.Status != StatusInProgress {
+		t.Errorf("Expected the owner to see the running batch, got %+v ok=%v", got, ok)
+	}
+}
+
+func TestNewStore_CreatesDirectoryLazily(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "batches")
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if jobs, err := store.LoadAll(); err != nil || len(jobs) != 0 {
+		t.Fatalf("Expected no jobs from a missing directory, got %v, %v", jobs, err)
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected no directory before the first save, got %v", err)
+	}
+}
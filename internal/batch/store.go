@@ -0,0 +1,81 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// Store persists jobs as one JSON file per batch under a directory.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store rooted at dir. The directory is created when the
+// first job is saved.
+func NewStore(dir string) (*Store, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("batch store directory is empty")
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save writes the job atomically so a crash never leaves a truncated file.
+func (s *Store) Save(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode batch %s: %w", job.ID, err)
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("create batch directory: %w", err)
+	}
+	tmp := s.path(job.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write batch %s: %w", job.ID, err)
+	}
+	if err := os.Rename(tmp, s.path(job.ID)); err != nil {
+		return fmt.Errorf("commit batch %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// LoadAll reads every stored job. Unreadable files are skipped and logged. A
+// missing directory holds no jobs.
+func (s *Store) LoadAll() ([]*Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read batch directory: %w", err)
+	}
+	var jobs []*Job
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, errRead := os.ReadFile(filepath.Join(s.dir, name))
+		if errRead != nil {
+			log.Warnf("batch: skip %s: %v", name, errRead)
+			continue
+		}
+		var job Job
+		if errDecode := json.Unmarshal(data, &job); errDecode != nil || job.ID == "" {
+			log.Warnf("batch: skip %s: invalid batch file", name)
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
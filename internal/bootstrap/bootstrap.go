@@ -11,10 +11,12 @@ import (
 
 	"github.com/joho/godotenv"
 	configaccess "github.com/nghyane/llm-mux/internal/access/config_access"
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	authlogin "github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/cli/env"
 	"github.com/nghyane/llm-mux/internal/config"
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/oauth"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/store"
 	"github.com/nghyane/llm-mux/internal/util"
@@ -110,6 +112,14 @@ func Bootstrap(configPath string) (*Result, error) {
 		cfg.AuthDir = resolvedAuthDir
 	}
 
+	if err = configureAuthEncryption(cfg); err != nil {
+		return nil, err
+	}
+
+	if err = configureOAuthCallbacks(cfg); err != nil {
+		return nil, err
+	}
+
 	// Register the shared token store
 	if storeResult != nil && storeResult.Store != nil {
 		authlogin.RegisterTokenStore(storeResult.Store)
@@ -202,6 +212,40 @@ func ApplyEnvOverrides(cfg *config.Config) {
 		cfg.MaxResponseSize = maxRespSize
 		log.Infof("Max response size overridden by env: %d bytes", maxRespSize)
 	}
+
+	if passphrase, ok := env.LookupEnv(atrest.PassphraseEnv); ok && passphrase != "" {
+		cfg.AuthEncryption.Passphrase = passphrase
+		log.Infof("Auth encryption passphrase set by env")
+	}
+}
+
+// configureAuthEncryption applies the auth file passphrase and checks it
+// against the existing files, so a wrong passphrase stops startup instead of
+// leaving accounts unloaded.
+func configureAuthEncryption(cfg *config.Config) error {
+	if err := atrest.Configure(cfg.AuthEncryption.Passphrase); err != nil {
+		return err
+	}
+	plaintext, err := atrest.CheckDir(cfg.AuthDir)
+	if err != nil {
+		return fmt.Errorf("auth encryption: %w", err)
+	}
+	if atrest.Enabled() && plaintext > 0 {
+		log.Warnf("%d auth file(s) in %s are not encrypted; run `llm-mux encrypt-auth` to encrypt them", plaintext, cfg.AuthDir)
+	}
+	return nil
+}
+
+// configureOAuthCallbacks applies the callback bind address, redirect URI
+// overrides and pending request limits used by logins started through the
+// management API.
+func configureOAuthCallbacks(cfg *config.Config) error {
+	oauth.ConfigureRequestLimits(cfg.OAuthCallback.MaxPending, cfg.OAuthCallback.MaxPendingPerProvider)
+	overrides := make(map[string]oauth.CallbackOverride, len(cfg.OAuthCallback.Providers))
+	for name, p := range cfg.OAuthCallback.Providers {
+		overrides[name] = oauth.CallbackOverride{Port: p.Port, RedirectURI: p.RedirectURI}
+	}
+	return oauth.ConfigureCallbacks(cfg.OAuthCallback.BindHost, overrides)
 }
 
 // autoInitConfig silently creates config on first run
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"github.com/nghyane/llm-mux/internal/bootstrap"
+	"github.com/nghyane/llm-mux/internal/cmd"
+	"github.com/spf13/cobra"
+)
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Inspect logged-in accounts",
+}
+
+var accountShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an account's status, token expiry, quota and model states",
+	Long: `Show the live state of one account as seen by the running server: its
+status, token expiry and scopes, quota and backoff, the last error and the
+state of each model. The account is named by its ID, file name, label or
+email. Tokens and keys are never printed.
+
+The server must be running; the command queries its management API on
+localhost with the management key.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		cfgPath, _ := c.Flags().GetString("config")
+		asJSON, _ := c.Flags().GetBool("json")
+
+		result, err := bootstrap.Bootstrap(cfgPath)
+		if err != nil {
+			return err
+		}
+		return cmd.DoAccountShow(result.Config, args[0], asJSON)
+	},
+}
+
+func init() {
+	accountShowCmd.Flags().Bool("json", false, "print the raw JSON response")
+	accountCmd.AddCommand(accountShowCmd)
+	rootCmd.AddCommand(accountCmd)
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"github.com/nghyane/llm-mux/internal/bootstrap"
+	"github.com/nghyane/llm-mux/internal/cmd"
+	"github.com/spf13/cobra"
+)
+
+var encryptAuthCmd = &cobra.Command{
+	Use:   "encrypt-auth",
+	Short: "Encrypt existing auth files with the configured passphrase",
+	Long: `Encrypt every auth file in the auth directory with the passphrase from
+LLM_MUX_AUTH_PASSPHRASE or auth-encryption.passphrase in config.yaml.
+
+Once a passphrase is set, new and refreshed tokens are written encrypted;
+this command converts the files written before. Use --decrypt to turn
+encryption off again. Stop the server first.`,
+	RunE: func(c *cobra.Command, args []string) error {
+		cfgPath, _ := c.Flags().GetString("config")
+		decrypt, _ := c.Flags().GetBool("decrypt")
+
+		result, err := bootstrap.Bootstrap(cfgPath)
+		if err != nil {
+			return err
+		}
+		return cmd.DoEncryptAuth(result.Config, decrypt)
+	},
+}
+
+func init() {
+	encryptAuthCmd.Flags().Bool("decrypt", false, "write all auth files back in plaintext")
+	rootCmd.AddCommand(encryptAuthCmd)
+}
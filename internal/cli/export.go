@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/nghyane/llm-mux/internal/bootstrap"
+	"github.com/nghyane/llm-mux/internal/cmd"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export accounts and config to a backup file",
+	Long: `Export all accounts and the config file into a single JSON bundle for
+backups and moving between hosts. Restore it with:
+
+  llm-mux import --from llm-mux backup.json --restore-config
+
+The bundle holds tokens and API keys in plain text and is written with
+owner-only permissions. Use --no-secrets to export only the structure.`,
+	RunE: func(c *cobra.Command, args []string) error {
+		cfgPath, _ := c.Flags().GetString("config")
+		out, _ := c.Flags().GetString("out")
+		noSecrets, _ := c.Flags().GetBool("no-secrets")
+
+		result, err := bootstrap.Bootstrap(cfgPath)
+		if err != nil {
+			return err
+		}
+		return cmd.DoExport(result.Config, result.ConfigFilePath, cmd.ExportOptions{
+			Out:       out,
+			NoSecrets: noSecrets,
+		})
+	},
+}
+
+func init() {
+	exportCmd.Flags().String("out", "llm-mux-backup.json", "bundle file to write")
+	exportCmd.Flags().Bool("no-secrets", false, "redact tokens, keys and passwords")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -1,10 +1,70 @@
 package importcmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/auth/importer"
+	"github.com/nghyane/llm-mux/internal/bootstrap"
+	"github.com/nghyane/llm-mux/internal/cmd"
+	"github.com/spf13/cobra"
+)
 
 // ImportCmd is the parent command for import operations
 var ImportCmd = &cobra.Command{
-	Use:   "import",
+	Use:   "import --from <source> [path]",
 	Short: "Import credentials from external files",
-	Long:  `Import credentials from external files (e.g. Vertex AI service accounts).`,
+	Long: `Import credentials from external files (e.g. Vertex AI service accounts)
+or from another proxy's credential store.
+
+With --from, accounts are read from the named source (at its default location
+unless a path is given), validated by refreshing their tokens with the provider,
+and saved to the auth store. Accounts that already exist are skipped.
+Bundles written by "llm-mux export" are read with --from llm-mux.
+
+Sources:
+` + sourceHelp(),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		from, _ := c.Flags().GetString("from")
+		if from == "" {
+			return c.Help()
+		}
+		cfgPath, _ := c.Flags().GetString("config")
+		noValidate, _ := c.Flags().GetBool("no-validate")
+		overwrite, _ := c.Flags().GetBool("overwrite")
+		restoreConfig, _ := c.Flags().GetBool("restore-config")
+
+		result, err := bootstrap.Bootstrap(cfgPath)
+		if err != nil {
+			return err
+		}
+
+		opts := cmd.ImportOptions{
+			From:           from,
+			SkipValidation: noValidate,
+			Overwrite:      overwrite,
+			RestoreConfig:  restoreConfig,
+			ConfigPath:     result.ConfigFilePath,
+		}
+		if len(args) > 0 {
+			opts.Path = args[0]
+		}
+		return cmd.DoImport(result.Config, opts)
+	},
+}
+
+func init() {
+	ImportCmd.Flags().String("from", "", "source format to import from ("+strings.Join(importer.Names(), ", ")+")")
+	ImportCmd.Flags().Bool("no-validate", false, "save accounts without checking them with the provider")
+	ImportCmd.Flags().Bool("overwrite", false, "replace existing accounts with the same ID")
+	ImportCmd.Flags().Bool("restore-config", false, "with --from llm-mux, also restore the exported config file")
+}
+
+func sourceHelp() string {
+	var b strings.Builder
+	for _, src := range importer.Sources() {
+		fmt.Fprintf(&b, "  %-12s %s\n", src.Name(), src.Description())
+	}
+	return b.String()
 }
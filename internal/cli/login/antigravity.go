@@ -14,10 +14,12 @@ var antigravityCmd = &cobra.Command{
 
 This command initiates the OAuth flow for Google Gemini through the Antigravity
 provider. A browser window will open for you to authenticate with your Google
-account. Use --no-browser to get a manual authentication URL instead.`,
+account. Use --no-browser to get a manual authentication URL instead, or
+--headless when this machine cannot receive the callback.`,
 	RunE: func(c *cobra.Command, args []string) error {
 		cfgPath, _ := c.Flags().GetString("config")
 		noBrowser, _ := c.Flags().GetBool("no-browser")
+		headless, _ := c.Flags().GetBool("headless")
 
 		result, err := bootstrap.Bootstrap(cfgPath)
 		if err != nil {
@@ -26,6 +28,7 @@ account. Use --no-browser to get a manual authentication URL instead.`,
 
 		options := &cmd.LoginOptions{
 			NoBrowser: noBrowser,
+			Headless:  headless,
 		}
 
 		cmd.DoAntigravityLogin(result.Config, options)
@@ -15,10 +15,12 @@ This command initiates the OAuth authentication flow for Anthropic Claude servic
 It will open a browser window for you to sign in with your Anthropic account.
 Once authenticated, your credentials will be saved locally.
 
-Use --no-browser flag to get a URL to open manually instead.`,
+Use --no-browser flag to get a URL to open manually instead, or --headless
+when this machine cannot receive the callback.`,
 	RunE: func(c *cobra.Command, args []string) error {
 		cfgPath, _ := c.Flags().GetString("config")
 		noBrowser, _ := c.Flags().GetBool("no-browser")
+		headless, _ := c.Flags().GetBool("headless")
 
 		result, err := bootstrap.Bootstrap(cfgPath)
 		if err != nil {
@@ -27,6 +29,7 @@ Use --no-browser flag to get a URL to open manually instead.`,
 
 		options := &cmd.LoginOptions{
 			NoBrowser: noBrowser,
+			Headless:  headless,
 		}
 
 		cmd.DoClaudeLogin(result.Config, options)
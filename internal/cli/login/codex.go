@@ -13,10 +13,12 @@ var codexCmd = &cobra.Command{
 
 This command initiates the OAuth authentication flow for OpenAI Codex services.
 A browser window will open to complete the authentication process.
-Use --no-browser flag to get the URL instead of opening the browser automatically.`,
+Use --no-browser flag to get the URL instead of opening the browser automatically,
+or --headless when this machine cannot receive the callback.`,
 	RunE: func(c *cobra.Command, args []string) error {
 		cfgPath, _ := c.Flags().GetString("config")
 		noBrowser, _ := c.Flags().GetBool("no-browser")
+		headless, _ := c.Flags().GetBool("headless")
 
 		result, err := bootstrap.Bootstrap(cfgPath)
 		if err != nil {
@@ -25,6 +27,7 @@ Use --no-browser flag to get the URL instead of opening the browser automaticall
 
 		options := &cmd.LoginOptions{
 			NoBrowser: noBrowser,
+			Headless:  headless,
 		}
 
 		cmd.DoCodexLogin(result.Config, options)
@@ -1,6 +1,8 @@
 package login
 
 import (
+	"fmt"
+
 	"github.com/nghyane/llm-mux/internal/bootstrap"
 	clicmd "github.com/nghyane/llm-mux/internal/cmd"
 	"github.com/spf13/cobra"
@@ -21,6 +23,9 @@ If not provided, you will be prompted to select from available projects.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfgPath, _ := cmd.Flags().GetString("config")
 		noBrowser, _ := cmd.Flags().GetBool("no-browser")
+		if headless, _ := cmd.Flags().GetBool("headless"); headless {
+			return fmt.Errorf("gemini login does not support --headless; use `llm-mux login antigravity --headless` or an SSH tunnel to port 8085")
+		}
 
 		result, err := bootstrap.Bootstrap(cfgPath)
 		if err != nil {
@@ -8,5 +8,15 @@ import (
 var LoginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login to LLM providers",
-	Long:  `Login to various LLM providers using OAuth or other authentication methods.`,
+	Long: `Login to various LLM providers using OAuth or other authentication methods.
+
+On a machine that cannot receive the OAuth redirect (a remote server without
+a browser or an open callback port), add --headless to claude, codex,
+antigravity or iflow: the auth URL is printed, consent is completed in any
+browser, and the redirect URL it lands on is pasted back into the terminal.
+Copilot and Qwen use a device code and need no callback.`,
+}
+
+func init() {
+	LoginCmd.PersistentFlags().Bool("headless", false, "print the auth URL and paste the redirect URL back instead of waiting for a local callback")
 }
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+const defaultProfileAddr = "localhost:6060"
+
+var (
+	profileAddr     string
+	cpuProfilePath  string
+	heapProfilePath string
+)
+
+// startProfiling serves net/http/pprof on addr when it is set and starts a
+// CPU profile into cpuPath when that is set. The returned stop ends the CPU
+// profile and writes a heap profile to heapPath when that is set.
+func startProfiling(addr, cpuPath, heapPath string) (stop func(), err error) {
+	if addr != "" {
+		if err := servePprof(addr); err != nil {
+			return nil, err
+		}
+	}
+
+	var cpuFile *os.File
+	if cpuPath != "" {
+		if cpuFile, err = os.Create(cpuPath); err != nil {
+			return nil, fmt.Errorf("cpu profile: %w", err)
+		}
+		if err = rpprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			return nil, fmt.Errorf("cpu profile: %w", err)
+		}
+		log.Infof("Writing CPU profile to %s until shutdown", cpuPath)
+	}
+
+	return func() {
+		if cpuFile != nil {
+			rpprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				log.Errorf("cpu profile: %v", err)
+			}
+		}
+		if heapPath != "" {
+			if err := writeHeapProfile(heapPath); err != nil {
+				log.Errorf("heap profile: %v", err)
+			}
+		}
+	}, nil
+}
+
+// servePprof starts the pprof handlers on their own listener, which must be
+// a loopback address so profiles are never exposed beyond the host.
+func servePprof(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("profile address %q: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("profile address %q must be on localhost", addr)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("profile listener: %w", err)
+	}
+	srv := &http.Server{Handler: pprofMux(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("profile server: %v", err)
+		}
+	}()
+	log.Infof("pprof listening on http://%s/debug/pprof/", ln.Addr())
+	return nil
+}
+
+// pprofMux registers the pprof handlers on a private mux rather than
+// http.DefaultServeMux.
+func pprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC() // report live objects as of now
+	if err := rpprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Infof("Wrote heap profile to %s", path)
+	return nil
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServePprofRequiresLoopback(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:6060", ":6060", "example.com:6060", "6060"} {
+		if err := servePprof(addr); err == nil {
+			t.Errorf("servePprof(%q) accepted a non-loopback address", addr)
+		}
+	}
+}
+
+func TestPprofMux(t *testing.T) {
+	srv := httptest.NewServer(pprofMux())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/debug/pprof/heap?debug=1")
+	if err != nil {
+		t.Fatalf("GET heap: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("heap status = %d", resp.StatusCode)
+	}
+}
+
+func TestProfileFiles(t *testing.T) {
+	dir := t.TempDir()
+	cpu, heap := filepath.Join(dir, "cpu.pprof"), filepath.Join(dir, "heap.pprof")
+
+	stop, err := startProfiling("", cpu, heap)
+	if err != nil {
+		t.Fatalf("startProfiling: %v", err)
+	}
+	stop()
+
+	for _, path := range []string{cpu, heap} {
+		if fi, err := os.Stat(path); err != nil || fi.Size() == 0 {
+			t.Errorf("%s not written: %v", filepath.Base(path), err)
+		}
+	}
+}
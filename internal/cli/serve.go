@@ -12,7 +12,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var servePort int
+var (
+	servePort  int
+	serveWatch bool
+)
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
@@ -51,7 +54,13 @@ initializes the token stores, and starts the HTTP server.`,
 			log.Fatalf("Failed to configure log output: %v", err)
 		}
 
-		cmd.StartService(cfg, result.ConfigFilePath, "")
+		stopProfiling, err := startProfiling(profileAddr, cpuProfilePath, heapProfilePath)
+		if err != nil {
+			log.Fatalf("Failed to start profiling: %v", err)
+		}
+		defer stopProfiling()
+
+		cmd.StartService(cfg, result.ConfigFilePath, "", serveWatch)
 	},
 }
 
@@ -88,5 +97,10 @@ func initUsageBackend(cfg *config.Config) {
 
 func init() {
 	serveCmd.Flags().IntVarP(&servePort, "port", "p", 8317, "server port")
+	serveCmd.Flags().BoolVar(&serveWatch, "watch", true, "apply config file edits without restarting (--watch=false reads it once)")
+	serveCmd.Flags().StringVar(&profileAddr, "profile", "", "serve net/http/pprof on this localhost address (--profile alone uses "+defaultProfileAddr+")")
+	serveCmd.Flags().Lookup("profile").NoOptDefVal = defaultProfileAddr
+	serveCmd.Flags().StringVar(&cpuProfilePath, "cpu-profile", "", "write a CPU profile covering the server's lifetime to this file")
+	serveCmd.Flags().StringVar(&heapProfilePath, "heap-profile", "", "write a heap profile to this file on shutdown")
 	rootCmd.AddCommand(serveCmd)
 }
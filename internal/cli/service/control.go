@@ -4,56 +4,54 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 func runServiceCommand(action string) error {
-	var cmd *exec.Cmd
+	initSys, err := detectInitSystem()
+	if err != nil {
+		return err
+	}
 
-	switch runtime.GOOS {
-	case "darwin":
-		// macOS uses launchctl
-		serviceName := "com.llm-mux"
+	var cmd *exec.Cmd
+	switch initSys {
+	case initLaunchd:
 		switch action {
 		case "start":
-			cmd = exec.Command("launchctl", "start", serviceName)
+			cmd = exec.Command("launchctl", "start", launchdLabel)
 		case "stop":
-			cmd = exec.Command("launchctl", "stop", serviceName)
-		case "status":
-			// launchctl list returns 0 if running, non-zero if not
-			cmd = exec.Command("launchctl", "list", serviceName)
+			cmd = exec.Command("launchctl", "stop", launchdLabel)
 		}
-	case "linux":
-		// Linux uses systemctl --user
-		serviceName := "llm-mux"
+	case initSystemd:
 		switch action {
 		case "start":
-			cmd = exec.Command("systemctl", "--user", "start", serviceName)
+			cmd = exec.Command("systemctl", "--user", "start", unitName)
 		case "stop":
-			cmd = exec.Command("systemctl", "--user", "stop", serviceName)
-		case "status":
-			cmd = exec.Command("systemctl", "--user", "is-active", serviceName)
+			cmd = exec.Command("systemctl", "--user", "stop", unitName)
 		}
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
 
 	if cmd == nil {
 		return fmt.Errorf("unknown action: %s", action)
 	}
 
-	// Capture output for status check, otherwise stream to stdout
-	if action == "status" {
-		return cmd.Run()
-	}
-
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// serviceRunning reports whether the installed service is running.
+func serviceRunning(initSys initSystem) bool {
+	if initSys == initLaunchd {
+		// A loaded job reports its PID only while running.
+		out, err := exec.Command("launchctl", "list", launchdLabel).Output()
+		return err == nil && strings.Contains(string(out), `"PID" =`)
+	}
+	return exec.Command("systemctl", "--user", "is-active", "--quiet", unitName).Run() == nil
+}
+
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the service",
@@ -74,11 +72,11 @@ var restartCmd = &cobra.Command{
 	Use:   "restart",
 	Short: "Restart the service",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if runtime.GOOS == "darwin" || runtime.GOOS == "linux" {
-			_ = runServiceCommand("stop")
-			return runServiceCommand("start")
+		if _, err := detectInitSystem(); err != nil {
+			return err
 		}
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+		_ = runServiceCommand("stop")
+		return runServiceCommand("start")
 	},
 }
 
@@ -86,7 +84,19 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check service status",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := runServiceCommand("status"); err != nil {
+		initSys, err := detectInitSystem()
+		if err != nil {
+			return err
+		}
+		path := unitPath()
+		if initSys == initLaunchd {
+			path = plistPath()
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			fmt.Println("Service is not installed")
+			return nil
+		}
+		if !serviceRunning(initSys) {
 			fmt.Println("Service is stopped")
 			return nil
 		}
@@ -99,15 +109,17 @@ var logsCmd = &cobra.Command{
 	Use:   "logs",
 	Short: "View service logs",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		initSys, err := detectInitSystem()
+		if err != nil {
+			return err
+		}
 		var c *exec.Cmd
-		if runtime.GOOS == "darwin" {
+		if initSys == initLaunchd {
 			home, _ := os.UserHomeDir()
 			logPath := home + "/.local/var/log/llm-mux.log"
 			c = exec.Command("tail", "-f", logPath)
-		} else if runtime.GOOS == "linux" {
-			c = exec.Command("journalctl", "--user", "-u", "llm-mux", "-f")
 		} else {
-			return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+			c = exec.Command("journalctl", "--user", "-u", unitName, "-f")
 		}
 
 		c.Stdout = os.Stdout
@@ -4,33 +4,49 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
 	"path/filepath"
-	"runtime"
+	"strings"
 
+	"github.com/nghyane/llm-mux/internal/util"
 	"github.com/spf13/cobra"
 )
 
+const defaultConfigPath = "$XDG_CONFIG_HOME/llm-mux/config.yaml"
+
+// unitSpec describes what the generated service definition runs and as whom.
+type unitSpec struct {
+	ExePath    string
+	ConfigPath string
+	User       string
+	Home       string
+	LogDir     string
+}
+
 var installCmd = &cobra.Command{
 	Use:   "install",
 	Short: "Install background service",
+	Long: `Install llm-mux as a per-user background service (systemd on Linux,
+launchd on macOS). The service runs "llm-mux serve" with the config file
+given by --config, or the default config path, and starts at login.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		exe, err := os.Executable()
+		initSys, err := detectInitSystem()
 		if err != nil {
 			return err
 		}
-
-		exePath, err := filepath.EvalSymlinks(exe)
+		spec, err := resolveUnitSpec(cmd)
 		if err != nil {
-			exePath = exe
+			return err
+		}
+		if _, err := os.Stat(spec.ConfigPath); err != nil {
+			fmt.Printf("Warning: config file %s not found; run 'llm-mux init' before starting the service\n", spec.ConfigPath)
 		}
 
-		switch runtime.GOOS {
-		case "darwin":
-			return installMacOS(exePath)
-		case "linux":
-			return installLinux(exePath)
+		switch initSys {
+		case initLaunchd:
+			return installMacOS(spec)
 		default:
-			return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+			return installLinux(spec)
 		}
 	},
 }
@@ -39,13 +55,15 @@ var uninstallCmd = &cobra.Command{
 	Use:   "uninstall",
 	Short: "Uninstall background service",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		switch runtime.GOOS {
-		case "darwin":
+		initSys, err := detectInitSystem()
+		if err != nil {
+			return err
+		}
+		switch initSys {
+		case initLaunchd:
 			return uninstallMacOS()
-		case "linux":
-			return uninstallLinux()
 		default:
-			return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+			return uninstallLinux()
 		}
 	},
 }
@@ -55,17 +73,59 @@ func init() {
 	ServiceCmd.AddCommand(uninstallCmd)
 }
 
-// macOS implementation
-func installMacOS(exePath string) error {
-	home, _ := os.UserHomeDir()
-	plistPath := filepath.Join(home, "Library/LaunchAgents/com.llm-mux.plist")
-	logDir := filepath.Join(home, ".local/var/log")
+// resolveUnitSpec gathers the absolute binary and config paths and the
+// invoking user for the service definition.
+func resolveUnitSpec(cmd *cobra.Command) (unitSpec, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return unitSpec{}, err
+	}
+	exePath, err := filepath.EvalSymlinks(exe)
+	if err != nil {
+		exePath = exe
+	}
 
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return err
+	configPath, _ := cmd.Flags().GetString("config")
+	if configPath == "" {
+		configPath = defaultConfigPath
+	}
+	configPath, err = util.ResolveAuthDir(configPath)
+	if err != nil {
+		return unitSpec{}, fmt.Errorf("resolve config path: %w", err)
 	}
+	if configPath, err = filepath.Abs(configPath); err != nil {
+		return unitSpec{}, fmt.Errorf("resolve config path: %w", err)
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return unitSpec{}, fmt.Errorf("resolve current user: %w", err)
+	}
+	home := u.HomeDir
+	if h, err := os.UserHomeDir(); err == nil {
+		home = h
+	}
+
+	return unitSpec{
+		ExePath:    exePath,
+		ConfigPath: configPath,
+		User:       u.Username,
+		Home:       home,
+		LogDir:     filepath.Join(home, ".local/var/log"),
+	}, nil
+}
+
+// macOS implementation
 
-	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+func plistPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library/LaunchAgents/com.llm-mux.plist")
+}
+
+// launchdPlist renders the LaunchAgent definition for spec.
+func launchdPlist(spec unitSpec) string {
+	esc := xmlEscape
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
 <plist version="1.0">
 <dict>
@@ -74,6 +134,9 @@ func installMacOS(exePath string) error {
     <key>ProgramArguments</key>
     <array>
         <string>%s</string>
+        <string>serve</string>
+        <string>--config</string>
+        <string>%s</string>
     </array>
     <key>RunAtLoad</key>
     <true/>
@@ -94,23 +157,36 @@ func installMacOS(exePath string) error {
         <string>/usr/local/bin:/usr/bin:/bin:/usr/sbin:/sbin</string>
         <key>HOME</key>
         <string>%s</string>
+        <key>USER</key>
+        <string>%s</string>
     </dict>
     <key>WorkingDirectory</key>
     <string>%s</string>
 </dict>
-</plist>`, exePath, logDir, logDir, home, home)
+</plist>
+`, esc(spec.ExePath), esc(spec.ConfigPath), esc(spec.LogDir), esc(spec.LogDir), esc(spec.Home), esc(spec.User), esc(spec.Home))
+}
 
-	if err := os.WriteFile(plistPath, []byte(plist), 0644); err != nil {
+func installMacOS(spec unitSpec) error {
+	path := plistPath()
+	if err := os.MkdirAll(spec.LogDir, 0755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(launchdPlist(spec)), 0644); err != nil {
 		return fmt.Errorf("failed to write plist: %w", err)
 	}
 
-	fmt.Printf("Service installed to %s\n", plistPath)
+	fmt.Printf("Service installed to %s\n", path)
+	fmt.Printf("  user:   %s\n  config: %s\n", spec.User, spec.ConfigPath)
 
-	// Load service
-	exec.Command("launchctl", "unload", plistPath).Run() // Ignore error
-	if err := exec.Command("launchctl", "load", plistPath).Run(); err != nil {
+	// Reload so a reinstall picks up the new definition.
+	_ = exec.Command("launchctl", "unload", path).Run()
+	if err := runQuiet("launchctl", "load", "-w", path); err != nil {
 		fmt.Printf("Warning: failed to load service: %v\n", err)
-		fmt.Printf("Try running: launchctl load %s\n", plistPath)
+		fmt.Printf("Try running: launchctl load -w %s\n", path)
 	} else {
 		fmt.Println("Service started")
 	}
@@ -119,12 +195,14 @@ func installMacOS(exePath string) error {
 }
 
 func uninstallMacOS() error {
-	home, _ := os.UserHomeDir()
-	plistPath := filepath.Join(home, "Library/LaunchAgents/com.llm-mux.plist")
+	path := plistPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("service is not installed (%s not found)", path)
+	}
 
-	exec.Command("launchctl", "unload", plistPath).Run()
+	_ = exec.Command("launchctl", "unload", "-w", path).Run()
 
-	if err := os.Remove(plistPath); err != nil {
+	if err := os.Remove(path); err != nil {
 		return fmt.Errorf("failed to remove plist: %w", err)
 	}
 
@@ -133,68 +211,115 @@ func uninstallMacOS() error {
 }
 
 // Linux implementation
-func installLinux(exePath string) error {
-	home, _ := os.UserHomeDir()
-	serviceDir := filepath.Join(home, ".config/systemd/user")
-	servicePath := filepath.Join(serviceDir, "llm-mux.service")
 
-	if err := os.MkdirAll(serviceDir, 0755); err != nil {
-		return err
-	}
+func unitPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config/systemd/user/llm-mux.service")
+}
 
-	unit := fmt.Sprintf(`[Unit]
+// systemdUnit renders the systemd user unit for spec.
+func systemdUnit(spec unitSpec) string {
+	return fmt.Sprintf(`# Installed by "llm-mux service install" for user %s
+[Unit]
 Description=llm-mux - Multi-provider LLM gateway
 After=network-online.target
 Wants=network-online.target
+StartLimitBurst=3
+StartLimitIntervalSec=60
 
 [Service]
 Type=simple
-ExecStart=%s
+ExecStart=%s serve --config %s
 WorkingDirectory=%s
 Restart=on-failure
 RestartSec=5
-StartLimitBurst=3
-StartLimitIntervalSec=60
-Environment=HOME=%s
+Environment=%s
+Environment=%s
 Environment=PATH=/usr/local/bin:/usr/bin:/bin
 
 [Install]
 WantedBy=default.target
-`, exePath, home, home)
+`, spec.User, systemdQuote(spec.ExePath), systemdQuote(spec.ConfigPath), spec.Home,
+		systemdQuote("HOME="+spec.Home), systemdQuote("USER="+spec.User))
+}
 
-	if err := os.WriteFile(servicePath, []byte(unit), 0644); err != nil {
+func installLinux(spec unitSpec) error {
+	path := unitPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(systemdUnit(spec)), 0644); err != nil {
 		return fmt.Errorf("failed to write unit file: %w", err)
 	}
 
-	fmt.Printf("Service installed to %s\n", servicePath)
+	fmt.Printf("Service installed to %s\n", path)
+	fmt.Printf("  user:   %s\n  config: %s\n", spec.User, spec.ConfigPath)
 
-	// Reload and enable
-	exec.Command("systemctl", "--user", "daemon-reload").Run()
-	exec.Command("systemctl", "--user", "enable", "llm-mux").Run()
-	if err := exec.Command("systemctl", "--user", "start", "llm-mux").Run(); err != nil {
+	if err := runQuiet("systemctl", "--user", "daemon-reload"); err != nil {
+		return fmt.Errorf("systemctl daemon-reload: %w", err)
+	}
+	if err := runQuiet("systemctl", "--user", "enable", unitName); err != nil {
+		return fmt.Errorf("systemctl enable: %w", err)
+	}
+	if err := runQuiet("systemctl", "--user", "restart", unitName); err != nil {
 		fmt.Printf("Warning: failed to start service: %v\n", err)
+		fmt.Printf("Check: journalctl --user -u %s\n", unitName)
 	} else {
 		fmt.Println("Service started")
 	}
 
-	// Enable lingering
-	exec.Command("loginctl", "enable-linger", os.Getenv("USER")).Run()
+	// Keep the user manager running without an active login session.
+	if err := runQuiet("loginctl", "enable-linger", spec.User); err != nil {
+		fmt.Printf("Warning: failed to enable lingering: %v\n", err)
+		fmt.Printf("The service stops at logout unless you run: loginctl enable-linger %s\n", spec.User)
+	}
 
 	return nil
 }
 
 func uninstallLinux() error {
-	exec.Command("systemctl", "--user", "stop", "llm-mux").Run()
-	exec.Command("systemctl", "--user", "disable", "llm-mux").Run()
+	path := unitPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("service is not installed (%s not found)", path)
+	}
 
-	home, _ := os.UserHomeDir()
-	servicePath := filepath.Join(home, ".config/systemd/user/llm-mux.service")
+	_ = exec.Command("systemctl", "--user", "stop", unitName).Run()
+	_ = exec.Command("systemctl", "--user", "disable", unitName).Run()
 
-	if err := os.Remove(servicePath); err != nil {
+	if err := os.Remove(path); err != nil {
 		return fmt.Errorf("failed to remove unit file: %w", err)
 	}
 
-	exec.Command("systemctl", "--user", "daemon-reload").Run()
+	if err := runQuiet("systemctl", "--user", "daemon-reload"); err != nil {
+		return fmt.Errorf("systemctl daemon-reload: %w", err)
+	}
 	fmt.Println("Service uninstalled")
 	return nil
 }
+
+// runQuiet runs a command and folds its combined output into the error.
+func runQuiet(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// systemdQuote quotes a unit file value when it contains whitespace, quotes,
+// backslashes or specifiers.
+func systemdQuote(s string) string {
+	if !strings.ContainsAny(s, " \t\"'\\%") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `%`, `%%`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func xmlEscape(s string) string {
+	r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+	return r.Replace(s)
+}
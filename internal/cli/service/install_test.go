@@ -0,0 +1,52 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServiceDefinitions(t *testing.T) {
+	spec := unitSpec{
+		ExePath:    "/usr/local/bin/llm-mux",
+		ConfigPath: "/home/alex/My Config/config.yaml",
+		User:       "alex",
+		Home:       "/home/alex",
+		LogDir:     "/home/alex/.local/var/log",
+	}
+
+	unit := systemdUnit(spec)
+	for _, want := range []string{
+		`ExecStart=/usr/local/bin/llm-mux serve --config "/home/alex/My Config/config.yaml"`,
+		"Environment=USER=alex",
+		"for user alex",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("systemd unit missing %q:\n%s", want, unit)
+		}
+	}
+
+	plist := launchdPlist(spec)
+	for _, want := range []string{
+		"<string>serve</string>",
+		"<string>/home/alex/My Config/config.yaml</string>",
+		"<key>USER</key>\n        <string>alex</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("launchd plist missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := map[string]string{
+		"/usr/bin/llm-mux": "/usr/bin/llm-mux",
+		"/a b/c":           `"/a b/c"`,
+		`/a"b`:             `"/a\"b"`,
+		"/100%":            `"/100%%"`,
+	}
+	for in, want := range tests {
+		if got := systemdQuote(in); got != want {
+			t.Errorf("systemdQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -1,10 +1,52 @@
 package service
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
 
 // ServiceCmd is the parent command for service management
 var ServiceCmd = &cobra.Command{
 	Use:   "service",
 	Short: "Manage background service",
-	Long:  `Manage the llm-mux background service (install, start, stop, status).`,
+	Long:  `Manage the llm-mux background service (install, uninstall, start, stop, status).`,
+}
+
+const (
+	unitName     = "llm-mux"
+	launchdLabel = "com.llm-mux"
+)
+
+type initSystem int
+
+const (
+	initSystemd initSystem = iota + 1
+	initLaunchd
+)
+
+// detectInitSystem returns the service manager this host runs, or an error
+// explaining why llm-mux cannot install itself as a service here.
+func detectInitSystem() (initSystem, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("launchctl"); err != nil {
+			return 0, fmt.Errorf("launchctl not found in PATH")
+		}
+		return initLaunchd, nil
+	case "linux":
+		// Same check as sd_booted(3): the directory exists only when systemd is PID 1.
+		if fi, err := os.Stat("/run/systemd/system"); err != nil || !fi.IsDir() {
+			return 0, fmt.Errorf("systemd is not running on this host; only systemd is supported on Linux, run 'llm-mux serve' under your init system instead")
+		}
+		if _, err := exec.LookPath("systemctl"); err != nil {
+			return 0, fmt.Errorf("systemctl not found in PATH")
+		}
+		return initSystemd, nil
+	default:
+		return 0, fmt.Errorf("unsupported platform: %s (service management supports systemd on Linux and launchd on macOS)", runtime.GOOS)
+	}
 }
@@ -0,0 +1,222 @@
+// Package cmd contains CLI helpers. This file implements showing one account's
+// live state by querying the management API of the running server.
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// accountRequestTimeout bounds the management API call made by DoAccountShow.
+const accountRequestTimeout = 10 * time.Second
+
+// accountDetail mirrors the fields of GET /v1/management/accounts/{id} that
+// DoAccountShow prints.
+type accountDetail struct {
+	ID             string                          `json:"id"`
+	Provider       string                          `json:"provider"`
+	Label          string                          `json:"label"`
+	Email          string                          `json:"email"`
+	Status         string                          `json:"status"`
+	StatusMessage  string                          `json:"status_message"`
+	Disabled       bool                            `json:"disabled"`
+	Unavailable    bool                            `json:"unavailable"`
+	TokenExpiresAt time.Time                       `json:"token_expires_at"`
+	Scopes         []string                        `json:"scopes"`
+	LastRefresh    time.Time                       `json:"last_refresh"`
+	NextRetryAfter time.Time                       `json:"next_retry_after"`
+	Quota          provider.QuotaState             `json:"quota"`
+	LastError      *provider.Error                 `json:"last_error"`
+	Latency        *provider.AccountLatency        `json:"latency"`
+	ModelStates    map[string]*provider.ModelState `json:"model_states"`
+	ActionRequired string                          `json:"action_required"`
+	QuotaState     struct {
+		ActiveRequests  int64     `json:"active_requests"`
+		TotalTokensUsed int64     `json:"total_tokens_used"`
+		InCooldown      bool      `json:"in_cooldown"`
+		CooldownUntil   time.Time `json:"cooldown_until"`
+	} `json:"quota_state"`
+}
+
+// DoAccountShow fetches one account from the running server and prints its
+// state. The account is named by ID, file name, label or email. With asJSON
+// the sanitized API response is printed as is.
+func DoAccountShow(cfg *config.Config, id string, asJSON bool) error {
+	if cfg == nil || cfg.Port <= 0 {
+		return fmt.Errorf("account: server port is not configured")
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return fmt.Errorf("account: an account id is required")
+	}
+	key := config.GetManagementKey()
+	if key == "" {
+		return fmt.Errorf("account: management key not configured (run llm-mux init or set MANAGEMENT_PASSWORD)")
+	}
+
+	scheme := "http"
+	client := &http.Client{Timeout: accountRequestTimeout}
+	if cfg.TLS.Enable {
+		scheme = "https"
+		// The server's certificate is issued for its public name, not the
+		// loopback address dialed here.
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	endpoint := fmt.Sprintf("%s://127.0.0.1:%d/v1/management/accounts/%s", scheme, cfg.Port, url.PathEscape(id))
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("account: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("account: cannot reach llm-mux on port %d, is the server running? (%w)", cfg.Port, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("account: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("account: %s", apiErr.Error.Message)
+		}
+		return fmt.Errorf("account: server returned %s", resp.Status)
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err = json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("account: decode response: %w", err)
+	}
+	if asJSON {
+		out, errIndent := json.MarshalIndent(envelope.Data, "", "  ")
+		if errIndent != nil {
+			return fmt.Errorf("account: %w", errIndent)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	var detail accountDetail
+	if err = json.Unmarshal(envelope.Data, &detail); err != nil {
+		return fmt.Errorf("account: decode response: %w", err)
+	}
+	printAccountDetail(os.Stdout, &detail, time.Now())
+	return nil
+}
+
+func printAccountDetail(w io.Writer, d *accountDetail, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", name, value)
+		}
+	}
+	row("ID", d.ID)
+	row("Provider", d.Provider)
+	row("Label", d.Label)
+	row("Email", d.Email)
+	status := d.Status
+	if d.Disabled {
+		status += " (disabled)"
+	} else if d.Unavailable {
+		status += " (unavailable)"
+	}
+	if d.StatusMessage != "" {
+		status += ": " + d.StatusMessage
+	}
+	row("Status", status)
+	row("Action", d.ActionRequired)
+	row("Token expires", relativeTime(d.TokenExpiresAt, now))
+	row("Scopes", strings.Join(d.Scopes, " "))
+	row("Last refresh", relativeTime(d.LastRefresh, now))
+	row("Retry after", relativeTime(d.NextRetryAfter, now))
+	if d.Quota.Exceeded {
+		quota := "exceeded"
+		if d.Quota.Reason != "" {
+			quota += " (" + d.Quota.Reason + ")"
+		}
+		if !d.Quota.NextRecoverAt.IsZero() {
+			quota += ", recovers " + relativeTime(d.Quota.NextRecoverAt, now)
+		}
+		row("Quota", quota)
+	}
+	if d.Quota.BackoffLevel > 0 {
+		row("Backoff level", fmt.Sprint(d.Quota.BackoffLevel))
+	}
+	usage := fmt.Sprintf("%d active request(s), %d token(s) used", d.QuotaState.ActiveRequests, d.QuotaState.TotalTokensUsed)
+	if d.QuotaState.InCooldown {
+		usage += ", cooling down until " + relativeTime(d.QuotaState.CooldownUntil, now)
+	}
+	row("Usage", usage)
+	if d.Latency != nil {
+		row("Latency", formatLatency(d.Latency.Total))
+		row("First byte", formatLatency(d.Latency.FirstByte))
+	}
+	if d.LastError != nil {
+		row("Last error", d.LastError.Error())
+	}
+	tw.Flush()
+
+	if len(d.ModelStates) == 0 {
+		return
+	}
+	models := make([]string, 0, len(d.ModelStates))
+	for model := range d.ModelStates {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	fmt.Fprintln(w, "\nModels:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, model := range models {
+		state := d.ModelStates[model]
+		line := string(state.Status)
+		if state.Unavailable {
+			line += "\tunavailable until " + relativeTime(state.NextRetryAfter, now)
+		} else {
+			line += "\t"
+		}
+		if state.LastError != nil {
+			line += "\t" + state.LastError.Error()
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", model, line)
+	}
+	tw.Flush()
+}
+
+func formatLatency(p provider.LatencyPercentiles) string {
+	if p.Samples == 0 {
+		return ""
+	}
+	return fmt.Sprintf("p50 %dms, p95 %dms, p99 %dms over %d request(s)", p.P50Ms, p.P95Ms, p.P99Ms, p.Samples)
+}
+
+// relativeTime formats t with its distance from now, e.g. "2026-01-02T15:04:05Z (in 42m)".
+func relativeTime(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := t.Sub(now).Round(time.Second)
+	if d >= 0 {
+		return fmt.Sprintf("%s (in %s)", t.Local().Format(time.RFC3339), d)
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Local().Format(time.RFC3339), -d)
+}
@@ -27,6 +27,7 @@ func DoClaudeLogin(cfg *config.Config, options *LoginOptions) {
 
 	authOpts := &login.LoginOptions{
 		NoBrowser: options.NoBrowser,
+		Headless:  options.Headless,
 		Metadata:  map[string]string{},
 		Prompt:    options.Prompt,
 	}
@@ -0,0 +1,83 @@
+// Package cmd contains CLI helpers. This file implements converting the auth
+// files of an existing store to and from at-rest encryption.
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// DoEncryptAuth rewrites every auth file under the auth directory encrypted
+// with the configured passphrase, or in plaintext when decrypt is set. Files
+// already in the requested form are left alone, so it is safe to re-run.
+func DoEncryptAuth(cfg *config.Config, decrypt bool) error {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if resolved, errResolve := util.ResolveAuthDir(cfg.AuthDir); errResolve == nil {
+		cfg.AuthDir = resolved
+	}
+	if !decrypt && !atrest.Enabled() {
+		return fmt.Errorf("encrypt-auth: no passphrase configured (set %s or auth-encryption.passphrase)", atrest.PassphraseEnv)
+	}
+
+	var converted, unchanged, failed int
+	err := filepath.WalkDir(cfg.AuthDir, func(path string, d fs.DirEntry, errWalk error) error {
+		if errWalk != nil {
+			return errWalk
+		}
+		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".json") {
+			return nil
+		}
+		raw, errRead := os.ReadFile(path)
+		if errRead != nil || len(raw) == 0 {
+			return nil
+		}
+		if atrest.IsEncrypted(raw) != decrypt {
+			unchanged++
+			return nil
+		}
+		plain, errOpen := atrest.Open(raw)
+		if errOpen != nil {
+			failed++
+			fmt.Printf("  failed   %s (%v)\n", d.Name(), errOpen)
+			return nil
+		}
+		out := plain
+		if !decrypt {
+			if out, errOpen = atrest.Seal(plain); errOpen != nil {
+				return errOpen
+			}
+		}
+		tmp := path + ".tmp"
+		if errWrite := os.WriteFile(tmp, out, 0o600); errWrite != nil {
+			return fmt.Errorf("encrypt-auth: write %s: %w", tmp, errWrite)
+		}
+		if errRename := os.Rename(tmp, path); errRename != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("encrypt-auth: rename %s: %w", tmp, errRename)
+		}
+		converted++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "Encrypted"
+	if decrypt {
+		verb = "Decrypted"
+	}
+	fmt.Printf("%s %d auth file(s) in %s, %d already done, %d failed\n", verb, converted, cfg.AuthDir, unchanged, failed)
+	if failed > 0 {
+		return fmt.Errorf("encrypt-auth: %d file(s) could not be converted", failed)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package cmd contains CLI helpers. This file implements exporting accounts and
+// config into a single bundle that `llm-mux import --from llm-mux` restores.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/auth/importer"
+	"github.com/nghyane/llm-mux/internal/auth/login"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportOptions controls DoExport.
+type ExportOptions struct {
+	// Out is the bundle path.
+	Out string
+	// NoSecrets redacts tokens, keys and passwords from accounts and config.
+	NoSecrets bool
+}
+
+// DoExport writes every stored account and the config file at configPath into
+// one JSON bundle. The file is created with owner-only permissions because it
+// holds credentials in plain text unless NoSecrets is set.
+func DoExport(cfg *config.Config, configPath string, opts ExportOptions) error {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if resolved, errResolve := util.ResolveAuthDir(cfg.AuthDir); errResolve == nil {
+		cfg.AuthDir = resolved
+	}
+	out := strings.TrimSpace(opts.Out)
+	if out == "" {
+		return fmt.Errorf("export: --out is required")
+	}
+
+	store := login.GetTokenStore()
+	if setter, ok := store.(interface{ SetBaseDir(string) }); ok {
+		setter.SetBaseDir(cfg.AuthDir)
+	}
+	auths, err := store.List(context.Background())
+	if err != nil {
+		return fmt.Errorf("export: list accounts: %w", err)
+	}
+
+	var rawConfig map[string]any
+	if configPath != "" {
+		data, errRead := os.ReadFile(configPath)
+		switch {
+		case errRead == nil:
+			if errParse := yaml.Unmarshal(data, &rawConfig); errParse != nil {
+				return fmt.Errorf("export: parse %s: %w", configPath, errParse)
+			}
+		case !os.IsNotExist(errRead):
+			return fmt.Errorf("export: read %s: %w", configPath, errRead)
+		}
+	}
+
+	bundle := importer.NewBundle(auths, rawConfig, !opts.NoSecrets)
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("export: encode bundle: %w", err)
+	}
+	if dir := filepath.Dir(out); dir != "." {
+		if err = os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("export: create dir: %w", err)
+		}
+	}
+	if err = os.WriteFile(out, data, 0o600); err != nil {
+		return fmt.Errorf("export: write %s: %w", out, err)
+	}
+
+	fmt.Printf("Exported %d account(s) to %s\n", len(bundle.Accounts), out)
+	if bundle.ContainsSecrets {
+		fmt.Println("The bundle contains credentials in plain text; store it somewhere private.")
+	}
+	return nil
+}
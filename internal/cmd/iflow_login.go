@@ -31,6 +31,7 @@ func DoIFlowLogin(cfg *config.Config, options *LoginOptions) {
 
 	authOpts := &login.LoginOptions{
 		NoBrowser: options.NoBrowser,
+		Headless:  options.Headless,
 		Metadata:  map[string]string{},
 		Prompt:    promptFn,
 	}
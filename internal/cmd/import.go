@@ -0,0 +1,177 @@
+// Package cmd contains CLI helpers. This file implements importing accounts
+// from other proxies' credential stores through the importer sources.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/auth/importer"
+	"github.com/nghyane/llm-mux/internal/auth/login"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/providers"
+	"github.com/nghyane/llm-mux/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// importValidateTimeout bounds the validation call made for each account.
+const importValidateTimeout = 30 * time.Second
+
+// ImportOptions controls DoImport.
+type ImportOptions struct {
+	// From names the importer source, e.g. "cliproxyapi".
+	From string
+	// Path overrides the source's default location.
+	Path string
+	// SkipValidation saves accounts without checking them upstream.
+	SkipValidation bool
+	// Overwrite replaces existing auth records with the same ID, and the
+	// config file when RestoreConfig is set.
+	Overwrite bool
+	// RestoreConfig writes the config stored in an llm-mux export bundle to
+	// ConfigPath before the accounts are imported.
+	RestoreConfig bool
+	ConfigPath    string
+}
+
+// DoImport reads accounts from a foreign credential store, validates each by
+// refreshing its tokens with the provider, saves them to the token store and
+// prints a summary. It returns an error only when nothing could be read.
+func DoImport(cfg *config.Config, opts ImportOptions) error {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if resolved, errResolve := util.ResolveAuthDir(cfg.AuthDir); errResolve == nil {
+		cfg.AuthDir = resolved
+	}
+	src, err := importer.Lookup(opts.From)
+	if err != nil {
+		return err
+	}
+	path := strings.TrimSpace(opts.Path)
+	if path == "" {
+		path = src.DefaultPath()
+	}
+	if path == "" {
+		return fmt.Errorf("import: --from %s needs a path", src.Name())
+	}
+	if opts.RestoreConfig {
+		if err = restoreBundleConfig(cfg, src, path, opts); err != nil {
+			return err
+		}
+	}
+	accounts, err := src.Read(path)
+	if err != nil {
+		return fmt.Errorf("import: read %s: %w", path, err)
+	}
+
+	store := login.GetTokenStore()
+	if setter, ok := store.(interface{ SetBaseDir(string) }); ok {
+		setter.SetBaseDir(cfg.AuthDir)
+	}
+	importOpts := importer.Options{Overwrite: opts.Overwrite}
+	if !opts.SkipValidation {
+		importOpts.Validate = importValidator(cfg)
+	}
+	summary, err := importer.Import(context.Background(), store, accounts, importOpts)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range summary.Outcomes {
+		name := o.ID
+		if name == "" {
+			name = o.Origin
+		}
+		line := fmt.Sprintf("  %-8s %s", o.Status, name)
+		if o.Detail != "" {
+			line += " (" + o.Detail + ")"
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("Imported %d, skipped %d, failed %d account(s) from %s\n",
+		summary.Count(importer.StatusImported),
+		summary.Count(importer.StatusSkipped),
+		summary.Count(importer.StatusFailed),
+		path)
+	return nil
+}
+
+// restoreBundleConfig writes the config section of an export bundle to
+// opts.ConfigPath and points cfg at the auth directory it names, so the
+// accounts that follow land where the restored config expects them.
+func restoreBundleConfig(cfg *config.Config, src importer.Source, path string, opts ImportOptions) error {
+	if src.Name() != "llm-mux" {
+		return fmt.Errorf("import: --restore-config needs --from llm-mux")
+	}
+	if opts.ConfigPath == "" {
+		return fmt.Errorf("import: --restore-config needs a config path")
+	}
+	bundle, err := importer.ReadBundle(path)
+	if err != nil {
+		return fmt.Errorf("import: read %s: %w", path, err)
+	}
+	if !bundle.ContainsSecrets {
+		return fmt.Errorf("import: %s was exported with --no-secrets; its config is redacted and cannot be restored", path)
+	}
+	if bundle.Config == nil {
+		return fmt.Errorf("import: %s contains no config", path)
+	}
+	if _, errStat := os.Stat(opts.ConfigPath); errStat == nil && !opts.Overwrite {
+		fmt.Printf("Config %s already exists, not restored (use --overwrite to replace it)\n", opts.ConfigPath)
+		return nil
+	}
+	data, err := yaml.Marshal(bundle.Config)
+	if err != nil {
+		return fmt.Errorf("import: encode config: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(opts.ConfigPath), 0o700); err != nil {
+		return fmt.Errorf("import: create config dir: %w", err)
+	}
+	if err = os.WriteFile(opts.ConfigPath, data, 0o600); err != nil {
+		return fmt.Errorf("import: write config: %w", err)
+	}
+	fmt.Printf("Config restored to %s\n", opts.ConfigPath)
+	if authDir, ok := bundle.Config["auth-dir"].(string); ok && authDir != "" {
+		if resolved, errResolve := util.ResolveAuthDir(authDir); errResolve == nil {
+			cfg.AuthDir = resolved
+		}
+	}
+	return nil
+}
+
+// importValidator checks an imported account by refreshing its OAuth tokens
+// through the provider's executor, the same call the service makes before
+// tokens expire. The refreshed tokens are what gets saved.
+func importValidator(cfg *config.Config) importer.ValidateFunc {
+	return func(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
+		var exec interface {
+			Refresh(context.Context, *provider.Auth) (*provider.Auth, error)
+		}
+		switch auth.Provider {
+		case "claude":
+			exec = providers.NewClaudeExecutor(cfg)
+		case "codex":
+			exec = providers.NewCodexExecutor(cfg)
+		case "qwen":
+			exec = providers.NewQwenExecutor(cfg)
+		case "iflow":
+			exec = providers.NewIFlowExecutor(cfg)
+		case "antigravity":
+			exec = providers.NewAntigravityExecutor(cfg)
+		default:
+			return nil, importer.ErrValidationUnsupported
+		}
+		if _, ok := auth.Metadata["refresh_token"].(string); !ok {
+			return nil, importer.ErrValidationUnsupported
+		}
+		ctx, cancel := context.WithTimeout(ctx, importValidateTimeout)
+		defer cancel()
+		return exec.Refresh(ctx, auth)
+	}
+}
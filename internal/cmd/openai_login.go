@@ -19,6 +19,10 @@ type LoginOptions struct {
 	// NoBrowser indicates whether to skip opening the browser automatically.
 	NoBrowser bool
 
+	// Headless prints the auth URL and reads the pasted redirect instead of
+	// waiting for the OAuth callback on a local port.
+	Headless bool
+
 	// Prompt allows the caller to provide interactive input when needed.
 	Prompt func(prompt string) (string, error)
 }
@@ -38,6 +42,7 @@ func DoCodexLogin(cfg *config.Config, options *LoginOptions) {
 
 	authOpts := &login.LoginOptions{
 		NoBrowser: options.NoBrowser,
+		Headless:  options.Headless,
 		Metadata:  map[string]string{},
 		Prompt:    options.Prompt,
 	}
@@ -23,10 +23,12 @@ import (
 //   - cfg: The application configuration
 //   - configPath: The path to the configuration file
 //   - localPassword: Optional password accepted for local management requests
-func StartService(cfg *config.Config, configPath string, localPassword string) {
+//   - watchConfig: Whether config file edits are applied without a restart
+func StartService(cfg *config.Config, configPath string, localPassword string, watchConfig bool) {
 	builder := service.NewBuilder().
 		WithConfig(cfg).
 		WithConfigPath(configPath).
+		WithConfigWatch(watchConfig).
 		WithLocalManagementPassword(localPassword)
 
 	ctxSignal, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Account selection strategies.
+const (
+	AccountSelectionDefault      = "default"
+	AccountSelectionRandom       = "random" // alias of default
+	AccountSelectionRoundRobin   = "round-robin"
+	AccountSelectionLRU          = "lru"
+	AccountSelectionLatencyAware = "latency-aware"
+)
+
+// DefaultAccountSelectionExploration is the share of latency-aware picks made
+// uniformly at random when no exploration fraction is configured.
+const DefaultAccountSelectionExploration = 0.1
+
+// AccountSelectionConfig controls how an account is chosen among the equally
+// suitable accounts of a provider.
+type AccountSelectionConfig struct {
+	// Strategy is "default" (random among the least loaded accounts, also
+	// "random"), "round-robin", "lru" (least recently picked) or
+	// "latency-aware" to favour accounts with a lower recent p95 latency.
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// Exploration is the fraction of latency-aware picks made uniformly at
+	// random so slower accounts keep fresh latency stats. Default: 0.1.
+	Exploration *float64 `yaml:"exploration,omitempty" json:"exploration,omitempty"`
+}
+
+// ExplorationFraction returns the configured exploration fraction or its default.
+func (c AccountSelectionConfig) ExplorationFraction() float64 {
+	if c.Exploration == nil {
+		return DefaultAccountSelectionExploration
+	}
+	return *c.Exploration
+}
+
+// Validate checks the strategy name and that exploration is between 0 and 1.
+func (c AccountSelectionConfig) Validate() error {
+	switch strings.ToLower(strings.TrimSpace(c.Strategy)) {
+	case "", AccountSelectionDefault, AccountSelectionRandom, AccountSelectionRoundRobin, AccountSelectionLRU, AccountSelectionLatencyAware:
+	default:
+		return fmt.Errorf("account-selection.strategy must be one of %q, %q, %q, %q or %q, got %q",
+			AccountSelectionDefault, AccountSelectionRandom, AccountSelectionRoundRobin, AccountSelectionLRU, AccountSelectionLatencyAware, c.Strategy)
+	}
+	if c.Exploration != nil && (*c.Exploration < 0 || *c.Exploration > 1) {
+		return fmt.Errorf("account-selection.exploration must be between 0 and 1, got %v", *c.Exploration)
+	}
+	return nil
+}
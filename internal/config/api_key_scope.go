@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APIKeyScope restricts which models and providers a client API key may use.
+// Keys without a scope keep unrestricted access.
+type APIKeyScope struct {
+	// APIKey is the client key the scope applies to.
+	APIKey string `yaml:"api-key" json:"api-key"`
+
+	// Models lists allowed model names or canonical model IDs. "*" allows every
+	// model and a trailing "*" matches a prefix, e.g. "gemini-2.5-flash*".
+	// Empty allows every model.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Providers lists the providers requests may be routed to, e.g. "gemini" or
+	// an OpenAI-compatible provider name. "*" or empty allows every provider.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// AllowsModel reports whether any of ids (the requested name, resolved model
+// and canonical ID) matches the model allow-list.
+func (s *APIKeyScope) AllowsModel(ids ...string) bool {
+	if len(s.Models) == 0 {
+		return true
+	}
+	for _, pattern := range s.Models {
+		for _, id := range ids {
+			if id != "" && matchScopePattern(pattern, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterProviders returns the providers the scope allows, in their original order.
+func (s *APIKeyScope) FilterProviders(providers []string) []string {
+	if len(s.Providers) == 0 {
+		return providers
+	}
+	allowed := make([]string, 0, len(providers))
+	for _, p := range providers {
+		for _, pattern := range s.Providers {
+			if matchScopePattern(pattern, p) {
+				allowed = append(allowed, p)
+				break
+			}
+		}
+	}
+	return allowed
+}
+
+func matchScopePattern(pattern, value string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(prefix))
+	}
+	return strings.EqualFold(pattern, value)
+}
+
+// APIKeyScopeFor returns the scope configured for key, or nil when the key is
+// unrestricted.
+func (c *SDKConfig) APIKeyScopeFor(key string) *APIKeyScope {
+	if c == nil || key == "" {
+		return nil
+	}
+	for i := range c.APIKeyScopes {
+		if c.APIKeyScopes[i].APIKey == key {
+			return &c.APIKeyScopes[i]
+		}
+	}
+	return nil
+}
+
+// ValidateAPIKeyScopes rejects scopes without a key and duplicate keys.
+func ValidateAPIKeyScopes(scopes []APIKeyScope) error {
+	seen := make(map[string]struct{}, len(scopes))
+	for i, s := range scopes {
+		if strings.TrimSpace(s.APIKey) == "" {
+			return fmt.Errorf("api-key-scopes[%d]: api-key is required", i)
+		}
+		if _, dup := seen[s.APIKey]; dup {
+			return fmt.Errorf("api-key-scopes[%d]: duplicate api-key", i)
+		}
+		seen[s.APIKey] = struct{}{}
+	}
+	return nil
+}
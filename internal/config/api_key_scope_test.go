@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAPIKeyScope_AllowsModel(t *testing.T) {
+	scope := &APIKeyScope{Models: []string{"gemini-2.5-flash*", "claude-haiku-4-5"}}
+	cases := []struct {
+		ids  []string
+		want bool
+	}{
+		{[]string{"gemini-2.5-flash-lite"}, true},
+		{[]string{"GEMINI-2.5-FLASH"}, true},
+		{[]string{"claude-haiku-4-5"}, true},
+		{[]string{"alias", "", "claude-haiku-4-5"}, true},
+		{[]string{"gemini-2.5-pro"}, false},
+		{[]string{""}, false},
+	}
+	for _, tc := range cases {
+		if got := scope.AllowsModel(tc.ids...); got != tc.want {
+			t.Errorf("AllowsModel(%v) = %v, want %v", tc.ids, got, tc.want)
+		}
+	}
+	if !(&APIKeyScope{Models: []string{"*"}}).AllowsModel("anything") {
+		t.Error("Expected the wildcard to allow every model")
+	}
+	if !(&APIKeyScope{}).AllowsModel("anything") {
+		t.Error("Expected an empty model list to allow every model")
+	}
+}
+
+func TestAPIKeyScope_FilterProviders(t *testing.T) {
+	scope := &APIKeyScope{Providers: []string{"gemini*", "openrouter"}}
+	got := scope.FilterProviders([]string{"claude", "gemini-cli", "openrouter", "gemini"})
+	if want := []string{"gemini-cli", "openrouter", "gemini"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterProviders = %v, want %v", got, want)
+	}
+}
+
+func TestValidateAPIKeyScopes(t *testing.T) {
+	if err := ValidateAPIKeyScopes([]APIKeyScope{{APIKey: "a"}, {APIKey: "b"}}); err != nil {
+		t.Errorf("Expected valid scopes, got %v", err)
+	}
+	if err := ValidateAPIKeyScopes([]APIKeyScope{{Models: []string{"*"}}}); err == nil {
+		t.Error("Expected an error for a scope without api-key")
+	}
+	if err := ValidateAPIKeyScopes([]APIKeyScope{{APIKey: "a"}, {APIKey: "a"}}); err == nil {
+		t.Error("Expected an error for duplicate keys")
+	}
+}
+
+func TestLoadConfigOptional_InvalidAPIKeyScopes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "api-keys: [\"k\"]\napi-key-scopes:\n  - api-key: \"k\"\n  - api-key: \"k\"\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := LoadConfigOptional(path, true); err == nil {
+		t.Errorf("Expected invalid scopes to fail an optional load, got %+v", cfg.APIKeyScopes)
+	}
+}
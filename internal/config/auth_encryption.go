@@ -0,0 +1,9 @@
+package config
+
+// AuthEncryptionConfig enables encryption of auth files at rest.
+type AuthEncryptionConfig struct {
+	// Passphrase derives the key auth files are encrypted with. Empty leaves new
+	// files in plaintext. The LLM_MUX_AUTH_PASSPHRASE environment variable
+	// takes precedence and keeps the passphrase out of the config file.
+	Passphrase string `yaml:"passphrase,omitempty" json:"-"`
+}
@@ -0,0 +1,61 @@
+package config
+
+import "fmt"
+
+// Cassette modes.
+const (
+	CassetteModeRecord = "record"
+	CassetteModeReplay = "replay"
+)
+
+// Actions for a replayed request that has no cassette.
+const (
+	CassetteMissLive   = "live"
+	CassetteMissRecord = "record"
+	CassetteMissError  = "error"
+)
+
+// CassetteConfig records upstream HTTP exchanges to cassette files and serves
+// them back without contacting providers, for integration tests. Off unless
+// Mode is set.
+type CassetteConfig struct {
+	// Mode is "record" to write every upstream exchange, "replay" to serve
+	// recorded ones, or empty to disable cassettes.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Dir holds the cassette files. Defaults to "cassettes" under the credentials directory.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// OnMiss is what replay does without a cassette: "live" calls the provider,
+	// "record" calls it and saves the exchange, "error" fails the request.
+	// Default: live.
+	OnMiss string `yaml:"on-miss,omitempty" json:"on-miss,omitempty"`
+}
+
+// Enabled reports whether cassettes are recorded or replayed.
+func (c CassetteConfig) Enabled() bool {
+	return c.Mode != ""
+}
+
+// MissAction returns the replay miss action, applying the default.
+func (c CassetteConfig) MissAction() string {
+	if c.OnMiss == "" {
+		return CassetteMissLive
+	}
+	return c.OnMiss
+}
+
+// Validate rejects unknown modes and miss actions.
+func (c CassetteConfig) Validate() error {
+	switch c.Mode {
+	case "", CassetteModeRecord, CassetteModeReplay:
+	default:
+		return fmt.Errorf("cassette.mode must be %q or %q", CassetteModeRecord, CassetteModeReplay)
+	}
+	switch c.OnMiss {
+	case "", CassetteMissLive, CassetteMissRecord, CassetteMissError:
+	default:
+		return fmt.Errorf("cassette.on-miss must be %q, %q or %q", CassetteMissLive, CassetteMissRecord, CassetteMissError)
+	}
+	return nil
+}
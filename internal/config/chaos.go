@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Fault kinds injected by the chaos mode.
+const (
+	ChaosFaultRateLimit     = "rate-limit"
+	ChaosFaultServerError   = "server-error"
+	ChaosFaultDisconnect    = "disconnect"
+	ChaosFaultSlowFirstByte = "slow-first-byte"
+)
+
+// ChaosFaults lists every fault kind, in the order used when none are configured.
+var ChaosFaults = []string{ChaosFaultRateLimit, ChaosFaultServerError, ChaosFaultDisconnect, ChaosFaultSlowFirstByte}
+
+// Chaos defaults applied when the mode is enabled.
+const (
+	DefaultChaosRetryAfter     = 30
+	DefaultChaosFirstByteDelay = 5
+)
+
+// ChaosConfig injects simulated upstream failures into chat requests so
+// clients can be tested against them. Nothing is injected unless Enabled is
+// set; it is meant for test deployments only.
+type ChaosConfig struct {
+	// Enabled turns the mode on. Rate and the request header have no effect without it.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Rate is the fraction of chat requests, between 0 and 1, that get a fault.
+	Rate float64 `yaml:"rate,omitempty" json:"rate,omitempty"`
+
+	// Faults limits the kinds picked at random. Default: all of them.
+	Faults []string `yaml:"faults,omitempty" json:"faults,omitempty"`
+
+	// RetryAfter is the Retry-After value in seconds sent with injected 429s. Default: 30.
+	RetryAfter int `yaml:"retry-after,omitempty" json:"retry-after,omitempty"`
+
+	// FirstByteDelay is how many seconds slow-first-byte holds a request. Default: 5.
+	FirstByteDelay int `yaml:"first-byte-delay,omitempty" json:"first-byte-delay,omitempty"`
+}
+
+// FaultKinds returns the configured fault kinds, applying the default.
+func (c ChaosConfig) FaultKinds() []string {
+	if len(c.Faults) == 0 {
+		return ChaosFaults
+	}
+	return c.Faults
+}
+
+// RetryAfterSeconds returns the Retry-After value, applying the default.
+func (c ChaosConfig) RetryAfterSeconds() int {
+	if c.RetryAfter <= 0 {
+		return DefaultChaosRetryAfter
+	}
+	return c.RetryAfter
+}
+
+// FirstByteDelayDuration returns the slow-first-byte delay, applying the default.
+func (c ChaosConfig) FirstByteDelayDuration() time.Duration {
+	if c.FirstByteDelay <= 0 {
+		return DefaultChaosFirstByteDelay * time.Second
+	}
+	return time.Duration(c.FirstByteDelay) * time.Second
+}
+
+// Validate rejects rates outside [0, 1], negative durations and unknown faults.
+func (c ChaosConfig) Validate() error {
+	if c.Rate < 0 || c.Rate > 1 {
+		return fmt.Errorf("chaos.rate must be between 0 and 1")
+	}
+	if c.RetryAfter < 0 {
+		return fmt.Errorf("chaos.retry-after must not be negative")
+	}
+	if c.FirstByteDelay < 0 {
+		return fmt.Errorf("chaos.first-byte-delay must not be negative")
+	}
+	for _, fault := range c.Faults {
+		if !slices.Contains(ChaosFaults, fault) {
+			return fmt.Errorf("chaos.faults: unknown fault %q", fault)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Concurrency policies applied when a model's in-flight limit is reached.
+const (
+	ConcurrencyPolicyQueue  = "queue"
+	ConcurrencyPolicyReject = "reject"
+)
+
+// ModelConcurrencyConfig caps in-flight requests for specific models.
+type ModelConcurrencyConfig struct {
+	// Policy is "queue" (default) to wait for a free slot, or "reject" to answer 429.
+	Policy string `yaml:"policy,omitempty" json:"policy,omitempty"`
+
+	// Limits lists the capped models.
+	Limits []ModelConcurrencyLimit `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+// ModelConcurrencyLimit caps concurrent requests for one model.
+type ModelConcurrencyLimit struct {
+	// Model is the client-facing or upstream model ID.
+	Model string `yaml:"model" json:"model"`
+
+	// MaxConcurrentRequests is the number of requests allowed in flight at once.
+	MaxConcurrentRequests int `yaml:"max-concurrent-requests" json:"max-concurrent-requests"`
+
+	// PerAccount applies the limit to each account separately instead of across all of them.
+	PerAccount bool `yaml:"per-account,omitempty" json:"per-account,omitempty"`
+}
+
+// Reject reports whether excess requests are rejected rather than queued.
+func (c ModelConcurrencyConfig) Reject() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Policy), ConcurrencyPolicyReject)
+}
+
+// Validate checks the policy and that every limit names a model with a positive cap.
+func (c ModelConcurrencyConfig) Validate() error {
+	switch strings.ToLower(strings.TrimSpace(c.Policy)) {
+	case "", ConcurrencyPolicyQueue, ConcurrencyPolicyReject:
+	default:
+		return fmt.Errorf("model-concurrency.policy must be %q or %q, got %q", ConcurrencyPolicyQueue, ConcurrencyPolicyReject, c.Policy)
+	}
+	seen := make(map[string]struct{}, len(c.Limits))
+	for i, limit := range c.Limits {
+		model := strings.ToLower(strings.TrimSpace(limit.Model))
+		if model == "" {
+			return fmt.Errorf("model-concurrency.limits[%d]: model is required", i)
+		}
+		if limit.MaxConcurrentRequests <= 0 {
+			return fmt.Errorf("model-concurrency.limits[%d]: max-concurrent-requests must be positive", i)
+		}
+		if _, dup := seen[model]; dup {
+			return fmt.Errorf("model-concurrency.limits[%d]: duplicate model %q", i, limit.Model)
+		}
+		seen[model] = struct{}{}
+	}
+	return nil
+}
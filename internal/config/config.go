@@ -11,6 +11,7 @@ import (
 	"os"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"gopkg.in/yaml.v3"
@@ -31,9 +32,61 @@ type SDKConfig struct {
 	// Access holds request authentication provider configuration.
 	Access AccessConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
 
+	// APIKeyScopes restricts individual API keys to a subset of models and providers.
+	APIKeyScopes []APIKeyScope `yaml:"api-key-scopes,omitempty" json:"api-key-scopes,omitempty"`
+
 	// ShowProviderPrefixes enables visual provider prefixes in model IDs (e.g., "[Gemini CLI] gemini-2.5-pro").
 	// This is purely cosmetic and does not affect actual model routing to providers.
 	ShowProviderPrefixes bool `yaml:"show-provider-prefixes" json:"show-provider-prefixes"`
+
+	// DebugHeaders attaches X-LLM-Mux-* routing headers (resolved model, provider, auth ID,
+	// applied transforms) to every response. Clients may also opt in per request by sending
+	// the X-LLM-Mux-Debug header.
+	DebugHeaders bool `yaml:"debug-headers" json:"debug-headers"`
+
+	// StreamErrorRecovery ends OpenAI-compatible streams that fail upstream after
+	// emitting output with a final chunk carrying finish_reason "error" and the
+	// error message, followed by [DONE]. When false the stream ends with an error event.
+	StreamErrorRecovery bool `yaml:"stream-error-recovery" json:"stream-error-recovery"`
+
+	// ContentFilterResults maps Gemini safety ratings and prompt feedback to Azure-style
+	// content_filter_results and prompt_filter_results in OpenAI-format responses.
+	// Filter results reported by OpenAI-compatible providers are always forwarded.
+	ContentFilterResults bool `yaml:"content-filter-results" json:"content-filter-results"`
+
+	// UpstreamResponseIDs reuses the upstream response ID, prefixed with
+	// "upstream-", and creation time as the id and created fields of translated
+	// Gemini responses. When false both are generated by llm-mux.
+	UpstreamResponseIDs bool `yaml:"upstream-response-ids" json:"upstream-response-ids"`
+
+	// EstimateMissingUsage counts tokens locally when a provider reports no
+	// usage, so every response carries usage marked "estimated": true. When
+	// false such responses carry no usage, as the provider sent them.
+	EstimateMissingUsage bool `yaml:"estimate-missing-usage" json:"estimate-missing-usage"`
+
+	// NonStreamBuffering is "stream" to assemble non-streaming responses from an
+	// upstream stream instead of buffering the whole upstream body.
+	NonStreamBuffering NonStreamBuffering `yaml:"non-stream-buffering,omitempty" json:"non-stream-buffering,omitempty"`
+
+	// ResponseCache serves repeated deterministic requests from memory.
+	ResponseCache ResponseCacheConfig `yaml:"response-cache,omitempty" json:"response-cache,omitempty"`
+
+	// CoalesceRequests answers concurrent identical deterministic non-streaming
+	// requests with a single upstream call.
+	CoalesceRequests bool `yaml:"coalesce-requests" json:"coalesce-requests"`
+
+	// Idempotency answers non-streaming requests that repeat an
+	// Idempotency-Key with the first response.
+	Idempotency IdempotencyConfig `yaml:"idempotency,omitempty" json:"idempotency,omitempty"`
+
+	// ToolLoopGuard ends conversations stuck in consecutive tool-call rounds.
+	ToolLoopGuard ToolLoopGuardConfig `yaml:"tool-loop-guard,omitempty" json:"tool-loop-guard,omitempty"`
+
+	// ThoughtSignatures restores Gemini thought signatures dropped by clients.
+	ThoughtSignatures ThoughtSignaturesConfig `yaml:"thought-signatures,omitempty" json:"thought-signatures,omitempty"`
+
+	// Chaos injects simulated upstream failures for client resilience tests.
+	Chaos ChaosConfig `yaml:"chaos,omitempty" json:"chaos,omitempty"`
 }
 
 // AccessConfig groups request authentication providers.
@@ -108,14 +161,66 @@ type Config struct {
 	Debug            bool             `yaml:"debug" json:"debug"`
 	LoggingToFile    bool             `yaml:"logging-to-file" json:"logging-to-file"`
 
+	// AuthEncryption encrypts the auth files under AuthDir at rest.
+	AuthEncryption AuthEncryptionConfig `yaml:"auth-encryption,omitempty" json:"-"`
+
 	Usage            UsageConfig   `yaml:"usage" json:"usage"`
 	DisableCooling   bool          `yaml:"disable-cooling" json:"disable-cooling"`
 	RequestRetry     int           `yaml:"request-retry" json:"request-retry"`
 	MaxRetryInterval int           `yaml:"max-retry-interval" json:"max-retry-interval"`
+	RetryJitter      int           `yaml:"retry-jitter" json:"retry-jitter"`
 	StreamTimeout    int           `yaml:"stream-timeout" json:"stream-timeout"`
+	RefreshLead      int           `yaml:"refresh-lead" json:"refresh-lead"`
 	QuotaWindow      int           `yaml:"quota-window" json:"quota-window"`
 	QuotaExceeded    QuotaExceeded `yaml:"quota-exceeded" json:"quota-exceeded"`
 
+	// RequestTimeout caps the total duration of an upstream call, globally and
+	// per provider, independently of the stream idle timeout.
+	RequestTimeout RequestTimeoutConfig `yaml:"request-timeout,omitempty" json:"request-timeout,omitempty"`
+
+	// ShutdownGracePeriod is how many seconds shutdown waits for in-flight
+	// requests, including streams, before closing their connections. Default: 30.
+	ShutdownGracePeriod int `yaml:"shutdown-grace-period,omitempty" json:"shutdown-grace-period,omitempty"`
+
+	// RequestQueue holds requests while every credential for a model is cooling down.
+	RequestQueue RequestQueueConfig `yaml:"request-queue" json:"request-queue"`
+
+	// HealthProbe periodically checks untested and, optionally, suspended accounts.
+	HealthProbe HealthProbeConfig `yaml:"health-probe,omitempty" json:"health-probe,omitempty"`
+
+	// StateWebhook notifies an HTTP endpoint when accounts become unhealthy or
+	// a provider runs out of usable accounts.
+	StateWebhook StateWebhookConfig `yaml:"state-webhook,omitempty" json:"state-webhook,omitempty"`
+
+	// ModelConcurrency caps in-flight requests per model.
+	ModelConcurrency ModelConcurrencyConfig `yaml:"model-concurrency,omitempty" json:"model-concurrency,omitempty"`
+
+	// AccountSelection picks the strategy for choosing among equally suitable accounts.
+	AccountSelection AccountSelectionConfig `yaml:"account-selection,omitempty" json:"account-selection,omitempty"`
+
+	// TranslationCache reuses translated upstream payloads for identical requests.
+	TranslationCache TranslationCacheConfig `yaml:"translation-cache,omitempty" json:"translation-cache,omitempty"`
+
+	// ImageFetch inlines remote image URLs for providers that need base64 data.
+	ImageFetch ImageFetchConfig `yaml:"image-fetch,omitempty" json:"image-fetch,omitempty"`
+
+	// ModelInfo overrides built-in model info such as limits, thinking ranges
+	// and pricing, and describes models the built-in registry does not know.
+	ModelInfo ModelInfoEntries `yaml:"model-info,omitempty" json:"model-info,omitempty"`
+
+	// Tracing exports OpenTelemetry spans for each request.
+	Tracing TracingConfig `yaml:"tracing,omitempty" json:"tracing,omitempty"`
+
+	// Metrics selects the backend for internal metrics.
+	Metrics MetricsConfig `yaml:"metrics,omitempty" json:"metrics,omitempty"`
+
+	// RateLimit throttles inbound requests on the public API.
+	RateLimit RateLimitConfig `yaml:"rate-limit,omitempty" json:"rate-limit,omitempty"`
+
+	// OAuthCallback sets the callback bind address and advertised redirect URIs
+	// for logins started through the management API.
+	OAuthCallback OAuthCallbackConfig `yaml:"oauth-callback,omitempty" json:"oauth-callback,omitempty"`
+
 	WebsocketAuth bool `yaml:"ws-auth" json:"ws-auth"`
 	DisableAuth   bool `yaml:"disable-auth" json:"disable-auth"`
 
@@ -141,6 +246,25 @@ type Config struct {
 	// MaxResponseSize is the maximum response body size to read into memory in bytes.
 	// Set to 0 to use the default (100MB). Applies to non-streaming responses only.
 	MaxResponseSize int64 `yaml:"max-response-size" json:"max-response-size"`
+
+	// ThinkingCapture keeps the last N thinking-model traces (request, raw SSE, parsed events)
+	// in memory for retrieval via the management API. Set to 0 (default) to disable capture.
+	ThinkingCapture int `yaml:"thinking-capture" json:"thinking-capture"`
+
+	// Transport tunes the connection pool and timeouts of the shared upstream HTTP transport.
+	Transport TransportConfig `yaml:"transport,omitempty" json:"transport,omitempty"`
+
+	// UpstreamHeaders adds static headers, per provider, to every upstream request.
+	UpstreamHeaders UpstreamHeaders `yaml:"upstream-headers,omitempty" json:"upstream-headers,omitempty"`
+
+	// Batch configures the /v1/batches background job runner.
+	Batch BatchConfig `yaml:"batch,omitempty" json:"batch,omitempty"`
+
+	// Files configures the /v1/files upload registry.
+	Files FilesConfig `yaml:"files,omitempty" json:"files,omitempty"`
+
+	// Cassette records upstream exchanges and replays them for tests.
+	Cassette CassetteConfig `yaml:"cassette,omitempty" json:"cassette,omitempty"`
 }
 
 // TLSConfig holds HTTPS server settings.
@@ -150,6 +274,20 @@ type TLSConfig struct {
 	Key    string `yaml:"key" json:"key"`
 }
 
+// BatchConfig holds settings for batch jobs submitted via /v1/batches.
+type BatchConfig struct {
+	// Dir is where batch state is stored. Defaults to "batches" under the credentials directory.
+	Dir string `yaml:"dir" json:"dir"`
+	// Concurrency bounds in-flight batch requests across all jobs. Default: 4.
+	Concurrency int `yaml:"concurrency" json:"concurrency"`
+}
+
+// FilesConfig holds settings for files uploaded via /v1/files.
+type FilesConfig struct {
+	// Dir is where file records are stored. Defaults to "files" under the credentials directory.
+	Dir string `yaml:"dir" json:"dir"`
+}
+
 // RemoteManagement holds management API configuration under 'remote-management'.
 type RemoteManagement struct {
 	AllowRemote bool `yaml:"allow-remote"`
@@ -161,6 +299,31 @@ type QuotaExceeded struct {
 	SwitchPreviewModel bool `yaml:"switch-preview-model" json:"switch-preview-model"`
 }
 
+// RequestQueueConfig defines the wait queue used when all credentials for a model
+// are quota-suspended. Queued requests are released in arrival order per model.
+type RequestQueueConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// MaxSize caps queued requests per model. Default: 100.
+	MaxSize int `yaml:"max-size" json:"max-size"`
+	// MaxWait is the longest a request waits in seconds before it is answered with 429. Default: 60.
+	MaxWait int `yaml:"max-wait" json:"max-wait"`
+}
+
+// Limits returns the effective queue size and wait, both zero when the queue is disabled.
+func (c RequestQueueConfig) Limits() (int, time.Duration) {
+	if !c.Enable {
+		return 0, 0
+	}
+	size, wait := c.MaxSize, c.MaxWait
+	if size <= 0 {
+		size = 100
+	}
+	if wait <= 0 {
+		wait = 60
+	}
+	return size, time.Duration(wait) * time.Second
+}
+
 // UsageConfig defines usage tracking and persistence settings.
 type UsageConfig struct {
 	// DSN specifies the database connection using URI scheme:
@@ -228,7 +391,9 @@ type RoutingConfig struct {
 	ProviderPriority map[string]int `yaml:"provider-priority,omitempty" json:"provider-priority,omitempty"`
 
 	// Aliases maps user-facing model names to canonical internal names.
-	// Handles naming inconsistencies across providers (e.g., "." vs "-").
+	// Handles naming inconsistencies across providers (e.g., "." vs "-") and
+	// short names such as "fast" -> "gemini-2.5-flash". An alias may point at
+	// another alias; cycles are rejected when the config is loaded.
 	// Example: "claude-sonnet-4.5" -> "claude-sonnet-4-5"
 	Aliases map[string]string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
 
@@ -237,9 +402,21 @@ type RoutingConfig struct {
 	// Example: "claude-opus-4-5" -> ["claude-sonnet-4-5", "gpt-4o"]
 	Fallbacks map[string][]string `yaml:"fallbacks,omitempty" json:"fallbacks,omitempty"`
 
-	hasAliases   bool
-	hasFallbacks bool
-	hasPriority  bool
+	// DefaultModel is used when a request's model field is empty or only
+	// whitespace. It goes through alias and family resolution like any other
+	// model name. When unset such requests fail with 400.
+	DefaultModel string `yaml:"default-model,omitempty" json:"default-model,omitempty"`
+
+	// CapabilityFallback substitutes the closest available model that meets
+	// the request's needs when the requested model has no available provider
+	// and its fallback chain is exhausted. Clients can turn it on or off per
+	// request with the X-LLM-Mux-Capability-Fallback header.
+	CapabilityFallback bool `yaml:"capability-fallback,omitempty" json:"capability-fallback,omitempty"`
+
+	hasAliases      bool
+	hasFallbacks    bool
+	hasPriority     bool
+	resolvedAliases map[string]string
 }
 
 func (r *RoutingConfig) Init() {
@@ -249,20 +426,32 @@ func (r *RoutingConfig) Init() {
 	r.hasAliases = len(r.Aliases) > 0
 	r.hasFallbacks = len(r.Fallbacks) > 0
 	r.hasPriority = len(r.ProviderPriority) > 0
+	r.resolvedAliases = resolveAliasChains(r.Aliases)
 }
 
-// ResolveModelAlias returns the canonical model name for the given input.
-// If no alias is defined, returns the original model name.
+// ResolveModelAlias returns the model an alias finally points to, following
+// chains of aliases. If no alias is defined, returns the original model name.
 func (r *RoutingConfig) ResolveModelAlias(model string) string {
 	if r == nil || !r.hasAliases {
 		return model
 	}
-	if alias, ok := r.Aliases[model]; ok {
-		return alias
+	if target, ok := r.resolvedAliases[model]; ok {
+		return target
+	}
+	if target, ok := r.Aliases[model]; ok {
+		return target
 	}
 	return model
 }
 
+// ModelAliases returns each alias with the model it finally resolves to.
+func (r *RoutingConfig) ModelAliases() map[string]string {
+	if r == nil || !r.hasAliases {
+		return nil
+	}
+	return r.resolvedAliases
+}
+
 // GetFallbackChain returns the fallback models for the given model.
 // Returns nil if no fallbacks are defined.
 func (r *RoutingConfig) GetFallbackChain(model string) []string {
@@ -277,6 +466,17 @@ func (r *RoutingConfig) HasProviderPriority() bool {
 	return r != nil && r.hasPriority
 }
 
+// DefaultShutdownGracePeriod applies when shutdown-grace-period is unset.
+const DefaultShutdownGracePeriod = 30 * time.Second
+
+// ShutdownGrace returns the configured shutdown grace period.
+func (c *Config) ShutdownGrace() time.Duration {
+	if c == nil || c.ShutdownGracePeriod <= 0 {
+		return DefaultShutdownGracePeriod
+	}
+	return time.Duration(c.ShutdownGracePeriod) * time.Second
+}
+
 // NewDefaultConfig creates a new Config with sensible defaults.
 // This allows the server to run without a config file using OAuth credentials only.
 func NewDefaultConfig() *Config {
@@ -369,9 +569,148 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 
 	cfg.Providers = SanitizeProviders(cfg.Providers)
 
+	if err = cfg.Transport.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.Transport = TransportConfig{}
+	}
+
+	cfg.UpstreamHeaders = cfg.UpstreamHeaders.Normalize()
+	if err = cfg.UpstreamHeaders.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.UpstreamHeaders = nil
+	}
+
+	if err = cfg.RequestTimeout.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.RequestTimeout = RequestTimeoutConfig{}
+	}
+
+	if err = cfg.ModelConcurrency.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.ModelConcurrency = ModelConcurrencyConfig{}
+	}
+
+	if err = cfg.AccountSelection.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.AccountSelection = AccountSelectionConfig{}
+	}
+
+	if err = cfg.NonStreamBuffering.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.NonStreamBuffering = ""
+	}
+
+	if err = cfg.TranslationCache.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.TranslationCache = TranslationCacheConfig{}
+	}
+
+	if err = cfg.ImageFetch.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.ImageFetch = ImageFetchConfig{}
+	}
+
+	if err = cfg.ModelInfo.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.ModelInfo = nil
+	}
+
+	if err = cfg.ResponseCache.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.ResponseCache = ResponseCacheConfig{}
+	}
+
+	if err = cfg.Idempotency.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.Idempotency = IdempotencyConfig{}
+	}
+
+	if err = cfg.ToolLoopGuard.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.ToolLoopGuard = ToolLoopGuardConfig{}
+	}
+
+	if err = cfg.ThoughtSignatures.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.ThoughtSignatures = ThoughtSignaturesConfig{}
+	}
+
+	if err = cfg.Cassette.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.Cassette = CassetteConfig{}
+	}
+
+	if err = cfg.Chaos.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.Chaos = ChaosConfig{}
+	}
+
+	if err = cfg.Tracing.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.Tracing = TracingConfig{}
+	}
+
+	// Dropping invalid scopes would leave the affected keys unrestricted, so
+	// they are rejected even when the config is optional.
+	if err = ValidateAPIKeyScopes(cfg.APIKeyScopes); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if err = cfg.RateLimit.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.RateLimit = RateLimitConfig{}
+	}
+
+	if err = cfg.OAuthCallback.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.OAuthCallback = OAuthCallbackConfig{}
+	}
+
 	// Normalize OAuth provider model exclusion map.
 	cfg.OAuthExcludedModels = NormalizeOAuthExcludedModels(cfg.OAuthExcludedModels)
 
+	if err = cfg.Routing.Validate(); err != nil {
+		if !optional {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.Routing.Aliases = nil
+	}
 	cfg.Routing.Init()
 
 	// Return the populated configuration struct.
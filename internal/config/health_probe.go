@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+// DefaultHealthProbeInterval is the probe interval in seconds when none is set.
+const DefaultHealthProbeInterval = 300
+
+// HealthProbeConfig controls the background prober that checks accounts
+// independently of traffic. Probes send a one-token request and never count
+// toward usage statistics.
+type HealthProbeConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// Interval is the time between probe rounds in seconds. Default: 300.
+	Interval int `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// IncludeSuspended also probes models suspended after auth, payment,
+	// not-found or server errors so recovered accounts return to rotation
+	// early. Accounts in a quota cooldown are never probed.
+	IncludeSuspended bool `yaml:"include-suspended,omitempty" json:"include-suspended,omitempty"`
+}
+
+// ProbeInterval returns the effective interval, zero when probing is disabled.
+func (c HealthProbeConfig) ProbeInterval() time.Duration {
+	if !c.Enable {
+		return 0
+	}
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultHealthProbeInterval
+	}
+	return time.Duration(interval) * time.Second
+}
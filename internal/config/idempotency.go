@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Idempotency defaults.
+const (
+	DefaultIdempotencyTTL        = 600
+	DefaultIdempotencyMaxEntries = 1024
+)
+
+// IdempotencyConfig controls the Idempotency-Key header of non-streaming
+// requests: a request repeating the key of an earlier one gets that request's
+// response instead of a new generation. On by default; it only applies to
+// requests that send the header.
+type IdempotencyConfig struct {
+	// Disable ignores the Idempotency-Key header.
+	Disable bool `yaml:"disable,omitempty" json:"disable,omitempty"`
+
+	// TTL is how many seconds a response is kept for its key. Default: 600.
+	TTL int `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// MaxEntries bounds the kept responses; the least recently used is
+	// evicted first. Default: 1024.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// TTLDuration returns how long a response is kept, or zero when disabled.
+func (c IdempotencyConfig) TTLDuration() time.Duration {
+	if c.Disable {
+		return 0
+	}
+	if c.TTL <= 0 {
+		return DefaultIdempotencyTTL * time.Second
+	}
+	return time.Duration(c.TTL) * time.Second
+}
+
+// Entries returns the entry limit, applying the default.
+func (c IdempotencyConfig) Entries() int {
+	if c.MaxEntries <= 0 {
+		return DefaultIdempotencyMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// Validate rejects negative values.
+func (c IdempotencyConfig) Validate() error {
+	if c.TTL < 0 {
+		return fmt.Errorf("idempotency.ttl must not be negative")
+	}
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("idempotency.max-entries must not be negative")
+	}
+	return nil
+}
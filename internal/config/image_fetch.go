@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Image fetch defaults applied when fetching is enabled.
+const (
+	DefaultImageFetchMaxBytes = 10 << 20
+	DefaultImageFetchTimeout  = 10
+	DefaultImageFetchCacheTTL = 300
+	DefaultImageFetchCacheMax = 64 << 20
+)
+
+// DefaultImageFetchTypes are the image content types fetched when
+// allowed-types is empty.
+var DefaultImageFetchTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// ImageFetchConfig downloads remote image URLs and inlines them as base64 for
+// providers that only accept inline image data. Off by default.
+type ImageFetchConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// MaxBytes caps the size of one image. Default: 10 MiB.
+	MaxBytes int64 `yaml:"max-bytes,omitempty" json:"max-bytes,omitempty"`
+
+	// Timeout is how many seconds one download may take. Default: 10.
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// AllowedTypes lists the accepted content types. Default: png, jpeg, gif
+	// and webp.
+	AllowedTypes []string `yaml:"allowed-types,omitempty" json:"allowed-types,omitempty"`
+
+	// CacheTTL is how many seconds a downloaded image is reused for the same
+	// URL. Zero uses the default of 300; a negative value disables caching.
+	CacheTTL int `yaml:"cache-ttl,omitempty" json:"cache-ttl,omitempty"`
+
+	// CacheMaxBytes caps the base64 data held by the cache; the least
+	// recently used images are evicted first. Default: 64 MiB.
+	CacheMaxBytes int64 `yaml:"cache-max-bytes,omitempty" json:"cache-max-bytes,omitempty"`
+}
+
+// SizeLimit returns the per-image byte cap, applying the default.
+func (c ImageFetchConfig) SizeLimit() int64 {
+	if c.MaxBytes <= 0 {
+		return DefaultImageFetchMaxBytes
+	}
+	return c.MaxBytes
+}
+
+// TimeoutDuration returns the per-image download timeout, applying the default.
+func (c ImageFetchConfig) TimeoutDuration() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultImageFetchTimeout * time.Second
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// CacheTTLDuration returns how long a downloaded image is cached, or zero when
+// caching is disabled.
+func (c ImageFetchConfig) CacheTTLDuration() time.Duration {
+	switch {
+	case c.CacheTTL < 0:
+		return 0
+	case c.CacheTTL == 0:
+		return DefaultImageFetchCacheTTL * time.Second
+	}
+	return time.Duration(c.CacheTTL) * time.Second
+}
+
+// CacheSizeLimit returns the cache's byte cap, applying the default.
+func (c ImageFetchConfig) CacheSizeLimit() int64 {
+	if c.CacheMaxBytes <= 0 {
+		return DefaultImageFetchCacheMax
+	}
+	return c.CacheMaxBytes
+}
+
+// Types returns the accepted content types, lowercased, applying the default.
+func (c ImageFetchConfig) Types() []string {
+	if len(c.AllowedTypes) == 0 {
+		return DefaultImageFetchTypes
+	}
+	types := make([]string, 0, len(c.AllowedTypes))
+	for _, t := range c.AllowedTypes {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// Validate rejects negative limits.
+func (c ImageFetchConfig) Validate() error {
+	if c.MaxBytes < 0 {
+		return fmt.Errorf("image-fetch.max-bytes must not be negative")
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("image-fetch.timeout must not be negative")
+	}
+	if c.CacheMaxBytes < 0 {
+		return fmt.Errorf("image-fetch.cache-max-bytes must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package config
+
+// MetricsConfig selects the backend internal metrics are emitted through.
+type MetricsConfig struct {
+	// Prometheus serves metrics in the Prometheus text format at
+	// /v1/management/metrics. When false metrics are discarded unless an
+	// embedding application installs its own backend.
+	Prometheus bool `yaml:"prometheus" json:"prometheus"`
+}
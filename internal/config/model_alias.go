@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate rejects empty and cyclic model aliases.
+func (r *RoutingConfig) Validate() error {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.Aliases))
+	for alias, target := range r.Aliases {
+		if strings.TrimSpace(alias) == "" || strings.TrimSpace(target) == "" {
+			return fmt.Errorf("routing.aliases: alias %q -> %q must name both models", alias, target)
+		}
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	for _, alias := range names {
+		chain := []string{alias}
+		seen := map[string]bool{alias: true}
+		for current := alias; ; {
+			next, ok := r.Aliases[current]
+			if !ok {
+				break
+			}
+			chain = append(chain, next)
+			if seen[next] {
+				return fmt.Errorf("routing.aliases: cyclic alias %s", strings.Join(chain, " -> "))
+			}
+			seen[next] = true
+			current = next
+		}
+	}
+	return nil
+}
+
+// resolveAliasChains maps every alias to the model at the end of its chain.
+// Aliases caught in a cycle are left out.
+func resolveAliasChains(aliases map[string]string) map[string]string {
+	if len(aliases) == 0 {
+		return nil
+	}
+	resolved := make(map[string]string, len(aliases))
+	for alias, target := range aliases {
+		seen := map[string]bool{alias: true}
+		for {
+			next, ok := aliases[target]
+			if !ok {
+				resolved[alias] = target
+				break
+			}
+			if seen[target] {
+				break
+			}
+			seen[target] = true
+			target = next
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoutingAliases(t *testing.T) {
+	r := &RoutingConfig{Aliases: map[string]string{
+		"fast":              "gemini-2.5-flash",
+		"smart":             "best",
+		"best":              "claude-sonnet-4-5",
+		"claude-sonnet-4.5": "claude-sonnet-4-5",
+	}}
+	if err := r.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	r.Init()
+
+	tests := map[string]string{
+		"fast":              "gemini-2.5-flash",
+		"smart":             "claude-sonnet-4-5",
+		"claude-sonnet-4.5": "claude-sonnet-4-5",
+		"gpt-4o":            "gpt-4o",
+	}
+	for in, want := range tests {
+		if got := r.ResolveModelAlias(in); got != want {
+			t.Errorf("ResolveModelAlias(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := r.ModelAliases()["smart"]; got != "claude-sonnet-4-5" {
+		t.Errorf("ModelAliases()[smart] = %q", got)
+	}
+}
+
+func TestRoutingAliasesRejectCycles(t *testing.T) {
+	tests := []map[string]string{
+		{"a": "a"},
+		{"fast": "smart", "smart": "fast"},
+		{"a": "b", "b": "c", "c": "a", "d": "e"},
+		{"fast": ""},
+	}
+	for _, aliases := range tests {
+		r := &RoutingConfig{Aliases: aliases}
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%v) accepted invalid aliases", aliases)
+		}
+	}
+	r := &RoutingConfig{Aliases: map[string]string{"fast": "smart", "smart": "fast"}}
+	if err := r.Validate(); err == nil || !strings.Contains(err.Error(), "fast -> smart -> fast") {
+		t.Errorf("Validate() = %v, want the cycle spelled out", err)
+	}
+}
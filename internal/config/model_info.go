@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ModelInfoEntry overrides the built-in info of one model, or describes a
+// model the built-in registry does not know. Zero fields keep the built-in
+// value.
+type ModelInfoEntry struct {
+	// ID is the model ID, canonical ID or provider specific name.
+	ID string `yaml:"id" json:"id"`
+
+	ContextLength       int `yaml:"context-length,omitempty" json:"context-length,omitempty"`
+	InputTokenLimit     int `yaml:"input-token-limit,omitempty" json:"input-token-limit,omitempty"`
+	OutputTokenLimit    int `yaml:"output-token-limit,omitempty" json:"output-token-limit,omitempty"`
+	MaxCompletionTokens int `yaml:"max-completion-tokens,omitempty" json:"max-completion-tokens,omitempty"`
+
+	// Thinking sets the accepted thinking budget range.
+	Thinking *ModelThinking `yaml:"thinking,omitempty" json:"thinking,omitempty"`
+
+	// SupportedParameters lists the request parameters the model accepts.
+	SupportedParameters []string `yaml:"supported-parameters,omitempty" json:"supported-parameters,omitempty"`
+
+	// Pricing is the price in USD per million tokens.
+	Pricing *ModelPricing `yaml:"pricing,omitempty" json:"pricing,omitempty"`
+
+	// AnthropicBetas replaces the anthropic-beta flags the Claude API is
+	// sent for this model.
+	AnthropicBetas *ModelAnthropicBetas `yaml:"anthropic-betas,omitempty" json:"anthropic-betas,omitempty"`
+}
+
+// ModelThinking is the thinking budget range of a model.
+type ModelThinking struct {
+	Min            int  `yaml:"min" json:"min"`
+	Max            int  `yaml:"max" json:"max"`
+	ZeroAllowed    bool `yaml:"zero-allowed,omitempty" json:"zero-allowed,omitempty"`
+	DynamicAllowed bool `yaml:"dynamic-allowed,omitempty" json:"dynamic-allowed,omitempty"`
+}
+
+// ModelPricing is the price of a model in USD per million tokens.
+type ModelPricing struct {
+	Input       float64 `yaml:"input,omitempty" json:"input,omitempty"`
+	Output      float64 `yaml:"output,omitempty" json:"output,omitempty"`
+	CachedInput float64 `yaml:"cached-input,omitempty" json:"cached-input,omitempty"`
+}
+
+// ModelAnthropicBetas lists anthropic-beta flags by the request feature that
+// needs them.
+type ModelAnthropicBetas struct {
+	Base           []string `yaml:"base,omitempty" json:"base,omitempty"`
+	Thinking       []string `yaml:"thinking,omitempty" json:"thinking,omitempty"`
+	Tools          []string `yaml:"tools,omitempty" json:"tools,omitempty"`
+	LongContext    []string `yaml:"long-context,omitempty" json:"long-context,omitempty"`
+	ExtendedOutput []string `yaml:"extended-output,omitempty" json:"extended-output,omitempty"`
+}
+
+// validate rejects empty flags and flags containing commas or whitespace.
+func (b *ModelAnthropicBetas) validate() error {
+	for _, group := range [][]string{b.Base, b.Thinking, b.Tools, b.LongContext, b.ExtendedOutput} {
+		for _, beta := range group {
+			if beta == "" || strings.ContainsAny(beta, ", \t\r\n") {
+				return fmt.Errorf("invalid anthropic-beta flag %q", beta)
+			}
+		}
+	}
+	return nil
+}
+
+// ModelInfoEntries is the model-info list of the config.
+type ModelInfoEntries []ModelInfoEntry
+
+// Validate rejects entries without an ID, duplicate IDs, negative limits and
+// prices, thinking ranges whose min exceeds max, and malformed beta flags.
+func (e ModelInfoEntries) Validate() error {
+	seen := make(map[string]struct{}, len(e))
+	for i, entry := range e {
+		id := strings.TrimSpace(entry.ID)
+		if id == "" {
+			return fmt.Errorf("model-info[%d].id is required", i)
+		}
+		if _, dup := seen[id]; dup {
+			return fmt.Errorf("model-info: duplicate id %q", id)
+		}
+		seen[id] = struct{}{}
+
+		if entry.ContextLength < 0 || entry.InputTokenLimit < 0 || entry.OutputTokenLimit < 0 || entry.MaxCompletionTokens < 0 {
+			return fmt.Errorf("model-info %q: token limits must not be negative", id)
+		}
+		if t := entry.Thinking; t != nil {
+			if t.Min < 0 || t.Max < 0 {
+				return fmt.Errorf("model-info %q: thinking range must not be negative", id)
+			}
+			if t.Min > t.Max {
+				return fmt.Errorf("model-info %q: thinking.min %d exceeds thinking.max %d", id, t.Min, t.Max)
+			}
+		}
+		if p := entry.Pricing; p != nil && (p.Input < 0 || p.Output < 0 || p.CachedInput < 0) {
+			return fmt.Errorf("model-info %q: pricing must not be negative", id)
+		}
+		if b := entry.AnthropicBetas; b != nil {
+			if err := b.validate(); err != nil {
+				return fmt.Errorf("model-info %q: %w", id, err)
+			}
+		}
+	}
+	return nil
+}
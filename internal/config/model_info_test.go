@@ -0,0 +1,35 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModelInfoEntriesValidate(t *testing.T) {
+	valid := ModelInfoEntries{
+		{ID: "gpt-5", ContextLength: 400000, Pricing: &ModelPricing{Input: 1.25, Output: 10}},
+		{ID: "new-model", Thinking: &ModelThinking{Min: 0, Max: 8192, ZeroAllowed: true}},
+		{ID: "claude-sonnet-4-5", AnthropicBetas: &ModelAnthropicBetas{LongContext: []string{"context-1m-2025-08-07"}}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	tests := map[string]struct {
+		entries ModelInfoEntries
+		want    string
+	}{
+		"missing id":     {ModelInfoEntries{{ContextLength: 1}}, "id is required"},
+		"duplicate id":   {ModelInfoEntries{{ID: "a"}, {ID: "a"}}, "duplicate id"},
+		"negative limit": {ModelInfoEntries{{ID: "a", OutputTokenLimit: -1}}, "token limits"},
+		"inverted range": {ModelInfoEntries{{ID: "a", Thinking: &ModelThinking{Min: 1024, Max: 128}}}, "exceeds thinking.max"},
+		"negative price": {ModelInfoEntries{{ID: "a", Pricing: &ModelPricing{Output: -1}}}, "pricing"},
+		"joined betas":   {ModelInfoEntries{{ID: "a", AnthropicBetas: &ModelAnthropicBetas{Base: []string{"a-1,b-2"}}}}, "anthropic-beta"},
+	}
+	for name, tt := range tests {
+		err := tt.entries.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Validate() = %v, want error containing %q", name, err, tt.want)
+		}
+	}
+}
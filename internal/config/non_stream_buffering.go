@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Non-streaming buffering modes.
+const (
+	NonStreamBufferingJSON   = "json"
+	NonStreamBufferingStream = "stream"
+)
+
+// NonStreamBuffering selects how non-streaming requests read the upstream
+// response: "json" (the default) buffers the whole upstream JSON body, while
+// "stream" requests a stream upstream and assembles the response from its
+// events as they arrive.
+type NonStreamBuffering string
+
+// Streamed reports whether non-streaming responses are assembled from an
+// upstream stream.
+func (b NonStreamBuffering) Streamed() bool {
+	return strings.EqualFold(strings.TrimSpace(string(b)), NonStreamBufferingStream)
+}
+
+// Validate checks the mode name.
+func (b NonStreamBuffering) Validate() error {
+	switch strings.ToLower(strings.TrimSpace(string(b))) {
+	case "", NonStreamBufferingJSON, NonStreamBufferingStream:
+		return nil
+	}
+	return fmt.Errorf("non-stream-buffering must be %q or %q, got %q", NonStreamBufferingJSON, NonStreamBufferingStream, string(b))
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// OAuthCallbackConfig controls where OAuth callback servers listen and which
+// redirect URI is sent to providers, so logins can run behind a reverse proxy
+// whose public URL differs from the local bind.
+type OAuthCallbackConfig struct {
+	// BindHost is the address callback servers listen on. Default: 127.0.0.1.
+	BindHost string `yaml:"bind-host,omitempty" json:"bind-host,omitempty"`
+
+	// Providers overrides the callback port and advertised redirect URI per
+	// provider (claude, codex, gemini, antigravity, iflow).
+	Providers map[string]OAuthCallbackProvider `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// MaxPending caps the logins awaiting a callback at once. Default: 100.
+	MaxPending int `yaml:"max-pending,omitempty" json:"max-pending,omitempty"`
+
+	// MaxPendingPerProvider caps the pending logins of one provider. Default: 20.
+	MaxPendingPerProvider int `yaml:"max-pending-per-provider,omitempty" json:"max-pending-per-provider,omitempty"`
+}
+
+// OAuthCallbackProvider overrides the callback settings of one provider.
+type OAuthCallbackProvider struct {
+	// Port is the local port the callback server listens on.
+	Port int `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// RedirectURI is the externally reachable callback URL sent to the provider.
+	// It must be an absolute http or https URL and reach the callback server
+	// or this server's /<provider>/callback route.
+	RedirectURI string `yaml:"redirect-uri,omitempty" json:"redirect-uri,omitempty"`
+}
+
+// Validate checks the limits, the ports and that each redirect URI is an
+// absolute URL.
+func (c OAuthCallbackConfig) Validate() error {
+	if c.MaxPending < 0 {
+		return fmt.Errorf("oauth-callback.max-pending must be >= 0, got %d", c.MaxPending)
+	}
+	if c.MaxPendingPerProvider < 0 {
+		return fmt.Errorf("oauth-callback.max-pending-per-provider must be >= 0, got %d", c.MaxPendingPerProvider)
+	}
+	for name, p := range c.Providers {
+		if p.Port < 0 || p.Port > 65535 {
+			return fmt.Errorf("oauth-callback.providers.%s.port must be between 1 and 65535, got %d", name, p.Port)
+		}
+		if p.RedirectURI == "" {
+			continue
+		}
+		u, err := url.Parse(p.RedirectURI)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("oauth-callback.providers.%s.redirect-uri must be an absolute http or https URL, got %q", name, p.RedirectURI)
+		}
+		if strings.Contains(p.RedirectURI, "#") {
+			return fmt.Errorf("oauth-callback.providers.%s.redirect-uri must not contain a fragment", name)
+		}
+	}
+	return nil
+}
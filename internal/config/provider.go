@@ -53,6 +53,11 @@ type Provider struct {
 	// Headers adds custom HTTP headers to requests.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
 
+	// TLSClientCert and TLSClientKey are PEM file paths presented as a client
+	// certificate to endpoints that require mutual TLS. Both or neither must be set.
+	TLSClientCert string `yaml:"tls-client-cert,omitempty" json:"tls-client-cert,omitempty"`
+	TLSClientKey  string `yaml:"tls-client-key,omitempty" json:"tls-client-key,omitempty"`
+
 	// Models defines available models for this provider.
 	// Required for: openai, vertex-compat
 	// Optional for: gemini, anthropic (uses built-in registry if not set)
@@ -121,6 +126,10 @@ func (p *Provider) Validate() error {
 		return &ProviderValidationError{Field: "api-key", Message: "api-key or api-keys is required"}
 	}
 
+	if (p.TLSClientCert == "") != (p.TLSClientKey == "") {
+		return &ProviderValidationError{Field: "tls-client-cert", Message: "tls-client-cert and tls-client-key must be set together"}
+	}
+
 	// Type-specific validation
 	switch p.Type {
 	case ProviderTypeOpenAI, ProviderTypeVertexCompat:
@@ -168,6 +177,8 @@ func SanitizeProviders(providers []Provider) []Provider {
 		p.APIKey = strings.TrimSpace(p.APIKey)
 		p.BaseURL = strings.TrimRight(strings.TrimSpace(p.BaseURL), "/")
 		p.ProxyURL = strings.TrimSpace(p.ProxyURL)
+		p.TLSClientCert = strings.TrimSpace(p.TLSClientCert)
+		p.TLSClientKey = strings.TrimSpace(p.TLSClientKey)
 		p.Headers = NormalizeHeaders(p.Headers)
 
 		// Normalize API keys
@@ -0,0 +1,36 @@
+package config
+
+import "fmt"
+
+// RateLimitConfig throttles inbound requests on the public API with token
+// buckets. Zero rates disable the corresponding bucket, so the limiter is off
+// by default.
+type RateLimitConfig struct {
+	// GlobalRPS caps requests per second across all clients.
+	GlobalRPS float64 `yaml:"global-rps,omitempty" json:"global-rps,omitempty"`
+
+	// GlobalBurst is the global bucket size. Defaults to GlobalRPS rounded up.
+	GlobalBurst int `yaml:"global-burst,omitempty" json:"global-burst,omitempty"`
+
+	// PerKeyRPS caps requests per second for each API key.
+	PerKeyRPS float64 `yaml:"per-key-rps,omitempty" json:"per-key-rps,omitempty"`
+
+	// PerKeyBurst is the per-key bucket size. Defaults to PerKeyRPS rounded up.
+	PerKeyBurst int `yaml:"per-key-burst,omitempty" json:"per-key-burst,omitempty"`
+}
+
+// Enabled reports whether any bucket is configured.
+func (c RateLimitConfig) Enabled() bool {
+	return c.GlobalRPS > 0 || c.PerKeyRPS > 0
+}
+
+// Validate rejects negative rates and bursts.
+func (c RateLimitConfig) Validate() error {
+	if c.GlobalRPS < 0 || c.PerKeyRPS < 0 {
+		return fmt.Errorf("rate-limit rates must not be negative")
+	}
+	if c.GlobalBurst < 0 || c.PerKeyBurst < 0 {
+		return fmt.Errorf("rate-limit bursts must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RequestTimeoutConfig caps the total duration of an upstream call, streaming
+// included, in seconds. It is independent of the stream idle timeout, which
+// only ends streams that stop sending data. Zero means no cap.
+type RequestTimeoutConfig struct {
+	// Default applies to every provider without an override.
+	Default int `yaml:"default,omitempty" json:"default,omitempty"`
+
+	// Providers overrides the default per provider name. Zero disables the
+	// cap for that provider.
+	Providers map[string]int `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// Validate checks that no timeout is negative.
+func (c RequestTimeoutConfig) Validate() error {
+	if c.Default < 0 {
+		return fmt.Errorf("request-timeout.default must not be negative, got %d", c.Default)
+	}
+	for name, seconds := range c.Providers {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("request-timeout.providers: provider name is required")
+		}
+		if seconds < 0 {
+			return fmt.Errorf("request-timeout.providers.%s must not be negative, got %d", name, seconds)
+		}
+	}
+	return nil
+}
+
+// DefaultTimeout returns the timeout of providers without an override.
+func (c RequestTimeoutConfig) DefaultTimeout() time.Duration {
+	return time.Duration(c.Default) * time.Second
+}
+
+// ProviderTimeouts returns the per-provider overrides keyed by lowercase name.
+func (c RequestTimeoutConfig) ProviderTimeouts() map[string]time.Duration {
+	if len(c.Providers) == 0 {
+		return nil
+	}
+	out := make(map[string]time.Duration, len(c.Providers))
+	for name, seconds := range c.Providers {
+		out[strings.ToLower(strings.TrimSpace(name))] = time.Duration(seconds) * time.Second
+	}
+	return out
+}
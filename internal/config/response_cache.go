@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Response cache defaults applied when the cache is enabled.
+const (
+	DefaultResponseCacheTTL        = 300
+	DefaultResponseCacheMaxEntries = 256
+)
+
+// ResponseCacheConfig serves repeated deterministic requests (temperature 0
+// or a seed) from memory instead of calling the upstream again. Off by default.
+type ResponseCacheConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// TTL is how many seconds a response is reused. Default: 300.
+	TTL int `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// MaxEntries bounds the cache; the least recently used entry is evicted
+	// first. Default: 256.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// TTLDuration returns the entry lifetime, or zero when the cache is disabled.
+func (c ResponseCacheConfig) TTLDuration() time.Duration {
+	if !c.Enable {
+		return 0
+	}
+	if c.TTL <= 0 {
+		return DefaultResponseCacheTTL * time.Second
+	}
+	return time.Duration(c.TTL) * time.Second
+}
+
+// Entries returns the entry limit, applying the default.
+func (c ResponseCacheConfig) Entries() int {
+	if c.MaxEntries <= 0 {
+		return DefaultResponseCacheMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// Validate rejects negative values.
+func (c ResponseCacheConfig) Validate() error {
+	if c.TTL < 0 {
+		return fmt.Errorf("response-cache.ttl must not be negative")
+	}
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("response-cache.max-entries must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// DefaultStateWebhookDebounce is the debounce window in seconds when none is set.
+const DefaultStateWebhookDebounce = 300
+
+// StateWebhookConfig posts account and provider state transitions, such as an
+// account failing with an auth or billing error, to an HTTP endpoint.
+type StateWebhookConfig struct {
+	// URL receives a JSON POST per transition. Empty disables the webhook.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Debounce is the minimum time in seconds between two events of the same
+	// kind for the same account or provider. Default: 300.
+	Debounce int `yaml:"debounce,omitempty" json:"debounce,omitempty"`
+}
+
+// DebounceInterval returns the effective debounce window.
+func (c StateWebhookConfig) DebounceInterval() time.Duration {
+	debounce := c.Debounce
+	if debounce <= 0 {
+		debounce = DefaultStateWebhookDebounce
+	}
+	return time.Duration(debounce) * time.Second
+}
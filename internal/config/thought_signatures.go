@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultThoughtSignatureTTL is how many seconds remembered thought signatures
+// are kept when no TTL is configured.
+const DefaultThoughtSignatureTTL = 3600
+
+// ThoughtSignaturesConfig remembers the thought signatures Gemini attaches to
+// function calls and puts them back on follow-up requests whose client
+// dropped them. Conversations are identified by the X-Session-ID header, or
+// by previous_response_id for Responses API requests. Off by default.
+type ThoughtSignaturesConfig struct {
+	// Enabled turns the store on.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// TTL is how many seconds a signature is kept. Default: 3600.
+	TTL int `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+}
+
+// TTLDuration returns how long signatures are kept.
+func (c ThoughtSignaturesConfig) TTLDuration() time.Duration {
+	if c.TTL <= 0 {
+		return DefaultThoughtSignatureTTL * time.Second
+	}
+	return time.Duration(c.TTL) * time.Second
+}
+
+// Validate rejects a negative TTL.
+func (c ThoughtSignaturesConfig) Validate() error {
+	if c.TTL < 0 {
+		return fmt.Errorf("thought-signatures.ttl must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tool loop guard defaults applied when the guard is enabled.
+const (
+	DefaultToolLoopGuardMessage = "Stopped after too many consecutive tool calls. Review the conversation before continuing."
+	DefaultToolLoopGuardTTL     = 3600
+)
+
+// ToolLoopGuardConfig stops agents that keep calling tools. Once a
+// conversation has MaxRounds consecutive tool-call rounds since the last user
+// message, the next request is answered with Message and finish_reason "stop"
+// instead of reaching the upstream. Off by default.
+type ToolLoopGuardConfig struct {
+	// MaxRounds is the number of consecutive tool-call rounds allowed. 0 disables the guard.
+	MaxRounds int `yaml:"max-rounds,omitempty" json:"max-rounds,omitempty"`
+
+	// Message is the assistant text returned when the guard stops a conversation.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	// TTL is how many seconds the round count of a Responses API response is
+	// kept for requests continuing it through previous_response_id. Default: 3600.
+	TTL int `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+}
+
+// Enabled reports whether the guard limits tool-call rounds.
+func (c ToolLoopGuardConfig) Enabled() bool {
+	return c.MaxRounds > 0
+}
+
+// Note returns the assistant text, applying the default.
+func (c ToolLoopGuardConfig) Note() string {
+	if c.Message == "" {
+		return DefaultToolLoopGuardMessage
+	}
+	return c.Message
+}
+
+// TTLDuration returns how long response round counts are kept.
+func (c ToolLoopGuardConfig) TTLDuration() time.Duration {
+	if c.TTL <= 0 {
+		return DefaultToolLoopGuardTTL * time.Second
+	}
+	return time.Duration(c.TTL) * time.Second
+}
+
+// Validate rejects negative values.
+func (c ToolLoopGuardConfig) Validate() error {
+	if c.MaxRounds < 0 {
+		return fmt.Errorf("tool-loop-guard.max-rounds must not be negative")
+	}
+	if c.TTL < 0 {
+		return fmt.Errorf("tool-loop-guard.ttl must not be negative")
+	}
+	return nil
+}
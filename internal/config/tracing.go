@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tracing exporters.
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// TracingConfig enables OpenTelemetry spans for the request lifecycle.
+type TracingConfig struct {
+	// Enable turns tracing on. When false no spans are recorded or exported.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// Exporter is "otlp" (default), which posts OTLP/HTTP protobuf to Endpoint, or
+	// "stdout", which writes spans to standard output.
+	Exporter string `yaml:"exporter,omitempty" json:"exporter,omitempty"`
+
+	// Endpoint is the OTLP/HTTP traces URL. Defaults to http://localhost:4318/v1/traces.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+
+	// Headers are sent with every OTLP export request, e.g. collector auth.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// ServiceName is reported as the service.name resource attribute. Defaults to "llm-mux".
+	ServiceName string `yaml:"service-name,omitempty" json:"service-name,omitempty"`
+
+	// SampleRatio is the fraction of new traces recorded, from 0 to 1. Zero
+	// means 1; requests with a sampled parent trace are always recorded.
+	SampleRatio float64 `yaml:"sample-ratio,omitempty" json:"sample-ratio,omitempty"`
+}
+
+// ExporterName returns the configured exporter, defaulting to OTLP.
+func (c TracingConfig) ExporterName() string {
+	if name := strings.ToLower(strings.TrimSpace(c.Exporter)); name != "" {
+		return name
+	}
+	return TracingExporterOTLP
+}
+
+// Validate checks the exporter name and sample ratio.
+func (c TracingConfig) Validate() error {
+	switch c.ExporterName() {
+	case TracingExporterOTLP, TracingExporterStdout:
+	default:
+		return fmt.Errorf("tracing.exporter must be %q or %q, got %q", TracingExporterOTLP, TracingExporterStdout, c.Exporter)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample-ratio must be between 0 and 1, got %v", c.SampleRatio)
+	}
+	return nil
+}
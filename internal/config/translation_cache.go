@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Translation cache defaults applied when the cache is enabled.
+const (
+	DefaultTranslationCacheTTL        = 30
+	DefaultTranslationCacheMaxEntries = 128
+)
+
+// TranslationCacheConfig caches translated upstream request payloads so an
+// identical request resent within the TTL skips parsing and conversion. It
+// never caches responses. Off by default.
+type TranslationCacheConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// TTL is how many seconds a translation is reused. Default: 30.
+	TTL int `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// MaxEntries bounds the cache; the least recently used entry is evicted
+	// first. Default: 128.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// TTLDuration returns the entry lifetime, or zero when the cache is disabled.
+func (c TranslationCacheConfig) TTLDuration() time.Duration {
+	if !c.Enable {
+		return 0
+	}
+	if c.TTL <= 0 {
+		return DefaultTranslationCacheTTL * time.Second
+	}
+	return time.Duration(c.TTL) * time.Second
+}
+
+// Entries returns the entry limit, applying the default.
+func (c TranslationCacheConfig) Entries() int {
+	if c.MaxEntries <= 0 {
+		return DefaultTranslationCacheMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// Validate rejects negative values.
+func (c TranslationCacheConfig) Validate() error {
+	if c.TTL < 0 {
+		return fmt.Errorf("translation-cache.ttl must not be negative")
+	}
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("translation-cache.max-entries must not be negative")
+	}
+	return nil
+}
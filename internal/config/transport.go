@@ -0,0 +1,73 @@
+package config
+
+import "fmt"
+
+// TransportConfig tunes the shared upstream HTTP transport.
+// Zero values keep the built-in defaults; durations are in seconds.
+type TransportConfig struct {
+	// MaxIdleConns caps idle connections kept across all upstream hosts.
+	MaxIdleConns int `yaml:"max-idle-conns,omitempty" json:"max-idle-conns,omitempty"`
+
+	// MaxIdleConnsPerHost caps idle connections kept per upstream host.
+	MaxIdleConnsPerHost int `yaml:"max-idle-conns-per-host,omitempty" json:"max-idle-conns-per-host,omitempty"`
+
+	// MaxConnsPerHost caps total connections per host. 0 means unlimited.
+	MaxConnsPerHost int `yaml:"max-conns-per-host,omitempty" json:"max-conns-per-host,omitempty"`
+
+	// IdleConnTimeout is how long an idle connection stays pooled.
+	IdleConnTimeout int `yaml:"idle-conn-timeout,omitempty" json:"idle-conn-timeout,omitempty"`
+
+	// TLSHandshakeTimeout bounds the TLS handshake.
+	TLSHandshakeTimeout int `yaml:"tls-handshake-timeout,omitempty" json:"tls-handshake-timeout,omitempty"`
+
+	// DialTimeout bounds establishing the TCP connection.
+	DialTimeout int `yaml:"dial-timeout,omitempty" json:"dial-timeout,omitempty"`
+
+	// KeepAlive is the TCP keep-alive probe interval.
+	KeepAlive int `yaml:"keep-alive,omitempty" json:"keep-alive,omitempty"`
+}
+
+// maxTransportTimeout is the upper bound accepted for transport timeouts (1 hour).
+const maxTransportTimeout = 3600
+
+// Validate rejects negative values, timeouts above one hour, and pool sizes
+// that contradict each other.
+func (t *TransportConfig) Validate() error {
+	if t == nil {
+		return nil
+	}
+	counts := []struct {
+		field string
+		value int
+	}{
+		{"max-idle-conns", t.MaxIdleConns},
+		{"max-idle-conns-per-host", t.MaxIdleConnsPerHost},
+		{"max-conns-per-host", t.MaxConnsPerHost},
+	}
+	for _, c := range counts {
+		if c.value < 0 {
+			return fmt.Errorf("transport.%s must not be negative, got %d", c.field, c.value)
+		}
+	}
+	timeouts := []struct {
+		field string
+		value int
+	}{
+		{"idle-conn-timeout", t.IdleConnTimeout},
+		{"tls-handshake-timeout", t.TLSHandshakeTimeout},
+		{"dial-timeout", t.DialTimeout},
+		{"keep-alive", t.KeepAlive},
+	}
+	for _, c := range timeouts {
+		if c.value < 0 || c.value > maxTransportTimeout {
+			return fmt.Errorf("transport.%s must be between 0 and %d seconds, got %d", c.field, maxTransportTimeout, c.value)
+		}
+	}
+	if t.MaxIdleConns > 0 && t.MaxIdleConnsPerHost > t.MaxIdleConns {
+		return fmt.Errorf("transport.max-idle-conns-per-host (%d) exceeds max-idle-conns (%d)", t.MaxIdleConnsPerHost, t.MaxIdleConns)
+	}
+	if t.MaxConnsPerHost > 0 && t.MaxIdleConnsPerHost > t.MaxConnsPerHost {
+		return fmt.Errorf("transport.max-idle-conns-per-host (%d) exceeds max-conns-per-host (%d)", t.MaxIdleConnsPerHost, t.MaxConnsPerHost)
+	}
+	return nil
+}
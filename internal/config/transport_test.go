@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestTransportConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     TransportConfig
+		wantErr bool
+	}{
+		{name: "zero keeps defaults", cfg: TransportConfig{}},
+		{name: "tuned pool", cfg: TransportConfig{MaxIdleConns: 500, MaxIdleConnsPerHost: 200, MaxConnsPerHost: 400, IdleConnTimeout: 600}},
+		{name: "negative pool size", cfg: TransportConfig{MaxIdleConns: -1}, wantErr: true},
+		{name: "negative timeout", cfg: TransportConfig{DialTimeout: -5}, wantErr: true},
+		{name: "timeout above limit", cfg: TransportConfig{IdleConnTimeout: maxTransportTimeout + 1}, wantErr: true},
+		{name: "per-host above total", cfg: TransportConfig{MaxIdleConns: 10, MaxIdleConnsPerHost: 20}, wantErr: true},
+		{name: "idle above conn cap", cfg: TransportConfig{MaxIdleConnsPerHost: 50, MaxConnsPerHost: 10}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
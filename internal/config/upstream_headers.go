@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// UpstreamHeaders maps a provider name, such as claude or gemini-cli, to
+// static headers sent with every request to that provider.
+type UpstreamHeaders map[string]map[string]string
+
+// Normalize lowercases provider names, trims header names and values, and
+// drops empty entries.
+func (h UpstreamHeaders) Normalize() UpstreamHeaders {
+	if len(h) == 0 {
+		return nil
+	}
+	clean := make(UpstreamHeaders, len(h))
+	for name, headers := range h {
+		name = strings.ToLower(strings.TrimSpace(name))
+		headers = NormalizeHeaders(headers)
+		if name == "" || len(headers) == 0 {
+			continue
+		}
+		if existing, ok := clean[name]; ok {
+			for k, v := range headers {
+				existing[k] = v
+			}
+			continue
+		}
+		clean[name] = headers
+	}
+	if len(clean) == 0 {
+		return nil
+	}
+	return clean
+}
+
+// Validate rejects header names and values that cannot be sent over HTTP.
+func (h UpstreamHeaders) Validate() error {
+	for name, headers := range h {
+		for k, v := range headers {
+			if !httpguts.ValidHeaderFieldName(k) {
+				return fmt.Errorf("upstream-headers.%s: invalid header name %q", name, k)
+			}
+			if !httpguts.ValidHeaderFieldValue(v) {
+				return fmt.Errorf("upstream-headers.%s: invalid value for header %s", name, k)
+			}
+		}
+	}
+	return nil
+}
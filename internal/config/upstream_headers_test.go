@@ -0,0 +1,38 @@
+package config
+
+import "testing"
+
+func TestUpstreamHeaders_Normalize(t *testing.T) {
+	h := UpstreamHeaders{
+		" Claude ": {" X-Organization ": " org-123 ", "X-Empty": " "},
+		"claude":   {"X-Team": "core"},
+		"gemini":   {"": "dropped"},
+	}.Normalize()
+
+	if len(h) != 1 {
+		t.Fatalf("Expected only claude to remain, got %v", h)
+	}
+	if got := h["claude"]; got["X-Organization"] != "org-123" || got["X-Team"] != "core" || len(got) != 2 {
+		t.Errorf("Expected trimmed and merged claude headers, got %v", got)
+	}
+}
+
+func TestUpstreamHeaders_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers UpstreamHeaders
+		wantErr bool
+	}{
+		{name: "valid", headers: UpstreamHeaders{"claude": {"anthropic-beta": "context-1m-2025-08-07"}}},
+		{name: "space in name", headers: UpstreamHeaders{"claude": {"X Org": "1"}}, wantErr: true},
+		{name: "newline in value", headers: UpstreamHeaders{"claude": {"X-Org": "a\r\nX-Injected: 1"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.headers.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Package files keeps the files uploaded through /v1/files: each record maps
+// the ID handed to clients to the provider file and the account that stored
+// it, so chat requests referencing the file can be rewritten and routed to
+// that account.
+package files
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDPrefix starts every file ID issued by llm-mux.
+const IDPrefix = "file-mux-"
+
+var idPattern = regexp.MustCompile(regexp.QuoteMeta(IDPrefix) + `[0-9a-f]{32}`)
+
+// ErrMixedAccounts is returned by Resolve when a request references files
+// stored by different accounts, which no single upstream request can read.
+var ErrMixedAccounts = errors.New("request references files stored by different accounts")
+
+// File is the persisted record of an uploaded file.
+type File struct {
+	ID        string    `json:"id"`
+	Filename  string    `json:"filename"`
+	Purpose   string    `json:"purpose"`
+	MimeType  string    `json:"mime_type,omitempty"`
+	Bytes     int64     `json:"bytes"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	// Provider and AuthID identify the account holding the upstream file.
+	Provider string `json:"provider"`
+	AuthID   string `json:"auth_id"`
+	// UpstreamID is the provider file ID, used for deletion. Ref replaces the
+	// llm-mux ID in chat requests.
+	UpstreamID string `json:"upstream_id"`
+	Ref        string `json:"ref"`
+	// APIKeyID scopes the file to the client key that uploaded it.
+	APIKeyID string `json:"api_key_id,omitempty"`
+}
+
+// NewID returns a fresh file ID.
+func NewID() string {
+	return IDPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// Expired reports whether the file is past its expiry at now.
+func (f *File) Expired(now time.Time) bool {
+	return !f.ExpiresAt.IsZero() && !now.Before(f.ExpiresAt)
+}
+
+// View is the client-facing OpenAI file object.
+type View struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt *int64 `json:"expires_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+}
+
+// View renders the file for API responses.
+func (f *File) View() View {
+	v := View{
+		ID:        f.ID,
+		Object:    "file",
+		Bytes:     f.Bytes,
+		CreatedAt: f.CreatedAt.Unix(),
+		Filename:  f.Filename,
+		Purpose:   f.Purpose,
+		Status:    "processed",
+	}
+	if !f.ExpiresAt.IsZero() {
+		ts := f.ExpiresAt.Unix()
+		v.ExpiresAt = &ts
+	}
+	return v
+}
+
+// Registry holds the file records in memory, backed by a Store.
+type Registry struct {
+	store *Store
+	mu    sync.Mutex
+	files map[string]*File
+}
+
+// NewRegistry loads the records in store, dropping expired ones.
+func NewRegistry(store *Store) (*Registry, error) {
+	records, err := store.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	r := &Registry{store: store, files: make(map[string]*File, len(records))}
+	now := time.Now()
+	for _, f := range records {
+		if f.Expired(now) {
+			_ = store.Delete(f.ID)
+			continue
+		}
+		r.files[f.ID] = f
+	}
+	return r, nil
+}
+
+// Add persists and registers f.
+func (r *Registry) Add(f *File) error {
+	if err := r.store.Save(f); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.files[f.ID] = f
+	r.mu.Unlock()
+	return nil
+}
+
+// Get returns the live file id visible to apiKeyID.
+func (r *Registry) Get(id, apiKeyID string) (*File, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[id]
+	if !ok || f.APIKeyID != apiKeyID {
+		return nil, false
+	}
+	if f.Expired(time.Now()) {
+		r.removeLocked(id)
+		return nil, false
+	}
+	cp := *f
+	return &cp, true
+}
+
+// List returns the live files visible to apiKeyID, newest first, optionally
+// filtered by purpose.
+func (r *Registry) List(apiKeyID, purpose string) []*File {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var out []*File
+	for id, f := range r.files {
+		if f.Expired(now) {
+			r.removeLocked(id)
+			continue
+		}
+		if f.APIKeyID != apiKeyID || (purpose != "" && f.Purpose != purpose) {
+			continue
+		}
+		cp := *f
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Remove forgets the file id.
+func (r *Registry) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.removeLocked(id)
+}
+
+func (r *Registry) removeLocked(id string) error {
+	delete(r.files, id)
+	return r.store.Delete(id)
+}
+
+// Resolve replaces the llm-mux file IDs referenced in payload with the
+// provider references and returns the account holding them. It returns
+// payload unchanged and a nil file when none is referenced. Unknown or expired
+// IDs and files from different accounts are errors.
+func (r *Registry) Resolve(payload []byte, apiKeyID string) ([]byte, *File, error) {
+	if !bytes.Contains(payload, []byte(IDPrefix)) {
+		return payload, nil, nil
+	}
+	var owner *File
+	var replacements []string
+	seen := make(map[string]struct{})
+	for _, match := range idPattern.FindAll(payload, -1) {
+		id := string(match)
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		f, ok := r.Get(id, apiKeyID)
+		if !ok {
+			return nil, nil, fmt.Errorf("no file found with id '%s'", id)
+		}
+		if owner != nil && owner.AuthID != f.AuthID {
+			return nil, nil, ErrMixedAccounts
+		}
+		if owner == nil {
+			owner = f
+		}
+		replacements = append(replacements, id, f.Ref)
+	}
+	if owner == nil {
+		return payload, nil, nil
+	}
+	return []byte(strings.NewReplacer(replacements...).Replace(string(payload))), owner, nil
+}
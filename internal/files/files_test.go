@@ -0,0 +1,98 @@
+package files
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRegistry(t *testing.T) (*Registry, *Store) {
+	t.Helper()
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRegistry(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, store
+}
+
+func addFile(t *testing.T, r *Registry, authID, ref, apiKeyID string, expiresAt time.Time) *File {
+	t.Helper()
+	f := &File{ID: NewID(), Filename: "doc.pdf", Purpose: "user_data", CreatedAt: time.Now(), ExpiresAt: expiresAt,
+		Provider: "gemini", AuthID: authID, UpstreamID: "files/x", Ref: ref, APIKeyID: apiKeyID}
+	if err := r.Add(f); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestRegistryPersistsAndExpires(t *testing.T) {
+	r, store := newTestRegistry(t)
+	live := addFile(t, r, "a", "ref-live", "", time.Now().Add(time.Hour))
+	expired := addFile(t, r, "a", "ref-old", "", time.Now().Add(-time.Second))
+	scoped := addFile(t, r, "a", "ref-key", "key-1", time.Time{})
+
+	if _, ok := r.Get(expired.ID, ""); ok {
+		t.Error("expired file is still returned")
+	}
+	if _, ok := r.Get(scoped.ID, ""); ok {
+		t.Error("file is visible to another API key")
+	}
+	if list := r.List("key-1", ""); len(list) != 1 || list[0].ID != scoped.ID {
+		t.Errorf("List(key-1) = %v", list)
+	}
+
+	reloaded, err := NewRegistry(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := reloaded.Get(live.ID, ""); !ok || f.Ref != "ref-live" {
+		t.Errorf("reloaded Get = %v, %v", f, ok)
+	}
+	if _, ok := reloaded.Get(expired.ID, ""); ok {
+		t.Error("expired file survived a reload")
+	}
+	if err := reloaded.Remove(live.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reloaded.Get(live.ID, ""); ok {
+		t.Error("removed file is still returned")
+	}
+}
+
+func TestRegistryResolve(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	a1 := addFile(t, r, "auth-a", "https://generativelanguage.googleapis.com/v1beta/files/one", "", time.Time{})
+	a2 := addFile(t, r, "auth-a", "files/two", "", time.Time{})
+	b := addFile(t, r, "auth-b", "file-upstream", "", time.Time{})
+
+	plain := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+	if out, owner, err := r.Resolve(plain, ""); err != nil || owner != nil || string(out) != string(plain) {
+		t.Fatalf("Resolve without files = %s, %v, %v", out, owner, err)
+	}
+
+	payload := []byte(`{"messages":[{"role":"user","content":[{"type":"file","file":{"file_id":"` + a1.ID + `"}},{"type":"file","file":{"file_id":"` + a2.ID + `"}},{"type":"file","file":{"file_id":"` + a1.ID + `"}}]}]}`)
+	out, owner, err := r.Resolve(payload, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner == nil || owner.AuthID != "auth-a" {
+		t.Fatalf("owner = %v, want auth-a", owner)
+	}
+	if strings.Contains(string(out), IDPrefix) || strings.Count(string(out), a1.Ref) != 2 || !strings.Contains(string(out), `"files/two"`) {
+		t.Errorf("rewritten payload = %s", out)
+	}
+
+	mixed := []byte(`["` + a1.ID + `","` + b.ID + `"]`)
+	if _, _, err := r.Resolve(mixed, ""); !errors.Is(err, ErrMixedAccounts) {
+		t.Errorf("mixed accounts err = %v", err)
+	}
+	unknown := []byte(`"` + NewID() + `"`)
+	if _, _, err := r.Resolve(unknown, ""); err == nil {
+		t.Error("unknown file ID was accepted")
+	}
+}
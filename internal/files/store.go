@@ -0,0 +1,84 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// Store persists file records as one JSON file per file under a directory.
+// Only the mapping to the provider file is kept; the content stays upstream.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store rooted at dir, creating it if needed.
+func NewStore(dir string) (*Store, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("file store directory is empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create file directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save writes the record atomically so a crash never leaves a truncated file.
+func (s *Store) Save(f *File) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("encode file %s: %w", f.ID, err)
+	}
+	tmp := s.path(f.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write file %s: %w", f.ID, err)
+	}
+	if err := os.Rename(tmp, s.path(f.ID)); err != nil {
+		return fmt.Errorf("commit file %s: %w", f.ID, err)
+	}
+	return nil
+}
+
+// Delete removes the record for id. A missing record is not an error.
+func (s *Store) Delete(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete file %s: %w", id, err)
+	}
+	return nil
+}
+
+// LoadAll reads every stored record. Unreadable files are skipped and logged.
+func (s *Store) LoadAll() ([]*File, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read file directory: %w", err)
+	}
+	var records []*File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, errRead := os.ReadFile(filepath.Join(s.dir, name))
+		if errRead != nil {
+			log.Warnf("files: skip %s: %v", name, errRead)
+			continue
+		}
+		var f File
+		if errDecode := json.Unmarshal(data, &f); errDecode != nil || f.ID == "" {
+			log.Warnf("files: skip %s: invalid file record", name)
+			continue
+		}
+		records = append(records, &f)
+	}
+	return records, nil
+}
@@ -38,24 +38,25 @@ func GinLogrusLogger() gin.HandlerFunc {
 			logLine = logLine + " | " + errorMessage
 		}
 
+		entry := WithContext(c.Request.Context())
 		switch {
 		case statusCode >= http.StatusInternalServerError:
-			Error(logLine)
+			entry.Error(logLine)
 		case statusCode >= http.StatusBadRequest:
-			Warn(logLine)
+			entry.Warn(logLine)
 		default:
-			Info(logLine)
+			entry.Info(logLine)
 		}
 	}
 }
 
 func GinLogrusRecovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
-		WithFields(Fields{
-			"panic": recovered,
-			"stack": string(debug.Stack()),
-			"path":  c.Request.URL.Path,
-		}).Error("recovered from panic")
+		WithContext(c.Request.Context()).
+			WithField("panic", recovered).
+			WithField("stack", string(debug.Stack())).
+			WithField("path", c.Request.URL.Path).
+			Error("recovered from panic")
 
 		c.AbortWithStatus(http.StatusInternalServerError)
 	})
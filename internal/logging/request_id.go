@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID between clients, llm-mux and its logs.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds client supplied request IDs.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// NewRequestID returns candidate when it is a usable client supplied ID, or a
+// freshly generated one otherwise.
+func NewRequestID(candidate string) string {
+	id := strings.TrimSpace(candidate)
+	if id != "" && len(id) <= maxRequestIDLen && !strings.ContainsAny(id, "\r\n/") {
+		return id
+	}
+	return uuid.NewString()
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithContext returns an Entry tagged with the request ID carried by ctx.
+func WithContext(ctx context.Context) *Entry {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return WithField("request_id", id)
+	}
+	return &Entry{}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+// Instruments emitted by llm-mux. Label values are passed positionally in the
+// order listed in each declaration.
+var (
+	// SelectorPicks counts credential selections by provider and outcome
+	// ("selected", "cooldown" or "unavailable").
+	SelectorPicks = NewCounter("llm_mux_selector_picks_total",
+		"Credential selections by provider and outcome.", "provider", "outcome")
+
+	// ExecutorRequests counts upstream executions by provider and outcome
+	// ("success" or "failure").
+	ExecutorRequests = NewCounter("llm_mux_executor_requests_total",
+		"Upstream executions by provider and outcome.", "provider", "outcome")
+
+	// ExecutorDuration observes upstream execution latency in seconds.
+	ExecutorDuration = NewHistogram("llm_mux_executor_duration_seconds",
+		"Upstream execution latency in seconds.", nil, "provider")
+
+	// StreamBytes counts payload bytes sent through streaming pipelines.
+	StreamBytes = NewCounter("llm_mux_stream_bytes_total",
+		"Payload bytes sent through streaming pipelines.")
+
+	// StreamChunks counts chunks sent through streaming pipelines.
+	StreamChunks = NewCounter("llm_mux_stream_chunks_total",
+		"Chunks sent through streaming pipelines.")
+
+	// TranslationCacheLookups counts request translation cache lookups by
+	// result ("hit" or "miss").
+	TranslationCacheLookups = NewCounter("llm_mux_translation_cache_lookups_total",
+		"Request translation cache lookups by result.", "result")
+
+	// ResponseCacheLookups counts response cache lookups for deterministic
+	// requests by result ("hit" or "miss").
+	ResponseCacheLookups = NewCounter("llm_mux_response_cache_lookups_total",
+		"Response cache lookups for deterministic requests by result.", "result")
+
+	// ToolLoopGuardStops counts requests answered by the tool loop guard
+	// instead of an upstream.
+	ToolLoopGuardStops = NewCounter("llm_mux_tool_loop_guard_stops_total",
+		"Requests stopped by the tool loop guard.")
+
+	// ChaosFaults counts faults injected by the chaos mode by kind.
+	ChaosFaults = NewCounter("llm_mux_chaos_faults_total",
+		"Simulated upstream faults injected by the chaos mode.", "fault")
+
+	// AsyncQueueDepth reports the pending items in background worker queues.
+	AsyncQueueDepth = NewGauge("llm_mux_async_queue_depth",
+		"Pending items in background worker queues.", "queue")
+
+	// AsyncQueueDropped counts items dropped because a worker queue was full.
+	AsyncQueueDropped = NewCounter("llm_mux_async_queue_dropped_total",
+		"Items dropped because a background worker queue was full.", "queue")
+)
@@ -0,0 +1,204 @@
+// Package metrics defines the small instrumentation interface the hot paths
+// emit through. Backends such as Prometheus, StatsD or OpenTelemetry plug in
+// with Set; until then every instrument is a no-op.
+//
+// Instruments are declared once at package level with NewCounter, NewGauge
+// and NewHistogram and emitted with fixed-size Labels values, so recording a
+// sample never allocates.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// MaxLabels is the maximum number of labels an instrument can declare.
+const MaxLabels = 4
+
+// Labels holds label values in the order the instrument declared its label
+// names. It is passed by value so emitting a sample does not allocate.
+type Labels [MaxLabels]string
+
+// L builds Labels from up to MaxLabels values.
+func L(values ...string) Labels {
+	var l Labels
+	copy(l[:], values)
+	return l
+}
+
+// Desc describes an instrument to a backend.
+type Desc struct {
+	Name       string
+	Help       string
+	LabelNames []string
+	// Buckets are the histogram upper bounds; nil for counters and gauges.
+	Buckets []float64
+}
+
+// Counter is a monotonically increasing value.
+type Counter interface {
+	Add(delta float64, labels Labels)
+}
+
+// Gauge is a value that can go up and down.
+type Gauge interface {
+	Set(value float64, labels Labels)
+	Add(delta float64, labels Labels)
+}
+
+// Histogram records observations into buckets.
+type Histogram interface {
+	Observe(value float64, labels Labels)
+}
+
+// Metrics creates backend instruments. Implementations must be safe for
+// concurrent use and should keep Add, Set and Observe allocation-free.
+type Metrics interface {
+	Counter(desc Desc) Counter
+	Gauge(desc Desc) Gauge
+	Histogram(desc Desc) Histogram
+}
+
+// DefaultBuckets are latency buckets in seconds.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+type noop struct{}
+
+func (noop) Add(float64, Labels)     {}
+func (noop) Set(float64, Labels)     {}
+func (noop) Observe(float64, Labels) {}
+
+// Noop is a Metrics backend that discards every sample.
+type Noop struct{}
+
+func (Noop) Counter(Desc) Counter     { return noop{} }
+func (Noop) Gauge(Desc) Gauge         { return noop{} }
+func (Noop) Histogram(Desc) Histogram { return noop{} }
+
+// instrument is implemented by the package-level instrument handles so Set
+// can rebind them to a new backend.
+type instrument interface {
+	bind(m Metrics)
+}
+
+var (
+	mu          sync.Mutex
+	backend     Metrics = Noop{}
+	instruments []instrument
+)
+
+// Set installs m as the metrics backend and rebinds every declared
+// instrument to it. A nil m restores the no-op backend.
+func Set(m Metrics) {
+	if m == nil {
+		m = Noop{}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	setLocked(m)
+}
+
+// SetDefault installs m only when no backend has been set yet, so a backend
+// installed by an embedding application wins over configuration defaults.
+func SetDefault(m Metrics) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, unset := backend.(Noop); !unset {
+		return false
+	}
+	setLocked(m)
+	return true
+}
+
+func setLocked(m Metrics) {
+	backend = m
+	for _, inst := range instruments {
+		inst.bind(m)
+	}
+}
+
+func register(inst instrument) {
+	mu.Lock()
+	defer mu.Unlock()
+	instruments = append(instruments, inst)
+	inst.bind(backend)
+}
+
+type counterHolder struct{ c Counter }
+type gaugeHolder struct{ g Gauge }
+type histogramHolder struct{ h Histogram }
+
+// CounterHandle is a package-level counter bound to the current backend.
+type CounterHandle struct {
+	desc Desc
+	impl atomic.Pointer[counterHolder]
+}
+
+// NewCounter declares a counter. Call it from package-level var blocks.
+func NewCounter(name, help string, labelNames ...string) *CounterHandle {
+	h := &CounterHandle{desc: Desc{Name: name, Help: help, LabelNames: labelNames}}
+	register(h)
+	return h
+}
+
+func (h *CounterHandle) bind(m Metrics) { h.impl.Store(&counterHolder{c: m.Counter(h.desc)}) }
+
+// Add increments the counter by delta.
+func (h *CounterHandle) Add(delta float64, labels Labels) { h.impl.Load().c.Add(delta, labels) }
+
+// Inc increments the counter by one.
+func (h *CounterHandle) Inc(labels Labels) { h.impl.Load().c.Add(1, labels) }
+
+// GaugeHandle is a package-level gauge bound to the current backend.
+type GaugeHandle struct {
+	desc Desc
+	impl atomic.Pointer[gaugeHolder]
+}
+
+// NewGauge declares a gauge. Call it from package-level var blocks.
+func NewGauge(name, help string, labelNames ...string) *GaugeHandle {
+	h := &GaugeHandle{desc: Desc{Name: name, Help: help, LabelNames: labelNames}}
+	register(h)
+	return h
+}
+
+func (h *GaugeHandle) bind(m Metrics) { h.impl.Store(&gaugeHolder{g: m.Gauge(h.desc)}) }
+
+// Set sets the gauge to value.
+func (h *GaugeHandle) Set(value float64, labels Labels) { h.impl.Load().g.Set(value, labels) }
+
+// Add adds delta, which may be negative, to the gauge.
+func (h *GaugeHandle) Add(delta float64, labels Labels) { h.impl.Load().g.Add(delta, labels) }
+
+// HistogramHandle is a package-level histogram bound to the current backend.
+type HistogramHandle struct {
+	desc Desc
+	impl atomic.Pointer[histogramHolder]
+}
+
+// NewHistogram declares a histogram. Nil buckets use DefaultBuckets.
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *HistogramHandle {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &HistogramHandle{desc: Desc{Name: name, Help: help, LabelNames: labelNames, Buckets: buckets}}
+	register(h)
+	return h
+}
+
+func (h *HistogramHandle) bind(m Metrics) { h.impl.Store(&histogramHolder{h: m.Histogram(h.desc)}) }
+
+// Observe records value.
+func (h *HistogramHandle) Observe(value float64, labels Labels) {
+	h.impl.Load().h.Observe(value, labels)
+}
+
+// Handler returns the scrape handler of the current backend, or nil when the
+// backend does not serve its own samples.
+func Handler() http.Handler {
+	mu.Lock()
+	defer mu.Unlock()
+	h, _ := backend.(http.Handler)
+	return h
+}
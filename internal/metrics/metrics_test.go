@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusExposition(t *testing.T) {
+	prom := NewPrometheus()
+	Set(prom)
+	t.Cleanup(func() { Set(nil) })
+
+	requests := NewCounter("test_requests_total", "Test requests.", "provider", "outcome")
+	depth := NewGauge("test_queue_depth", "Test depth.", "queue")
+	latency := NewHistogram("test_latency_seconds", "Test latency.", []float64{0.1, 1}, "provider")
+
+	requests.Inc(L("claude", "success"))
+	requests.Add(2, L("claude", "success"))
+	requests.Inc(L("gemini", "failure"))
+	depth.Set(7, L("usage"))
+	latency.Observe(0.05, L("claude"))
+	latency.Observe(0.5, L("claude"))
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	prom.write(w)
+	_ = w.Flush()
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE test_requests_total counter\n",
+		`test_requests_total{provider="claude",outcome="success"} 3` + "\n",
+		`test_requests_total{provider="gemini",outcome="failure"} 1` + "\n",
+		`test_queue_depth{queue="usage"} 7` + "\n",
+		"# TYPE test_latency_seconds histogram\n",
+		`test_latency_seconds_bucket{provider="claude",le="0.1"} 1` + "\n",
+		`test_latency_seconds_bucket{provider="claude",le="1"} 2` + "\n",
+		`test_latency_seconds_bucket{provider="claude",le="+Inf"} 2` + "\n",
+		`test_latency_seconds_sum{provider="claude"} 0.55` + "\n",
+		`test_latency_seconds_count{provider="claude"} 2` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected exposition to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestSetRebindsDeclaredInstruments(t *testing.T) {
+	counter := NewCounter("test_rebind_total", "")
+	counter.Inc(Labels{}) // no-op backend
+
+	prom := NewPrometheus()
+	if !SetDefault(prom) {
+		t.Fatal("Expected SetDefault to install over the no-op backend")
+	}
+	t.Cleanup(func() { Set(nil) })
+	if SetDefault(NewPrometheus()) {
+		t.Error("Expected SetDefault to keep an installed backend")
+	}
+
+	counter.Inc(Labels{})
+	if Handler() != prom {
+		t.Error("Expected Handler to return the installed Prometheus backend")
+	}
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	prom.write(w)
+	_ = w.Flush()
+	if !strings.Contains(buf.String(), "test_rebind_total 1\n") {
+		t.Errorf("Expected only the sample after Set to be recorded, got:\n%s", buf.String())
+	}
+}
+
+func TestHotPathDoesNotAllocate(t *testing.T) {
+	Set(NewPrometheus())
+	t.Cleanup(func() { Set(nil) })
+
+	provider := "claude"
+	ExecutorRequests.Inc(L(provider, "success"))
+	ExecutorDuration.Observe(0.2, L(provider))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ExecutorRequests.Inc(L(provider, "success"))
+		ExecutorDuration.Observe(0.2, L(provider))
+		StreamBytes.Add(128, Labels{})
+	})
+	if allocs != 0 {
+		t.Errorf("Expected allocation-free emission, got %v allocs per run", allocs)
+	}
+}
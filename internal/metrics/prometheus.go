@@ -0,0 +1,241 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Prometheus is a Metrics backend that serves samples in the Prometheus text
+// exposition format. It implements http.Handler for the scrape endpoint.
+type Prometheus struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewPrometheus creates an empty Prometheus backend.
+func NewPrometheus() *Prometheus {
+	return &Prometheus{families: make(map[string]*family)}
+}
+
+type familyKind int
+
+const (
+	kindCounter familyKind = iota
+	kindGauge
+	kindHistogram
+)
+
+func (k familyKind) String() string {
+	switch k {
+	case kindCounter:
+		return "counter"
+	case kindGauge:
+		return "gauge"
+	default:
+		return "histogram"
+	}
+}
+
+// family holds every labelled series of one instrument.
+type family struct {
+	desc Desc
+	kind familyKind
+
+	mu     sync.RWMutex
+	series map[Labels]*series
+}
+
+type series struct {
+	value   atomicFloat
+	count   atomic.Uint64
+	buckets []atomic.Uint64
+}
+
+type atomicFloat struct{ bits atomic.Uint64 }
+
+func (f *atomicFloat) Load() float64   { return math.Float64frombits(f.bits.Load()) }
+func (f *atomicFloat) Store(v float64) { f.bits.Store(math.Float64bits(v)) }
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// get returns the series for labels, creating it on first use. Only the
+// first sample of a label combination allocates.
+func (f *family) get(labels Labels) *series {
+	f.mu.RLock()
+	s := f.series[labels]
+	f.mu.RUnlock()
+	if s != nil {
+		return s
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s = f.series[labels]; s == nil {
+		s = &series{}
+		if f.kind == kindHistogram {
+			s.buckets = make([]atomic.Uint64, len(f.desc.Buckets))
+		}
+		f.series[labels] = s
+	}
+	return s
+}
+
+func (f *family) Add(delta float64, labels Labels) { f.get(labels).value.Add(delta) }
+func (f *family) Set(value float64, labels Labels) { f.get(labels).value.Store(value) }
+
+func (f *family) Observe(value float64, labels Labels) {
+	s := f.get(labels)
+	for i, le := range f.desc.Buckets {
+		if value <= le {
+			s.buckets[i].Add(1)
+		}
+	}
+	s.count.Add(1)
+	s.value.Add(value)
+}
+
+func (p *Prometheus) family(desc Desc, kind familyKind) *family {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if f, ok := p.families[desc.Name]; ok {
+		return f
+	}
+	f := &family{desc: desc, kind: kind, series: make(map[Labels]*series)}
+	p.families[desc.Name] = f
+	return f
+}
+
+// Counter implements Metrics.
+func (p *Prometheus) Counter(desc Desc) Counter { return p.family(desc, kindCounter) }
+
+// Gauge implements Metrics.
+func (p *Prometheus) Gauge(desc Desc) Gauge { return p.family(desc, kindGauge) }
+
+// Histogram implements Metrics.
+func (p *Prometheus) Histogram(desc Desc) Histogram { return p.family(desc, kindHistogram) }
+
+// ServeHTTP writes every series in the text exposition format.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	p.write(bw)
+	_ = bw.Flush()
+}
+
+func (p *Prometheus) write(w *bufio.Writer) {
+	p.mu.Lock()
+	names := make([]string, 0, len(p.families))
+	for name := range p.families {
+		names = append(names, name)
+	}
+	families := make([]*family, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		families = append(families, p.families[name])
+	}
+	p.mu.Unlock()
+
+	for _, f := range families {
+		f.write(w)
+	}
+}
+
+func (f *family) write(w *bufio.Writer) {
+	f.mu.RLock()
+	keys := make([]Labels, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	f.mu.RUnlock()
+	sort.Slice(keys, func(i, j int) bool {
+		for n := range keys[i] {
+			if keys[i][n] != keys[j][n] {
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+
+	name := f.desc.Name
+	if f.desc.Help != "" {
+		w.WriteString("# HELP " + name + " " + escapeHelp(f.desc.Help) + "\n")
+	}
+	w.WriteString("# TYPE " + name + " " + f.kind.String() + "\n")
+
+	for _, labels := range keys {
+		f.mu.RLock()
+		s := f.series[labels]
+		f.mu.RUnlock()
+		base := f.labelPairs(labels)
+		if f.kind != kindHistogram {
+			writeSample(w, name, base, "", s.value.Load())
+			continue
+		}
+		for i, le := range f.desc.Buckets {
+			writeSample(w, name+"_bucket", base, `le="`+formatFloat(le)+`"`, float64(s.buckets[i].Load()))
+		}
+		count := float64(s.count.Load())
+		writeSample(w, name+"_bucket", base, `le="+Inf"`, count)
+		writeSample(w, name+"_sum", base, "", s.value.Load())
+		writeSample(w, name+"_count", base, "", count)
+	}
+}
+
+func (f *family) labelPairs(labels Labels) string {
+	var b strings.Builder
+	for i, name := range f.desc.LabelNames {
+		if i >= MaxLabels {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name + `="` + escapeLabel(labels[i]) + `"`)
+	}
+	return b.String()
+}
+
+func writeSample(w *bufio.Writer, name, labels, extra string, value float64) {
+	w.WriteString(name)
+	if labels != "" || extra != "" {
+		w.WriteByte('{')
+		w.WriteString(labels)
+		if labels != "" && extra != "" {
+			w.WriteByte(',')
+		}
+		w.WriteString(extra)
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
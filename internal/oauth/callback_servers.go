@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,7 +22,8 @@ type ProviderConfig struct {
 }
 
 // Pre-defined provider configurations with fixed ports.
-// These CANNOT be changed - they are registered with OAuth providers.
+// These are the defaults registered with OAuth providers; ConfigureCallbacks
+// can override the port and advertised redirect URI per provider.
 var ProviderConfigs = map[string]ProviderConfig{
 	"claude": {
 		Name:         "claude",
@@ -59,6 +62,100 @@ var ProviderConfigs = map[string]ProviderConfig{
 	},
 }
 
+// DefaultCallbackBindHost is the address callback servers listen on unless configured.
+const DefaultCallbackBindHost = "127.0.0.1"
+
+// CallbackOverride replaces the default callback port and redirect URI of a provider.
+type CallbackOverride struct {
+	Port        int    // Local port the callback server listens on; 0 keeps the default
+	RedirectURI string // Advertised redirect URI; empty derives it from the port
+}
+
+var (
+	callbackMu        sync.RWMutex
+	callbackBindHost  = DefaultCallbackBindHost
+	callbackOverrides map[string]CallbackOverride
+)
+
+// ConfigureCallbacks sets the address callback servers bind to and the
+// per-provider overrides, so the redirect URI sent to providers can point at a
+// reverse proxy while the servers listen locally. It rejects unknown providers
+// and malformed redirect URIs and leaves the previous settings in place.
+func ConfigureCallbacks(bindHost string, overrides map[string]CallbackOverride) error {
+	bindHost = strings.TrimSpace(bindHost)
+	if bindHost == "" {
+		bindHost = DefaultCallbackBindHost
+	}
+	normalized := make(map[string]CallbackOverride, len(overrides))
+	for name, o := range overrides {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if canonical, ok := callbackAliases[name]; ok {
+			name = canonical
+		}
+		if _, ok := ProviderConfigs[name]; !ok {
+			return fmt.Errorf("oauth callback: unknown provider %q", name)
+		}
+		if o.Port < 0 || o.Port > 65535 {
+			return fmt.Errorf("oauth callback: invalid port %d for %s", o.Port, name)
+		}
+		if o.RedirectURI != "" {
+			if err := ValidateRedirectURI(o.RedirectURI); err != nil {
+				return fmt.Errorf("oauth callback: %s: %w", name, err)
+			}
+		}
+		normalized[name] = o
+	}
+	callbackMu.Lock()
+	callbackBindHost = bindHost
+	callbackOverrides = normalized
+	callbackMu.Unlock()
+	return nil
+}
+
+// ValidateRedirectURI reports whether uri can be sent to a provider as a
+// redirect URI: an absolute http or https URL without a fragment.
+func ValidateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid redirect URI %q: %w", uri, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid redirect URI %q: scheme must be http or https", uri)
+	}
+	if u.Host == "" || u.Hostname() == "" {
+		return fmt.Errorf("invalid redirect URI %q: missing host", uri)
+	}
+	if u.Fragment != "" || strings.Contains(uri, "#") {
+		return fmt.Errorf("invalid redirect URI %q: fragments are not allowed", uri)
+	}
+	return nil
+}
+
+// callbackAliases maps provider aliases to the name overrides are keyed by.
+var callbackAliases = map[string]string{
+	"anthropic":  "claude",
+	"gemini-cli": "gemini",
+}
+
+// callbackOverride returns the configured override for a provider.
+func callbackOverride(provider string) (CallbackOverride, bool) {
+	if canonical, ok := callbackAliases[provider]; ok {
+		provider = canonical
+	}
+	callbackMu.RLock()
+	defer callbackMu.RUnlock()
+	o, ok := callbackOverrides[provider]
+	return o, ok
+}
+
+// callbackAddr returns the listen address for a callback port.
+func callbackAddr(port int) string {
+	callbackMu.RLock()
+	host := callbackBindHost
+	callbackMu.RUnlock()
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
 // CallbackServer represents a persistent HTTP server listening for OAuth callbacks.
 type CallbackServer struct {
 	port     int
@@ -103,11 +200,11 @@ func (m *CallbackServersManager) EnsureRunning(providers ...string) error {
 	portsNeeded := make(map[int][]string) // port -> provider names
 
 	for _, provider := range providers {
-		config, ok := ProviderConfigs[provider]
-		if !ok {
+		port := GetCallbackPort(provider)
+		if port == 0 {
 			continue
 		}
-		portsNeeded[config.Port] = append(portsNeeded[config.Port], provider)
+		portsNeeded[port] = append(portsNeeded[port], provider)
 	}
 
 	for port, providerNames := range portsNeeded {
@@ -128,7 +225,7 @@ func (m *CallbackServersManager) ensureServerRunning(port int, providers []strin
 		return nil
 	}
 
-	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	addr := callbackAddr(port)
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return fmt.Errorf("failed to listen on %s: %w", addr, err)
@@ -180,8 +277,8 @@ func (m *CallbackServersManager) ensureServerRunning(port int, providers []strin
 
 // getProviderForPort returns the primary provider name for a given port.
 func (m *CallbackServersManager) getProviderForPort(port int) string {
-	for name, config := range ProviderConfigs {
-		if config.Port == port {
+	for name := range ProviderConfigs {
+		if GetCallbackPort(name) == port {
 			return name
 		}
 	}
@@ -257,21 +354,28 @@ func (m *CallbackServersManager) RunningPorts() []int {
 	return ports
 }
 
-// GetRedirectURI returns the full redirect URI for a provider.
+// GetRedirectURI returns the redirect URI advertised to a provider: the
+// configured override, or the local callback URL on its port.
 func GetRedirectURI(provider string) string {
 	config, ok := ProviderConfigs[provider]
 	if !ok {
 		return ""
 	}
-	return fmt.Sprintf("http://localhost:%d%s", config.Port, config.CallbackPath)
+	if o, ok := callbackOverride(provider); ok && o.RedirectURI != "" {
+		return o.RedirectURI
+	}
+	return fmt.Sprintf("http://localhost:%d%s", GetCallbackPort(provider), config.CallbackPath)
 }
 
-// GetCallbackPort returns the callback port for a provider.
+// GetCallbackPort returns the local callback port for a provider.
 func GetCallbackPort(provider string) int {
 	config, ok := ProviderConfigs[provider]
 	if !ok {
 		return 0
 	}
+	if o, ok := callbackOverride(provider); ok && o.Port > 0 {
+		return o.Port
+	}
 	return config.Port
 }
 
@@ -304,7 +408,7 @@ func (m *CallbackServersManager) StartForwarder(port int, provider, targetBase s
 		m.stopForwarderInstance(prev)
 	}
 
-	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	addr := callbackAddr(port)
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
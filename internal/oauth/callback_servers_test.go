@@ -0,0 +1,58 @@
+package oauth
+
+import "testing"
+
+func TestConfigureCallbacks(t *testing.T) {
+	t.Cleanup(func() { _ = ConfigureCallbacks("", nil) })
+
+	if got := GetRedirectURI("claude"); got != "http://localhost:54545/callback" {
+		t.Fatalf("default GetRedirectURI(claude) = %q", got)
+	}
+
+	err := ConfigureCallbacks("0.0.0.0", map[string]CallbackOverride{
+		"claude": {Port: 9100, RedirectURI: "https://mux.example.com/claude/callback"},
+		"codex":  {Port: 9200},
+	})
+	if err != nil {
+		t.Fatalf("ConfigureCallbacks() error = %v", err)
+	}
+	if got := GetRedirectURI("anthropic"); got != "https://mux.example.com/claude/callback" {
+		t.Fatalf("GetRedirectURI(anthropic) = %q, want the claude override", got)
+	}
+	if got := GetCallbackPort("claude"); got != 9100 {
+		t.Fatalf("GetCallbackPort(claude) = %d, want 9100", got)
+	}
+	if got := GetRedirectURI("codex"); got != "http://localhost:9200/auth/callback" {
+		t.Fatalf("GetRedirectURI(codex) = %q", got)
+	}
+	if got := callbackAddr(9100); got != "0.0.0.0:9100" {
+		t.Fatalf("callbackAddr() = %q", got)
+	}
+
+	req, err := NewRegistry().Register("claude", ModeWebUI)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if req.RedirectURI != "https://mux.example.com/claude/callback" {
+		t.Fatalf("Register() RedirectURI = %q", req.RedirectURI)
+	}
+
+	for name, override := range map[string]CallbackOverride{
+		"unknown provider": {RedirectURI: "https://mux.example.com/cb"},
+		"relative URI":     {RedirectURI: "/claude/callback"},
+		"bad scheme":       {RedirectURI: "ftp://mux.example.com/cb"},
+		"fragment":         {RedirectURI: "https://mux.example.com/cb#x"},
+		"bad port":         {Port: 70000},
+	} {
+		provider := "claude"
+		if name == "unknown provider" {
+			provider = "nope"
+		}
+		if err := ConfigureCallbacks("", map[string]CallbackOverride{provider: override}); err == nil {
+			t.Errorf("%s: ConfigureCallbacks() accepted %+v", name, override)
+		}
+	}
+	if got := GetCallbackPort("claude"); got != 9100 {
+		t.Fatalf("rejected config replaced the previous one: port = %d", got)
+	}
+}
@@ -0,0 +1,51 @@
+package oauth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseCallbackInput extracts the OAuth result from what a user pastes back
+// after completing consent on another machine. It accepts the full redirect
+// URL copied from the browser's address bar, its query string, or the bare
+// authorization code, optionally in the "code#state" form some providers
+// display. State is empty when the input does not carry one.
+func ParseCallbackInput(input string) (*OAuthResult, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("no code entered")
+	}
+
+	if strings.Contains(input, "code=") || strings.Contains(input, "error=") {
+		query := input
+		if i := strings.Index(query, "?"); i >= 0 {
+			query = query[i+1:]
+		}
+		if i := strings.Index(query, "#"); i >= 0 {
+			query = query[:i]
+		}
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redirect URL: %w", err)
+		}
+		result := &OAuthResult{
+			Code:  strings.TrimSpace(values.Get("code")),
+			State: strings.TrimSpace(values.Get("state")),
+			Error: strings.TrimSpace(values.Get("error")),
+		}
+		if desc := strings.TrimSpace(values.Get("error_description")); result.Error != "" && desc != "" {
+			result.Error += ": " + desc
+		}
+		if result.Code == "" && result.Error == "" {
+			return nil, fmt.Errorf("redirect URL has no authorization code")
+		}
+		return result, nil
+	}
+
+	if strings.ContainsAny(input, " \t?&") {
+		return nil, fmt.Errorf("input is neither a redirect URL nor an authorization code")
+	}
+	code, state, _ := strings.Cut(input, "#")
+	return &OAuthResult{Code: code, State: state}, nil
+}
@@ -0,0 +1,38 @@
+package oauth
+
+import "testing"
+
+func TestParseCallbackInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    OAuthResult
+		wantErr bool
+	}{
+		{name: "redirect URL", input: " http://localhost:54545/callback?code=abc&state=xyz \n", want: OAuthResult{Code: "abc", State: "xyz"}},
+		{name: "query string", input: "?state=xyz&code=4%2F0Ab", want: OAuthResult{Code: "4/0Ab", State: "xyz"}},
+		{name: "provider error", input: "http://localhost:1455/auth/callback?error=access_denied&error_description=denied&state=xyz", want: OAuthResult{State: "xyz", Error: "access_denied: denied"}},
+		{name: "bare code", input: "4/0AbCd", want: OAuthResult{Code: "4/0AbCd"}},
+		{name: "code with state", input: "abc#xyz", want: OAuthResult{Code: "abc", State: "xyz"}},
+		{name: "empty", input: "  ", wantErr: true},
+		{name: "URL without code", input: "http://localhost:54545/callback?state=xyz&code=", wantErr: true},
+		{name: "prose", input: "not a code", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCallbackInput(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCallbackInput(%q) = %+v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCallbackInput(%q) error = %v", tt.input, err)
+			}
+			if *got != tt.want {
+				t.Fatalf("ParseCallbackInput(%q) = %+v, want %+v", tt.input, *got, tt.want)
+			}
+		})
+	}
+}
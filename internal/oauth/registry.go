@@ -1,6 +1,8 @@
 package oauth
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -62,6 +64,45 @@ type OAuthRequest struct {
 	Scopes      []string
 }
 
+// Default limits on pending requests, which bound the memory a client can
+// tie up by starting logins it never finishes.
+const (
+	DefaultMaxPendingRequests    = 100
+	DefaultMaxPendingPerProvider = 20
+)
+
+// ErrTooManyPendingRequests is returned by Register and Create when a pending
+// request limit is reached.
+var ErrTooManyPendingRequests = errors.New("too many pending OAuth requests")
+
+var (
+	limitsMu              sync.RWMutex
+	maxPendingRequests    = DefaultMaxPendingRequests
+	maxPendingPerProvider = DefaultMaxPendingPerProvider
+)
+
+// ConfigureRequestLimits sets how many requests may be pending at once in a
+// registry, in total and per provider. Zero or negative values restore the
+// defaults.
+func ConfigureRequestLimits(total, perProvider int) {
+	if total <= 0 {
+		total = DefaultMaxPendingRequests
+	}
+	if perProvider <= 0 {
+		perProvider = DefaultMaxPendingPerProvider
+	}
+	limitsMu.Lock()
+	maxPendingRequests = total
+	maxPendingPerProvider = perProvider
+	limitsMu.Unlock()
+}
+
+func requestLimits() (total, perProvider int) {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return maxPendingRequests, maxPendingPerProvider
+}
+
 // Registry manages pending OAuth requests with thread-safe access.
 type Registry struct {
 	mu       sync.RWMutex
@@ -84,8 +125,16 @@ func NewRegistry() *Registry {
 	return r
 }
 
-// Register creates and stores a new OAuth request.
+// Register creates and stores a new OAuth request. The request's RedirectURI
+// is the one advertised for the provider and must be well-formed.
 func (r *Registry) Register(provider string, mode RequestMode) (*OAuthRequest, error) {
+	redirectURI := GetRedirectURI(provider)
+	if redirectURI != "" {
+		if err := ValidateRedirectURI(redirectURI); err != nil {
+			return nil, err
+		}
+	}
+
 	state, err := misc.GenerateRandomState()
 	if err != nil {
 		return nil, err
@@ -98,24 +147,53 @@ func (r *Registry) Register(provider string, mode RequestMode) (*OAuthRequest, e
 
 	now := time.Now()
 	req := &OAuthRequest{
-		ID:         id,
-		State:      state,
-		Provider:   provider,
-		Mode:       mode,
-		Status:     StatusPending,
-		CreatedAt:  now,
-		ExpiresAt:  now.Add(r.defaultTTL),
-		ResultChan: make(chan *OAuthResult, 1), // Buffered to prevent blocking
+		ID:          id,
+		State:       state,
+		Provider:    provider,
+		Mode:        mode,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(r.defaultTTL),
+		ResultChan:  make(chan *OAuthResult, 1), // Buffered to prevent blocking
+		RedirectURI: redirectURI,
 	}
 
 	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkLimitsLocked(state, provider, now); err != nil {
+		return nil, err
+	}
 	r.requests[state] = req
 	r.byID[id] = req
-	r.mu.Unlock()
 
 	return req, nil
 }
 
+// checkLimitsLocked reports ErrTooManyPendingRequests when adding a pending
+// request for provider would exceed a limit. Expired requests awaiting
+// cleanup and a request being replaced under the same state are not counted.
+// Caller must hold r.mu.
+func (r *Registry) checkLimitsLocked(state, provider string, now time.Time) error {
+	total, perProvider := requestLimits()
+	pending, forProvider := 0, 0
+	for s, req := range r.requests {
+		if s == state || req.Status != StatusPending || now.After(req.ExpiresAt) {
+			continue
+		}
+		pending++
+		if req.Provider == provider {
+			forProvider++
+		}
+	}
+	if pending >= total {
+		return fmt.Errorf("%w: %d pending", ErrTooManyPendingRequests, pending)
+	}
+	if forProvider >= perProvider {
+		return fmt.Errorf("%w: %d pending for %s", ErrTooManyPendingRequests, forProvider, provider)
+	}
+	return nil
+}
+
 // Get retrieves a request by state parameter.
 func (r *Registry) Get(state string) *OAuthRequest {
 	r.mu.RLock()
@@ -279,27 +357,33 @@ func (r *Registry) cleanup() {
 
 // Create creates a new OAuth request with a given state.
 // Used to explicitly set the state parameter during OAuth flow initiation.
-func (r *Registry) Create(state, provider string, mode RequestMode) *OAuthRequest {
+// RedirectURI is set to the provider's advertised redirect URI. It returns
+// ErrTooManyPendingRequests when a pending request limit is reached.
+func (r *Registry) Create(state, provider string, mode RequestMode) (*OAuthRequest, error) {
 	now := time.Now()
 	id := state // Use state as ID for simplicity
 
 	req := &OAuthRequest{
-		ID:         id,
-		State:      state,
-		Provider:   provider,
-		Mode:       mode,
-		Status:     StatusPending,
-		CreatedAt:  now,
-		ExpiresAt:  now.Add(r.defaultTTL),
-		ResultChan: make(chan *OAuthResult, 1),
+		ID:          id,
+		State:       state,
+		Provider:    provider,
+		Mode:        mode,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(r.defaultTTL),
+		ResultChan:  make(chan *OAuthResult, 1),
+		RedirectURI: GetRedirectURI(provider),
 	}
 
 	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkLimitsLocked(state, provider, now); err != nil {
+		return nil, err
+	}
 	r.requests[state] = req
 	r.byID[id] = req
-	r.mu.Unlock()
 
-	return req
+	return req, nil
 }
 
 // Delete removes a request from the registry.
@@ -0,0 +1,57 @@
+package oauth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryPendingLimits(t *testing.T) {
+	ConfigureRequestLimits(3, 2)
+	t.Cleanup(func() { ConfigureRequestLimits(0, 0) })
+
+	r := NewRegistry()
+	if _, err := r.Create("c1", "claude", ModeWebUI); err != nil {
+		t.Fatalf("Create(c1) error = %v", err)
+	}
+	if _, err := r.Register("claude", ModeWebUI); err != nil {
+		t.Fatalf("Register(claude) error = %v", err)
+	}
+	if _, err := r.Register("claude", ModeWebUI); !errors.Is(err, ErrTooManyPendingRequests) {
+		t.Fatalf("third claude request: err = %v, want ErrTooManyPendingRequests", err)
+	}
+	if _, err := r.Create("c1", "claude", ModeWebUI); err != nil {
+		t.Fatalf("replacing c1 counted against the limit: %v", err)
+	}
+
+	if _, err := r.Create("q1", "qwen", ModeWebUI); err != nil {
+		t.Fatalf("Create(q1) error = %v", err)
+	}
+	if _, err := r.Create("q2", "qwen", ModeWebUI); !errors.Is(err, ErrTooManyPendingRequests) {
+		t.Fatalf("fourth request: err = %v, want ErrTooManyPendingRequests", err)
+	}
+
+	// Finished requests stay readable but no longer count as pending.
+	r.Complete("c1", &OAuthResult{Code: "code", State: "c1"})
+	if _, err := r.Create("q2", "qwen", ModeWebUI); err != nil {
+		t.Fatalf("Create after Complete error = %v", err)
+	}
+	r.Remove("q1")
+	if _, err := r.Register("claude", ModeWebUI); err != nil {
+		t.Fatalf("Register after Remove error = %v", err)
+	}
+
+	// Expired requests are not counted even before cleanup removes them.
+	r.mu.Lock()
+	for _, req := range r.requests {
+		req.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	r.mu.Unlock()
+	if _, err := r.Create("q3", "qwen", ModeWebUI); err != nil {
+		t.Fatalf("Create with expired requests error = %v", err)
+	}
+	r.cleanup()
+	if got := r.Stats()["pending"]; got != 1 {
+		t.Fatalf("pending after cleanup = %d, want 1", got)
+	}
+}
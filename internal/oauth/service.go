@@ -124,9 +124,6 @@ func (s *Service) StartFlow(ctx context.Context, req StartRequest) (*StartRespon
 		return nil, fmt.Errorf("failed to register OAuth request: %w", err)
 	}
 
-	// Set redirect URI based on provider
-	oauthReq.RedirectURI = GetRedirectURI(req.Provider)
-
 	// Get the token exchanger for this provider
 	s.mu.RLock()
 	exchanger, hasExchanger := s.tokenExchangers[req.Provider]
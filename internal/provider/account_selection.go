@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tierSelector chooses one of a provider's equally suitable accounts, the
+// tier left after quota, cooldown and load filtering.
+type tierSelector interface {
+	pick(provider string, auths []*Auth) *Auth
+}
+
+// authForgetter is implemented by tierSelectors that keep per-account state,
+// so the state of a removed account can be dropped.
+type authForgetter interface {
+	forget(authID string)
+}
+
+// selectionStrategy is a named tierSelector. QuotaManager swaps it as a whole
+// and loads it once per pick, so each pick uses a single strategy even while
+// it is being replaced.
+type selectionStrategy struct {
+	name        string
+	exploration float64
+	selector    tierSelector
+}
+
+// ParseSelectionStrategy returns the canonical name of an account selection
+// strategy: "default" (alias "random"), "round-robin", "lru" or
+// "latency-aware". An empty name is "default".
+func ParseSelectionStrategy(name string) (string, error) {
+	switch s := strings.ToLower(strings.TrimSpace(name)); s {
+	case "", StrategyDefault, StrategyRandom:
+		return StrategyDefault, nil
+	case StrategyRoundRobin, StrategyLRU, StrategyLatencyAware:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown selection strategy %q: want %s, %s, %s, %s or %s",
+			name, StrategyDefault, StrategyRandom, StrategyRoundRobin, StrategyLRU, StrategyLatencyAware)
+	}
+}
+
+// SetSelectionStrategy switches how accounts are chosen among equally
+// suitable ones; see ParseSelectionStrategy for the names. exploration is the
+// share of latency-aware picks made at random. The switch is atomic and
+// applies to picks that start after it. Setting the strategy in effect again
+// keeps its state, such as round-robin positions. It only applies when the
+// selector is a QuotaManager.
+func (m *Manager) SetSelectionStrategy(name string, exploration float64) error {
+	name, err := ParseSelectionStrategy(name)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return nil
+	}
+	qm, ok := m.selector.(*QuotaManager)
+	if !ok {
+		return fmt.Errorf("selection strategy %q needs the quota-aware selector", name)
+	}
+	if cur := qm.selection.Load(); cur != nil && cur.name == name && cur.exploration == exploration {
+		return nil
+	}
+
+	var selector tierSelector
+	switch name {
+	case StrategyRoundRobin:
+		selector = &roundRobinSelection{}
+	case StrategyLRU:
+		selector = &lruSelection{lastPicked: make(map[string]uint64)}
+	case StrategyLatencyAware:
+		if m.latency == nil {
+			return fmt.Errorf("selection strategy %q needs latency tracking", name)
+		}
+		tracker := m.latency
+		selector = &latencySelection{
+			p95: func(authID string) (time.Duration, bool) {
+				return tracker.firstByteP95(authID, time.Now())
+			},
+			exploration: exploration,
+		}
+	default:
+		qm.selection.Store(nil)
+		return nil
+	}
+	qm.selection.Store(&selectionStrategy{name: name, exploration: exploration, selector: selector})
+	return nil
+}
+
+// forgetSelection drops the selection state the current strategy keeps for
+// authID.
+func (m *Manager) forgetSelection(authID string) {
+	qm, ok := m.selector.(*QuotaManager)
+	if !ok {
+		return
+	}
+	if sel := qm.selection.Load(); sel != nil {
+		if f, ok := sel.selector.(authForgetter); ok {
+			f.forget(authID)
+		}
+	}
+}
+
+// SelectionStrategy names the account selection strategy in effect, or
+// "custom" when the selector is not a QuotaManager.
+func (m *Manager) SelectionStrategy() string {
+	if m == nil {
+		return StrategyDefault
+	}
+	qm, ok := m.selector.(*QuotaManager)
+	if !ok {
+		return StrategyCustom
+	}
+	if sel := qm.selection.Load(); sel != nil {
+		return sel.name
+	}
+	return StrategyDefault
+}
+
+// pickRandom is the default strategy.
+func pickRandom(auths []*Auth) *Auth {
+	return auths[rand.N(len(auths))]
+}
+
+// roundRobinSelection cycles through a provider's accounts in ID order.
+type roundRobinSelection struct {
+	cursors sync.Map // provider -> *atomic.Uint64
+}
+
+func (s *roundRobinSelection) pick(provider string, auths []*Auth) *Auth {
+	sorted := slices.SortedFunc(slices.Values(auths), func(a, b *Auth) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	v, _ := s.cursors.LoadOrStore(provider, new(atomic.Uint64))
+	n := v.(*atomic.Uint64).Add(1) - 1
+	return sorted[n%uint64(len(sorted))]
+}
+
+// lruSelection picks the account whose last pick is the oldest; accounts
+// never picked go first.
+type lruSelection struct {
+	mu         sync.Mutex
+	seq        uint64
+	lastPicked map[string]uint64 // auth ID -> seq of its last pick
+}
+
+func (s *lruSelection) pick(_ string, auths []*Auth) *Auth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	best := auths[0]
+	for _, auth := range auths[1:] {
+		if s.lastPicked[auth.ID] < s.lastPicked[best.ID] {
+			best = auth
+		}
+	}
+	s.seq++
+	s.lastPicked[best.ID] = s.seq
+	return best
+}
+
+func (s *lruSelection) forget(authID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.lastPicked, authID)
+}
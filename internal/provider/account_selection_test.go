@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestSelectionStrategySwap(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+	qm := m.GetQuotaManager()
+	auths := []*Auth{{ID: "c"}, {ID: "a"}, {ID: "b"}}
+
+	picks := func(n int) []string {
+		ids := make([]string, n)
+		for i := range ids {
+			auth, err := qm.Pick(t.Context(), "selection-test", "m", Options{ForceRotate: true}, auths)
+			if err != nil {
+				t.Fatalf("Pick: %v", err)
+			}
+			qm.RecordRequestEnd(auth.ID, "selection-test", 0, false)
+			ids[i] = auth.ID
+		}
+		return ids
+	}
+
+	if err := m.SetSelectionStrategy("Round-Robin", 0); err != nil {
+		t.Fatalf("SetSelectionStrategy: %v", err)
+	}
+	if got := m.SelectionStrategy(); got != StrategyRoundRobin {
+		t.Fatalf("SelectionStrategy = %q", got)
+	}
+	got := picks(4)
+	// Setting the strategy in effect again keeps its position.
+	if err := m.SetSelectionStrategy(StrategyRoundRobin, 0); err != nil {
+		t.Fatalf("SetSelectionStrategy: %v", err)
+	}
+	got = append(got, picks(2)...)
+	for i, id := range []string{"a", "b", "c", "a", "b", "c"} {
+		if got[i] != id {
+			t.Fatalf("round-robin picks = %v, want a b c a b c", got)
+		}
+	}
+
+	if err := m.SetSelectionStrategy(StrategyLRU, 0); err != nil {
+		t.Fatalf("SetSelectionStrategy: %v", err)
+	}
+	got = picks(9)
+	for i := 0; i+3 <= len(got); i++ {
+		if got[i] == got[i+1] || got[i] == got[i+2] || got[i+1] == got[i+2] {
+			t.Fatalf("lru picks = %v, want every account before any repeats", got)
+		}
+	}
+	// Disabling an account, as its removal does, drops its pick history.
+	lru := qm.selection.Load().selector.(*lruSelection)
+	if _, err := m.Update(t.Context(), &Auth{ID: "a", Provider: "selection-test", Disabled: true}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, ok := lru.lastPicked["a"]; ok || len(lru.lastPicked) != 2 {
+		t.Fatalf("lru history after disabling a = %v", lru.lastPicked)
+	}
+
+	if err := m.SetSelectionStrategy(StrategyRandom, 0); err != nil {
+		t.Fatalf("SetSelectionStrategy: %v", err)
+	}
+	if got := m.SelectionStrategy(); got != StrategyDefault {
+		t.Fatalf("SelectionStrategy after random = %q", got)
+	}
+	counts := make(map[string]int)
+	repeats := 0
+	got = picks(300)
+	for i, id := range got {
+		counts[id]++
+		if i > 0 && got[i-1] == id {
+			repeats++
+		}
+	}
+	if len(counts) != 3 || repeats == 0 {
+		t.Fatalf("random picks: counts %v, %d immediate repeats; want all accounts and some repeats", counts, repeats)
+	}
+
+	if err := m.SetSelectionStrategy("fastest", 0); err == nil {
+		t.Fatal("unknown strategy accepted")
+	}
+	if got := m.SelectionStrategy(); got != StrategyDefault {
+		t.Fatalf("SelectionStrategy after a rejected swap = %q", got)
+	}
+
+	m.latency = nil
+	if err := m.SetSelectionStrategy(StrategyLatencyAware, 0); err == nil {
+		t.Fatal("latency-aware accepted without latency tracking")
+	}
+	if got := m.SelectionStrategy(); got != StrategyDefault {
+		t.Fatalf("SelectionStrategy after a rejected latency-aware swap = %q", got)
+	}
+}
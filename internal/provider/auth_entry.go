@@ -358,6 +358,7 @@ type TokenFields struct {
 	ExpiresAt  atomic.Int64 // UnixNano
 	RefreshAt  atomic.Int64 // UnixNano
 	Refreshing atomic.Bool
+	Failures   atomic.Int32 // Consecutive refresh failures
 }
 
 // GetExpiresAt returns the token expiration time.
@@ -416,6 +417,16 @@ func (t *TokenFields) FinishRefresh() {
 	t.Refreshing.Store(false)
 }
 
+// RecordRefreshFailure increments the consecutive failure count and returns it.
+func (t *TokenFields) RecordRefreshFailure() int {
+	return int(t.Failures.Add(1))
+}
+
+// ResetRefreshFailures clears the consecutive failure count after a successful refresh.
+func (t *TokenFields) ResetRefreshFailures() {
+	t.Failures.Store(0)
+}
+
 // AuthEntry is the unified auth state container.
 // Uses atomics and COW pointers for lock-free hot path reads.
 type AuthEntry struct {
@@ -546,17 +557,10 @@ func NewAuthEntry(auth *Auth) *AuthEntry {
 		entry.Quota.SetCooldownUntil(auth.NextRetryAfter)
 	}
 
-	// Initialize token expiry from metadata
-	if meta.Metadata != nil {
-		if ts, ok := expirationFromMap(meta.Metadata); ok {
-			entry.Token.SetExpiresAt(ts)
-			// Schedule refresh 5 minutes before expiry
-			refreshAt := ts.Add(-5 * time.Minute)
-			if refreshAt.Before(time.Now()) {
-				refreshAt = time.Now().Add(5 * time.Second)
-			}
-			entry.Token.SetRefreshAt(refreshAt)
-		}
+	// Initialize token expiry from metadata or attributes
+	if ts, ok := auth.ExpirationTime(); ok {
+		entry.Token.SetExpiresAt(ts)
+		entry.Token.SetRefreshAt(refreshTimeFor(ts, defaultRefreshLead))
 	}
 
 	return entry
@@ -4,10 +4,12 @@ import (
 	"container/heap"
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/metrics"
 )
 
 const (
@@ -15,8 +17,40 @@ const (
 	persistDebounceMs     = 500
 	persistQueueSize      = 256
 	refreshHeapInitialCap = 64
+
+	// defaultRefreshLead is how long before expiry a token is proactively refreshed.
+	defaultRefreshLead = 5 * time.Minute
+	// refreshRetryBase and refreshRetryMax bound the exponential backoff applied
+	// after consecutive refresh failures.
+	refreshRetryBase = time.Minute
+	refreshRetryMax  = 30 * time.Minute
 )
 
+var persistQueueLabels = metrics.L("auth_persist")
+
+// refreshTimeFor returns when a token expiring at expiresAt should be refreshed.
+// Tokens already inside the lead window are refreshed almost immediately.
+func refreshTimeFor(expiresAt time.Time, lead time.Duration) time.Time {
+	refreshAt := expiresAt.Add(-lead)
+	if now := time.Now(); refreshAt.Before(now) {
+		refreshAt = now.Add(5 * time.Second)
+	}
+	return refreshAt
+}
+
+// refreshRetryDelay returns the backoff delay after the given number of
+// consecutive refresh failures.
+func refreshRetryDelay(failures int) time.Duration {
+	delay := refreshRetryBase
+	for i := 1; i < failures && delay < refreshRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > refreshRetryMax {
+		delay = refreshRetryMax
+	}
+	return delay
+}
+
 type authShard struct {
 	mu      sync.RWMutex
 	entries map[string]*AuthEntry
@@ -76,6 +110,8 @@ type AuthRegistry struct {
 
 	indexCounter uint64
 	indexMu      sync.Mutex
+
+	refreshLead atomic.Int64 // Configured duration in nanoseconds; 0 uses defaultRefreshLead
 }
 
 func NewAuthRegistry(store Store, hook Hook) *AuthRegistry {
@@ -101,6 +137,29 @@ func NewAuthRegistry(store Store, hook Hook) *AuthRegistry {
 	return r
 }
 
+// SetRefreshLead configures how long before token expiry a proactive refresh
+// is scheduled. Non-positive values restore the default lead.
+func (r *AuthRegistry) SetRefreshLead(lead time.Duration) {
+	if lead < 0 {
+		lead = 0
+	}
+	r.refreshLead.Store(int64(lead))
+}
+
+// RefreshLead returns the proactive refresh lead.
+func (r *AuthRegistry) RefreshLead() time.Duration {
+	if lead := r.configuredRefreshLead(); lead > 0 {
+		return lead
+	}
+	return defaultRefreshLead
+}
+
+// configuredRefreshLead returns the lead set with SetRefreshLead, or 0 when
+// none is set.
+func (r *AuthRegistry) configuredRefreshLead() time.Duration {
+	return time.Duration(r.refreshLead.Load())
+}
+
 func (r *AuthRegistry) SetExecutorProvider(fn func(provider string) ProviderExecutor) {
 	r.getExecutor = fn
 }
@@ -245,6 +304,9 @@ func (r *AuthRegistry) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 
 	entry.SetDisabled(auth.Disabled)
 	entry.SetUnavailable(auth.Unavailable)
+	if ts, ok := auth.ExpirationTime(); ok {
+		entry.Token.SetExpiresAt(ts)
+	}
 
 	if len(auth.ModelStates) > 0 {
 		entry.UpdateAllModelStates(func(old *ModelStatesSnapshot) *ModelStatesSnapshot {
@@ -394,28 +456,33 @@ func (r *AuthRegistry) scheduleRefreshIfNeeded(entry *AuthEntry) {
 	}
 
 	meta := entry.Metadata()
-	if meta == nil || meta.Metadata == nil {
+	if meta == nil {
 		return
 	}
 
+	// OAuth credentials carry tokens in metadata; custom executors may instead
+	// expose only an expiry timestamp through attributes.
 	_, hasAccessToken := meta.Metadata["access_token"]
 	_, hasRefreshToken := meta.Metadata["refresh_token"]
-	if !hasAccessToken || !hasRefreshToken {
+	_, hasAttrExpiry := expirationFromAttributes(meta.Attributes)
+	if (!hasAccessToken || !hasRefreshToken) && !hasAttrExpiry {
 		return
 	}
 
-	refreshAt := entry.Token.GetRefreshAt()
-	if refreshAt.IsZero() {
-		expiresAt := entry.Token.GetExpiresAt()
-		if expiresAt.IsZero() {
-			return
-		}
-		refreshAt = expiresAt.Add(-5 * time.Minute)
-		if refreshAt.Before(time.Now()) {
-			refreshAt = time.Now().Add(5 * time.Second)
-		}
-		entry.Token.SetRefreshAt(refreshAt)
+	expiresAt := entry.Token.GetExpiresAt()
+	if expiresAt.IsZero() {
+		return
+	}
+	refreshAt := refreshTimeFor(expiresAt, r.RefreshLead())
+	// A token whose refresh failed waits out its backoff, and one marked
+	// with NextRefreshAfter is not refreshed before then.
+	if retryAt := entry.Token.GetRefreshAt(); entry.Token.Failures.Load() > 0 && retryAt.After(time.Now()) {
+		refreshAt = retryAt
 	}
+	if meta.NextRefreshAfter.After(refreshAt) {
+		refreshAt = meta.NextRefreshAfter
+	}
+	entry.Token.SetRefreshAt(refreshAt)
 
 	r.scheduleRefresh(entry.ID(), refreshAt)
 }
@@ -545,18 +612,24 @@ func (r *AuthRegistry) doRefresh(authID string) {
 				for k, v := range updated.Metadata {
 					newMeta.Metadata[k] = v
 				}
+				if len(updated.Attributes) > 0 {
+					if newMeta.Attributes == nil {
+						newMeta.Attributes = make(map[string]string, len(updated.Attributes))
+					}
+					for k, v := range updated.Attributes {
+						newMeta.Attributes[k] = v
+					}
+				}
 				newMeta.LastRefreshedAt = time.Now()
 				newMeta.LastError = nil
 				newMeta.UpdatedAt = time.Now()
 				return newMeta
 			})
 
-			if ts, ok := expirationFromMap(updated.Metadata); ok {
+			entry.Token.ResetRefreshFailures()
+			if ts, ok := updated.ExpirationTime(); ok {
 				entry.Token.SetExpiresAt(ts)
-				refreshAt := ts.Add(-5 * time.Minute)
-				if refreshAt.Before(time.Now()) {
-					refreshAt = time.Now().Add(5 * time.Second)
-				}
+				refreshAt := refreshTimeFor(ts, r.RefreshLead())
 				entry.Token.SetRefreshAt(refreshAt)
 				r.scheduleRefresh(authID, refreshAt)
 			}
@@ -575,14 +648,19 @@ func (r *AuthRegistry) doRefresh(authID string) {
 		}
 	}
 
-	log.Warnf("auth_registry: failed refresh %s after 3 attempts, retry in 1min", authID)
-	r.scheduleRefresh(authID, time.Now().Add(time.Minute))
+	delay := refreshRetryDelay(entry.Token.RecordRefreshFailure())
+	log.Warnf("auth_registry: failed refresh %s after 3 attempts, retry in %s", authID, delay)
+	retryAt := time.Now().Add(delay)
+	entry.Token.SetRefreshAt(retryAt)
+	r.scheduleRefresh(authID, retryAt)
 }
 
 func (r *AuthRegistry) markDirty(authID string) {
 	select {
 	case r.persistQueue <- authID:
+		metrics.AsyncQueueDepth.Set(float64(len(r.persistQueue)), persistQueueLabels)
 	default:
+		metrics.AsyncQueueDropped.Inc(persistQueueLabels)
 	}
 }
 
@@ -595,9 +673,21 @@ func (r *AuthRegistry) persistLoop() {
 	for {
 		select {
 		case <-r.stopCh:
+			// Take ids still queued by results recorded just before Stop.
+			for drained := false; !drained; {
+				select {
+				case id := <-r.persistQueue:
+					r.persistMu.Lock()
+					r.persistBatch[id] = struct{}{}
+					r.persistMu.Unlock()
+				default:
+					drained = true
+				}
+			}
 			r.flushPending()
 			return
 		case id := <-r.persistQueue:
+			metrics.AsyncQueueDepth.Set(float64(len(r.persistQueue)), persistQueueLabels)
 			r.persistMu.Lock()
 			r.persistBatch[id] = struct{}{}
 			r.persistMu.Unlock()
@@ -663,6 +753,12 @@ func (r *AuthRegistry) MarkResult(ctx context.Context, result Result) {
 		entry.DecrementActiveRequests()
 	}
 
+	// A client disconnect says nothing about the auth's health
+	if result.Canceled {
+		r.hook.OnResult(ctx, result)
+		return
+	}
+
 	if result.Success {
 		r.handleSuccessResult(ctx, entry, result, now)
 	} else {
@@ -736,6 +832,14 @@ func (r *AuthRegistry) handleFailureResult(ctx context.Context, entry *AuthEntry
 			newState.StatusMessage = result.Error.Message
 		}
 
+		// Billing exhaustion often arrives as a 429 but will not clear on
+		// its own, so it skips the quota backoff.
+		if category == CategoryBillingError {
+			newState.StatusMessage = BillingStatusMessage
+			newState.NextRetryAfter = now.Add(billingSuspension).UnixNano()
+			return newState
+		}
+
 		switch statusCode {
 		case 401:
 			newState.NextRetryAfter = now.Add(30 * time.Minute).UnixNano()
@@ -745,18 +849,15 @@ func (r *AuthRegistry) handleFailureResult(ctx context.Context, entry *AuthEntry
 			newState.NextRetryAfter = now.Add(12 * time.Hour).UnixNano()
 		case 429:
 			var next time.Time
-			if result.RetryAfter != nil {
-				next = now.Add(*result.RetryAfter)
-			} else {
-				cooldown, nextLevel := nextQuotaCooldown(old.BackoffLevel)
-				if cooldown > 0 {
-					next = now.Add(cooldown)
-				}
-				newState.BackoffLevel = nextLevel
+			scope := ClassifyQuotaScope(errMsg)
+			cooldown, nextLevel := quotaCooldown(result.RetryAfter, scope, old.BackoffLevel)
+			if cooldown > 0 {
+				next = now.Add(cooldown)
 			}
+			newState.BackoffLevel = nextLevel
 			newState.NextRetryAfter = next.UnixNano()
 			newState.QuotaExceeded = true
-			newState.QuotaReason = "quota"
+			newState.QuotaReason = scope.String()
 			newState.QuotaRecover = next.UnixNano()
 		case 408, 500, 502, 503, 504:
 			newState.NextRetryAfter = now.Add(time.Minute).UnixNano()
@@ -780,6 +881,9 @@ func (r *AuthRegistry) handleFailureResult(ctx context.Context, entry *AuthEntry
 			}
 			newMeta.StatusMessage = result.Error.Message
 		}
+		if category == CategoryBillingError {
+			newMeta.StatusMessage = BillingStatusMessage
+		}
 		return newMeta
 	})
 }
@@ -793,6 +897,10 @@ func (r *AuthRegistry) applyAuthLevelFailure(entry *AuthEntry, result Result, no
 	} else if result.Error != nil {
 		category = CategorizeError(result.Error.StatusCode(), result.Error.Message)
 	}
+	scope := QuotaScopeUnknown
+	if category == CategoryQuotaError && result.Error != nil {
+		scope = ClassifyQuotaScope(result.Error.Message)
+	}
 
 	entry.UpdateMetadata(func(old *AuthMetadata) *AuthMetadata {
 		newMeta := old.Clone()
@@ -819,15 +927,13 @@ func (r *AuthRegistry) applyAuthLevelFailure(entry *AuthEntry, result Result, no
 		case CategoryQuotaError:
 			newMeta.StatusMessage = "quota exhausted"
 			var next time.Time
-			if result.RetryAfter != nil {
-				next = now.Add(*result.RetryAfter)
-			} else {
-				cooldown, _ := nextQuotaCooldown(0)
-				if cooldown > 0 {
-					next = now.Add(cooldown)
-				}
+			if cooldown, _ := quotaCooldown(result.RetryAfter, scope, 0); cooldown > 0 {
+				next = now.Add(cooldown)
 			}
 			newMeta.NextRetryAfter = next
+		case CategoryBillingError:
+			newMeta.StatusMessage = BillingStatusMessage
+			newMeta.NextRetryAfter = now.Add(billingSuspension)
 		case CategoryNotFound:
 			newMeta.StatusMessage = "not_found"
 			newMeta.NextRetryAfter = now.Add(12 * time.Hour)
@@ -851,14 +957,8 @@ func (r *AuthRegistry) applyAuthLevelFailure(entry *AuthEntry, result Result, no
 	}
 
 	if category == CategoryQuotaError {
-		var cooldownUntil time.Time
-		if result.RetryAfter != nil {
-			cooldownUntil = now.Add(*result.RetryAfter)
-		} else {
-			cooldown, _ := nextQuotaCooldown(0)
-			cooldownUntil = now.Add(cooldown)
-		}
-		entry.SetCooldown(cooldownUntil)
+		cooldown, _ := quotaCooldown(result.RetryAfter, scope, 0)
+		entry.SetCooldown(now.Add(cooldown))
 	}
 }
 
@@ -907,11 +1007,14 @@ func (r *AuthRegistry) Pick(ctx context.Context, provider, model string, opts Op
 			if resetIn < 0 {
 				resetIn = 0
 			}
+			metrics.SelectorPicks.Inc(metrics.L(provider, "cooldown"))
 			return nil, newModelCooldownError(model, provider, resetIn)
 		}
+		metrics.SelectorPicks.Inc(metrics.L(provider, "unavailable"))
 		return nil, &Error{Code: "auth_unavailable", Message: "no auth available"}
 	}
 
+	metrics.SelectorPicks.Inc(metrics.L(provider, "selected"))
 	if len(available) == 1 {
 		available[0].IncrementActiveRequests()
 		return available[0], nil
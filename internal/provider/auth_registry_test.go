@@ -463,6 +463,39 @@ func TestAuthRegistry_MarkResultFailure(t *testing.T) {
 	}
 }
 
+func TestAuthRegistry_MarkResultCanceled(t *testing.T) {
+	registry := NewAuthRegistry(nil, nil)
+	ctx := context.Background()
+
+	auth := &Auth{
+		ID:       "mark-canceled",
+		Provider: "claude",
+		Status:   StatusActive,
+	}
+	_, _ = registry.Register(ctx, auth)
+
+	entries := registry.ListByProvider("claude")
+	entry, err := registry.Pick(ctx, "claude", "claude-3-opus", Options{}, entries)
+	if err != nil {
+		t.Fatalf("Pick failed: %v", err)
+	}
+
+	registry.MarkResult(ctx, Result{
+		AuthID:   "mark-canceled",
+		Provider: "claude",
+		Canceled: true,
+		Error:    &Error{Code: "client_canceled", Message: "context canceled"},
+	})
+
+	if active := entry.Quota.ActiveRequests.Load(); active != 0 {
+		t.Errorf("Expected the canceled request to release its slot, got %d active", active)
+	}
+	meta := entry.Metadata()
+	if meta.Status != StatusActive || meta.LastError != nil {
+		t.Errorf("Expected a client disconnect not to mark the auth, got status=%v error=%v", meta.Status, meta.LastError)
+	}
+}
+
 func TestAuthMetadata_Clone(t *testing.T) {
 	original := &AuthMetadata{
 		Label:      "Test",
@@ -559,3 +592,101 @@ func BenchmarkAuthEntry_QuotaOperations(b *testing.B) {
 		}
 	})
 }
+
+func TestAuthRegistry_ScheduleRefreshFromAttributes(t *testing.T) {
+	registry := NewAuthRegistry(nil, nil)
+	registry.SetRefreshLead(10 * time.Minute)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	auth := &Auth{
+		ID:         "attr-expiry",
+		Provider:   "custom",
+		Attributes: map[string]string{"expires_at": expiresAt.Format(time.RFC3339)},
+	}
+	_, _ = registry.Register(ctx, auth)
+
+	entry := registry.GetEntry("attr-expiry")
+	if got := entry.Token.GetExpiresAt(); !got.Equal(expiresAt) {
+		t.Fatalf("Expected expiry %v, got %v", expiresAt, got)
+	}
+	want := expiresAt.Add(-10 * time.Minute)
+	if got := entry.Token.GetRefreshAt(); !got.Equal(want) {
+		t.Errorf("Expected refresh at %v, got %v", want, got)
+	}
+
+	registry.refreshMu.Lock()
+	_, scheduled := registry.refreshEntries["attr-expiry"]
+	registry.refreshMu.Unlock()
+	if !scheduled {
+		t.Error("Expected refresh to be scheduled for attribute expiry")
+	}
+}
+
+func TestRefreshRetryDelay(t *testing.T) {
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{10, refreshRetryMax},
+	}
+	for _, tc := range cases {
+		if got := refreshRetryDelay(tc.failures); got != tc.want {
+			t.Errorf("refreshRetryDelay(%d) = %v, want %v", tc.failures, got, tc.want)
+		}
+	}
+}
+
+func TestAuthRegistry_UpdateKeepsRefreshBackoff(t *testing.T) {
+	registry := NewAuthRegistry(nil, nil)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour)
+	auth := &Auth{
+		ID:       "backoff",
+		Provider: "custom",
+		Metadata: map[string]any{"access_token": "a", "refresh_token": "r", "expires_at": expiresAt.Format(time.RFC3339)},
+	}
+	_, _ = registry.Register(ctx, auth)
+
+	// A failed refresh backs off past the time the lead alone would give.
+	entry := registry.GetEntry("backoff")
+	entry.Token.RecordRefreshFailure()
+	retryAt := time.Now().Add(2 * time.Hour)
+	entry.Token.SetRefreshAt(retryAt)
+
+	_, _ = registry.Update(ctx, auth.Clone())
+	if got := entry.Token.GetRefreshAt(); !got.Equal(retryAt) {
+		t.Errorf("Expected the backoff refresh time %v to survive Update, got %v", retryAt, got)
+	}
+
+	next := time.Now().Add(3 * time.Hour)
+	updated := auth.Clone()
+	updated.NextRefreshAfter = next
+	_, _ = registry.Update(ctx, updated)
+	if got := entry.Token.GetRefreshAt(); !got.Equal(next) {
+		t.Errorf("Expected refresh at NextRefreshAfter %v, got %v", next, got)
+	}
+}
+
+func TestManager_ShouldRefreshUsesConfiguredLead(t *testing.T) {
+	RegisterRefreshLeadProvider("lead-test", func() *time.Duration {
+		lead := time.Minute
+		return &lead
+	})
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+
+	now := time.Now()
+	auth := &Auth{ID: "lead", Provider: "lead-test", Metadata: map[string]any{"expires_at": now.Add(10 * time.Minute).Format(time.RFC3339)}}
+	if m.shouldRefresh(auth, now) {
+		t.Error("Expected no refresh outside the provider lead")
+	}
+	m.SetRefreshLead(15 * time.Minute)
+	if !m.shouldRefresh(auth, now) {
+		t.Error("Expected refresh inside the configured lead")
+	}
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedContentMetadataKey is the request metadata key carrying the Gemini
+// cachedContent name the client reuses. A cached content is readable only by
+// the project that created it, so requests naming one prefer that credential.
+const CachedContentMetadataKey = "gemini_cached_content"
+
+const (
+	// cachedContentTTL matches the default lifetime of a Gemini cached content.
+	cachedContentTTL = time.Hour
+
+	// maxCachedContentEntries bounds the remembered cache owners.
+	maxCachedContentEntries = 4096
+)
+
+// cachedContentAffinity remembers which credential last served a request
+// naming each cached content.
+type cachedContentAffinity struct {
+	mu      sync.Mutex
+	entries map[string]cachedContentOwner
+}
+
+type cachedContentOwner struct {
+	authID  string
+	expires time.Time
+}
+
+func (a *cachedContentAffinity) get(key string, now time.Time) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	owner, ok := a.entries[key]
+	if !ok {
+		return ""
+	}
+	if now.After(owner.expires) {
+		delete(a.entries, key)
+		return ""
+	}
+	return owner.authID
+}
+
+func (a *cachedContentAffinity) set(key, authID string, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.entries == nil {
+		a.entries = make(map[string]cachedContentOwner)
+	}
+	if _, ok := a.entries[key]; !ok && len(a.entries) >= maxCachedContentEntries {
+		for k, owner := range a.entries {
+			if now.After(owner.expires) || len(a.entries) >= maxCachedContentEntries {
+				delete(a.entries, k)
+			}
+		}
+	}
+	a.entries[key] = cachedContentOwner{authID: authID, expires: now.Add(cachedContentTTL)}
+}
+
+// cachedContentName returns the cachedContent name recorded in opts, if any.
+func cachedContentName(opts Options) string {
+	name, _ := opts.Metadata[CachedContentMetadataKey].(string)
+	return strings.TrimSpace(name)
+}
+
+// cachedContentProject returns the project of a Vertex cached content name
+// ("projects/{project}/locations/{location}/cachedContents/{id}").
+func cachedContentProject(name string) string {
+	rest, ok := strings.CutPrefix(name, "projects/")
+	if !ok {
+		return ""
+	}
+	project, _, _ := strings.Cut(rest, "/")
+	return project
+}
+
+// authProject returns the Google Cloud project a credential is bound to.
+func authProject(metadata map[string]any) string {
+	for _, key := range []string{"project_id", "project"} {
+		if v, ok := metadata[key].(string); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// cachedContentOwnerID returns the credential that owns the cached content
+// named in opts: the one whose project appears in the name, or else the one
+// that last served it. project reports the project of a candidate ID.
+func (m *Manager) cachedContentOwnerID(provider string, opts Options, ids []string, project func(int) string) string {
+	name := cachedContentName(opts)
+	if name == "" {
+		return ""
+	}
+	if want := cachedContentProject(name); want != "" {
+		for i, id := range ids {
+			if project(i) == want {
+				return id
+			}
+		}
+	}
+	return m.cachedContent.get(provider+":"+name, time.Now())
+}
+
+// rememberCachedContent records authID as the owner of the cached content
+// named in opts after it served the request.
+func (m *Manager) rememberCachedContent(provider string, opts Options, authID string) {
+	if name := cachedContentName(opts); name != "" {
+		m.cachedContent.set(provider+":"+name, authID, time.Now())
+	}
+}
+
+// preferCachedContentAuths narrows candidates to the owner of the cached
+// content named in opts when it is among them.
+func (m *Manager) preferCachedContentAuths(provider string, opts Options, candidates []*Auth) []*Auth {
+	if cachedContentName(opts) == "" || len(candidates) < 2 {
+		return candidates
+	}
+	ids := make([]string, len(candidates))
+	for i, auth := range candidates {
+		ids[i] = auth.ID
+	}
+	owner := m.cachedContentOwnerID(provider, opts, ids, func(i int) string { return authProject(candidates[i].Metadata) })
+	for _, auth := range candidates {
+		if owner != "" && auth.ID == owner {
+			return []*Auth{auth}
+		}
+	}
+	return candidates
+}
+
+// preferCachedContentEntries is preferCachedContentAuths for registry entries.
+func (m *Manager) preferCachedContentEntries(provider string, opts Options, entries []*AuthEntry) []*AuthEntry {
+	if cachedContentName(opts) == "" || len(entries) < 2 {
+		return entries
+	}
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID()
+	}
+	owner := m.cachedContentOwnerID(provider, opts, ids, func(i int) string {
+		if meta := entries[i].Metadata(); meta != nil {
+			return authProject(meta.Metadata)
+		}
+		return ""
+	})
+	for _, entry := range entries {
+		if owner != "" && entry.ID() == owner {
+			return []*AuthEntry{entry}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,36 @@
+package provider
+
+import "testing"
+
+func TestPreferCachedContentAuths(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	a := &Auth{ID: "a", Metadata: map[string]any{"project_id": "p1"}}
+	b := &Auth{ID: "b", Metadata: map[string]any{"project_id": "p2"}}
+	candidates := []*Auth{a, b}
+
+	if got := m.preferCachedContentAuths("gemini", Options{}, candidates); len(got) != 2 {
+		t.Fatalf("without cachedContent got %d candidates, want 2", len(got))
+	}
+
+	byProject := Options{Metadata: map[string]any{CachedContentMetadataKey: "projects/p2/locations/us-central1/cachedContents/x"}}
+	if got := m.preferCachedContentAuths("gemini", byProject, candidates); len(got) != 1 || got[0] != b {
+		t.Fatalf("project-scoped name should prefer b, got %v", got)
+	}
+
+	byOwner := Options{Metadata: map[string]any{CachedContentMetadataKey: "cachedContents/y"}}
+	if got := m.preferCachedContentAuths("gemini", byOwner, candidates); len(got) != 2 {
+		t.Fatalf("unknown owner should keep all candidates, got %d", len(got))
+	}
+	m.rememberCachedContent("gemini", byOwner, "a")
+	if got := m.preferCachedContentAuths("gemini", byOwner, candidates); len(got) != 1 || got[0] != a {
+		t.Fatalf("remembered owner should prefer a, got %v", got)
+	}
+	if got := m.preferCachedContentAuths("vertex", byOwner, candidates); len(got) != 2 {
+		t.Fatalf("owner is remembered per provider, got %d", len(got))
+	}
+
+	entries := []*AuthEntry{NewAuthEntry(a), NewAuthEntry(b)}
+	if got := m.preferCachedContentEntries("gemini", byProject, entries); len(got) != 1 || got[0].ID() != "b" {
+		t.Fatalf("registry: project-scoped name should prefer b, got %d entries", len(got))
+	}
+}
@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ConcurrencyLimit caps in-flight requests for a model, either across all
+// accounts or for each account separately.
+type ConcurrencyLimit struct {
+	Model      string
+	Max        int
+	PerAccount bool
+}
+
+// ConcurrencyStats reports the saturation of one concurrency slot. AuthID is
+// empty for limits shared across accounts.
+type ConcurrencyStats struct {
+	Model    string `json:"model"`
+	AuthID   string `json:"auth_id,omitempty"`
+	Limit    int    `json:"limit"`
+	InFlight int64  `json:"in_flight"`
+	Waiting  int64  `json:"waiting"`
+}
+
+// concurrencyLimiter enforces ConcurrencyLimits around executor calls. Slots
+// are created lazily per model, or per account and model, and replaced when
+// limits change; requests holding an old slot release into it.
+type concurrencyLimiter struct {
+	reject atomic.Bool
+
+	mu     sync.Mutex
+	limits map[string]ConcurrencyLimit
+	slots  map[string]*concurrencySlot
+}
+
+type concurrencySlot struct {
+	modelKey string
+	model    string
+	authID   string
+	limit    int
+	sem      *semaphore.Weighted
+	inFlight atomic.Int64
+	waiting  atomic.Int64
+}
+
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{
+		limits: make(map[string]ConcurrencyLimit),
+		slots:  make(map[string]*concurrencySlot),
+	}
+}
+
+func (l *concurrencyLimiter) configure(limits []ConcurrencyLimit, reject bool) {
+	next := make(map[string]ConcurrencyLimit, len(limits))
+	for _, limit := range limits {
+		key := strings.ToLower(strings.TrimSpace(limit.Model))
+		if key == "" || limit.Max <= 0 {
+			continue
+		}
+		next[key] = limit
+	}
+	l.mu.Lock()
+	// Keep slots whose limit is unchanged so in-flight counts survive reloads.
+	for key, s := range l.slots {
+		if limit, ok := next[s.modelKey]; !ok || limit.Max != s.limit || (limit.PerAccount != (s.authID != "")) {
+			delete(l.slots, key)
+		}
+	}
+	l.limits = next
+	l.mu.Unlock()
+	l.reject.Store(reject)
+}
+
+// slot returns the slot governing authID for the first of models that has a
+// limit, or nil when none is limited.
+func (l *concurrencyLimiter) slot(authID string, models ...string) *concurrencySlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.limits) == 0 {
+		return nil
+	}
+	for _, model := range models {
+		modelKey := strings.ToLower(strings.TrimSpace(model))
+		limit, ok := l.limits[modelKey]
+		if !ok {
+			continue
+		}
+		key, slotAuth := modelKey, ""
+		if limit.PerAccount {
+			key, slotAuth = authID+"|"+modelKey, authID
+		}
+		s := l.slots[key]
+		if s == nil {
+			s = &concurrencySlot{modelKey: modelKey, model: limit.Model, authID: slotAuth, limit: limit.Max, sem: semaphore.NewWeighted(int64(limit.Max))}
+			l.slots[key] = s
+		}
+		return s
+	}
+	return nil
+}
+
+// forget drops the per-account slots of authID. Requests still holding one
+// release into it as usual.
+func (l *concurrencyLimiter) forget(authID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, s := range l.slots {
+		if s.authID == authID {
+			delete(l.slots, key)
+		}
+	}
+}
+
+// acquire reserves an in-flight slot for authID under the first limited model
+// name. It waits in FIFO order under the queue policy and fails with 429 under
+// the reject policy. The returned release must be called exactly once.
+func (l *concurrencyLimiter) acquire(ctx context.Context, authID string, models ...string) (func(), error) {
+	s := l.slot(authID, models...)
+	if s == nil {
+		return func() {}, nil
+	}
+	if l.reject.Load() {
+		if !s.sem.TryAcquire(1) {
+			return nil, &Error{
+				Code:       "concurrency_limit",
+				Message:    fmt.Sprintf("model %s has reached its limit of %d concurrent requests", s.model, s.limit),
+				HTTPStatus: http.StatusTooManyRequests,
+			}
+		}
+	} else {
+		s.waiting.Add(1)
+		err := s.sem.Acquire(ctx, 1)
+		s.waiting.Add(-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.inFlight.Add(-1)
+			s.sem.Release(1)
+		})
+	}, nil
+}
+
+func (l *concurrencyLimiter) snapshot() []ConcurrencyStats {
+	l.mu.Lock()
+	stats := make([]ConcurrencyStats, 0, len(l.slots))
+	for _, s := range l.slots {
+		stats = append(stats, ConcurrencyStats{
+			Model:    s.model,
+			AuthID:   s.authID,
+			Limit:    s.limit,
+			InFlight: s.inFlight.Load(),
+			Waiting:  s.waiting.Load(),
+		})
+	}
+	l.mu.Unlock()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Model != stats[j].Model {
+			return stats[i].Model < stats[j].Model
+		}
+		return stats[i].AuthID < stats[j].AuthID
+	})
+	return stats
+}
+
+// SetConcurrencyLimits replaces the per-model in-flight limits. With reject
+// set, requests over a limit fail with 429 instead of waiting for a slot.
+func (m *Manager) SetConcurrencyLimits(limits []ConcurrencyLimit, reject bool) {
+	if m == nil {
+		return
+	}
+	m.concurrency.configure(limits, reject)
+}
+
+// ConcurrencySnapshot returns in-flight and waiting counts for every limited
+// model or account and model seen since the limits were last set. Accounts
+// drop out once removed.
+func (m *Manager) ConcurrencySnapshot() []ConcurrencyStats {
+	if m == nil {
+		return nil
+	}
+	return m.concurrency.snapshot()
+}
+
+// acquireConcurrency reserves a concurrency slot for a picked auth. When no
+// slot is granted the pick is undone so the auth's active count stays accurate.
+func (m *Manager) acquireConcurrency(ctx context.Context, authID string, models ...string) (func(), error) {
+	release, err := m.concurrency.acquire(ctx, authID, models...)
+	if err != nil && m.registry != nil {
+		if entry := m.registry.GetEntry(authID); entry != nil {
+			entry.DecrementActiveRequests()
+		}
+	}
+	return release, err
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestConcurrencyLimiter_QueuePolicy(t *testing.T) {
+	l := newConcurrencyLimiter()
+	l.configure([]ConcurrencyLimit{{Model: "Slow-Model", Max: 1}}, false)
+
+	release, err := l.acquire(context.Background(), "a1", "slow-model")
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+
+	acquired := make(chan func(), 1)
+	go func() {
+		next, errNext := l.acquire(context.Background(), "a2", "slow-model")
+		if errNext != nil {
+			t.Errorf("queued acquire failed: %v", errNext)
+		}
+		acquired <- next
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for l.snapshot()[0].Waiting != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats := l.snapshot()
+	if len(stats) != 1 || stats[0].InFlight != 1 || stats[0].Waiting != 1 || stats[0].Limit != 1 {
+		t.Fatalf("Expected one in flight and one waiting, got %+v", stats)
+	}
+
+	release()
+	release() // releasing twice must not free a second slot
+	next := <-acquired
+	if got := l.snapshot()[0]; got.InFlight != 1 || got.Waiting != 0 {
+		t.Errorf("Expected queued request to take the slot, got %+v", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, "a3", "slow-model"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected waiting to honour the context, got %v", err)
+	}
+	next()
+
+	if rel, err := l.acquire(context.Background(), "a1", "other-model"); err != nil {
+		t.Errorf("Expected unlimited model to pass, got %v", err)
+	} else {
+		rel()
+	}
+}
+
+func TestConcurrencyLimiter_RejectPerAccount(t *testing.T) {
+	l := newConcurrencyLimiter()
+	l.configure([]ConcurrencyLimit{{Model: "m", Max: 1, PerAccount: true}}, true)
+
+	r1, err := l.acquire(context.Background(), "a1", "m")
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	_, err = l.acquire(context.Background(), "a1", "m")
+	if statusCodeFromError(err) != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 for saturated account, got %v", err)
+	}
+	r2, err := l.acquire(context.Background(), "a2", "m")
+	if err != nil {
+		t.Fatalf("Expected a separate slot for another account, got %v", err)
+	}
+
+	// Reloading with the same limits keeps in-flight counts.
+	l.configure([]ConcurrencyLimit{{Model: "m", Max: 1, PerAccount: true}}, true)
+	if _, err := l.acquire(context.Background(), "a1", "m"); err == nil {
+		t.Error("Expected reload to keep the saturated slot")
+	}
+	r1()
+	r2()
+	for _, s := range l.snapshot() {
+		if s.InFlight != 0 {
+			t.Errorf("Expected slots drained, got %+v", s)
+		}
+	}
+
+	// A removed account's slot is dropped.
+	l.forget("a1")
+	for _, s := range l.snapshot() {
+		if s.AuthID == "a1" {
+			t.Errorf("Expected removed account's slot dropped, got %+v", s)
+		}
+	}
+	if len(l.snapshot()) != 1 {
+		t.Errorf("Expected the other account's slot kept, got %+v", l.snapshot())
+	}
+}
+
+// gateExecutor blocks each call until the test releases it.
+type gateExecutor struct {
+	gate    chan struct{}
+	started chan string
+	active  atomic.Int32
+	peak    atomic.Int32
+}
+
+func (e *gateExecutor) Identifier() string { return "gatetest" }
+func (e *gateExecutor) Execute(_ context.Context, auth *Auth, _ Request, _ Options) (Response, error) {
+	n := e.active.Add(1)
+	defer e.active.Add(-1)
+	for {
+		peak := e.peak.Load()
+		if n <= peak || e.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	e.started <- auth.ID
+	<-e.gate
+	return Response{Payload: []byte(`{}`)}, nil
+}
+func (e *gateExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+func (e *gateExecutor) Refresh(_ context.Context, a *Auth) (*Auth, error) { return a, nil }
+func (e *gateExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func TestManager_ConcurrencyLimitSpillsToOtherAccount(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	exec := &gateExecutor{gate: make(chan struct{}), started: make(chan string, 4)}
+	m.RegisterExecutor(exec)
+	for _, id := range []string{"gate-a", "gate-b"} {
+		registry.GetGlobalRegistry().RegisterClient(id, "gatetest", []*registry.ModelInfo{{ID: "gate-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "gatetest"}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+	m.SetConcurrencyLimits([]ConcurrencyLimit{{Model: "gate-model", Max: 1, PerAccount: true}}, true)
+
+	errs := make(chan error, 3)
+	run := func() {
+		_, err := m.Execute(context.Background(), []string{"gatetest"}, Request{Model: "gate-model"}, Options{})
+		errs <- err
+	}
+	go run()
+	go run()
+	first, second := <-exec.started, <-exec.started
+	if first == second {
+		t.Fatalf("Expected requests on different accounts, both ran on %s", first)
+	}
+
+	go run()
+	err := <-errs
+	if statusCodeFromError(err) != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once every account is saturated, got %v", err)
+	}
+
+	close(exec.gate)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Expected in-flight request to succeed, got %v", err)
+		}
+	}
+	if peak := exec.peak.Load(); peak != 2 {
+		t.Errorf("Expected peak concurrency 2, got %d", peak)
+	}
+	for _, s := range m.ConcurrencySnapshot() {
+		if s.InFlight != 0 || s.AuthID == "" {
+			t.Errorf("Expected drained per-account slots, got %+v", s)
+		}
+	}
+}
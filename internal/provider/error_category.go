@@ -3,6 +3,21 @@ package provider
 import (
 	"net/http"
 	"strings"
+	"time"
+)
+
+const (
+	// ErrCodeContextLengthExceeded is the error.code reported to clients for
+	// requests over the model's context window.
+	ErrCodeContextLengthExceeded = "context_length_exceeded"
+
+	// BillingStatusMessage is the status message, and registry suspend
+	// reason, of accounts suspended because they ran out of credits.
+	BillingStatusMessage = "insufficient_quota"
+
+	// billingSuspension matches the 402/403 suspension: billing exhaustion
+	// only clears once the operator adds credits.
+	billingSuspension = 30 * time.Minute
 )
 
 // ErrorCategory classifies errors for retry/fallback decisions
@@ -28,6 +43,11 @@ const (
 	// Should wait cooldown, then retry or fallback to another auth
 	CategoryQuotaError
 
+	// CategoryBillingError indicates the account has run out of credits or has
+	// no active billing. Unlike rate limits it persists until topped up
+	// Should suspend auth for a long time and fallback to another auth
+	CategoryBillingError
+
 	// CategoryTransient indicates temporary server-side errors
 	// Should retry with exponential backoff
 	CategoryTransient
@@ -52,6 +72,8 @@ func (c ErrorCategory) String() string {
 		return "auth_revoked"
 	case CategoryQuotaError:
 		return "quota_error"
+	case CategoryBillingError:
+		return "billing_error"
 	case CategoryTransient:
 		return "transient"
 	case CategoryNotFound:
@@ -63,9 +85,52 @@ func (c ErrorCategory) String() string {
 	}
 }
 
+// ErrorType returns the OpenAI-style error.type reported to clients for the
+// category. The names match Anthropic's error types as well.
+func (c ErrorCategory) ErrorType() string {
+	switch c {
+	case CategoryUserError, CategoryClientCanceled:
+		return "invalid_request_error"
+	case CategoryAuthError, CategoryAuthRevoked:
+		return "authentication_error"
+	case CategoryQuotaError:
+		return "rate_limit_error"
+	case CategoryBillingError:
+		return "insufficient_quota"
+	case CategoryNotFound:
+		return "not_found_error"
+	default:
+		return "api_error"
+	}
+}
+
+// ErrorCode returns the error.code reported to clients for the category.
+func (c ErrorCategory) ErrorCode() string {
+	switch c {
+	case CategoryUserError:
+		return "invalid_request"
+	case CategoryAuthError:
+		return "upstream_authentication_failed"
+	case CategoryAuthRevoked:
+		return "upstream_credentials_revoked"
+	case CategoryQuotaError:
+		return "rate_limit_exceeded"
+	case CategoryBillingError:
+		return "insufficient_quota"
+	case CategoryTransient:
+		return "upstream_unavailable"
+	case CategoryNotFound:
+		return "not_found"
+	case CategoryClientCanceled:
+		return "request_canceled"
+	default:
+		return "internal_error"
+	}
+}
+
 // ShouldFallback returns true if should try another auth/provider
 func (c ErrorCategory) ShouldFallback() bool {
-	return c == CategoryQuotaError || c == CategoryTransient || c == CategoryAuthError
+	return c == CategoryQuotaError || c == CategoryBillingError || c == CategoryTransient || c == CategoryAuthError
 }
 
 // ShouldDisableAuth returns true if auth should be disabled
@@ -75,7 +140,7 @@ func (c ErrorCategory) ShouldDisableAuth() bool {
 
 // ShouldSuspendAuth returns true if auth should be temporarily suspended
 func (c ErrorCategory) ShouldSuspendAuth() bool {
-	return c == CategoryAuthError || c == CategoryQuotaError
+	return c == CategoryAuthError || c == CategoryQuotaError || c == CategoryBillingError
 }
 
 // IsUserFault returns true if error is caused by user's request
@@ -124,11 +189,21 @@ func CategorizeError(statusCode int, message string) ErrorCategory {
 		return CategoryAuthRevoked
 	}
 
+	// A request over the context window fails the same way on every account
+	if IsContextLengthError(message) {
+		return CategoryUserError
+	}
+
 	// Check for user errors in message
 	if isUserError(message) {
 		return CategoryUserError
 	}
 
+	// Check for billing exhaustion before quota errors: both mention quota
+	if isBillingError(message) {
+		return CategoryBillingError
+	}
+
 	// Check for quota errors in message
 	if isQuotaError(message) {
 		return CategoryQuotaError
@@ -167,6 +242,24 @@ func isUserError(msg string) bool {
 		strings.Contains(lower, "cannot be empty")
 }
 
+// IsContextLengthError reports whether msg is a provider's rejection of a
+// request whose input exceeds the model's context window.
+func IsContextLengthError(msg string) bool {
+	if msg == "" {
+		return false
+	}
+	lower := strings.ToLower(msg)
+	return strings.Contains(lower, ErrCodeContextLengthExceeded) || // OpenAI, Codex
+		strings.Contains(lower, "maximum context length") || // OpenAI-compatible
+		strings.Contains(lower, "exceeds the context window") || // Responses API
+		strings.Contains(lower, "prompt is too long") || // Anthropic
+		(strings.Contains(lower, "input token count") && strings.Contains(lower, "exceeds the maximum")) || // Gemini
+		strings.Contains(lower, "input is too long") || // Kiro, Bedrock
+		strings.Contains(lower, "content_length_exceeds_threshold") || // Kiro
+		strings.Contains(lower, "model_max_prompt_tokens_exceeded") || // Copilot
+		strings.Contains(lower, "range of input length should be") // Qwen
+}
+
 // isQuotaError checks if message indicates quota/rate limit error
 func isQuotaError(msg string) bool {
 	if msg == "" {
@@ -178,10 +271,33 @@ func isQuotaError(msg string) bool {
 		strings.Contains(lower, "rate limit") ||
 		strings.Contains(lower, "rate_limit_error") ||
 		strings.Contains(lower, "too many requests") ||
-		strings.Contains(lower, "credit balance is too low") ||
 		strings.Contains(lower, "overloaded_error")
 }
 
+// isBillingError checks if message indicates the account is out of credits or
+// has no active billing (OpenAI insufficient_quota, Anthropic credit balance,
+// prepaid balance exhaustion on OpenAI-compatible providers). Gemini's
+// "exceeded your current quota ... billing details" wording is used for plain
+// rate limits and is deliberately not matched.
+func isBillingError(msg string) bool {
+	if msg == "" {
+		return false
+	}
+	lower := strings.ToLower(msg)
+	return strings.Contains(lower, "insufficient_quota") ||
+		strings.Contains(lower, "billing_hard_limit_reached") ||
+		strings.Contains(lower, "billing_not_active") ||
+		strings.Contains(lower, "credit balance is too low") ||
+		strings.Contains(lower, "insufficient balance") ||
+		strings.Contains(lower, "insufficient credits")
+}
+
+// isQuotaHit reports whether err is a rate limit the quota manager should
+// track. Billing exhaustion is suspended separately.
+func isQuotaHit(err *Error) bool {
+	return err != nil && err.HTTPStatus == http.StatusTooManyRequests && !isBillingError(err.Message)
+}
+
 func isContextCanceledError(msg string) bool {
 	if msg == "" {
 		return false
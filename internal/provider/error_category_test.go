@@ -0,0 +1,39 @@
+package provider
+
+import "testing"
+
+func TestCategorizeError_ContextLength(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"openai", 400, `{"error":{"message":"This model's maximum context length is 128000 tokens. However, your messages resulted in 130512 tokens. Please reduce the length of the messages.","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`},
+		{"codex responses", 400, `{"error":{"message":"Your input exceeds the context window of this model. Please adjust your input and try again.","type":"invalid_request_error","param":"input","code":"context_length_exceeded"}}`},
+		{"anthropic", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 215000 tokens > 200000 maximum"}}`},
+		{"gemini", 400, `{"error":{"code":400,"message":"The input token count (1200000) exceeds the maximum number of tokens allowed (1048576).","status":"INVALID_ARGUMENT"}}`},
+		{"kiro", 400, `{"message":"Input is too long for requested model.","reason":"CONTENT_LENGTH_EXCEEDS_THRESHOLD"}`},
+		{"copilot", 400, `{"error":{"message":"prompt token count of 140000 exceeds the limit of 128000","code":"model_max_prompt_tokens_exceeded"}}`},
+		{"qwen", 400, `{"code":"InvalidParameter","message":"Range of input length should be [1, 129024]"}`},
+		{"overflow behind a server error status", 500, `{"error":{"message":"prompt is too long: 215000 tokens > 200000 maximum"}}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !IsContextLengthError(tc.body) {
+				t.Errorf("IsContextLengthError() = false for %s", tc.body)
+			}
+			if got := CategorizeError(tc.status, tc.body); got != CategoryUserError {
+				t.Errorf("CategorizeError() = %v, want %v", got, CategoryUserError)
+			}
+		})
+	}
+
+	// A per-minute token limit mentions tokens but is a rate limit.
+	tpm := `{"error":{"message":"Request too large for gpt-4o in organization org-x on tokens per min (TPM): Limit 30000, Requested 45000.","type":"tokens","code":"rate_limit_exceeded"}}`
+	if IsContextLengthError(tpm) {
+		t.Error("IsContextLengthError() = true for a tokens-per-minute limit")
+	}
+	if got := CategorizeError(429, tpm); got != CategoryQuotaError {
+		t.Errorf("CategorizeError(tpm) = %v, want %v", got, CategoryQuotaError)
+	}
+}
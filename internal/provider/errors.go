@@ -40,3 +40,15 @@ func (e *Error) Category() ErrorCategory {
 	}
 	return e.ErrCategory
 }
+
+// NoAccountAvailable reports whether err means no account could take the
+// request at all, because none is registered, all are blocked or all are
+// cooling down.
+func NoAccountAvailable(err error) bool {
+	var authErr *Error
+	if errors.As(err, &authErr) {
+		return authErr.Code == "auth_not_found" || authErr.Code == "auth_unavailable"
+	}
+	var cooldownErr *modelCooldownError
+	return errors.As(err, &cooldownErr)
+}
@@ -29,12 +29,16 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		return Response{}, &Error{Code: "circuit_open", Message: "provider circuit breaker is open"}
 	}
 
+	requestedModel := req.Model
 	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider)
 
+	ctx, cancelTimeout := m.withRequestTimeout(ctx, provider)
+	defer cancelTimeout()
+
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNextFromRegistry(ctx, provider, req.Model, opts, tried)
+		auth, executor, errPick := m.pickTraced(ctx, provider, req.Model, opts, tried)
 		if errPick != nil {
 			telemetry.RecordError(span, errPick)
 			if lastErr != nil {
@@ -49,14 +53,34 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 		}
 
+		release, errSlot := m.acquireConcurrency(execCtx, auth.ID, requestedModel, req.Model)
+		if errSlot != nil {
+			if timeoutErr := requestTimeoutErr(ctx); timeoutErr != nil {
+				return Response{}, timeoutErr
+			}
+			if errors.Is(errSlot, context.Canceled) || errors.Is(errSlot, context.DeadlineExceeded) {
+				return Response{}, errSlot
+			}
+			lastErr = errSlot
+			continue
+		}
+
 		authCopy := auth
 		reqCopy := req
+		reqCopy.Metadata = telemetry.WithMetadata(reqCopy.Metadata, execCtx)
+		callStart := time.Now()
 		result, errBreaker := breaker.Execute(func() (any, error) {
 			return executor.Execute(execCtx, authCopy, reqCopy, opts)
 		})
+		callLatency := time.Since(callStart)
+		release()
 
 		if errBreaker != nil {
 			telemetry.RecordError(span, errBreaker)
+			if timeoutErr := requestTimeoutErr(ctx); timeoutErr != nil {
+				m.MarkResult(context.WithoutCancel(execCtx), Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Error: timeoutErr, Latency: callLatency})
+				return Response{}, timeoutErr
+			}
 			if errors.Is(errBreaker, context.Canceled) || errors.Is(errBreaker, context.DeadlineExceeded) {
 				return Response{}, errBreaker
 			}
@@ -82,14 +106,18 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		}
 
 		resp := result.(Response)
-		m.MarkResult(execCtx, Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: true})
+		telemetry.RecordAuth(span, auth.ID)
+		telemetry.RecordResponse(span, resp.Payload)
+		m.MarkResult(execCtx, Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: true, FirstByteLatency: callLatency, Latency: callLatency})
+		RouteTraceFromContext(ctx).SetRoute(provider, req.Model, auth.ID)
+		m.rememberCachedContent(provider, opts, auth.ID)
 		return resp, nil
 	}
 }
 
-// ExecuteCountWithProvider handles token counting for a single provider, attempting
-// multiple auth candidates until one succeeds or all are exhausted.
-func (m *Manager) executeCountWithProvider(ctx context.Context, provider string, req Request, opts Options) (Response, error) {
+// ExecuteUnaryWithProvider handles token counting and embeddings for a single provider,
+// attempting multiple auth candidates until one succeeds or all are exhausted.
+func (m *Manager) executeUnaryWithProvider(ctx context.Context, provider string, req Request, opts Options, call unaryCall) (Response, error) {
 	if provider == "" {
 		return Response{}, &Error{Code: "provider_not_found", Message: "provider identifier is empty"}
 	}
@@ -121,7 +149,7 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 		authCopy := auth
 		reqCopy := req
 		result, errBreaker := breaker.Execute(func() (any, error) {
-			return executor.CountTokens(execCtx, authCopy, reqCopy, opts)
+			return call(execCtx, executor, authCopy, reqCopy, opts)
 		})
 
 		if errBreaker != nil {
@@ -151,6 +179,8 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 
 		resp := result.(Response)
 		m.MarkResult(execCtx, Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: true})
+		RouteTraceFromContext(ctx).SetRoute(provider, req.Model, auth.ID)
+		m.rememberCachedContent(provider, opts, auth.ID)
 		return resp, nil
 	}
 }
@@ -169,17 +199,42 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		return nil, &Error{Code: "circuit_open", Message: "provider circuit breaker is open"}
 	}
 
+	requestedModel := req.Model
 	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider)
 
+	// The execute span stays open until the stream goroutine finishes so it
+	// covers time to first byte and total stream duration.
+	start := time.Now()
+	ctx, span := telemetry.StartProviderSpan(ctx, provider, requestedModel)
+	span.SetAttributes(telemetry.AttrStream.Bool(true))
+	endSpan := func(err error) {
+		telemetry.RecordError(span, err)
+		telemetry.RecordLatency(span, start)
+		span.End()
+	}
+
+	// The request timeout spans the whole stream, so it is released by the
+	// stream goroutine once one starts.
+	clientCtx := ctx
+	ctx, cancelTimeout := m.withRequestTimeout(ctx, provider)
+	streaming := false
+	defer func() {
+		if !streaming {
+			cancelTimeout()
+		}
+	}()
+
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNextFromRegistry(ctx, provider, req.Model, opts, tried)
+		auth, executor, errPick := m.pickTraced(ctx, provider, req.Model, opts, tried)
 		if errPick != nil {
 			done(false)
 			if lastErr != nil {
+				endSpan(lastErr)
 				return nil, lastErr
 			}
+			endSpan(errPick)
 			return nil, errPick
 		}
 
@@ -188,10 +243,34 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		if rt := m.roundTripperFor(auth); rt != nil {
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 		}
-		chunks, errStream := executor.ExecuteStream(execCtx, auth, req, opts)
+		release, errSlot := m.acquireConcurrency(execCtx, auth.ID, requestedModel, req.Model)
+		if errSlot != nil {
+			if timeoutErr := requestTimeoutErr(ctx); timeoutErr != nil {
+				errSlot = timeoutErr
+			}
+			if errors.Is(errSlot, context.Canceled) || errors.Is(errSlot, context.DeadlineExceeded) || RequestTimedOut(ctx) {
+				done(false)
+				endSpan(errSlot)
+				return nil, errSlot
+			}
+			lastErr = errSlot
+			continue
+		}
+		streamReq := req
+		streamReq.Metadata = telemetry.WithMetadata(streamReq.Metadata, execCtx)
+		startTime := time.Now()
+		chunks, errStream := executor.ExecuteStream(execCtx, auth, streamReq, opts)
 		if errStream != nil {
+			release()
+			if timeoutErr := requestTimeoutErr(ctx); timeoutErr != nil {
+				m.MarkResult(context.WithoutCancel(execCtx), Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Error: timeoutErr, Latency: time.Since(startTime)})
+				done(false)
+				endSpan(timeoutErr)
+				return nil, timeoutErr
+			}
 			if errors.Is(errStream, context.Canceled) || errors.Is(errStream, context.DeadlineExceeded) {
 				done(false)
+				endSpan(errStream)
 				return nil, errStream
 			}
 
@@ -213,27 +292,79 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 			continue
 		}
 
+		RouteTraceFromContext(ctx).SetRoute(provider, req.Model, auth.ID)
+		m.rememberCachedContent(provider, opts, auth.ID)
+		telemetry.RecordAuth(span, auth.ID)
+
 		// Single output channel - consolidates previous 2 wrapper layers
 		out := make(chan StreamChunk, 128) // Unified buffer size for all stream operations
-		startTime := time.Now()
 
+		streaming = true
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamModel string, streamChunks <-chan StreamChunk, cbDone func(bool)) {
 			defer close(out)
+			defer cancelTimeout()
+			defer release()
 			var failed bool
+			var streamErr error
+			var firstByte bool
+			var firstByteLatency time.Duration
+			defer func() { endSpan(streamErr) }()
+
+			// timedOut ends a stream that ran past its request timeout: the
+			// partial call is recorded as failed and the client gets the
+			// timeout as the stream's final error.
+			timedOut := func(timeoutErr *Error) {
+				if !failed {
+					failed = true
+					streamErr = timeoutErr
+					m.MarkResult(context.WithoutCancel(streamCtx), Result{
+						AuthID:           streamAuth.ID,
+						Provider:         streamProvider,
+						Model:            streamModel,
+						Error:            timeoutErr,
+						FirstByteLatency: firstByteLatency,
+						Latency:          time.Since(startTime),
+					})
+					select {
+					case out <- StreamChunk{Err: timeoutErr}:
+					case <-clientCtx.Done():
+					}
+				}
+				m.recordProviderResult(streamProvider, streamModel, false, time.Since(startTime))
+				cbDone(false)
+			}
 
 			for {
 				select {
 				case <-streamCtx.Done():
+					if timeoutErr := requestTimeoutErr(streamCtx); timeoutErr != nil {
+						timedOut(timeoutErr)
+						return
+					}
 					// Context cancelled - record stats but don't count as failure
+					if !failed {
+						m.markCanceled(streamCtx, streamAuth.ID, streamProvider, streamModel, streamCtx.Err())
+					}
 					m.recordProviderResult(streamProvider, streamModel, !failed, time.Since(startTime))
 					cbDone(!failed)
 					return
 
 				case chunk, ok := <-streamChunks:
 					if !ok {
+						if timeoutErr := requestTimeoutErr(streamCtx); timeoutErr != nil {
+							timedOut(timeoutErr)
+							return
+						}
 						// Stream complete
 						if !failed {
-							m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: streamModel, Success: true})
+							m.MarkResult(streamCtx, Result{
+								AuthID:           streamAuth.ID,
+								Provider:         streamProvider,
+								Model:            streamModel,
+								Success:          true,
+								FirstByteLatency: firstByteLatency,
+								Latency:          time.Since(startTime),
+							})
 						}
 						m.recordProviderResult(streamProvider, streamModel, !failed, time.Since(startTime))
 						cbDone(!failed)
@@ -242,12 +373,18 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 
 					// Check for errors in chunk
 					if chunk.Err != nil && !failed {
+						if timeoutErr := requestTimeoutErr(streamCtx); timeoutErr != nil {
+							timedOut(timeoutErr)
+							return
+						}
 						if errors.Is(chunk.Err, context.Canceled) || errors.Is(chunk.Err, context.DeadlineExceeded) {
+							m.markCanceled(streamCtx, streamAuth.ID, streamProvider, streamModel, chunk.Err)
 							m.recordProviderResult(streamProvider, streamModel, true, time.Since(startTime))
 							cbDone(true)
 							return
 						}
 						failed = true
+						streamErr = chunk.Err
 						rerr := &Error{Message: chunk.Err.Error()}
 						var se StatusCodeError
 						if errors.As(chunk.Err, &se) && se != nil {
@@ -258,10 +395,20 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 						m.MarkResult(streamCtx, result)
 					}
 
+					if !firstByte && len(chunk.Payload) > 0 {
+						firstByte = true
+						firstByteLatency = time.Since(startTime)
+						telemetry.RecordFirstByte(span, start)
+					}
+
 					// Forward chunk - non-blocking with context check
 					select {
 					case out <- chunk:
 					case <-streamCtx.Done():
+						if timeoutErr := requestTimeoutErr(streamCtx); timeoutErr != nil {
+							timedOut(timeoutErr)
+							return
+						}
 						m.recordProviderResult(streamProvider, streamModel, !failed, time.Since(startTime))
 						cbDone(!failed)
 						return
@@ -274,6 +421,19 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 	}
 }
 
+// pickTraced wraps pickNextFromRegistry in a select span.
+func (m *Manager) pickTraced(ctx context.Context, provider, model string, opts Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
+	ctx, span := telemetry.StartSpan(ctx, telemetry.SpanSelect, telemetry.AttrProvider.String(provider), telemetry.AttrModel.String(model))
+	defer span.End()
+	auth, executor, err := m.pickNextFromRegistry(ctx, provider, model, opts, tried)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		return nil, nil, err
+	}
+	telemetry.RecordAuth(span, auth.ID)
+	return auth, executor, nil
+}
+
 // executeProvidersOnce attempts execution across multiple providers in sequence,
 // returning the first successful response.
 func (m *Manager) executeProvidersOnce(ctx context.Context, providers []string, fn func(context.Context, string) (Response, error)) (Response, error) {
@@ -313,3 +473,19 @@ func (m *Manager) executeStreamProvidersOnce(ctx context.Context, providers []st
 	}
 	return nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 }
+
+// markCanceled releases the auth of a stream the client abandoned without
+// penalizing it. ctx may already be done, so its values are kept but not its
+// cancellation.
+func (m *Manager) markCanceled(ctx context.Context, authID, provider, model string, cause error) {
+	if cause == nil {
+		cause = context.Canceled
+	}
+	m.MarkResult(context.WithoutCancel(ctx), Result{
+		AuthID:   authID,
+		Provider: provider,
+		Model:    model,
+		Canceled: true,
+		Error:    &Error{Code: "client_canceled", Message: cause.Error()},
+	})
+}
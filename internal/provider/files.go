@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// FileUpload is a file to store with a provider's Files API. A positive TTL
+// asks the provider to expire the file after that long where supported.
+type FileUpload struct {
+	Filename string
+	MimeType string
+	Purpose  string
+	TTL      time.Duration
+	Data     []byte
+}
+
+// StoredFile describes a file held by a provider. Ref is the value chat
+// requests use to reference it upstream: the provider file ID, or the file
+// URI for Gemini. ExpiresAt is zero when the provider keeps files until they
+// are deleted.
+type StoredFile struct {
+	ID        string
+	Ref       string
+	ExpiresAt time.Time
+}
+
+// UploadedFile is a stored file together with the credential that owns it.
+// Provider files are private to the account that uploaded them.
+type UploadedFile struct {
+	StoredFile
+	Provider string
+	AuthID   string
+}
+
+// FileStorer is an optional interface implemented by provider executors that
+// can store files with the upstream Files API. MaxFileSize returns the largest
+// upload the provider accepts in bytes, or 0 for no limit.
+type FileStorer interface {
+	MaxFileSize() int64
+	UploadFile(ctx context.Context, auth *Auth, file FileUpload) (StoredFile, error)
+	DeleteFile(ctx context.Context, auth *Auth, id string) error
+}
+
+type pinnedAuthContextKey struct{}
+
+// WithPinnedAuth restricts credential selection for requests made with ctx to
+// authID, e.g. because the request references a file only that account can
+// read. Providers without that credential have no candidates.
+func WithPinnedAuth(ctx context.Context, authID string) context.Context {
+	if authID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, pinnedAuthContextKey{}, authID)
+}
+
+// pinnedAuth returns the credential ctx is pinned to, or "".
+func pinnedAuth(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	authID, _ := ctx.Value(pinnedAuthContextKey{}).(string)
+	return authID
+}
+
+// UploadFile stores file with one of providers whose executors implement
+// FileStorer and accept its size, using the same selection and retries as
+// ExecuteEmbed. With no providers every file-capable provider is a candidate.
+// model, when set, limits candidates to credentials serving it.
+func (m *Manager) UploadFile(ctx context.Context, providers []string, model string, file FileUpload) (UploadedFile, error) {
+	normalized := m.normalizeProviders(providers)
+	if len(normalized) == 0 {
+		normalized = m.fileProviders()
+	}
+	var capable []string
+	tooLarge := false
+	for _, provider := range normalized {
+		storer, ok := m.executorFor(provider).(FileStorer)
+		if !ok {
+			continue
+		}
+		if limit := storer.MaxFileSize(); limit > 0 && int64(len(file.Data)) > limit {
+			tooLarge = true
+			continue
+		}
+		capable = append(capable, provider)
+	}
+	if len(capable) == 0 {
+		if tooLarge {
+			return UploadedFile{}, &Error{Code: "file_too_large", Message: "file exceeds the size limit of every provider for this model", HTTPStatus: http.StatusRequestEntityTooLarge}
+		}
+		return UploadedFile{}, &Error{Code: "files_not_supported", Message: "no provider for this model supports file uploads", HTTPStatus: http.StatusBadRequest}
+	}
+
+	var uploaded UploadedFile
+	call := func(ctx context.Context, executor ProviderExecutor, auth *Auth, _ Request, _ Options) (Response, error) {
+		storer, ok := executor.(FileStorer)
+		if !ok {
+			return Response{}, &Error{Code: "files_not_supported", Message: "provider does not support file uploads", HTTPStatus: http.StatusBadRequest}
+		}
+		stored, err := storer.UploadFile(ctx, auth, file)
+		if err != nil {
+			return Response{}, err
+		}
+		uploaded = UploadedFile{StoredFile: stored, Provider: auth.Provider, AuthID: auth.ID}
+		return Response{}, nil
+	}
+	if _, err := m.executeUnary(ctx, capable, Request{Model: model}, Options{}, call); err != nil {
+		return UploadedFile{}, err
+	}
+	return uploaded, nil
+}
+
+// DeleteFile deletes the provider file id held by the credential authID.
+func (m *Manager) DeleteFile(ctx context.Context, authID, id string) error {
+	auth, ok := m.GetByID(authID)
+	if !ok {
+		return &Error{Code: "auth_not_found", Message: "the account that stored this file is no longer configured", HTTPStatus: http.StatusNotFound}
+	}
+	storer, ok := m.executorFor(auth.Provider).(FileStorer)
+	if !ok {
+		return &Error{Code: "files_not_supported", Message: "provider does not support file uploads", HTTPStatus: http.StatusBadRequest}
+	}
+	if rt := m.roundTripperFor(auth); rt != nil {
+		ctx = context.WithValue(ctx, roundTripperContextKey{}, rt)
+	}
+	return storer.DeleteFile(ctx, auth, id)
+}
+
+// fileProviders lists the providers whose executors implement FileStorer.
+func (m *Manager) fileProviders() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var providers []string
+	for name, executor := range m.executors {
+		if _, ok := executor.(FileStorer); ok {
+			providers = append(providers, name)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
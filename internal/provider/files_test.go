@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+type fileExecutor struct {
+	chatOnlyExecutor
+	limit   int64
+	deleted []string
+}
+
+func (e *fileExecutor) MaxFileSize() int64 { return e.limit }
+
+func (e *fileExecutor) UploadFile(_ context.Context, auth *Auth, file FileUpload) (StoredFile, error) {
+	return StoredFile{ID: "up-" + file.Filename, Ref: auth.ID + "/" + file.Filename}, nil
+}
+
+func (e *fileExecutor) DeleteFile(_ context.Context, _ *Auth, id string) error {
+	e.deleted = append(e.deleted, id)
+	return nil
+}
+
+func TestManager_UploadFile(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.RegisterExecutor(&chatOnlyExecutor{id: "chatonly"})
+	small := &fileExecutor{chatOnlyExecutor: chatOnlyExecutor{id: "small"}, limit: 4}
+	m.RegisterExecutor(small)
+	for _, p := range []string{"chatonly", "small"} {
+		id := p + "-auth"
+		registry.GetGlobalRegistry().RegisterClient(id, p, []*registry.ModelInfo{{ID: "file-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: p}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	uploaded, err := m.UploadFile(context.Background(), []string{"chatonly", "small"}, "file-model", FileUpload{Filename: "a.txt", Data: []byte("abc")})
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if uploaded.Provider != "small" || uploaded.AuthID != "small-auth" || uploaded.ID != "up-a.txt" {
+		t.Errorf("Unexpected upload result %+v", uploaded)
+	}
+
+	if _, err = m.UploadFile(context.Background(), nil, "", FileUpload{Filename: "b.txt", Data: []byte("abcde")}); statusCodeFromError(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 above every provider limit, got %v", err)
+	}
+	if _, err = m.UploadFile(context.Background(), []string{"chatonly"}, "file-model", FileUpload{Filename: "c.txt"}); statusCodeFromError(err) != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a file-capable provider, got %v", err)
+	}
+
+	if err = m.DeleteFile(context.Background(), "small-auth", "up-a.txt"); err != nil || len(small.deleted) != 1 {
+		t.Errorf("DeleteFile: err=%v deleted=%v", err, small.deleted)
+	}
+	if err = m.DeleteFile(context.Background(), "missing", "x"); statusCodeFromError(err) != http.StatusNotFound {
+		t.Errorf("Expected 404 for a removed account, got %v", err)
+	}
+}
+
+func TestManager_PinnedAuth(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.RegisterExecutor(&chatOnlyExecutor{id: "pinned"})
+	for _, id := range []string{"pin-a", "pin-b"} {
+		registry.GetGlobalRegistry().RegisterClient(id, "pinned", []*registry.ModelInfo{{ID: "pin-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "pinned"}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	ctx := WithPinnedAuth(context.Background(), "pin-b")
+	for i := 0; i < 4; i++ {
+		auth, _, err := m.pickNextFromRegistry(ctx, "pinned", "pin-model", Options{}, map[string]struct{}{})
+		if err != nil || auth.ID != "pin-b" {
+			t.Fatalf("registry pick %d: auth=%v err=%v, want pin-b", i, auth, err)
+		}
+		auth, _, err = m.pickNext(ctx, "pinned", "pin-model", Options{}, map[string]struct{}{})
+		if err != nil || auth.ID != "pin-b" {
+			t.Fatalf("legacy pick %d: auth=%v err=%v, want pin-b", i, auth, err)
+		}
+	}
+	if _, _, err := m.pickNextFromRegistry(ctx, "pinned", "pin-model", Options{}, map[string]struct{}{"pin-b": {}}); err == nil {
+		t.Error("Expected no candidate once the pinned auth was tried")
+	}
+}
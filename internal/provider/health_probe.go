@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/usage"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	// maxConcurrentProbes bounds the probes in flight across all accounts.
+	maxConcurrentProbes = 4
+
+	healthProbeTimeout = 30 * time.Second
+)
+
+// HealthProbeConfig controls the background health prober. A zero Interval
+// disables it.
+type HealthProbeConfig struct {
+	// Interval between probe rounds.
+	Interval time.Duration
+	// IncludeSuspended also probes models suspended after an auth, payment,
+	// not-found or server error. Quota cooldowns are never probed.
+	IncludeSuspended bool
+}
+
+// healthProbePayload is the minimal OpenAI chat request sent as a probe.
+func healthProbePayload(model string) []byte {
+	payload, _ := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "ping"}},
+		"max_tokens": 1,
+	})
+	return payload
+}
+
+// SetHealthProbe starts, reconfigures or stops the background prober that
+// checks untested accounts, and optionally suspended ones, independently of
+// traffic. Probe outcomes update account state like real requests, but never
+// produce usage records.
+func (m *Manager) SetHealthProbe(cfg HealthProbeConfig) {
+	if m == nil {
+		return
+	}
+	m.probeMu.Lock()
+	defer m.probeMu.Unlock()
+	if m.probeCancel != nil {
+		if cfg == m.probeCfg {
+			return
+		}
+		m.probeCancel()
+		m.probeCancel = nil
+	}
+	m.probeCfg = cfg
+	if cfg.Interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.probeCancel = cancel
+	sem := semaphore.NewWeighted(maxConcurrentProbes)
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.probeRound(ctx, cfg, sem)
+			}
+		}
+	}()
+	log.Infof("health probe started (interval=%s, include-suspended=%v)", cfg.Interval, cfg.IncludeSuspended)
+}
+
+// stopHealthProbe cancels the background prober, if running.
+func (m *Manager) stopHealthProbe() {
+	m.probeMu.Lock()
+	defer m.probeMu.Unlock()
+	if m.probeCancel != nil {
+		m.probeCancel()
+		m.probeCancel = nil
+	}
+	m.probeCfg = HealthProbeConfig{}
+}
+
+// probeRound probes every eligible account once. Accounts whose previous
+// probe is still running, or that find the semaphore full, wait for the next
+// round.
+func (m *Manager) probeRound(ctx context.Context, cfg HealthProbeConfig, sem *semaphore.Weighted) {
+	for _, a := range m.listAuths() {
+		if a == nil || a.Disabled {
+			continue
+		}
+		_, probed := m.probed.Load(a.ID)
+		model := healthProbeModel(a, !probed, cfg.IncludeSuspended)
+		if model == "" {
+			continue
+		}
+		exec := m.executorFor(a.Provider)
+		if exec == nil {
+			continue
+		}
+		if _, busy := m.probing.LoadOrStore(a.ID, struct{}{}); busy {
+			continue
+		}
+		if !sem.TryAcquire(1) {
+			m.probing.Delete(a.ID)
+			return
+		}
+		go func(auth *Auth) {
+			defer sem.Release(1)
+			defer m.probing.Delete(auth.ID)
+			m.probeAuth(ctx, exec, auth, model)
+		}(a)
+	}
+}
+
+// healthProbeModel picks the model to probe on a, or "" when a needs no
+// probe. Accounts that never reported a result are probed once with their
+// first registered model; with includeSuspended, the first suspended model
+// that is not in a quota cooldown is probed.
+func healthProbeModel(a *Auth, untested, includeSuspended bool) string {
+	if untested && len(a.ModelStates) == 0 && a.LastError == nil && a.Status != StatusError {
+		if models := registry.GetGlobalRegistry().ClientModels(a.ID); len(models) > 0 {
+			return models[0]
+		}
+		return ""
+	}
+	if !includeSuspended {
+		return ""
+	}
+	models := make([]string, 0, len(a.ModelStates))
+	for model, state := range a.ModelStates {
+		if state != nil && state.Unavailable && !state.Quota.Exceeded {
+			models = append(models, model)
+		}
+	}
+	if len(models) == 0 {
+		return ""
+	}
+	sort.Strings(models)
+	return models[0]
+}
+
+// probeAuth sends one probe request and records the outcome. Timeouts,
+// cancellation and tokens that are still refreshing are inconclusive and
+// leave the account state untouched.
+func (m *Manager) probeAuth(ctx context.Context, exec ProviderExecutor, auth *Auth, model string) {
+	ctx, cancel := context.WithTimeout(usage.WithoutRecording(ctx), healthProbeTimeout)
+	defer cancel()
+	if rt := m.roundTripperFor(auth); rt != nil {
+		ctx = context.WithValue(ctx, roundTripperContextKey{}, rt)
+	}
+
+	// Count the probe as active so a failed MarkResult, which releases one
+	// active request, does not take the slot of a real request.
+	var entry *AuthEntry
+	if m.registry != nil {
+		entry = m.registry.GetEntry(auth.ID)
+	}
+	if entry != nil {
+		entry.IncrementActiveRequests()
+	}
+
+	payload := healthProbePayload(model)
+	req := Request{Model: model, Payload: payload, Format: FormatOpenAI}
+	opts := Options{OriginalRequest: payload, SourceFormat: FormatOpenAI}
+	_, err := exec.Execute(ctx, auth, req, opts)
+
+	var provErr *Error
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &provErr) && provErr.Code == "token_not_ready")) {
+		if entry != nil {
+			entry.DecrementActiveRequests()
+		}
+		log.Debugf("health probe for %s (%s) inconclusive: %v", auth.ID, model, err)
+		return
+	}
+	// A rejected probe (e.g. 400) leaves no model state behind; remember the
+	// account so it is not probed as untested every round.
+	m.probed.Store(auth.ID, struct{}{})
+
+	result := Result{AuthID: auth.ID, Provider: auth.Provider, Model: model, Success: err == nil}
+	if err != nil {
+		result.Error = &Error{Message: err.Error()}
+		var se StatusCodeError
+		if errors.As(err, &se) && se != nil {
+			result.Error.HTTPStatus = se.StatusCode()
+		}
+		result.RetryAfter = retryAfterFromError(err)
+		log.Debugf("health probe for %s (%s) failed: %v", auth.ID, model, err)
+	} else {
+		if entry != nil {
+			entry.DecrementActiveRequests()
+		}
+		log.Debugf("health probe for %s (%s) succeeded", auth.ID, model)
+	}
+	m.MarkResult(ctx, result)
+}
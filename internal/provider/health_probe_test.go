@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/usage"
+	"github.com/tidwall/gjson"
+	"golang.org/x/sync/semaphore"
+)
+
+type probeStatusError struct{ code int }
+
+func (e probeStatusError) Error() string   { return http.StatusText(e.code) }
+func (e probeStatusError) StatusCode() int { return e.code }
+
+// probeExecutor answers with status (0 for success) and records each call.
+type probeExecutor struct {
+	status     atomic.Int32
+	calls      atomic.Int32
+	mu         sync.Mutex
+	models     []string
+	suppressed bool
+}
+
+func (e *probeExecutor) Identifier() string { return "probetest" }
+func (e *probeExecutor) Execute(ctx context.Context, _ *Auth, req Request, _ Options) (Response, error) {
+	e.calls.Add(1)
+	e.mu.Lock()
+	e.models = append(e.models, gjson.GetBytes(req.Payload, "model").String())
+	e.suppressed = usage.RecordingSuppressed(ctx)
+	e.mu.Unlock()
+	if code := e.status.Load(); code != 0 {
+		return Response{}, probeStatusError{code: int(code)}
+	}
+	return Response{Payload: []byte(`{}`)}, nil
+}
+func (e *probeExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+func (e *probeExecutor) Refresh(_ context.Context, a *Auth) (*Auth, error) { return a, nil }
+func (e *probeExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func newProbeTestManager(t *testing.T, exec *probeExecutor) (*Manager, string) {
+	t.Helper()
+	authID := "probe-" + t.Name()
+	registry.GetGlobalRegistry().RegisterClient(authID, "probetest", []*registry.ModelInfo{{ID: "probe-model"}, {ID: "probe-model-2"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(authID) })
+
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.RegisterExecutor(exec)
+	if _, err := m.Register(context.Background(), &Auth{ID: authID, Provider: "probetest", Status: StatusActive}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	return m, authID
+}
+
+// runProbeRound runs one probe round and waits for its probes to finish.
+func runProbeRound(t *testing.T, m *Manager, cfg HealthProbeConfig) {
+	t.Helper()
+	sem := semaphore.NewWeighted(maxConcurrentProbes)
+	m.probeRound(context.Background(), cfg, sem)
+	deadline := time.Now().Add(2 * time.Second)
+	for !sem.TryAcquire(maxConcurrentProbes) {
+		if time.Now().After(deadline) {
+			t.Fatal("probe round did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHealthProbe_ProbesUntestedAccountOnce(t *testing.T) {
+	exec := &probeExecutor{}
+	m, authID := newProbeTestManager(t, exec)
+
+	runProbeRound(t, m, HealthProbeConfig{Interval: time.Minute})
+	if calls := exec.calls.Load(); calls != 1 {
+		t.Fatalf("Expected one probe for the untested account, got %d", calls)
+	}
+	if exec.models[0] != "probe-model" {
+		t.Errorf("Expected the first registered model to be probed, got %q", exec.models[0])
+	}
+	if !exec.suppressed {
+		t.Error("Expected probe context to suppress usage records")
+	}
+	auth := m.registry.Get(authID)
+	if state := auth.ModelStates["probe-model"]; state == nil || state.Status != StatusActive {
+		t.Errorf("Expected probe success to record an active model state, got %+v", state)
+	}
+
+	runProbeRound(t, m, HealthProbeConfig{Interval: time.Minute})
+	if calls := exec.calls.Load(); calls != 1 {
+		t.Errorf("Expected a tested account not to be probed again, got %d calls", calls)
+	}
+}
+
+func TestHealthProbe_SuspendedAccounts(t *testing.T) {
+	exec := &probeExecutor{}
+	m, authID := newProbeTestManager(t, exec)
+	m.MarkResult(context.Background(), Result{AuthID: authID, Provider: "probetest", Model: "probe-model-2", Error: &Error{Message: "unauthorized", HTTPStatus: http.StatusUnauthorized}})
+
+	runProbeRound(t, m, HealthProbeConfig{Interval: time.Minute})
+	if calls := exec.calls.Load(); calls != 0 {
+		t.Fatalf("Expected suspended accounts to be skipped by default, got %d calls", calls)
+	}
+
+	runProbeRound(t, m, HealthProbeConfig{Interval: time.Minute, IncludeSuspended: true})
+	if calls := exec.calls.Load(); calls != 1 || exec.models[0] != "probe-model-2" {
+		t.Fatalf("Expected one probe of the suspended model, got %d calls for %v", calls, exec.models)
+	}
+	auth := m.registry.Get(authID)
+	if state := auth.ModelStates["probe-model-2"]; state == nil || state.Unavailable {
+		t.Errorf("Expected successful probe to resume the model, got %+v", state)
+	}
+
+	exec.status.Store(http.StatusTooManyRequests)
+	m.MarkResult(context.Background(), Result{AuthID: authID, Provider: "probetest", Model: "probe-model", Error: &Error{Message: "quota", HTTPStatus: http.StatusTooManyRequests}})
+	runProbeRound(t, m, HealthProbeConfig{Interval: time.Minute, IncludeSuspended: true})
+	if calls := exec.calls.Load(); calls != 1 {
+		t.Errorf("Expected quota cooldowns not to be probed, got %d calls", calls)
+	}
+}
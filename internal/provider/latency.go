@@ -0,0 +1,231 @@
+package provider
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// latencyWindow is how long a histogram generation collects samples. Two
+// generations are kept, so percentiles cover the last one to two windows.
+const latencyWindow = 5 * time.Minute
+
+// latencyBounds are the upper bounds of the histogram buckets, growing by 25%
+// from 5ms to over 10 minutes. Samples above the last bound land in an
+// overflow bucket reported as the last bound.
+var latencyBounds = func() []time.Duration {
+	var bounds []time.Duration
+	for b := float64(5 * time.Millisecond); ; b *= 1.25 {
+		bounds = append(bounds, time.Duration(b))
+		if time.Duration(b) > 10*time.Minute {
+			return bounds
+		}
+	}
+}()
+
+// LatencyPercentiles summarizes recent latencies of one kind, in milliseconds.
+type LatencyPercentiles struct {
+	Samples int64 `json:"samples"`
+	P50Ms   int64 `json:"p50_ms"`
+	P95Ms   int64 `json:"p95_ms"`
+	P99Ms   int64 `json:"p99_ms"`
+}
+
+// AccountLatency holds the rolling latency percentiles of one account over
+// its recent successful requests.
+type AccountLatency struct {
+	// FirstByte is the time from sending the request to the first response
+	// bytes; for non-streaming requests it equals Total.
+	FirstByte LatencyPercentiles `json:"first_byte"`
+	Total     LatencyPercentiles `json:"total"`
+}
+
+type latencyHistogram struct {
+	counts []uint32
+	total  int64
+}
+
+func (h *latencyHistogram) add(d time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]uint32, len(latencyBounds)+1)
+	}
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.total++
+}
+
+// percentile interpolates the q-th quantile linearly inside its bucket.
+func percentile(counts []uint32, total int64, q float64) time.Duration {
+	if total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(total)))
+	var seen int64
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+		if seen+int64(c) < rank {
+			seen += int64(c)
+			continue
+		}
+		if i >= len(latencyBounds) {
+			return latencyBounds[len(latencyBounds)-1]
+		}
+		var lower time.Duration
+		if i > 0 {
+			lower = latencyBounds[i-1]
+		}
+		frac := float64(rank-seen) / float64(c)
+		return lower + time.Duration(frac*float64(latencyBounds[i]-lower))
+	}
+	return latencyBounds[len(latencyBounds)-1]
+}
+
+// rollingLatency keeps the current and previous histogram generation.
+type rollingLatency struct {
+	cur, prev latencyHistogram
+	started   time.Time
+}
+
+func (r *rollingLatency) rotate(now time.Time) {
+	switch age := now.Sub(r.started); {
+	case age < latencyWindow:
+		return
+	case age < 2*latencyWindow:
+		r.prev = r.cur
+	default:
+		r.prev = latencyHistogram{}
+	}
+	r.cur = latencyHistogram{}
+	r.started = now
+}
+
+func (r *rollingLatency) percentiles() LatencyPercentiles {
+	total := r.cur.total + r.prev.total
+	if total == 0 {
+		return LatencyPercentiles{}
+	}
+	merged := make([]uint32, len(latencyBounds)+1)
+	for _, h := range []latencyHistogram{r.cur, r.prev} {
+		for i, c := range h.counts {
+			merged[i] += c
+		}
+	}
+	return LatencyPercentiles{
+		Samples: total,
+		P50Ms:   percentile(merged, total, 0.50).Milliseconds(),
+		P95Ms:   percentile(merged, total, 0.95).Milliseconds(),
+		P99Ms:   percentile(merged, total, 0.99).Milliseconds(),
+	}
+}
+
+type accountLatency struct {
+	firstByte  rollingLatency
+	total      rollingLatency
+	lastSample time.Time
+}
+
+// latencyTracker records per-account latency in fixed-size histograms, so
+// memory stays constant per account however many requests it serves.
+type latencyTracker struct {
+	mu       sync.Mutex
+	accounts map[string]*accountLatency
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{accounts: make(map[string]*accountLatency)}
+}
+
+func (t *latencyTracker) record(authID string, firstByte, total time.Duration, now time.Time) {
+	if authID == "" || total <= 0 {
+		return
+	}
+	if firstByte <= 0 || firstByte > total {
+		firstByte = total
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	a := t.accounts[authID]
+	if a == nil {
+		a = &accountLatency{firstByte: rollingLatency{started: now}, total: rollingLatency{started: now}}
+		t.accounts[authID] = a
+	}
+	a.firstByte.rotate(now)
+	a.total.rotate(now)
+	a.firstByte.cur.add(firstByte)
+	a.total.cur.add(total)
+	a.lastSample = now
+}
+
+func (t *latencyTracker) snapshot(authID string, now time.Time) (AccountLatency, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	a := t.accounts[authID]
+	if a == nil {
+		return AccountLatency{}, false
+	}
+	a.firstByte.rotate(now)
+	a.total.rotate(now)
+	out := AccountLatency{FirstByte: a.firstByte.percentiles(), Total: a.total.percentiles()}
+	return out, out.Total.Samples > 0
+}
+
+// firstByteP95 returns an account's recent p95 time to first byte once it has
+// at least minSelectionSamples samples.
+func (t *latencyTracker) firstByteP95(authID string, now time.Time) (time.Duration, bool) {
+	lat, ok := t.snapshot(authID, now)
+	if !ok || lat.FirstByte.Samples < minSelectionSamples {
+		return 0, false
+	}
+	return time.Duration(lat.FirstByte.P95Ms) * time.Millisecond, true
+}
+
+// cleanup drops accounts with no samples left in either generation.
+func (t *latencyTracker) cleanup(now time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	removed := 0
+	for id, a := range t.accounts {
+		if now.Sub(a.lastSample) >= 2*latencyWindow {
+			delete(t.accounts, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// AccountLatency returns the rolling latency percentiles of an account's
+// recent successful requests. It reports false when there are none.
+func (m *Manager) AccountLatency(authID string) (AccountLatency, bool) {
+	if m == nil || m.latency == nil {
+		return AccountLatency{}, false
+	}
+	return m.latency.snapshot(authID, time.Now())
+}
+
+// AccountLatencies returns the latency percentiles of every account with
+// recent successful requests, keyed by auth ID.
+func (m *Manager) AccountLatencies() map[string]AccountLatency {
+	if m == nil || m.latency == nil {
+		return nil
+	}
+	m.latency.mu.Lock()
+	ids := make([]string, 0, len(m.latency.accounts))
+	for id := range m.latency.accounts {
+		ids = append(ids, id)
+	}
+	m.latency.mu.Unlock()
+
+	now := time.Now()
+	out := make(map[string]AccountLatency, len(ids))
+	for _, id := range ids {
+		if lat, ok := m.latency.snapshot(id, now); ok {
+			out[id] = lat
+		}
+	}
+	return out
+}
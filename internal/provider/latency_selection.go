@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// minSelectionSamples is how many recent requests an account needs before its
+// latency steers selection.
+const minSelectionSamples = 5
+
+// latencySelection biases QuotaManager picks among equally suitable accounts
+// toward those with a lower recent p95 time to first byte. Accounts are
+// weighted by the inverse of their p95, and the exploration fraction of picks
+// is uniform so slower accounts keep fresh stats.
+type latencySelection struct {
+	p95         func(authID string) (time.Duration, bool)
+	exploration float64
+}
+
+// pick chooses one of auths. Accounts without enough samples are weighted as
+// the average of the measured ones so they get probed too.
+func (s *latencySelection) pick(_ string, auths []*Auth) *Auth {
+	if rand.Float64() < s.exploration {
+		return auths[rand.N(len(auths))]
+	}
+	weights := make([]float64, len(auths))
+	var sum float64
+	measured := 0
+	for i, auth := range auths {
+		if p95, ok := s.p95(auth.ID); ok {
+			if p95 < time.Millisecond {
+				p95 = time.Millisecond
+			}
+			weights[i] = 1 / float64(p95)
+			sum += weights[i]
+			measured++
+		}
+	}
+	if measured == 0 {
+		return auths[rand.N(len(auths))]
+	}
+	mean := sum / float64(measured)
+	total := 0.0
+	for i := range weights {
+		if weights[i] == 0 {
+			weights[i] = mean
+		}
+		total += weights[i]
+	}
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return auths[i]
+		}
+		r -= w
+	}
+	return auths[len(auths)-1]
+}
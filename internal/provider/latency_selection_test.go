@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencySelectionFavoursFastAccounts(t *testing.T) {
+	p95 := map[string]time.Duration{"fast": 100 * time.Millisecond, "slow": time.Second}
+	auths := []*Auth{{ID: "fast"}, {ID: "slow"}, {ID: "new"}}
+	sel := &latencySelection{
+		p95: func(id string) (time.Duration, bool) {
+			d, ok := p95[id]
+			return d, ok
+		},
+	}
+
+	picks := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		picks[sel.pick("", auths).ID]++
+	}
+	// Weights are 10, 1 and the unmeasured account gets their mean of 5.5.
+	if picks["fast"] < 1500 || picks["slow"] > 300 || picks["new"] < 700 {
+		t.Fatalf("picks = %v, want fast ~1800, new ~1000, slow ~200", picks)
+	}
+
+	sel.exploration = 1
+	picks = make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		picks[sel.pick("", auths).ID]++
+	}
+	if picks["slow"] < 800 {
+		t.Fatalf("with full exploration picks = %v, want roughly uniform", picks)
+	}
+}
+
+func TestSetLatencyAwareSelection(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+	qm := m.GetQuotaManager()
+
+	if err := m.SetSelectionStrategy(StrategyLatencyAware, 0.2); err != nil {
+		t.Fatalf("SetSelectionStrategy: %v", err)
+	}
+	strategy := qm.selection.Load()
+	if strategy == nil {
+		t.Fatal("latency-aware selection not enabled")
+	}
+	sel, ok := strategy.selector.(*latencySelection)
+	if !ok || sel.exploration != 0.2 {
+		t.Fatalf("selector = %+v", strategy.selector)
+	}
+	for i := 0; i < minSelectionSamples; i++ {
+		m.MarkResult(t.Context(), Result{AuthID: "a", Success: true, Latency: 300 * time.Millisecond})
+	}
+	if p95, ok := sel.p95("a"); !ok || p95 < 200*time.Millisecond || p95 > 400*time.Millisecond {
+		t.Fatalf("p95(a) = %v, %v", p95, ok)
+	}
+	if _, ok := sel.p95("b"); ok {
+		t.Fatal("p95 reported for an account without samples")
+	}
+
+	if err := m.SetSelectionStrategy(StrategyDefault, 0.2); err != nil {
+		t.Fatalf("SetSelectionStrategy: %v", err)
+	}
+	if qm.selection.Load() != nil {
+		t.Fatal("latency-aware selection still enabled")
+	}
+}
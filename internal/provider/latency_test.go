@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyTrackerPercentiles(t *testing.T) {
+	tr := newLatencyTracker()
+	now := time.Now()
+	for i := 1; i <= 100; i++ {
+		tr.record("a", 50*time.Millisecond, time.Duration(i)*10*time.Millisecond, now)
+	}
+
+	lat, ok := tr.snapshot("a", now)
+	if !ok || lat.Total.Samples != 100 {
+		t.Fatalf("snapshot() = %+v, %v", lat, ok)
+	}
+	// Buckets are 25% wide, so allow that much error against the exact values.
+	for _, c := range []struct {
+		name      string
+		got, want int64
+	}{{"p50", lat.Total.P50Ms, 500}, {"p95", lat.Total.P95Ms, 950}, {"p99", lat.Total.P99Ms, 990}} {
+		if float64(c.got) < 0.75*float64(c.want) || float64(c.got) > 1.25*float64(c.want) {
+			t.Errorf("%s = %dms, want about %dms", c.name, c.got, c.want)
+		}
+	}
+	if lat.FirstByte.P99Ms < 40 || lat.FirstByte.P99Ms > 60 {
+		t.Errorf("first byte p99 = %dms, want about 50ms", lat.FirstByte.P99Ms)
+	}
+	if _, ok = tr.snapshot("b", now); ok {
+		t.Fatal("snapshot() reported data for an unknown account")
+	}
+}
+
+func TestLatencyTrackerRollsOver(t *testing.T) {
+	tr := newLatencyTracker()
+	now := time.Now()
+	tr.record("a", 0, time.Second, now)
+
+	if lat, _ := tr.snapshot("a", now.Add(latencyWindow+time.Second)); lat.Total.Samples != 1 {
+		t.Fatalf("previous window dropped too early: %+v", lat)
+	}
+	tr.record("a", 0, 100*time.Millisecond, now.Add(latencyWindow+time.Second))
+	if lat, _ := tr.snapshot("a", now.Add(2*latencyWindow+2*time.Second)); lat.Total.Samples != 1 || lat.Total.P99Ms > 125 {
+		t.Fatalf("old window not rotated out: %+v", lat)
+	}
+	if _, ok := tr.snapshot("a", now.Add(4*latencyWindow)); ok {
+		t.Fatal("samples older than two windows still reported")
+	}
+	if removed := tr.cleanup(now.Add(4 * latencyWindow)); removed != 1 {
+		t.Fatalf("cleanup() = %d, want 1", removed)
+	}
+}
+
+func TestMarkResultRecordsLatency(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	defer m.Stop()
+	m.MarkResult(t.Context(), Result{AuthID: "a", Success: true, Latency: 200 * time.Millisecond})
+	m.MarkResult(t.Context(), Result{AuthID: "a", Success: false, Latency: time.Millisecond})
+
+	lat, ok := m.AccountLatency("a")
+	if !ok || lat.Total.Samples != 1 || lat.FirstByte.Samples != 1 {
+		t.Fatalf("AccountLatency() = %+v, %v", lat, ok)
+	}
+	if all := m.AccountLatencies(); len(all) != 1 {
+		t.Fatalf("AccountLatencies() = %+v", all)
+	}
+}
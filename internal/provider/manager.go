@@ -12,6 +12,7 @@ import (
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/resilience"
+	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/sony/gobreaker"
 	"golang.org/x/sync/semaphore"
 )
@@ -50,10 +51,37 @@ type Result struct {
 	Model string
 	// Success marks whether the execution succeeded.
 	Success bool
+	// Canceled marks an execution abandoned by the client. It releases the
+	// auth's active request slot without counting as a failure.
+	Canceled bool
 	// RetryAfter carries a provider supplied retry hint (e.g. 429 retryDelay).
 	RetryAfter *time.Duration
 	// Error describes the failure when Success is false.
 	Error *Error
+	// RequestID is the client request ID (X-Request-ID) that produced this result.
+	RequestID string
+	// APIKeyID identifies the calling client API key (see usage.APIKeyID).
+	APIKeyID string
+	// FirstByteLatency is the time until the first response bytes arrived.
+	// It is zero when not measured and equals Latency for non-streaming calls.
+	FirstByteLatency time.Duration
+	// Latency is the total time the upstream call took, zero when not measured.
+	Latency time.Duration
+	// RequestedModel is the canonical model the client asked for, before
+	// fallbacks and provider model mapping.
+	RequestedModel string
+	// Strategy names the account selection strategy (see StrategyDefault).
+	Strategy string
+	// Attempt numbers the upstream calls made for the client request, starting
+	// at 1. Later attempts are retries on another account, provider or model.
+	// It is zero for calls made outside a client request, such as health probes.
+	Attempt int
+	// Failover marks a call to another provider or upstream model than the
+	// first attempt.
+	Failover bool
+	// CacheHit marks a response served from the response cache without an
+	// upstream call; AuthID and Provider are empty.
+	CacheHit bool
 }
 
 // Selector chooses an auth candidate for execution.
@@ -93,6 +121,7 @@ type Manager struct {
 	auths     map[string]*Auth
 
 	providerStats *ProviderStats
+	latency       *latencyTracker
 
 	requestRetry     atomic.Int32
 	maxRetryInterval atomic.Int64
@@ -102,13 +131,29 @@ type Manager struct {
 	refreshCancel context.CancelFunc
 	refreshSem    *semaphore.Weighted
 
+	probeMu     sync.Mutex
+	probeCancel context.CancelFunc
+	probeCfg    HealthProbeConfig
+	probing     sync.Map // auth ID -> struct{} while a health probe runs
+	probed      sync.Map // auth ID -> struct{} once a health probe completed
+
+	notifier atomic.Pointer[stateNotifier]
+	timeouts atomic.Pointer[RequestTimeouts]
+
 	breakerMu         sync.RWMutex
 	breakers          map[string]*resilience.CircuitBreaker
 	streamingBreakers map[string]*resilience.StreamingCircuitBreaker
 
 	retryBudget *resilience.RetryBudget
 
+	queue       *requestQueue
+	concurrency *concurrencyLimiter
+
 	registry *AuthRegistry
+
+	cachedContent cachedContentAffinity
+
+	resultSink atomic.Pointer[func(Result)]
 }
 
 // NewManager constructs a manager with optional custom selector and hook.
@@ -127,11 +172,17 @@ func NewManager(store Store, selector Selector, hook Hook) *Manager {
 		hook:              hook,
 		auths:             make(map[string]*Auth),
 		providerStats:     NewProviderStats(),
+		latency:           newLatencyTracker(),
 		breakers:          make(map[string]*resilience.CircuitBreaker),
 		streamingBreakers: make(map[string]*resilience.StreamingCircuitBreaker),
 		retryBudget:       resilience.NewRetryBudget(100),
 		refreshSem:        newRefreshSemaphore(),
+		concurrency:       newConcurrencyLimiter(),
 	}
+	m.queue = newRequestQueue(func(model string, w *queueWaiter) bool {
+		ready, _, _ := m.queueAvailability(w.providers, model, w.stream)
+		return ready
+	})
 	m.registry = NewAuthRegistry(store, hook)
 	m.registry.SetExecutorProvider(m.executorFor)
 	m.registry.Start()
@@ -146,6 +197,7 @@ func (m *Manager) Stop() {
 	if m.refreshCancel != nil {
 		m.refreshCancel()
 	}
+	m.stopHealthProbe()
 	if m.registry != nil {
 		m.registry.Stop()
 	}
@@ -186,6 +238,15 @@ func (m *Manager) SetRetryConfig(retry int, maxRetryInterval time.Duration) {
 	m.maxRetryInterval.Store(maxRetryInterval.Nanoseconds())
 }
 
+// SetRefreshLead configures how long before credential expiry the background
+// refresher proactively rotates tokens.
+func (m *Manager) SetRefreshLead(lead time.Duration) {
+	if m == nil || m.registry == nil {
+		return
+	}
+	m.registry.SetRefreshLead(lead)
+}
+
 // RegisterExecutor registers a provider executor with the manager.
 func (m *Manager) RegisterExecutor(executor ProviderExecutor) {
 	if executor == nil {
@@ -244,6 +305,11 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	if m.registry != nil {
 		_, _ = m.registry.Update(ctx, auth)
 	}
+	if auth.Disabled {
+		// Removed accounts are disabled rather than deleted.
+		m.forgetSelection(auth.ID)
+		m.concurrency.forget(auth.ID)
+	}
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthUpdated(ctx, auth.Clone())
 
@@ -277,11 +343,19 @@ func (m *Manager) Load(ctx context.Context) error {
 
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model with weighted selection based on performance.
+// With the request queue enabled, it waits for a credential when all of them are cooling down.
 func (m *Manager) Execute(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	ctx = WithRequestAnalytics(ctx, req.Model)
+	return runQueued(ctx, m, normalized, req.Model, false, func() (Response, error) {
+		return m.execute(ctx, normalized, req, opts)
+	})
+}
+
+func (m *Manager) execute(ctx context.Context, normalized []string, req Request, opts Options) (Response, error) {
 	selected := m.selectProviders(req.Model, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
@@ -341,10 +415,68 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req Request,
 // ExecuteCount performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model with weighted selection based on performance.
 func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
-	normalized := m.normalizeProviders(providers)
+	return m.executeUnary(ctx, m.normalizeProviders(providers), req, opts, countTokensCall)
+}
+
+// ExecuteEmbed produces embeddings through the providers whose executors implement
+// Embedder, using the same selection, retry and result tracking as ExecuteCount.
+func (m *Manager) ExecuteEmbed(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
+	var capable []string
+	for _, provider := range m.normalizeProviders(providers) {
+		if _, ok := m.executorFor(provider).(Embedder); ok {
+			capable = append(capable, provider)
+		}
+	}
+	if len(capable) == 0 {
+		return Response{}, &Error{Code: "embeddings_not_supported", Message: "no provider for this model supports embeddings", HTTPStatus: http.StatusBadRequest}
+	}
+	return m.executeUnary(ctx, capable, req, opts, embedCall)
+}
+
+// ExecuteRerank reranks documents through the providers whose executors implement
+// Reranker. Models served only by providers without native rerank return 501.
+func (m *Manager) ExecuteRerank(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
+	var capable []string
+	for _, provider := range m.normalizeProviders(providers) {
+		if _, ok := m.executorFor(provider).(Reranker); ok {
+			capable = append(capable, provider)
+		}
+	}
+	if len(capable) == 0 {
+		return Response{}, &Error{Code: "rerank_not_supported", Message: "no provider for this model supports rerank", HTTPStatus: http.StatusNotImplemented}
+	}
+	return m.executeUnary(ctx, capable, req, opts, rerankCall)
+}
+
+// unaryCall invokes a single non-streaming executor operation.
+type unaryCall func(ctx context.Context, executor ProviderExecutor, auth *Auth, req Request, opts Options) (Response, error)
+
+func countTokensCall(ctx context.Context, executor ProviderExecutor, auth *Auth, req Request, opts Options) (Response, error) {
+	return executor.CountTokens(ctx, auth, req, opts)
+}
+
+func embedCall(ctx context.Context, executor ProviderExecutor, auth *Auth, req Request, opts Options) (Response, error) {
+	embedder, ok := executor.(Embedder)
+	if !ok {
+		return Response{}, &Error{Code: "embeddings_not_supported", Message: "provider does not support embeddings", HTTPStatus: http.StatusBadRequest}
+	}
+	return embedder.Embed(ctx, auth, req, opts)
+}
+
+func rerankCall(ctx context.Context, executor ProviderExecutor, auth *Auth, req Request, opts Options) (Response, error) {
+	reranker, ok := executor.(Reranker)
+	if !ok {
+		return Response{}, &Error{Code: "rerank_not_supported", Message: "provider does not support rerank", HTTPStatus: http.StatusNotImplemented}
+	}
+	return reranker.Rerank(ctx, auth, req, opts)
+}
+
+// executeUnary retries a unary operation across providers and attempts.
+func (m *Manager) executeUnary(ctx context.Context, normalized []string, req Request, opts Options, call unaryCall) (Response, error) {
 	if len(normalized) == 0 {
 		return Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	ctx = WithRequestAnalytics(ctx, req.Model)
 	selected := m.selectProviders(req.Model, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
@@ -367,7 +499,7 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Requ
 		start := time.Now()
 		resp, errExec := m.executeProvidersOnce(ctx, selected, func(execCtx context.Context, provider string) (Response, error) {
 			lastProvider = provider
-			return m.executeCountWithProvider(execCtx, provider, req, opts)
+			return m.executeUnaryWithProvider(execCtx, provider, req, opts, call)
 		})
 		latency := time.Since(start)
 
@@ -402,11 +534,19 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Requ
 // ExecuteStream performs a streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model with weighted selection based on performance.
 // Stats tracking is now consolidated in executeStreamWithProvider to reduce wrapper overhead.
+// With the request queue enabled, it waits for a credential when all of them are cooling down.
 func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req Request, opts Options) (<-chan StreamChunk, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	ctx = WithRequestAnalytics(ctx, req.Model)
+	return runQueued(ctx, m, normalized, req.Model, true, func() (<-chan StreamChunk, error) {
+		return m.executeStream(ctx, normalized, req, opts)
+	})
+}
+
+func (m *Manager) executeStream(ctx context.Context, normalized []string, req Request, opts Options) (<-chan StreamChunk, error) {
 	selected := m.selectProviders(req.Model, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
@@ -463,17 +603,38 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	if result.AuthID == "" {
 		return
 	}
+	if result.RequestID == "" {
+		result.RequestID = log.RequestIDFromContext(ctx)
+	}
+	if result.APIKeyID == "" {
+		result.APIKeyID = usage.APIKeyIDFromContext(ctx)
+	}
+	m.annotateResult(ctx, &result)
+	if sink := m.resultSink.Load(); sink != nil {
+		(*sink)(result)
+	}
+	if result.Success && m.latency != nil {
+		m.latency.record(result.AuthID, result.FirstByteLatency, result.Latency, time.Now())
+	}
 	// Delegate to AuthRegistry for lock-free path
 	if m.registry != nil {
 		m.registry.MarkResult(ctx, result)
-		if result.Error != nil && result.Error.HTTPStatus == 429 {
+		if result.Canceled {
+			return
+		}
+		if isQuotaHit(result.Error) {
 			if qm, ok := m.selector.(*QuotaManager); ok {
 				qm.RecordQuotaHit(result.AuthID, result.Provider, result.Model, result.RetryAfter)
 			}
 		}
+		m.observeStateTransition(result)
+		m.syncProviderAvailability(result)
 		return
 	}
 	// Fallback to sync processing when registry is not available (legacy mode)
+	if result.Canceled {
+		return
+	}
 	m.markResultSync(ctx, result)
 }
 
@@ -544,59 +705,64 @@ func (m *Manager) markResultSync(ctx context.Context, result Result) {
 					auth.LastError = cloneError(result.Error)
 					auth.StatusMessage = result.Error.Message
 				}
-				switch statusCode {
-				case 401:
-					next := now.Add(30 * time.Minute)
-					state.NextRetryAfter = next
-					suspendReason = "unauthorized"
-					shouldSuspendModel = true
-				case 402, 403:
-					next := now.Add(30 * time.Minute)
-					state.NextRetryAfter = next
-					suspendReason = "payment_required"
+				if category == CategoryBillingError {
+					// Billing exhaustion often arrives as a 429 but will not clear
+					// on its own, so it skips the quota backoff.
+					state.NextRetryAfter = now.Add(billingSuspension)
+					state.StatusMessage = BillingStatusMessage
+					auth.StatusMessage = BillingStatusMessage
+					suspendReason = BillingStatusMessage
 					shouldSuspendModel = true
-				case 404:
-					next := now.Add(12 * time.Hour)
-					state.NextRetryAfter = next
-					suspendReason = "not_found"
-					shouldSuspendModel = true
-				case 429:
-					var next time.Time
-					backoffLevel := state.Quota.BackoffLevel
-					if result.RetryAfter != nil {
-						next = now.Add(*result.RetryAfter)
-					} else {
-						cooldown, nextLevel := nextQuotaCooldown(backoffLevel)
+				} else {
+					switch statusCode {
+					case 401:
+						next := now.Add(30 * time.Minute)
+						state.NextRetryAfter = next
+						suspendReason = "unauthorized"
+						shouldSuspendModel = true
+					case 402, 403:
+						next := now.Add(30 * time.Minute)
+						state.NextRetryAfter = next
+						suspendReason = "payment_required"
+						shouldSuspendModel = true
+					case 404:
+						next := now.Add(12 * time.Hour)
+						state.NextRetryAfter = next
+						suspendReason = "not_found"
+						shouldSuspendModel = true
+					case 429:
+						var next time.Time
+						scope := ClassifyQuotaScope(errMsg)
+						cooldown, backoffLevel := quotaCooldown(result.RetryAfter, scope, state.Quota.BackoffLevel)
 						if cooldown > 0 {
 							next = now.Add(cooldown)
 						}
-						backoffLevel = nextLevel
-					}
-					state.NextRetryAfter = next
-					state.Quota = QuotaState{
-						Exceeded:      true,
-						Reason:        "quota",
-						NextRecoverAt: next,
-						BackoffLevel:  backoffLevel,
-					}
-					suspendReason = "quota"
-					shouldSuspendModel = true
-					setModelQuota = true
-
-					// Propagate quota to all models in the same quota group
-					// (e.g., for Antigravity: all Claude models share quota)
-					affectedModels := propagateQuotaToGroup(auth, result.Model, state.Quota, next, now)
-					for _, affectedModel := range affectedModels {
-						registry.GetGlobalRegistry().SetModelQuotaExceeded(result.AuthID, affectedModel)
-						registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, affectedModel, "quota_group")
+						state.NextRetryAfter = next
+						state.Quota = QuotaState{
+							Exceeded:      true,
+							Reason:        scope.String(),
+							NextRecoverAt: next,
+							BackoffLevel:  backoffLevel,
+						}
+						suspendReason = "quota"
+						shouldSuspendModel = true
+						setModelQuota = true
+
+						// Propagate quota to all models in the same quota group
+						// (e.g., for Antigravity: all Claude models share quota)
+						affectedModels := propagateQuotaToGroup(auth, result.Model, state.Quota, next, now)
+						for _, affectedModel := range affectedModels {
+							registry.GetGlobalRegistry().SetModelQuotaExceeded(result.AuthID, affectedModel)
+							registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, affectedModel, "quota_group")
+						}
+					case 408, 500, 502, 503, 504:
+						next := now.Add(1 * time.Minute)
+						state.NextRetryAfter = next
+					default:
+						// Unknown/unhandled errors (network failures, parsing errors, etc.)
+						// Set short cooldown to enable auto-recovery via updateAggregatedAvailability
+						state.NextRetryAfter = now.Add(30 * time.Second)
 					}
-				case 408, 500, 502, 503, 504:
-					next := now.Add(1 * time.Minute)
-					state.NextRetryAfter = next
-				default:
-					// Unknown/unhandled errors (network failures, parsing errors, etc.)
-					// Set short cooldown to enable auto-recovery via updateAggregatedAvailability
-					state.NextRetryAfter = now.Add(30 * time.Second)
 				}
 
 				// Only update auth-level status for non-user errors
@@ -626,12 +792,14 @@ func (m *Manager) markResultSync(ctx context.Context, result Result) {
 		registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, result.Model, suspendReason)
 	}
 
-	if result.Error != nil && result.Error.HTTPStatus == 429 {
+	if isQuotaHit(result.Error) {
 		if qm, ok := m.selector.(*QuotaManager); ok {
 			qm.RecordQuotaHit(result.AuthID, result.Provider, result.Model, result.RetryAfter)
 		}
 	}
 
+	m.observeStateTransition(result)
+	m.syncProviderAvailability(result)
 	m.hook.OnResult(ctx, result)
 }
 
@@ -643,12 +811,17 @@ func (m *Manager) GetProviderStats() map[string]map[string]int64 {
 	return m.providerStats.Stats()
 }
 
-// CleanupProviderStats removes stale provider statistics older than maxAge.
+// CleanupProviderStats removes stale provider statistics older than maxAge,
+// and the latency histograms of accounts that served no request recently.
 func (m *Manager) CleanupProviderStats(maxAge time.Duration) int {
 	if m.providerStats == nil {
 		return 0
 	}
-	return m.providerStats.Cleanup(maxAge)
+	removed := m.providerStats.Cleanup(maxAge)
+	if m.latency != nil {
+		removed += m.latency.cleanup(time.Now())
+	}
+	return removed
 }
 
 // List returns all auth entries currently known by the manager.
@@ -695,10 +868,14 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts Opt
 	// Collect candidate pointers under lock (cheap - no cloning yet)
 	candidatePtrs := make([]*Auth, 0, len(m.auths))
 	registryRef := registry.GetGlobalRegistry()
+	pinned := pinnedAuth(ctx)
 	for _, candidate := range m.auths {
 		if candidate.Provider != provider || candidate.Disabled {
 			continue
 		}
+		if pinned != "" && candidate.ID != pinned {
+			continue
+		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
@@ -721,7 +898,11 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts Opt
 	m.mu.RUnlock()
 
 	// Phase 3: Selector runs outside lock - OK because we have cloned data
-	selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
+	preferred := m.preferCachedContentAuths(provider, opts, candidates)
+	selected, errPick := m.selector.Pick(ctx, provider, model, opts, preferred)
+	if errPick != nil && len(preferred) < len(candidates) {
+		selected, errPick = m.selector.Pick(ctx, provider, model, opts, candidates)
+	}
 	if errPick != nil {
 		return nil, nil, errPick
 	}
@@ -761,10 +942,14 @@ func (m *Manager) pickNextFromRegistry(ctx context.Context, provider, model stri
 
 	var entries []*AuthEntry
 	registryRef := registry.GetGlobalRegistry()
+	pinned := pinnedAuth(ctx)
 	for _, entry := range m.registry.ListByProvider(provider) {
 		if entry.IsDisabled() {
 			continue
 		}
+		if pinned != "" && entry.ID() != pinned {
+			continue
+		}
 		if _, used := tried[entry.ID()]; used {
 			continue
 		}
@@ -778,7 +963,11 @@ func (m *Manager) pickNextFromRegistry(ctx context.Context, provider, model stri
 		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
 
-	selected, errPick := m.registry.Pick(ctx, provider, model, opts, entries)
+	preferred := m.preferCachedContentEntries(provider, opts, entries)
+	selected, errPick := m.registry.Pick(ctx, provider, model, opts, preferred)
+	if errPick != nil && len(preferred) < len(entries) {
+		selected, errPick = m.registry.Pick(ctx, provider, model, opts, entries)
+	}
 	if errPick != nil {
 		return nil, nil, errPick
 	}
@@ -900,6 +1089,20 @@ type RoundTripperProvider interface {
 	RoundTripperFor(auth *Auth) http.RoundTripper
 }
 
+// Embedder is an optional interface implemented by provider executors that can
+// produce embeddings. Requests carry an OpenAI embeddings payload and responses
+// return the OpenAI embeddings response shape.
+type Embedder interface {
+	Embed(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
+}
+
+// Reranker is an optional interface implemented by provider executors that can
+// rerank documents against a query. Requests and responses use the Cohere/Jina
+// rerank payload shape.
+type Reranker interface {
+	Rerank(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
+}
+
 // RequestPreparer is an optional interface that provider executors can implement
 // to mutate outbound HTTP requests with provider credentials.
 type RequestPreparer interface {
@@ -927,7 +1130,9 @@ func (m *Manager) PreWarmToken(auth *Auth) {
 
 // InjectCredentials delegates per-provider HTTP request preparation when supported.
 // If the registered executor for the auth provider implements RequestPreparer,
-// it will be invoked to modify the request (e.g., add headers).
+// it will be invoked to modify the request (e.g., add headers). If it implements
+// RequestSigner, the body is buffered first, so PrepareRequest can read it via
+// GetBody, and the signature headers are applied last.
 func (m *Manager) InjectCredentials(req *http.Request, authID string) error {
 	if req == nil || authID == "" {
 		return nil
@@ -942,6 +1147,9 @@ func (m *Manager) InjectCredentials(req *http.Request, authID string) error {
 	if a == nil || exec == nil {
 		return nil
 	}
+	if signer, ok := exec.(RequestSigner); ok && signer != nil {
+		return prepareAndSign(req, a, exec, signer)
+	}
 	if p, ok := exec.(RequestPreparer); ok && p != nil {
 		return p.PrepareRequest(req, a)
 	}
@@ -965,6 +1173,7 @@ func (m *Manager) getOrCreateBreaker(provider string) *resilience.CircuitBreaker
 	cfg := resilience.DefaultBreakerConfig("provider:" + provider)
 	cfg.OnStateChange = func(name string, from, to gobreaker.State) {
 		log.Infof("circuit breaker %s: %s -> %s", name, from, to)
+		breakerAvailability(provider, to, cfg.Timeout)
 	}
 	cb := resilience.NewCircuitBreaker(cfg)
 	m.breakers[provider] = cb
@@ -988,6 +1197,7 @@ func (m *Manager) getOrCreateStreamingBreaker(provider string) *resilience.Strea
 	cfg := resilience.DefaultBreakerConfig("streaming:" + provider)
 	cfg.OnStateChange = func(name string, from, to gobreaker.State) {
 		log.Infof("circuit breaker %s: %s -> %s", name, from, to)
+		breakerAvailability(provider, to, cfg.Timeout)
 	}
 	cb := resilience.NewStreamingCircuitBreaker(cfg)
 	m.streamingBreakers[provider] = cb
@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+type resultHook struct {
+	NoopHook
+	results chan Result
+}
+
+func (h *resultHook) OnResult(_ context.Context, result Result) { h.results <- result }
+
+func TestManager_MarkResultCarriesRequestID(t *testing.T) {
+	hook := &resultHook{results: make(chan Result, 1)}
+	m := NewManager(nil, nil, hook)
+	t.Cleanup(m.Stop)
+	if _, err := m.Register(context.Background(), &Auth{ID: "rid-auth", Provider: "ridtest"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	ctx := log.WithRequestID(context.Background(), "trace-42")
+	m.MarkResult(ctx, Result{AuthID: "rid-auth", Provider: "ridtest", Model: "m", Success: true})
+
+	if got := (<-hook.results).RequestID; got != "trace-42" {
+		t.Errorf("Expected result to carry the request ID, got %q", got)
+	}
+}
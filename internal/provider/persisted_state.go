@@ -0,0 +1,78 @@
+package provider
+
+import "time"
+
+// PersistedState captures the cooldown and backoff state of an auth that must
+// survive process restarts, so a restarted server keeps honoring in-flight
+// quota suspensions instead of re-probing accounts that are still limited.
+type PersistedState struct {
+	NextRetryAfter time.Time              `json:"next_retry_after,omitempty"`
+	Quota          QuotaState             `json:"quota"`
+	ModelStates    map[string]*ModelState `json:"model_states,omitempty"`
+}
+
+// SnapshotState extracts the restart-relevant state from the auth.
+// Cooldowns that already elapsed are omitted; nil is returned when nothing
+// remains worth persisting.
+func (a *Auth) SnapshotState(now time.Time) *PersistedState {
+	if a == nil {
+		return nil
+	}
+	state := &PersistedState{}
+	keep := false
+	if a.NextRetryAfter.After(now) {
+		state.NextRetryAfter = a.NextRetryAfter
+		keep = true
+	}
+	if a.Quota.Exceeded && a.Quota.NextRecoverAt.After(now) {
+		state.Quota = a.Quota
+		keep = true
+	}
+	for model, ms := range a.ModelStates {
+		if !modelStateActive(ms, now) {
+			continue
+		}
+		if state.ModelStates == nil {
+			state.ModelStates = make(map[string]*ModelState)
+		}
+		state.ModelStates[model] = ms.Clone()
+		keep = true
+	}
+	if !keep {
+		return nil
+	}
+	return state
+}
+
+// RestoreState applies a previously persisted state to the auth.
+// Cooldowns that expired while the process was down are dropped so the
+// affected models become immediately selectable again.
+func (a *Auth) RestoreState(state *PersistedState, now time.Time) {
+	if a == nil || state == nil {
+		return
+	}
+	if state.NextRetryAfter.After(now) {
+		a.NextRetryAfter = state.NextRetryAfter
+	}
+	if state.Quota.Exceeded && state.Quota.NextRecoverAt.After(now) {
+		a.Quota = state.Quota
+		a.Unavailable = true
+	}
+	for model, ms := range state.ModelStates {
+		if !modelStateActive(ms, now) {
+			continue
+		}
+		if a.ModelStates == nil {
+			a.ModelStates = make(map[string]*ModelState)
+		}
+		a.ModelStates[model] = ms.Clone()
+	}
+}
+
+// modelStateActive reports whether the model state still blocks selection at now.
+func modelStateActive(ms *ModelState, now time.Time) bool {
+	if ms == nil || !ms.Unavailable {
+		return false
+	}
+	return ms.NextRetryAfter.After(now) || ms.Quota.NextRecoverAt.After(now)
+}
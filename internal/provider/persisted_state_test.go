@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+// stateStore simulates a persistent backend by round-tripping the persisted
+// state through JSON, mirroring the sidecar written by the file token store.
+type stateStore struct {
+	mu     sync.Mutex
+	auths  map[string]*Auth
+	states map[string][]byte
+}
+
+func newStateStore() *stateStore {
+	return &stateStore{auths: make(map[string]*Auth), states: make(map[string][]byte)}
+}
+
+func (s *stateStore) List(ctx context.Context) ([]*Auth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Auth, 0, len(s.auths))
+	for id, stored := range s.auths {
+		auth := &Auth{ID: id, Provider: stored.Provider, Metadata: stored.Metadata}
+		if raw, ok := s.states[id]; ok {
+			var state PersistedState
+			if err := json.Unmarshal(raw, &state); err != nil {
+				return nil, err
+			}
+			auth.RestoreState(&state, time.Now())
+		}
+		out = append(out, auth)
+	}
+	return out, nil
+}
+
+func (s *stateStore) Save(ctx context.Context, auth *Auth) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auths[auth.ID] = auth.Clone()
+	delete(s.states, auth.ID)
+	if state := auth.SnapshotState(time.Now()); state != nil {
+		raw, err := json.Marshal(state)
+		if err != nil {
+			return "", err
+		}
+		s.states[auth.ID] = raw
+	}
+	return auth.ID, nil
+}
+
+func (s *stateStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.auths, id)
+	delete(s.states, id)
+	return nil
+}
+
+func TestPersistedState_SurvivesSaveLoad(t *testing.T) {
+	store := newStateStore()
+	ctx := context.Background()
+
+	registry := NewAuthRegistry(store, nil)
+	_, _ = registry.Register(ctx, &Auth{
+		ID:       "persist-1",
+		Provider: "claude",
+		Metadata: map[string]any{"email": "a@example.com"},
+	})
+	retryAfter := 10 * time.Minute
+	registry.MarkResult(ctx, Result{
+		AuthID:     "persist-1",
+		Provider:   "claude",
+		Model:      "claude-sonnet-4",
+		Error:      &Error{HTTPStatus: 429, Message: "rate limit exceeded"},
+		RetryAfter: &retryAfter,
+	})
+	if _, err := store.Save(ctx, registry.Get("persist-1")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	restarted := NewAuthRegistry(store, nil)
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	entry := restarted.GetEntry("persist-1")
+	if entry == nil {
+		t.Fatal("Expected auth to be restored")
+	}
+	blocked, reason, retryAt := entry.IsBlockedForModel("claude-sonnet-4", time.Now())
+	if !blocked || reason != blockReasonCooldown {
+		t.Fatalf("Expected model to remain in cooldown after restart, got blocked=%v reason=%v", blocked, reason)
+	}
+	if time.Until(retryAt) < 9*time.Minute {
+		t.Errorf("Expected cooldown to be preserved, retry at %v", retryAt)
+	}
+}
+
+func TestPersistedState_ExpiredCooldownClears(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	state := &PersistedState{
+		NextRetryAfter: past,
+		Quota:          QuotaState{Exceeded: true, NextRecoverAt: past},
+		ModelStates: map[string]*ModelState{
+			"gemini-2.5-pro": {
+				Unavailable:    true,
+				NextRetryAfter: past,
+				Quota:          QuotaState{Exceeded: true, NextRecoverAt: past},
+			},
+		},
+	}
+
+	auth := &Auth{ID: "expired", Provider: "gemini"}
+	auth.RestoreState(state, now)
+
+	if !auth.NextRetryAfter.IsZero() || auth.Quota.Exceeded || auth.Unavailable {
+		t.Errorf("Expected auth-level cooldown to clear, got %+v", auth.Quota)
+	}
+	if len(auth.ModelStates) != 0 {
+		t.Errorf("Expected expired model states to be dropped, got %d", len(auth.ModelStates))
+	}
+	if auth.SnapshotState(now) != nil {
+		t.Error("Expected nothing to persist once cooldowns expired")
+	}
+}
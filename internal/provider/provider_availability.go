@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/sony/gobreaker"
+)
+
+// providerDownFallback bounds the down mark of a provider whose accounts are
+// all disabled and therefore carry no retry time.
+const providerDownFallback = 30 * time.Second
+
+// syncProviderAvailability marks result's provider down in the model registry
+// once every account of it is suspended, and clears the mark when it serves a
+// request again.
+func (m *Manager) syncProviderAvailability(result Result) {
+	if result.Provider == "" {
+		return
+	}
+	reg := registry.GetGlobalRegistry()
+	if result.Success {
+		reg.SetProviderDownUntil(result.Provider, time.Time{})
+		return
+	}
+	now := time.Now()
+	auths := m.providerAuths(result.Provider)
+	if !providerSuspended(auths, result.Provider, now) {
+		return
+	}
+	reg.SetProviderDownUntil(result.Provider, providerRecoveryTime(auths, result.Provider, now))
+}
+
+// providerAuths returns the accounts of provider only, so a failure does not
+// copy every registered account.
+func (m *Manager) providerAuths(provider string) []*Auth {
+	if m.registry == nil {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		var out []*Auth
+		for _, a := range m.auths {
+			if a.Provider == provider {
+				out = append(out, a.Clone())
+			}
+		}
+		return out
+	}
+	entries := m.registry.ListByProvider(provider)
+	out := make([]*Auth, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry.ToAuth())
+	}
+	return out
+}
+
+// providerRecoveryTime returns the earliest time an account of provider may
+// serve requests again.
+func providerRecoveryTime(auths []*Auth, provider string, now time.Time) time.Time {
+	var earliest time.Time
+	consider := func(t time.Time) {
+		if t.After(now) && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+	for _, a := range auths {
+		if a == nil || a.Provider != provider || a.Disabled || a.Status == StatusDisabled {
+			continue
+		}
+		if a.Unavailable {
+			consider(a.NextRetryAfter)
+			continue
+		}
+		// Every model is suspended; the account recovers with its first model.
+		for _, state := range a.ModelStates {
+			if state != nil {
+				consider(state.NextRetryAfter)
+			}
+		}
+	}
+	if earliest.IsZero() {
+		return now.Add(providerDownFallback)
+	}
+	return earliest
+}
+
+// breakerAvailability mirrors a provider circuit breaker transition into the
+// model registry: an open breaker marks the provider down until it half-opens.
+func breakerAvailability(provider string, to gobreaker.State, timeout time.Duration) {
+	reg := registry.GetGlobalRegistry()
+	if to == gobreaker.StateOpen {
+		reg.SetProviderDownUntil(provider, time.Now().Add(timeout))
+		return
+	}
+	reg.SetProviderDownUntil(provider, time.Time{})
+}
+
+// ProviderHealth summarizes whether a provider can currently serve requests.
+type ProviderHealth struct {
+	Provider  string     `json:"provider"`
+	Status    string     `json:"status"`
+	Accounts  int        `json:"accounts"`
+	Ready     int        `json:"ready"`
+	Circuit   string     `json:"circuit"`
+	DownUntil *time.Time `json:"down_until,omitempty"`
+}
+
+// Provider health statuses.
+const (
+	ProviderAvailable   = "available"
+	ProviderUnavailable = "unavailable"
+)
+
+// ProviderHealth reports every provider with registered accounts, sorted by
+// name. A provider is unavailable when its circuit breaker is open or none of
+// its accounts can serve a request.
+func (m *Manager) ProviderHealth() []ProviderHealth {
+	now := time.Now()
+	byProvider := make(map[string]*ProviderHealth)
+	for _, a := range m.listAuths() {
+		if a == nil || a.Provider == "" {
+			continue
+		}
+		h := byProvider[a.Provider]
+		if h == nil {
+			h = &ProviderHealth{Provider: a.Provider}
+			byProvider[a.Provider] = h
+		}
+		h.Accounts++
+		if !authSuspended(a, now) {
+			h.Ready++
+		}
+	}
+	out := make([]ProviderHealth, 0, len(byProvider))
+	for name, h := range byProvider {
+		state := m.BreakerState(name)
+		h.Circuit = state.String()
+		if until, down := registry.GetGlobalRegistry().ProviderDownUntil(name); down {
+			h.DownUntil = &until
+		}
+		h.Status = ProviderAvailable
+		if h.Ready == 0 || state == gobreaker.StateOpen {
+			h.Status = ProviderUnavailable
+		}
+		out = append(out, *h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestProviderAvailability_FamilyFallsBackWhenAccountsDown(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	ctx := context.Background()
+	reg := registry.GetGlobalRegistry()
+	const canonical = "avail-family-model"
+	accounts := map[string]string{
+		"availdown-1": "availdown",
+		"availdown-2": "availdown",
+		"availup-1":   "availup",
+	}
+	for id, prov := range accounts {
+		if _, err := m.Register(ctx, &Auth{ID: id, Provider: prov, Status: StatusActive}); err != nil {
+			t.Fatalf("Register %s: %v", id, err)
+		}
+		reg.RegisterClient(id, prov, []*registry.ModelInfo{{ID: prov + "-model", CanonicalID: canonical}})
+	}
+	t.Cleanup(func() {
+		for id := range accounts {
+			reg.UnregisterClient(id)
+		}
+		reg.SetProviderDownUntil("availdown", time.Time{})
+	})
+	if got := reg.GetModelProviders(canonical); len(got) != 2 {
+		t.Fatalf("providers = %v, want both", got)
+	}
+
+	failure := func(id string) Result {
+		return Result{AuthID: id, Provider: "availdown", Model: "availdown-model", Error: &Error{HTTPStatus: http.StatusUnauthorized, Message: "unauthorized"}}
+	}
+	m.MarkResult(ctx, failure("availdown-1"))
+	if _, down := reg.ProviderDownUntil("availdown"); down {
+		t.Fatal("provider marked down while an account is still usable")
+	}
+	m.MarkResult(ctx, failure("availdown-2"))
+	if _, down := reg.ProviderDownUntil("availdown"); !down {
+		t.Fatal("provider not marked down after every account failed")
+	}
+	if got := reg.GetModelProviders(canonical); len(got) != 1 || got[0] != "availup" {
+		t.Errorf("providers with availdown down = %v, want [availup]", got)
+	}
+	for _, h := range m.ProviderHealth() {
+		want := ProviderAvailable
+		if h.Provider == "availdown" {
+			want = ProviderUnavailable
+		}
+		if h.Status != want {
+			t.Errorf("%s health = %+v, want %s", h.Provider, h, want)
+		}
+	}
+
+	m.MarkResult(ctx, Result{AuthID: "availdown-1", Provider: "availdown", Model: "availdown-model", Success: true})
+	if _, down := reg.ProviderDownUntil("availdown"); down {
+		t.Error("provider still marked down after a success")
+	}
+	if got := reg.GetModelProviders(canonical); len(got) != 2 {
+		t.Errorf("providers after recovery = %v, want both", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/metrics"
 	"github.com/sony/gobreaker"
 )
 
@@ -56,6 +57,13 @@ func (m *Manager) selectProviders(model string, providers []string) []string {
 
 // recordProviderResult records success/failure for weighted selection.
 func (m *Manager) recordProviderResult(provider, model string, success bool, latency time.Duration) {
+	outcome := "success"
+	if !success {
+		outcome = "failure"
+	}
+	metrics.ExecutorRequests.Inc(metrics.L(provider, outcome))
+	metrics.ExecutorDuration.Observe(latency.Seconds(), metrics.L(provider))
+
 	stats := m.providerStats
 	if stats == nil {
 		return
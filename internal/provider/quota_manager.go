@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
-	"math/rand/v2"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -139,6 +138,8 @@ type QuotaManager struct {
 
 	refreshMu      sync.Mutex
 	refreshCancels map[string]context.CancelFunc
+
+	selection atomic.Pointer[selectionStrategy] // nil picks at random
 }
 
 var quotaHasherPool = sync.Pool{
@@ -244,7 +245,7 @@ func (m *QuotaManager) Pick(
 		}
 	}
 
-	selected := m.selectWithStrategy(available, config, strategy)
+	selected := m.selectWithStrategy(provider, available, config, strategy)
 
 	if config.StickyEnabled {
 		m.sticky.Set(provider+":"+model, selected.ID)
@@ -254,7 +255,7 @@ func (m *QuotaManager) Pick(
 	return selected, nil
 }
 
-func (m *QuotaManager) selectWithStrategy(auths []*Auth, config *ProviderQuotaConfig, strategy ProviderStrategy) *Auth {
+func (m *QuotaManager) selectWithStrategy(provider string, auths []*Auth, config *ProviderQuotaConfig, strategy ProviderStrategy) *Auth {
 	type scored struct {
 		auth     *Auth
 		priority int64
@@ -286,7 +287,14 @@ func (m *QuotaManager) selectWithStrategy(auths []*Auth, config *ProviderQuotaCo
 	}
 
 	if similarCount > 1 {
-		return candidates[rand.N(similarCount)].auth
+		tier := make([]*Auth, similarCount)
+		for i := range tier {
+			tier[i] = candidates[i].auth
+		}
+		if sel := m.selection.Load(); sel != nil {
+			return sel.selector.pick(provider, tier)
+		}
+		return pickRandom(tier)
 	}
 
 	return candidates[0].auth
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// ResetQuota clears the quota, backoff and retry state of an account so it is
+// selectable again right away, e.g. after its billing was topped up. With a
+// model only that model's state is cleared; otherwise the whole account is.
+// Disabled accounts and models stay disabled. Circuit breakers are kept per
+// provider, so the account's provider breakers are closed as well.
+// It returns the models whose state was cleared.
+func (m *Manager) ResetQuota(ctx context.Context, authID, model string) ([]string, error) {
+	if authID == "" {
+		return nil, &Error{Code: "auth_not_found", Message: "auth id is required"}
+	}
+	now := time.Now()
+	cleared := make(map[string]struct{})
+	found := false
+	providerName := ""
+
+	m.mu.Lock()
+	if auth, ok := m.auths[authID]; ok && auth != nil {
+		found = true
+		providerName = auth.Provider
+		for name, state := range auth.ModelStates {
+			if state == nil || state.Status == StatusDisabled || (model != "" && name != model) {
+				continue
+			}
+			resetModelState(state, now)
+			cleared[name] = struct{}{}
+		}
+		if model == "" {
+			if auth.Status != StatusDisabled {
+				clearAuthStateOnSuccess(auth, now)
+			}
+			auth.Quota = QuotaState{}
+		} else {
+			updateAggregatedAvailability(auth, now)
+			if !hasModelError(auth, now) && auth.Status != StatusDisabled {
+				auth.LastError = nil
+				auth.StatusMessage = ""
+				auth.Status = StatusActive
+			}
+			auth.UpdatedAt = now
+		}
+		_ = m.persist(ctx, auth)
+	}
+	m.mu.Unlock()
+
+	if m.registry != nil {
+		if entry := m.registry.GetEntry(authID); entry != nil {
+			found = true
+			providerName = entry.Provider()
+			for _, name := range m.registry.resetQuota(entry, model, now) {
+				cleared[name] = struct{}{}
+			}
+		}
+	}
+	if !found {
+		return nil, &Error{Code: "auth_not_found", Message: "auth not found"}
+	}
+
+	if model == "" {
+		if qm := m.GetQuotaManager(); qm != nil {
+			if state := qm.getState(authID); state != nil {
+				state.SetCooldownUntil(time.Time{})
+				state.SetRealQuota(nil)
+			}
+		}
+	} else {
+		cleared[model] = struct{}{}
+	}
+
+	models := make([]string, 0, len(cleared))
+	reg := registry.GetGlobalRegistry()
+	for name := range cleared {
+		reg.ClearModelQuotaExceeded(authID, name)
+		reg.ResumeClientModel(authID, name)
+		models = append(models, name)
+	}
+	sort.Strings(models)
+
+	m.resetBreakers(providerName)
+	reg.SetProviderDownUntil(providerName, time.Time{})
+	return models, nil
+}
+
+// resetBreakers replaces the circuit breakers of provider with closed ones.
+func (m *Manager) resetBreakers(provider string) {
+	if provider == "" {
+		return
+	}
+	m.breakerMu.Lock()
+	delete(m.breakers, provider)
+	delete(m.streamingBreakers, provider)
+	m.breakerMu.Unlock()
+}
+
+// resetQuota clears the quota and retry state of entry, or only of model when
+// set, and returns the models whose state was cleared.
+func (r *AuthRegistry) resetQuota(entry *AuthEntry, model string, now time.Time) []string {
+	var cleared []string
+	entry.UpdateAllModelStates(func(old *ModelStatesSnapshot) *ModelStatesSnapshot {
+		cleared = cleared[:0]
+		next := old.Clone()
+		for name, state := range next.States {
+			if state.Status == StatusDisabled || (model != "" && name != model) {
+				continue
+			}
+			next.States[name] = ModelStateSnapshot{Status: StatusActive, UpdatedAt: now.UnixNano()}
+			cleared = append(cleared, name)
+		}
+		return next
+	})
+	if model == "" {
+		entry.UpdateMetadata(func(old *AuthMetadata) *AuthMetadata {
+			if old.Status == StatusDisabled {
+				return nil
+			}
+			return old.ClearError()
+		})
+		entry.ClearCooldown()
+		entry.Quota.RealQuota.Store(nil)
+	}
+	r.markDirty(entry.ID())
+	return cleared
+}
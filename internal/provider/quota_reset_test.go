@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestManager_ResetQuotaMakesAccountSelectable(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	ctx := context.Background()
+	m.RegisterExecutor(&chatOnlyExecutor{id: "resetq"})
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("resetq-1", "resetq", []*registry.ModelInfo{{ID: "resetq-model"}})
+	t.Cleanup(func() {
+		reg.UnregisterClient("resetq-1")
+		reg.SetProviderDownUntil("resetq", time.Time{})
+	})
+	if _, err := m.Register(ctx, &Auth{ID: "resetq-1", Provider: "resetq", Status: StatusActive}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	m.MarkResult(ctx, Result{AuthID: "resetq-1", Provider: "resetq", Model: "resetq-model", Error: &Error{HTTPStatus: http.StatusTooManyRequests, Message: "rate limit exceeded"}})
+	if auth, _, err := m.pickNextFromRegistry(ctx, "resetq", "resetq-model", Options{}, map[string]struct{}{}); err == nil {
+		t.Fatalf("Expected no candidate during the quota cooldown, got %s", auth.ID)
+	}
+
+	if _, err := m.ResetQuota(ctx, "missing", ""); err == nil {
+		t.Error("Expected an error resetting an unknown account")
+	}
+	models, err := m.ResetQuota(ctx, "resetq-1", "resetq-model")
+	if err != nil {
+		t.Fatalf("ResetQuota failed: %v", err)
+	}
+	if len(models) != 1 || models[0] != "resetq-model" {
+		t.Errorf("Expected resetq-model to be reset, got %v", models)
+	}
+	auth, _, err := m.pickNextFromRegistry(ctx, "resetq", "resetq-model", Options{}, map[string]struct{}{})
+	if err != nil || auth.ID != "resetq-1" {
+		t.Fatalf("Expected resetq-1 after the reset, got auth=%v err=%v", auth, err)
+	}
+	if _, down := reg.ProviderDownUntil("resetq"); down {
+		t.Error("Expected the provider not to be marked down after the reset")
+	}
+}
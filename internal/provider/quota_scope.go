@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// QuotaScope distinguishes short-lived rate limits from hard quota exhaustion.
+// Gemini reports both as 429 RESOURCE_EXHAUSTED, but they recover on very
+// different timescales.
+type QuotaScope int
+
+const (
+	// QuotaScopeUnknown is used when the error body carries no usable hint.
+	QuotaScopeUnknown QuotaScope = iota
+	// QuotaScopeRateLimit covers per-second/per-minute limits that recover in seconds.
+	QuotaScopeRateLimit
+	// QuotaScopeDaily covers per-day quotas that recover at the next daily reset.
+	QuotaScopeDaily
+)
+
+const (
+	// rateLimitCooldown is applied to per-minute limits without a retry hint.
+	rateLimitCooldown = 15 * time.Second
+)
+
+// String returns the quota reason recorded in auth state.
+func (s QuotaScope) String() string {
+	switch s {
+	case QuotaScopeRateLimit:
+		return "rate_limit"
+	case QuotaScopeDaily:
+		return "quota_daily"
+	default:
+		return "quota"
+	}
+}
+
+// ClassifyQuotaScope inspects a 429 error body (Gemini google.rpc.Status JSON or
+// plain text) and reports whether it describes a per-minute or per-day limit.
+func ClassifyQuotaScope(message string) QuotaScope {
+	if message == "" {
+		return QuotaScopeUnknown
+	}
+	if gjson.Valid(message) {
+		root := gjson.Parse(message)
+		if root.IsArray() {
+			root = root.Get("0")
+		}
+		for _, detail := range root.Get("error.details").Array() {
+			for _, violation := range detail.Get("violations").Array() {
+				if scope := quotaScopeFromName(violation.Get("quotaId").String()); scope != QuotaScopeUnknown {
+					return scope
+				}
+				if scope := quotaScopeFromName(violation.Get("quotaMetric").String()); scope != QuotaScopeUnknown {
+					return scope
+				}
+			}
+			if scope := quotaScopeFromName(detail.Get("metadata.quota_limit").String()); scope != QuotaScopeUnknown {
+				return scope
+			}
+		}
+		if msg := root.Get("error.message").String(); msg != "" {
+			return quotaScopeFromName(msg)
+		}
+	}
+	return quotaScopeFromName(message)
+}
+
+// quotaScopeFromName matches quota identifiers such as
+// "GenerateRequestsPerMinutePerProjectPerModel" or free-text descriptions.
+func quotaScopeFromName(name string) QuotaScope {
+	if name == "" {
+		return QuotaScopeUnknown
+	}
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "perday"), strings.Contains(lower, "per day"),
+		strings.Contains(lower, "per_day"), strings.Contains(lower, "daily"):
+		return QuotaScopeDaily
+	case strings.Contains(lower, "perminute"), strings.Contains(lower, "per minute"),
+		strings.Contains(lower, "per_minute"), strings.Contains(lower, "persecond"),
+		strings.Contains(lower, "per second"):
+		return QuotaScopeRateLimit
+	}
+	return QuotaScopeUnknown
+}
+
+// quotaCooldown computes the cooldown for a quota error. A provider supplied
+// retry hint always wins; otherwise per-minute limits get a short fixed cooldown,
+// daily quotas jump straight to the maximum backoff, and unknown scopes keep the
+// exponential backoff keyed by level. The result is spread by the configured
+// retry jitter.
+func quotaCooldown(retryAfter *time.Duration, scope QuotaScope, level int) (time.Duration, int) {
+	if retryAfter != nil {
+		return JitterRetryAfter(*retryAfter, true), level
+	}
+	if quotaCooldownDisabled.Load() {
+		return 0, level
+	}
+	switch scope {
+	case QuotaScopeRateLimit:
+		return JitterRetryAfter(rateLimitCooldown, false), level
+	case QuotaScopeDaily:
+		return JitterRetryAfter(quotaBackoffMax, false), level
+	default:
+		cooldown, next := nextQuotaCooldown(level)
+		return JitterRetryAfter(cooldown, false), next
+	}
+}
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const geminiPerMinuteBody = `{"error":{"code":429,"message":"Quota exceeded for metric: generativelanguage.googleapis.com/generate_content_free_tier_requests, limit: 15","status":"RESOURCE_EXHAUSTED","details":[{"@type":"type.googleapis.com/google.rpc.QuotaFailure","violations":[{"quotaMetric":"generativelanguage.googleapis.com/generate_content_free_tier_requests","quotaId":"GenerateRequestsPerMinutePerProjectPerModel-FreeTier"}]}]}}`
+
+const geminiPerDayBody = `{"error":{"code":429,"message":"Quota exceeded for metric: generativelanguage.googleapis.com/generate_content_free_tier_requests, limit: 50","status":"RESOURCE_EXHAUSTED","details":[{"@type":"type.googleapis.com/google.rpc.QuotaFailure","violations":[{"quotaMetric":"generativelanguage.googleapis.com/generate_content_free_tier_requests","quotaId":"GenerateRequestsPerDayPerProjectPerModel-FreeTier"}]}]}}`
+
+func TestClassifyQuotaScope(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want QuotaScope
+	}{
+		{"gemini per-minute", geminiPerMinuteBody, QuotaScopeRateLimit},
+		{"gemini per-day", geminiPerDayBody, QuotaScopeDaily},
+		{"array wrapped", "[" + geminiPerDayBody + "]", QuotaScopeDaily},
+		{"plain text minute", "Too many requests per minute", QuotaScopeRateLimit},
+		{"no hint", `{"error":{"code":429,"status":"RESOURCE_EXHAUSTED","message":"Resource has been exhausted"}}`, QuotaScopeUnknown},
+		{"empty", "", QuotaScopeUnknown},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ClassifyQuotaScope(tc.body); got != tc.want {
+				t.Errorf("ClassifyQuotaScope() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuthRegistry_QuotaScopeCooldowns(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		reason     string
+		minRecover time.Duration
+		maxRecover time.Duration
+	}{
+		{"per-minute", geminiPerMinuteBody, "rate_limit", 0, time.Minute},
+		{"per-day", geminiPerDayBody, "quota_daily", quotaBackoffMax - time.Minute, quotaBackoffMax + time.Minute},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := NewAuthRegistry(nil, nil)
+			ctx := context.Background()
+			_, _ = registry.Register(ctx, &Auth{ID: "gemini-1", Provider: "gemini"})
+
+			registry.MarkResult(ctx, Result{
+				AuthID:   "gemini-1",
+				Provider: "gemini",
+				Model:    "gemini-2.5-pro",
+				Error:    &Error{HTTPStatus: 429, Message: tc.body},
+			})
+
+			state, ok := registry.GetEntry("gemini-1").ModelStates().Get("gemini-2.5-pro")
+			if !ok {
+				t.Fatal("Expected model state after 429")
+			}
+			if state.QuotaReason != tc.reason {
+				t.Errorf("Expected reason %q, got %q", tc.reason, state.QuotaReason)
+			}
+			wait := time.Until(time.Unix(0, state.QuotaRecover))
+			if wait < tc.minRecover || wait > tc.maxRecover {
+				t.Errorf("Cooldown %v outside [%v, %v]", wait, tc.minRecover, tc.maxRecover)
+			}
+		})
+	}
+}
+
+const openAIRateLimitBody = `{"error":{"message":"Rate limit reached for gpt-4o on requests per min (RPM): Limit 500, Used 500, Requested 1.","type":"requests","param":null,"code":"rate_limit_exceeded"}}`
+
+const openAIInsufficientQuotaBody = `{"error":{"message":"You exceeded your current quota, please check your plan and billing details.","type":"insufficient_quota","param":null,"code":"insufficient_quota"}}`
+
+func TestCategorizeError_BillingVersusRateLimit(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		want   ErrorCategory
+	}{
+		{"openai rate limit", 429, openAIRateLimitBody, CategoryQuotaError},
+		{"openai insufficient quota", 429, openAIInsufficientQuotaBody, CategoryBillingError},
+		{"gemini billing wording", 429, geminiPerMinuteBody, CategoryQuotaError},
+		{"anthropic credit balance", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"Your credit balance is too low to access the Anthropic API."}}`, CategoryBillingError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CategorizeError(tc.status, tc.body); got != tc.want {
+				t.Errorf("CategorizeError() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuthRegistry_BillingVersusRateLimit429(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		message    string
+		quota      bool
+		minRecover time.Duration
+		maxRecover time.Duration
+	}{
+		{"rate limit", openAIRateLimitBody, openAIRateLimitBody, true, 0, 2 * time.Minute},
+		{"insufficient quota", openAIInsufficientQuotaBody, BillingStatusMessage, false, billingSuspension - time.Minute, billingSuspension + time.Minute},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := NewAuthRegistry(nil, nil)
+			ctx := context.Background()
+			_, _ = registry.Register(ctx, &Auth{ID: "openai-1", Provider: "openai"})
+
+			registry.MarkResult(ctx, Result{
+				AuthID:   "openai-1",
+				Provider: "openai",
+				Model:    "gpt-4o",
+				Error:    &Error{HTTPStatus: 429, Message: tc.body},
+			})
+
+			entry := registry.GetEntry("openai-1")
+			state, ok := entry.ModelStates().Get("gpt-4o")
+			if !ok {
+				t.Fatal("Expected model state after 429")
+			}
+			if state.QuotaExceeded != tc.quota {
+				t.Errorf("Expected quota exceeded %v, got %v", tc.quota, state.QuotaExceeded)
+			}
+			if state.StatusMessage != tc.message {
+				t.Errorf("Expected status message %q, got %q", tc.message, state.StatusMessage)
+			}
+			wait := time.Until(time.Unix(0, state.NextRetryAfter))
+			if wait < tc.minRecover || wait > tc.maxRecover {
+				t.Errorf("Suspension %v outside [%v, %v]", wait, tc.minRecover, tc.maxRecover)
+			}
+			if auth := registry.Get("openai-1"); (auth.StatusMessage == BillingStatusMessage) == tc.quota {
+				t.Errorf("Unexpected account status message %q", auth.StatusMessage)
+			}
+		})
+	}
+}
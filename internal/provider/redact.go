@@ -0,0 +1,70 @@
+package provider
+
+import "strings"
+
+// RedactedValue replaces secret values in output meant for humans.
+const RedactedValue = "<redacted>"
+
+// IsSecretKey reports whether a metadata, attribute or config key holds a credential,
+// judged by its last word: access_token, api-keys and client_secret match,
+// token_type and max-tokens do not.
+func IsSecretKey(key string) bool {
+	k := strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	switch k {
+	case "headers", "service_account":
+		return true
+	}
+	last := k[strings.LastIndex(k, "_")+1:]
+	switch last {
+	case "token", "key", "keys", "secret", "password", "passphrase", "cookie", "dsn", "credentials":
+		return true
+	}
+	return false
+}
+
+// RedactSecrets returns a copy of v with the values under secret keys
+// replaced, keeping list lengths and map keys so the structure stays visible.
+// Maps must be map[string]any as produced by JSON or YAML decoding.
+func RedactSecrets(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, sub := range val {
+			if IsSecretKey(k) {
+				out[k] = redactAll(sub)
+			} else {
+				out[k] = RedactSecrets(sub)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, sub := range val {
+			out[i] = RedactSecrets(sub)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func redactAll(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, sub := range val {
+			out[k] = redactAll(sub)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, sub := range val {
+			out[i] = redactAll(sub)
+		}
+		return out
+	case nil, bool:
+		return v
+	default:
+		return RedactedValue
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import "testing"
+
+func TestIsSecretKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"access_token":  true,
+		"refresh-token": true,
+		"api-keys":      true,
+		"client_secret": true,
+		"cookie":        true,
+		"headers":       true,
+		"token_type":    false,
+		"max-tokens":    false,
+		"email":         false,
+		"expired":       false,
+	} {
+		if got := IsSecretKey(key); got != want {
+			t.Errorf("IsSecretKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	md := map[string]any{
+		"email":        "a@example.com",
+		"access_token": "at",
+		"token":        map[string]any{"refresh_token": "rt", "expiry": 123},
+		"api-keys":     []any{"sk-1", "sk-2"},
+		"disabled":     false,
+	}
+	out := RedactSecrets(md).(map[string]any)
+	if out["email"] != "a@example.com" || out["access_token"] != RedactedValue || out["disabled"] != false {
+		t.Fatalf("RedactSecrets() = %v", out)
+	}
+	if token := out["token"].(map[string]any); token["refresh_token"] != RedactedValue || token["expiry"] != RedactedValue {
+		t.Fatalf("nested secret map = %v", token)
+	}
+	if keys := out["api-keys"].([]any); len(keys) != 2 || keys[1] != RedactedValue {
+		t.Fatalf("api-keys = %v", keys)
+	}
+	if md["access_token"] != "at" {
+		t.Fatal("RedactSecrets modified its input")
+	}
+}
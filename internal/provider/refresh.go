@@ -112,6 +112,14 @@ func (m *Manager) checkRefreshes(ctx context.Context) {
 	}
 }
 
+// listAuths returns a snapshot of all auths, preferring the registry view.
+func (m *Manager) listAuths() []*Auth {
+	if m.registry != nil {
+		return m.registry.List()
+	}
+	return m.snapshotAuths()
+}
+
 // snapshotAuths creates a copy of all currently registered auths.
 func (m *Manager) snapshotAuths() []*Auth {
 	m.mu.RLock()
@@ -164,6 +172,13 @@ func (m *Manager) shouldRefresh(a *Auth, now time.Time) bool {
 	if lead == nil {
 		return false
 	}
+	// refresh-lead replaces the provider's own lead; providers without one
+	// are still not refreshed ahead of expiry.
+	if m.registry != nil {
+		if configured := m.registry.configuredRefreshLead(); configured > 0 {
+			lead = &configured
+		}
+	}
 	if *lead <= 0 {
 		if hasExpiry && !expiry.IsZero() {
 			return now.After(expiry)
@@ -31,6 +31,19 @@ type Response struct {
 	Metadata map[string]any
 }
 
+// TokenCountSourceKey is the Response.Metadata key recording how a token
+// count was produced: TokenCountExact or TokenCountEstimated.
+const TokenCountSourceKey = "token_count_source"
+
+// Token count sources.
+const (
+	TokenCountExact     = "exact"
+	TokenCountEstimated = "estimated"
+)
+
+// HeaderTokenCount reports the token count source to clients.
+const HeaderTokenCount = "X-LLM-Mux-Token-Count"
+
 // StreamChunk represents a single streaming payload unit emitted by provider executors.
 type StreamChunk struct {
 	Payload []byte
@@ -0,0 +1,304 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/sony/gobreaker"
+)
+
+var (
+	errQueueDisabled = errors.New("request queue disabled")
+	errQueueFull     = errors.New("request queue full")
+	errQueueTimeout  = errors.New("request queue wait exceeded")
+)
+
+// requestQueue parks requests while no credential can serve their model and
+// releases them one at a time, in arrival order, as credentials recover. A request that is
+// throttled again after release re-enters with its original ticket, so it
+// keeps its place ahead of later arrivals.
+type requestQueue struct {
+	maxSize atomic.Int32
+	maxWait atomic.Int64
+
+	// ready reports whether the head waiter of model could be served now.
+	// It is called without q.mu held.
+	ready func(model string, w *queueWaiter) bool
+	poll  time.Duration
+
+	mu          sync.Mutex
+	seq         uint64
+	waiters     map[string][]*queueWaiter
+	dispatching map[string]bool
+}
+
+type queueWaiter struct {
+	seq       uint64
+	deadline  time.Time
+	providers []string
+	stream    bool
+	release   chan struct{}
+}
+
+func newRequestQueue(ready func(model string, w *queueWaiter) bool) *requestQueue {
+	return &requestQueue{
+		ready:       ready,
+		poll:        cooldownPollInterval,
+		waiters:     make(map[string][]*queueWaiter),
+		dispatching: make(map[string]bool),
+	}
+}
+
+func (q *requestQueue) configure(maxSize int, maxWait time.Duration) {
+	if maxSize < 0 {
+		maxSize = 0
+	}
+	if maxWait < 0 {
+		maxWait = 0
+	}
+	q.maxSize.Store(int32(maxSize))
+	q.maxWait.Store(maxWait.Nanoseconds())
+}
+
+func (q *requestQueue) enabled() bool {
+	return q.maxSize.Load() > 0 && q.maxWait.Load() > 0
+}
+
+// pending reports whether requests for model are already waiting.
+func (q *requestQueue) pending(model string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiters[model]) > 0
+}
+
+// wait blocks until w is released, its deadline passes or ctx ends. A nil w
+// takes a new ticket; the returned waiter must be passed back on retries.
+func (q *requestQueue) wait(ctx context.Context, w *queueWaiter, model string, providers []string, stream bool) (*queueWaiter, error) {
+	if !q.enabled() {
+		return w, errQueueDisabled
+	}
+	maxSize, maxWait := int(q.maxSize.Load()), time.Duration(q.maxWait.Load())
+	now := time.Now()
+
+	q.mu.Lock()
+	queued := q.waiters[model]
+	if w == nil {
+		if len(queued) >= maxSize {
+			q.mu.Unlock()
+			return nil, errQueueFull
+		}
+		q.seq++
+		w = &queueWaiter{seq: q.seq, deadline: now.Add(maxWait), providers: providers, stream: stream}
+	} else if !now.Before(w.deadline) {
+		q.mu.Unlock()
+		return w, errQueueTimeout
+	}
+	w.release = make(chan struct{})
+	idx := sort.Search(len(queued), func(i int) bool { return queued[i].seq > w.seq })
+	q.waiters[model] = slices.Insert(queued, idx, w)
+	if !q.dispatching[model] {
+		q.dispatching[model] = true
+		go q.dispatch(model)
+	}
+	q.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(w.deadline))
+	defer timer.Stop()
+	select {
+	case <-w.release:
+		return w, nil
+	case <-ctx.Done():
+		q.remove(model, w)
+		return w, ctx.Err()
+	case <-timer.C:
+		if !q.remove(model, w) {
+			// Released while the timer fired.
+			return w, nil
+		}
+		return w, errQueueTimeout
+	}
+}
+
+// remove drops w from the queue, reporting false if it was already released.
+func (q *requestQueue) remove(model string, w *queueWaiter) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queued := q.waiters[model]
+	for i, candidate := range queued {
+		if candidate == w {
+			q.waiters[model] = slices.Delete(queued, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch polls readiness for model and releases the head waiter whenever a
+// credential can serve it, so one recovered slot lets one request through per
+// poll instead of the whole queue at once. It exits when the queue drains.
+func (q *requestQueue) dispatch(model string) {
+	ticker := time.NewTicker(q.poll)
+	defer ticker.Stop()
+	for range ticker.C {
+		q.mu.Lock()
+		queued := q.waiters[model]
+		if len(queued) == 0 {
+			delete(q.waiters, model)
+			delete(q.dispatching, model)
+			q.mu.Unlock()
+			return
+		}
+		head := queued[0]
+		q.mu.Unlock()
+
+		if !q.ready(model, head) {
+			continue
+		}
+
+		q.mu.Lock()
+		// The head may have left while readiness was checked; the next
+		// waiter then gets its own check on the following poll.
+		if queued := q.waiters[model]; len(queued) > 0 && queued[0] == head {
+			close(head.release)
+			q.waiters[model] = queued[1:]
+		}
+		q.mu.Unlock()
+	}
+}
+
+// queueableError reports whether err means the model is temporarily unservable
+// rather than that the request itself failed.
+func queueableError(err error) bool {
+	var cooldown *modelCooldownError
+	if errors.As(err, &cooldown) {
+		return true
+	}
+	var provErr *Error
+	if errors.As(err, &provErr) && provErr.Code == "circuit_open" {
+		return true
+	}
+	return statusCodeFromError(err) == http.StatusTooManyRequests
+}
+
+// runQueued runs fn and, while the request queue is enabled and no credential
+// can serve the model, parks the request until one recovers. Requests arriving
+// while others wait join the back of the line instead of racing them.
+func runQueued[T any](ctx context.Context, m *Manager, providers []string, model string, stream bool, fn func() (T, error)) (T, error) {
+	q := m.queue
+	if !q.enabled() {
+		return fn()
+	}
+	var w *queueWaiter
+	if q.pending(model) {
+		var errWait error
+		w, errWait = q.wait(ctx, nil, model, providers, stream)
+		switch {
+		case errWait == nil, errors.Is(errWait, errQueueDisabled):
+		case ctx.Err() != nil:
+			var zero T
+			return zero, ctx.Err()
+		default:
+			// A full queue or a spent max-wait ends here with 429, rather
+			// than jumping ahead of the waiters or waiting a second period.
+			_, retryIn, _ := m.queueAvailability(providers, model, stream)
+			var zero T
+			return zero, newModelCooldownError(model, "", retryIn)
+		}
+	}
+	for {
+		res, err := fn()
+		if err == nil || ctx.Err() != nil || !queueableError(err) {
+			return res, err
+		}
+		if ready, _, _ := m.queueAvailability(providers, model, stream); ready {
+			// Another credential is usable; the manager's own retry handles it.
+			return res, err
+		}
+		var errWait error
+		w, errWait = q.wait(ctx, w, model, providers, stream)
+		switch {
+		case errWait == nil:
+			continue
+		case errors.Is(errWait, errQueueTimeout):
+			if _, retryIn, known := m.queueAvailability(providers, model, stream); known {
+				return res, newModelCooldownError(model, "", retryIn)
+			}
+			return res, err
+		case ctx.Err() != nil:
+			return res, ctx.Err()
+		default:
+			return res, err
+		}
+	}
+}
+
+// queueAvailability reports whether any credential of providers can serve
+// model now and, if none can, how long until the soonest quota cooldown ends.
+func (m *Manager) queueAvailability(providers []string, model string, stream bool) (ready bool, retryIn time.Duration, known bool) {
+	if m.registry == nil {
+		return m.hasAvailableAuth(providers, model), 0, false
+	}
+	now := time.Now()
+	registryRef := registry.GetGlobalRegistry()
+	var earliest time.Time
+	for _, provider := range providers {
+		if m.circuitOpen(provider, stream) {
+			continue
+		}
+		providerModel := registryRef.GetModelIDForProvider(model, provider)
+		for _, entry := range m.registry.ListByProvider(provider) {
+			if entry.IsDisabled() {
+				continue
+			}
+			if providerModel != "" && !registryRef.ClientSupportsModel(entry.ID(), providerModel) {
+				continue
+			}
+			next := time.Time{}
+			if entry.IsInCooldown(now) {
+				next = entry.Quota.GetCooldownUntil()
+			} else {
+				blocked, reason, retryAt := entry.IsBlockedForModel(providerModel, now)
+				if !blocked {
+					return true, 0, false
+				}
+				if reason == blockReasonCooldown {
+					next = retryAt
+				}
+			}
+			if !next.IsZero() && (earliest.IsZero() || next.Before(earliest)) {
+				earliest = next
+			}
+		}
+	}
+	if earliest.IsZero() {
+		return false, 0, false
+	}
+	retryIn = earliest.Sub(now)
+	if retryIn < 0 {
+		retryIn = 0
+	}
+	return false, retryIn, true
+}
+
+func (m *Manager) circuitOpen(provider string, stream bool) bool {
+	if stream {
+		return m.getOrCreateStreamingBreaker(provider).State() == gobreaker.StateOpen
+	}
+	return m.getOrCreateBreaker(provider).State() == gobreaker.StateOpen
+}
+
+// SetQueueConfig enables the wait queue for requests whose model has every
+// credential quota-suspended. A zero size or wait disables it.
+func (m *Manager) SetQueueConfig(maxSize int, maxWait time.Duration) {
+	if m == nil {
+		return
+	}
+	m.queue.configure(maxSize, maxWait)
+}
@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestRequestQueue_ReleasesInArrivalOrder(t *testing.T) {
+	var open atomic.Bool
+	q := newRequestQueue(func(string, *queueWaiter) bool { return open.Load() })
+	q.poll = 5 * time.Millisecond
+	q.configure(10, time.Second)
+
+	// A requeued waiter keeps its original ticket and goes back to the front.
+	early := &queueWaiter{seq: 0, deadline: time.Now().Add(time.Second)}
+
+	var mu sync.Mutex
+	var order []uint64
+	var wg sync.WaitGroup
+	enqueue := func(w *queueWaiter) {
+		defer wg.Done()
+		got, err := q.wait(context.Background(), w, "m", nil, false)
+		if err != nil {
+			t.Errorf("wait failed: %v", err)
+			return
+		}
+		mu.Lock()
+		order = append(order, got.seq)
+		mu.Unlock()
+	}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go enqueue(nil)
+		for !queuedAtLeast(q, "m", i+1) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Add(1)
+	go enqueue(early)
+	for !queuedAtLeast(q, "m", 4) {
+		time.Sleep(time.Millisecond)
+	}
+
+	q.mu.Lock()
+	var seqs []uint64
+	for _, w := range q.waiters["m"] {
+		seqs = append(seqs, w.seq)
+	}
+	q.mu.Unlock()
+	if want := []uint64{0, 1, 2, 3}; !equalSeqs(seqs, want) {
+		t.Fatalf("Expected queue order %v, got %v", want, seqs)
+	}
+
+	open.Store(true)
+	wg.Wait()
+	if len(order) != 4 {
+		t.Fatalf("Expected all waiters released, got %v", order)
+	}
+	if q.pending("m") {
+		t.Error("Expected queue to be empty after release")
+	}
+}
+
+func TestRequestQueue_ReleasesOnePerFreeSlot(t *testing.T) {
+	// ready takes the slot it reports, as a request sent to the recovered
+	// credential would.
+	var slots atomic.Int32
+	q := newRequestQueue(func(string, *queueWaiter) bool { return slots.CompareAndSwap(1, 0) })
+	q.poll = 5 * time.Millisecond
+	q.configure(10, 5*time.Second)
+
+	released := make(chan uint64, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			w, err := q.wait(context.Background(), nil, "m", nil, false)
+			if err != nil {
+				t.Errorf("wait failed: %v", err)
+				return
+			}
+			released <- w.seq
+		}()
+		for !queuedAtLeast(q, "m", i+1) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	for want := uint64(1); want <= 3; want++ {
+		slots.Store(1)
+		select {
+		case seq := <-released:
+			if seq != want {
+				t.Fatalf("Expected waiter %d released next, got %d", want, seq)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Waiter %d not released", want)
+		}
+		select {
+		case seq := <-released:
+			t.Fatalf("Waiter %d released without a free slot", seq)
+		case <-time.After(4 * q.poll):
+		}
+	}
+}
+
+func TestRequestQueue_LimitsAndTimeout(t *testing.T) {
+	q := newRequestQueue(func(string, *queueWaiter) bool { return false })
+	q.poll = 5 * time.Millisecond
+
+	if _, err := q.wait(context.Background(), nil, "m", nil, false); !errors.Is(err, errQueueDisabled) {
+		t.Fatalf("Expected disabled queue, got %v", err)
+	}
+
+	q.configure(1, 50*time.Millisecond)
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.wait(context.Background(), nil, "m", nil, false)
+		done <- err
+	}()
+	for !queuedAtLeast(q, "m", 1) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := q.wait(context.Background(), nil, "m", nil, false); !errors.Is(err, errQueueFull) {
+		t.Errorf("Expected full queue, got %v", err)
+	}
+	if _, err := q.wait(context.Background(), nil, "other", nil, false); !errors.Is(err, errQueueTimeout) {
+		t.Errorf("Expected other models to queue independently and time out, got %v", err)
+	}
+	if err := <-done; !errors.Is(err, errQueueTimeout) {
+		t.Errorf("Expected timeout, got %v", err)
+	}
+}
+
+func queuedAtLeast(q *requestQueue, model string, n int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiters[model]) >= n
+}
+
+func equalSeqs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type quotaError struct{ retryAfter time.Duration }
+
+func (e quotaError) Error() string              { return "quota exhausted" }
+func (e quotaError) StatusCode() int            { return http.StatusTooManyRequests }
+func (e quotaError) RetryAfter() *time.Duration { return &e.retryAfter }
+
+// quotaExecutor fails with 429 until its quota window passes.
+type quotaExecutor struct {
+	recoverAt  time.Time
+	retryAfter time.Duration
+	calls      atomic.Int32
+}
+
+func (e *quotaExecutor) Identifier() string { return "queuetest" }
+func (e *quotaExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	e.calls.Add(1)
+	if time.Now().Before(e.recoverAt) {
+		return Response{}, quotaError{retryAfter: e.retryAfter}
+	}
+	return Response{Payload: []byte(`{"ok":true}`)}, nil
+}
+func (e *quotaExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+func (e *quotaExecutor) Refresh(_ context.Context, a *Auth) (*Auth, error) { return a, nil }
+func (e *quotaExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func newQueueTestManager(t *testing.T, exec *quotaExecutor, maxWait time.Duration) *Manager {
+	t.Helper()
+	authID := "queue-" + t.Name()
+	registry.GetGlobalRegistry().RegisterClient(authID, "queuetest", []*registry.ModelInfo{{ID: "queue-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(authID) })
+
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.RegisterExecutor(exec)
+	if _, err := m.Register(context.Background(), &Auth{ID: authID, Provider: "queuetest"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	m.queue.poll = 10 * time.Millisecond
+	m.SetQueueConfig(10, maxWait)
+	return m
+}
+
+func TestManager_QueuesUntilQuotaRecovers(t *testing.T) {
+	exec := &quotaExecutor{recoverAt: time.Now().Add(150 * time.Millisecond), retryAfter: 150 * time.Millisecond}
+	m := newQueueTestManager(t, exec, 2*time.Second)
+
+	resp, err := m.Execute(context.Background(), []string{"queuetest"}, Request{Model: "queue-model"}, Options{})
+	if err != nil {
+		t.Fatalf("Expected queued request to succeed after recovery, got %v", err)
+	}
+	if string(resp.Payload) != `{"ok":true}` {
+		t.Errorf("Unexpected payload %s", resp.Payload)
+	}
+	if calls := exec.calls.Load(); calls != 2 {
+		t.Errorf("Expected one throttled call and one retry, got %d", calls)
+	}
+}
+
+func TestManager_QueueTimeoutReturnsRetryAfter(t *testing.T) {
+	exec := &quotaExecutor{recoverAt: time.Now().Add(time.Hour), retryAfter: 10 * time.Second}
+	m := newQueueTestManager(t, exec, 100*time.Millisecond)
+
+	start := time.Now()
+	_, err := m.Execute(context.Background(), []string{"queuetest"}, Request{Model: "queue-model"}, Options{})
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected request to wait in the queue, returned after %v", elapsed)
+	}
+	var cooldown *modelCooldownError
+	if !errors.As(err, &cooldown) {
+		t.Fatalf("Expected cooldown error after queue timeout, got %v", err)
+	}
+	retryAfter, _ := strconv.Atoi(cooldown.Headers().Get("Retry-After"))
+	if retryAfter < 9 || retryAfter > 10 {
+		t.Errorf("Expected Retry-After near the 10s recovery, got %d", retryAfter)
+	}
+}
+
+func TestRunQueued_RejectsBehindWaiters(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.queue.ready = func(string, *queueWaiter) bool { return false }
+	m.SetQueueConfig(1, 100*time.Millisecond)
+	go m.queue.wait(context.Background(), nil, "m", nil, false)
+	for !queuedAtLeast(m.queue, "m", 1) {
+		time.Sleep(time.Millisecond)
+	}
+
+	var calls atomic.Int32
+	fn := func() (struct{}, error) {
+		calls.Add(1)
+		return struct{}{}, nil
+	}
+	var cooldown *modelCooldownError
+
+	// A full queue answers 429 instead of letting the request jump the line.
+	if _, err := runQueued(context.Background(), m, nil, "m", false, fn); !errors.As(err, &cooldown) {
+		t.Errorf("Expected cooldown error from a full queue, got %v", err)
+	}
+
+	// A request that spent max-wait behind others is answered 429 too.
+	m.SetQueueConfig(2, 100*time.Millisecond)
+	start := time.Now()
+	if _, err := runQueued(context.Background(), m, nil, "m", false, fn); !errors.As(err, &cooldown) {
+		t.Errorf("Expected cooldown error after queue timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 180*time.Millisecond {
+		t.Errorf("Expected one max-wait period in the queue, waited %v", elapsed)
+	}
+	if got := calls.Load(); got != 0 {
+		t.Errorf("Expected rejected requests never to run, got %d calls", got)
+	}
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RequestSigner is an optional interface for executors whose upstream requires
+// a signature over the request body (e.g. HMAC of the payload). The returned
+// headers are set on the outbound request after PrepareRequest runs.
+//
+// When the executor implements RequestSigner, InjectCredentials buffers the
+// whole body in memory so it can be signed and still sent. Large uploads are
+// therefore held in full for the lifetime of the request; executors that do
+// not sign bodies keep streaming them untouched.
+type RequestSigner interface {
+	SignRequest(payload []byte, auth *Auth) (http.Header, error)
+}
+
+// prepareAndSign buffers req's body, runs exec's PrepareRequest if any, then
+// applies the headers returned by signer, replacing existing values.
+func prepareAndSign(req *http.Request, auth *Auth, exec ProviderExecutor, signer RequestSigner) error {
+	payload, err := bufferRequestBody(req)
+	if err != nil {
+		return err
+	}
+	if p, ok := exec.(RequestPreparer); ok && p != nil {
+		if err := p.PrepareRequest(req, auth); err != nil {
+			return err
+		}
+		if payload != nil {
+			// PrepareRequest may have drained the body while inspecting it.
+			req.Body = io.NopCloser(bytes.NewReader(payload))
+		}
+	}
+	headers, err := signer.SignRequest(payload, auth)
+	if err != nil {
+		return fmt.Errorf("sign request: %w", err)
+	}
+	for key, values := range headers {
+		req.Header.Del(key)
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	return nil
+}
+
+// bufferRequestBody reads req's body into memory and makes it replayable:
+// req.Body is reset to the buffered bytes and req.GetBody returns fresh
+// readers, so PrepareRequest and retries can read it again.
+func bufferRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body := req.Body
+	if req.GetBody != nil {
+		if fresh, err := req.GetBody(); err == nil {
+			body = fresh
+		}
+	}
+	payload, err := io.ReadAll(body)
+	body.Close()
+	if body != req.Body {
+		req.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("buffer request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+	req.ContentLength = int64(len(payload))
+	return payload, nil
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// signingExecutor reads the body in PrepareRequest and signs it with an
+// HMAC keyed by the auth's api_key attribute.
+type signingExecutor struct {
+	preparedBody string
+}
+
+func (e *signingExecutor) Identifier() string { return "signed" }
+func (e *signingExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+func (e *signingExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+func (e *signingExecutor) Refresh(_ context.Context, a *Auth) (*Auth, error) { return a, nil }
+func (e *signingExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *signingExecutor) PrepareRequest(req *http.Request, _ *Auth) error {
+	body, err := io.ReadAll(req.Body)
+	e.preparedBody = string(body)
+	return err
+}
+
+func (e *signingExecutor) SignRequest(payload []byte, auth *Auth) (http.Header, error) {
+	mac := hmac.New(sha256.New, []byte(auth.Attributes["api_key"]))
+	mac.Write(payload)
+	return http.Header{"X-Signature": {hex.EncodeToString(mac.Sum(nil))}}, nil
+}
+
+func TestInjectCredentials_SignsBufferedBody(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	exec := &signingExecutor{}
+	manager.RegisterExecutor(exec)
+	if _, err := manager.Register(context.Background(), &Auth{ID: "a1", Provider: "signed", Attributes: map[string]string{"api_key": "secret"}}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	const payload = `{"model":"m","input":"hi"}`
+	req, _ := http.NewRequest(http.MethodPost, "https://gateway.internal/v1", io.NopCloser(strings.NewReader(payload)))
+	req.Header.Set("X-Signature", "stale")
+	if err := manager.InjectCredentials(req, "a1"); err != nil {
+		t.Fatalf("InjectCredentials failed: %v", err)
+	}
+
+	if exec.preparedBody != payload {
+		t.Errorf("Expected PrepareRequest to read the body, got %q", exec.preparedBody)
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload))
+	if got, want := req.Header.Values("X-Signature"), hex.EncodeToString(mac.Sum(nil)); len(got) != 1 || got[0] != want {
+		t.Errorf("Expected signature %s, got %v", want, got)
+	}
+
+	sent, _ := io.ReadAll(req.Body)
+	if string(sent) != payload {
+		t.Errorf("Expected body to remain sendable, got %q", sent)
+	}
+	if req.GetBody == nil || req.ContentLength != int64(len(payload)) {
+		t.Fatalf("Expected replayable body with length %d, got GetBody=%v length=%d", len(payload), req.GetBody != nil, req.ContentLength)
+	}
+	replay, _ := req.GetBody()
+	if again, _ := io.ReadAll(replay); string(again) != payload {
+		t.Errorf("Expected GetBody to replay the payload, got %q", again)
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestTimeouts caps the wall-clock duration of an upstream call, streaming
+// included. Unlike the stream idle timeout it also ends upstreams that keep
+// sending data slowly. Zero durations mean no cap.
+type RequestTimeouts struct {
+	Default   time.Duration
+	Providers map[string]time.Duration // lowercase provider name -> override
+}
+
+// errCodeRequestTimeout is the code of errors reported for calls that ran
+// past their request timeout.
+const errCodeRequestTimeout = "request_timeout"
+
+// SetRequestTimeouts replaces the request timeouts applied to upstream calls.
+func (m *Manager) SetRequestTimeouts(t RequestTimeouts) {
+	if m == nil {
+		return
+	}
+	if t.Default <= 0 && len(t.Providers) == 0 {
+		m.timeouts.Store(nil)
+		return
+	}
+	m.timeouts.Store(&t)
+}
+
+// requestTimeout returns the timeout for calls to provider; non-positive
+// values mean none.
+func (m *Manager) requestTimeout(provider string) time.Duration {
+	t := m.timeouts.Load()
+	if t == nil {
+		return 0
+	}
+	if d, ok := t.Providers[strings.ToLower(provider)]; ok {
+		return d
+	}
+	return t.Default
+}
+
+// withRequestTimeout bounds ctx by the request timeout of provider. The
+// returned context's cause is a request_timeout *Error once it expires.
+func (m *Manager) withRequestTimeout(ctx context.Context, provider string) (context.Context, context.CancelFunc) {
+	d := m.requestTimeout(provider)
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, d, &Error{
+		Code:       errCodeRequestTimeout,
+		Message:    fmt.Sprintf("upstream request exceeded the %s request timeout", d),
+		HTTPStatus: http.StatusGatewayTimeout,
+	})
+}
+
+// RequestTimedOut reports whether ctx ended because its upstream call ran
+// past the request timeout, as opposed to being canceled by the client.
+func RequestTimedOut(ctx context.Context) bool {
+	return requestTimeoutErr(ctx) != nil
+}
+
+// requestTimeoutErr returns the request_timeout error ctx ended with, or nil.
+func requestTimeoutErr(ctx context.Context) *Error {
+	if ctx == nil || ctx.Err() == nil {
+		return nil
+	}
+	var err *Error
+	if errors.As(context.Cause(ctx), &err) && err.Code == errCodeRequestTimeout {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// trickleExecutor streams a chunk every interval until its context ends. A
+// zero interval stalls without sending anything.
+type trickleExecutor struct {
+	chatOnlyExecutor
+	interval time.Duration
+}
+
+func (e *trickleExecutor) ExecuteStream(ctx context.Context, _ *Auth, _ Request, _ Options) (<-chan StreamChunk, error) {
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		var tick <-chan time.Time
+		if e.interval > 0 {
+			ticker := time.NewTicker(e.interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+				select {
+				case out <- StreamChunk{Payload: []byte("data: {}\n\n")}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func TestManager_RequestTimeoutEndsStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		wantChunk bool
+	}{
+		{"stalled", 0, false},
+		{"slow but active", 5 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(nil, nil, nil)
+			t.Cleanup(m.Stop)
+			ctx := context.Background()
+			m.RegisterExecutor(&trickleExecutor{chatOnlyExecutor: chatOnlyExecutor{id: "trickle"}, interval: tt.interval})
+			registry.GetGlobalRegistry().RegisterClient("trickle-1", "trickle", []*registry.ModelInfo{{ID: "trickle-model"}})
+			t.Cleanup(func() {
+				registry.GetGlobalRegistry().UnregisterClient("trickle-1")
+				registry.GetGlobalRegistry().SetProviderDownUntil("trickle", time.Time{})
+			})
+			if _, err := m.Register(ctx, &Auth{ID: "trickle-1", Provider: "trickle", Status: StatusActive}); err != nil {
+				t.Fatalf("Register failed: %v", err)
+			}
+			m.SetRequestTimeouts(RequestTimeouts{Default: time.Hour, Providers: map[string]time.Duration{"trickle": 60 * time.Millisecond}})
+
+			start := time.Now()
+			chunks, err := m.ExecuteStream(ctx, []string{"trickle"}, Request{Model: "trickle-model"}, Options{})
+			if err != nil {
+				t.Fatalf("ExecuteStream failed: %v", err)
+			}
+			var payloads int
+			var last error
+			for chunk := range chunks {
+				if chunk.Err != nil {
+					last = chunk.Err
+					continue
+				}
+				payloads++
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Fatalf("Expected the stream to end at its timeout, took %s", elapsed)
+			}
+			if (payloads > 0) != tt.wantChunk {
+				t.Errorf("Expected chunks before the timeout: %t, got %d", tt.wantChunk, payloads)
+			}
+			var provErr *Error
+			if !errors.As(last, &provErr) || provErr.Code != errCodeRequestTimeout || provErr.HTTPStatus != http.StatusGatewayTimeout {
+				t.Fatalf("Expected a request_timeout error as the final chunk, got %v", last)
+			}
+
+			state, ok := m.GetAuthEntry("trickle-1").ModelStates().Get("trickle-model")
+			if !ok || !state.Unavailable || state.LastError == nil || state.LastError.Code != errCodeRequestTimeout {
+				t.Errorf("Expected the timed out call to be recorded as failed, got %+v", state)
+			}
+		})
+	}
+}
+
+func TestManager_RequestTimeoutOverride(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	if d := m.requestTimeout("claude"); d != 0 {
+		t.Errorf("Expected no timeout by default, got %s", d)
+	}
+	m.SetRequestTimeouts(RequestTimeouts{Default: time.Minute, Providers: map[string]time.Duration{"claude": 0, "gemini-cli": time.Hour}})
+	for provider, want := range map[string]time.Duration{"claude": 0, "Gemini-CLI": time.Hour, "openai": time.Minute} {
+		if d := m.requestTimeout(provider); d != want {
+			t.Errorf("requestTimeout(%s) = %s, want %s", provider, d, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// chatOnlyExecutor implements none of the optional executor capabilities.
+type chatOnlyExecutor struct{ id string }
+
+func (e *chatOnlyExecutor) Identifier() string { return e.id }
+func (e *chatOnlyExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+func (e *chatOnlyExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+func (e *chatOnlyExecutor) Refresh(_ context.Context, a *Auth) (*Auth, error) { return a, nil }
+func (e *chatOnlyExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+type rerankExecutor struct{ chatOnlyExecutor }
+
+func (e *rerankExecutor) Rerank(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{Payload: []byte(`{"results":[]}`)}, nil
+}
+
+func TestManager_ExecuteRerankWithoutCapableProvider(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.RegisterExecutor(&chatOnlyExecutor{id: "chatonly"})
+
+	_, err := m.ExecuteRerank(context.Background(), []string{"chatonly"}, Request{Model: "rerank-model"}, Options{})
+	if statusCodeFromError(err) != http.StatusNotImplemented {
+		t.Fatalf("Expected 501 for a provider without rerank, got %v", err)
+	}
+}
+
+func TestManager_ExecuteRerankRoutesToCapableProvider(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.RegisterExecutor(&chatOnlyExecutor{id: "chatonly"})
+	m.RegisterExecutor(&rerankExecutor{chatOnlyExecutor{id: "reranker"}})
+	for _, p := range []string{"chatonly", "reranker"} {
+		id := p + "-auth"
+		registry.GetGlobalRegistry().RegisterClient(id, p, []*registry.ModelInfo{{ID: "rerank-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: p}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	resp, err := m.ExecuteRerank(context.Background(), []string{"chatonly", "reranker"}, Request{Model: "rerank-model"}, Options{})
+	if err != nil {
+		t.Fatalf("ExecuteRerank failed: %v", err)
+	}
+	if string(resp.Payload) != `{"results":[]}` {
+		t.Errorf("Expected the rerank executor's payload, got %s", resp.Payload)
+	}
+}
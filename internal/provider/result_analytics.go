@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+// Account selection strategies reported in Result.Strategy.
+const (
+	StrategyDefault      = "default"
+	StrategyRandom       = "random" // alias of StrategyDefault
+	StrategyRoundRobin   = "round-robin"
+	StrategyLRU          = "lru"
+	StrategyLatencyAware = "latency-aware"
+	StrategyCustom       = "custom"
+)
+
+// requestAnalytics follows one client request across the upstream calls made
+// for it, so each Result can say which attempt it was.
+type requestAnalytics struct {
+	model string
+
+	mu         sync.Mutex
+	attempts   int
+	firstRoute string // provider and upstream model of the first call
+}
+
+type requestAnalyticsContextKey struct{}
+
+// WithRequestAnalytics starts tracking the upstream calls made with ctx as one
+// request for model, the canonical model the client asked for. Calls for
+// fallback models made with the returned context count as further attempts of
+// the same request. A context already tracked is returned unchanged.
+func WithRequestAnalytics(ctx context.Context, model string) context.Context {
+	if ctx == nil || requestAnalyticsFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestAnalyticsContextKey{}, &requestAnalytics{model: model})
+}
+
+func requestAnalyticsFromContext(ctx context.Context) *requestAnalytics {
+	if ctx == nil {
+		return nil
+	}
+	a, _ := ctx.Value(requestAnalyticsContextKey{}).(*requestAnalytics)
+	return a
+}
+
+// next counts an upstream call and reports its attempt number and whether it
+// went to another provider or upstream model than the first call.
+func (a *requestAnalytics) next(provider, model string) (attempt int, failover bool) {
+	route := provider + "\x00" + model
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.attempts++
+	if a.attempts == 1 {
+		a.firstRoute = route
+	}
+	return a.attempts, route != a.firstRoute
+}
+
+// SetResultSink installs fn to receive every recorded Result, including
+// failed and canceled calls and response cache hits, for analytics export.
+// fn runs on the request path and must not block; hand results to a buffered
+// channel when the consumer is slow. A nil fn removes the sink.
+func (m *Manager) SetResultSink(fn func(Result)) {
+	if m == nil {
+		return
+	}
+	if fn == nil {
+		m.resultSink.Store(nil)
+		return
+	}
+	m.resultSink.Store(&fn)
+}
+
+// RecordCacheHit reports a response served from the response cache for model
+// to the result sink. No upstream call is made, so no auth is affected.
+func (m *Manager) RecordCacheHit(ctx context.Context, model string) {
+	if m == nil {
+		return
+	}
+	sink := m.resultSink.Load()
+	if sink == nil {
+		return
+	}
+	(*sink)(Result{
+		Model:          model,
+		RequestedModel: model,
+		Success:        true,
+		CacheHit:       true,
+		RequestID:      log.RequestIDFromContext(ctx),
+		APIKeyID:       usage.APIKeyIDFromContext(ctx),
+	})
+}
+
+// annotateResult fills in the request-level fields of result from ctx.
+func (m *Manager) annotateResult(ctx context.Context, result *Result) {
+	if result.Strategy == "" {
+		result.Strategy = m.SelectionStrategy()
+	}
+	a := requestAnalyticsFromContext(ctx)
+	if a == nil {
+		return
+	}
+	if result.RequestedModel == "" {
+		result.RequestedModel = a.model
+	}
+	if result.Attempt == 0 {
+		result.Attempt, result.Failover = a.next(result.Provider, result.Model)
+	}
+}
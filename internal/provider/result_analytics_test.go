@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// flakyExecutor fails its first call and succeeds afterwards.
+type flakyExecutor struct {
+	chatOnlyExecutor
+	mu    sync.Mutex
+	calls int
+}
+
+func (e *flakyExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls++
+	if e.calls == 1 {
+		return Response{}, errors.New("upstream failed")
+	}
+	return Response{Payload: []byte(`{}`)}, nil
+}
+
+func TestManager_ResultSink(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	ctx := context.Background()
+	m.RegisterExecutor(&flakyExecutor{chatOnlyExecutor: chatOnlyExecutor{id: "flaky"}})
+	models := []*registry.ModelInfo{{ID: "flaky-model"}, {ID: "flaky-fallback"}}
+	for _, id := range []string{"flaky-1", "flaky-2"} {
+		registry.GetGlobalRegistry().RegisterClient(id, "flaky", models)
+		if _, err := m.Register(ctx, &Auth{ID: id, Provider: "flaky", Status: StatusActive}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		registry.GetGlobalRegistry().UnregisterClient("flaky-1")
+		registry.GetGlobalRegistry().UnregisterClient("flaky-2")
+		registry.GetGlobalRegistry().SetProviderDownUntil("flaky", time.Time{})
+	})
+
+	var mu sync.Mutex
+	var results []Result
+	m.SetResultSink(func(r Result) {
+		mu.Lock()
+		results = append(results, r)
+		mu.Unlock()
+	})
+
+	// A fallback model executed with the same context is a later attempt of
+	// the same request.
+	ctx = WithRequestAnalytics(ctx, "flaky-model")
+	if _, err := m.Execute(ctx, []string{"flaky"}, Request{Model: "flaky-model"}, Options{}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := m.Execute(ctx, []string{"flaky"}, Request{Model: "flaky-fallback"}, Options{}); err != nil {
+		t.Fatalf("Execute fallback failed: %v", err)
+	}
+	m.RecordCacheHit(context.Background(), "flaky-model")
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []struct {
+		success, failover, cacheHit bool
+		attempt                     int
+	}{
+		{false, false, false, 1},
+		{true, false, false, 2},
+		{true, true, false, 3},
+		{true, false, true, 0},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(want), len(results), results)
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Success != w.success || r.Failover != w.failover || r.CacheHit != w.cacheHit || r.Attempt != w.attempt {
+			t.Errorf("result %d = %+v, want %+v", i, r, w)
+		}
+		if r.RequestedModel != "flaky-model" {
+			t.Errorf("result %d RequestedModel = %q, want flaky-model", i, r.RequestedModel)
+		}
+		if !w.cacheHit && r.Strategy != StrategyDefault {
+			t.Errorf("result %d Strategy = %q, want %q", i, r.Strategy, StrategyDefault)
+		}
+	}
+
+	m.SetResultSink(nil)
+	m.RecordCacheHit(ctx, "flaky-model")
+	if len(results) != len(want) {
+		t.Errorf("Expected no results after removing the sink, got %d", len(results))
+	}
+}
@@ -156,6 +156,25 @@ func (m *Manager) hasAvailableAuth(providers []string, model string) bool {
 	return false
 }
 
+// CategorizeErr classifies err the way retry and fallback decisions do,
+// preferring a category reported by the error itself.
+func CategorizeErr(err error) ErrorCategory {
+	return categoryFromError(err)
+}
+
+// RetryAfterFromError returns the retry delay reported by err or any error it
+// wraps, or nil when none is known.
+func RetryAfterFromError(err error) *time.Duration {
+	return retryAfterFromError(err)
+}
+
+// CooldownWait returns how long until the first auth for model on one of
+// providers leaves its cooldown, as tracked by NextRetryAfter. ok is false
+// when no matching auth is cooling down.
+func (m *Manager) CooldownWait(providers []string, model string) (wait time.Duration, ok bool) {
+	return m.closestCooldownWait(providers, model)
+}
+
 // categoryFromError extracts ErrorCategory from error.
 // Uses errors.As to properly unwrap wrapped errors.
 func categoryFromError(err error) ErrorCategory {
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// MaxRetryJitterPercent bounds the configurable retry jitter.
+const MaxRetryJitterPercent = 50
+
+// retryJitter spreads cooldowns and Retry-After hints so that clients and
+// accounts released by the same quota recovery do not all retry at once.
+type retryJitter struct {
+	mu      sync.Mutex
+	percent int
+	rng     *rand.Rand
+}
+
+var defaultRetryJitter = &retryJitter{rng: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))}
+
+// SetRetryJitter sets the jitter applied to cooldowns and Retry-After hints,
+// as a percentage of the delay. 0 disables it; values are clamped to
+// [0, MaxRetryJitterPercent].
+func SetRetryJitter(percent int) {
+	if percent < 0 {
+		percent = 0
+	} else if percent > MaxRetryJitterPercent {
+		percent = MaxRetryJitterPercent
+	}
+	defaultRetryJitter.mu.Lock()
+	defaultRetryJitter.percent = percent
+	defaultRetryJitter.mu.Unlock()
+}
+
+// SeedRetryJitter makes the jitter sequence deterministic. Intended for tests.
+func SeedRetryJitter(seed uint64) {
+	defaultRetryJitter.mu.Lock()
+	defaultRetryJitter.rng = rand.New(rand.NewPCG(seed, seed))
+	defaultRetryJitter.mu.Unlock()
+}
+
+// JitterRetryAfter spreads d by up to the configured percentage either way.
+// A delay that came from the provider (hinted) is only ever lengthened, since
+// retrying before the provider's own deadline fails again.
+func JitterRetryAfter(d time.Duration, hinted bool) time.Duration {
+	return defaultRetryJitter.apply(d, hinted)
+}
+
+func (j *retryJitter) apply(d time.Duration, hinted bool) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	j.mu.Lock()
+	percent := j.percent
+	var f float64
+	if percent > 0 {
+		f = j.rng.Float64()
+	}
+	j.mu.Unlock()
+	if percent == 0 {
+		return d
+	}
+	spread := float64(d) * float64(percent) / 100
+	if hinted {
+		return d + time.Duration(f*spread)
+	}
+	return d + time.Duration((2*f-1)*spread)
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitterRetryAfterBounds(t *testing.T) {
+	SetRetryJitter(20)
+	SeedRetryJitter(1)
+	t.Cleanup(func() { SetRetryJitter(0) })
+
+	const base = 10 * time.Second
+	var below, above bool
+	for i := 0; i < 1000; i++ {
+		got := JitterRetryAfter(base, false)
+		if got < 8*time.Second || got > 12*time.Second {
+			t.Fatalf("JitterRetryAfter(%v) = %v, outside ±20%%", base, got)
+		}
+		below = below || got < base
+		above = above || got > base
+	}
+	if !below || !above {
+		t.Errorf("jitter not spread both ways (below=%v above=%v)", below, above)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if got := JitterRetryAfter(base, true); got < base || got > 12*time.Second {
+			t.Fatalf("hinted JitterRetryAfter(%v) = %v, want within [10s, 12s]", base, got)
+		}
+	}
+	if got := JitterRetryAfter(0, false); got != 0 {
+		t.Errorf("JitterRetryAfter(0) = %v", got)
+	}
+}
+
+func TestJitterRetryAfterSeededAndDisabled(t *testing.T) {
+	t.Cleanup(func() { SetRetryJitter(0) })
+	SetRetryJitter(30)
+	SeedRetryJitter(42)
+	first := []time.Duration{JitterRetryAfter(time.Minute, false), JitterRetryAfter(time.Minute, false)}
+	SeedRetryJitter(42)
+	second := []time.Duration{JitterRetryAfter(time.Minute, false), JitterRetryAfter(time.Minute, false)}
+	if first[0] != second[0] || first[1] != second[1] {
+		t.Errorf("same seed gave %v then %v", first, second)
+	}
+
+	SetRetryJitter(0)
+	if got := JitterRetryAfter(time.Minute, false); got != time.Minute {
+		t.Errorf("disabled jitter changed the delay to %v", got)
+	}
+
+	SetRetryJitter(500)
+	for i := 0; i < 1000; i++ {
+		if got := JitterRetryAfter(time.Minute, false); got < 30*time.Second || got > 90*time.Second {
+			t.Fatalf("jitter above the %d%% cap: %v", MaxRetryJitterPercent, got)
+		}
+	}
+}
+
+func TestQuotaCooldownJitter(t *testing.T) {
+	SetRetryJitter(20)
+	SeedRetryJitter(7)
+	t.Cleanup(func() { SetRetryJitter(0) })
+
+	seen := make(map[time.Duration]struct{})
+	for i := 0; i < 50; i++ {
+		got, _ := quotaCooldown(nil, QuotaScopeRateLimit, 0)
+		lo := rateLimitCooldown - rateLimitCooldown/5
+		hi := rateLimitCooldown + rateLimitCooldown/5
+		if got < lo || got > hi {
+			t.Fatalf("quotaCooldown = %v, want within [%v, %v]", got, lo, hi)
+		}
+		seen[got] = struct{}{}
+	}
+	if len(seen) < 2 {
+		t.Error("cooldowns were not spread")
+	}
+	hint := 5 * time.Second
+	if got, _ := quotaCooldown(&hint, QuotaScopeUnknown, 0); got < hint {
+		t.Errorf("cooldown %v shorter than the provider hint %v", got, hint)
+	}
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RouteTraceMetadataKey is the request metadata key carrying the *RouteTrace
+// to translation code that has no access to the request context.
+// Translators must strip it before forwarding metadata upstream.
+const RouteTraceMetadataKey = "__route_trace"
+
+// Response headers emitted when route tracing is enabled.
+const (
+	HeaderRouteModel      = "X-LLM-Mux-Model"
+	HeaderRouteProvider   = "X-LLM-Mux-Provider"
+	HeaderRouteAuth       = "X-LLM-Mux-Auth"
+	HeaderRouteTransforms = "X-LLM-Mux-Transforms"
+)
+
+// RouteTrace records how a single request was routed and which request
+// transforms were applied. It only holds routing identifiers and never
+// credentials, so it is safe to expose to the calling client.
+type RouteTrace struct {
+	mu         sync.Mutex
+	model      string
+	provider   string
+	authID     string
+	transforms []string
+}
+
+type routeTraceContextKey struct{}
+
+// WithRouteTrace attaches trace to ctx so the manager can record the route.
+func WithRouteTrace(ctx context.Context, trace *RouteTrace) context.Context {
+	if trace == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, routeTraceContextKey{}, trace)
+}
+
+// RouteTraceFromContext returns the trace attached to ctx, or nil.
+func RouteTraceFromContext(ctx context.Context) *RouteTrace {
+	if ctx == nil {
+		return nil
+	}
+	trace, _ := ctx.Value(routeTraceContextKey{}).(*RouteTrace)
+	return trace
+}
+
+// RouteTraceFromMetadata returns the trace carried in request metadata, or nil.
+func RouteTraceFromMetadata(meta map[string]any) *RouteTrace {
+	if meta == nil {
+		return nil
+	}
+	trace, _ := meta[RouteTraceMetadataKey].(*RouteTrace)
+	return trace
+}
+
+// SetRoute records the provider, upstream model and auth that served the request.
+// Later calls overwrite earlier ones so fallbacks report the final route.
+func (t *RouteTrace) SetRoute(provider, model, authID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.provider = provider
+	t.model = model
+	t.authID = authID
+	t.mu.Unlock()
+}
+
+// SetTransforms replaces the recorded request transforms.
+func (t *RouteTrace) SetTransforms(transforms []string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.transforms = append(t.transforms[:0], transforms...)
+	t.mu.Unlock()
+}
+
+// Headers renders the trace as response headers. Empty fields are omitted.
+func (t *RouteTrace) Headers() http.Header {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := make(http.Header, 4)
+	setTraceHeader(h, HeaderRouteModel, t.model)
+	setTraceHeader(h, HeaderRouteProvider, t.provider)
+	setTraceHeader(h, HeaderRouteAuth, t.authID)
+	setTraceHeader(h, HeaderRouteTransforms, strings.Join(t.transforms, "; "))
+	return h
+}
+
+func setTraceHeader(h http.Header, key, value string) {
+	value = strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, value)
+	if value != "" {
+		h.Set(key, value)
+	}
+}
@@ -112,7 +112,7 @@ func (e *modelCooldownError) StatusCode() int {
 func (e *modelCooldownError) Headers() http.Header {
 	headers := make(http.Header)
 	headers.Set("Content-Type", "application/json")
-	resetSeconds := int(math.Ceil(e.resetIn.Seconds()))
+	resetSeconds := int(math.Ceil(JitterRetryAfter(e.resetIn, false).Seconds()))
 	if resetSeconds < 0 {
 		resetSeconds = 0
 	}
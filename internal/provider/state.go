@@ -174,21 +174,26 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 		auth.StatusMessage = "unauthorized"
 		auth.NextRetryAfter = now.Add(30 * time.Minute)
 	case CategoryQuotaError:
+		var msg string
+		if resultErr != nil {
+			msg = resultErr.Message
+		}
+		scope := ClassifyQuotaScope(msg)
 		auth.StatusMessage = "quota exhausted"
 		auth.Quota.Exceeded = true
-		auth.Quota.Reason = "quota"
+		auth.Quota.Reason = scope.String()
 		var next time.Time
-		if retryAfter != nil {
-			next = now.Add(*retryAfter)
-		} else {
-			cooldown, nextLevel := nextQuotaCooldown(auth.Quota.BackoffLevel)
-			if cooldown > 0 {
-				next = now.Add(cooldown)
-			}
-			auth.Quota.BackoffLevel = nextLevel
+		cooldown, nextLevel := quotaCooldown(retryAfter, scope, auth.Quota.BackoffLevel)
+		if cooldown > 0 {
+			next = now.Add(cooldown)
 		}
+		auth.Quota.BackoffLevel = nextLevel
 		auth.Quota.NextRecoverAt = next
 		auth.NextRetryAfter = next
+	case CategoryBillingError:
+		// Out of credits - suspend until the operator tops up
+		auth.StatusMessage = BillingStatusMessage
+		auth.NextRetryAfter = now.Add(billingSuspension)
 	case CategoryNotFound:
 		auth.StatusMessage = "not_found"
 		auth.NextRetryAfter = now.Add(12 * time.Hour)
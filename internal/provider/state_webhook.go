@@ -0,0 +1,270 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+const stateWebhookTimeout = 10 * time.Second
+
+// State webhook event names.
+const (
+	// StateEventAccountUnhealthy fires when an account fails with an auth,
+	// revoked token, payment or billing error.
+	StateEventAccountUnhealthy = "account_unhealthy"
+	// StateEventAccountRecovered fires when an unhealthy account succeeds again.
+	StateEventAccountRecovered = "account_recovered"
+	// StateEventProviderUnavailable fires when every account of a provider is
+	// disabled or suspended.
+	StateEventProviderUnavailable = "provider_unavailable"
+	// StateEventProviderRecovered fires when an unavailable provider serves a
+	// request again.
+	StateEventProviderRecovered = "provider_recovered"
+)
+
+// StateWebhookConfig controls the account state webhook. An empty URL
+// disables it.
+type StateWebhookConfig struct {
+	// URL receives a JSON POST per state transition.
+	URL string
+	// Debounce is the minimum time between two events of the same kind for the
+	// same account or provider. Transitions inside the window are dropped.
+	Debounce time.Duration
+}
+
+// StateEvent is the JSON payload posted to the state webhook.
+type StateEvent struct {
+	Event     string    `json:"event"`
+	Provider  string    `json:"provider"`
+	AuthID    string    `json:"auth_id,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// stateNotifier tracks which accounts and providers were reported unhealthy
+// and posts transitions to the webhook.
+type stateNotifier struct {
+	cfg    StateWebhookConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	unhealthy map[string]struct{}  // auth ID
+	down      map[string]struct{}  // provider
+	sent      map[string]time.Time // event key -> last post
+}
+
+func newStateNotifier(cfg StateWebhookConfig) *stateNotifier {
+	return &stateNotifier{
+		cfg:       cfg,
+		client:    &http.Client{Timeout: stateWebhookTimeout},
+		unhealthy: make(map[string]struct{}),
+		down:      make(map[string]struct{}),
+		sent:      make(map[string]time.Time),
+	}
+}
+
+// SetStateWebhook installs, replaces or removes the webhook notified of
+// account state transitions. Reapplying the same configuration keeps the
+// tracked state.
+func (m *Manager) SetStateWebhook(cfg StateWebhookConfig) {
+	if m == nil {
+		return
+	}
+	cfg.URL = strings.TrimSpace(cfg.URL)
+	if cfg.URL == "" {
+		m.notifier.Store(nil)
+		return
+	}
+	if cur := m.notifier.Load(); cur != nil && cur.cfg == cfg {
+		return
+	}
+	m.notifier.Store(newStateNotifier(cfg))
+}
+
+// observeStateTransition reports the account and provider transitions caused
+// by result to the state webhook, if one is configured.
+func (m *Manager) observeStateTransition(result Result) {
+	n := m.notifier.Load()
+	if n == nil || result.AuthID == "" {
+		return
+	}
+	now := time.Now()
+	if result.Success {
+		n.recovered(result.Provider, result.AuthID, now)
+		return
+	}
+	if reason := unhealthyReason(result.Error); reason != "" {
+		n.accountUnhealthy(result.Provider, result.AuthID, reason, now)
+	}
+	if result.Provider != "" && providerSuspended(m.listAuths(), result.Provider, now) {
+		n.providerDown(result.Provider, now)
+	}
+}
+
+// unhealthyReason returns the reason reported for errors that need an
+// operator: credentials, payment or billing. Other errors recover on their own.
+func unhealthyReason(err *Error) string {
+	if err == nil {
+		return ""
+	}
+	category := err.ErrCategory
+	if category == CategoryUnknown {
+		category = CategorizeError(err.StatusCode(), err.Message)
+	}
+	switch category {
+	case CategoryAuthRevoked:
+		return "oauth_token_revoked"
+	case CategoryAuthError:
+		return "unauthorized"
+	case CategoryBillingError:
+		return BillingStatusMessage
+	}
+	if status := err.StatusCode(); status == http.StatusPaymentRequired {
+		return "payment_required"
+	}
+	return ""
+}
+
+// providerSuspended reports whether provider has accounts and none of them
+// can currently serve a request.
+func providerSuspended(auths []*Auth, provider string, now time.Time) bool {
+	found := false
+	for _, a := range auths {
+		if a == nil || a.Provider != provider {
+			continue
+		}
+		found = true
+		if !authSuspended(a, now) {
+			return false
+		}
+	}
+	return found
+}
+
+// authSuspended reports whether a is disabled, suspended as a whole, or has
+// every known model suspended.
+func authSuspended(a *Auth, now time.Time) bool {
+	if a.Disabled || a.Status == StatusDisabled {
+		return true
+	}
+	if a.Unavailable && a.NextRetryAfter.After(now) {
+		return true
+	}
+	if len(a.ModelStates) == 0 {
+		return false
+	}
+	for _, state := range a.ModelStates {
+		if state == nil || !state.Unavailable || !state.NextRetryAfter.After(now) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *stateNotifier) accountUnhealthy(provider, authID, reason string, now time.Time) {
+	n.mu.Lock()
+	_, seen := n.unhealthy[authID]
+	n.unhealthy[authID] = struct{}{}
+	send := !seen && n.allow(StateEventAccountUnhealthy+"|"+authID, now)
+	n.mu.Unlock()
+	if send {
+		n.post(StateEvent{Event: StateEventAccountUnhealthy, Provider: provider, AuthID: sanitizeAuthID(authID), Reason: reason, Timestamp: now})
+	}
+}
+
+func (n *stateNotifier) recovered(provider, authID string, now time.Time) {
+	var events []StateEvent
+	n.mu.Lock()
+	if _, ok := n.unhealthy[authID]; ok {
+		delete(n.unhealthy, authID)
+		if n.allow(StateEventAccountRecovered+"|"+authID, now) {
+			events = append(events, StateEvent{Event: StateEventAccountRecovered, Provider: provider, AuthID: sanitizeAuthID(authID), Timestamp: now})
+		}
+	}
+	if _, ok := n.down[provider]; ok {
+		delete(n.down, provider)
+		if n.allow(StateEventProviderRecovered+"|"+provider, now) {
+			events = append(events, StateEvent{Event: StateEventProviderRecovered, Provider: provider, Timestamp: now})
+		}
+	}
+	n.mu.Unlock()
+	for _, ev := range events {
+		n.post(ev)
+	}
+}
+
+func (n *stateNotifier) providerDown(provider string, now time.Time) {
+	n.mu.Lock()
+	_, seen := n.down[provider]
+	n.down[provider] = struct{}{}
+	send := !seen && n.allow(StateEventProviderUnavailable+"|"+provider, now)
+	n.mu.Unlock()
+	if send {
+		n.post(StateEvent{Event: StateEventProviderUnavailable, Provider: provider, Reason: "all accounts suspended", Timestamp: now})
+	}
+}
+
+// allow applies the debounce window to key. Callers hold n.mu.
+func (n *stateNotifier) allow(key string, now time.Time) bool {
+	if last, ok := n.sent[key]; ok && now.Sub(last) < n.cfg.Debounce {
+		return false
+	}
+	n.sent[key] = now
+	return true
+}
+
+// post sends ev in the background. Failures are logged and not retried.
+func (n *stateNotifier) post(ev StateEvent) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), stateWebhookTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+		if err != nil {
+			log.Warnf("state webhook: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := n.client.Do(req)
+		if err != nil {
+			// Keep the URL, which may embed a token, out of the log.
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			log.Warnf("state webhook: %s for %s failed: %v", ev.Event, ev.Provider, err)
+			return
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Warnf("state webhook: %s for %s returned %d", ev.Event, ev.Provider, resp.StatusCode)
+		}
+	}()
+}
+
+// sanitizeAuthID masks the mailbox part of e-mail addresses embedded in auth
+// IDs such as "claude-user@example.com.json".
+func sanitizeAuthID(id string) string {
+	at := strings.IndexByte(id, '@')
+	if at < 0 {
+		return id
+	}
+	start := strings.LastIndexAny(id[:at], "-_/ ") + 1
+	local := id[start:at]
+	if len(local) > 2 {
+		local = local[:2]
+	}
+	return id[:start] + local + "***" + id[at:]
+}
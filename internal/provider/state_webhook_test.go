@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+// newWebhookServer returns a fake webhook endpoint and the channel its events
+// arrive on.
+func newWebhookServer(t *testing.T) (*httptest.Server, <-chan StateEvent) {
+	t.Helper()
+	events := make(chan StateEvent, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev StateEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("decode webhook payload: %v", err)
+		}
+		events <- ev
+	}))
+	t.Cleanup(srv.Close)
+	return srv, events
+}
+
+// receiveEvents waits for n events and returns them sorted by name, then
+// checks that no further event arrives.
+func receiveEvents(t *testing.T, events <-chan StateEvent, n int) []StateEvent {
+	t.Helper()
+	var got []StateEvent
+	for len(got) < n {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d webhook events, want %d", len(got), n)
+		}
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected webhook event %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].Event < got[j].Event })
+	return got
+}
+
+func TestStateWebhook_AuthFailureTransitions(t *testing.T) {
+	srv, events := newWebhookServer(t)
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.SetStateWebhook(StateWebhookConfig{URL: srv.URL, Debounce: time.Minute})
+	ctx := context.Background()
+	authID := "claude-user@example.com.json"
+	if _, err := m.Register(ctx, &Auth{ID: authID, Provider: "claude", Status: StatusActive}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	unauthorized := Result{AuthID: authID, Provider: "claude", Model: "claude-sonnet", Error: &Error{HTTPStatus: http.StatusUnauthorized, Message: "unauthorized"}}
+	m.MarkResult(ctx, unauthorized)
+	got := receiveEvents(t, events, 2)
+	if ev := got[0]; ev.Event != StateEventAccountUnhealthy || ev.Reason != "unauthorized" || ev.Provider != "claude" || ev.AuthID != "claude-us***@example.com.json" || ev.Timestamp.IsZero() {
+		t.Errorf("account event = %+v", ev)
+	}
+	if ev := got[1]; ev.Event != StateEventProviderUnavailable || ev.Provider != "claude" || ev.AuthID != "" {
+		t.Errorf("provider event = %+v", ev)
+	}
+
+	// Repeated failures while already unhealthy stay quiet.
+	m.MarkResult(ctx, unauthorized)
+	receiveEvents(t, events, 0)
+
+	m.MarkResult(ctx, Result{AuthID: authID, Provider: "claude", Model: "claude-sonnet", Success: true})
+	got = receiveEvents(t, events, 2)
+	if got[0].Event != StateEventAccountRecovered || got[1].Event != StateEventProviderRecovered {
+		t.Errorf("recovery events = %+v", got)
+	}
+
+	// Flapping back inside the debounce window is not reported again.
+	m.MarkResult(ctx, unauthorized)
+	receiveEvents(t, events, 0)
+}
+
+func TestStateWebhook_RateLimitIsNotAccountFailure(t *testing.T) {
+	srv, events := newWebhookServer(t)
+	m := NewManager(nil, nil, nil)
+	t.Cleanup(m.Stop)
+	m.SetStateWebhook(StateWebhookConfig{URL: srv.URL})
+	ctx := context.Background()
+	for _, id := range []string{"openai-a", "openai-b"} {
+		if _, err := m.Register(ctx, &Auth{ID: id, Provider: "openai", Status: StatusActive}); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	rateLimited := func(id string) Result {
+		return Result{AuthID: id, Provider: "openai", Model: "gpt-4o", Error: &Error{HTTPStatus: http.StatusTooManyRequests, Message: openAIRateLimitBody}}
+	}
+	m.MarkResult(ctx, rateLimited("openai-a"))
+	receiveEvents(t, events, 0)
+
+	m.MarkResult(ctx, rateLimited("openai-b"))
+	got := receiveEvents(t, events, 1)
+	if got[0].Event != StateEventProviderUnavailable || got[0].Provider != "openai" {
+		t.Errorf("event = %+v, want provider_unavailable for openai", got[0])
+	}
+}
@@ -184,6 +184,8 @@ func (a *Auth) AccountInfo() (string, string) {
 // ExpirationTime attempts to extract the credential expiration timestamp from metadata.
 // It inspects common keys such as "expired", "expire", "expires_at", and also
 // nested "token" objects to remain compatible with legacy auth file formats.
+// Attributes are consulted last so custom executors can expose an expiry
+// without writing token material into metadata.
 func (a *Auth) ExpirationTime() (time.Time, bool) {
 	if a == nil {
 		return time.Time{}, false
@@ -191,6 +193,9 @@ func (a *Auth) ExpirationTime() (time.Time, bool) {
 	if ts, ok := expirationFromMap(a.Metadata); ok {
 		return ts, true
 	}
+	if ts, ok := expirationFromAttributes(a.Attributes); ok {
+		return ts, true
+	}
 	return time.Time{}, false
 }
 
@@ -243,6 +248,20 @@ func expirationFromMap(meta map[string]any) (time.Time, bool) {
 	return time.Time{}, false
 }
 
+func expirationFromAttributes(attrs map[string]string) (time.Time, bool) {
+	if len(attrs) == 0 {
+		return time.Time{}, false
+	}
+	for _, key := range expireKeys {
+		if v, ok := attrs[key]; ok {
+			if ts, ok1 := parseTimeValue(v); ok1 {
+				return ts, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
 func ProviderRefreshLead(provider string, runtime any) *time.Duration {
 	provider = strings.ToLower(strings.TrimSpace(provider))
 	if runtime != nil {
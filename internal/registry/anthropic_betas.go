@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"slices"
+	"sync"
+)
+
+// Anthropic beta flags negotiated per model.
+const (
+	betaInterleavedThinking      = "interleaved-thinking-2025-05-14"
+	betaFineGrainedToolStreaming = "fine-grained-tool-streaming-2025-05-14"
+	betaContext1M                = "context-1m-2025-08-07"
+	betaOutput128K               = "output-128k-2025-02-19"
+)
+
+// AnthropicBetas lists the anthropic-beta flags a Claude model needs, by the
+// request feature that requires them.
+type AnthropicBetas struct {
+	Base           []string // sent with every request
+	Thinking       []string // extended thinking is enabled
+	Tools          []string // the request declares tools
+	LongContext    []string // the prompt exceeds the model's standard context window
+	ExtendedOutput []string // max_tokens exceeds the model's standard output limit
+}
+
+// AnthropicFeatures are the request features that select betas.
+type AnthropicFeatures struct {
+	Thinking       bool
+	Tools          bool
+	LongContext    bool
+	ExtendedOutput bool
+}
+
+// For returns the betas needed for the features of a request, in declaration
+// order and without duplicates.
+func (b *AnthropicBetas) For(f AnthropicFeatures) []string {
+	if b == nil {
+		return nil
+	}
+	var out []string
+	add := func(enabled bool, betas []string) {
+		if !enabled {
+			return
+		}
+		for _, beta := range betas {
+			if !slices.Contains(out, beta) {
+				out = append(out, beta)
+			}
+		}
+	}
+	add(true, b.Base)
+	add(f.Thinking, b.Thinking)
+	add(f.Tools, b.Tools)
+	add(f.LongContext, b.LongContext)
+	add(f.ExtendedOutput, b.ExtendedOutput)
+	return out
+}
+
+var (
+	claude4Betas = &AnthropicBetas{
+		Thinking: []string{betaInterleavedThinking},
+		Tools:    []string{betaFineGrainedToolStreaming},
+	}
+	claude4LongContextBetas = &AnthropicBetas{
+		Thinking:    []string{betaInterleavedThinking},
+		Tools:       []string{betaFineGrainedToolStreaming},
+		LongContext: []string{betaContext1M},
+	}
+	claude37Betas = &AnthropicBetas{
+		Tools:          []string{betaFineGrainedToolStreaming},
+		ExtendedOutput: []string{betaOutput128K},
+	}
+	claude35Betas = &AnthropicBetas{
+		Tools: []string{betaFineGrainedToolStreaming},
+	}
+)
+
+// builtinClaudeModels indexes the built-in Claude models by ID and canonical ID.
+var builtinClaudeModels = sync.OnceValue(func() map[string]*ModelInfo {
+	models := GetClaudeModels()
+	byID := make(map[string]*ModelInfo, 2*len(models))
+	for _, m := range models {
+		byID[m.ID] = m
+	}
+	for _, m := range models {
+		if _, ok := byID[m.CanonicalID]; m.CanonicalID != "" && !ok {
+			byID[m.CanonicalID] = m
+		}
+	}
+	return byID
+})
+
+// ClaudeModelInfo returns the info of a model served by the Claude API: the
+// built-in Claude definition when there is one, else the registered model,
+// with configured overrides applied. It returns nil for unknown models.
+func (r *ModelRegistry) ClaudeModelInfo(modelID string) *ModelInfo {
+	s := r.snapshot()
+	info := builtinClaudeModels()[modelID]
+	if info == nil {
+		if reg := s.findModelRegistration(modelID); reg != nil {
+			info = reg.Info
+		}
+	}
+	return s.withOverride(modelID, info)
+}
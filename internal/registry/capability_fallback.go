@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"slices"
+	"time"
+)
+
+// CapabilityRequirements are the features a request needs from the model
+// that serves it.
+type CapabilityRequirements struct {
+	Tools    bool // the request declares tools
+	Vision   bool // the request contains images
+	Thinking bool // the request asks for a thinking budget or reasoning effort
+}
+
+// ModelCapabilities are the features a model advertises to clients in
+// model listings.
+type ModelCapabilities struct {
+	Vision           bool `json:"vision"`
+	Tools            bool `json:"tools"`
+	Thinking         bool `json:"thinking"`
+	StructuredOutput bool `json:"structured_output"`
+}
+
+// Capabilities reports the features of m by the same rules capability
+// fallback applies: a model that lists no supported parameters is assumed to
+// accept images, tools and response formats.
+func (m *ModelInfo) Capabilities() ModelCapabilities {
+	return ModelCapabilities{
+		Vision:           supportsParameter(m, "vision"),
+		Tools:            supportsParameter(m, "tools"),
+		Thinking:         supportsThinking(m),
+		StructuredOutput: supportsParameter(m, "response_format") || supportsParameter(m, "structured_outputs"),
+	}
+}
+
+// contextSize returns the context window of info, or zero when unknown.
+func contextSize(info *ModelInfo) int {
+	if info.ContextLength > 0 {
+		return info.ContextLength
+	}
+	return info.InputTokenLimit
+}
+
+// supportsParameter reports whether info lists param among its supported
+// parameters. Models that list none are assumed to support it.
+func supportsParameter(info *ModelInfo, param string) bool {
+	return len(info.SupportedParameters) == 0 || slices.Contains(info.SupportedParameters, param)
+}
+
+func supportsThinking(info *ModelInfo) bool {
+	return info.Thinking != nil || slices.Contains(info.SupportedParameters, "reasoning")
+}
+
+// meets reports whether candidate provides everything need asks for and at
+// least the context window of the requested model, when both are known.
+func (need CapabilityRequirements) meets(candidate *ModelInfo, minContext int) bool {
+	if need.Tools && !supportsParameter(candidate, "tools") {
+		return false
+	}
+	if need.Vision && !supportsParameter(candidate, "vision") {
+		return false
+	}
+	if need.Thinking && !supportsThinking(candidate) {
+		return false
+	}
+	if size := contextSize(candidate); minContext > 0 && size > 0 && size < minContext {
+		return false
+	}
+	return true
+}
+
+// ClosestAvailableModel returns the available model that best stands in for
+// modelID, or "" when none meets need. Candidates must have a ready account,
+// provide every capability in need and offer at least modelID's context
+// window. Among them it prefers, in order: the same owner as modelID, the
+// same thinking support, the context window nearest to modelID's, the newest
+// model, and finally the lowest ID.
+func (r *ModelRegistry) ClosestAvailableModel(modelID string, need CapabilityRequirements) string {
+	s := r.snapshot()
+	now := time.Now()
+
+	var requested *ModelInfo
+	if reg := s.findModelRegistration(modelID); reg != nil {
+		requested = reg.Info
+	}
+	requested = s.withOverride(modelID, requested)
+
+	var wantOwner string
+	var wantContext int
+	var wantThinking bool
+	if requested != nil {
+		wantOwner = requested.OwnedBy
+		wantContext = contextSize(requested)
+		wantThinking = supportsThinking(requested)
+	}
+
+	ready := make(map[string]*ModelInfo)
+	for _, reg := range s.models {
+		if reg == nil || reg.Info == nil || reg.Info.Hidden || reg.Info.ID == modelID {
+			continue
+		}
+		if requested != nil && requested.CanonicalID != "" && reg.Info.CanonicalID == requested.CanonicalID {
+			continue
+		}
+		var counts availabilityCounts
+		counts.add(s, reg, now)
+		if counts.ready == 0 {
+			continue
+		}
+		info := s.withOverride(reg.Info.ID, reg.Info)
+		if need.meets(info, wantContext) {
+			ready[info.ID] = info
+		}
+	}
+
+	var best *ModelInfo
+	for _, info := range ready {
+		if best == nil || closerModel(info, best, wantOwner, wantContext, wantThinking) {
+			best = info
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.ID
+}
+
+// closerModel reports whether a is a closer substitute than b.
+func closerModel(a, b *ModelInfo, owner string, context int, thinking bool) bool {
+	if owner != "" {
+		if sameA, sameB := a.OwnedBy == owner, b.OwnedBy == owner; sameA != sameB {
+			return sameA
+		}
+	}
+	if matchA, matchB := supportsThinking(a) == thinking, supportsThinking(b) == thinking; matchA != matchB {
+		return matchA
+	}
+	if context > 0 {
+		if distA, distB := contextDistance(a, context), contextDistance(b, context); distA != distB {
+			return distA < distB
+		}
+	}
+	if a.Created != b.Created {
+		return a.Created > b.Created
+	}
+	return a.ID < b.ID
+}
+
+// contextDistance is how far the context window of info is from want. An
+// unknown window counts as farthest.
+func contextDistance(info *ModelInfo, want int) int {
+	size := contextSize(info)
+	if size == 0 {
+		return int(^uint(0) >> 1)
+	}
+	if size > want {
+		return size - want
+	}
+	return want - size
+}
@@ -0,0 +1,43 @@
+package registry
+
+import "testing"
+
+func TestClosestAvailableModel(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterClient("c1", "claude", []*ModelInfo{
+		{ID: "claude-opus-4-5", OwnedBy: "anthropic", ContextLength: 200000, Created: 3},
+	})
+	r.RegisterClient("c2", "claude", []*ModelInfo{
+		{ID: "claude-sonnet-4-5", OwnedBy: "anthropic", ContextLength: 200000, Created: 2},
+		{ID: "claude-haiku-4-5", OwnedBy: "anthropic", ContextLength: 100000, Created: 4},
+	})
+	r.RegisterClient("c3", "gemini", []*ModelInfo{
+		{ID: "gemini-2.5-pro", OwnedBy: "google", ContextLength: 1048576, Created: 5, Thinking: &ThinkingSupport{Min: 128, Max: 32768}},
+	})
+	r.SuspendClientModel("c1", "claude-opus-4-5", "unauthorized")
+
+	if got := r.ClosestAvailableModel("claude-opus-4-5", CapabilityRequirements{}); got != "claude-sonnet-4-5" {
+		t.Errorf("ClosestAvailableModel() = %q, want same-owner model with enough context", got)
+	}
+	if got := r.ClosestAvailableModel("claude-opus-4-5", CapabilityRequirements{Thinking: true}); got != "gemini-2.5-pro" {
+		t.Errorf("ClosestAvailableModel(thinking) = %q, want the only thinking model", got)
+	}
+	if got := r.ClosestAvailableModel("unknown-model", CapabilityRequirements{}); got != "claude-haiku-4-5" {
+		t.Errorf("ClosestAvailableModel(unknown) = %q, want newest model without thinking", got)
+	}
+}
+
+func TestClosestAvailableModelRespectsCapabilities(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterClient("c1", "openai", []*ModelInfo{
+		{ID: "text-only", OwnedBy: "openai", SupportedParameters: []string{"tools"}, Created: 2},
+		{ID: "no-tools", OwnedBy: "openai", SupportedParameters: []string{"vision"}, Created: 1},
+	})
+
+	if got := r.ClosestAvailableModel("gpt-x", CapabilityRequirements{Tools: true, Vision: true}); got != "" {
+		t.Errorf("ClosestAvailableModel() = %q, want no model", got)
+	}
+	if got := r.ClosestAvailableModel("gpt-x", CapabilityRequirements{Vision: true}); got != "no-tools" {
+		t.Errorf("ClosestAvailableModel(vision) = %q, want no-tools", got)
+	}
+}
@@ -0,0 +1,200 @@
+package registry
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Model availability states reported with ModelListOptions.Availability.
+const (
+	AvailabilityAvailable   = "available"
+	AvailabilityCoolingDown = "cooling_down"
+	AvailabilityUnavailable = "unavailable"
+)
+
+// quotaExceededWindow is how long a quota-exceeded mark keeps a client out of
+// the available count.
+const quotaExceededWindow = 5 * time.Minute
+
+// ModelListOptions selects the optional views of the model list.
+type ModelListOptions struct {
+	// Availability annotates each model with an "availability" object and
+	// lists canonical model families that are not already listed by ID.
+	Availability bool
+
+	// AvailableOnly drops models that no account can serve right now,
+	// including those whose accounts are all cooling down.
+	AvailableOnly bool
+}
+
+// ModelAvailability summarizes how many registered accounts can currently
+// serve a model.
+type ModelAvailability struct {
+	Status      string   `json:"status"`
+	Accounts    int      `json:"accounts"`
+	Ready       int      `json:"ready"`
+	CoolingDown int      `json:"cooling_down"`
+	Suspended   int      `json:"suspended"`
+	Providers   []string `json:"providers,omitempty"`
+	// DownProviders lists providers whose circuit breaker is open or whose
+	// accounts are all suspended; their accounts count as suspended.
+	DownProviders []string `json:"down_providers,omitempty"`
+}
+
+type availabilityCounts struct {
+	accounts, ready, cooling, suspended int
+	providers                           map[string]struct{}
+	down                                map[string]struct{}
+}
+
+// add counts the clients of one registration. A client is cooling down while
+// it has a recent quota mark or a quota suspension, and suspended for any
+// other suspension reason. Every client of a registration whose providers are
+// all down counts as suspended.
+func (a *availabilityCounts) add(s *registryState, reg *ModelRegistration, now time.Time) {
+	if reg == nil || reg.Count <= 0 {
+		return
+	}
+	if a.providers == nil {
+		a.providers = make(map[string]struct{})
+	}
+	for provider, count := range reg.Providers {
+		if count > 0 {
+			a.providers[provider] = struct{}{}
+		}
+	}
+	for _, provider := range s.downProviderNames(reg, now) {
+		if a.down == nil {
+			a.down = make(map[string]struct{})
+		}
+		a.down[provider] = struct{}{}
+	}
+	a.accounts += reg.Count
+	if s.registrationDown(reg, now) {
+		a.suspended += reg.Count
+		return
+	}
+	cooling, suspended := 0, 0
+	for clientID, reason := range reg.SuspendedClients {
+		if isQuotaSuspension(reason) || recentQuotaMark(reg, clientID, now) {
+			cooling++
+		} else {
+			suspended++
+		}
+	}
+	for clientID, quotaTime := range reg.QuotaExceededClients {
+		if _, counted := reg.SuspendedClients[clientID]; !counted && quotaTime != nil && now.Sub(*quotaTime) < quotaExceededWindow {
+			cooling++
+		}
+	}
+	a.cooling += cooling
+	a.suspended += suspended
+	if ready := reg.Count - cooling - suspended; ready > 0 {
+		a.ready += ready
+	}
+}
+
+func (a *availabilityCounts) merge(other *availabilityCounts) {
+	if other == nil {
+		return
+	}
+	a.accounts += other.accounts
+	a.ready += other.ready
+	a.cooling += other.cooling
+	a.suspended += other.suspended
+	if a.providers == nil {
+		a.providers = make(map[string]struct{}, len(other.providers))
+	}
+	for provider := range other.providers {
+		a.providers[provider] = struct{}{}
+	}
+	for provider := range other.down {
+		if a.down == nil {
+			a.down = make(map[string]struct{})
+		}
+		a.down[provider] = struct{}{}
+	}
+}
+
+func (a *availabilityCounts) availability() ModelAvailability {
+	status := AvailabilityUnavailable
+	switch {
+	case a.ready > 0:
+		status = AvailabilityAvailable
+	case a.cooling > 0:
+		status = AvailabilityCoolingDown
+	}
+	providers := make([]string, 0, len(a.providers))
+	for provider := range a.providers {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	var down []string
+	for provider := range a.down {
+		down = append(down, provider)
+	}
+	sort.Strings(down)
+	return ModelAvailability{
+		Status:        status,
+		Accounts:      a.accounts,
+		Ready:         a.ready,
+		CoolingDown:   a.cooling,
+		Suspended:     a.suspended,
+		Providers:     providers,
+		DownProviders: down,
+	}
+}
+
+func isQuotaSuspension(reason string) bool {
+	return strings.EqualFold(reason, "quota") || strings.EqualFold(reason, "quota_group")
+}
+
+func recentQuotaMark(reg *ModelRegistration, clientID string, now time.Time) bool {
+	quotaTime := reg.QuotaExceededClients[clientID]
+	return quotaTime != nil && now.Sub(*quotaTime) < quotaExceededWindow
+}
+
+// familyAvailability aggregates every registration mapped to canonicalID and
+// returns the distinct provider-specific model IDs of the family.
+func (s *registryState) familyAvailability(canonicalID string, now time.Time) (*availabilityCounts, []string, *ModelInfo) {
+	mappings := s.canonicalIndex[canonicalID]
+	if len(mappings) == 0 {
+		return nil, nil, nil
+	}
+	counts := &availabilityCounts{}
+	seen := make(map[string]struct{}, len(mappings))
+	var ids []string
+	var info *ModelInfo
+	for _, m := range mappings {
+		reg := s.models[m.Provider+":"+m.ModelID]
+		if reg == nil || reg.Count <= 0 {
+			continue
+		}
+		counts.add(s, reg, now)
+		if info == nil {
+			info = reg.Info
+		}
+		if _, ok := seen[m.ModelID]; !ok {
+			seen[m.ModelID] = struct{}{}
+			ids = append(ids, m.ModelID)
+		}
+	}
+	if info == nil {
+		return nil, nil, nil
+	}
+	sort.Strings(ids)
+	return counts, ids, info
+}
+
+// isFamily reports whether a canonical family spans IDs other than its own.
+func isFamily(canonicalID string, ids []string) bool {
+	return len(ids) > 1 || (len(ids) == 1 && ids[0] != canonicalID)
+}
+
+// ListModels returns the model list for handlerType like GetAvailableModels,
+// optionally annotated with live availability or filtered to models that can
+// be served right now.
+func (r *ModelRegistry) ListModels(handlerType string, opts ModelListOptions) []map[string]any {
+	return r.getAvailableModelsFromState(r.snapshot(), handlerType, opts)
+}
@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"testing"
+)
+
+func newTestRegistry() *ModelRegistry {
+	r := &ModelRegistry{}
+	r.state.Store(newRegistryState())
+	return r
+}
+
+func modelsByID(models []map[string]any) map[string]map[string]any {
+	byID := make(map[string]map[string]any, len(models))
+	for _, m := range models {
+		byID[m["id"].(string)] = m
+	}
+	return byID
+}
+
+func TestListModelsAvailability(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterClient("claude-1", "claude", []*ModelInfo{{ID: "claude-sonnet-4-5", CanonicalID: "claude-sonnet-4-5"}})
+	r.RegisterClient("kiro-1", "kiro", []*ModelInfo{
+		{ID: "claude-sonnet-4.5", CanonicalID: "claude-sonnet-4-5"},
+		{ID: "kiro-opus", CanonicalID: "claude-opus"},
+	})
+	r.RegisterClient("gemini-1", "gemini-cli", []*ModelInfo{{ID: "gemini-x"}})
+	r.SetModelQuotaExceeded("claude-1", "claude-sonnet-4-5")
+	r.SuspendClientModel("gemini-1", "gemini-x", "unauthorized")
+
+	if _, ok := modelsByID(r.GetAvailableModels("openai"))["gemini-x"]; ok {
+		t.Fatal("default list includes a model whose only account is suspended")
+	}
+
+	models := modelsByID(r.ListModels("openai", ModelListOptions{Availability: true}))
+	family := models["claude-sonnet-4-5"]
+	avail := family["availability"].(ModelAvailability)
+	if avail.Status != AvailabilityAvailable || avail.Accounts != 2 || avail.Ready != 1 || avail.CoolingDown != 1 {
+		t.Errorf("claude-sonnet-4-5 availability = %+v, want 1 of 2 accounts ready", avail)
+	}
+	if ids, _ := family["family"].([]string); len(ids) != 2 {
+		t.Errorf("claude-sonnet-4-5 family = %v", family["family"])
+	}
+	if got := models["claude-sonnet-4.5"]["canonical_id"]; got != "claude-sonnet-4-5" {
+		t.Errorf("claude-sonnet-4.5 canonical_id = %v", got)
+	}
+	if avail := models["gemini-x"]["availability"].(ModelAvailability); avail.Status != AvailabilityUnavailable || avail.Suspended != 1 {
+		t.Errorf("gemini-x availability = %+v", avail)
+	}
+	if ids, _ := models["claude-opus"]["family"].([]string); len(ids) != 1 || ids[0] != "kiro-opus" {
+		t.Errorf("claude-opus family entry = %v", models["claude-opus"])
+	}
+
+	r.SetModelQuotaExceeded("kiro-1", "claude-sonnet-4.5")
+	models = modelsByID(r.ListModels("openai", ModelListOptions{AvailableOnly: true}))
+	for _, id := range []string{"gemini-x", "claude-sonnet-4.5"} {
+		if _, ok := models[id]; ok {
+			t.Errorf("available_only list includes %s", id)
+		}
+	}
+	if _, ok := models["kiro-opus"]; !ok {
+		t.Error("available_only list dropped kiro-opus")
+	}
+	if _, ok := models["kiro-opus"]["availability"]; ok {
+		t.Error("availability annotation added without being requested")
+	}
+}
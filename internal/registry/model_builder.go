@@ -161,6 +161,12 @@ func (b *ModelBuilder) ThinkingFull(min, max int, zeroAllowed, dynamicAllowed bo
 	return b
 }
 
+// Betas sets the anthropic-beta flags the model needs per request feature.
+func (b *ModelBuilder) Betas(betas *AnthropicBetas) *ModelBuilder {
+	b.info.AnthropicBetas = betas
+	return b
+}
+
 // Limits sets input and output token limits.
 func (b *ModelBuilder) Limits(input, output int) *ModelBuilder {
 	b.info.InputTokenLimit = input
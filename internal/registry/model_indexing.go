@@ -16,11 +16,11 @@ func (r *ModelRegistry) GetProvidersWithModelID(modelID string) []ProviderModelM
 			}
 		}
 		if len(result) > 0 {
-			return result
+			return s.liveMappings(result)
 		}
 	}
 
-	providers := s.getModelProvidersInternal(modelID)
+	providers := s.liveProviders(s.getModelProvidersInternal(modelID))
 	if len(providers) == 0 {
 		return nil
 	}
@@ -74,9 +74,9 @@ func (r *ModelRegistry) GetModelProviders(modelID string) []string {
 			for i, p := range available {
 				result[i] = p.provider
 			}
-			return result
+			return s.liveProviders(result)
 		}
 	}
 
-	return s.getModelProvidersInternal(modelID)
+	return s.liveProviders(s.getModelProvidersInternal(modelID))
 }
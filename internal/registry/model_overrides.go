@@ -0,0 +1,94 @@
+package registry
+
+// ModelPricing is the price of a model in USD per million tokens.
+type ModelPricing struct {
+	Input       float64 `json:"input,omitempty"`
+	Output      float64 `json:"output,omitempty"`
+	CachedInput float64 `json:"cached_input,omitempty"`
+}
+
+// ModelInfoOverride replaces parts of a model's built-in info. Zero and nil
+// fields keep the built-in value.
+type ModelInfoOverride struct {
+	ContextLength       int
+	InputTokenLimit     int
+	OutputTokenLimit    int
+	MaxCompletionTokens int
+	Thinking            *ThinkingSupport
+	SupportedParameters []string
+	Pricing             *ModelPricing
+	AnthropicBetas      *AnthropicBetas
+}
+
+// SetModelInfoOverrides replaces the configured model info overrides, keyed
+// by model ID. Overrides apply to GetModelInfo and model listings, and give
+// info to models the registry does not know. A nil map clears them.
+func (r *ModelRegistry) SetModelInfoOverrides(overrides map[string]ModelInfoOverride) {
+	if r == nil {
+		return
+	}
+	var copied map[string]ModelInfoOverride
+	if len(overrides) > 0 {
+		copied = make(map[string]ModelInfoOverride, len(overrides))
+		for id, o := range overrides {
+			copied[id] = o
+		}
+	}
+
+	r.writerMu.Lock()
+	defer r.writerMu.Unlock()
+
+	newState := r.state.Load().clone()
+	newState.overrides = copied
+	r.state.Store(newState)
+}
+
+// withOverride returns info merged with the override for modelID, its ID or
+// its canonical ID, in that order. info is returned as is when no override
+// applies; a nil info gets one built from the override alone.
+func (s *registryState) withOverride(modelID string, info *ModelInfo) *ModelInfo {
+	if len(s.overrides) == 0 {
+		return info
+	}
+	o, ok := s.overrides[modelID]
+	if !ok && info != nil {
+		if o, ok = s.overrides[info.ID]; !ok && info.CanonicalID != "" {
+			o, ok = s.overrides[info.CanonicalID]
+		}
+	}
+	if !ok {
+		return info
+	}
+
+	var merged *ModelInfo
+	if info != nil {
+		merged = cloneModelInfo(info)
+	} else {
+		merged = &ModelInfo{ID: modelID, Object: "model"}
+	}
+	if o.ContextLength > 0 {
+		merged.ContextLength = o.ContextLength
+	}
+	if o.InputTokenLimit > 0 {
+		merged.InputTokenLimit = o.InputTokenLimit
+	}
+	if o.OutputTokenLimit > 0 {
+		merged.OutputTokenLimit = o.OutputTokenLimit
+	}
+	if o.MaxCompletionTokens > 0 {
+		merged.MaxCompletionTokens = o.MaxCompletionTokens
+	}
+	if o.Thinking != nil {
+		merged.Thinking = o.Thinking
+	}
+	if len(o.SupportedParameters) > 0 {
+		merged.SupportedParameters = o.SupportedParameters
+	}
+	if o.Pricing != nil {
+		merged.Pricing = o.Pricing
+	}
+	if o.AnthropicBetas != nil {
+		merged.AnthropicBetas = o.AnthropicBetas
+	}
+	return merged
+}
@@ -0,0 +1,80 @@
+package registry
+
+import "testing"
+
+func TestModelInfoOverridesWinOverBuiltIn(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterClient("c1", "gemini", []*ModelInfo{{
+		ID:               "gemini-2.5-pro",
+		Object:           "model",
+		OwnedBy:          "google",
+		ContextLength:    1048576,
+		OutputTokenLimit: 65536,
+		Thinking:         &ThinkingSupport{Min: 128, Max: 32768, DynamicAllowed: true},
+	}})
+	r.SetModelInfoOverrides(map[string]ModelInfoOverride{
+		"gemini-2.5-pro": {
+			OutputTokenLimit: 8192,
+			Thinking:         &ThinkingSupport{Min: 0, Max: 16384, ZeroAllowed: true},
+			Pricing:          &ModelPricing{Input: 1.25, Output: 10},
+		},
+	})
+
+	info := r.GetModelInfo("gemini-2.5-pro")
+	if info == nil {
+		t.Fatal("GetModelInfo() = nil")
+	}
+	if info.OutputTokenLimit != 8192 {
+		t.Errorf("OutputTokenLimit = %d, want override 8192", info.OutputTokenLimit)
+	}
+	if info.ContextLength != 1048576 {
+		t.Errorf("ContextLength = %d, want built-in 1048576", info.ContextLength)
+	}
+	if info.Thinking == nil || info.Thinking.Max != 16384 || !info.Thinking.ZeroAllowed {
+		t.Errorf("Thinking = %+v, want override", info.Thinking)
+	}
+	if info.Pricing == nil || info.Pricing.Output != 10 {
+		t.Errorf("Pricing = %+v, want override", info.Pricing)
+	}
+
+	models := modelsByID(r.GetAvailableModels("openai"))
+	if got := models["gemini-2.5-pro"]["pricing"]; got == nil {
+		t.Errorf("listing has no pricing: %v", models["gemini-2.5-pro"])
+	}
+
+	r.SetModelInfoOverrides(nil)
+	if info := r.GetModelInfo("gemini-2.5-pro"); info.OutputTokenLimit != 65536 || info.Pricing != nil {
+		t.Errorf("after clearing overrides got %+v, want built-in info", info)
+	}
+}
+
+func TestModelInfoOverrideMatchesCanonicalID(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterClient("c1", "kiro", []*ModelInfo{{
+		ID:          "kiro-claude-sonnet-4-5",
+		Object:      "model",
+		CanonicalID: "claude-sonnet-4-5",
+	}})
+	r.SetModelInfoOverrides(map[string]ModelInfoOverride{
+		"claude-sonnet-4-5": {ContextLength: 200000},
+	})
+
+	if info := r.GetModelInfo("kiro-claude-sonnet-4-5"); info == nil || info.ContextLength != 200000 {
+		t.Errorf("GetModelInfo() = %+v, want canonical override", info)
+	}
+}
+
+func TestModelInfoOverrideDescribesUnknownModel(t *testing.T) {
+	r := newTestRegistry()
+	if r.GetModelInfo("new-model") != nil {
+		t.Fatal("unknown model has info before overrides")
+	}
+	r.SetModelInfoOverrides(map[string]ModelInfoOverride{
+		"new-model": {ContextLength: 128000, MaxCompletionTokens: 16384},
+	})
+
+	info := r.GetModelInfo("new-model")
+	if info == nil || info.ID != "new-model" || info.ContextLength != 128000 || info.MaxCompletionTokens != 16384 {
+		t.Errorf("GetModelInfo() = %+v, want info from override", info)
+	}
+}
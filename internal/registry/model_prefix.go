@@ -87,6 +87,7 @@ func (r *ModelRegistry) convertModelToMapWithState(s *registryState, model *Mode
 	if model == nil {
 		return nil
 	}
+	model = s.withOverride(model.ID, model)
 
 	prefix := formatProviderPrefix(s.showProviderPrefixes, model.Type)
 
@@ -121,6 +122,10 @@ func (r *ModelRegistry) convertModelToMapWithState(s *registryState, model *Mode
 		if len(model.SupportedParameters) > 0 {
 			result["supported_parameters"] = model.SupportedParameters
 		}
+		if model.Pricing != nil {
+			result["pricing"] = model.Pricing
+		}
+		result["capabilities"] = model.Capabilities()
 		return result
 
 	case "claude":
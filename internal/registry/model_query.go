@@ -9,18 +9,21 @@ import (
 
 func (r *ModelRegistry) GetAvailableModels(handlerType string) []map[string]any {
 	s := r.snapshot()
-	return r.getAvailableModelsFromState(s, handlerType)
+	return r.getAvailableModelsFromState(s, handlerType, ModelListOptions{})
 }
 
-func (r *ModelRegistry) getAvailableModelsFromState(s *registryState, handlerType string) []map[string]any {
-	quotaExpiredDuration := 5 * time.Minute
+func (r *ModelRegistry) getAvailableModelsFromState(s *registryState, handlerType string, opts ModelListOptions) []map[string]any {
+	quotaExpiredDuration := quotaExceededWindow
 	now := time.Now()
+	annotate := opts.Availability || opts.AvailableOnly
 
 	type modelAggregate struct {
 		info             *ModelInfo
 		effectiveClients int
 		providers        map[string]int
 		isAvailable      bool
+		counts           availabilityCounts
+		providerCounts   map[string]*availabilityCounts
 	}
 	aggregated := make(map[string]*modelAggregate)
 
@@ -50,6 +53,10 @@ func (r *ModelRegistry) getAvailableModelsFromState(s *registryState, handlerTyp
 			}
 		}
 
+		if s.registrationDown(registration, now) {
+			otherSuspended = registration.Count
+		}
+
 		effectiveClients := availableClients - expiredClients - otherSuspended
 		if effectiveClients < 0 {
 			effectiveClients = 0
@@ -77,27 +84,98 @@ func (r *ModelRegistry) getAvailableModelsFromState(s *registryState, handlerTyp
 		for provider, count := range registration.Providers {
 			existing.providers[provider] += count
 		}
+
+		if annotate {
+			existing.counts.add(s, registration, now)
+			if existing.providerCounts == nil {
+				existing.providerCounts = make(map[string]*availabilityCounts)
+			}
+			for provider := range registration.Providers {
+				pc := existing.providerCounts[provider]
+				if pc == nil {
+					pc = &availabilityCounts{}
+					existing.providerCounts[provider] = pc
+				}
+				pc.add(s, registration, now)
+			}
+		}
 	}
 
 	models := make([]map[string]any, 0, len(aggregated))
 
-	for _, agg := range aggregated {
-		if !agg.isAvailable {
+	// appendModel converts info and, when requested, annotates it with the
+	// given availability and its canonical family. It drops models that
+	// cannot be served now when only available models were asked for.
+	appendModel := func(info *ModelInfo, counts *availabilityCounts, family []string) {
+		if opts.AvailableOnly && counts.ready == 0 {
+			return
+		}
+		model := r.convertModelToMapWithState(s, info, handlerType)
+		if model == nil {
+			return
+		}
+		if opts.Availability {
+			model["availability"] = counts.availability()
+			if len(family) > 0 {
+				model["family"] = family
+			} else if info.CanonicalID != "" && info.CanonicalID != info.ID {
+				model["canonical_id"] = info.CanonicalID
+			}
+		}
+		models = append(models, model)
+	}
+
+	for modelID, agg := range aggregated {
+		if !agg.isAvailable && !opts.Availability {
 			continue
 		}
 
+		// Requests for a canonical ID are routed across its whole family, so
+		// its availability is the family's.
+		counts := &agg.counts
+		var family []string
+		if annotate {
+			if familyCounts, ids, _ := s.familyAvailability(modelID, now); familyCounts != nil && isFamily(modelID, ids) {
+				counts, family = familyCounts, ids
+			}
+		}
+
 		if s.showProviderPrefixes && len(agg.providers) > 0 {
 			for providerType := range agg.providers {
 				modelInfoCopy := *agg.info
 				modelInfoCopy.Type = providerType
-				if model := r.convertModelToMapWithState(s, &modelInfoCopy, handlerType); model != nil {
+				providerCounts := counts
+				if pc := agg.providerCounts[providerType]; pc != nil {
+					providerCounts = pc
+				}
+				if annotate {
+					appendModel(&modelInfoCopy, providerCounts, nil)
+				} else if model := r.convertModelToMapWithState(s, &modelInfoCopy, handlerType); model != nil {
 					models = append(models, model)
 				}
 			}
-		} else {
-			if model := r.convertModelToMapWithState(s, agg.info, handlerType); model != nil {
-				models = append(models, model)
+		} else if annotate {
+			appendModel(agg.info, counts, family)
+		} else if model := r.convertModelToMapWithState(s, agg.info, handlerType); model != nil {
+			models = append(models, model)
+		}
+	}
+
+	// Canonical families whose ID is not itself a model ID get their own entry.
+	if opts.Availability {
+		for canonicalID := range s.canonicalIndex {
+			if _, listed := aggregated[canonicalID]; listed {
+				continue
 			}
+			counts, ids, info := s.familyAvailability(canonicalID, now)
+			if counts == nil {
+				continue
+			}
+			familyInfo := *info
+			familyInfo.ID = canonicalID
+			familyInfo.Type = ""
+			familyInfo.CanonicalID = ""
+			appendModel(&familyInfo, counts, ids)
 		}
 	}
 
@@ -141,15 +219,20 @@ func (r *ModelRegistry) getModelCountFromState(s *registryState, modelID string)
 	return result
 }
 
+// ClientModels returns the model IDs registered for clientID in registration order.
+func (r *ModelRegistry) ClientModels(clientID string) []string {
+	s := r.snapshot()
+	return append([]string(nil), s.clientModels[clientID]...)
+}
+
 func (r *ModelRegistry) GetModelInfo(modelID string) *ModelInfo {
 	s := r.snapshot()
 
-	reg := s.findModelRegistration(modelID)
-	if reg != nil {
-		return reg.Info
+	var info *ModelInfo
+	if reg := s.findModelRegistration(modelID); reg != nil {
+		info = reg.Info
 	}
-
-	return nil
+	return s.withOverride(modelID, info)
 }
 
 func (r *ModelRegistry) GetAvailableProviders() []string {
@@ -177,7 +260,7 @@ func (r *ModelRegistry) GetAvailableProviders() []string {
 func (r *ModelRegistry) GetFirstAvailableModel(handlerType string) (string, error) {
 	s := r.snapshot()
 
-	models := r.getAvailableModelsFromState(s, handlerType)
+	models := r.getAvailableModelsFromState(s, handlerType, ModelListOptions{})
 	if len(models) == 0 {
 		return "", fmt.Errorf("no models available for handler type: %s", handlerType)
 	}
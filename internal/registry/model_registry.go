@@ -24,11 +24,27 @@ type ModelInfo struct {
 	MaxCompletionTokens        int              `json:"max_completion_tokens,omitempty"`
 	SupportedParameters        []string         `json:"supported_parameters,omitempty"`
 	Thinking                   *ThinkingSupport `json:"thinking,omitempty"`
+	Pricing                    *ModelPricing    `json:"pricing,omitempty"`
+	AnthropicBetas             *AnthropicBetas  `json:"-"`
 	Priority                   int              `json:"priority,omitempty"`
 	UpstreamName               string           `json:"-"`
 	Hidden                     bool             `json:"-"`
 }
 
+// ContextWindow returns the model's context window, or zero when unknown.
+func (m *ModelInfo) ContextWindow() int {
+	return contextSize(m)
+}
+
+// OutputLimit returns the most tokens the model generates by default, or
+// zero when unknown.
+func (m *ModelInfo) OutputLimit() int {
+	if m.MaxCompletionTokens > 0 {
+		return m.MaxCompletionTokens
+	}
+	return m.OutputTokenLimit
+}
+
 type ThinkingSupport struct {
 	Min            int  `json:"min,omitempty"`
 	Max            int  `json:"max,omitempty"`
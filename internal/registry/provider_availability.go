@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"sort"
+	"time"
+)
+
+// SetProviderDownUntil marks provider as unable to serve any request until
+// the given time, because its circuit breaker is open or every account is
+// suspended. Its models are then listed as unavailable and model resolution
+// skips it while another provider can serve the model. A zero time clears the
+// mark.
+func (r *ModelRegistry) SetProviderDownUntil(provider string, until time.Time) {
+	if r == nil || provider == "" {
+		return
+	}
+	cur, ok := r.snapshot().downProviders[provider]
+	if (!ok && until.IsZero()) || (ok && cur.Equal(until)) {
+		return
+	}
+	r.writerMu.Lock()
+	defer r.writerMu.Unlock()
+
+	newState := r.snapshot().clone()
+	if until.IsZero() {
+		delete(newState.downProviders, provider)
+	} else {
+		newState.downProviders[provider] = until
+	}
+	r.state.Store(newState)
+}
+
+// ProviderDownUntil returns the time until which provider is marked down.
+func (r *ModelRegistry) ProviderDownUntil(provider string) (time.Time, bool) {
+	s := r.snapshot()
+	if !s.providerDown(provider, time.Now()) {
+		return time.Time{}, false
+	}
+	return s.downProviders[provider], true
+}
+
+func (s *registryState) providerDown(provider string, now time.Time) bool {
+	until, ok := s.downProviders[provider]
+	return ok && now.Before(until)
+}
+
+// registrationDown reports whether every provider serving reg is down.
+func (s *registryState) registrationDown(reg *ModelRegistration, now time.Time) bool {
+	if len(s.downProviders) == 0 || reg == nil {
+		return false
+	}
+	found := false
+	for provider, count := range reg.Providers {
+		if count <= 0 {
+			continue
+		}
+		if !s.providerDown(provider, now) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// downProviderNames returns the providers of reg that are down, sorted.
+func (s *registryState) downProviderNames(reg *ModelRegistration, now time.Time) []string {
+	var names []string
+	for provider, count := range reg.Providers {
+		if count > 0 && s.providerDown(provider, now) {
+			names = append(names, provider)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// liveMappings drops mappings to down providers. When every provider is down
+// the mappings are returned unchanged, so requests keep probing for recovery.
+func (s *registryState) liveMappings(mappings []ProviderModelMapping) []ProviderModelMapping {
+	if len(s.downProviders) == 0 {
+		return mappings
+	}
+	now := time.Now()
+	live := make([]ProviderModelMapping, 0, len(mappings))
+	for _, m := range mappings {
+		if !s.providerDown(m.Provider, now) {
+			live = append(live, m)
+		}
+	}
+	if len(live) == 0 {
+		return mappings
+	}
+	return live
+}
+
+// liveProviders is liveMappings for plain provider names.
+func (s *registryState) liveProviders(providers []string) []string {
+	if len(s.downProviders) == 0 {
+		return providers
+	}
+	now := time.Now()
+	live := make([]string, 0, len(providers))
+	for _, p := range providers {
+		if !s.providerDown(p, now) {
+			live = append(live, p)
+		}
+	}
+	if len(live) == 0 {
+		return providers
+	}
+	return live
+}
@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDownProviderSkippedInFamily(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterClient("claude-1", "claude", []*ModelInfo{{ID: "claude-sonnet-4-5", CanonicalID: "claude-sonnet-4-5"}})
+	r.RegisterClient("kiro-1", "kiro", []*ModelInfo{{ID: "claude-sonnet-4.5", CanonicalID: "claude-sonnet-4-5"}})
+
+	if got := r.GetModelProviders("claude-sonnet-4-5"); len(got) != 2 {
+		t.Fatalf("providers = %v, want both", got)
+	}
+
+	r.SetProviderDownUntil("claude", time.Now().Add(time.Minute))
+	if got := r.GetModelProviders("claude-sonnet-4-5"); len(got) != 1 || got[0] != "kiro" {
+		t.Errorf("providers with claude down = %v, want [kiro]", got)
+	}
+	if got := r.GetProvidersWithModelID("claude-sonnet-4-5"); len(got) != 1 || got[0].Provider != "kiro" {
+		t.Errorf("mappings with claude down = %v, want kiro only", got)
+	}
+	if _, ok := modelsByID(r.GetAvailableModels("openai"))["claude-sonnet-4-5"]; ok {
+		t.Error("default list includes a model whose only provider is down")
+	}
+	models := modelsByID(r.ListModels("openai", ModelListOptions{Availability: true}))
+	avail := models["claude-sonnet-4-5"]["availability"].(ModelAvailability)
+	if avail.Status != AvailabilityAvailable || avail.Ready != 1 || avail.Suspended != 1 {
+		t.Errorf("family availability = %+v, want kiro ready and claude suspended", avail)
+	}
+	if len(avail.DownProviders) != 1 || avail.DownProviders[0] != "claude" {
+		t.Errorf("down providers = %v, want [claude]", avail.DownProviders)
+	}
+
+	// With every provider down, resolution keeps probing all of them.
+	r.SetProviderDownUntil("kiro", time.Now().Add(time.Minute))
+	if got := r.GetModelProviders("claude-sonnet-4-5"); len(got) != 2 {
+		t.Errorf("providers with all down = %v, want both", got)
+	}
+	models = modelsByID(r.ListModels("openai", ModelListOptions{Availability: true}))
+	if avail := models["claude-sonnet-4-5"]["availability"].(ModelAvailability); avail.Status != AvailabilityUnavailable {
+		t.Errorf("family availability with all down = %+v", avail)
+	}
+
+	r.SetProviderDownUntil("claude", time.Time{})
+	r.SetProviderDownUntil("kiro", time.Now().Add(-time.Second))
+	if got := r.GetModelProviders("claude-sonnet-4-5"); len(got) != 2 {
+		t.Errorf("providers after recovery = %v, want both", got)
+	}
+}
@@ -12,6 +12,8 @@ type registryState struct {
 	clientProviders      map[string]string
 	canonicalIndex       map[string][]ProviderModelMapping
 	modelIDIndex         map[string][]string
+	downProviders        map[string]time.Time         // provider -> down until
+	overrides            map[string]ModelInfoOverride // replaced wholesale, never modified
 	showProviderPrefixes bool
 }
 
@@ -22,6 +24,7 @@ func newRegistryState() *registryState {
 		clientProviders: make(map[string]string),
 		canonicalIndex:  make(map[string][]ProviderModelMapping),
 		modelIDIndex:    make(map[string][]string),
+		downProviders:   make(map[string]time.Time),
 	}
 }
 
@@ -37,6 +40,8 @@ func (s *registryState) clone() *registryState {
 		clientProviders:      make(map[string]string, len(s.clientProviders)),
 		canonicalIndex:       make(map[string][]ProviderModelMapping, len(s.canonicalIndex)),
 		modelIDIndex:         make(map[string][]string, len(s.modelIDIndex)),
+		downProviders:        make(map[string]time.Time, len(s.downProviders)),
+		overrides:            s.overrides,
 		showProviderPrefixes: s.showProviderPrefixes,
 	}
 
@@ -60,6 +65,10 @@ func (s *registryState) clone() *registryState {
 		newState.modelIDIndex[k] = append([]string(nil), v...)
 	}
 
+	for k, v := range s.downProviders {
+		newState.downProviders[k] = v
+	}
+
 	return newState
 }
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"time"
@@ -69,6 +70,9 @@ type HTTPRequestConfig struct {
 	Timeout         time.Duration
 	ContentType     string
 	MaxResponseSize int64
+	// Signer, when set, signs the body as sent on the wire (after compression)
+	// and its headers are applied last, overriding Headers.
+	Signer provider.RequestSigner
 }
 
 // HTTPResult holds the result of an HTTP request.
@@ -98,6 +102,18 @@ func (b *BaseExecutor) DoRequest(ctx context.Context, cfg HTTPRequestConfig) (*H
 	for k, v := range cfg.Headers {
 		httpReq.Header.Set(k, v)
 	}
+	if cfg.Signer != nil {
+		signed, errSign := cfg.Signer.SignRequest(compressed.Data, cfg.Auth)
+		if errSign != nil {
+			return nil, fmt.Errorf("sign request: %w", errSign)
+		}
+		for k, values := range signed {
+			httpReq.Header.Del(k)
+			for _, v := range values {
+				httpReq.Header.Add(k, v)
+			}
+		}
+	}
 
 	httpClient := b.NewHTTPClient(ctx, cfg.Auth, cfg.Timeout)
 	httpResp, err := httpClient.Do(httpReq)
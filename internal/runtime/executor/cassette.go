@@ -0,0 +1,241 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// cassette is one recorded upstream exchange. Bodies are kept as text when
+// they are valid UTF-8 so recordings of JSON and SSE payloads stay readable.
+type cassette struct {
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	StatusCode      int         `json:"status_code"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    string      `json:"response_body,omitempty"`
+	// ResponseBodyBase64 replaces ResponseBody for binary responses.
+	ResponseBodyBase64 string `json:"response_body_base64,omitempty"`
+}
+
+// cassetteTransport records upstream exchanges to cassette files, or serves
+// them back without contacting the provider, according to cfg.
+type cassetteTransport struct {
+	base http.RoundTripper
+	cfg  config.CassetteConfig
+	dir  string
+}
+
+// withCassette wraps base to record or replay upstream exchanges when the
+// cassette mode is configured, or returns base unchanged.
+func withCassette(base http.RoundTripper, cfg *config.Config) http.RoundTripper {
+	if cfg == nil || !cfg.Cassette.Enabled() {
+		return base
+	}
+	dir := cassetteDir(cfg.Cassette)
+	if dir == "" {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cassetteTransport{base: base, cfg: cfg.Cassette, dir: dir}
+}
+
+// cassetteDir resolves the cassette directory, defaulting to "cassettes"
+// under the credentials directory.
+func cassetteDir(cfg config.CassetteConfig) string {
+	if cfg.Dir != "" {
+		return cfg.Dir
+	}
+	if base := config.CredentialsDir(); base != "" {
+		return filepath.Join(base, "cassettes")
+	}
+	return ""
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+	key := cassetteKey(req, body)
+
+	record := t.cfg.Mode == config.CassetteModeRecord
+	if t.cfg.Mode == config.CassetteModeReplay {
+		if resp, ok := t.replay(req, key); ok {
+			return resp, nil
+		}
+		switch t.cfg.MissAction() {
+		case config.CassetteMissError:
+			return nil, fmt.Errorf("cassette: no recording for %s %s", req.Method, redactedURL(req.URL))
+		case config.CassetteMissRecord:
+			record = true
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || !record {
+		return resp, err
+	}
+	entry := &cassette{
+		Method:          req.Method,
+		URL:             redactedURL(req.URL),
+		RequestHeaders:  redactedHeaders(req.Header),
+		RequestBody:     string(body),
+		StatusCode:      resp.StatusCode,
+		ResponseHeaders: resp.Header.Clone(),
+	}
+	resp.Body = &cassetteRecorder{ReadCloser: resp.Body, save: func(data []byte) {
+		if utf8.Valid(data) {
+			entry.ResponseBody = string(data)
+		} else {
+			entry.ResponseBodyBase64 = base64.StdEncoding.EncodeToString(data)
+		}
+		if errSave := t.save(key, entry); errSave != nil {
+			log.Warnf("cassette: %v", errSave)
+		}
+	}}
+	return resp, nil
+}
+
+// replay returns the recorded response for key.
+func (t *cassetteTransport) replay(req *http.Request, key string) (*http.Response, bool) {
+	data, err := os.ReadFile(filepath.Join(t.dir, key+".json"))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warnf("cassette: read %s: %v", key, err)
+		}
+		return nil, false
+	}
+	var entry cassette
+	if err = json.Unmarshal(data, &entry); err != nil {
+		log.Warnf("cassette: decode %s: %v", key, err)
+		return nil, false
+	}
+	body := []byte(entry.ResponseBody)
+	if entry.ResponseBodyBase64 != "" {
+		if body, err = base64.StdEncoding.DecodeString(entry.ResponseBodyBase64); err != nil {
+			log.Warnf("cassette: decode %s body: %v", key, err)
+			return nil, false
+		}
+	}
+	header := entry.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, true
+}
+
+var cassetteWriteMu sync.Mutex
+
+// save writes entry atomically under key.
+func (t *cassetteTransport) save(key string, entry *cassette) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+	cassetteWriteMu.Lock()
+	defer cassetteWriteMu.Unlock()
+	if err = os.MkdirAll(t.dir, 0o700); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	path := filepath.Join(t.dir, key+".json")
+	if err = os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", key, err)
+	}
+	if err = os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("commit %s: %w", key, err)
+	}
+	return nil
+}
+
+// cassetteRecorder passes the response body through and hands the complete
+// body to save once it has been read to EOF. Bodies closed early are dropped,
+// so a cancelled stream never becomes a truncated recording.
+type cassetteRecorder struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	save func([]byte)
+	done bool
+}
+
+func (r *cassetteRecorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.buf.Write(p[:n])
+	if errors.Is(err, io.EOF) && !r.done {
+		r.done = true
+		r.save(r.buf.Bytes())
+	}
+	return n, err
+}
+
+// cassetteKey hashes what identifies an upstream exchange: method, URL
+// without credentials, and body. Headers are left out because they carry
+// tokens that change between runs.
+func cassetteKey(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(redactedURL(req.URL)))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// redactedURL drops the API key query parameter some providers accept.
+func redactedURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	cp := *u
+	query := cp.Query()
+	if query.Has("key") {
+		query.Del("key")
+		cp.RawQuery = query.Encode()
+	}
+	return cp.String()
+}
+
+// redactedHeaders drops credential headers before a request is recorded.
+func redactedHeaders(header http.Header) http.Header {
+	out := header.Clone()
+	for key := range credentialHeaders {
+		out.Del(key)
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"golang.org/x/oauth2"
+)
+
+func postThrough(t *testing.T, rt http.RoundTripper, target, body string) (int, string, error) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data), nil
+}
+
+func TestCassetteRecordThenReplay(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"n\":1}\n\ndata: [DONE]\n\n")
+	}))
+	defer upstream.Close()
+	dir := t.TempDir()
+	target := upstream.URL + "/v1/chat?key=abc"
+
+	rec := withCassette(http.DefaultTransport, &config.Config{Cassette: config.CassetteConfig{Mode: config.CassetteModeRecord, Dir: dir}})
+	if _, _, err := postThrough(t, rec, target, `{"model":"m"}`); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("recorded %d cassettes, want 1", len(files))
+	}
+	saved, _ := os.ReadFile(dir + "/" + files[0].Name())
+	if strings.Contains(string(saved), "secret") || strings.Contains(string(saved), "key=abc") {
+		t.Errorf("cassette leaks credentials: %s", saved)
+	}
+
+	replay := withCassette(http.DefaultTransport, &config.Config{Cassette: config.CassetteConfig{Mode: config.CassetteModeReplay, Dir: dir, OnMiss: config.CassetteMissError}})
+	status, body, err := postThrough(t, replay, upstream.URL+"/v1/chat?key=other", `{"model":"m"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusOK || !strings.Contains(body, "[DONE]") {
+		t.Errorf("replay = %d %q", status, body)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("upstream called %d times, want 1", calls.Load())
+	}
+
+	if _, _, err = postThrough(t, replay, target, `{"model":"other"}`); err == nil {
+		t.Error("replay miss with on-miss error succeeded")
+	}
+
+	live := withCassette(http.DefaultTransport, &config.Config{Cassette: config.CassetteConfig{Mode: config.CassetteModeReplay, Dir: dir}})
+	if _, _, err = postThrough(t, live, target, `{"model":"other"}`); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("replay miss did not fall through to live")
+	}
+	if files, _ = os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("on-miss live recorded a cassette")
+	}
+}
+
+func TestTokenHTTPClientNeverRecords(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"access_token":"fresh-access","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer upstream.Close()
+	dir := t.TempDir()
+	cfg := &config.Config{Cassette: config.CassetteConfig{Mode: config.CassetteModeRecord, Dir: dir}}
+
+	conf := &oauth2.Config{ClientID: "id", ClientSecret: "client-secret", Endpoint: oauth2.Endpoint{TokenURL: upstream.URL + "/token"}}
+	ctx := context.WithValue(t.Context(), oauth2.HTTPClient, NewTokenHTTPClient(t.Context(), cfg, nil, 0))
+	tok, err := conf.TokenSource(ctx, &oauth2.Token{RefreshToken: "refresh-secret"}).Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "fresh-access" {
+		t.Fatalf("access token = %q", tok.AccessToken)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("token refresh recorded %d cassettes, want 0", len(files))
+	}
+}
@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// Auth attributes naming PEM files for a mutual TLS client certificate.
+const (
+	AttrTLSClientCert = "tls_client_cert"
+	AttrTLSClientKey  = "tls_client_key"
+)
+
+// clientCertAttrs returns the client certificate pair configured on auth.
+// Both paths are empty unless both attributes are set.
+func clientCertAttrs(auth *provider.Auth) (certFile, keyFile string) {
+	if auth == nil {
+		return "", ""
+	}
+	certFile = AttrStringValue(auth.Attributes, AttrTLSClientCert)
+	keyFile = AttrStringValue(auth.Attributes, AttrTLSClientKey)
+	if certFile == "" || keyFile == "" {
+		return "", ""
+	}
+	return certFile, keyFile
+}
+
+// clientCertTransport returns a cached transport presenting the given client
+// certificate, routed through proxyURL when it is usable.
+func clientCertTransport(certFile, keyFile, proxyURL string) *http.Transport {
+	key := strings.Join([]string{"mtls", certFile, keyFile, proxyURL}, "\x00")
+	return getCachedTransport(key, func() *http.Transport {
+		t := buildProxyTransport(proxyURL)
+		if t == nil {
+			t = baseTransport()
+			t.DialContext = newDialer().DialContext
+		}
+		source := newClientCertSource(certFile, keyFile)
+		if _, err := source.GetClientCertificate(nil); err != nil {
+			log.Warnf("mTLS: %v; handshakes will fail until the files are fixed", err)
+		}
+		t.TLSClientConfig.GetClientCertificate = source.GetClientCertificate
+		return t
+	})
+}
+
+// clientCertSource loads a certificate pair from disk and reloads it when
+// either file changes, so rotated certificates apply to new connections
+// without a restart.
+type clientCertSource struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+func newClientCertSource(certFile, keyFile string) *clientCertSource {
+	return &clientCertSource{certFile: certFile, keyFile: keyFile}
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate. A failed
+// reload keeps serving the previously loaded certificate.
+func (s *clientCertSource) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	certInfo, errCert := os.Stat(s.certFile)
+	keyInfo, errKey := os.Stat(s.keyFile)
+	if errCert == nil && errKey == nil && s.cert != nil &&
+		certInfo.ModTime().Equal(s.certMod) && keyInfo.ModTime().Equal(s.keyMod) {
+		return s.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		if s.cert != nil {
+			log.Warnf("mTLS: reload %s failed, keeping previous certificate: %v", s.certFile, err)
+			return s.cert, nil
+		}
+		return nil, fmt.Errorf("load client certificate %s: %w", s.certFile, err)
+	}
+	if s.cert != nil {
+		log.Infof("mTLS: reloaded client certificate %s", s.certFile)
+	}
+	s.cert = &cert
+	if errCert == nil && errKey == nil {
+		s.certMod, s.keyMod = certInfo.ModTime(), keyInfo.ModTime()
+	}
+	return s.cert, nil
+}
@@ -0,0 +1,136 @@
+package executor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// writeTestClientCert writes a self-signed certificate with the given common
+// name to dir and returns the cert and key paths.
+func writeTestClientCert(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey failed: %v", err)
+	}
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert failed: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key failed: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewProxyAwareHTTPClient_PresentsClientCertificate(t *testing.T) {
+	t.Cleanup(ClearTransportCache)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "no client cert", http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	certFile, keyFile := writeTestClientCert(t, t.TempDir(), "gateway-client")
+	auth := &provider.Auth{Attributes: map[string]string{
+		AttrTLSClientCert: certFile,
+		AttrTLSClientKey:  keyFile,
+	}}
+	client := NewProxyAwareHTTPClient(context.Background(), nil, auth, 0)
+	rt, ok := client.Transport.(*http.Transport)
+	if !ok || rt == SharedTransport {
+		t.Fatalf("Expected dedicated mTLS transport, got %T", client.Transport)
+	}
+	rt.TLSClientConfig.InsecureSkipVerify = true
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "gateway-client" {
+		t.Errorf("Expected server to see client cert gateway-client, got %q", body)
+	}
+}
+
+func TestClientCertSource_ReloadsOnRotation(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestClientCert(t, dir, "before")
+	source := newClientCertSource(certFile, keyFile)
+
+	first, err := source.GetClientCertificate(nil)
+	if err != nil {
+		t.Fatalf("Initial load failed: %v", err)
+	}
+
+	writeTestClientCert(t, dir, "after")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	os.Chtimes(keyFile, later, later)
+
+	second, err := source.GetClientCertificate(nil)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(second.Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate failed: %v", err)
+	}
+	if first == second || leaf.Subject.CommonName != "after" {
+		t.Errorf("Expected rotated certificate, got CN %q", leaf.Subject.CommonName)
+	}
+
+	// A broken rotation keeps serving the last good certificate.
+	os.WriteFile(certFile, []byte("garbage"), 0o600)
+	broken := time.Now().Add(2 * time.Minute)
+	os.Chtimes(certFile, broken, broken)
+	if third, err := source.GetClientCertificate(nil); err != nil || third != second {
+		t.Errorf("Expected previous certificate after failed reload, got %v (err=%v)", third, err)
+	}
+}
+
+func TestClientCertAttrs_RequiresBothFiles(t *testing.T) {
+	auth := &provider.Auth{Attributes: map[string]string{AttrTLSClientCert: "/etc/certs/client.crt"}}
+	if certFile, keyFile := clientCertAttrs(auth); certFile != "" || keyFile != "" {
+		t.Errorf("Expected half-configured pair to be ignored, got %q/%q", certFile, keyFile)
+	}
+}
@@ -3,6 +3,7 @@ package executor
 import (
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,10 +16,26 @@ const (
 	cleanupInterval = 5 * time.Minute
 )
 
-// cachedTransport wraps a transport with usage tracking for LRU eviction
+// cachedTransport wraps a transport with usage tracking for LRU eviction.
+// A nil transport records a proxy URL that failed to build, so it is not
+// re-parsed and re-logged on every request.
 type cachedTransport struct {
 	transport *http.Transport
-	lastUsed  time.Time
+	lastUsed  atomic.Int64 // unix nanoseconds
+}
+
+func (c *cachedTransport) touch() {
+	c.lastUsed.Store(time.Now().UnixNano())
+}
+
+func (c *cachedTransport) idleSince() time.Time {
+	return time.Unix(0, c.lastUsed.Load())
+}
+
+func (c *cachedTransport) close() {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
 }
 
 // httpClientPool pools http.Client instances keyed by transport.
@@ -57,10 +74,10 @@ func ReleaseHTTPClient(c *http.Client) {
 	httpClientPool.Put(c)
 }
 
-// getCachedTransport returns a cached transport for the given proxy URL,
-// creating one if it doesn't exist. Returns nil for empty proxyURL.
-func getCachedTransport(proxyURL string) *http.Transport {
-	if proxyURL == "" {
+// getCachedTransport returns the transport cached under key, creating it with
+// build if it doesn't exist. Returns nil for an empty key or when build fails.
+func getCachedTransport(key string, build func() *http.Transport) *http.Transport {
+	if key == "" {
 		return nil
 	}
 
@@ -68,8 +85,8 @@ func getCachedTransport(proxyURL string) *http.Transport {
 
 	// Fast path: read lock
 	transportCacheMu.RLock()
-	if cached, ok := transportCache[proxyURL]; ok {
-		cached.lastUsed = time.Now()
+	if cached, ok := transportCache[key]; ok {
+		cached.touch()
 		transportCacheMu.RUnlock()
 		return cached.transport
 	}
@@ -80,8 +97,8 @@ func getCachedTransport(proxyURL string) *http.Transport {
 	defer transportCacheMu.Unlock()
 
 	// Double-check after acquiring write lock
-	if cached, ok := transportCache[proxyURL]; ok {
-		cached.lastUsed = time.Now()
+	if cached, ok := transportCache[key]; ok {
+		cached.touch()
 		return cached.transport
 	}
 
@@ -91,24 +108,20 @@ func getCachedTransport(proxyURL string) *http.Transport {
 	}
 
 	// Build and cache the transport
-	t := buildProxyTransport(proxyURL)
-	if t != nil {
-		transportCache[proxyURL] = &cachedTransport{
-			transport: t,
-			lastUsed:  time.Now(),
-		}
-	}
-	return t
+	cached := &cachedTransport{transport: build()}
+	cached.touch()
+	transportCache[key] = cached
+	return cached.transport
 }
 
-// ClearTransportCache clears all cached proxy transports.
+// ClearTransportCache clears all cached proxy and client-certificate transports.
 // Useful for testing or when proxy configuration changes.
 func ClearTransportCache() {
 	transportCacheMu.Lock()
 	defer transportCacheMu.Unlock()
 
 	for _, cached := range transportCache {
-		cached.transport.CloseIdleConnections()
+		cached.close()
 	}
 	transportCache = make(map[string]*cachedTransport)
 }
@@ -129,8 +142,8 @@ func cleanupExpiredTransports() {
 
 	now := time.Now()
 	for key, cached := range transportCache {
-		if now.Sub(cached.lastUsed) > transportCacheExpiry {
-			cached.transport.CloseIdleConnections()
+		if now.Sub(cached.idleSince()) > transportCacheExpiry {
+			cached.close()
 			delete(transportCache, key)
 		}
 	}
@@ -141,15 +154,15 @@ func evictLRUTransport() {
 	var oldestTime time.Time
 
 	for key, cached := range transportCache {
-		if oldestKey == "" || cached.lastUsed.Before(oldestTime) {
+		if lastUsed := cached.idleSince(); oldestKey == "" || lastUsed.Before(oldestTime) {
 			oldestKey = key
-			oldestTime = cached.lastUsed
+			oldestTime = lastUsed
 		}
 	}
 
 	if oldestKey != "" {
 		if cached, ok := transportCache[oldestKey]; ok {
-			cached.transport.CloseIdleConnections()
+			cached.close()
 		}
 		delete(transportCache, oldestKey)
 	}
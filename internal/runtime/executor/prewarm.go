@@ -3,47 +3,98 @@ package executor
 import (
 	"context"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+const (
+	googleOAuthEndpoint = "https://oauth2.googleapis.com"
+
+	prewarmTimeout     = 5 * time.Second
+	prewarmMaxAttempts = 2
 )
 
-var antigravityEndpoints = []string{
-	"https://cloudcode-pa.googleapis.com",
-	"https://oauth2.googleapis.com",
+// prewarmEndpoints maps provider identifiers to the upstream hosts they hit on
+// the request path. Warming them at startup moves DNS and TLS handshakes off the
+// first user request.
+var prewarmEndpoints = map[string][]string{
+	"antigravity":         {AntigravityBaseURLProd, googleOAuthEndpoint},
+	"gemini-cli":          {AntigravityBaseURLProd, googleOAuthEndpoint},
+	"gemini":              {GeminiDefaultBaseURL},
+	"vertex":              {"https://aiplatform.googleapis.com", googleOAuthEndpoint},
+	"claude":              {ClaudeDefaultBaseURL},
+	"codex":               {CodexDefaultBaseURL},
+	"qwen":                {QwenDefaultBaseURL},
+	"iflow":               {"https://apis.iflow.cn"},
+	"cline":               {ClineDefaultBaseURL},
+	GitHubCopilotAuthType: {GitHubCopilotDefaultBaseURL},
+	"kiro":                {"https://codewhisperer.us-east-1.amazonaws.com"},
 }
 
-func PrewarmAntigravityConnections(ctx context.Context) {
-	var wg sync.WaitGroup
-	timeout := 5 * time.Second
+// PrewarmEndpoints returns the deduplicated endpoints registered for providers.
+// Unknown providers are ignored.
+func PrewarmEndpoints(providers []string) []string {
+	seen := make(map[string]struct{})
+	var endpoints []string
+	for _, p := range providers {
+		for _, endpoint := range prewarmEndpoints[strings.ToLower(strings.TrimSpace(p))] {
+			if _, ok := seen[endpoint]; ok {
+				continue
+			}
+			seen[endpoint] = struct{}{}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// PrewarmProviders opens connections to the endpoints of the given providers
+// through SharedTransport. It is best-effort: failures are logged and never
+// returned, and the call blocks until every endpoint finished or ctx ends.
+func PrewarmProviders(ctx context.Context, providers []string) {
+	endpoints := PrewarmEndpoints(providers)
+	if len(endpoints) == 0 {
+		return
+	}
 
-	for _, endpoint := range antigravityEndpoints {
+	var wg sync.WaitGroup
+	for _, endpoint := range endpoints {
 		wg.Add(1)
 		go func(url string) {
 			defer wg.Done()
-			prewarmHTTPWithRetry(ctx, url, timeout, 2)
+			start := time.Now()
+			if prewarmHTTPWithRetry(ctx, url, prewarmTimeout, prewarmMaxAttempts) {
+				log.Debugf("prewarm: %s ready in %s", url, time.Since(start).Round(time.Millisecond))
+			} else {
+				log.Warnf("prewarm: %s unreachable after %d attempts", url, prewarmMaxAttempts)
+			}
 		}(endpoint)
 	}
 
 	wg.Wait()
 }
 
-func prewarmHTTPWithRetry(ctx context.Context, baseURL string, timeout time.Duration, maxAttempts int) {
+func prewarmHTTPWithRetry(ctx context.Context, baseURL string, timeout time.Duration, maxAttempts int) bool {
 	backoff := 100 * time.Millisecond
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		if attempt > 0 {
 			select {
 			case <-ctx.Done():
-				return
+				return false
 			case <-time.After(backoff):
 				backoff *= 2
 			}
 		}
 
 		if prewarmHTTP(ctx, baseURL, timeout) {
-			return
+			return true
 		}
 	}
+	return false
 }
 
 func prewarmHTTP(ctx context.Context, baseURL string, timeout time.Duration) bool {
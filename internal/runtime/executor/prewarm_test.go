@@ -0,0 +1,22 @@
+package executor
+
+import "testing"
+
+func TestPrewarmEndpoints_DedupesSharedHosts(t *testing.T) {
+	got := PrewarmEndpoints([]string{"antigravity", "gemini-cli", "Claude", "unknown"})
+	want := []string{AntigravityBaseURLProd, googleOAuthEndpoint, ClaudeDefaultBaseURL}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Endpoint %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestPrewarmEndpoints_Empty(t *testing.T) {
+	if got := PrewarmEndpoints(nil); len(got) != 0 {
+		t.Errorf("Expected no endpoints, got %v", got)
+	}
+}
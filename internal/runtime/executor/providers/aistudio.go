@@ -43,7 +43,7 @@ func (e *AIStudioExecutor) Execute(ctx context.Context, auth *provider.Auth, req
 	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
 	defer reporter.TrackFailure(ctx, &err)
 
-	_, body, err := e.translateRequest(req, opts, false)
+	_, body, err := e.translateRequest(ctx, req, opts, false)
 	if err != nil {
 		return resp, err
 	}
@@ -69,8 +69,9 @@ func (e *AIStudioExecutor) Execute(ctx context.Context, auth *provider.Auth, req
 	}
 	reporter.Publish(ctx, executor.ExtractUsageFromGeminiResponse(wsResp.Body))
 
+	stream.RememberThoughtSignatures(ctx, wsResp.Body)
 	fromFormat := provider.FromString("gemini")
-	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, opts.SourceFormat, wsResp.Body, req.Model)
+	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, opts.SourceFormat, opts.OriginalRequest, wsResp.Body, req.Model)
 	if err != nil {
 		return resp, err
 	}
@@ -86,7 +87,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
 	defer reporter.TrackFailure(ctx, &err)
 
-	_, body, err := e.translateRequest(req, opts, true)
+	_, body, err := e.translateRequest(ctx, req, opts, true)
 	if err != nil {
 		return nil, err
 	}
@@ -152,6 +153,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 		streamCtx := stream.NewStreamContext()
 		messageID := "chatcmpl-" + req.Model
 		translator := stream.NewStreamTranslator(e.Cfg, opts.SourceFormat, opts.SourceFormat.String(), req.Model, messageID, streamCtx)
+		translator.SetThoughtSignatures(stream.ThoughtSignaturesFromContext(ctx))
 		processor := &aistudioStreamProcessor{
 			translator: translator,
 		}
@@ -204,7 +206,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 				return false
 			case wsrelay.MessageTypeHTTPResp:
 				fromFormat := provider.FromString("gemini")
-				translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, opts.SourceFormat, event.Payload, req.Model)
+				translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, opts.SourceFormat, opts.OriginalRequest, event.Payload, req.Model)
 				if err != nil {
 					pipeline.SendError(err)
 					return false
@@ -242,8 +244,10 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 	return stream.ConvertPipelineToStreamChunk(ctx, pipeline.Output()), nil
 }
 
-func (e *AIStudioExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
-	_, body, err := e.translateRequest(req, opts, false)
+func (e *AIStudioExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
+	_, body, err := e.translateRequest(ctx, req, opts, false)
 	if err != nil {
 		return provider.Response{}, err
 	}
@@ -315,14 +319,18 @@ func (p *aistudioStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage,
 	return result.Chunks, result.Usage, nil
 }
 
+func (p *aistudioStreamProcessor) StreamTranslator() *stream.StreamTranslator {
+	return p.translator
+}
+
 func (p *aistudioStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush()
 }
 
-func (e *AIStudioExecutor) translateRequest(req provider.Request, opts provider.Options, isStreaming bool) ([]byte, translatedPayload, error) {
+func (e *AIStudioExecutor) translateRequest(ctx context.Context, req provider.Request, opts provider.Options, isStreaming bool) ([]byte, translatedPayload, error) {
 	from := opts.SourceFormat
 	formatGemini := provider.FromString("gemini")
-	payload, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
+	payload, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
 	if err != nil {
 		return nil, translatedPayload{}, fmt.Errorf("translate request: %w", err)
 	}
@@ -80,7 +80,7 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *provider.Auth,
 
 	from := opts.SourceFormat
 
-	geminiPayload, errGemini := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	geminiPayload, errGemini := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if errGemini != nil {
 		return resp, fmt.Errorf("failed to translate request: %w", errGemini)
 	}
@@ -158,8 +158,9 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *provider.Auth,
 
 			// Unwrap envelope if present (Gemini CLI format)
 			cleanData := cloudcode.ResponseUnwrap(bodyBytes)
+			stream.RememberThoughtSignatures(ctx, cleanData)
 
-			translatedResp, errTranslateResp := stream.TranslateResponseNonStream(e.Cfg, provider.FormatGemini, from, cleanData, req.Model)
+			translatedResp, errTranslateResp := stream.TranslateResponseNonStream(e.Cfg, provider.FormatGemini, from, opts.OriginalRequest, cleanData, req.Model)
 			if errTranslateResp != nil {
 				return resp, fmt.Errorf("failed to translate response: %w", errTranslateResp)
 			}
@@ -221,7 +222,7 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *provider.
 
 	from := opts.SourceFormat
 
-	translation, errTranslate := stream.TranslateToGeminiWithTokens(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	translation, errTranslate := stream.TranslateToGeminiWithTokens(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if errTranslate != nil {
 		return nil, fmt.Errorf("failed to translate request: %w", errTranslate)
 	}
@@ -360,7 +361,9 @@ func (e *AntigravityExecutor) Refresh(ctx context.Context, auth *provider.Auth)
 	return updated, nil
 }
 
-func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	token, errToken := e.ensureAccessToken(ctx, auth)
 	if errToken != nil {
 		if errors.Is(errToken, provider.ErrTokenNotReady) {
@@ -375,7 +378,7 @@ func (e *AntigravityExecutor) CountTokens(ctx context.Context, auth *provider.Au
 	}
 
 	from := opts.SourceFormat
-	geminiPayload, errGemini := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	geminiPayload, errGemini := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if errGemini != nil {
 		return provider.Response{}, fmt.Errorf("failed to translate request: %w", errGemini)
 	}
@@ -54,6 +54,10 @@ func (p *claudeStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, e
 	return result.Chunks, result.Usage, nil
 }
 
+func (p *claudeStreamProcessor) StreamTranslator() *stream.StreamTranslator {
+	return p.translator
+}
+
 func (p *claudeStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush()
 }
@@ -91,7 +95,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 	defer reporter.TrackFailure(ctx, &err)
 	from := opts.SourceFormat
 	isStreaming := from.String() != "claude"
-	body, err := stream.TranslateToClaude(e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
+	body, err := stream.TranslateToClaude(ctx, e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
 	if err != nil {
 		return resp, err
 	}
@@ -111,6 +115,10 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 
 	var extraBetas []string
 	extraBetas, body = extractAndRemoveBetas(body)
+	betas := claudeModelBetas(req.Model, modelForUpstream, body, extraBetas, func() int64 {
+		n, _ := estimateClaudeTokens(req, opts)
+		return n
+	})
 
 	ub := executor.GetURLBuilder()
 	defer ub.Release()
@@ -122,7 +130,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 	if err != nil {
 		return resp, err
 	}
-	applyClaudeHeaders(httpReq, auth, apiKey, false, extraBetas)
+	applyClaudeHeaders(httpReq, auth, apiKey, false, betas)
 
 	httpClient := e.NewHTTPClient(ctx, auth, 0)
 	httpResp, err := httpClient.Do(httpReq)
@@ -171,7 +179,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 	}
 
 	claudeFrom := provider.FromString("claude")
-	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, claudeFrom, from, data, req.Model)
+	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, claudeFrom, from, opts.OriginalRequest, data, req.Model)
 	if err != nil {
 		return resp, err
 	}
@@ -192,7 +200,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth)
 	defer reporter.TrackFailure(ctx, &err)
 	from := opts.SourceFormat
-	body, err := stream.TranslateToClaude(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	body, err := stream.TranslateToClaude(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -214,6 +222,10 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 
 	var extraBetas []string
 	extraBetas, body = extractAndRemoveBetas(body)
+	betas := claudeModelBetas(req.Model, modelForUpstream, body, extraBetas, func() int64 {
+		n, _ := estimateClaudeTokens(req, opts)
+		return n
+	})
 
 	ub := executor.GetURLBuilder()
 	defer ub.Release()
@@ -225,7 +237,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	if err != nil {
 		return nil, err
 	}
-	applyClaudeHeaders(httpReq, auth, apiKey, true, extraBetas)
+	applyClaudeHeaders(httpReq, auth, apiKey, true, betas)
 
 	httpClient := e.NewHTTPClient(ctx, auth, 0)
 	httpResp, err := httpClient.Do(httpReq)
@@ -270,7 +282,62 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	}), nil
 }
 
-func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+// CountTokens asks Anthropic's count_tokens endpoint for an exact count. When
+// the endpoint is unreachable, unsupported by a custom base URL, rate limited
+// or failing, it falls back to a local tiktoken estimate. Request and auth
+// errors (400/401/403) are returned so they reach the caller and auth state.
+// Response.Metadata[provider.TokenCountSourceKey] records which path was used.
+func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
+	data, err := e.countTokensUpstream(ctx, auth, req, opts)
+	if err == nil {
+		return provider.Response{
+			Payload:  data,
+			Metadata: map[string]any{provider.TokenCountSourceKey: provider.TokenCountExact},
+		}, nil
+	}
+	if !shouldEstimateClaudeTokens(ctx, err) {
+		return provider.Response{}, err
+	}
+	count, errEstimate := estimateClaudeTokens(req, opts)
+	if errEstimate != nil {
+		return provider.Response{}, err
+	}
+	log.Debugf("claude count_tokens failed, using estimate: %v", err)
+	return provider.Response{
+		Payload:  []byte(fmt.Sprintf(`{"input_tokens":%d}`, count)),
+		Metadata: map[string]any{provider.TokenCountSourceKey: provider.TokenCountEstimated},
+	}, nil
+}
+
+// shouldEstimateClaudeTokens reports whether a count_tokens failure may be
+// replaced by a local estimate.
+func shouldEstimateClaudeTokens(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var se provider.StatusCodeError
+	if !errors.As(err, &se) || se == nil {
+		return true
+	}
+	switch se.StatusCode() {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return false
+	}
+	return true
+}
+
+// estimateClaudeTokens counts the request's input tokens with the local tokenizer.
+func estimateClaudeTokens(req provider.Request, opts provider.Options) (int64, error) {
+	irReq, err := stream.ConvertRequestToIR(opts.SourceFormat, req.Model, req.Payload, req.Metadata)
+	if err != nil {
+		return 0, err
+	}
+	return util.CountTokensFromIR(req.Model, irReq), nil
+}
+
+func (e *ClaudeExecutor) countTokensUpstream(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) ([]byte, error) {
 	apiKey, baseURL := claudeCreds(auth)
 
 	if baseURL == "" {
@@ -279,9 +346,9 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 
 	from := opts.SourceFormat
 	isStreaming := from.String() != "claude"
-	body, err := stream.TranslateToClaude(e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
+	body, err := stream.TranslateToClaude(ctx, e.Cfg, from, req.Model, req.Payload, isStreaming, req.Metadata)
 	if err != nil {
-		return provider.Response{}, err
+		return nil, err
 	}
 	modelForUpstream := req.Model
 	if modelOverride := e.resolveUpstreamModel(req.Model, auth); modelOverride != "" {
@@ -295,6 +362,10 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 
 	var extraBetas []string
 	extraBetas, body = extractAndRemoveBetas(body)
+	betas := claudeModelBetas(req.Model, modelForUpstream, body, extraBetas, func() int64 {
+		n, _ := estimateClaudeTokens(req, opts)
+		return n
+	})
 
 	ub := executor.GetURLBuilder()
 	defer ub.Release()
@@ -304,31 +375,31 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 	url := ub.String()
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		return provider.Response{}, err
+		return nil, err
 	}
-	applyClaudeHeaders(httpReq, auth, apiKey, false, extraBetas)
+	applyClaudeHeaders(httpReq, auth, apiKey, false, betas)
 
 	httpClient := e.NewHTTPClient(ctx, auth, 0)
 	resp, err := httpClient.Do(httpReq)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
-			return provider.Response{}, executor.NewTimeoutError("request timed out")
+			return nil, executor.NewTimeoutError("request timed out")
 		}
-		return provider.Response{}, err
+		return nil, err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
 		if errClose := resp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
 		}
-		return provider.Response{}, executor.NewStatusError(resp.StatusCode, string(b), nil)
+		return nil, executor.NewStatusError(resp.StatusCode, string(b), nil)
 	}
 	decodedBody, err := executor.DecodeResponseBody(resp.Body, resp.Header.Get("Content-Encoding"))
 	if err != nil {
 		if errClose := resp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
 		}
-		return provider.Response{}, err
+		return nil, err
 	}
 	defer func() {
 		if errClose := decodedBody.Close(); errClose != nil {
@@ -337,9 +408,9 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 	}()
 	data, err := io.ReadAll(decodedBody)
 	if err != nil {
-		return provider.Response{}, err
+		return nil, err
 	}
-	return provider.Response{Payload: data}, nil
+	return data, nil
 }
 
 func (e *ClaudeExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
@@ -485,7 +556,9 @@ func (e *ClaudeExecutor) resolveClaudeConfig(auth *provider.Auth) *config.Provid
 	return nil
 }
 
-func applyClaudeHeaders(r *http.Request, auth *provider.Auth, apiKey string, stream bool, extraBetas []string) {
+// applyClaudeHeaders sets the request headers for the Claude API. betas are
+// appended to the base anthropic-beta flags or to those the client sent.
+func applyClaudeHeaders(r *http.Request, auth *provider.Auth, apiKey string, stream bool, betas []string) {
 	r.Header.Set("Authorization", "Bearer "+apiKey)
 	executor.SetCommonHeaders(r, "application/json")
 
@@ -494,7 +567,7 @@ func applyClaudeHeaders(r *http.Request, auth *provider.Auth, apiKey string, str
 		ginHeaders = ginCtx.Request.Header
 	}
 
-	baseBetas := "claude-code-20250219,oauth-2025-04-20,interleaved-thinking-2025-05-14,fine-grained-tool-streaming-2025-05-14"
+	baseBetas := claudeBaseBetas
 	if val := strings.TrimSpace(ginHeaders.Get("Anthropic-Beta")); val != "" {
 		baseBetas = val
 		if !strings.Contains(val, "oauth") {
@@ -502,12 +575,12 @@ func applyClaudeHeaders(r *http.Request, auth *provider.Auth, apiKey string, str
 		}
 	}
 
-	if len(extraBetas) > 0 {
+	if len(betas) > 0 {
 		existingSet := make(map[string]bool)
 		for _, b := range strings.Split(baseBetas, ",") {
 			existingSet[strings.TrimSpace(b)] = true
 		}
-		for _, beta := range extraBetas {
+		for _, beta := range betas {
 			beta = strings.TrimSpace(beta)
 			if beta != "" && !existingSet[beta] {
 				baseBetas += "," + beta
@@ -0,0 +1,59 @@
+package providers
+
+import (
+	"slices"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+// claudeBaseBetas are sent with every Claude request unless the client sets
+// its own anthropic-beta header.
+const claudeBaseBetas = "claude-code-20250219,oauth-2025-04-20"
+
+// defaultClaudeModelBetas are sent for models without beta metadata.
+var defaultClaudeModelBetas = []string{"interleaved-thinking-2025-05-14", "fine-grained-tool-streaming-2025-05-14"}
+
+// claudeModelBetas returns the anthropic-beta flags that model needs for
+// body, the translated Claude request, followed by the betas the request
+// asked for itself. countTokens estimates the prompt size; it is only called
+// when the model has long context betas and the body is large enough to
+// exceed the standard context window.
+func claudeModelBetas(model, upstreamModel string, body []byte, requested []string, countTokens func() int64) []string {
+	info := registry.GetGlobalRegistry().ClaudeModelInfo(model)
+	if info == nil && upstreamModel != model {
+		info = registry.GetGlobalRegistry().ClaudeModelInfo(upstreamModel)
+	}
+
+	var betas []string
+	if info == nil || info.AnthropicBetas == nil {
+		betas = slices.Clone(defaultClaudeModelBetas)
+	} else {
+		betas = info.AnthropicBetas.For(claudeRequestFeatures(info, body, countTokens))
+	}
+	for _, beta := range requested {
+		if !slices.Contains(betas, beta) {
+			betas = append(betas, beta)
+		}
+	}
+	return betas
+}
+
+// claudeRequestFeatures reports which beta-gated features body uses.
+func claudeRequestFeatures(info *registry.ModelInfo, body []byte, countTokens func() int64) registry.AnthropicFeatures {
+	var f registry.AnthropicFeatures
+	switch gjson.GetBytes(body, "thinking.type").String() {
+	case "enabled", "adaptive":
+		f.Thinking = true
+	}
+	f.Tools = len(gjson.GetBytes(body, "tools").Array()) > 0
+	if limit := info.OutputLimit(); limit > 0 {
+		f.ExtendedOutput = gjson.GetBytes(body, "max_tokens").Int() > int64(limit)
+	}
+	// Every token takes at least one byte, so smaller bodies cannot exceed
+	// the window and skip the tokenizer.
+	if limit := info.ContextWindow(); limit > 0 && len(info.AnthropicBetas.LongContext) > 0 && len(body) > limit && countTokens != nil {
+		f.LongContext = countTokens() > int64(limit)
+	}
+	return f
+}
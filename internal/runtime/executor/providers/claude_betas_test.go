@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestClaudeModelBetas(t *testing.T) {
+	longPrompt := `{"messages":[{"role":"user","content":"` + strings.Repeat("x", 250000) + `"}]}`
+
+	tests := []struct {
+		name      string
+		model     string
+		body      string
+		requested []string
+		tokens    int64
+		want      []string
+	}{
+		{name: "plain request needs no betas", model: "claude-sonnet-4-5", body: `{"max_tokens":1024}`},
+		{
+			name:  "thinking and tools",
+			model: "claude-opus-4-5-20251101",
+			body:  `{"max_tokens":1024,"thinking":{"type":"enabled","budget_tokens":2048},"tools":[{"name":"get_weather"}]}`,
+			want:  []string{"interleaved-thinking-2025-05-14", "fine-grained-tool-streaming-2025-05-14"},
+		},
+		{
+			name:   "long context on sonnet",
+			model:  "claude-sonnet-4-5-20250929",
+			body:   longPrompt,
+			tokens: 250000,
+			want:   []string{"context-1m-2025-08-07"},
+		},
+		{name: "long context not offered on opus", model: "claude-opus-4-5-20251101", body: longPrompt, tokens: 250000},
+		{name: "large body within the window", model: "claude-sonnet-4-5", body: longPrompt, tokens: 60000},
+		{
+			name:  "extended output on 3.7 sonnet",
+			model: "claude-3-7-sonnet-20250219",
+			body:  `{"max_tokens":100000,"tools":[{"name":"t"}]}`,
+			want:  []string{"fine-grained-tool-streaming-2025-05-14", "output-128k-2025-02-19"},
+		},
+		{
+			name:  "unknown model keeps the defaults",
+			model: "claude-custom-proxy-model",
+			body:  `{"max_tokens":1024}`,
+			want:  []string{"interleaved-thinking-2025-05-14", "fine-grained-tool-streaming-2025-05-14"},
+		},
+		{
+			name:      "requested betas are appended once",
+			model:     "claude-sonnet-4-5",
+			body:      `{"thinking":{"type":"enabled","budget_tokens":2048}}`,
+			requested: []string{"interleaved-thinking-2025-05-14", "token-efficient-tools-2025-02-19"},
+			want:      []string{"interleaved-thinking-2025-05-14", "token-efficient-tools-2025-02-19"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counted := false
+			got := claudeModelBetas(tt.model, tt.model, []byte(tt.body), tt.requested, func() int64 {
+				counted = true
+				return tt.tokens
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected betas %v, got %v", tt.want, got)
+			}
+			if counted && len(tt.body) < 200000 {
+				t.Error("Expected small bodies to skip token counting")
+			}
+		})
+	}
+}
+
+func TestClaudeModelBetas_ConfigOverride(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.SetModelInfoOverrides(map[string]registry.ModelInfoOverride{
+		"claude-sonnet-4-5": {AnthropicBetas: &registry.AnthropicBetas{
+			Base:     []string{"context-management-2025-06-27"},
+			Thinking: []string{"interleaved-thinking-2025-05-14"},
+		}},
+	})
+	t.Cleanup(func() { reg.SetModelInfoOverrides(nil) })
+
+	got := claudeModelBetas("claude-sonnet-4-5", "claude-sonnet-4-5", []byte(`{"tools":[{"name":"t"}]}`), nil, nil)
+	if want := []string{"context-management-2025-06-27"}; !slices.Equal(got, want) {
+		t.Errorf("Expected configured betas %v, got %v", want, got)
+	}
+}
+
+func TestClaudeExecutor_NegotiatesBetaHeader(t *testing.T) {
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Anthropic-Beta")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewClaudeExecutor(&config.Config{})
+	auth := &provider.Auth{ID: "claude-test", Provider: "claude", Attributes: map[string]string{
+		"api_key":  "sk-ant-test",
+		"base_url": srv.URL,
+	}}
+	payload := []byte(`{"model":"claude-sonnet-4-5","max_tokens":64,"tools":[{"name":"t","input_schema":{"type":"object"}}],"messages":[{"role":"user","content":"hi"}]}`)
+	_, err := exec.Execute(context.Background(), auth,
+		provider.Request{Model: "claude-sonnet-4-5", Payload: payload},
+		provider.Options{SourceFormat: provider.FormatClaude})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	got := strings.Split(header, ",")
+	for _, want := range []string{"claude-code-20250219", "oauth-2025-04-20", "fine-grained-tool-streaming-2025-05-14"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("Expected %s in anthropic-beta, got %q", want, header)
+		}
+	}
+	if strings.Contains(header, "interleaved-thinking") {
+		t.Errorf("Expected no thinking beta without thinking, got %q", header)
+	}
+}
@@ -0,0 +1,71 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestClaudeExecutor_CountTokensSource(t *testing.T) {
+	payload := []byte(`{"model":"claude-sonnet-4-5","max_tokens":64,"messages":[{"role":"user","content":"Count the tokens in this sentence, please."}]}`)
+
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantSource string
+		wantErr    bool
+	}{
+		{name: "exact from upstream", status: http.StatusOK, body: `{"input_tokens":42}`, wantSource: provider.TokenCountExact},
+		{name: "endpoint missing falls back", status: http.StatusNotFound, body: `not found`, wantSource: provider.TokenCountEstimated},
+		{name: "upstream error falls back", status: http.StatusBadGateway, body: `bad gateway`, wantSource: provider.TokenCountEstimated},
+		{name: "auth error surfaces", status: http.StatusUnauthorized, body: `{"error":"invalid x-api-key"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/messages/count_tokens" {
+					t.Errorf("Expected count_tokens path, got %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			exec := NewClaudeExecutor(&config.Config{})
+			auth := &provider.Auth{ID: "claude-test", Provider: "claude", Attributes: map[string]string{
+				"api_key":  "sk-ant-test",
+				"base_url": srv.URL,
+			}}
+			resp, err := exec.CountTokens(context.Background(), auth,
+				provider.Request{Model: "claude-sonnet-4-5", Payload: payload},
+				provider.Options{SourceFormat: provider.FormatClaude})
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error to surface")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CountTokens failed: %v", err)
+			}
+			if got := resp.Metadata[provider.TokenCountSourceKey]; got != tt.wantSource {
+				t.Errorf("Expected source %q, got %v", tt.wantSource, got)
+			}
+			count := gjson.GetBytes(resp.Payload, "input_tokens").Int()
+			if tt.wantSource == provider.TokenCountExact && count != 42 {
+				t.Errorf("Expected upstream count 42, got %d", count)
+			}
+			if count <= 0 {
+				t.Errorf("Expected positive input_tokens, got %s", resp.Payload)
+			}
+		})
+	}
+}
@@ -84,7 +84,7 @@ func (e *ClineExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 	reporter.Publish(ctx, executor.ExtractUsageFromOpenAIResponse(data))
 
 	fromOpenAI := provider.FromString("openai")
-	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, data, req.Model)
+	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, opts.OriginalRequest, data, req.Model)
 	if err != nil {
 		return resp, err
 	}
@@ -226,7 +226,9 @@ func shouldSkipEmptyContentChunk(payload []byte) bool {
 	return true
 }
 
-func (e *ClineExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *ClineExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	return executor.CountTokensForOpenAIProvider(ctx, e.Cfg, "cline executor", opts.SourceFormat, req.Model, req.Payload, nil)
 }
 
@@ -105,7 +105,7 @@ func (e *CodexExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 		}
 
 		fromFormat := provider.FromString("codex")
-		translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, from, line, req.Model)
+		translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, from, opts.OriginalRequest, line, req.Model)
 		if err != nil {
 			return resp, err
 		}
@@ -199,11 +199,17 @@ func (p *codexStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, er
 	return result.Chunks, result.Usage, nil
 }
 
+func (p *codexStreamProcessor) StreamTranslator() *stream.StreamTranslator {
+	return p.translator
+}
+
 func (p *codexStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush()
 }
 
-func (e *CodexExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *CodexExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	from := opts.SourceFormat
 	body, err := stream.TranslateToCodex(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
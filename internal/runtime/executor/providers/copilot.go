@@ -89,7 +89,7 @@ func (e *CopilotExecutor) Execute(ctx context.Context, auth *provider.Auth, req
 	}
 
 	fromOpenAI := provider.FromString("openai")
-	translatedResp, errTranslate := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, data, req.Model)
+	translatedResp, errTranslate := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, opts.OriginalRequest, data, req.Model)
 	if errTranslate != nil {
 		return resp, errTranslate
 	}
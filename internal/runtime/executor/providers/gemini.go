@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -19,10 +21,13 @@ import (
 	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
 	"github.com/nghyane/llm-mux/internal/sseutil"
 	"github.com/nghyane/llm-mux/internal/streamutil"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/preprocess"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -56,6 +61,10 @@ func (p *geminiStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, e
 	return result.Chunks, result.Usage, nil
 }
 
+func (p *geminiStreamProcessor) StreamTranslator() *stream.StreamTranslator {
+	return p.translator
+}
+
 func (p *geminiStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush()
 }
@@ -71,7 +80,7 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	body, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return resp, fmt.Errorf("translate request: %w", err)
 	}
@@ -87,6 +96,12 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 			action = "countTokens"
 		}
 	}
+	// With non-stream-buffering "stream" the response is assembled from an
+	// upstream stream rather than read as one JSON body.
+	collect := action == "generateContent" && opts.Alt == "" && e.Cfg != nil && e.Cfg.NonStreamBuffering.Streamed()
+	if collect {
+		action = "streamGenerateContent"
+	}
 	baseURL := resolveGeminiBaseURL(auth)
 	ub := executor.GetURLBuilder()
 	defer ub.Release()
@@ -98,7 +113,9 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 	ub.WriteString(req.Model)
 	ub.WriteString(":")
 	ub.WriteString(action)
-	if opts.Alt != "" && action != "countTokens" {
+	if collect {
+		ub.WriteString("?alt=sse")
+	} else if opts.Alt != "" && action != "countTokens" {
 		ub.WriteString("?$alt=")
 		ub.WriteString(opts.Alt)
 	}
@@ -135,14 +152,23 @@ func (e *GeminiExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 		result := executor.HandleHTTPError(httpResp, "gemini executor")
 		return resp, result.Error
 	}
+	fromFormat := provider.FromString("gemini")
+	if collect {
+		translatedResp, usage, err := stream.CollectResponseStream(ctx, e.Cfg, fromFormat, from, opts.OriginalRequest, httpResp.Body, req.Model)
+		if err != nil {
+			return resp, err
+		}
+		reporter.Publish(ctx, usage)
+		return provider.Response{Payload: translatedResp}, nil
+	}
 	data, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		return resp, err
 	}
 	reporter.Publish(ctx, executor.ExtractUsageFromGeminiResponse(data))
 
-	fromFormat := provider.FromString("gemini")
-	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, from, data, req.Model)
+	stream.RememberThoughtSignatures(ctx, data)
+	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, from, opts.OriginalRequest, data, req.Model)
 	if err != nil {
 		return resp, err
 	}
@@ -162,7 +188,7 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 
 	from := opts.SourceFormat
 
-	translation, err := stream.TranslateToGeminiWithTokens(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	translation, err := stream.TranslateToGeminiWithTokens(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if err != nil {
 		return nil, fmt.Errorf("translate request: %w", err)
 	}
@@ -248,6 +274,7 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 		streamCtx := stream.NewStreamContext()
 		messageID := "chatcmpl-" + req.Model
 		translator := stream.NewStreamTranslator(e.Cfg, from, from.String(), req.Model, messageID, streamCtx)
+		translator.SetThoughtSignatures(stream.ThoughtSignaturesFromContext(ctx))
 		processor := &geminiStreamProcessor{
 			translator: translator,
 		}
@@ -283,6 +310,7 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 						}
 					}
 				}
+				reporter.PublishPartialFailure(ctx, streamCtx.PartialUsage())
 				pipeline.SendError(err)
 				return nil
 			}
@@ -296,7 +324,14 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 			}
 		}
 		if errScan := scanner.Err(); errScan != nil {
-			reporter.PublishFailure(ctx)
+			if flushed, _ := processor.ProcessDone(); len(flushed) > 0 {
+				for _, chunk := range flushed {
+					if !pipeline.SendData(chunk) {
+						return nil
+					}
+				}
+			}
+			reporter.PublishPartialFailure(ctx, streamCtx.PartialUsage())
 			pipeline.SendError(errScan)
 			return nil
 		}
@@ -314,11 +349,13 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	return stream.ConvertPipelineToStreamChunk(ctx, pipeline.Output()), nil
 }
 
-func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	apiKey, bearer := geminiCreds(auth)
 
 	from := opts.SourceFormat
-	translatedReq, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	translatedReq, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return provider.Response{}, fmt.Errorf("translate request: %w", err)
 	}
@@ -518,3 +555,171 @@ func FetchGeminiModels(ctx context.Context, auth *provider.Auth, cfg *config.Con
 
 	return FetchGLAPIModels(ctx, httpClient, fetchCfg)
 }
+
+// Embed implements provider.Embedder using the batchEmbedContents endpoint.
+func (e *GeminiExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	apiKey, bearer := geminiCreds(auth)
+
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth).WithOperation(usage.OperationEmbeddings)
+	defer reporter.TrackFailure(ctx, &err)
+
+	embedReq, err := to_ir.ParseOpenAIEmbeddingRequest(req.Payload)
+	if err != nil {
+		return resp, executor.NewStatusError(http.StatusBadRequest, err.Error(), nil)
+	}
+	body, err := from_ir.ToGeminiEmbeddingRequest(embedReq, req.Model)
+	if err != nil {
+		return resp, fmt.Errorf("translate request: %w", err)
+	}
+
+	baseURL := resolveGeminiBaseURL(auth)
+	ub := executor.GetURLBuilder()
+	defer ub.Release()
+	ub.Grow(128)
+	ub.WriteString(baseURL)
+	ub.WriteString("/")
+	ub.WriteString(executor.GeminiGLAPIVersion)
+	ub.WriteString("/models/")
+	ub.WriteString(req.Model)
+	ub.WriteString(":batchEmbedContents")
+	url := ub.String()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	executor.SetCommonHeaders(httpReq, "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("x-goog-api-key", apiKey)
+	} else if bearer != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	applyGeminiHeaders(httpReq, auth)
+
+	httpClient := e.NewHTTPClient(ctx, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return resp, executor.NewTimeoutError("request timed out")
+		}
+		return resp, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		result := executor.HandleHTTPError(httpResp, "gemini executor")
+		return resp, result.Error
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	embedResp, err := to_ir.ParseGeminiEmbeddingResponse(data)
+	if err != nil {
+		return resp, fmt.Errorf("parse embeddings: %w", err)
+	}
+	// batchEmbedContents reports no usage, so prompt tokens are estimated.
+	tokens := executor.CountEmbeddingTokens(req.Model, embedReq.Input)
+	embedResp.Usage = &ir.Usage{PromptTokens: tokens, TotalTokens: tokens}
+	reporter.Publish(ctx, embedResp.Usage)
+	reporter.EnsurePublished(ctx)
+
+	out, err := from_ir.ToOpenAIEmbeddingResponse(embedResp, req.Model, embedReq.EncodingFormat)
+	if err != nil {
+		return resp, err
+	}
+	return provider.Response{Payload: out}, nil
+}
+
+// geminiMaxFileSize is the upload limit of the Gemini Files API.
+const geminiMaxFileSize = 2 << 30
+
+// MaxFileSize implements provider.FileStorer.
+func (e *GeminiExecutor) MaxFileSize() int64 { return geminiMaxFileSize }
+
+// UploadFile implements provider.FileStorer with a multipart Files API upload.
+// Gemini keeps files for 48 hours and ignores a requested TTL; the file URI is
+// what content parts reference.
+func (e *GeminiExecutor) UploadFile(ctx context.Context, auth *provider.Auth, file provider.FileUpload) (provider.StoredFile, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	meta, _ := sjson.SetBytes([]byte(`{"file":{}}`), "file.display_name", file.Filename)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", "application/json; charset=UTF-8")
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return provider.StoredFile{}, err
+	}
+	if _, err = part.Write(meta); err != nil {
+		return provider.StoredFile{}, err
+	}
+	header = make(textproto.MIMEHeader)
+	header.Set("Content-Type", file.MimeType)
+	if part, err = mw.CreatePart(header); err != nil {
+		return provider.StoredFile{}, err
+	}
+	if _, err = part.Write(file.Data); err != nil {
+		return provider.StoredFile{}, err
+	}
+	if err = mw.Close(); err != nil {
+		return provider.StoredFile{}, err
+	}
+
+	url := resolveGeminiBaseURL(auth) + "/upload/" + executor.GeminiGLAPIVersion + "/files"
+	contentType := "multipart/related; boundary=" + mw.Boundary()
+	data, err := e.filesRequest(ctx, auth, http.MethodPost, url, contentType, &body)
+	if err != nil {
+		return provider.StoredFile{}, err
+	}
+	name := gjson.GetBytes(data, "file.name").String()
+	uri := gjson.GetBytes(data, "file.uri").String()
+	if name == "" || uri == "" {
+		return provider.StoredFile{}, fmt.Errorf("gemini executor: upload response has no file name")
+	}
+	stored := provider.StoredFile{ID: name, Ref: uri}
+	if exp, errParse := time.Parse(time.RFC3339, gjson.GetBytes(data, "file.expirationTime").String()); errParse == nil {
+		stored.ExpiresAt = exp
+	}
+	return stored, nil
+}
+
+// DeleteFile implements provider.FileStorer. id is the file name ("files/...").
+func (e *GeminiExecutor) DeleteFile(ctx context.Context, auth *provider.Auth, id string) error {
+	url := resolveGeminiBaseURL(auth) + "/" + executor.GeminiGLAPIVersion + "/" + id
+	_, err := e.filesRequest(ctx, auth, http.MethodDelete, url, "", nil)
+	return err
+}
+
+// filesRequest sends a Files API request and returns the body of a 2xx reply.
+func (e *GeminiExecutor) filesRequest(ctx context.Context, auth *provider.Auth, method, url, contentType string, body io.Reader) ([]byte, error) {
+	apiKey, bearer := geminiCreds(auth)
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	executor.SetCommonHeaders(httpReq, contentType)
+	if method == http.MethodPost {
+		httpReq.Header.Set("X-Goog-Upload-Protocol", "multipart")
+	}
+	if apiKey != "" {
+		httpReq.Header.Set("x-goog-api-key", apiKey)
+	} else if bearer != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	applyGeminiHeaders(httpReq, auth)
+
+	httpClient := e.NewHTTPClient(ctx, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, executor.NewTimeoutError("request timed out")
+		}
+		return nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		result := executor.HandleHTTPError(httpResp, "gemini executor")
+		return nil, result.Error
+	}
+	return io.ReadAll(httpResp.Body)
+}
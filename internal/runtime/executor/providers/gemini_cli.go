@@ -74,7 +74,7 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *provider.Auth, re
 			return resp, fmt.Errorf("failed to translate request: %w", err)
 		}
 	} else {
-		geminiPayload, errGemini := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+		geminiPayload, errGemini := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 		if errGemini != nil {
 			return resp, fmt.Errorf("failed to translate request: %w", errGemini)
 		}
@@ -160,8 +160,9 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *provider.Auth, re
 			// Unwrap envelope if present (Gemini CLI wraps response in {"response": ...})
 			// This allows us to use the standard Gemini format translator.
 			cleanData := cloudcode.ResponseUnwrap(data)
+			stream.RememberThoughtSignatures(ctx, cleanData)
 
-			translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, provider.FormatGemini, from, cleanData, attemptModel)
+			translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, provider.FormatGemini, from, opts.OriginalRequest, cleanData, attemptModel)
 			if err != nil {
 				return resp, err
 			}
@@ -232,7 +233,7 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *provider.Au
 		}
 	} else {
 		var errGemini error
-		translation, errGemini = stream.TranslateToGeminiWithTokens(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+		translation, errGemini = stream.TranslateToGeminiWithTokens(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 		if errGemini != nil {
 			return nil, fmt.Errorf("failed to translate request: %w", errGemini)
 		}
@@ -363,7 +364,9 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *provider.Au
 	return nil, err
 }
 
-func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	tokenSource, baseTokenData, err := prepareGeminiCLITokenSource(ctx, e.Cfg, auth)
 	if err != nil {
 		return provider.Response{}, err
@@ -392,7 +395,7 @@ func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *provider.Auth
 				return provider.Response{}, fmt.Errorf("failed to translate request: %w", errClaude)
 			}
 		} else {
-			geminiPayload, errGemini := stream.TranslateToGemini(e.Cfg, from, attemptModel, req.Payload, false, req.Metadata)
+			geminiPayload, errGemini := stream.TranslateToGemini(ctx, e.Cfg, from, attemptModel, req.Payload, false, req.Metadata)
 			if errGemini != nil {
 				return provider.Response{}, fmt.Errorf("failed to translate request: %w", errGemini)
 			}
@@ -524,7 +527,7 @@ func prepareGeminiCLITokenSource(ctx context.Context, cfg *config.Config, auth *
 	}
 
 	ctxToken := ctx
-	if httpClient := executor.NewProxyAwareHTTPClient(ctx, cfg, auth, 0); httpClient != nil {
+	if httpClient := executor.NewTokenHTTPClient(ctx, cfg, auth, 0); httpClient != nil {
 		ctxToken = context.WithValue(ctxToken, oauth2.HTTPClient, httpClient)
 	}
 
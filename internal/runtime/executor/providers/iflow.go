@@ -88,7 +88,7 @@ func (e *IFlowExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 	reporter.EnsurePublished(ctx)
 
 	fromOpenAI := provider.FromString("openai")
-	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, data, req.Model)
+	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, opts.OriginalRequest, data, req.Model)
 	if err != nil {
 		return resp, err
 	}
@@ -156,7 +156,9 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	}), nil
 }
 
-func (e *IFlowExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *IFlowExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	return executor.CountTokensForOpenAIProvider(ctx, e.Cfg, "iflow executor", opts.SourceFormat, req.Model, req.Payload, req.Metadata)
 }
 
@@ -130,6 +130,9 @@ func (e *KiroExecutor) prepareRequest(ctx context.Context, auth *provider.Auth,
 		}
 		rc.irReq.Metadata["profileArn"] = arn
 	}
+	if err = stream.InlineRemoteImages(ctx, rc.irReq); err != nil {
+		return nil, err
+	}
 
 	rc.kiroBody, err = translator.ConvertRequest("kiro", rc.irReq)
 	return rc, err
@@ -309,7 +312,9 @@ func (e *KiroExecutor) processStream(ctx context.Context, resp *http.Response, m
 	}
 }
 
-func (e *KiroExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *KiroExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	return provider.Response{Payload: []byte(`{"total_tokens": 0}`)}, nil
 }
 
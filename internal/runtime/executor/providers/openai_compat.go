@@ -6,8 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nghyane/llm-mux/internal/config"
 	log "github.com/nghyane/llm-mux/internal/logging"
@@ -15,7 +20,12 @@ import (
 	"github.com/nghyane/llm-mux/internal/runtime/executor"
 	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
 	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
 
@@ -93,7 +103,7 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *provider.Auth,
 	reporter.EnsurePublished(ctx)
 
 	fromOpenAI := provider.FromString("openai")
-	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, body, req.Model)
+	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, opts.OriginalRequest, body, req.Model)
 	if err != nil {
 		return resp, err
 	}
@@ -166,7 +176,9 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *provider
 	}), nil
 }
 
-func (e *OpenAICompatExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *OpenAICompatExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	from := opts.SourceFormat
 	translated, err := stream.TranslateToOpenAI(e.Cfg, from, req.Model, req.Payload, false, nil)
 	if err != nil {
@@ -268,3 +280,187 @@ func (e *OpenAICompatExecutor) overrideModel(payload []byte, model string) []byt
 	payload, _ = sjson.SetBytes(payload, "model", model)
 	return payload
 }
+
+// Embed implements provider.Embedder against the upstream /embeddings endpoint.
+func (e *OpenAICompatExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth).WithOperation(usage.OperationEmbeddings)
+	defer reporter.TrackFailure(ctx, &err)
+
+	embedReq, err := to_ir.ParseOpenAIEmbeddingRequest(req.Payload)
+	if err != nil {
+		return resp, executor.NewStatusError(http.StatusBadRequest, err.Error(), nil)
+	}
+	upstreamModel := e.upstreamModelFor(req.Model, auth)
+	body, err := from_ir.ToOpenAIEmbeddingRequest(embedReq, upstreamModel)
+	if err != nil {
+		return resp, err
+	}
+	data, err := e.postJSON(ctx, auth, "/embeddings", body)
+	if err != nil {
+		return resp, err
+	}
+
+	embedResp, err := to_ir.ParseOpenAIEmbeddingResponse(data)
+	if err != nil {
+		return resp, fmt.Errorf("parse embeddings: %w", err)
+	}
+	if embedResp.Usage == nil {
+		tokens := executor.CountEmbeddingTokens(upstreamModel, embedReq.Input)
+		embedResp.Usage = &ir.Usage{PromptTokens: tokens, TotalTokens: tokens}
+	}
+	reporter.Publish(ctx, embedResp.Usage)
+	reporter.EnsurePublished(ctx)
+
+	out, err := from_ir.ToOpenAIEmbeddingResponse(embedResp, req.Model, embedReq.EncodingFormat)
+	if err != nil {
+		return resp, err
+	}
+	return provider.Response{Payload: out}, nil
+}
+
+// Rerank implements provider.Reranker against the upstream /rerank endpoint
+// exposed by Cohere, Jina and similar OpenAI-compatible rerank servers.
+func (e *OpenAICompatExecutor) Rerank(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := e.NewUsageReporter(ctx, e.Identifier(), req.Model, auth).WithOperation(usage.OperationRerank)
+	defer reporter.TrackFailure(ctx, &err)
+
+	rerankReq, err := to_ir.ParseRerankRequest(req.Payload)
+	if err != nil {
+		return resp, executor.NewStatusError(http.StatusBadRequest, err.Error(), nil)
+	}
+	upstreamModel := e.upstreamModelFor(req.Model, auth)
+	body, err := from_ir.ToRerankRequest(rerankReq, upstreamModel)
+	if err != nil {
+		return resp, err
+	}
+	data, err := e.postJSON(ctx, auth, "/rerank", body)
+	if err != nil {
+		return resp, err
+	}
+
+	rerankResp, err := to_ir.ParseRerankResponse(data, len(rerankReq.Documents))
+	if err != nil {
+		return resp, fmt.Errorf("parse rerank: %w", err)
+	}
+	if rerankResp.Usage == nil {
+		tokens := executor.CountEmbeddingTokens(upstreamModel, append([]string{rerankReq.Query}, rerankReq.Documents...))
+		rerankResp.Usage = &ir.Usage{PromptTokens: tokens, TotalTokens: tokens}
+	}
+	reporter.Publish(ctx, rerankResp.Usage)
+	reporter.EnsurePublished(ctx)
+
+	out, err := from_ir.ToRerankResponse(rerankResp, rerankReq, req.Model)
+	if err != nil {
+		return resp, err
+	}
+	return provider.Response{Payload: out}, nil
+}
+
+// openAIMaxFileSize is the upload limit of the OpenAI Files API.
+const openAIMaxFileSize = 512 << 20
+
+// MaxFileSize implements provider.FileStorer.
+func (e *OpenAICompatExecutor) MaxFileSize() int64 { return openAIMaxFileSize }
+
+// UploadFile implements provider.FileStorer against the upstream /files
+// endpoint. A TTL is forwarded as expires_after so the upstream copy expires
+// with the record.
+func (e *OpenAICompatExecutor) UploadFile(ctx context.Context, auth *provider.Auth, file provider.FileUpload) (provider.StoredFile, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	purpose := file.Purpose
+	if purpose == "" {
+		purpose = "user_data"
+	}
+	_ = mw.WriteField("purpose", purpose)
+	if file.TTL > 0 {
+		_ = mw.WriteField("expires_after[anchor]", "created_at")
+		_ = mw.WriteField("expires_after[seconds]", strconv.FormatInt(int64(file.TTL/time.Second), 10))
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, file.Filename))
+	header.Set("Content-Type", file.MimeType)
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return provider.StoredFile{}, err
+	}
+	if _, err = part.Write(file.Data); err != nil {
+		return provider.StoredFile{}, err
+	}
+	if err = mw.Close(); err != nil {
+		return provider.StoredFile{}, err
+	}
+
+	data, err := e.send(ctx, auth, http.MethodPost, "/files", mw.FormDataContentType(), &body)
+	if err != nil {
+		return provider.StoredFile{}, err
+	}
+	id := gjson.GetBytes(data, "id").String()
+	if id == "" {
+		return provider.StoredFile{}, fmt.Errorf("openai-compat executor: upload response has no file id")
+	}
+	stored := provider.StoredFile{ID: id, Ref: id}
+	if expiresAt := gjson.GetBytes(data, "expires_at").Int(); expiresAt > 0 {
+		stored.ExpiresAt = time.Unix(expiresAt, 0)
+	}
+	return stored, nil
+}
+
+// DeleteFile implements provider.FileStorer.
+func (e *OpenAICompatExecutor) DeleteFile(ctx context.Context, auth *provider.Auth, id string) error {
+	_, err := e.send(ctx, auth, http.MethodDelete, "/files/"+url.PathEscape(id), "", nil)
+	return err
+}
+
+func (e *OpenAICompatExecutor) upstreamModelFor(model string, auth *provider.Auth) string {
+	if modelOverride := e.resolveUpstreamModel(model, auth); modelOverride != "" {
+		return modelOverride
+	}
+	return model
+}
+
+// postJSON sends a unary JSON request to path under the provider base URL and
+// returns the response body of a 2xx reply.
+func (e *OpenAICompatExecutor) postJSON(ctx context.Context, auth *provider.Auth, path string, body []byte) ([]byte, error) {
+	return e.send(ctx, auth, http.MethodPost, path, "application/json", bytes.NewReader(body))
+}
+
+// send issues a unary request to path under the provider base URL and returns
+// the response body of a 2xx reply.
+func (e *OpenAICompatExecutor) send(ctx context.Context, auth *provider.Auth, method, path, contentType string, body io.Reader) ([]byte, error) {
+	baseURL, apiKey := e.resolveCredentials(auth)
+	if baseURL == "" {
+		return nil, executor.NewStatusError(http.StatusUnauthorized, "missing provider baseURL", nil)
+	}
+
+	url := strings.TrimSuffix(baseURL, "/") + path
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	executor.SetCommonHeaders(httpReq, contentType)
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+
+	httpClient := e.NewHTTPClient(ctx, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, executor.NewTimeoutError("request timed out")
+		}
+		return nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		result := executor.HandleHTTPError(httpResp, "openai-compat executor")
+		return nil, result.Error
+	}
+	return io.ReadAll(httpResp.Body)
+}
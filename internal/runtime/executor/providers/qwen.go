@@ -78,7 +78,7 @@ func (e *QwenExecutor) Execute(ctx context.Context, auth *provider.Auth, req pro
 	reporter.Publish(ctx, executor.ExtractUsageFromOpenAIResponse(data))
 
 	fromOpenAI := provider.FromString("openai")
-	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, data, req.Model)
+	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromOpenAI, from, opts.OriginalRequest, data, req.Model)
 	if err != nil {
 		return resp, err
 	}
@@ -142,7 +142,9 @@ func (e *QwenExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, r
 	}), nil
 }
 
-func (e *QwenExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *QwenExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	return executor.CountTokensForOpenAIProvider(ctx, e.Cfg, "qwen executor", opts.SourceFormat, req.Model, req.Payload, req.Metadata)
 }
 
@@ -143,7 +143,7 @@ func (e *VertexExecutor) executeWithStrategy(ctx context.Context, auth *provider
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	body, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	body, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return resp, err
 	}
@@ -201,8 +201,9 @@ func (e *VertexExecutor) executeWithStrategy(ctx context.Context, auth *provider
 	}
 	reporter.Publish(ctx, executor.ExtractUsageFromGeminiResponse(data))
 
+	stream.RememberThoughtSignatures(ctx, data)
 	fromFormat := provider.FromString("gemini")
-	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, from, data, req.Model)
+	translatedResp, err := stream.TranslateResponseNonStream(e.Cfg, fromFormat, from, opts.OriginalRequest, data, req.Model)
 	if err != nil {
 		return resp, err
 	}
@@ -227,7 +228,7 @@ func (e *VertexExecutor) executeStreamWithStrategy(ctx context.Context, auth *pr
 	defer reporter.TrackFailure(ctx, &err)
 
 	from := opts.SourceFormat
-	translation, err := stream.TranslateToGeminiWithTokens(e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
+	translation, err := stream.TranslateToGeminiWithTokens(ctx, e.Cfg, from, req.Model, req.Payload, true, req.Metadata)
 	if err != nil {
 		return nil, err
 	}
@@ -293,7 +294,9 @@ func (e *VertexExecutor) executeStreamWithStrategy(ctx context.Context, auth *pr
 	}), nil
 }
 
-func (e *VertexExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+func (e *VertexExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (_ provider.Response, err error) {
+	defer e.ReportTokenCount(ctx, e.Identifier(), req.Model, auth, &err)
+
 	strategy, err := e.resolveStrategy(auth)
 	if err != nil {
 		return provider.Response{}, err
@@ -303,7 +306,7 @@ func (e *VertexExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 
 func (e *VertexExecutor) countTokensWithStrategy(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options, strategy VertexAuthStrategy) (provider.Response, error) {
 	from := opts.SourceFormat
-	translatedReq, err := stream.TranslateToGemini(e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
+	translatedReq, err := stream.TranslateToGemini(ctx, e.Cfg, from, req.Model, req.Payload, false, req.Metadata)
 	if err != nil {
 		return provider.Response{}, err
 	}
@@ -384,6 +387,10 @@ func (p *vertexStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, e
 	return result.Chunks, result.Usage, nil
 }
 
+func (p *vertexStreamProcessor) StreamTranslator() *stream.StreamTranslator {
+	return p.translator
+}
+
 func (p *vertexStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush()
 }
@@ -457,7 +464,7 @@ func vertexBaseURL(location string) string {
 }
 
 func vertexAccessToken(ctx context.Context, cfg *config.Config, auth *provider.Auth, saJSON []byte) (string, error) {
-	if httpClient := executor.NewProxyAwareHTTPClient(ctx, cfg, auth, 0); httpClient != nil {
+	if httpClient := executor.NewTokenHTTPClient(ctx, cfg, auth, 0); httpClient != nil {
 		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
 	}
 	creds, errCreds := google.CredentialsFromJSON(ctx, saJSON, "https://www.googleapis.com/auth/cloud-platform")
@@ -2,6 +2,8 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,12 +15,35 @@ import (
 	"golang.org/x/net/proxy"
 )
 
+// NewProxyAwareHTTPClient returns a pooled client routed through the auth's
+// proxy, falling back to the global proxy-url. Supported schemes are http,
+// https, socks5 and socks5h; userinfo in the URL is sent as proxy credentials.
+// Malformed or unsupported proxy URLs fall back to a direct connection. Auths
+// carrying tls_client_cert/tls_client_key attributes get a dedicated transport
+// presenting that certificate. Requests carry the upstream-headers configured
+// for the auth's provider, and are recorded or replayed when cassettes are on.
 func NewProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *provider.Auth, timeout time.Duration) *http.Client {
+	httpClient := NewTokenHTTPClient(ctx, cfg, auth, timeout)
+	httpClient.Transport = withCassette(httpClient.Transport, cfg)
+	return httpClient
+}
+
+// NewTokenHTTPClient is NewProxyAwareHTTPClient without cassettes, for OAuth
+// token exchanges: their bodies carry refresh tokens, client secrets and
+// access tokens, which must never be written to a recording or replayed stale.
+func NewTokenHTTPClient(ctx context.Context, cfg *config.Config, auth *provider.Auth, timeout time.Duration) *http.Client {
 	httpClient := AcquireHTTPClient()
 	if timeout > 0 {
 		httpClient.Timeout = timeout
 	}
+	httpClient.Transport = withUpstreamHeaders(selectTransport(ctx, cfg, auth), cfg, auth)
+	return httpClient
+}
 
+// selectTransport picks the transport for auth: a client certificate
+// transport, a cached proxy transport, one supplied through ctx, or the
+// shared transport, in that order.
+func selectTransport(ctx context.Context, cfg *config.Config, auth *provider.Auth) http.RoundTripper {
 	var proxyURL string
 	if auth != nil {
 		proxyURL = strings.TrimSpace(auth.ProxyURL)
@@ -28,38 +53,61 @@ func NewProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *prov
 		proxyURL = strings.TrimSpace(cfg.ProxyURL)
 	}
 
+	if certFile, keyFile := clientCertAttrs(auth); certFile != "" {
+		return clientCertTransport(certFile, keyFile, proxyURL)
+	}
+
 	if proxyURL != "" {
 		// Use cached transport for proxy URLs to enable connection pooling
-		transport := getCachedTransport(proxyURL)
+		transport := getCachedTransport(proxyURL, func() *http.Transport {
+			return buildProxyTransport(proxyURL)
+		})
 		if transport != nil {
-			httpClient.Transport = transport
-			return httpClient
+			return transport
 		}
-		log.Debugf("failed to setup proxy from URL: %s, falling back to context transport", proxyURL)
 	}
 
 	if rt, ok := ctx.Value("cliproxy.roundtripper").(http.RoundTripper); ok && rt != nil {
-		httpClient.Transport = rt
-		return httpClient
+		return rt
 	}
 
-	httpClient.Transport = SharedTransport
-	return httpClient
+	return SharedTransport
+}
+
+// parseProxyURL validates a proxy URL and normalizes its scheme.
+func parseProxyURL(raw string) (*url.URL, error) {
+	parsedURL, err := url.Parse(raw)
+	if err != nil {
+		// url.Error quotes the whole URL, credentials included.
+		return nil, errors.New("parse proxy URL: malformed URL")
+	}
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+	switch parsedURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", parsedURL.Scheme)
+	}
+	if parsedURL.Hostname() == "" {
+		return nil, fmt.Errorf("proxy URL %s has no host", parsedURL.Redacted())
+	}
+	return parsedURL, nil
 }
 
+// buildProxyTransport returns a transport for proxyURLStr, or nil when the URL
+// is unusable. Errors are logged with credentials redacted.
 func buildProxyTransport(proxyURLStr string) *http.Transport {
 	if proxyURLStr == "" {
 		return nil
 	}
 
-	parsedURL, errParse := url.Parse(proxyURLStr)
+	parsedURL, errParse := parseProxyURL(proxyURLStr)
 	if errParse != nil {
-		log.Errorf("parse proxy URL failed: %v", errParse)
+		log.Warnf("proxy disabled, using direct connection: %v", errParse)
 		return nil
 	}
 
 	switch parsedURL.Scheme {
-	case "socks5":
+	case "socks5", "socks5h":
 		var proxyAuth *proxy.Auth
 		if parsedURL.User != nil {
 			username := parsedURL.User.Username()
@@ -68,14 +116,12 @@ func buildProxyTransport(proxyURLStr string) *http.Transport {
 		}
 		dialer, errSOCKS5 := proxy.SOCKS5("tcp", parsedURL.Host, proxyAuth, proxy.Direct)
 		if errSOCKS5 != nil {
-			log.Errorf("create SOCKS5 dialer failed: %v", errSOCKS5)
+			log.Warnf("proxy disabled, using direct connection: create SOCKS5 dialer for %s: %v", parsedURL.Redacted(), errSOCKS5)
 			return nil
 		}
 		return SOCKS5Transport(dialer.Dial)
-	case "http", "https":
-		return ProxyTransport(parsedURL)
 	default:
-		log.Errorf("unsupported proxy scheme: %s", parsedURL.Scheme)
-		return nil
+		// net/http sends the URL's userinfo as Proxy-Authorization.
+		return ProxyTransport(parsedURL)
 	}
 }
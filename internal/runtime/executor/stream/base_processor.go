@@ -26,6 +26,10 @@ func (p *BaseStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, err
 	return result.Chunks, result.Usage, nil
 }
 
+func (p *BaseStreamProcessor) StreamTranslator() *StreamTranslator {
+	return p.Translator
+}
+
 func (p *BaseStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.Translator.Flush()
 }
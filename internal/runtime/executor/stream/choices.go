@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// choiceState tracks the additional choices of a request with n > 1. Choice 0
+// carries the stream's finish, after which nothing more is sent, so its finish
+// event is held back until every other choice seen so far has finished.
+type choiceState struct {
+	finished  map[int]bool // choice index -> finish seen
+	toolCalls map[int]bool
+	pending   *ir.UnifiedEvent
+}
+
+// allFinished reports whether every additional choice seen has finished.
+func (c *choiceState) allFinished() bool {
+	for _, done := range c.finished {
+		if !done {
+			return false
+		}
+	}
+	return true
+}
+
+// takePending returns and clears the held back finish of choice 0.
+func (c *choiceState) takePending() *ir.UnifiedEvent {
+	ev := c.pending
+	c.pending = nil
+	return ev
+}
+
+// holdFinish keeps the finish of choice 0 while other choices are still
+// streaming. Usage reported by a later duplicate finish is merged into the
+// held event.
+func (t *StreamTranslator) holdFinish(event *ir.UnifiedEvent) bool {
+	c := &t.Ctx.choices
+	if event.Type != ir.EventTypeFinish || t.Ctx.FinishSent || c.allFinished() {
+		return false
+	}
+	if c.pending == nil {
+		held := *event
+		c.pending = &held
+	} else if c.pending.Usage == nil {
+		c.pending.Usage = event.Usage
+	}
+	return true
+}
+
+// translateChoice converts an event of an additional choice. Only OpenAI
+// chat completions can carry several choices; other formats keep choice 0.
+func (t *StreamTranslator) translateChoice(event *ir.UnifiedEvent) ([][]byte, error) {
+	if (t.to != "openai" && t.to != "cline") || t.Ctx.FinishSent {
+		return nil, nil
+	}
+	c := &t.Ctx.choices
+	idx := event.CandidateIndex
+	if c.finished[idx] {
+		return nil, nil
+	}
+	if c.finished == nil {
+		c.finished = make(map[int]bool)
+		c.toolCalls = make(map[int]bool)
+	}
+	c.finished[idx] = false
+
+	switch event.Type {
+	case ir.EventTypeToolCall:
+		c.toolCalls[idx] = true
+	case ir.EventTypeToken:
+		t.Ctx.ContentCharsAccum += len(event.Content)
+	case ir.EventTypeReasoning:
+		t.Ctx.AccumulateReasoning(event.Reasoning)
+		if t.Ctx.HideThoughts {
+			return nil, nil
+		}
+	case ir.EventTypeFinish:
+		c.finished[idx] = true
+		if c.toolCalls[idx] {
+			event.FinishReason = ir.FinishReasonToolCalls
+		}
+	}
+
+	var meta *ir.OpenAIMeta
+	if created := t.Ctx.OpenAIToolCalls.Created; created > 0 {
+		meta = &ir.OpenAIMeta{CreateTime: created}
+	}
+	chunk, err := from_ir.ToOpenAIChunkMeta(*event, t.model, t.messageID, event.ToolCallIndex, meta)
+	if err != nil {
+		return nil, err
+	}
+	var out [][]byte
+	if chunk != nil {
+		out = t.chunkBuffer.Process(chunk, nil)
+	}
+	if event.Type == ir.EventTypeFinish && c.pending != nil && c.allFinished() {
+		chunks, err := t.translateEvent(c.takePending())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunks...)
+	}
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+// collectChoices returns the streamed text and finish reason per choice index,
+// and checks that the stream's last chunk is a finish.
+func collectChoices(t *testing.T, chunks [][]byte) (map[int64]string, map[int64]string) {
+	t.Helper()
+	text := map[int64]string{}
+	finish := map[int64]string{}
+	for i, chunk := range chunks {
+		data := ir.ExtractSSEData(chunk)
+		for _, c := range gjson.GetBytes(data, "choices").Array() {
+			idx := c.Get("index").Int()
+			text[idx] += c.Get("delta.content").String()
+			if fr := c.Get("finish_reason").String(); fr != "" {
+				if _, dup := finish[idx]; dup {
+					t.Errorf("choice %d finished twice", idx)
+				}
+				finish[idx] = fr
+			}
+		}
+		if i == len(chunks)-1 && gjson.GetBytes(data, "choices.0.index").Int() != 0 {
+			t.Errorf("last chunk = %s, want the finish of choice 0", data)
+		}
+	}
+	return text, finish
+}
+
+func TestStreamChoicesFromOpenAI(t *testing.T) {
+	lines := []string{
+		`{"id":"x","choices":[{"index":0,"delta":{"content":"A"}}]}`,
+		`{"id":"x","choices":[{"index":1,"delta":{"content":"B"}}]}`,
+		`{"id":"x","choices":[{"index":0,"delta":{"content":"a"}}]}`,
+		`{"id":"x","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`{"id":"x","choices":[{"index":1,"delta":{"content":"b"}}]}`,
+		`{"id":"x","choices":[{"index":1,"delta":{},"finish_reason":"length"}]}`,
+		`{"id":"x","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		`[DONE]`,
+	}
+	st := NewStreamTranslator(nil, provider.FormatOpenAI, "openai", "gpt-test", "chatcmpl-gpt-test", nil)
+
+	var chunks [][]byte
+	for _, line := range lines {
+		events, err := to_ir.ParseOpenAIChunk([]byte(line))
+		if err != nil {
+			t.Fatalf("ParseOpenAIChunk: %v", err)
+		}
+		res, err := st.Translate(events)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		chunks = append(chunks, res.Chunks...)
+	}
+	flushed, err := st.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	chunks = append(chunks, flushed...)
+
+	text, finish := collectChoices(t, chunks)
+	if text[0] != "Aa" || text[1] != "Bb" {
+		t.Errorf("text = %v, want Aa for choice 0 and Bb for choice 1", text)
+	}
+	if finish[0] != "stop" || finish[1] != "length" {
+		t.Errorf("finish reasons = %v, want stop and length", finish)
+	}
+	last := ir.ExtractSSEData(chunks[len(chunks)-1])
+	if gjson.GetBytes(last, "usage.total_tokens").Int() != 7 {
+		t.Errorf("final chunk = %s, want usage merged into the held finish", last)
+	}
+}
+
+func TestStreamChoicesFromGemini(t *testing.T) {
+	lines := []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"A"}]}},{"index":1,"content":{"role":"model","parts":[{"text":"B"}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"a"}]},"finishReason":"STOP"},{"index":1,"content":{"role":"model","parts":[{"functionCall":{"name":"lookup","args":{}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":4,"totalTokenCount":7}}`,
+	}
+	st := NewStreamTranslator(nil, provider.FormatGemini, "openai", "gemini-test", "chatcmpl-gemini-test", nil)
+
+	var chunks [][]byte
+	for _, line := range lines {
+		events, err := to_ir.ParseGeminiChunkWithStateContext([]byte(line), st.Ctx.GeminiState, nil)
+		if err != nil {
+			t.Fatalf("ParseGeminiChunk: %v", err)
+		}
+		res, err := st.Translate(events)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		chunks = append(chunks, res.Chunks...)
+	}
+
+	text, finish := collectChoices(t, chunks)
+	if text[0] != "Aa" || text[1] != "B" {
+		t.Errorf("text = %v, want Aa for choice 0 and B for choice 1", text)
+	}
+	if finish[0] != "stop" || finish[1] != "tool_calls" {
+		t.Errorf("finish reasons = %v, want stop for choice 0 and tool_calls for choice 1", finish)
+	}
+}
+
+func TestStreamChoicesDroppedForSingleChoiceFormats(t *testing.T) {
+	st := NewStreamTranslator(nil, provider.FormatOpenAI, "claude", "claude-test", "msg-test", nil)
+	events, err := to_ir.ParseOpenAIChunk([]byte(`{"id":"x","choices":[{"index":1,"delta":{"content":"B"},"finish_reason":"stop"}]}`))
+	if err != nil {
+		t.Fatalf("ParseOpenAIChunk: %v", err)
+	}
+	for _, ev := range events {
+		if ev.CandidateIndex != 1 {
+			t.Fatalf("event %+v, want candidate index 1", ev)
+		}
+	}
+	res, err := st.Translate(events)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	for _, chunk := range res.Chunks {
+		if gjson.GetBytes(ir.ExtractSSEData(chunk), "delta.text").String() == "B" {
+			t.Errorf("choice 1 text leaked into a single-choice stream: %s", chunk)
+		}
+	}
+	if st.Ctx.FinishSent {
+		t.Error("finish of choice 1 ended the stream")
+	}
+}
@@ -0,0 +1,213 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+)
+
+// CollectResponseStream reads an upstream SSE stream from body and returns the
+// non-streaming response to request in the to format, together with the usage
+// the upstream reported. Each event is parsed as it arrives and merged into the
+// response being built, so the raw upstream body is never held in full. Only
+// Gemini-format upstreams are supported.
+func CollectResponseStream(ctx context.Context, cfg *config.Config, from, to provider.Format, request []byte, body io.Reader, model string) ([]byte, *ir.Usage, error) {
+	if !provider.IsGeminiFormat(from.String()) {
+		return nil, nil, fmt.Errorf("collect response stream: unsupported upstream format %q", from.String())
+	}
+
+	bufPtr := ScannerBufferPool.Get().(*[]byte)
+	defer ScannerBufferPool.Put(bufPtr)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(*bufPtr, DefaultStreamBufferSize)
+
+	c := newResponseCollector(cfg)
+	for scanner.Scan() {
+		payload := sseutil.JSONPayload(sseutil.FilterSSEUsageMetadata(scanner.Bytes()))
+		if len(payload) == 0 {
+			continue
+		}
+		RememberThoughtSignatures(ctx, payload)
+		if err := c.addGeminiChunk(payload); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	parsed := c.finish()
+	usage := parsed.Usage
+	out, err := translateParsed(cfg, from, to, request, model, parsed)
+	return out, usage, err
+}
+
+// responseCollector merges streamed IR events into the candidates of a
+// non-streaming response, as ParseGeminiResponseCandidates would report them
+// for the same output sent as one body.
+type responseCollector struct {
+	state      *ir.GeminiStreamParserState
+	candidates []ir.CandidateResult
+	usage      *ir.Usage
+	meta       ir.OpenAIMeta
+	texts      map[partKey]*strings.Builder // text of the parts still being streamed
+}
+
+// partKey locates a content part by its index in candidates and in the
+// content of the candidate's message.
+type partKey struct{ candidate, part int }
+
+func newResponseCollector(cfg *config.Config) *responseCollector {
+	state := ir.NewGeminiStreamParserState()
+	state.ContentFilterResults = contentFilterResults(cfg)
+	return &responseCollector{state: state, texts: make(map[partKey]*strings.Builder)}
+}
+
+func (c *responseCollector) addGeminiChunk(payload []byte) error {
+	events, err := to_ir.ParseGeminiChunkWithState(payload, c.state)
+	if err != nil {
+		return err
+	}
+	parsed, _ := ir.UnwrapAntigravityEnvelope(payload)
+	if fr := parsed.Get("candidates.0.finishReason").String(); fr != "" {
+		c.meta.NativeFinishReason = fr
+	}
+	if tier := parsed.Get("service_tier").String(); tier != "" {
+		c.meta.ServiceTier = tier
+	}
+	for i := range events {
+		c.add(&events[i])
+	}
+	// Finish reasons are taken as sent, as for a buffered response; the
+	// stream parser reports tool calls ending with STOP as tool_calls.
+	for i, cand := range parsed.Get("candidates").Array() {
+		if fr := cand.Get("finishReason").String(); fr != "" {
+			idx := int(cand.Get("index").Int())
+			if idx == 0 {
+				idx = i
+			}
+			c.candidates[c.candidate(idx)].FinishReason = ir.MapGeminiFinishReason(fr)
+		}
+	}
+	return nil
+}
+
+func (c *responseCollector) add(event *ir.UnifiedEvent) {
+	ci := c.candidate(event.CandidateIndex)
+	cand := &c.candidates[ci]
+	switch event.Type {
+	case ir.EventTypeToken:
+		c.appendText(ci, ir.ContentTypeText, event.Content, event.ThoughtSignature)
+	case ir.EventTypeReasoning:
+		c.appendText(ci, ir.ContentTypeReasoning, event.Reasoning, event.ThoughtSignature)
+	case ir.EventTypeToolCall:
+		if event.ToolCall != nil {
+			tc := *event.ToolCall
+			tc.PartialArgs = ""
+			msg := candidateMessage(cand)
+			msg.ToolCalls = append(msg.ToolCalls, tc)
+		}
+	case ir.EventTypeCodeExecution:
+		if event.CodeExecution != nil {
+			partType := ir.ContentTypeExecutableCode
+			if event.CodeExecution.Outcome != "" {
+				partType = ir.ContentTypeCodeResult
+			}
+			msg := candidateMessage(cand)
+			msg.Content = append(msg.Content, ir.ContentPart{Type: partType, CodeExecution: event.CodeExecution, ThoughtSignature: event.ThoughtSignature})
+		}
+	case ir.EventTypeFinish:
+		// Every chunk that reports usage carries a finish event, so the
+		// last one holds the final usage.
+		if event.FinishReason != "" {
+			cand.FinishReason = event.FinishReason
+		}
+		if event.Logprobs != nil {
+			cand.Logprobs = event.Logprobs
+		}
+		if event.GroundingMetadata != nil {
+			cand.GroundingMetadata = event.GroundingMetadata
+		}
+		if event.ContentFilter != nil {
+			cand.ContentFilter = event.ContentFilter
+		}
+		if event.PromptFilter != nil {
+			c.meta.PromptFilter = event.PromptFilter
+		}
+		if event.Usage != nil {
+			c.usage = event.Usage
+		}
+	}
+}
+
+// finish adds any thinking still held for a signature and returns the
+// assembled response.
+func (c *responseCollector) finish() *ParsedResponse {
+	if pending := c.state.Finalize(); pending != nil {
+		c.add(pending)
+	}
+	for key, text := range c.texts {
+		part := &c.candidates[key.candidate].Messages[0].Content[key.part]
+		if part.Type == ir.ContentTypeText {
+			part.Text = text.String()
+		} else {
+			part.Reasoning = text.String()
+		}
+	}
+	c.meta.ResponseID, c.meta.CreateTime = c.state.ResponseID, c.state.CreateTime
+	if len(c.candidates) > 0 {
+		c.meta.Logprobs = c.candidates[0].Logprobs
+	}
+	return &ParsedResponse{Candidates: c.candidates, Usage: c.usage, Meta: &c.meta}
+}
+
+// candidate returns the position in c.candidates of the candidate with the
+// given index, adding it on first use.
+func (c *responseCollector) candidate(index int) int {
+	for i := range c.candidates {
+		if c.candidates[i].Index == index {
+			return i
+		}
+	}
+	c.candidates = append(c.candidates, ir.CandidateResult{Index: index, FinishReason: ir.FinishReasonStop})
+	return len(c.candidates) - 1
+}
+
+// candidateMessage returns the assistant message of cand, adding it on first use.
+func candidateMessage(cand *ir.CandidateResult) *ir.Message {
+	if len(cand.Messages) == 0 {
+		cand.Messages = []ir.Message{{Role: ir.RoleAssistant}}
+	}
+	return &cand.Messages[0]
+}
+
+// appendText extends the last part of the candidate's message when it has
+// the same type, so streamed deltas become one text or reasoning part, and
+// otherwise starts a new part. Text is gathered in a builder and set on the
+// part by finish.
+func (c *responseCollector) appendText(ci int, partType ir.ContentType, text string, signature []byte) {
+	if text == "" && len(signature) == 0 {
+		return
+	}
+	msg := candidateMessage(&c.candidates[ci])
+	if n := len(msg.Content); n > 0 && msg.Content[n-1].Type == partType {
+		last := &msg.Content[n-1]
+		if len(last.ThoughtSignature) == 0 {
+			last.ThoughtSignature = signature
+		}
+		c.texts[partKey{ci, n - 1}].WriteString(text)
+		return
+	}
+	msg.Content = append(msg.Content, ir.ContentPart{Type: partType, ThoughtSignature: signature})
+	b := new(strings.Builder)
+	b.WriteString(text)
+	c.texts[partKey{ci, len(msg.Content) - 1}] = b
+}
@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// geminiStreamed is geminiBuffered sent as a stream: the reasoning and text
+// arrive in pieces and an intermediate chunk carries usage.
+var geminiStreamed = []string{
+	`{"responseId":"r-1","createTime":"2025-06-01T12:00:00Z","candidates":[{"content":{"role":"model","parts":[{"text":"Let me ","thought":true}]}}]}`,
+	`{"candidates":[{"content":{"role":"model","parts":[{"text":"think.","thought":true}]}}]}`,
+	`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}],"usageMetadata":{"promptTokenCount":10,"totalTokenCount":10}}`,
+	`{"candidates":[{"content":{"role":"model","parts":[{"text":", world."},{"functionCall":{"id":"call_1","name":"lookup","args":{"q":"x"}}}]},"finishReason":"STOP"}],` +
+		`"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":6,"thoughtsTokenCount":3,"totalTokenCount":19}}`,
+}
+
+const geminiBuffered = `{"responseId":"r-1","createTime":"2025-06-01T12:00:00Z","candidates":[{"content":{"role":"model","parts":[` +
+	`{"text":"Let me think.","thought":true},{"text":"Hello, world."},{"functionCall":{"id":"call_1","name":"lookup","args":{"q":"x"}}}]},"finishReason":"STOP"}],` +
+	`"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":6,"thoughtsTokenCount":3,"totalTokenCount":19}}`
+
+func TestCollectResponseStreamMatchesBuffered(t *testing.T) {
+	var sse strings.Builder
+	for _, chunk := range geminiStreamed {
+		sse.WriteString("data: " + chunk + "\n\n")
+	}
+	cfg := upstreamIDConfig(true) // fixed id and created on both sides
+
+	for _, to := range []provider.Format{provider.FormatOpenAI, provider.FormatClaude, provider.FormatCodex, provider.FormatGemini} {
+		t.Run(to.String(), func(t *testing.T) {
+			want, err := TranslateResponseNonStream(cfg, provider.FormatGemini, to, nil, []byte(geminiBuffered), "gemini-test")
+			if err != nil {
+				t.Fatalf("TranslateResponseNonStream: %v", err)
+			}
+			if to == provider.FormatGemini {
+				// Same-format responses pass through; compare with the
+				// translated form instead.
+				parsed, err := parseSourceResponse("gemini", []byte(geminiBuffered))
+				if err != nil {
+					t.Fatalf("parseSourceResponse: %v", err)
+				}
+				if want, err = translateParsed(cfg, provider.FormatGemini, to, nil, "gemini-test", parsed); err != nil {
+					t.Fatalf("translateParsed: %v", err)
+				}
+			}
+
+			got, usage, err := CollectResponseStream(t.Context(), cfg, provider.FormatGemini, to, nil, strings.NewReader(sse.String()), "gemini-test")
+			if err != nil {
+				t.Fatalf("CollectResponseStream: %v", err)
+			}
+			var gotJSON, wantJSON any
+			if err := json.Unmarshal(got, &gotJSON); err != nil {
+				t.Fatalf("streamed response: %v: %s", err, got)
+			}
+			if err := json.Unmarshal(want, &wantJSON); err != nil {
+				t.Fatalf("buffered response: %v: %s", err, want)
+			}
+			if !reflect.DeepEqual(gotJSON, wantJSON) {
+				t.Errorf("streamed response differs from buffered\n got: %s\nwant: %s", got, want)
+			}
+			if usage == nil || usage.TotalTokens != 19 || usage.ThoughtsTokenCount != 3 {
+				t.Errorf("usage = %+v, want the final chunk's", usage)
+			}
+		})
+	}
+}
+
+func TestCollectResponseStreamUnsupportedFormat(t *testing.T) {
+	if _, _, err := CollectResponseStream(t.Context(), nil, provider.FormatClaude, provider.FormatOpenAI, nil, strings.NewReader(""), "m"); err == nil {
+		t.Fatal("Claude upstream accepted")
+	}
+}
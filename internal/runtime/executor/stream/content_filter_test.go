@@ -0,0 +1,132 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+const geminiSafetyBlocked = `{"candidates":[{"finishReason":"SAFETY","index":0,"safetyRatings":[` +
+	`{"category":"HARM_CATEGORY_HARASSMENT","probability":"HIGH","blocked":true},` +
+	`{"category":"HARM_CATEGORY_HATE_SPEECH","probability":"NEGLIGIBLE"},` +
+	`{"category":"HARM_CATEGORY_SEXUALLY_EXPLICIT","probability":"LOW"}]}],` +
+	`"usageMetadata":{"promptTokenCount":5,"totalTokenCount":5}}`
+
+func contentFilterConfig(enabled bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.ContentFilterResults = enabled
+	return cfg
+}
+
+func TestGeminiSafetyBlockToOpenAIContentFilter(t *testing.T) {
+	out, err := TranslateResponseNonStream(contentFilterConfig(true), provider.FormatGemini, provider.FormatOpenAI, nil, []byte(geminiSafetyBlocked), "gemini-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	choice := gjson.GetBytes(out, "choices.0")
+	if choice.Get("finish_reason").String() != "content_filter" || choice.Get("message.content").Type != gjson.Null {
+		t.Errorf("choice = %s, want content_filter finish with null content", choice.Raw)
+	}
+	cf := choice.Get("content_filter_results")
+	for category, want := range map[string]struct {
+		filtered bool
+		severity string
+	}{
+		"harassment": {true, "high"},
+		"hate":       {false, "safe"},
+		"sexual":     {false, "low"},
+	} {
+		got := cf.Get(category)
+		if got.Get("filtered").Bool() != want.filtered || got.Get("severity").String() != want.severity {
+			t.Errorf("content_filter_results.%s = %s, want filtered=%v severity=%s", category, got.Raw, want.filtered, want.severity)
+		}
+	}
+	if gjson.GetBytes(out, "prompt_filter_results").Exists() {
+		t.Errorf("prompt_filter_results reported without prompt feedback: %s", out)
+	}
+}
+
+func TestGeminiPromptBlockToOpenAIPromptFilter(t *testing.T) {
+	resp := []byte(`{"promptFeedback":{"blockReason":"PROHIBITED_CONTENT"},"usageMetadata":{"promptTokenCount":5,"totalTokenCount":5}}`)
+	out, err := TranslateResponseNonStream(contentFilterConfig(true), provider.FormatGemini, provider.FormatOpenAI, nil, resp, "gemini-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if got := gjson.GetBytes(out, "choices.0.finish_reason").String(); got != "content_filter" {
+		t.Errorf("finish_reason = %q, want content_filter", got)
+	}
+	pf := gjson.GetBytes(out, "prompt_filter_results.0")
+	if pf.Get("prompt_index").Int() != 0 || !pf.Get("content_filter_results.prohibited_content.filtered").Bool() {
+		t.Errorf("prompt_filter_results = %s, want prohibited_content filtered", pf.Raw)
+	}
+}
+
+func TestGeminiContentFilterDisabledByDefault(t *testing.T) {
+	out, err := TranslateResponseNonStream(nil, provider.FormatGemini, provider.FormatOpenAI, nil, []byte(geminiSafetyBlocked), "gemini-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if gjson.GetBytes(out, "choices.0.content_filter_results").Exists() {
+		t.Errorf("content_filter_results reported while disabled: %s", out)
+	}
+	if got := gjson.GetBytes(out, "choices.0.finish_reason").String(); got != "content_filter" {
+		t.Errorf("finish_reason = %q, want content_filter", got)
+	}
+}
+
+func TestGeminiStreamSafetyBlockToOpenAIContentFilter(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		st := NewStreamTranslator(contentFilterConfig(enabled), provider.FormatOpenAI, "openai", "gemini-test", "chatcmpl-gemini-test", nil)
+		events, err := to_ir.ParseGeminiChunkWithStateContext([]byte(geminiSafetyBlocked), st.Ctx.GeminiState, nil)
+		if err != nil {
+			t.Fatalf("ParseGeminiChunk: %v", err)
+		}
+		res, err := st.Translate(events)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		var finish gjson.Result
+		for _, chunk := range res.Chunks {
+			if c := gjson.GetBytes(ir.ExtractSSEData(chunk), "choices.0"); c.Get("finish_reason").Exists() {
+				finish = c
+			}
+		}
+		if finish.Get("finish_reason").String() != "content_filter" {
+			t.Fatalf("enabled=%v: finish chunk = %s, want content_filter", enabled, finish.Raw)
+		}
+		if got := finish.Get("content_filter_results.harassment.filtered").Bool(); got != enabled {
+			t.Errorf("enabled=%v: content_filter_results = %s", enabled, finish.Get("content_filter_results").Raw)
+		}
+	}
+}
+
+func TestOpenAIStreamContentFilterPassthrough(t *testing.T) {
+	line := `{"id":"x","choices":[{"index":0,"delta":{"content":"Hi"},"content_filter_results":{"hate":{"filtered":false,"severity":"safe"}}}],"prompt_filter_results":[{"prompt_index":0,"content_filter_results":{"hate":{"filtered":false,"severity":"safe"}}}]}`
+	st := NewStreamTranslator(nil, provider.FormatOpenAI, "openai", "gpt-test", "chatcmpl-gpt-test", nil)
+	events, err := to_ir.ParseOpenAIChunk([]byte(line))
+	if err != nil {
+		t.Fatalf("ParseOpenAIChunk: %v", err)
+	}
+	res, err := st.Translate(events)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	var found bool
+	for _, chunk := range res.Chunks {
+		data := ir.ExtractSSEData(chunk)
+		if gjson.GetBytes(data, "choices.0.delta.content").String() != "Hi" {
+			continue
+		}
+		found = true
+		if gjson.GetBytes(data, "choices.0.content_filter_results.hate.severity").String() != "safe" || !gjson.GetBytes(data, "prompt_filter_results.0").Exists() {
+			t.Errorf("chunk = %s, want filter results forwarded", data)
+		}
+	}
+	if !found {
+		t.Fatal("content chunk not emitted")
+	}
+}
@@ -0,0 +1,97 @@
+package stream
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// debugThinkingEnv enables reasoning trace logging for thinking-capable models.
+//
+//	DEBUG_THINKING=1         trace every thinking model
+//	DEBUG_THINKING=gemini-3  trace only models whose ID contains "gemini-3"
+const debugThinkingEnv = "DEBUG_THINKING"
+
+// debugThinkingMaxLen bounds how much reasoning text is logged per event.
+const debugThinkingMaxLen = 200
+
+var debugThinkingFilter = sync.OnceValue(func() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(debugThinkingEnv)))
+})
+
+// DebugThinkingEnabled reports whether reasoning traces should be logged for model.
+func DebugThinkingEnabled(model string) bool {
+	if !matchThinkingFilter(debugThinkingFilter(), model) {
+		return false
+	}
+	return util.ModelSupportsThinking(model)
+}
+
+// matchThinkingFilter applies the DEBUG_THINKING value to a model ID.
+// Boolean values toggle tracing for all models; anything else is a substring filter.
+func matchThinkingFilter(filter, model string) bool {
+	switch filter {
+	case "", "0", "false", "off", "no":
+		return false
+	case "1", "true", "on", "yes", "all", "*":
+		return true
+	}
+	return strings.Contains(strings.ToLower(model), filter)
+}
+
+// logThinkingRequest logs the thinking configuration sent upstream.
+func logThinkingRequest(req *ir.UnifiedChatRequest) {
+	if req == nil {
+		return
+	}
+	if req.Thinking == nil {
+		log.Debugf("thinking[%s] request: thinking not configured", req.Model)
+		return
+	}
+	budget := "unset"
+	if req.Thinking.ThinkingBudget != nil {
+		budget = strconv.Itoa(int(*req.Thinking.ThinkingBudget))
+	}
+	log.Debugf("thinking[%s] request: budget=%s include_thoughts=%v effort=%q messages=%d",
+		req.Model, budget, req.Thinking.IncludeThoughts, req.Thinking.Effort, len(req.Messages))
+}
+
+// logThinkingEvents logs reasoning-related IR events emitted by a stream.
+func logThinkingEvents(model string, events []ir.UnifiedEvent) {
+	for i := range events {
+		ev := &events[i]
+		switch ev.Type {
+		case ir.EventTypeReasoning:
+			log.Debugf("thinking[%s] reasoning: %q signature=%d", model, truncateForLog(ev.Reasoning, debugThinkingMaxLen), len(ev.ThoughtSignature))
+		case ir.EventTypeReasoningSummary:
+			log.Debugf("thinking[%s] summary: %q", model, truncateForLog(ev.ReasoningSummary, debugThinkingMaxLen))
+		case ir.EventTypeToken:
+			if ev.Reasoning != "" || len(ev.ThoughtSignature) > 0 {
+				log.Debugf("thinking[%s] token with reasoning: %q signature=%d", model, truncateForLog(ev.Reasoning, debugThinkingMaxLen), len(ev.ThoughtSignature))
+			}
+		case ir.EventTypeFinish:
+			var thoughts int32
+			if ev.Usage != nil {
+				thoughts = ev.Usage.ThoughtsTokenCount
+			}
+			log.Debugf("thinking[%s] finish: reason=%s thoughts_tokens=%d", model, ev.FinishReason, thoughts)
+		}
+	}
+}
+
+// truncateForLog shortens s to at most max runes, marking the cut.
+func truncateForLog(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "...(truncated)"
+}
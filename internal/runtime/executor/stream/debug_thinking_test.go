@@ -0,0 +1,33 @@
+package stream
+
+import "testing"
+
+func TestMatchThinkingFilter(t *testing.T) {
+	cases := []struct {
+		filter string
+		model  string
+		want   bool
+	}{
+		{"", "gemini-3-pro-preview", false},
+		{"0", "gemini-3-pro-preview", false},
+		{"1", "claude-sonnet-4-5-thinking", true},
+		{"true", "gpt-5", true},
+		{"gemini-3", "gemini-3-pro-preview", true},
+		{"gemini-3", "claude-opus-4-5-thinking", false},
+		{"gpt-5", "GPT-5-Codex", true},
+	}
+	for _, tc := range cases {
+		if got := matchThinkingFilter(tc.filter, tc.model); got != tc.want {
+			t.Errorf("matchThinkingFilter(%q, %q) = %v, want %v", tc.filter, tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestTruncateForLog(t *testing.T) {
+	if got := truncateForLog("short", 10); got != "short" {
+		t.Errorf("Expected short string unchanged, got %q", got)
+	}
+	if got := truncateForLog("héllo wörld", 5); got != "héllo...(truncated)" {
+		t.Errorf("Expected rune-safe truncation, got %q", got)
+	}
+}
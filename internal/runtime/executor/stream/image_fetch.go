@@ -0,0 +1,228 @@
+package stream
+
+import (
+	"container/list"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// imageFetcher downloads remote image URLs so they can be sent inline to
+// providers that reject image URLs. Downloads are cached by URL for ttl; once
+// the cached data exceeds cacheMax bytes the least recently used images are
+// evicted.
+type imageFetcher struct {
+	client   *http.Client
+	maxBytes int64
+	types    []string
+	ttl      time.Duration
+	cacheMax int64
+
+	mu        sync.Mutex
+	cache     map[string]*list.Element
+	order     *list.List // front is most recently used
+	cacheSize int64
+}
+
+type fetchedImage struct {
+	url      string
+	mimeType string
+	data     string
+	expires  time.Time
+}
+
+var imageFetch atomic.Pointer[imageFetcher]
+
+// SetImageFetch replaces the remote image fetcher, dropping its cache. A
+// disabled config turns fetching off.
+func SetImageFetch(cfg config.ImageFetchConfig) {
+	if !cfg.Enable {
+		imageFetch.Store(nil)
+		return
+	}
+	imageFetch.Store(newImageFetcher(cfg, false))
+}
+
+func newImageFetcher(cfg config.ImageFetchConfig, allowPrivate bool) *imageFetcher {
+	dialer := &net.Dialer{Timeout: cfg.TimeoutDuration()}
+	if !allowPrivate {
+		dialer.Control = rejectPrivateAddress
+	}
+	return &imageFetcher{
+		client:   &http.Client{Timeout: cfg.TimeoutDuration(), Transport: &http.Transport{DialContext: dialer.DialContext}},
+		maxBytes: cfg.SizeLimit(),
+		types:    cfg.Types(),
+		ttl:      cfg.CacheTTLDuration(),
+		cacheMax: cfg.CacheSizeLimit(),
+		cache:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// rejectPrivateAddress keeps client supplied URLs, including redirects, from
+// reaching loopback, private or link-local addresses.
+func rejectPrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("address %s is not allowed", host)
+	}
+	return nil
+}
+
+// InlineRemoteImages downloads the http and https image URLs of req, including
+// images inside tool results, and replaces them with base64 data. It does
+// nothing unless image-fetch is enabled. A failed download fails the request
+// with a 400 error naming the URL. Downloads end when ctx does.
+func InlineRemoteImages(ctx context.Context, req *ir.UnifiedChatRequest) error {
+	f := imageFetch.Load()
+	if f == nil || req == nil {
+		return nil
+	}
+	for i := range req.Messages {
+		for j := range req.Messages[i].Content {
+			part := &req.Messages[i].Content[j]
+			if err := f.inline(ctx, part.Image); err != nil {
+				return err
+			}
+			if part.ToolResult == nil {
+				continue
+			}
+			for _, img := range part.ToolResult.Images {
+				if err := f.inline(ctx, img); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (f *imageFetcher) inline(ctx context.Context, img *ir.ImagePart) error {
+	if img == nil || img.Data != "" || !isRemoteImageURL(img.URL) {
+		return nil
+	}
+	mimeType, data, err := f.get(ctx, img.URL)
+	if err != nil {
+		return &provider.Error{
+			Code:       "invalid_image_url",
+			Message:    fmt.Sprintf("failed to fetch image %s: %v", img.URL, err),
+			HTTPStatus: http.StatusBadRequest,
+		}
+	}
+	img.MimeType, img.Data, img.URL = mimeType, data, ""
+	return nil
+}
+
+func isRemoteImageURL(u string) bool {
+	return strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://")
+}
+
+func (f *imageFetcher) get(ctx context.Context, rawURL string) (string, string, error) {
+	if f.ttl > 0 {
+		if entry, ok := f.cached(rawURL, time.Now()); ok {
+			return entry.mimeType, entry.data, nil
+		}
+	}
+	mimeType, data, err := f.fetch(ctx, rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	if f.ttl > 0 {
+		f.store(&fetchedImage{url: rawURL, mimeType: mimeType, data: data, expires: time.Now().Add(f.ttl)})
+	}
+	return mimeType, data, nil
+}
+
+// cached returns the unexpired image kept for rawURL and marks it recently used.
+func (f *imageFetcher) cached(rawURL string, now time.Time) (*fetchedImage, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	el, ok := f.cache[rawURL]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*fetchedImage)
+	if now.After(entry.expires) {
+		f.evict(el)
+		return nil, false
+	}
+	f.order.MoveToFront(el)
+	return entry, true
+}
+
+// store keeps entry, evicting the least recently used images until the cache
+// fits in cacheMax. An image larger than cacheMax on its own is not kept.
+func (f *imageFetcher) store(entry *fetchedImage) {
+	size := int64(len(entry.data))
+	if size > f.cacheMax {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if el, ok := f.cache[entry.url]; ok {
+		f.evict(el)
+	}
+	for f.cacheSize+size > f.cacheMax {
+		f.evict(f.order.Back())
+	}
+	f.cache[entry.url] = f.order.PushFront(entry)
+	f.cacheSize += size
+}
+
+func (f *imageFetcher) evict(el *list.Element) {
+	entry := f.order.Remove(el).(*fetchedImage)
+	delete(f.cache, entry.url)
+	f.cacheSize -= int64(len(entry.data))
+}
+
+func (f *imageFetcher) fetch(ctx context.Context, rawURL string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > f.maxBytes {
+		return "", "", fmt.Errorf("image is %d bytes, limit is %d", resp.ContentLength, f.maxBytes)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return "", "", err
+	}
+	if int64(len(body)) > f.maxBytes {
+		return "", "", fmt.Errorf("image exceeds %d bytes", f.maxBytes)
+	}
+	mimeType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+	mimeType = strings.ToLower(mimeType)
+	if !slices.Contains(f.types, mimeType) {
+		return "", "", fmt.Errorf("content type %q is not allowed", mimeType)
+	}
+	return mimeType, base64.StdEncoding.EncodeToString(body), nil
+}
@@ -0,0 +1,175 @@
+package stream
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+var pngBytes = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func useImageFetcher(t *testing.T, cfg config.ImageFetchConfig) {
+	t.Helper()
+	imageFetch.Store(newImageFetcher(cfg, true))
+	t.Cleanup(func() { imageFetch.Store(nil) })
+}
+
+func TestInlineRemoteImages(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/cat.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(pngBytes)
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		case "/big.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	useImageFetcher(t, config.ImageFetchConfig{Enable: true, MaxBytes: 32})
+
+	req := &ir.UnifiedChatRequest{Messages: []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{
+		{Type: ir.ContentTypeImage, Image: &ir.ImagePart{URL: srv.URL + "/cat.png"}},
+		{Type: ir.ContentTypeImage, Image: &ir.ImagePart{URL: "gs://bucket/cat.png"}},
+		{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{Images: []*ir.ImagePart{{URL: srv.URL + "/cat.png"}}}},
+	}}}}
+	if err := InlineRemoteImages(t.Context(), req); err != nil {
+		t.Fatalf("InlineRemoteImages: %v", err)
+	}
+	content := req.Messages[0].Content
+	if img := content[0].Image; img.MimeType != "image/png" || img.Data == "" || img.URL != "" {
+		t.Errorf("fetched image = %+v", img)
+	}
+	if img := content[1].Image; img.URL != "gs://bucket/cat.png" || img.Data != "" {
+		t.Errorf("non-http image was changed: %+v", img)
+	}
+	if img := content[2].ToolResult.Images[0]; img.Data != content[0].Image.Data {
+		t.Errorf("tool result image = %+v", img)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("server hit %d times, want 1 with the cache", n)
+	}
+
+	for _, path := range []string{"/page.html", "/big.png", "/missing.png"} {
+		req := &ir.UnifiedChatRequest{Messages: []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{
+			{Type: ir.ContentTypeImage, Image: &ir.ImagePart{URL: srv.URL + path}},
+		}}}}
+		err := InlineRemoteImages(t.Context(), req)
+		perr, ok := err.(*provider.Error)
+		if !ok || perr.HTTPStatus != http.StatusBadRequest || !strings.Contains(perr.Message, path) {
+			t.Errorf("%s: error = %v, want a 400 naming the URL", path, err)
+		}
+	}
+}
+
+func TestInlineRemoteImagesRejectsPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(pngBytes)
+	}))
+	t.Cleanup(srv.Close)
+	imageFetch.Store(newImageFetcher(config.ImageFetchConfig{Enable: true}, false))
+	t.Cleanup(func() { imageFetch.Store(nil) })
+
+	req := &ir.UnifiedChatRequest{Messages: []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{
+		{Type: ir.ContentTypeImage, Image: &ir.ImagePart{URL: srv.URL + "/cat.png"}},
+	}}}}
+	if err := InlineRemoteImages(t.Context(), req); err == nil {
+		t.Fatal("fetched an image from a loopback address")
+	}
+}
+
+func TestTranslateToClaudeInlinesRemoteImage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(pngBytes)
+	}))
+	t.Cleanup(srv.Close)
+	useImageFetcher(t, config.ImageFetchConfig{Enable: true})
+
+	payload := []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":[{"type":"text","text":"what is this"},{"type":"image_url","image_url":{"url":"` + srv.URL + `/cat.png"}}]}]}`)
+	body, err := TranslateToClaude(t.Context(), nil, provider.FormatOpenAI, "claude-sonnet-4-5", payload, false, nil)
+	if err != nil {
+		t.Fatalf("TranslateToClaude: %v", err)
+	}
+	source := gjson.GetBytes(body, "messages.0.content.1.source")
+	if source.Get("type").String() != "base64" || source.Get("media_type").String() != "image/png" || source.Get("data").String() == "" {
+		t.Errorf("image source = %s", source.Raw)
+	}
+}
+
+func TestImageFetcherCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(pngBytes)
+	}))
+	t.Cleanup(srv.Close)
+	// Room for two images' base64 data.
+	size := int64(base64.StdEncoding.EncodedLen(len(pngBytes)))
+	f := newImageFetcher(config.ImageFetchConfig{Enable: true, CacheMaxBytes: 2 * size}, true)
+
+	get := func(path string) {
+		t.Helper()
+		if _, _, err := f.get(t.Context(), srv.URL+path); err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+	}
+	get("/a.png")
+	get("/b.png")
+	get("/a.png") // a is now more recently used than b
+	get("/c.png") // evicts b
+	if n := hits.Load(); n != 3 {
+		t.Fatalf("server hit %d times, want 3", n)
+	}
+	if f.cacheSize != 2*size || len(f.cache) != 2 {
+		t.Errorf("cache holds %d entries, %d bytes; want 2, %d", len(f.cache), f.cacheSize, 2*size)
+	}
+	get("/a.png")
+	if n := hits.Load(); n != 3 {
+		t.Errorf("a was evicted: server hit %d times", n)
+	}
+	get("/b.png")
+	if n := hits.Load(); n != 4 {
+		t.Errorf("b was kept: server hit %d times", n)
+	}
+}
+
+func TestInlineRemoteImagesStopsWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+	useImageFetcher(t, config.ImageFetchConfig{Enable: true, Timeout: 30})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	req := &ir.UnifiedChatRequest{Messages: []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{
+		{Type: ir.ContentTypeImage, Image: &ir.ImagePart{URL: srv.URL + "/slow.png"}},
+	}}}}
+	start := time.Now()
+	if err := InlineRemoteImages(ctx, req); err == nil {
+		t.Fatal("Expected the download to fail once the request ended")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("download ran %v after the request ended", elapsed)
+	}
+}
@@ -0,0 +1,60 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+type hideThoughtsContextKey struct{}
+
+// WithHiddenThoughts records whether request turns reasoning output off with
+// include_thoughts: false, so streams translated under ctx drop it.
+func WithHiddenThoughts(ctx context.Context, request []byte) context.Context {
+	if !hidesThoughts(request) {
+		return ctx
+	}
+	return context.WithValue(ctx, hideThoughtsContextKey{}, true)
+}
+
+// ThoughtsHiddenFromContext reports whether WithHiddenThoughts turned
+// reasoning output off.
+func ThoughtsHiddenFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	hide, _ := ctx.Value(hideThoughtsContextKey{}).(bool)
+	return hide
+}
+
+// hidesThoughts reports whether request sets include_thoughts to false.
+func hidesThoughts(request []byte) bool {
+	if len(request) == 0 {
+		return false
+	}
+	include := ir.ExtractIncludeThoughts(gjson.ParseBytes(request))
+	return include != nil && !*include
+}
+
+// hiddenThought reports whether event is reasoning the client turned off.
+// It is still counted for usage before being dropped.
+func (s *StreamContext) hiddenThought(event *ir.UnifiedEvent) bool {
+	return s.HideThoughts && (event.Type == ir.EventTypeReasoning || event.Type == ir.EventTypeReasoningSummary)
+}
+
+// withoutThoughts removes reasoning parts from the messages of candidates.
+func withoutThoughts(candidates []ir.CandidateResult) {
+	for i := range candidates {
+		for j := range candidates[i].Messages {
+			msg := &candidates[i].Messages[j]
+			parts := msg.Content[:0]
+			for _, part := range msg.Content {
+				if part.Type != ir.ContentTypeReasoning && part.Type != ir.ContentTypeRedactedThinking {
+					parts = append(parts, part)
+				}
+			}
+			msg.Content = parts
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package stream
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+const claudeThinkingResponse = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"thinking","thinking":"Let me think.","signature":"sig"},{"type":"text","text":"Hello"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":9}}`
+
+func TestIncludeThoughtsNonStream(t *testing.T) {
+	tests := []struct {
+		request       string
+		wantReasoning bool
+	}{
+		{`{"model":"m","messages":[]}`, true},
+		{`{"model":"m","include_thoughts":true,"messages":[]}`, true},
+		{`{"model":"m","include_thoughts":false,"messages":[]}`, false},
+		{`{"model":"m","extra_body":{"include_thoughts":false},"messages":[]}`, false},
+	}
+	for _, tt := range tests {
+		out, err := TranslateResponseNonStream(nil, provider.FormatClaude, provider.FormatOpenAI, []byte(tt.request), []byte(claudeThinkingResponse), "m")
+		if err != nil {
+			t.Fatalf("TranslateResponseNonStream: %v", err)
+		}
+		msg := gjson.GetBytes(out, "choices.0.message")
+		if got := msg.Get("reasoning_content").Exists(); got != tt.wantReasoning {
+			t.Errorf("%s: reasoning_content present = %v, want %v: %s", tt.request, got, tt.wantReasoning, msg.Raw)
+		}
+		if msg.Get("content").String() != "Hello" {
+			t.Errorf("%s: content = %s, want Hello", tt.request, msg.Get("content").Raw)
+		}
+		if gjson.GetBytes(out, "usage.completion_tokens").Int() != 9 {
+			t.Errorf("%s: usage = %s, want the reported 9 output tokens", tt.request, gjson.GetBytes(out, "usage").Raw)
+		}
+	}
+}
+
+func TestIncludeThoughtsStream(t *testing.T) {
+	for _, hide := range []bool{false, true} {
+		st := NewStreamTranslator(nil, provider.FormatClaude, "openai", "m", "id", nil)
+		st.Ctx.HideThoughts = hide
+		res, err := st.Translate([]ir.UnifiedEvent{
+			{Type: ir.EventTypeReasoning, Reasoning: strings.Repeat("thinking ", 30)},
+			{Type: ir.EventTypeToken, Content: "Hello"},
+			{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonStop},
+		})
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		var reasoning, content bool
+		var usage gjson.Result
+		for _, chunk := range res.Chunks {
+			data := strings.TrimPrefix(strings.TrimSpace(string(chunk)), "data: ")
+			delta := gjson.Get(data, "choices.0.delta")
+			reasoning = reasoning || strings.Contains(delta.Raw, "thinking thinking")
+			content = content || delta.Get("content").String() == "Hello"
+			if u := gjson.Get(data, "usage"); u.Exists() {
+				usage = u
+			}
+		}
+		if reasoning == hide {
+			t.Errorf("hide=%v: reasoning streamed = %v", hide, reasoning)
+		}
+		if !content {
+			t.Errorf("hide=%v: content missing", hide)
+		}
+		if usage.Get("completion_tokens_details.reasoning_tokens").Int() == 0 {
+			t.Errorf("hide=%v: usage = %s, want reasoning tokens counted", hide, usage.Raw)
+		}
+	}
+}
+
+func TestHiddenThoughtsContext(t *testing.T) {
+	if !ThoughtsHiddenFromContext(WithHiddenThoughts(context.Background(), []byte(`{"include_thoughts":false}`))) {
+		t.Error("include_thoughts false not recorded")
+	}
+	for _, request := range []string{`{"include_thoughts":true}`, `{"include_thoughts":"false"}`, `{}`, ``} {
+		if ThoughtsHiddenFromContext(WithHiddenThoughts(context.Background(), []byte(request))) {
+			t.Errorf("%q hides thoughts", request)
+		}
+	}
+}
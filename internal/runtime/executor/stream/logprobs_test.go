@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestClaudeStreamLogprobsToOpenAI(t *testing.T) {
+	lines := []string{
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello","logprobs":{"content":[{"token":"Hello","logprob":-0.1,"top_logprobs":[{"token":"Hi","logprob":-2.3}]}]}}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" world","logprobs":[{"token":" world","logprob":-0.4}]}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"!"}}`,
+	}
+	state := ir.NewClaudeStreamParserState()
+	st := NewStreamTranslator(nil, provider.FormatClaude, "openai", "claude-test", "id", nil)
+
+	var chunks [][]byte
+	for _, line := range lines {
+		events, err := to_ir.ParseClaudeChunkWithState([]byte(line), state)
+		if err != nil {
+			t.Fatalf("ParseClaudeChunkWithState: %v", err)
+		}
+		res, err := st.Translate(events)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		chunks = append(chunks, res.Chunks...)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+
+	want := []struct {
+		token   string
+		logprob float64
+	}{{"Hello", -0.1}, {" world", -0.4}}
+	for i, w := range want {
+		lp := gjson.GetBytes(ir.ExtractSSEData(chunks[i]), "choices.0.logprobs.content")
+		if got := lp.Get("0.token").String(); got != w.token || lp.Get("0.logprob").Float() != w.logprob {
+			t.Errorf("chunk %d logprobs = %s, want %q at %v", i, lp.Raw, w.token, w.logprob)
+		}
+	}
+	if got := gjson.GetBytes(ir.ExtractSSEData(chunks[0]), "choices.0.logprobs.content.0.top_logprobs.0.token").String(); got != "Hi" {
+		t.Errorf("top_logprobs token = %q, want Hi", got)
+	}
+	if gjson.GetBytes(ir.ExtractSSEData(chunks[2]), "choices.0.logprobs").Exists() {
+		t.Errorf("chunk without logprobs reported some: %s", chunks[2])
+	}
+}
+
+func TestClaudeResponseLogprobsToOpenAI(t *testing.T) {
+	resp := []byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":1},"logprobs":{"content":[{"token":"Hi","logprob":-0.05}]}}`)
+	out, err := TranslateResponseNonStream(nil, provider.FormatClaude, provider.FormatOpenAI, nil, resp, "claude-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if got := gjson.GetBytes(out, "choices.0.logprobs.content.0.token").String(); got != "Hi" {
+		t.Errorf("logprobs not carried over: %s", out)
+	}
+}
+
+func TestClaudeResponseToResponsesAPIInclude(t *testing.T) {
+	resp := []byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"thinking","thinking":"hmm","signature":"sig"},{"type":"text","text":"Hi"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":1},"logprobs":{"content":[{"token":"Hi","logprob":-0.05}]}}`)
+	format := provider.FromString("openai-response")
+
+	out, err := TranslateResponseNonStream(nil, provider.FormatClaude, format, []byte(`{"input":"Hi"}`), resp, "claude-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if gjson.GetBytes(out, `output.#(type=="reasoning").encrypted_content`).Exists() || gjson.GetBytes(out, `output.#(type=="message").content.0.logprobs`).Exists() {
+		t.Errorf("extras returned without include: %s", out)
+	}
+
+	request := []byte(`{"input":"Hi","include":["reasoning.encrypted_content","message.output_text.logprobs"]}`)
+	out, err = TranslateResponseNonStream(nil, provider.FormatClaude, format, request, resp, "claude-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if got := gjson.GetBytes(out, `output.#(type=="reasoning").encrypted_content`).String(); got != "sig" {
+		t.Errorf("encrypted_content = %q, want sig: %s", got, out)
+	}
+	if got := gjson.GetBytes(out, `output.#(type=="message").content.0.logprobs.0.token`).String(); got != "Hi" {
+		t.Errorf("logprobs not carried over: %s", out)
+	}
+}
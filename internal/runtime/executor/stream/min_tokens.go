@@ -0,0 +1,51 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+type minTokensContextKey struct{}
+
+// WithMinTokens records the minimum output the client asked for so streams
+// translated under ctx can flag responses that stop short of it.
+func WithMinTokens(ctx context.Context, minTokens int) context.Context {
+	if minTokens <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, minTokensContextKey{}, minTokens)
+}
+
+// MinTokensFromContext returns the minimum output recorded by WithMinTokens, or 0.
+func MinTokensFromContext(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+	n, _ := ctx.Value(minTokensContextKey{}).(int)
+	return n
+}
+
+// outputTokens returns the completion tokens reported on the finish event,
+// excluding reasoning, or an estimate from the streamed text.
+func (s *StreamContext) outputTokens(usage *ir.Usage) int64 {
+	if usage != nil && usage.CompletionTokens > 0 {
+		if tokens := usage.CompletionTokens - int64(usage.ThoughtsTokenCount); tokens > 0 {
+			return tokens
+		}
+	}
+	return int64(s.ContentCharsAccum / 4)
+}
+
+// belowMinTokens reports whether a normal stop ended the output before
+// MinTokens. Other finish reasons are left alone: a length stop already tells
+// the client the output was cut, and tool calls are not final output.
+func (s *StreamContext) belowMinTokens(event *ir.UnifiedEvent) bool {
+	if s.MinTokens <= 0 {
+		return false
+	}
+	if event.FinishReason != ir.FinishReasonStop && event.FinishReason != ir.FinishReasonStopSequence {
+		return false
+	}
+	return s.outputTokens(event.Usage) < int64(s.MinTokens)
+}
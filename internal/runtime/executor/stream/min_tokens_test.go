@@ -0,0 +1,50 @@
+package stream
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func translateFinish(t *testing.T, minTokens int, text string, reason ir.FinishReason, usage *ir.Usage) string {
+	t.Helper()
+	st := NewStreamTranslator(nil, provider.FormatClaude, "openai", "m", "id", nil)
+	st.Ctx.MinTokens = minTokens
+	res, err := st.Translate([]ir.UnifiedEvent{
+		{Type: ir.EventTypeToken, Content: text},
+		{Type: ir.EventTypeFinish, FinishReason: reason, Usage: usage},
+	})
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	return string(res.Chunks[len(res.Chunks)-1])
+}
+
+func TestMinTokensFinishReason(t *testing.T) {
+	short := strings.Repeat("word ", 10)
+	if got := translateFinish(t, 100, short, ir.FinishReasonStop, nil); !strings.Contains(got, `"finish_reason":"min_tokens"`) {
+		t.Errorf("short output finish chunk = %s, want min_tokens", got)
+	}
+	if got := translateFinish(t, 100, short, ir.FinishReasonStop, &ir.Usage{CompletionTokens: 150}); !strings.Contains(got, `"finish_reason":"stop"`) {
+		t.Errorf("reported usage above the minimum: %s", got)
+	}
+	if got := translateFinish(t, 100, short, ir.FinishReasonMaxTokens, nil); !strings.Contains(got, `"finish_reason":"length"`) {
+		t.Errorf("length stop was rewritten: %s", got)
+	}
+	if got := translateFinish(t, 0, short, ir.FinishReasonStop, nil); !strings.Contains(got, `"finish_reason":"stop"`) {
+		t.Errorf("finish rewritten without min_tokens: %s", got)
+	}
+}
+
+func TestMinTokensContext(t *testing.T) {
+	ctx := WithMinTokens(context.Background(), 50)
+	if got := MinTokensFromContext(ctx); got != 50 {
+		t.Errorf("MinTokensFromContext = %d, want 50", got)
+	}
+	if got := MinTokensFromContext(WithMinTokens(context.Background(), 0)); got != 0 {
+		t.Errorf("MinTokensFromContext with 0 = %d", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
 )
 
 // =============================================================================
@@ -19,6 +20,7 @@ type ResponseTranslator struct {
 	to        string
 	model     string
 	messageID string
+	include   []string // Responses API "include" list of the request
 }
 
 // NewResponseTranslator creates a translator for non-streaming responses.
@@ -32,13 +34,45 @@ func NewResponseTranslator(cfg *config.Config, to, model string) *ResponseTransl
 }
 
 func generateMessageID(to, model string) string {
+	return messageIDPrefix(to) + model
+}
+
+func messageIDPrefix(to string) string {
 	switch to {
 	case "codex", "openai-response":
-		return "resp-" + model
+		return "resp-"
 	case "claude":
-		return "msg-" + model
+		return "msg-"
 	default:
-		return "chatcmpl-" + model
+		return "chatcmpl-"
+	}
+}
+
+// upstreamMessageID marks an upstream response ID so clients can tell it
+// from IDs generated by llm-mux.
+func upstreamMessageID(to, responseID string) string {
+	return messageIDPrefix(to) + "upstream-" + responseID
+}
+
+// upstreamResponseIDs reports whether upstream response IDs and creation
+// times are reused in translated responses.
+func upstreamResponseIDs(cfg *config.Config) bool {
+	return cfg != nil && cfg.UpstreamResponseIDs
+}
+
+// applyUpstreamResponseID marks the upstream response ID of meta when
+// upstream-response-ids is enabled, and otherwise clears it and the creation
+// time so the response gets generated values.
+func applyUpstreamResponseID(cfg *config.Config, to string, meta *ir.OpenAIMeta) {
+	if meta == nil {
+		return
+	}
+	if !upstreamResponseIDs(cfg) {
+		meta.ResponseID, meta.CreateTime = "", 0
+		return
+	}
+	if meta.ResponseID != "" {
+		meta.ResponseID = upstreamMessageID(to, meta.ResponseID)
 	}
 }
 
@@ -64,7 +98,15 @@ func (t *ResponseTranslator) Translate(candidates []ir.CandidateResult, usage *i
 	case provider.IsGeminiFormat(t.to):
 		return from_ir.ToGeminiResponseMeta(messages, usage, t.model, meta)
 	case t.to == "codex" || t.to == "openai-response":
-		return from_ir.ToResponsesAPIResponse(messages, usage, t.model, meta)
+		if len(candidates) > 0 && candidates[0].Logprobs != nil {
+			withLogprobs := ir.OpenAIMeta{}
+			if meta != nil {
+				withLogprobs = *meta
+			}
+			withLogprobs.Logprobs = candidates[0].Logprobs
+			meta = &withLogprobs
+		}
+		return from_ir.ToResponsesAPIResponse(messages, usage, t.model, meta, t.include)
 	default:
 		return nil, nil
 	}
@@ -98,7 +140,7 @@ func parseClaudeResponse(response []byte) (*ParsedResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	candidates := []ir.CandidateResult{{Index: 0, Messages: messages, FinishReason: ir.FinishReasonStop}}
+	candidates := []ir.CandidateResult{{Index: 0, Messages: messages, FinishReason: ir.FinishReasonStop, Logprobs: ir.ParseClaudeLogprobs(gjson.GetBytes(response, "logprobs"))}}
 	return &ParsedResponse{Candidates: candidates, Usage: usage}, nil
 }
 
@@ -130,12 +172,17 @@ func parseSourceResponse(from string, response []byte) (*ParsedResponse, error)
 // =============================================================================
 
 // TranslateResponseNonStream is the unified entry point for non-streaming response translation.
-func TranslateResponseNonStream(cfg *config.Config, from, to provider.Format, response []byte, model string) ([]byte, error) {
+// request is the client request in the to format; it selects optional output
+// such as the Responses API "include" list or include_thoughts and may be nil.
+func TranslateResponseNonStream(cfg *config.Config, from, to provider.Format, request, response []byte, model string) ([]byte, error) {
 	fromStr := from.String()
 	toStr := to.String()
 
 	// Handle passthrough cases
 	if passthrough := handlePassthrough(fromStr, toStr, response); passthrough != nil {
+		if estimateMissingUsage(cfg) {
+			passthrough = withEstimatedUsage(to, model, request, passthrough)
+		}
 		return passthrough, nil
 	}
 
@@ -147,12 +194,50 @@ func TranslateResponseNonStream(cfg *config.Config, from, to provider.Format, re
 	if parsed == nil {
 		return response, nil
 	}
+	return translateParsed(cfg, from, to, request, model, parsed)
+}
+
+// translateParsed converts a response parsed from the from format to the to
+// format, applying the response options of cfg and request.
+func translateParsed(cfg *config.Config, from, to provider.Format, request []byte, model string, parsed *ParsedResponse) ([]byte, error) {
+	fromStr := from.String()
+	toStr := to.String()
+
+	if provider.IsGeminiFormat(fromStr) && !contentFilterResults(cfg) {
+		for i := range parsed.Candidates {
+			parsed.Candidates[i].ContentFilter = nil
+		}
+		if parsed.Meta != nil {
+			parsed.Meta.PromptFilter = nil
+		}
+	}
+
+	applyUpstreamResponseID(cfg, toStr, parsed.Meta)
+	if estimateMissingUsage(cfg) && usageMissing(parsed.Usage) {
+		output, reasoning := candidateOutput(parsed.Candidates)
+		parsed.Usage = estimateUsage(to, model, request, output, reasoning)
+	}
+
+	if hidesThoughts(request) {
+		withoutThoughts(parsed.Candidates)
+	}
 
 	// Convert IR to target format
 	translator := NewResponseTranslator(cfg, toStr, model)
+	if toStr == "codex" || toStr == "openai-response" {
+		for _, v := range gjson.GetBytes(request, "include").Array() {
+			translator.include = append(translator.include, v.String())
+		}
+	}
 	return translator.Translate(parsed.Candidates, parsed.Usage, parsed.Meta)
 }
 
+// contentFilterResults reports whether Gemini safety ratings are reported as
+// OpenAI content filter results.
+func contentFilterResults(cfg *config.Config) bool {
+	return cfg != nil && cfg.ContentFilterResults
+}
+
 // handlePassthrough returns response bytes if passthrough is needed, nil otherwise.
 func handlePassthrough(from, to string, response []byte) []byte {
 	switch {
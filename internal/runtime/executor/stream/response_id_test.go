@@ -0,0 +1,86 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+const geminiWithResponseID = `{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP","index":0}],` +
+	`"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":1,"totalTokenCount":4},` +
+	`"responseId":"abc123","createTime":"2025-06-01T12:00:00.5Z"}`
+
+// createdAt is createTime of geminiWithResponseID in Unix seconds.
+const createdAt = 1748779200
+
+func upstreamIDConfig(enabled bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.UpstreamResponseIDs = enabled
+	return cfg
+}
+
+func TestGeminiResponseIDPassthrough(t *testing.T) {
+	out, err := TranslateResponseNonStream(upstreamIDConfig(true), provider.FormatGemini, provider.FormatOpenAI, nil, []byte(geminiWithResponseID), "gemini-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if got := gjson.GetBytes(out, "id").String(); got != "chatcmpl-upstream-abc123" {
+		t.Errorf("id = %q, want chatcmpl-upstream-abc123", got)
+	}
+	if got := gjson.GetBytes(out, "created").Int(); got != createdAt {
+		t.Errorf("created = %d, want %d", got, createdAt)
+	}
+}
+
+func TestGeminiResponseIDGeneratedByDefault(t *testing.T) {
+	out, err := TranslateResponseNonStream(nil, provider.FormatGemini, provider.FormatOpenAI, nil, []byte(geminiWithResponseID), "gemini-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if got := gjson.GetBytes(out, "id").String(); strings.Contains(got, "abc123") {
+		t.Errorf("id = %q, want a generated id", got)
+	}
+	if got := gjson.GetBytes(out, "created").Int(); got == createdAt {
+		t.Errorf("created = %d, want the current time", got)
+	}
+
+	// Without a responseId the generated values are used even when enabled.
+	noID := strings.Replace(geminiWithResponseID, `"responseId":"abc123",`, "", 1)
+	out, err = TranslateResponseNonStream(upstreamIDConfig(true), provider.FormatGemini, provider.FormatOpenAI, nil, []byte(noID), "gemini-test")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if got := gjson.GetBytes(out, "id").String(); got != "chatcmpl-gemini-test" {
+		t.Errorf("id without responseId = %q, want generated chatcmpl-gemini-test", got)
+	}
+}
+
+func TestGeminiStreamResponseIDPassthrough(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		p := NewGeminiStreamProcessor(upstreamIDConfig(enabled), provider.FormatOpenAI, "gemini-test", "chatcmpl-gemini-test", nil)
+		chunks, _, err := p.ProcessLine([]byte("data: " + geminiWithResponseID))
+		if err != nil {
+			t.Fatalf("ProcessLine: %v", err)
+		}
+		if len(chunks) == 0 {
+			t.Fatal("no chunks")
+		}
+		wantID, wantCreated := "chatcmpl-gemini-test", false
+		if enabled {
+			wantID, wantCreated = "chatcmpl-upstream-abc123", true
+		}
+		for _, chunk := range chunks {
+			data := ir.ExtractSSEData(chunk)
+			if got := gjson.GetBytes(data, "id").String(); got != wantID {
+				t.Errorf("enabled=%v: chunk id = %q, want %q", enabled, got, wantID)
+			}
+			if got := gjson.GetBytes(data, "created").Int() == createdAt; got != wantCreated {
+				t.Errorf("enabled=%v: chunk created = %d", enabled, gjson.GetBytes(data, "created").Int())
+			}
+		}
+	}
+}
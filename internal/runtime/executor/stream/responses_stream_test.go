@@ -0,0 +1,28 @@
+package stream
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestStreamToResponsesAPI(t *testing.T) {
+	text := `{"id":"x","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`
+	finish := `{"id":"x","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`
+
+	st := NewStreamTranslator(nil, provider.FormatOpenAI, "openai-response", "gpt-4o", "resp-gpt-4o", nil)
+	out := string(bytes.Join(translateOpenAIStream(t, st, text, finish, `[DONE]`), nil))
+	if out == "" {
+		t.Fatal("expected Responses API events, got no output")
+	}
+	for _, want := range []string{"event: response.created", "event: response.output_text.delta", `"delta":"Hello"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "response.created") > strings.Index(out, "response.output_text.delta") {
+		t.Errorf("response.created must precede the first text delta:\n%s", out)
+	}
+}
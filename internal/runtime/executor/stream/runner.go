@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"fmt"
 	"io"
 	"sync"
 	"time"
@@ -14,6 +13,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/sseutil"
 	"github.com/nghyane/llm-mux/internal/streamutil"
+	"github.com/nghyane/llm-mux/internal/telemetry"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/tidwall/gjson"
@@ -23,6 +23,8 @@ import (
 type UsageReporter interface {
 	Publish(ctx context.Context, u *ir.Usage)
 	PublishFailure(ctx context.Context)
+	PublishPartialFailure(ctx context.Context, u *ir.Usage)
+	PublishCanceled(ctx context.Context, u *ir.Usage)
 	EnsurePublished(ctx context.Context)
 }
 
@@ -49,6 +51,11 @@ type StreamProcessor interface {
 	ProcessDone() (chunks [][]byte, err error)
 }
 
+// TranslatorProvider is implemented by stream processors backed by a StreamTranslator.
+type TranslatorProvider interface {
+	StreamTranslator() *StreamTranslator
+}
+
 type StreamPreprocessor func(line []byte) (payload []byte, skip bool)
 
 type StreamConfig struct {
@@ -134,17 +141,22 @@ func RunSSEStream(
 	processor StreamProcessor,
 	cfg StreamConfig,
 ) <-chan provider.StreamChunk {
+	if tp, ok := processor.(TranslatorProvider); ok && tp.StreamTranslator() != nil {
+		tp.StreamTranslator().Ctx.MinTokens = MinTokensFromContext(ctx)
+		tp.StreamTranslator().Ctx.HideThoughts = ThoughtsHiddenFromContext(ctx)
+		tp.StreamTranslator().EnableUsageEstimate(UsageRequestFromContext(ctx))
+	}
 	pipeline := streamutil.NewPipeline(ctx, streamutil.PipelineConfig{
 		BufferSize: 128,
 		OnError: func(err error) {
-			log.Errorf("%s: stream error: %v", cfg.ExecutorName, err)
+			log.WithContext(ctx).Errorf("%s: stream error: %v", cfg.ExecutorName, err)
 		},
 	})
 
 	pipeline.Go(func(ctx context.Context) error {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Errorf("%s: panic in stream goroutine: %v", cfg.ExecutorName, r)
+				log.WithContext(ctx).Errorf("%s: panic in stream goroutine: %v", cfg.ExecutorName, r)
 			}
 		}()
 
@@ -166,14 +178,63 @@ func RunSSEStream(
 		}
 		scanner.Buffer(*bufPtr, maxBufferSize)
 
+		capture := ThinkingCaptureFromContext(ctx)
+		if !capture.active() {
+			capture = nil
+		} else if tp, ok := processor.(TranslatorProvider); ok && tp.StreamTranslator() != nil {
+			tp.StreamTranslator().SetThinkingCapture(capture)
+		}
+		if sigs := ThoughtSignaturesFromContext(ctx); sigs != nil {
+			if tp, ok := processor.(TranslatorProvider); ok && tp.StreamTranslator() != nil {
+				tp.StreamTranslator().SetThoughtSignatures(sigs)
+			}
+		}
+
+		// fail records the partial result and surfaces err as a stream error so
+		// the manager and handlers can account for the interrupted request.
+		// Buffered output is flushed first, without finishing the stream.
+		// canceled records the usage of a stream abandoned by the client, or
+		// cut off by the request timeout. The upstream body is closed by the
+		// stream reader once ctx is done.
+		canceled := func() {
+			if reporter == nil {
+				return
+			}
+			if provider.RequestTimedOut(ctx) {
+				reporter.PublishPartialFailure(context.WithoutCancel(ctx), partialUsage(processor))
+				return
+			}
+			reporter.PublishCanceled(ctx, partialUsage(processor))
+		}
+
+		fail := func(err error) {
+			if tp, ok := processor.(TranslatorProvider); ok && tp.StreamTranslator() != nil {
+				flushed, _ := tp.StreamTranslator().Flush()
+				for _, chunk := range flushed {
+					if !pipeline.SendData(chunk) {
+						canceled()
+						return
+					}
+				}
+			}
+			if reporter != nil {
+				reporter.PublishPartialFailure(ctx, partialUsage(processor))
+			}
+			pipeline.SendError(err)
+		}
+
 		for scanner.Scan() {
 			select {
 			case <-ctx.Done():
+				canceled()
 				return nil
 			default:
 			}
 
 			line := scanner.Bytes()
+			if capture != nil {
+				capture.recordRaw(line)
+			}
 
 			if IsDoneLine(line) {
 				if cfg.SkipDoneInData {
@@ -182,14 +243,12 @@ func RunSSEStream(
 				if cfg.HandleDoneSignal && processor != nil {
 					doneChunks, doneErr := processor.ProcessDone()
 					if doneErr != nil {
-						if reporter != nil {
-							reporter.PublishFailure(ctx)
-						}
-						pipeline.SendError(doneErr)
+						fail(doneErr)
 						return nil
 					}
 					for _, chunk := range doneChunks {
 						if !pipeline.SendData(chunk) {
+							canceled()
 							return nil
 						}
 					}
@@ -212,20 +271,7 @@ func RunSSEStream(
 
 			chunks, usage, err := processor.ProcessLine(payload)
 			if err != nil {
-				if reporter != nil {
-					reporter.PublishFailure(ctx)
-				}
-				if processor != nil {
-					if flushed, _ := processor.ProcessDone(); len(flushed) > 0 {
-						for _, chunk := range flushed {
-							if !pipeline.SendData(chunk) {
-								return nil
-							}
-						}
-					}
-				}
-				errorJSON := fmt.Sprintf(`data: {"error": {"message": "%s", "type": "server_error"}}`+"\n\n", err.Error())
-				pipeline.SendData([]byte(errorJSON))
+				fail(err)
 				return nil
 			}
 
@@ -236,39 +282,44 @@ func RunSSEStream(
 			if len(chunks) > 0 {
 				for _, chunk := range chunks {
 					if !pipeline.SendData(chunk) {
+						canceled()
 						return nil
 					}
 				}
 			} else if cfg.PassthroughOnEmpty {
 				if !pipeline.SendData(payload) {
+					canceled()
 					return nil
 				}
 			}
 		}
 
+		if ctx.Err() != nil {
+			canceled()
+			return nil
+		}
+
+		if errScan := scanner.Err(); errScan != nil {
+			fail(errScan)
+			return nil
+		}
+
 		if processor != nil {
 			doneChunks, doneErr := processor.ProcessDone()
 			if doneErr != nil {
-				if reporter != nil {
-					reporter.PublishFailure(ctx)
-				}
-				pipeline.SendError(doneErr)
+				fail(doneErr)
 				return nil
 			}
 			for _, chunk := range doneChunks {
 				if !pipeline.SendData(chunk) {
+					canceled()
 					return nil
 				}
 			}
 		}
 
-		if errScan := scanner.Err(); errScan != nil {
-			if reporter != nil {
-				reporter.PublishFailure(ctx)
-			}
-			errorJSON := fmt.Sprintf(`data: {"error": {"message": "%s", "type": "server_error"}}`+"\n\n", errScan.Error())
-			pipeline.SendData([]byte(errorJSON))
-			return nil
+		if tp, ok := processor.(TranslatorProvider); ok && tp.StreamTranslator() != nil {
+			telemetry.RecordFinishReason(ctx, string(tp.StreamTranslator().Ctx.FinishReason))
 		}
 
 		if cfg.EnsurePublished && reporter != nil {
@@ -282,6 +333,15 @@ func RunSSEStream(
 	return ConvertPipelineToStreamChunk(ctx, pipeline.Output())
 }
 
+// partialUsage returns the usage estimated from output translated so far.
+func partialUsage(processor StreamProcessor) *ir.Usage {
+	tp, ok := processor.(TranslatorProvider)
+	if !ok || tp.StreamTranslator() == nil {
+		return nil
+	}
+	return tp.StreamTranslator().Ctx.PartialUsage()
+}
+
 type SimpleStreamProcessor struct {
 	ProcessFunc func(line []byte) (chunks [][]byte, usage *ir.Usage, err error)
 }
@@ -344,6 +404,10 @@ func (p *OpenAIStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, e
 	return result.Chunks, result.Usage, nil
 }
 
+func (p *OpenAIStreamProcessor) StreamTranslator() *StreamTranslator {
+	return p.translator
+}
+
 func (p *OpenAIStreamProcessor) ProcessDone() ([][]byte, error) {
 	events, _ := to_ir.ParseOpenAIChunk([]byte("[DONE]"))
 	if len(events) == 0 {
@@ -378,6 +442,7 @@ func (p *GeminiStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, e
 	if err != nil {
 		return nil, nil, err
 	}
+	p.translator.adoptUpstreamResponseID()
 	if len(events) == 0 {
 		return nil, nil, nil
 	}
@@ -389,6 +454,10 @@ func (p *GeminiStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, e
 	return result.Chunks, result.Usage, nil
 }
 
+func (p *GeminiStreamProcessor) StreamTranslator() *StreamTranslator {
+	return p.translator
+}
+
 func (p *GeminiStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush()
 }
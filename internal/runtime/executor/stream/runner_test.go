@@ -0,0 +1,136 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+type recordingReporter struct {
+	failed   bool
+	canceled bool
+	partial  *ir.Usage
+	// published, when set, is closed once a canceled record is published.
+	published chan struct{}
+}
+
+func (r *recordingReporter) Publish(context.Context, *ir.Usage) {}
+func (r *recordingReporter) PublishFailure(context.Context)     { r.failed = true }
+func (r *recordingReporter) PublishPartialFailure(_ context.Context, u *ir.Usage) {
+	r.failed = true
+	r.partial = u
+}
+func (r *recordingReporter) PublishCanceled(_ context.Context, u *ir.Usage) {
+	r.canceled = true
+	r.partial = u
+	if r.published != nil {
+		close(r.published)
+	}
+}
+func (r *recordingReporter) EnsurePublished(context.Context) {}
+
+// closeTracker records whether the upstream body was closed.
+type closeTracker struct {
+	io.Reader
+	closer io.Closer
+	closed atomic.Bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed.Store(true)
+	return c.closer.Close()
+}
+
+type failingReader struct{ err error }
+
+func (f failingReader) Read([]byte) (int, error) { return 0, f.err }
+
+func TestRunSSEStream_MidStreamErrorSurfacesAsStreamError(t *testing.T) {
+	upstream := strings.Repeat(`data: {"id":"c1","model":"m","choices":[{"index":0,"delta":{"content":"hello world "}}]}`+"\n\n", 3)
+	upstreamErr := errors.New("connection reset by peer")
+	body := io.NopCloser(io.MultiReader(strings.NewReader(upstream), failingReader{err: upstreamErr}))
+
+	reporter := &recordingReporter{}
+	processor := NewOpenAIStreamProcessor(nil, provider.FromString("openai"), "m", "c1")
+	out := RunSSEStream(context.Background(), body, reporter, processor, StreamConfig{
+		ExecutorName: "test",
+		Preprocessor: DataTagPreprocessor(),
+	})
+
+	var payloads int
+	var streamErr error
+	for chunk := range out {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+			continue
+		}
+		if strings.Contains(string(chunk.Payload), `"finish_reason"`) {
+			t.Errorf("Expected no finish chunk before the error, got %s", chunk.Payload)
+		}
+		payloads++
+	}
+
+	if !errors.Is(streamErr, upstreamErr) {
+		t.Fatalf("Expected upstream error as a stream error, got %v", streamErr)
+	}
+	if payloads == 0 {
+		t.Error("Expected output emitted before the error to be delivered")
+	}
+	if !reporter.failed || reporter.partial == nil || reporter.partial.CompletionTokens == 0 {
+		t.Errorf("Expected a failed record with partial usage, got failed=%v usage=%+v", reporter.failed, reporter.partial)
+	}
+}
+
+func TestRunSSEStream_ClientDisconnectRecordsPartialUsage(t *testing.T) {
+	pr, pw := io.Pipe()
+	body := &closeTracker{Reader: pr, closer: pr}
+	go func() {
+		// Send some output, then keep the upstream open as if still generating.
+		chunk := `data: {"id":"c1","model":"m","choices":[{"index":0,"delta":{"content":"hello world "}}]}` + "\n\n"
+		_, _ = pw.Write([]byte(strings.Repeat(chunk, 3)))
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reporter := &recordingReporter{published: make(chan struct{})}
+	processor := NewOpenAIStreamProcessor(nil, provider.FromString("openai"), "m", "c1")
+	out := RunSSEStream(ctx, body, reporter, processor, StreamConfig{
+		ExecutorName: "test",
+		Preprocessor: DataTagPreprocessor(),
+	})
+
+	var payloads int
+	for chunk := range out {
+		if chunk.Err != nil {
+			t.Errorf("Expected no stream error after a client disconnect, got %v", chunk.Err)
+			continue
+		}
+		payloads++
+		if payloads == 3 {
+			cancel() // the client went away
+		}
+	}
+
+	// The record is published asynchronously once the worker notices.
+	select {
+	case <-reporter.published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a canceled record to be published after the client disconnected")
+	}
+	if !body.closed.Load() {
+		t.Error("Expected the upstream body to be closed after the client disconnected")
+	}
+	if reporter.failed {
+		t.Error("Expected a client disconnect not to be recorded as a failure")
+	}
+	if !reporter.canceled || reporter.partial == nil || reporter.partial.CompletionTokens == 0 {
+		t.Errorf("Expected a canceled record with partial usage, got canceled=%v usage=%+v", reporter.canceled, reporter.partial)
+	}
+}
@@ -0,0 +1,280 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/util"
+)
+
+// ThinkingCaptureMetadataKey carries the *ThinkingCapture through request
+// metadata to request translation, which has no access to the context.
+const ThinkingCaptureMetadataKey = "__thinking_capture"
+
+const (
+	// maxCapturedEntries bounds raw SSE lines and parsed events kept per trace.
+	maxCapturedEntries = 2000
+	// maxCapturedBytes bounds the size of each captured payload.
+	maxCapturedBytes = 16 * 1024
+)
+
+// ThinkingTrace is a captured exchange with a thinking-capable model.
+type ThinkingTrace struct {
+	RequestID string          `json:"request_id"`
+	Model     string          `json:"model"`
+	StartedAt time.Time       `json:"started_at"`
+	Request   string          `json:"request"`
+	RawSSE    []string        `json:"raw_sse"`
+	Events    []CapturedEvent `json:"events"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// CapturedEvent is the loggable subset of an IR stream event.
+type CapturedEvent struct {
+	Type             string `json:"type"`
+	Content          string `json:"content,omitempty"`
+	Reasoning        string `json:"reasoning,omitempty"`
+	ReasoningSummary string `json:"reasoning_summary,omitempty"`
+	SignatureBytes   int    `json:"signature_bytes,omitempty"`
+	FinishReason     string `json:"finish_reason,omitempty"`
+	ThoughtsTokens   int32  `json:"thoughts_tokens,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// ThinkingTraceBuffer keeps the most recent traces in a fixed-size ring.
+type ThinkingTraceBuffer struct {
+	mu     sync.RWMutex
+	ring   []string
+	next   int
+	traces map[string]*ThinkingTrace
+}
+
+// NewThinkingTraceBuffer creates a buffer holding at most size traces.
+func NewThinkingTraceBuffer(size int) *ThinkingTraceBuffer {
+	if size <= 0 {
+		return nil
+	}
+	return &ThinkingTraceBuffer{
+		ring:   make([]string, size),
+		traces: make(map[string]*ThinkingTrace, size),
+	}
+}
+
+// Get returns a copy of the trace recorded for requestID.
+func (b *ThinkingTraceBuffer) Get(requestID string) (*ThinkingTrace, bool) {
+	if b == nil {
+		return nil, false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	trace, ok := b.traces[requestID]
+	if !ok {
+		return nil, false
+	}
+	cp := *trace
+	cp.RawSSE = append([]string(nil), trace.RawSSE...)
+	cp.Events = append([]CapturedEvent(nil), trace.Events...)
+	return &cp, true
+}
+
+// IDs returns the request IDs currently held, oldest first.
+func (b *ThinkingTraceBuffer) IDs() []string {
+	if b == nil {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	ids := make([]string, 0, len(b.traces))
+	for i := range b.ring {
+		if id := b.ring[(b.next+i)%len(b.ring)]; id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (b *ThinkingTraceBuffer) add(trace *ThinkingTrace) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, exists := b.traces[trace.RequestID]; exists {
+		b.traces[trace.RequestID] = trace
+		return
+	}
+	if evicted := b.ring[b.next]; evicted != "" {
+		delete(b.traces, evicted)
+	}
+	b.ring[b.next] = trace.RequestID
+	b.next = (b.next + 1) % len(b.ring)
+	b.traces[trace.RequestID] = trace
+}
+
+var (
+	thinkingTracesMu sync.RWMutex
+	thinkingTraces   *ThinkingTraceBuffer
+)
+
+// SetThinkingCaptureSize resizes the global trace buffer. A size of zero
+// disables capture and drops all stored traces.
+func SetThinkingCaptureSize(size int) {
+	thinkingTracesMu.Lock()
+	defer thinkingTracesMu.Unlock()
+	if thinkingTraces != nil && len(thinkingTraces.ring) == size {
+		return
+	}
+	thinkingTraces = NewThinkingTraceBuffer(size)
+}
+
+// ThinkingTraces returns the global trace buffer, or nil when capture is disabled.
+func ThinkingTraces() *ThinkingTraceBuffer {
+	thinkingTracesMu.RLock()
+	defer thinkingTracesMu.RUnlock()
+	return thinkingTraces
+}
+
+// ThinkingCapture collects a single request's trace. Nothing is stored
+// until the request turns out to target a thinking-capable model.
+type ThinkingCapture struct {
+	requestID string
+	buffer    *ThinkingTraceBuffer
+
+	mu    sync.Mutex
+	trace *ThinkingTrace
+}
+
+// NewThinkingCapture starts a capture for requestID when capture is enabled.
+func NewThinkingCapture(requestID string) *ThinkingCapture {
+	buffer := ThinkingTraces()
+	if buffer == nil || requestID == "" {
+		return nil
+	}
+	return &ThinkingCapture{requestID: requestID, buffer: buffer}
+}
+
+// RequestID returns the ID the trace is stored under.
+func (c *ThinkingCapture) RequestID() string {
+	if c == nil {
+		return ""
+	}
+	return c.requestID
+}
+
+type thinkingCaptureContextKey struct{}
+
+// WithThinkingCapture attaches capture to ctx for the stream runner.
+func WithThinkingCapture(ctx context.Context, capture *ThinkingCapture) context.Context {
+	if capture == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, thinkingCaptureContextKey{}, capture)
+}
+
+// ThinkingCaptureFromContext returns the capture attached to ctx, or nil.
+func ThinkingCaptureFromContext(ctx context.Context) *ThinkingCapture {
+	if ctx == nil {
+		return nil
+	}
+	capture, _ := ctx.Value(thinkingCaptureContextKey{}).(*ThinkingCapture)
+	return capture
+}
+
+// ThinkingCaptureFromMetadata returns the capture carried in request metadata, or nil.
+func ThinkingCaptureFromMetadata(meta map[string]any) *ThinkingCapture {
+	if meta == nil {
+		return nil
+	}
+	capture, _ := meta[ThinkingCaptureMetadataKey].(*ThinkingCapture)
+	return capture
+}
+
+// begin records the upstream request and registers the trace when model
+// supports thinking. Retries against another auth replace the trace.
+func (c *ThinkingCapture) begin(model string, payload []byte) {
+	if c == nil || !util.ModelSupportsThinking(model) {
+		return
+	}
+	trace := &ThinkingTrace{
+		RequestID: c.requestID,
+		Model:     model,
+		StartedAt: time.Now(),
+		Request:   clipCapture(string(payload)),
+	}
+	c.mu.Lock()
+	c.trace = trace
+	c.mu.Unlock()
+	c.buffer.add(trace)
+}
+
+// active reports whether a trace has been started for this capture.
+func (c *ThinkingCapture) active() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.trace != nil
+}
+
+func (c *ThinkingCapture) recordRaw(line []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.trace == nil {
+		return
+	}
+	c.buffer.mu.Lock()
+	defer c.buffer.mu.Unlock()
+	if len(c.trace.RawSSE) >= maxCapturedEntries {
+		c.trace.Truncated = true
+		return
+	}
+	c.trace.RawSSE = append(c.trace.RawSSE, clipCapture(string(line)))
+}
+
+func (c *ThinkingCapture) recordEvents(events []ir.UnifiedEvent) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.trace == nil {
+		return
+	}
+	c.buffer.mu.Lock()
+	defer c.buffer.mu.Unlock()
+	for i := range events {
+		if len(c.trace.Events) >= maxCapturedEntries {
+			c.trace.Truncated = true
+			return
+		}
+		c.trace.Events = append(c.trace.Events, captureEvent(&events[i]))
+	}
+}
+
+func captureEvent(ev *ir.UnifiedEvent) CapturedEvent {
+	out := CapturedEvent{
+		Type:             string(ev.Type),
+		Content:          clipCapture(ev.Content),
+		Reasoning:        clipCapture(ev.Reasoning),
+		ReasoningSummary: clipCapture(ev.ReasoningSummary),
+		SignatureBytes:   len(ev.ThoughtSignature),
+		FinishReason:     string(ev.FinishReason),
+	}
+	if ev.Usage != nil {
+		out.ThoughtsTokens = ev.Usage.ThoughtsTokenCount
+	}
+	if ev.Type == ir.EventTypeError {
+		out.Error = ev.ErrorMessage()
+	}
+	return out
+}
+
+func clipCapture(s string) string {
+	if len(s) <= maxCapturedBytes {
+		return s
+	}
+	return s[:maxCapturedBytes] + "...(truncated)"
+}
@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestThinkingTraceBuffer_EvictsOldest(t *testing.T) {
+	buf := NewThinkingTraceBuffer(2)
+	for i := 1; i <= 3; i++ {
+		buf.add(&ThinkingTrace{RequestID: fmt.Sprintf("req-%d", i)})
+	}
+
+	if _, ok := buf.Get("req-1"); ok {
+		t.Error("Expected oldest trace to be evicted")
+	}
+	for _, id := range []string{"req-2", "req-3"} {
+		if _, ok := buf.Get(id); !ok {
+			t.Errorf("Expected trace %s to be retained", id)
+		}
+	}
+	if ids := buf.IDs(); len(ids) != 2 || ids[0] != "req-2" || ids[1] != "req-3" {
+		t.Errorf("Expected [req-2 req-3], got %v", ids)
+	}
+}
+
+func TestThinkingCapture_RecordsBoundedEntries(t *testing.T) {
+	buf := NewThinkingTraceBuffer(1)
+	capture := &ThinkingCapture{requestID: "req-1", buffer: buf}
+
+	capture.recordRaw([]byte("ignored before begin"))
+	if _, ok := buf.Get("req-1"); ok {
+		t.Fatal("Expected nothing stored before the trace begins")
+	}
+
+	capture.trace = &ThinkingTrace{RequestID: "req-1", Model: "gemini-3-pro-preview"}
+	buf.add(capture.trace)
+	for i := 0; i < maxCapturedEntries+5; i++ {
+		capture.recordRaw([]byte(`data: {"candidates":[]}`))
+	}
+	capture.recordEvents([]ir.UnifiedEvent{{Type: ir.EventTypeReasoning, Reasoning: "step 1"}})
+
+	trace, ok := buf.Get("req-1")
+	if !ok {
+		t.Fatal("Expected trace to be stored")
+	}
+	if len(trace.RawSSE) != maxCapturedEntries || !trace.Truncated {
+		t.Errorf("Expected raw SSE capped at %d with truncation flag, got %d (truncated=%v)", maxCapturedEntries, len(trace.RawSSE), trace.Truncated)
+	}
+	if len(trace.Events) != 1 || trace.Events[0].Reasoning != "step 1" {
+		t.Errorf("Expected reasoning event to be captured, got %+v", trace.Events)
+	}
+}
+
+func TestNewThinkingCapture_DisabledByDefault(t *testing.T) {
+	SetThinkingCaptureSize(0)
+	if capture := NewThinkingCapture("req-1"); capture != nil {
+		t.Error("Expected no capture when thinking capture is disabled")
+	}
+}
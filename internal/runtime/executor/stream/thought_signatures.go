@@ -0,0 +1,281 @@
+package stream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stdjson "encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+// ThoughtSignatureMetadataKey carries the *ThoughtSignatureSession through
+// request metadata to request translation, which has no access to the context.
+const ThoughtSignatureMetadataKey = "__thought_signatures"
+
+// maxThoughtSignatureEntries bounds the signatures kept across all sessions.
+const maxThoughtSignatureEntries = 10000
+
+// ThoughtSignatureStore remembers the thought signatures Gemini attached to
+// function calls, keyed by conversation and call content, so they can be sent
+// back when a client replays the call without its signature.
+type ThoughtSignatureStore struct {
+	mu      sync.Mutex
+	entries map[string]thoughtSignatureEntry
+}
+
+type thoughtSignatureEntry struct {
+	signature []byte
+	expires   time.Time
+}
+
+// NewThoughtSignatureStore creates an empty store.
+func NewThoughtSignatureStore() *ThoughtSignatureStore {
+	return &ThoughtSignatureStore{entries: make(map[string]thoughtSignatureEntry)}
+}
+
+func (s *ThoughtSignatureStore) get(key string, now time.Time) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if now.After(entry.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return entry.signature, true
+}
+
+func (s *ThoughtSignatureStore) put(key string, signature []byte, ttl time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= maxThoughtSignatureEntries {
+		for k, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		for k := range s.entries {
+			if len(s.entries) < maxThoughtSignatureEntries {
+				break
+			}
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = thoughtSignatureEntry{signature: signature, expires: now.Add(ttl)}
+}
+
+// ThoughtSignatureSession reads and records the signatures of one request.
+// With a client session ID, signatures are read and written under it. Without
+// one, Responses API requests read those of previous_response_id and the
+// handler saves the signatures seen by the request under the new response ID
+// through Commit, so a chain of responses carries them forward.
+type ThoughtSignatureSession struct {
+	store    *ThoughtSignatureStore
+	ttl      time.Duration
+	session  string
+	previous string
+
+	mu      sync.Mutex
+	pending map[string][]byte // call key -> signature, saved by Commit
+}
+
+// NewThoughtSignatureSession returns a session keyed by sessionID, or by
+// response IDs when byResponse is set and sessionID is empty. It returns nil
+// when the request has nothing to key signatures on.
+func NewThoughtSignatureSession(store *ThoughtSignatureStore, ttl time.Duration, sessionID, previousResponseID string, byResponse bool) *ThoughtSignatureSession {
+	if store == nil || ttl <= 0 {
+		return nil
+	}
+	s := &ThoughtSignatureSession{store: store, ttl: ttl}
+	switch {
+	case sessionID != "":
+		s.session = "s\x00" + sessionID
+	case byResponse:
+		if previousResponseID != "" {
+			s.previous = "r\x00" + previousResponseID
+		}
+		s.pending = make(map[string][]byte)
+	default:
+		return nil
+	}
+	return s
+}
+
+// Commit saves the signatures seen by a Responses API request under the ID of
+// its response. Sessions keyed by a client session ID save them as they are
+// seen, so Commit does nothing for them.
+func (s *ThoughtSignatureSession) Commit(responseID string) {
+	if s == nil || s.session != "" || responseID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, sig := range s.pending {
+		s.store.put("r\x00"+responseID+"\x00"+key, sig, s.ttl, now)
+	}
+}
+
+// restore attaches remembered signatures to assistant function calls that
+// arrive without one, and remembers the ones that arrive with one. It returns
+// the number of signatures restored.
+func (s *ThoughtSignatureSession) restore(req *ir.UnifiedChatRequest) int {
+	if s == nil || req == nil {
+		return 0
+	}
+	restored := 0
+	for i := range req.Messages {
+		msg := &req.Messages[i]
+		if msg.Role != ir.RoleAssistant {
+			continue
+		}
+		for j := range msg.ToolCalls {
+			tc := &msg.ToolCalls[j]
+			if validThoughtSignature(tc.ThoughtSignature) {
+				s.remember(tc.Name, tc.Args, tc.ThoughtSignature)
+				continue
+			}
+			if sig, ok := s.lookup(tc.Name, tc.Args); ok {
+				tc.ThoughtSignature = sig
+				restored++
+			}
+		}
+	}
+	return restored
+}
+
+func (s *ThoughtSignatureSession) lookup(name, args string) ([]byte, bool) {
+	key := toolCallKey(name, args)
+	from := s.session
+	if from == "" {
+		from = s.previous
+	}
+	if from == "" {
+		return nil, false
+	}
+	sig, ok := s.store.get(from+"\x00"+key, time.Now())
+	if ok && s.session == "" {
+		s.mu.Lock()
+		s.pending[key] = sig
+		s.mu.Unlock()
+	}
+	return sig, ok
+}
+
+func (s *ThoughtSignatureSession) remember(name, args string, sig []byte) {
+	if s == nil || name == "" || !validThoughtSignature(sig) {
+		return
+	}
+	key := toolCallKey(name, args)
+	if s.session != "" {
+		s.store.put(s.session+"\x00"+key, sig, s.ttl, time.Now())
+		return
+	}
+	s.mu.Lock()
+	s.pending[key] = sig
+	s.mu.Unlock()
+}
+
+// recordEvents remembers the signatures of streamed function calls.
+func (s *ThoughtSignatureSession) recordEvents(events []ir.UnifiedEvent) {
+	if s == nil {
+		return
+	}
+	for i := range events {
+		ev := &events[i]
+		if ev.Type != ir.EventTypeToolCall || ev.ToolCall == nil {
+			continue
+		}
+		sig := ev.ToolCall.ThoughtSignature
+		if !validThoughtSignature(sig) {
+			sig = ev.ThoughtSignature
+		}
+		s.remember(ev.ToolCall.Name, ev.ToolCall.Args, sig)
+	}
+}
+
+// RememberThoughtSignatures records the function call signatures of a
+// non-streaming Gemini response for the session attached to ctx.
+func RememberThoughtSignatures(ctx context.Context, response []byte) {
+	s := ThoughtSignaturesFromContext(ctx)
+	if s == nil {
+		return
+	}
+	for _, candidate := range gjson.GetBytes(response, "candidates").Array() {
+		for _, part := range candidate.Get("content.parts").Array() {
+			fc := part.Get("functionCall")
+			if !fc.Exists() {
+				continue
+			}
+			s.remember(fc.Get("name").String(), fc.Get("args").Raw, []byte(part.Get("thoughtSignature").String()))
+		}
+	}
+}
+
+// validThoughtSignature reports whether sig is a real signature rather than
+// a placeholder.
+func validThoughtSignature(sig []byte) bool {
+	return ir.IsValidThoughtSignature(sig) && string(sig) != ir.DummyThoughtSignature
+}
+
+// toolCallKey identifies a function call by name and arguments, ignoring key
+// order and whitespace in the arguments.
+func toolCallKey(name, args string) string {
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(canonicalArgs(args))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func canonicalArgs(args string) []byte {
+	trimmed := strings.TrimSpace(args)
+	if trimmed == "" {
+		return []byte("{}")
+	}
+	var v any
+	if err := stdjson.Unmarshal([]byte(trimmed), &v); err != nil {
+		return []byte(trimmed)
+	}
+	// encoding/json writes map keys in sorted order.
+	b, err := stdjson.Marshal(v)
+	if err != nil {
+		return []byte(trimmed)
+	}
+	return b
+}
+
+type thoughtSignatureContextKey struct{}
+
+// WithThoughtSignatures attaches s to ctx for response translation.
+func WithThoughtSignatures(ctx context.Context, s *ThoughtSignatureSession) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, thoughtSignatureContextKey{}, s)
+}
+
+// ThoughtSignaturesFromContext returns the session attached to ctx, or nil.
+func ThoughtSignaturesFromContext(ctx context.Context) *ThoughtSignatureSession {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(thoughtSignatureContextKey{}).(*ThoughtSignatureSession)
+	return s
+}
+
+// ThoughtSignaturesFromMetadata returns the session carried in request metadata, or nil.
+func ThoughtSignaturesFromMetadata(meta map[string]any) *ThoughtSignatureSession {
+	if meta == nil {
+		return nil
+	}
+	s, _ := meta[ThoughtSignatureMetadataKey].(*ThoughtSignatureSession)
+	return s
+}
@@ -0,0 +1,86 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func signatureRequest(args string, sig []byte) *ir.UnifiedChatRequest {
+	return &ir.UnifiedChatRequest{
+		Messages: []ir.Message{
+			{Role: ir.RoleUser},
+			{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: "call_1", Name: "get_weather", Args: args, ThoughtSignature: sig}}},
+		},
+	}
+}
+
+func TestThoughtSignatureSession_SessionID(t *testing.T) {
+	store := NewThoughtSignatureStore()
+	sig := []byte("c2lnbmF0dXJlLWZyb20tZ2VtaW5pLXJlc3BvbnNl")
+
+	first := NewThoughtSignatureSession(store, time.Hour, "conv-1", "", false)
+	first.recordEvents([]ir.UnifiedEvent{{
+		Type:     ir.EventTypeToolCall,
+		ToolCall: &ir.ToolCall{Name: "get_weather", Args: `{"city":"Paris","unit":"c"}`, ThoughtSignature: sig},
+	}})
+
+	req := signatureRequest(`{"unit": "c", "city": "Paris"}`, nil)
+	next := NewThoughtSignatureSession(store, time.Hour, "conv-1", "", false)
+	if n := next.restore(req); n != 1 {
+		t.Fatalf("restored %d signatures, want 1", n)
+	}
+	if got := string(req.Messages[1].ToolCalls[0].ThoughtSignature); got != string(sig) {
+		t.Errorf("signature = %q, want %q", got, sig)
+	}
+
+	other := signatureRequest(`{"city":"Paris","unit":"c"}`, nil)
+	if n := NewThoughtSignatureSession(store, time.Hour, "conv-2", "", false).restore(other); n != 0 {
+		t.Errorf("restored %d signatures across sessions, want 0", n)
+	}
+}
+
+func TestThoughtSignatureSession_ResponseChain(t *testing.T) {
+	store := NewThoughtSignatureStore()
+	sig := []byte("c2lnbmF0dXJlLWZyb20tZ2VtaW5pLXJlc3BvbnNl")
+
+	first := NewThoughtSignatureSession(store, time.Hour, "", "", true)
+	first.remember("get_weather", `{"city":"Paris"}`, sig)
+	first.Commit("resp_1")
+
+	// The second turn restores from resp_1 and carries the signature to resp_2.
+	second := NewThoughtSignatureSession(store, time.Hour, "", "resp_1", true)
+	req := signatureRequest(`{"city":"Paris"}`, nil)
+	if n := second.restore(req); n != 1 {
+		t.Fatalf("restored %d signatures, want 1", n)
+	}
+	second.Commit("resp_2")
+
+	third := NewThoughtSignatureSession(store, time.Hour, "", "resp_2", true)
+	req = signatureRequest(`{"city":"Paris"}`, nil)
+	if n := third.restore(req); n != 1 {
+		t.Fatalf("restored %d signatures from the carried response, want 1", n)
+	}
+}
+
+func TestThoughtSignatureSession_IgnoresPlaceholders(t *testing.T) {
+	store := NewThoughtSignatureStore()
+	s := NewThoughtSignatureSession(store, time.Hour, "conv-1", "", false)
+	s.remember("get_weather", `{}`, []byte(ir.DummyThoughtSignature))
+
+	req := signatureRequest(``, []byte(ir.DummyThoughtSignature))
+	if n := s.restore(req); n != 0 {
+		t.Errorf("restored %d signatures from a placeholder, want 0", n)
+	}
+}
+
+func TestNewThoughtSignatureSession_NothingToKeyOn(t *testing.T) {
+	store := NewThoughtSignatureStore()
+	if s := NewThoughtSignatureSession(store, time.Hour, "", "", false); s != nil {
+		t.Error("expected nil session without a session ID outside the Responses API")
+	}
+	if s := NewThoughtSignatureSession(store, 0, "conv-1", "", false); s != nil {
+		t.Error("expected nil session with a zero TTL")
+	}
+}
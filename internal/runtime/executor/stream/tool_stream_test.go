@@ -0,0 +1,133 @@
+package stream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+type streamedToolCall struct {
+	id, name, args string
+	frames         int
+}
+
+// collectToolCalls reassembles OpenAI tool_calls deltas by index and checks
+// that each call opens with an id/name frame carrying empty arguments.
+func collectToolCalls(t *testing.T, chunks [][]byte) map[int]*streamedToolCall {
+	t.Helper()
+	calls := make(map[int]*streamedToolCall)
+	for _, chunk := range chunks {
+		for _, frame := range bytes.Split(chunk, []byte("\n\n")) {
+			data := ir.ExtractSSEData(frame)
+			if len(data) == 0 {
+				continue
+			}
+			for _, tc := range gjson.GetBytes(data, "choices.0.delta.tool_calls").Array() {
+				idx := int(tc.Get("index").Int())
+				call := calls[idx]
+				if call == nil {
+					if tc.Get("id").String() == "" || tc.Get("function.name").String() == "" || tc.Get("type").String() != "function" {
+						t.Fatalf("first frame for index %d lacks id/name/type: %s", idx, tc.Raw)
+					}
+					if args := tc.Get("function.arguments"); !args.Exists() || args.String() != "" {
+						t.Fatalf("first frame for index %d has arguments %s, want empty", idx, args.Raw)
+					}
+					call = &streamedToolCall{id: tc.Get("id").String(), name: tc.Get("function.name").String()}
+					calls[idx] = call
+				} else if tc.Get("id").Exists() || tc.Get("function.name").Exists() {
+					t.Errorf("argument frame for index %d repeats id/name: %s", idx, tc.Raw)
+				}
+				call.args += tc.Get("function.arguments").String()
+				call.frames++
+			}
+		}
+	}
+	return calls
+}
+
+func TestClaudeToolCallArgsStreamToOpenAI(t *testing.T) {
+	lines := []string{
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_a","name":"get_weather","input":{}}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":""}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+		`data: {"type":"content_block_stop","index":0}`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_b","name":"get_time","input":{}}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"tz\":"}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"CET\"}"}}`,
+		`data: {"type":"content_block_stop","index":1}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":12}}`,
+	}
+	state := ir.NewClaudeStreamParserState()
+	st := NewStreamTranslator(nil, provider.FormatClaude, "openai", "claude-test", "id", nil)
+
+	var chunks [][]byte
+	for _, line := range lines {
+		events, err := to_ir.ParseClaudeChunkWithState([]byte(line), state)
+		if err != nil {
+			t.Fatalf("ParseClaudeChunkWithState: %v", err)
+		}
+		res, err := st.Translate(events)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		chunks = append(chunks, res.Chunks...)
+	}
+
+	calls := collectToolCalls(t, chunks)
+	want := map[int]streamedToolCall{
+		0: {id: "toolu_a", name: "get_weather", args: `{"city":"Paris"}`, frames: 3},
+		1: {id: "toolu_b", name: "get_time", args: `{"tz":"CET"}`, frames: 3},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("got %d tool calls, want %d", len(calls), len(want))
+	}
+	for idx, w := range want {
+		if got := calls[idx]; *got != w {
+			t.Errorf("tool call %d = %+v, want %+v", idx, *got, w)
+		}
+	}
+	last := ir.ExtractSSEData(chunks[len(chunks)-1])
+	if got := gjson.GetBytes(last, "choices.0.finish_reason").String(); got != "tool_calls" {
+		t.Errorf("finish_reason = %q, want tool_calls", got)
+	}
+}
+
+func TestParallelToolCallDeltasToOpenAI(t *testing.T) {
+	delta := func(src int, id, name, args string) ir.UnifiedEvent {
+		return ir.UnifiedEvent{Type: ir.EventTypeToolCallDelta, ToolCall: &ir.ToolCall{ID: id, Name: name, Args: args}, ToolCallIndex: src}
+	}
+	events := []ir.UnifiedEvent{
+		delta(3, "call_x", "search", ""),
+		delta(5, "call_y", "fetch", `{"u`),
+		delta(3, "", "", `{"q":`),
+		delta(5, "", "", `rl":"a"}`),
+		delta(3, "", "", `"go"}`),
+		{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{ID: "call_x", Name: "search", Args: `{"q":"go"}`}, ToolCallIndex: 3},
+		{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{ID: "call_y", Name: "fetch", Args: `{"url":"a"}`}, ToolCallIndex: 5},
+		// A complete call that was never streamed gets the next index in one chunk.
+		{Type: ir.EventTypeToolCall, ToolCall: &ir.ToolCall{ID: "call_z", Name: "noop", Args: `{}`}, ToolCallIndex: 0},
+	}
+	st := NewStreamTranslator(nil, provider.FormatClaude, "openai", "test-model", "id", nil)
+	res, err := st.Translate(events)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	calls := collectToolCalls(t, res.Chunks[:len(res.Chunks)-1])
+	if c := calls[0]; c == nil || c.id != "call_x" || c.args != `{"q":"go"}` {
+		t.Errorf("index 0 = %+v, want call_x with {\"q\":\"go\"}", c)
+	}
+	if c := calls[1]; c == nil || c.id != "call_y" || c.args != `{"url":"a"}` {
+		t.Errorf("index 1 = %+v, want call_y with {\"url\":\"a\"}", c)
+	}
+
+	tc := gjson.GetBytes(ir.ExtractSSEData(res.Chunks[len(res.Chunks)-1]), "choices.0.delta.tool_calls.0")
+	if tc.Get("index").Int() != 2 || tc.Get("id").String() != "call_z" || tc.Get("function.arguments").String() != "{}" {
+		t.Errorf("unstreamed call chunk = %s, want index 2 with full arguments", tc.Raw)
+	}
+}
@@ -0,0 +1,140 @@
+package stream
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/metrics"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// translationCache keeps recently translated upstream payloads keyed by the
+// target format and a hash of everything the translation depends on, so
+// agents resending an identical request skip parsing, preprocessing and
+// conversion. Entries expire after ttl; the least recently used is evicted
+// once maxEntries is reached.
+type translationCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // front is most recently used
+}
+
+type translationEntry struct {
+	key     [sha256.Size]byte
+	payload []byte
+	expires time.Time
+}
+
+var translations atomic.Pointer[translationCache]
+
+// SetTranslationCache replaces the translation cache, dropping every entry.
+// A zero ttl or maxEntries disables it.
+func SetTranslationCache(ttl time.Duration, maxEntries int) {
+	if ttl <= 0 || maxEntries <= 0 {
+		translations.Store(nil)
+		return
+	}
+	translations.Store(&translationCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[[sha256.Size]byte]*list.Element),
+		order:      list.New(),
+	})
+}
+
+func (c *translationCache) get(key [sha256.Size]byte, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*translationEntry)
+	if now.After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.payload, true
+}
+
+func (c *translationCache) put(key [sha256.Size]byte, payload []byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*translationEntry)
+		entry.payload = payload
+		entry.expires = now.Add(c.ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&translationEntry{key: key, payload: payload, expires: now.Add(c.ttl)})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*translationEntry).key)
+	}
+}
+
+// translationKey hashes the inputs of a translation. It reports false for
+// requests that must not be served from the cache because translating them
+// has side effects or depends on state outside the request: route traces,
+// thinking capture, thinking debug logs and remembered thought signatures.
+func translationKey(target string, from provider.Format, model string, streaming bool, payload []byte, metadata map[string]any) ([sha256.Size]byte, bool) {
+	if provider.RouteTraceFromMetadata(metadata) != nil || ThinkingCaptureFromMetadata(metadata) != nil || ThoughtSignaturesFromMetadata(metadata) != nil || DebugThinkingEnabled(model) {
+		return [sha256.Size]byte{}, false
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%t\x00", target, from, model, streaming)
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		// Internal per-request carriers such as the telemetry span.
+		if !strings.HasPrefix(k, "__") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%v\x00", k, metadata[k])
+	}
+	h.Write(payload)
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key, true
+}
+
+// cachedTranslation returns the cached result of translate for identical
+// inputs, or runs it and caches the result. Callers receive their own copy.
+func cachedTranslation(target string, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any, translate func() ([]byte, error)) ([]byte, error) {
+	cache := translations.Load()
+	if cache == nil {
+		return translate()
+	}
+	key, ok := translationKey(target, from, model, streaming, payload, metadata)
+	if !ok {
+		return translate()
+	}
+	now := time.Now()
+	if cached, hit := cache.get(key, now); hit {
+		metrics.TranslationCacheLookups.Inc(metrics.L("hit"))
+		return bytes.Clone(cached), nil
+	}
+	metrics.TranslationCacheLookups.Inc(metrics.L("miss"))
+	body, err := translate()
+	if err != nil {
+		return nil, err
+	}
+	cache.put(key, bytes.Clone(body), now)
+	return body, nil
+}
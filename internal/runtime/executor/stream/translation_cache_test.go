@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestCachedTranslation(t *testing.T) {
+	SetTranslationCache(time.Minute, 8)
+	t.Cleanup(func() { SetTranslationCache(0, 0) })
+
+	calls := 0
+	translate := func() ([]byte, error) {
+		calls++
+		return []byte(`{"translated":true}`), nil
+	}
+	payload := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+
+	first, _ := cachedTranslation("claude", provider.FormatOpenAI, "m", payload, false, nil, translate)
+	second, _ := cachedTranslation("claude", provider.FormatOpenAI, "m", payload, false, nil, translate)
+	if calls != 1 {
+		t.Fatalf("translate called %d times, want 1", calls)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("cached payload %s, want %s", second, first)
+	}
+	second[0] = 'x'
+	third, _ := cachedTranslation("claude", provider.FormatOpenAI, "m", payload, false, nil, translate)
+	if third[0] != '{' {
+		t.Fatal("callers share the cached payload")
+	}
+
+	cachedTranslation("claude", provider.FormatOpenAI, "m", payload, true, nil, translate)
+	cachedTranslation("codex", provider.FormatOpenAI, "m", payload, false, nil, translate)
+	cachedTranslation("claude", provider.FormatOpenAI, "m", payload, false, map[string]any{"thinking_budget": 1024}, translate)
+	if calls != 4 {
+		t.Fatalf("translate called %d times, want 4 for distinct inputs", calls)
+	}
+
+	traced := map[string]any{provider.RouteTraceMetadataKey: &provider.RouteTrace{}}
+	cachedTranslation("claude", provider.FormatOpenAI, "m", payload, false, traced, translate)
+	cachedTranslation("claude", provider.FormatOpenAI, "m", payload, false, traced, translate)
+	if calls != 6 {
+		t.Fatalf("translate called %d times, want traced requests to bypass the cache", calls)
+	}
+}
+
+func TestTranslationCacheExpiryAndEviction(t *testing.T) {
+	SetTranslationCache(time.Second, 2)
+	c := translations.Load()
+	t.Cleanup(func() { SetTranslationCache(0, 0) })
+
+	now := time.Now()
+	a, b, d := sha256.Sum256([]byte("a")), sha256.Sum256([]byte("b")), sha256.Sum256([]byte("d"))
+	c.put(a, []byte("a"), now)
+	c.put(b, []byte("b"), now)
+	c.get(a, now)
+	c.put(d, []byte("d"), now)
+	if _, ok := c.get(b, now); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, ok := c.get(a, now); !ok {
+		t.Error("recently used entry was evicted")
+	}
+	if _, ok := c.get(a, now.Add(2*time.Second)); ok {
+		t.Error("expired entry was returned")
+	}
+	if len(c.entries) != 1 || c.order.Len() != 1 {
+		t.Errorf("cache holds %d/%d entries after expiry, want 1", len(c.entries), c.order.Len())
+	}
+}
+
+// largeOpenAIRequest builds an agent-style request with a long history.
+func largeOpenAIRequest(turns int) []byte {
+	var sb strings.Builder
+	sb.WriteString(`{"model":"claude-sonnet-4-20250514","stream":true,"messages":[{"role":"system","content":"You are a coding agent."}`)
+	for i := 0; i < turns; i++ {
+		fmt.Fprintf(&sb, `,{"role":"user","content":"Step %d: %s"}`, i, strings.Repeat("refactor the handler and keep tests green. ", 20))
+		fmt.Fprintf(&sb, `,{"role":"assistant","content":"Done with step %d. %s"}`, i, strings.Repeat("Updated the file and ran the suite. ", 20))
+	}
+	sb.WriteString(`]}`)
+	return []byte(sb.String())
+}
+
+func benchmarkTranslateToClaude(b *testing.B, ttl time.Duration) {
+	SetTranslationCache(ttl, 16)
+	b.Cleanup(func() { SetTranslationCache(0, 0) })
+	payload := largeOpenAIRequest(200)
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := TranslateToClaude(b.Context(), nil, provider.FormatOpenAI, "claude-sonnet-4-20250514", payload, true, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTranslateToClaude(b *testing.B)       { benchmarkTranslateToClaude(b, 0) }
+func BenchmarkTranslateToClaudeCached(b *testing.B) { benchmarkTranslateToClaude(b, time.Minute) }
@@ -1,6 +1,8 @@
 package stream
 
 import (
+	"bytes"
+
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/translator/from_ir"
@@ -33,18 +35,28 @@ func (b *PassthroughEventBuffer) Flush() []*ir.UnifiedEvent {
 type StreamContext struct {
 	ClaudeState          *from_ir.ClaudeStreamState
 	GeminiState          *ir.GeminiStreamParserState
-	ToolCallIndex        int
+	OpenAIToolCalls      *from_ir.OpenAIToolCallStream
+	ResponsesState       *from_ir.ResponsesStreamState
 	HasToolCalls         bool
 	FinishSent           bool
+	UsageSent            bool // Token counts went out with the finish event
 	ReasoningCharsAccum  int
+	ContentCharsAccum    int
+	FinishReason         ir.FinishReason
 	ToolSchemaCtx        *ir.ToolSchemaContext
 	EstimatedInputTokens int64
+	MinTokens            int             // Requested minimum output; see WithMinTokens
+	HideThoughts         bool            // Drop reasoning events; see WithHiddenThoughts
+	usageEstimate        *usageEstimator // Set when usage is estimated for streams without it
+	choices              choiceState
 }
 
 func NewStreamContext() *StreamContext {
 	return &StreamContext{
-		ClaudeState: from_ir.NewClaudeStreamState(),
-		GeminiState: ir.NewGeminiStreamParserState(),
+		ClaudeState:     from_ir.NewClaudeStreamState(),
+		GeminiState:     ir.NewGeminiStreamParserState(),
+		OpenAIToolCalls: from_ir.NewOpenAIToolCallStream(),
+		ResponsesState:  from_ir.NewResponsesStreamState(),
 	}
 }
 
@@ -75,6 +87,26 @@ func (s *StreamContext) EstimateReasoningTokens() int32 {
 	return int32(s.ReasoningCharsAccum / 3)
 }
 
+// PartialUsage estimates usage for a stream that ended before the provider
+// reported it. Returns nil when nothing was generated.
+func (s *StreamContext) PartialUsage() *ir.Usage {
+	if s.ContentCharsAccum == 0 && s.ReasoningCharsAccum == 0 {
+		return nil
+	}
+	prompt := s.EstimatedInputTokens
+	if s.GeminiState != nil && s.GeminiState.ActualInputTokens > 0 {
+		prompt = s.GeminiState.ActualInputTokens
+	}
+	thoughts := s.EstimateReasoningTokens()
+	completion := int64(s.ContentCharsAccum/4) + int64(thoughts)
+	return &ir.Usage{
+		PromptTokens:       prompt,
+		CompletionTokens:   completion,
+		TotalTokens:        prompt + completion,
+		ThoughtsTokenCount: thoughts,
+	}
+}
+
 // StreamTranslator handles format conversion with integrated buffering
 type StreamTranslator struct {
 	cfg            *config.Config
@@ -86,6 +118,10 @@ type StreamTranslator struct {
 	eventBuffer    EventBufferStrategy
 	chunkBuffer    ChunkBufferStrategy
 	streamMetaSent bool
+	upstreamID     bool // messageID comes from the upstream response
+	debugThinking  bool
+	capture        *ThinkingCapture
+	signatures     *ThoughtSignatureSession
 }
 
 func NewStreamTranslator(cfg *config.Config, from provider.Format, to, model, messageID string, Ctx *StreamContext) *StreamTranslator {
@@ -93,12 +129,16 @@ func NewStreamTranslator(cfg *config.Config, from provider.Format, to, model, me
 		Ctx = NewStreamContext()
 	}
 	st := &StreamTranslator{
-		cfg:       cfg,
-		from:      from,
-		to:        to,
-		model:     model,
-		messageID: messageID,
-		Ctx:       Ctx,
+		cfg:           cfg,
+		from:          from,
+		to:            to,
+		model:         model,
+		messageID:     messageID,
+		Ctx:           Ctx,
+		debugThinking: DebugThinkingEnabled(model),
+	}
+	if Ctx.GeminiState != nil {
+		Ctx.GeminiState.ContentFilterResults = contentFilterResults(cfg)
 	}
 
 	if provider.IsGeminiFormat(to) {
@@ -112,10 +152,42 @@ func NewStreamTranslator(cfg *config.Config, from provider.Format, to, model, me
 	return st
 }
 
+// adoptUpstreamResponseID switches the stream to the upstream response ID
+// and creation time once a Gemini chunk reports them, when
+// upstream-response-ids is enabled.
+func (t *StreamTranslator) adoptUpstreamResponseID() {
+	state := t.Ctx.GeminiState
+	if t.upstreamID || state == nil || state.ResponseID == "" || !upstreamResponseIDs(t.cfg) {
+		return
+	}
+	t.upstreamID = true
+	t.messageID = upstreamMessageID(t.to, state.ResponseID)
+	t.Ctx.OpenAIToolCalls.Created = state.CreateTime
+}
+
+// SetThinkingCapture records every translated IR event into capture.
+func (t *StreamTranslator) SetThinkingCapture(capture *ThinkingCapture) {
+	t.capture = capture
+}
+
+// SetThoughtSignatures remembers the thought signatures of streamed function
+// calls in s.
+func (t *StreamTranslator) SetThoughtSignatures(s *ThoughtSignatureSession) {
+	t.signatures = s
+}
+
 // Translate converts IR events to target format with buffering
 func (t *StreamTranslator) Translate(events []ir.UnifiedEvent) (*StreamTranslationResult, error) {
 	var allChunks [][]byte
 
+	if t.debugThinking {
+		logThinkingEvents(t.model, events)
+	}
+	if t.capture != nil {
+		t.capture.recordEvents(events)
+	}
+	t.signatures.recordEvents(events)
+
 	if !t.streamMetaSent && len(events) > 0 {
 		t.streamMetaSent = true
 
@@ -144,18 +216,20 @@ func (t *StreamTranslator) Translate(events []ir.UnifiedEvent) (*StreamTranslati
 	for i := range events {
 		event := &events[i]
 
-		if t.preprocess(event) {
+		var chunks [][]byte
+		var err error
+		switch {
+		case event.CandidateIndex > 0:
+			chunks, err = t.translateChoice(event)
+		case t.holdFinish(event):
 			continue
+		default:
+			chunks, err = t.translateEvent(event)
 		}
-
-		bufferedEvents := t.eventBuffer.Process(event)
-		for _, ev := range bufferedEvents {
-			chunks, err := t.convertAndBuffer(ev)
-			if err != nil {
-				return nil, err
-			}
-			allChunks = append(allChunks, chunks...)
+		if err != nil {
+			return nil, err
 		}
+		allChunks = append(allChunks, chunks...)
 	}
 
 	usage := ExtractUsageFromEvents(events)
@@ -166,6 +240,30 @@ func (t *StreamTranslator) Translate(events []ir.UnifiedEvent) (*StreamTranslati
 	}, nil
 }
 
+// translateEvent converts one event of the first choice, which carries the
+// stream's finish.
+func (t *StreamTranslator) translateEvent(event *ir.UnifiedEvent) ([][]byte, error) {
+	if t.preprocess(event) {
+		if chunk := t.lateUsageChunk(event); chunk != nil {
+			return [][]byte{chunk}, nil
+		}
+		return nil, nil
+	}
+	if t.Ctx.hiddenThought(event) {
+		return nil, nil
+	}
+
+	var allChunks [][]byte
+	for _, ev := range t.eventBuffer.Process(event) {
+		chunks, err := t.convertAndBuffer(ev)
+		if err != nil {
+			return nil, err
+		}
+		allChunks = append(allChunks, chunks...)
+	}
+	return allChunks, nil
+}
+
 func (t *StreamTranslator) convertAndBuffer(event *ir.UnifiedEvent) ([][]byte, error) {
 	chunk, err := t.convertEvent(event)
 	if err != nil {
@@ -186,6 +284,15 @@ func (t *StreamTranslator) convertAndBuffer(event *ir.UnifiedEvent) ([][]byte, e
 func (t *StreamTranslator) Flush() ([][]byte, error) {
 	var allChunks [][]byte
 
+	// A choice that never finished must not keep the stream open.
+	if pending := t.Ctx.choices.takePending(); pending != nil {
+		chunks, err := t.translateEvent(pending)
+		if err != nil {
+			return nil, err
+		}
+		allChunks = append(allChunks, chunks...)
+	}
+
 	// Finalize Claude parser state (embedded in ClaudeState)
 	if t.Ctx != nil && t.Ctx.ClaudeState != nil && t.Ctx.ClaudeState.ParserState != nil {
 		if finalEvent := t.Ctx.ClaudeState.ParserState.Finalize(); finalEvent != nil {
@@ -217,18 +324,23 @@ func (t *StreamTranslator) Flush() ([][]byte, error) {
 	}
 
 	allChunks = append(allChunks, t.chunkBuffer.Flush()...)
+	if chunk := t.estimatedUsageChunk(); chunk != nil {
+		allChunks = append(allChunks, chunk)
+	}
 	return allChunks, nil
 }
 
 // preprocess handles state tracking (tool calls, reasoning, finish dedup)
 func (t *StreamTranslator) preprocess(event *ir.UnifiedEvent) bool {
-	// Track tool calls - mark HasToolCalls but don't increment index yet
-	// Index increment happens in convertEvent to maintain correct 0-based indexing
+	// Track tool calls - mark HasToolCalls; OpenAI indexes are assigned in convertEvent
 	if event.Type == ir.EventTypeToolCall {
 		t.Ctx.HasToolCalls = true
 	}
 
-	// Track reasoning content for token estimation
+	// Track generated content for token estimation
+	if event.Type == ir.EventTypeToken && event.Content != "" {
+		t.Ctx.ContentCharsAccum += len(event.Content)
+	}
 	if event.Type == ir.EventTypeReasoning && event.Reasoning != "" {
 		t.Ctx.AccumulateReasoning(event.Reasoning)
 	}
@@ -236,6 +348,10 @@ func (t *StreamTranslator) preprocess(event *ir.UnifiedEvent) bool {
 		t.Ctx.AccumulateReasoning(event.ReasoningSummary)
 	}
 
+	if t.Ctx.usageEstimate != nil {
+		t.Ctx.usageEstimate.record(event)
+	}
+
 	// Handle finish event with deduplication and token estimation
 	if event.Type == ir.EventTypeFinish {
 		if !t.Ctx.MarkFinishSent() {
@@ -246,6 +362,15 @@ func (t *StreamTranslator) preprocess(event *ir.UnifiedEvent) bool {
 		if t.Ctx.HasToolCalls {
 			event.FinishReason = ir.FinishReasonToolCalls
 		}
+		if t.Ctx.belowMinTokens(event) {
+			event.FinishReason = ir.FinishReasonMinTokens
+		}
+		t.Ctx.FinishReason = event.FinishReason
+		t.Ctx.UsageSent = event.Usage != nil && event.Usage.TotalTokens > 0
+		if !t.Ctx.UsageSent && t.Ctx.usageEstimate != nil && !t.usageAfterFinish() {
+			event.Usage = t.estimatedUsage()
+			t.Ctx.UsageSent = true
+		}
 
 		// Estimate reasoning tokens if provider didn't provide them
 		if t.Ctx.ReasoningCharsAccum > 0 {
@@ -261,27 +386,70 @@ func (t *StreamTranslator) preprocess(event *ir.UnifiedEvent) bool {
 	return false // don't skip
 }
 
+// lateUsageChunk returns a usage-only OpenAI chunk for a duplicate finish
+// event that carries the token counts the first one lacked. OpenAI upstreams
+// report usage in a separate chunk after the one with finish_reason. The
+// chunk bypasses the chunk buffer, which drops output after the finish.
+func (t *StreamTranslator) lateUsageChunk(event *ir.UnifiedEvent) []byte {
+	if event.Type != ir.EventTypeFinish || event.Usage == nil || event.Usage.TotalTokens == 0 || t.Ctx.UsageSent {
+		return nil
+	}
+	if !t.usageAfterFinish() {
+		return nil
+	}
+	t.Ctx.UsageSent = true
+	return from_ir.ToOpenAIUsageChunk(event.Usage, t.model, t.messageID, event.SystemFingerprint)
+}
+
+// usageAfterFinish reports whether the target format carries usage in its
+// own chunk after the finish, as OpenAI streams do.
+func (t *StreamTranslator) usageAfterFinish() bool {
+	return t.to == "openai" || t.to == "cline"
+}
+
+// estimatedUsageChunk returns a usage-only OpenAI chunk with estimated usage
+// for a finished stream whose provider never reported usage.
+func (t *StreamTranslator) estimatedUsageChunk() []byte {
+	if t.Ctx.usageEstimate == nil || !t.Ctx.FinishSent || t.Ctx.UsageSent || !t.usageAfterFinish() {
+		return nil
+	}
+	t.Ctx.UsageSent = true
+	return from_ir.ToOpenAIUsageChunk(t.estimatedUsage(), t.model, t.messageID, "")
+}
+
+// estimatedUsage counts the stream's request and output locally.
+func (t *StreamTranslator) estimatedUsage() *ir.Usage {
+	e := t.Ctx.usageEstimate
+	return estimateUsage(provider.FromString(t.to), t.model, e.request, e.output.String(), e.reasoning.String())
+}
+
+// EnableUsageEstimate makes the stream estimate usage from request and its
+// output when the provider reports none, if estimate-missing-usage is on.
+func (t *StreamTranslator) EnableUsageEstimate(request []byte) {
+	if estimateMissingUsage(t.cfg) {
+		t.Ctx.usageEstimate = &usageEstimator{request: request}
+	}
+}
+
 // convertEvent converts single event to target format
 func (t *StreamTranslator) convertEvent(event *ir.UnifiedEvent) ([]byte, error) {
 	switch {
 	case t.to == "openai" || t.to == "cline":
-		idx := 0
-		if event.Type == ir.EventTypeToolCall {
-			idx = t.Ctx.ToolCallIndex
-			t.Ctx.ToolCallIndex++ // Increment AFTER getting current index
-		} else if event.Type == ir.EventTypeToolCallDelta {
-			// For deltas, use PREVIOUS index (the tool call we're continuing)
-			if t.Ctx.ToolCallIndex > 0 {
-				idx = t.Ctx.ToolCallIndex - 1
-			}
-		}
-		return from_ir.ToOpenAIChunk(*event, t.model, t.messageID, idx)
+		// Tool call indexes follow the source ToolCallIndex so streamed
+		// argument fragments of parallel calls land on the right call.
+		return t.Ctx.OpenAIToolCalls.Chunk(*event, t.model, t.messageID)
 	case t.to == "claude":
 		return from_ir.ToClaudeSSE(*event, t.Ctx.ClaudeState)
 	case provider.IsGeminiFormat(t.to):
 		return from_ir.ToGeminiChunk(*event, t.model)
 	case t.to == "ollama":
 		return from_ir.ToOllamaChatChunk(*event, t.model)
+	case t.to == "codex" || t.to == "openai-response":
+		chunks, err := from_ir.ToResponsesAPIChunk(*event, t.model, t.Ctx.ResponsesState)
+		if err != nil || len(chunks) == 0 {
+			return nil, err
+		}
+		return bytes.Join(chunks, nil), nil
 	default:
 		return nil, nil // unsupported format
 	}
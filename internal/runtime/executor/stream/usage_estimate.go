@@ -0,0 +1,178 @@
+package stream
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+type usageRequestContextKey struct{}
+
+// WithUsageRequest records the client request so streams translated under ctx
+// can estimate prompt tokens when the provider reports no usage.
+func WithUsageRequest(ctx context.Context, request []byte) context.Context {
+	if len(request) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, usageRequestContextKey{}, request)
+}
+
+// UsageRequestFromContext returns the request recorded by WithUsageRequest, or nil.
+func UsageRequestFromContext(ctx context.Context) []byte {
+	if ctx == nil {
+		return nil
+	}
+	request, _ := ctx.Value(usageRequestContextKey{}).([]byte)
+	return request
+}
+
+// estimateMissingUsage reports whether usage is estimated for responses the
+// provider reported none for.
+func estimateMissingUsage(cfg *config.Config) bool {
+	return cfg != nil && cfg.EstimateMissingUsage
+}
+
+// usageMissing reports whether usage lacks token counts.
+func usageMissing(usage *ir.Usage) bool {
+	return usage == nil || usage.TotalTokens == 0
+}
+
+// usageEstimator collects a stream's output for estimating its usage.
+type usageEstimator struct {
+	request   []byte
+	output    strings.Builder
+	reasoning strings.Builder
+}
+
+// record adds the generated text of event.
+func (e *usageEstimator) record(event *ir.UnifiedEvent) {
+	switch event.Type {
+	case ir.EventTypeToken:
+		e.output.WriteString(event.Content)
+	case ir.EventTypeReasoning:
+		e.reasoning.WriteString(event.Reasoning)
+	case ir.EventTypeReasoningSummary:
+		e.reasoning.WriteString(event.ReasoningSummary)
+	case ir.EventTypeToolCall, ir.EventTypeToolCallDelta:
+		if event.ToolCall != nil {
+			e.output.WriteString(event.ToolCall.Name)
+			e.output.WriteString(event.ToolCall.Args)
+		}
+	}
+}
+
+// estimateUsage counts tokens locally: the prompt from request, a client
+// request in format from, and the completion from output and reasoning.
+func estimateUsage(from provider.Format, model string, request []byte, output, reasoning string) *ir.Usage {
+	var prompt int64
+	if len(request) > 0 {
+		if irReq, err := ConvertRequestToIR(from, model, request, nil); err == nil {
+			prompt = util.CountTokensFromIR(model, irReq)
+		}
+		if prompt == 0 {
+			prompt = int64(len(request) / 4)
+		}
+	}
+	thoughts := countOutputTokens(model, reasoning)
+	completion := countOutputTokens(model, output) + thoughts
+	return &ir.Usage{
+		PromptTokens:       prompt,
+		CompletionTokens:   completion,
+		TotalTokens:        prompt + completion,
+		ThoughtsTokenCount: int32(thoughts),
+		Estimated:          true,
+	}
+}
+
+// countOutputTokens counts text as one assistant message, falling back to
+// four characters per token when no tokenizer is available for model.
+func countOutputTokens(model, text string) int64 {
+	if text == "" {
+		return 0
+	}
+	n := util.CountTokensFromIR(model, &ir.UnifiedChatRequest{
+		Model:    model,
+		Messages: []ir.Message{{Role: ir.RoleAssistant, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: text}}}},
+	})
+	if n == 0 {
+		n = int64(len(text)/4) + 1
+	}
+	return n
+}
+
+// candidateOutput returns the text, tool calls and reasoning of the first
+// candidate, the output estimated usage is counted from.
+func candidateOutput(candidates []ir.CandidateResult) (output, reasoning string) {
+	if len(candidates) == 0 {
+		return "", ""
+	}
+	var out, thought strings.Builder
+	for _, msg := range candidates[0].Messages {
+		for _, part := range msg.Content {
+			out.WriteString(part.Text)
+			thought.WriteString(part.Reasoning)
+		}
+		for _, tc := range msg.ToolCalls {
+			out.WriteString(tc.Name)
+			out.WriteString(tc.Args)
+		}
+	}
+	return out.String(), thought.String()
+}
+
+// withEstimatedUsage adds estimated usage to a response passed through in
+// the client format when it has none. Responses that cannot be parsed are
+// returned unchanged.
+func withEstimatedUsage(to provider.Format, model string, request, response []byte) []byte {
+	toStr := to.String()
+	var totalPath string
+	switch {
+	case toStr == "openai" || toStr == "cline" || toStr == "codex" || toStr == "openai-response":
+		totalPath = "usage.total_tokens"
+	case toStr == "claude":
+		if gjson.GetBytes(response, "usage.input_tokens").Int()+gjson.GetBytes(response, "usage.output_tokens").Int() > 0 {
+			return response
+		}
+	case toStr == "gemini":
+		totalPath = "usageMetadata.totalTokenCount"
+	default:
+		return response
+	}
+	if totalPath != "" && gjson.GetBytes(response, totalPath).Int() > 0 {
+		return response
+	}
+
+	parsed, err := parseSourceResponse(toStr, response)
+	if err != nil || parsed == nil {
+		return response
+	}
+	output, reasoning := candidateOutput(parsed.Candidates)
+	usage := estimateUsage(to, model, request, output, reasoning)
+
+	var path string
+	var value map[string]any
+	switch {
+	case toStr == "openai" || toStr == "cline":
+		path, value = "usage", map[string]any{"prompt_tokens": usage.PromptTokens, "completion_tokens": usage.CompletionTokens, "total_tokens": usage.TotalTokens, "estimated": true}
+	case toStr == "codex" || toStr == "openai-response":
+		path, value = "usage", map[string]any{"input_tokens": usage.PromptTokens, "output_tokens": usage.CompletionTokens, "total_tokens": usage.TotalTokens, "estimated": true}
+	case toStr == "claude":
+		path, value = "usage", map[string]any{"input_tokens": usage.PromptTokens, "output_tokens": usage.CompletionTokens, "estimated": true}
+	default:
+		path, value = "usageMetadata", map[string]any{"promptTokenCount": usage.PromptTokens, "candidatesTokenCount": usage.CompletionTokens - int64(usage.ThoughtsTokenCount), "totalTokenCount": usage.TotalTokens, "estimated": true}
+		if usage.ThoughtsTokenCount > 0 {
+			value["thoughtsTokenCount"] = usage.ThoughtsTokenCount
+		}
+	}
+	out, err := sjson.SetBytes(response, path, value)
+	if err != nil {
+		return response
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	usageTestRequest  = `{"model":"gpt-4o","messages":[{"role":"user","content":"Say hello to the whole world"}]}`
+	openAINoUsage     = `{"id":"x","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hello, world!"},"finish_reason":"stop"}]}`
+	openAIStreamNoUse = `{"id":"x","choices":[{"index":0,"delta":{"content":"Hello, world!"}}]}`
+)
+
+func estimateUsageConfig(enabled bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.EstimateMissingUsage = enabled
+	return cfg
+}
+
+func TestMissingUsageEstimatedNonStream(t *testing.T) {
+	tests := []struct {
+		to                        provider.Format
+		request                   string
+		usage, prompt, completion string
+	}{
+		{provider.FormatOpenAI, usageTestRequest, "usage", "prompt_tokens", "completion_tokens"},
+		{provider.FormatClaude, `{"model":"gpt-4o","max_tokens":64,"messages":[{"role":"user","content":"Say hello to the whole world"}]}`, "usage", "input_tokens", "output_tokens"},
+		{provider.FormatGemini, `{"contents":[{"role":"user","parts":[{"text":"Say hello to the whole world"}]}]}`, "usageMetadata", "promptTokenCount", "candidatesTokenCount"},
+	}
+	for _, tt := range tests {
+		out, err := TranslateResponseNonStream(estimateUsageConfig(true), provider.FormatOpenAI, tt.to, []byte(tt.request), []byte(openAINoUsage), "gpt-4o")
+		if err != nil {
+			t.Fatalf("%s: TranslateResponseNonStream: %v", tt.to, err)
+		}
+		usage := gjson.GetBytes(out, tt.usage)
+		if !usage.Get("estimated").Bool() || usage.Get(tt.prompt).Int() < 5 || usage.Get(tt.completion).Int() == 0 {
+			t.Errorf("%s: usage = %s, want estimated prompt and completion tokens", tt.to, usage.Raw)
+		}
+	}
+
+	out, err := TranslateResponseNonStream(estimateUsageConfig(false), provider.FormatOpenAI, provider.FormatOpenAI, []byte(usageTestRequest), []byte(openAINoUsage), "gpt-4o")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if gjson.GetBytes(out, "usage").Exists() {
+		t.Errorf("usage estimated while disabled: %s", out)
+	}
+}
+
+func TestReportedUsageNotEstimated(t *testing.T) {
+	out, err := TranslateResponseNonStream(estimateUsageConfig(true), provider.FormatGemini, provider.FormatOpenAI, []byte(usageTestRequest), []byte(geminiWithResponseID), "gpt-4o")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	if gjson.GetBytes(out, "usage.estimated").Exists() || gjson.GetBytes(out, "usage.total_tokens").Int() != 4 {
+		t.Errorf("usage = %s, want the reported 4 tokens", gjson.GetBytes(out, "usage").Raw)
+	}
+}
+
+func translateOpenAIStream(t *testing.T, st *StreamTranslator, lines ...string) [][]byte {
+	t.Helper()
+	var chunks [][]byte
+	for _, line := range lines {
+		events, err := to_ir.ParseOpenAIChunk([]byte(line))
+		if err != nil {
+			t.Fatalf("ParseOpenAIChunk: %v", err)
+		}
+		res, err := st.Translate(events)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		chunks = append(chunks, res.Chunks...)
+	}
+	flushed, err := st.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return append(chunks, flushed...)
+}
+
+func TestMissingUsageEstimatedStream(t *testing.T) {
+	finish := `{"id":"x","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`
+
+	st := NewStreamTranslator(estimateUsageConfig(true), provider.FormatOpenAI, "openai", "gpt-4o", "chatcmpl-gpt-4o", nil)
+	st.EnableUsageEstimate([]byte(usageTestRequest))
+	var usages []gjson.Result
+	for _, chunk := range translateOpenAIStream(t, st, openAIStreamNoUse, finish, `[DONE]`) {
+		if u := gjson.GetBytes(ir.ExtractSSEData(chunk), "usage"); u.Exists() {
+			usages = append(usages, u)
+		}
+	}
+	if len(usages) != 1 || !usages[0].Get("estimated").Bool() || usages[0].Get("prompt_tokens").Int() == 0 || usages[0].Get("completion_tokens").Int() == 0 {
+		t.Errorf("usage chunks = %v, want one estimated usage", usages)
+	}
+
+	st = NewStreamTranslator(estimateUsageConfig(true), provider.FormatOpenAI, "claude", "gpt-4o", "msg-gpt-4o", nil)
+	st.EnableUsageEstimate([]byte(`{"model":"gpt-4o","max_tokens":64,"messages":[{"role":"user","content":"Say hello"}]}`))
+	chunks := bytes.Join(translateOpenAIStream(t, st, openAIStreamNoUse, finish), nil)
+	if !bytes.Contains(chunks, []byte(`"estimated":true`)) {
+		t.Errorf("claude stream has no estimated usage: %s", chunks)
+	}
+
+	st = NewStreamTranslator(estimateUsageConfig(false), provider.FormatOpenAI, "openai", "gpt-4o", "chatcmpl-gpt-4o", nil)
+	st.EnableUsageEstimate([]byte(usageTestRequest))
+	for _, chunk := range translateOpenAIStream(t, st, openAIStreamNoUse, finish, `[DONE]`) {
+		if gjson.GetBytes(ir.ExtractSSEData(chunk), "usage").Exists() {
+			t.Errorf("usage estimated while disabled: %s", chunk)
+		}
+	}
+}
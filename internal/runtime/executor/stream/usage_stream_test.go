@@ -0,0 +1,50 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAITrailingUsageChunkIsForwarded(t *testing.T) {
+	lines := []string{
+		`{"id":"x","choices":[{"index":0,"delta":{"content":"Hi"}}]}`,
+		`{"id":"x","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`{"id":"x","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`,
+		`[DONE]`,
+	}
+	st := NewStreamTranslator(nil, provider.FormatOpenAI, "openai", "gpt-test", "chatcmpl-gpt-test", nil)
+
+	var chunks [][]byte
+	for _, line := range lines {
+		events, err := to_ir.ParseOpenAIChunk([]byte(line))
+		if err != nil {
+			t.Fatalf("ParseOpenAIChunk: %v", err)
+		}
+		res, err := st.Translate(events)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		chunks = append(chunks, res.Chunks...)
+	}
+
+	var finishes, usages int
+	for _, chunk := range chunks {
+		data := ir.ExtractSSEData(chunk)
+		if gjson.GetBytes(data, "choices.0.finish_reason").String() != "" {
+			finishes++
+		}
+		if u := gjson.GetBytes(data, "usage"); u.Exists() {
+			usages++
+			if len(gjson.GetBytes(data, "choices").Array()) != 0 || u.Get("total_tokens").Int() != 4 {
+				t.Errorf("usage chunk = %s, want empty choices and 4 total tokens", data)
+			}
+		}
+	}
+	if finishes != 1 || usages != 1 {
+		t.Errorf("got %d finish and %d usage chunks, want one of each", finishes, usages)
+	}
+}
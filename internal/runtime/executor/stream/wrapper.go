@@ -1,14 +1,21 @@
 package stream
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/sseutil"
+	"github.com/nghyane/llm-mux/internal/telemetry"
 	"github.com/nghyane/llm-mux/internal/translator"
 	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/preprocess"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 )
 
 func ExtractUsageFromEvents(events []ir.UnifiedEvent) *ir.Usage {
@@ -24,7 +31,7 @@ func ExtractUsageFromEvents(events []ir.UnifiedEvent) *ir.Usage {
 type TranslationResult struct {
 	Payload              []byte                 // Translated payload
 	EstimatedInputTokens int64                  // Pre-calculated input token count (0 if not applicable)
-	IR                   *ir.UnifiedChatRequest // Parsed IR (for advanced use cases)
+	IR                   *ir.UnifiedChatRequest // Parsed IR (for advanced use cases); nil when served from the translation cache
 }
 
 type StreamTranslationResult struct {
@@ -32,30 +39,42 @@ type StreamTranslationResult struct {
 	Usage  *ir.Usage // Usage extracted from IR events (nil if not present in this chunk)
 }
 
-func TranslateToGeminiWithTokens(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) (*TranslationResult, error) {
-	irReq, err := ConvertRequestToIR(from, model, payload, metadata)
-	if err != nil {
-		return nil, err
-	}
+func TranslateToGeminiWithTokens(ctx context.Context, cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) (*TranslationResult, error) {
+	var irReq *ir.UnifiedChatRequest
+	body, err := cachedTranslation("gemini", from, model, payload, streaming, metadata, func() ([]byte, error) {
+		var errConvert error
+		irReq, errConvert = ConvertRequestToIR(from, model, payload, metadata)
+		if errConvert != nil {
+			return nil, errConvert
+		}
+		if errConvert = InlineRemoteImages(ctx, irReq); errConvert != nil {
+			return nil, errConvert
+		}
 
-	geminiJSON, err := translator.ConvertRequest("gemini", irReq)
+		_, span := startTranslateSpan(metadata, "gemini", irReq.Model)
+		geminiJSON, errConvert := translator.ConvertRequest("gemini", irReq)
+		telemetry.RecordError(span, errConvert)
+		span.End()
+		if errConvert != nil {
+			return nil, errConvert
+		}
+		return sseutil.ApplyPayloadConfig(cfg, model, geminiJSON), nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	result := &TranslationResult{
-		Payload: sseutil.ApplyPayloadConfig(cfg, model, geminiJSON),
-		IR:      irReq,
-	}
-
-	return result, nil
+	return &TranslationResult{Payload: body, IR: irReq}, nil
 }
 
 func ConvertRequestToIR(from provider.Format, model string, payload []byte, metadata map[string]any) (*ir.UnifiedChatRequest, error) {
 	payload = sseutil.SanitizeUndefinedValues(payload)
 
 	formatStr := from.String()
+	_, parseSpan := telemetry.StartSpanFromMetadata(metadata, telemetry.SpanParse,
+		telemetry.AttrFormat.String(formatStr), telemetry.AttrModel.String(model))
 	irReq, err := translator.ParseRequest(formatStr, payload)
+	telemetry.RecordError(parseSpan, err)
+	parseSpan.End()
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +88,10 @@ func ConvertRequestToIR(from provider.Format, model string, payload []byte, meta
 			irReq.Metadata = make(map[string]any)
 		}
 		for k, v := range metadata {
+			if strings.HasPrefix(k, "__") {
+				// Internal per-request carriers such as the route trace.
+				continue
+			}
 			irReq.Metadata[k] = v
 		}
 	}
@@ -89,13 +112,71 @@ func ConvertRequestToIR(from provider.Format, model string, payload []byte, meta
 		}
 	}
 
+	ThoughtSignaturesFromMetadata(metadata).restore(irReq)
+
+	trace := provider.RouteTraceFromMetadata(metadata)
+	var before irSnapshot
+	if trace != nil {
+		before = snapshotIR(irReq)
+	}
+
+	_, preprocessSpan := telemetry.StartSpanFromMetadata(metadata, telemetry.SpanPreprocess, telemetry.AttrModel.String(irReq.Model))
 	NormalizeIRLimits(irReq.Model, irReq)
 	ApplyThinkingToIR(irReq.Model, irReq)
 	preprocess.Apply(irReq)
+	preprocessSpan.End()
+
+	if trace != nil {
+		trace.SetTransforms(describeTransforms(before, snapshotIR(irReq)))
+	}
+	if DebugThinkingEnabled(irReq.Model) {
+		logThinkingRequest(irReq)
+	}
+	ThinkingCaptureFromMetadata(metadata).begin(irReq.Model, payload)
 
 	return irReq, nil
 }
 
+// irSnapshot captures the IR fields that preprocessing may rewrite.
+type irSnapshot struct {
+	model          string
+	thinkingBudget *int32
+	maxTokens      *int
+}
+
+func snapshotIR(req *ir.UnifiedChatRequest) irSnapshot {
+	s := irSnapshot{model: req.Model}
+	if req.Thinking != nil && req.Thinking.ThinkingBudget != nil {
+		b := *req.Thinking.ThinkingBudget
+		s.thinkingBudget = &b
+	}
+	if req.MaxTokens != nil {
+		m := *req.MaxTokens
+		s.maxTokens = &m
+	}
+	return s
+}
+
+// describeTransforms lists the normalizations applied between two snapshots
+// in the form reported by the X-LLM-Mux-Transforms debug header.
+func describeTransforms(before, after irSnapshot) []string {
+	var out []string
+	if before.model != after.model {
+		out = append(out, "model="+before.model+"->"+after.model)
+	}
+	if after.thinkingBudget != nil {
+		if before.thinkingBudget != nil && *before.thinkingBudget != *after.thinkingBudget {
+			out = append(out, fmt.Sprintf("thinking_budget=%d->%d", *before.thinkingBudget, *after.thinkingBudget))
+		} else {
+			out = append(out, fmt.Sprintf("thinking_budget=%d", *after.thinkingBudget))
+		}
+	}
+	if before.maxTokens != nil && after.maxTokens != nil && *before.maxTokens != *after.maxTokens {
+		out = append(out, fmt.Sprintf("max_tokens=%d->%d", *before.maxTokens, *after.maxTokens))
+	}
+	return out
+}
+
 func NormalizeIRLimits(model string, req *ir.UnifiedChatRequest) {
 	if model == "" {
 		return
@@ -159,46 +240,94 @@ func ExtractThinkingFromMetadata(metadata map[string]any) (budget *int, include
 }
 
 func TranslateToCodex(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
-	irReq, err := ConvertRequestToIR(from, model, payload, metadata)
-	if err != nil {
-		return nil, err
-	}
-	return from_ir.ToOpenAIRequestFmt(irReq, from_ir.FormatResponsesAPI)
+	return cachedTranslation("codex", from, model, payload, streaming, metadata, func() ([]byte, error) {
+		irReq, err := ConvertRequestToIR(from, model, payload, metadata)
+		if err != nil {
+			return nil, err
+		}
+		_, span := startTranslateSpan(metadata, "codex", irReq.Model)
+		defer span.End()
+		body, err := from_ir.ToOpenAIRequestFmt(irReq, from_ir.FormatResponsesAPI)
+		telemetry.RecordError(span, err)
+		return body, err
+	})
 }
 
-func TranslateToClaude(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
-	irReq, err := ConvertRequestToIR(from, model, payload, metadata)
-	if err != nil {
-		return nil, err
-	}
-	return translator.ConvertRequest("claude", irReq)
+func TranslateToClaude(ctx context.Context, cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
+	return cachedTranslation("claude", from, model, payload, streaming, metadata, func() ([]byte, error) {
+		irReq, err := ConvertRequestToIR(from, model, payload, metadata)
+		if err != nil {
+			return nil, err
+		}
+		if err = InlineRemoteImages(ctx, irReq); err != nil {
+			return nil, err
+		}
+		_, span := startTranslateSpan(metadata, "claude", irReq.Model)
+		defer span.End()
+		body, err := translator.ConvertRequest("claude", irReq)
+		telemetry.RecordError(span, err)
+		return body, err
+	})
 }
 
 func TranslateToOpenAI(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
 	fromStr := from.String()
 	if fromStr == "openai" || fromStr == "cline" {
-		return sseutil.ApplyPayloadConfig(cfg, model, payload), nil
+		return sseutil.ApplyPayloadConfig(cfg, model, renameMaxTokensField(model, payload)), nil
 	}
 
-	irReq, err := ConvertRequestToIR(from, model, payload, metadata)
-	if err != nil {
-		return nil, err
+	return cachedTranslation("openai", from, model, payload, streaming, metadata, func() ([]byte, error) {
+		irReq, err := ConvertRequestToIR(from, model, payload, metadata)
+		if err != nil {
+			return nil, err
+		}
+		_, span := startTranslateSpan(metadata, "openai", irReq.Model)
+		openaiJSON, err := from_ir.ToOpenAIRequest(irReq)
+		telemetry.RecordError(span, err)
+		span.End()
+		if err != nil {
+			return nil, err
+		}
+		return sseutil.ApplyPayloadConfig(cfg, model, openaiJSON), nil
+	})
+}
+
+// renameMaxTokensField moves a passthrough request's max_tokens to
+// max_completion_tokens when the target model rejects the former.
+func renameMaxTokensField(model string, payload []byte) []byte {
+	if from_ir.OpenAIMaxTokensField(model) != "max_completion_tokens" {
+		return payload
 	}
-	openaiJSON, err := from_ir.ToOpenAIRequest(irReq)
+	maxTokens := gjson.GetBytes(payload, "max_tokens")
+	if !maxTokens.Exists() {
+		return payload
+	}
+	out, err := sjson.DeleteBytes(payload, "max_tokens")
 	if err != nil {
-		return nil, err
+		return payload
+	}
+	if !gjson.GetBytes(out, "max_completion_tokens").Exists() {
+		if out, err = sjson.SetRawBytes(out, "max_completion_tokens", []byte(maxTokens.Raw)); err != nil {
+			return payload
+		}
 	}
-	return sseutil.ApplyPayloadConfig(cfg, model, openaiJSON), nil
+	return out
 }
 
-func TranslateToGemini(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
-	result, err := TranslateToGeminiWithTokens(cfg, from, model, payload, streaming, metadata)
+func TranslateToGemini(ctx context.Context, cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
+	result, err := TranslateToGeminiWithTokens(ctx, cfg, from, model, payload, streaming, metadata)
 	if err != nil {
 		return nil, err
 	}
 	return result.Payload, nil
 }
 
+// startTranslateSpan starts the span covering IR to provider request conversion.
+func startTranslateSpan(metadata map[string]any, target, model string) (context.Context, *telemetry.Span) {
+	return telemetry.StartSpanFromMetadata(metadata, telemetry.SpanTranslate,
+		telemetry.AttrFormat.String(target), telemetry.AttrModel.String(model))
+}
+
 // ApplyThinkingToIR applies thinking configuration to the IR request
 func ApplyThinkingToIR(model string, req *ir.UnifiedChatRequest) {
 	// Get model info from registry
@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertRequestToIR_RecordsRouteTrace(t *testing.T) {
+	trace := &provider.RouteTrace{}
+	metadata := map[string]any{
+		provider.RouteTraceMetadataKey: trace,
+		"thinking_budget":              2048,
+	}
+	payload := []byte(`{"model":"unknown-model","messages":[{"role":"user","content":"hi"}]}`)
+
+	irReq, err := ConvertRequestToIR(provider.FormatOpenAI, "unknown-model", payload, metadata)
+	if err != nil {
+		t.Fatalf("ConvertRequestToIR failed: %v", err)
+	}
+	if _, ok := irReq.Metadata[provider.RouteTraceMetadataKey]; ok {
+		t.Error("Expected route trace to be stripped from IR metadata")
+	}
+	if got := trace.Headers().Get(provider.HeaderRouteTransforms); got != "thinking_budget=2048" {
+		t.Errorf("Expected thinking budget transform, got %q", got)
+	}
+}
+
+func TestDescribeTransforms(t *testing.T) {
+	budgetIn, budgetOut := int32(-1), int32(8192)
+	tokensIn, tokensOut := 100000, 64000
+	before := irSnapshot{model: "claude-sonnet-4", thinkingBudget: &budgetIn, maxTokens: &tokensIn}
+	after := irSnapshot{model: "claude-sonnet-4-thinking", thinkingBudget: &budgetOut, maxTokens: &tokensOut}
+
+	got := describeTransforms(before, after)
+	want := []string{
+		"model=claude-sonnet-4->claude-sonnet-4-thinking",
+		"thinking_budget=-1->8192",
+		"max_tokens=100000->64000",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Transform %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestTranslateToOpenAI_PassthroughMaxTokensField(t *testing.T) {
+	payload := []byte(`{"model":"o3","max_tokens":256,"messages":[{"role":"user","content":"hi"}]}`)
+	out, err := TranslateToOpenAI(nil, provider.FormatOpenAI, "o3", payload, false, nil)
+	if err != nil {
+		t.Fatalf("TranslateToOpenAI failed: %v", err)
+	}
+	if gjson.GetBytes(out, "max_tokens").Exists() || gjson.GetBytes(out, "max_completion_tokens").Int() != 256 {
+		t.Errorf("reasoning model payload = %s, want max_completion_tokens 256", out)
+	}
+
+	payload = []byte(`{"model":"gpt-4o","max_tokens":256,"messages":[{"role":"user","content":"hi"}]}`)
+	out, err = TranslateToOpenAI(nil, provider.FormatOpenAI, "gpt-4o", payload, false, nil)
+	if err != nil {
+		t.Fatalf("TranslateToOpenAI failed: %v", err)
+	}
+	if string(out) != string(payload) {
+		t.Errorf("non-reasoning payload changed: %s", out)
+	}
+}
@@ -283,3 +283,21 @@ func CountTokensForOpenAIProvider(
 	usageJSON := buildOpenAIUsageJSON(count)
 	return provider.Response{Payload: usageJSON}, nil
 }
+
+// CountEmbeddingTokens estimates the prompt tokens of embedding inputs for
+// providers whose embedding responses carry no usage.
+func CountEmbeddingTokens(model string, inputs []string) int64 {
+	enc, err := tokenizerForModel(model)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, input := range inputs {
+		count, errCount := enc.Count(input)
+		if errCount != nil {
+			continue
+		}
+		total += int64(count)
+	}
+	return total
+}
@@ -76,6 +76,13 @@ func SOCKS5Transport(dialFunc func(network, addr string) (net.Conn, error)) *htt
 	return t
 }
 
+// ConfigureSharedTransport re-applies transport.Config to SharedTransport and
+// the shared resilience client. Call it once at startup, before any request.
+func ConfigureSharedTransport() {
+	transport.Apply(SharedTransport)
+	transport.ApplyShared()
+}
+
 func CloseIdleConnections() {
 	SharedTransport.CloseIdleConnections()
 }
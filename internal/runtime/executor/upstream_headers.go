@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// credentialHeaders carry the auth's credentials. Configured upstream
+// headers never replace them once an executor has set them.
+var credentialHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"X-Api-Key":           {},
+	"X-Goog-Api-Key":      {},
+	"Cookie":              {},
+}
+
+// headerTransport adds configured static headers to every request it sends.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	applyUpstreamHeaders(req.Header, t.headers)
+	return t.base.RoundTrip(req)
+}
+
+// applyUpstreamHeaders merges headers into dst. They replace the executor's
+// own headers, except credential headers, which win on conflict.
+func applyUpstreamHeaders(dst http.Header, headers map[string]string) {
+	for k, v := range headers {
+		key := http.CanonicalHeaderKey(k)
+		if _, ok := credentialHeaders[key]; ok && dst.Get(key) != "" {
+			continue
+		}
+		dst.Set(key, v)
+	}
+}
+
+// upstreamHeadersFor returns the configured headers of auth's provider.
+func upstreamHeadersFor(cfg *config.Config, auth *provider.Auth) map[string]string {
+	if cfg == nil || auth == nil || len(cfg.UpstreamHeaders) == 0 {
+		return nil
+	}
+	return cfg.UpstreamHeaders[strings.ToLower(auth.Provider)]
+}
+
+// withUpstreamHeaders wraps base to add the configured headers of auth's
+// provider, or returns base when there are none.
+func withUpstreamHeaders(base http.RoundTripper, cfg *config.Config, auth *provider.Auth) http.RoundTripper {
+	headers := upstreamHeadersFor(cfg, auth)
+	if len(headers) == 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &headerTransport{base: base, headers: headers}
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestNewProxyAwareHTTPClient_UpstreamHeadersMergePrecedence(t *testing.T) {
+	received := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{UpstreamHeaders: config.UpstreamHeaders{
+		"claude": {
+			"X-Organization": "org-123",
+			"anthropic-beta": "context-1m-2025-08-07",
+			"x-api-key":      "configured-key",
+			"Authorization":  "Bearer configured",
+		},
+	}}
+	auth := &provider.Auth{ID: "a1", Provider: "claude"}
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, nil)
+	req.Header.Set("X-Api-Key", "auth-key")
+	req.Header.Set("Anthropic-Beta", "oauth-2025-04-20")
+
+	client := NewProxyAwareHTTPClient(context.Background(), cfg, auth, 0)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	got := <-received
+
+	if v := got.Get("X-Organization"); v != "org-123" {
+		t.Errorf("Expected configured header to be added, got %q", v)
+	}
+	if v := got.Get("Anthropic-Beta"); v != "context-1m-2025-08-07" {
+		t.Errorf("Expected configured header to replace the executor's, got %q", v)
+	}
+	if v := got.Get("X-Api-Key"); v != "auth-key" {
+		t.Errorf("Expected the auth credential to win on conflict, got %q", v)
+	}
+	if v := got.Get("Authorization"); v != "Bearer configured" {
+		t.Errorf("Expected a credential header the executor left unset to be added, got %q", v)
+	}
+	if v := req.Header.Get("X-Organization"); v != "" {
+		t.Errorf("Expected the caller's request to stay unmodified, got X-Organization %q", v)
+	}
+}
+
+func TestNewProxyAwareHTTPClient_UpstreamHeadersPerProvider(t *testing.T) {
+	cfg := &config.Config{UpstreamHeaders: config.UpstreamHeaders{"claude": {"X-Organization": "org-123"}}}
+
+	client := NewProxyAwareHTTPClient(context.Background(), cfg, &provider.Auth{Provider: "gemini"}, 0)
+	if _, wrapped := client.Transport.(*headerTransport); wrapped {
+		t.Error("Expected providers without configured headers to use the plain transport")
+	}
+	client = NewProxyAwareHTTPClient(context.Background(), cfg, &provider.Auth{Provider: "Claude"}, 0)
+	if _, wrapped := client.Transport.(*headerTransport); !wrapped {
+		t.Error("Expected provider names to match case-insensitively")
+	}
+}
@@ -2,13 +2,13 @@ package executor
 
 import (
 	"context"
-	"fmt"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/gin-gonic/gin"
+	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/telemetry"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/nghyane/llm-mux/internal/usage"
@@ -20,8 +20,10 @@ type UsageReporter struct {
 	model       string
 	authID      string
 	authIndex   uint64
-	apiKey      string
+	apiKeyID    string
 	source      string
+	requestID   string
+	operation   string
 	requestedAt time.Time
 	once        sync.Once
 }
@@ -30,13 +32,14 @@ type UsageReporter struct {
 type usageReporter = UsageReporter
 
 func NewUsageReporter(ctx context.Context, provider, model string, auth *provider.Auth) *UsageReporter {
-	apiKey := apiKeyFromContext(ctx)
+	apiKeyID := usage.APIKeyIDFromContext(ctx)
 	reporter := &usageReporter{
 		provider:    provider,
 		model:       model,
 		requestedAt: time.Now(),
-		apiKey:      apiKey,
-		source:      resolveUsageSource(auth, apiKey),
+		apiKeyID:    apiKeyID,
+		source:      resolveUsageSource(auth, apiKeyID),
+		requestID:   log.RequestIDFromContext(ctx),
 	}
 	if auth != nil {
 		reporter.authID = auth.ID
@@ -45,6 +48,12 @@ func NewUsageReporter(ctx context.Context, provider, model string, auth *provide
 	return reporter
 }
 
+// WithOperation tags the records published by r with a usage.Operation* type.
+func (r *UsageReporter) WithOperation(op string) *UsageReporter {
+	r.operation = op
+	return r
+}
+
 func (r *usageReporter) publish(ctx context.Context, u *ir.Usage) {
 	r.publishWithOutcome(ctx, u, false)
 }
@@ -63,6 +72,40 @@ func (r *usageReporter) PublishFailure(ctx context.Context) {
 	r.publishFailure(ctx)
 }
 
+// PublishPartialFailure records a failed request together with the usage
+// consumed before it failed, such as an upstream error mid-stream.
+func (r *usageReporter) PublishPartialFailure(ctx context.Context, u *ir.Usage) {
+	r.publishWithOutcome(ctx, u, true)
+}
+
+// PublishCanceled records a stream the client abandoned, together with the
+// usage consumed before the disconnect. It is published without the request
+// context's cancellation so plugins can still persist it.
+func (r *usageReporter) PublishCanceled(ctx context.Context, u *ir.Usage) {
+	if r == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	r.once.Do(func() {
+		if u != nil {
+			telemetry.RecordUsage(ctx, u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+		}
+		usage.PublishRecord(ctx, usage.Record{
+			Provider:    r.provider,
+			Model:       r.model,
+			Source:      r.source,
+			AuthID:      r.authID,
+			AuthIndex:   r.authIndex,
+			RequestedAt: r.requestedAt,
+			Canceled:    true,
+			Usage:       u,
+			RequestID:   r.requestID,
+			Operation:   r.operation,
+			APIKeyID:    r.apiKeyID,
+		})
+	})
+}
+
 func (r *usageReporter) trackFailure(ctx context.Context, errPtr *error) {
 	if r == nil || errPtr == nil {
 		return
@@ -79,6 +122,18 @@ func (r *UsageReporter) TrackFailure(ctx context.Context, errPtr *error) {
 	r.trackFailure(ctx, errPtr)
 }
 
+// ReportTokenCount records a count_tokens call under usage.OperationCountTokens.
+// Counting consumes no billed tokens, so the record carries none; failures are
+// recorded like those of any other request.
+func (b *BaseExecutor) ReportTokenCount(ctx context.Context, prov, model string, auth *provider.Auth, errPtr *error) {
+	reporter := NewUsageReporter(ctx, prov, model, auth).WithOperation(usage.OperationCountTokens)
+	if errPtr != nil && *errPtr != nil {
+		reporter.trackFailure(ctx, errPtr)
+		return
+	}
+	reporter.ensurePublished(ctx)
+}
+
 func isUserError(err error) bool {
 	if err == nil {
 		return false
@@ -109,16 +164,21 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, u *ir.Usage, fai
 		return
 	}
 	r.once.Do(func() {
+		if u != nil {
+			telemetry.RecordUsage(ctx, u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+		}
 		usage.PublishRecord(ctx, usage.Record{
 			Provider:    r.provider,
 			Model:       r.model,
 			Source:      r.source,
-			APIKey:      r.apiKey,
 			AuthID:      r.authID,
 			AuthIndex:   r.authIndex,
 			RequestedAt: r.requestedAt,
 			Failed:      failed,
 			Usage:       u,
+			RequestID:   r.requestID,
+			Operation:   r.operation,
+			APIKeyID:    r.apiKeyID,
 		})
 	})
 }
@@ -132,12 +192,14 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 			Provider:    r.provider,
 			Model:       r.model,
 			Source:      r.source,
-			APIKey:      r.apiKey,
 			AuthID:      r.authID,
 			AuthIndex:   r.authIndex,
 			RequestedAt: r.requestedAt,
 			Failed:      false,
 			Usage:       nil,
+			RequestID:   r.requestID,
+			Operation:   r.operation,
+			APIKeyID:    r.apiKeyID,
 		})
 	})
 }
@@ -147,28 +209,7 @@ func (r *usageReporter) EnsurePublished(ctx context.Context) {
 	r.ensurePublished(ctx)
 }
 
-func apiKeyFromContext(ctx context.Context) string {
-	if ctx == nil {
-		return ""
-	}
-	ginCtx, ok := ctx.Value("gin").(*gin.Context)
-	if !ok || ginCtx == nil {
-		return ""
-	}
-	if v, exists := ginCtx.Get("apiKey"); exists {
-		switch value := v.(type) {
-		case string:
-			return value
-		case fmt.Stringer:
-			return value.String()
-		default:
-			return fmt.Sprintf("%v", value)
-		}
-	}
-	return ""
-}
-
-func resolveUsageSource(auth *provider.Auth, ctxAPIKey string) string {
+func resolveUsageSource(auth *provider.Auth, apiKeyID string) string {
 	if auth != nil {
 		provider := strings.TrimSpace(auth.Provider)
 		if strings.EqualFold(provider, "gemini-cli") {
@@ -201,13 +242,10 @@ func resolveUsageSource(auth *provider.Auth, ctxAPIKey string) string {
 			}
 		}
 		if key := AttrStringValue(auth.Attributes, "api_key"); key != "" {
-			return key
+			return usage.APIKeyID(key)
 		}
 	}
-	if trimmed := strings.TrimSpace(ctxAPIKey); trimmed != "" {
-		return trimmed
-	}
-	return ""
+	return apiKeyID
 }
 
 func extractUsageFromClaudeResponse(data []byte) *ir.Usage {
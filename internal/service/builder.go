@@ -26,6 +26,8 @@ type Builder struct {
 	accessManager  *access.Manager
 	coreManager    *provider.Manager
 	serverOptions  []api.ServerOption
+	// staticConfig disables config file reloading.
+	staticConfig bool
 }
 
 // Hooks allows callers to plug into service lifecycle stages.
@@ -39,6 +41,11 @@ type Hooks struct {
 	// OnAfterStart is called after the service has started successfully,
 	// providing access to the service instance for additional operations.
 	OnAfterStart func(*Service)
+
+	// OnResult receives the result of every upstream call and response cache
+	// hit, for exporting request analytics. It runs on the request path and
+	// must not block.
+	OnResult func(provider.Result)
 }
 
 // NewBuilder creates a Builder with default dependencies left unset.
@@ -92,6 +99,14 @@ func (b *Builder) WithWatcherFactory(factory WatcherFactory) *Builder {
 	return b
 }
 
+// WithConfigWatch controls whether edits to the config file are applied while
+// the service runs. Watching is on by default; when off, the config is read
+// once at startup. Auth files are watched either way.
+func (b *Builder) WithConfigWatch(enabled bool) *Builder {
+	b.staticConfig = !enabled
+	return b
+}
+
 // WithHooks registers lifecycle hooks executed around service startup.
 func (b *Builder) WithHooks(h Hooks) *Builder {
 	b.hooks = h
@@ -183,6 +198,9 @@ func (b *Builder) Build() (*Service, error) {
 	}
 	// Attach a default RoundTripper provider so providers can opt-in per-auth transports.
 	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
+	if b.hooks.OnResult != nil {
+		coreManager.SetResultSink(b.hooks.OnResult)
+	}
 
 	// Register quota sync plugin if QuotaManager is active
 	if qm := coreManager.GetQuotaManager(); qm != nil {
@@ -201,6 +219,7 @@ func (b *Builder) Build() (*Service, error) {
 		accessManager:  accessManager,
 		coreManager:    coreManager,
 		serverOptions:  append([]api.ServerOption(nil), b.serverOptions...),
+		staticConfig:   b.staticConfig,
 	}
 
 	serviceHook.SetService(service)
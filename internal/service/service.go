@@ -17,8 +17,12 @@ import (
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/config"
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/metrics"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/runtime/executor"
+	"github.com/nghyane/llm-mux/internal/runtime/executor/stream"
+	"github.com/nghyane/llm-mux/internal/telemetry"
 	"github.com/nghyane/llm-mux/internal/transport"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
@@ -37,6 +41,7 @@ type Service struct {
 	watcherFactory WatcherFactory
 	hooks          Hooks
 	serverOptions  []api.ServerOption
+	staticConfig   bool
 
 	server    *api.Server
 	serverErr chan error
@@ -53,6 +58,10 @@ type Service struct {
 
 	shutdownOnce sync.Once
 	wsGateway    *wsrelay.Manager
+
+	// stopTracing flushes and shuts down the tracer provider installed from
+	// config.Tracing; tracing changes take effect on restart.
+	stopTracing func(context.Context) error
 }
 
 // RegisterUsagePlugin registers a usage plugin on the global usage manager.
@@ -254,6 +263,9 @@ func (s *Service) applyCoreAuthAddOrUpdate(ctx context.Context, auth *provider.A
 		auth.CreatedAt = existing.CreatedAt
 		auth.LastRefreshedAt = existing.LastRefreshedAt
 		auth.NextRefreshAfter = existing.NextRefreshAfter
+		if auth.NextRetryAfter.IsZero() {
+			auth.NextRetryAfter = existing.NextRetryAfter
+		}
 		if _, err := s.coreManager.Update(ctx, auth); err != nil {
 			log.Errorf("failed to update auth %s: %v", auth.ID, err)
 		}
@@ -281,16 +293,157 @@ func (s *Service) applyCoreAuthRemoval(ctx context.Context, id string) {
 	}
 }
 
+// concurrencyLimits converts configured per-model limits for the provider manager.
+func concurrencyLimits(cfg config.ModelConcurrencyConfig) []provider.ConcurrencyLimit {
+	limits := make([]provider.ConcurrencyLimit, 0, len(cfg.Limits))
+	for _, limit := range cfg.Limits {
+		limits = append(limits, provider.ConcurrencyLimit{
+			Model:      limit.Model,
+			Max:        limit.MaxConcurrentRequests,
+			PerAccount: limit.PerAccount,
+		})
+	}
+	return limits
+}
+
 func (s *Service) applyRetryConfig(cfg *config.Config) {
 	if s == nil || s.coreManager == nil || cfg == nil {
 		return
 	}
 	maxInterval := time.Duration(cfg.MaxRetryInterval) * time.Second
 	s.coreManager.SetRetryConfig(cfg.RequestRetry, maxInterval)
+	provider.SetRetryJitter(cfg.RetryJitter)
+	s.coreManager.SetQueueConfig(cfg.RequestQueue.Limits())
+	s.coreManager.SetConcurrencyLimits(concurrencyLimits(cfg.ModelConcurrency), cfg.ModelConcurrency.Reject())
+	s.coreManager.SetRequestTimeouts(provider.RequestTimeouts{
+		Default:   cfg.RequestTimeout.DefaultTimeout(),
+		Providers: cfg.RequestTimeout.ProviderTimeouts(),
+	})
+	if err := s.coreManager.SetSelectionStrategy(cfg.AccountSelection.Strategy, cfg.AccountSelection.ExplorationFraction()); err != nil {
+		log.Warnf("account-selection: %v", err)
+	}
+	s.coreManager.SetRefreshLead(time.Duration(cfg.RefreshLead) * time.Second)
+	s.coreManager.SetHealthProbe(provider.HealthProbeConfig{
+		Interval:         cfg.HealthProbe.ProbeInterval(),
+		IncludeSuspended: cfg.HealthProbe.IncludeSuspended,
+	})
+	s.coreManager.SetStateWebhook(provider.StateWebhookConfig{
+		URL:      cfg.StateWebhook.URL,
+		Debounce: cfg.StateWebhook.DebounceInterval(),
+	})
 
 	if cfg.StreamTimeout > 0 {
 		transport.Config.ResponseHeaderTimeout = time.Duration(cfg.StreamTimeout) * time.Second
 	}
+	stream.SetThinkingCaptureSize(cfg.ThinkingCapture)
+	stream.SetTranslationCache(cfg.TranslationCache.TTLDuration(), cfg.TranslationCache.Entries())
+	stream.SetImageFetch(cfg.ImageFetch)
+	registry.GetGlobalRegistry().SetModelInfoOverrides(modelInfoOverrides(cfg.ModelInfo))
+}
+
+// modelInfoOverrides converts configured model info for the registry.
+func modelInfoOverrides(entries config.ModelInfoEntries) map[string]registry.ModelInfoOverride {
+	if len(entries) == 0 {
+		return nil
+	}
+	overrides := make(map[string]registry.ModelInfoOverride, len(entries))
+	for _, e := range entries {
+		o := registry.ModelInfoOverride{
+			ContextLength:       e.ContextLength,
+			InputTokenLimit:     e.InputTokenLimit,
+			OutputTokenLimit:    e.OutputTokenLimit,
+			MaxCompletionTokens: e.MaxCompletionTokens,
+			SupportedParameters: e.SupportedParameters,
+		}
+		if t := e.Thinking; t != nil {
+			o.Thinking = &registry.ThinkingSupport{Min: t.Min, Max: t.Max, ZeroAllowed: t.ZeroAllowed, DynamicAllowed: t.DynamicAllowed}
+		}
+		if p := e.Pricing; p != nil {
+			o.Pricing = &registry.ModelPricing{Input: p.Input, Output: p.Output, CachedInput: p.CachedInput}
+		}
+		if b := e.AnthropicBetas; b != nil {
+			o.AnthropicBetas = &registry.AnthropicBetas{
+				Base:           b.Base,
+				Thinking:       b.Thinking,
+				Tools:          b.Tools,
+				LongContext:    b.LongContext,
+				ExtendedOutput: b.ExtendedOutput,
+			}
+		}
+		overrides[strings.TrimSpace(e.ID)] = o
+	}
+	return overrides
+}
+
+// applyTransportConfig overlays the configured transport tuning onto
+// transport.Config and rebuilds the shared transports from it. Live transports
+// cannot be retuned safely, so this only runs at startup; changes to the
+// transport block take effect after a restart.
+func applyTransportConfig(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	t := cfg.Transport
+	if t.MaxIdleConns > 0 {
+		transport.Config.MaxIdleConns = t.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost > 0 {
+		transport.Config.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+	if t.MaxConnsPerHost > 0 {
+		transport.Config.MaxConnsPerHost = t.MaxConnsPerHost
+	}
+	if t.IdleConnTimeout > 0 {
+		transport.Config.IdleConnTimeout = time.Duration(t.IdleConnTimeout) * time.Second
+	}
+	if t.TLSHandshakeTimeout > 0 {
+		transport.Config.TLSHandshakeTimeout = time.Duration(t.TLSHandshakeTimeout) * time.Second
+	}
+	if t.DialTimeout > 0 {
+		transport.Config.DialTimeout = time.Duration(t.DialTimeout) * time.Second
+	}
+	if t.KeepAlive > 0 {
+		transport.Config.KeepAlive = time.Duration(t.KeepAlive) * time.Second
+	}
+	executor.ConfigureSharedTransport()
+}
+
+// prewarmTargets lists the providers that have at least one configured account.
+func (s *Service) prewarmTargets() []string {
+	seen := make(map[string]struct{})
+	var targets []string
+	add := func(name string) {
+		if name == "" {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		targets = append(targets, name)
+	}
+	if s.coreManager != nil {
+		for _, a := range s.coreManager.List() {
+			if a != nil && !a.Disabled {
+				add(strings.ToLower(a.Provider))
+			}
+		}
+	}
+	if s.cfg != nil {
+		for i := range s.cfg.Providers {
+			p := &s.cfg.Providers[i]
+			if !p.IsEnabled() || p.BaseURL != "" {
+				continue
+			}
+			switch p.Type {
+			case config.ProviderTypeGemini:
+				add("gemini")
+			case config.ProviderTypeAnthropic:
+				add("claude")
+			}
+		}
+	}
+	return targets
 }
 
 func openAICompatInfoFromAuth(a *provider.Auth) (providerKey string, compatName string, ok bool) {
@@ -354,9 +507,11 @@ func (s *Service) Run(ctx context.Context) error {
 
 	usage.StartDefault(ctx)
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer shutdownCancel()
 	defer func() {
+		// The deadline starts at shutdown: the grace period for in-flight
+		// requests plus time to stop the background workers.
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), s.shutdownGrace()+shutdownWorkerTimeout)
+		defer shutdownCancel()
 		if err := s.Shutdown(shutdownCtx); err != nil {
 			log.Errorf("service shutdown returned error: %v", err)
 		}
@@ -367,6 +522,16 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	applyTransportConfig(s.cfg)
+
+	stopTracing, errTracing := telemetry.Setup(s.cfg.Tracing)
+	if errTracing != nil {
+		log.Warnf("tracing disabled: %v", errTracing)
+	}
+	s.stopTracing = stopTracing
+	if s.cfg.Metrics.Prometheus {
+		metrics.SetDefault(metrics.NewPrometheus())
+	}
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
@@ -421,7 +586,7 @@ func (s *Service) Run(ctx context.Context) error {
 		s.hooks.OnBeforeStart(s.cfg)
 	}
 
-	go executor.PrewarmAntigravityConnections(ctx)
+	go executor.PrewarmProviders(ctx, s.prewarmTargets())
 
 	s.serverErr = make(chan error, 1)
 	go func() {
@@ -475,6 +640,9 @@ func (s *Service) Run(ctx context.Context) error {
 		return fmt.Errorf("cliproxy: failed to create watcher: %w", err)
 	}
 	s.watcher = watcherWrapper
+	if s.staticConfig {
+		watcherWrapper.DisableConfigWatch()
+	}
 	login.RegisterPendingWriteNotifier(watcherWrapper)
 	s.ensureAuthUpdateQueue(ctx)
 	if s.authUpdates != nil {
@@ -505,6 +673,10 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 }
 
+// shutdownWorkerTimeout bounds stopping the background workers once the
+// HTTP server has drained.
+const shutdownWorkerTimeout = 15 * time.Second
+
 // Shutdown gracefully stops background workers and the HTTP server.
 // It ensures all resources are properly cleaned up and connections are closed.
 // The shutdown is idempotent and can be called multiple times safely.
@@ -524,19 +696,29 @@ func (s *Service) Shutdown(ctx context.Context) error {
 			ctx = context.Background()
 		}
 
+		// Drain the HTTP server first so in-flight requests keep the watcher,
+		// quota manager and websocket providers they depend on.
+		if s.server != nil {
+			grace := s.shutdownGrace()
+			log.Infof("shutting down, waiting up to %s for in-flight requests", grace)
+			drainCtx, cancel := context.WithTimeout(ctx, grace)
+			err := s.server.Stop(drainCtx)
+			cancel()
+			if err != nil {
+				log.Errorf("error stopping API server: %v", err)
+				shutdownErr = err
+			}
+		}
+
 		if s.watcherCancel != nil {
 			s.watcherCancel()
 		}
-		if s.coreManager != nil {
-			s.coreManager.StopAutoRefresh()
-			if qm := s.coreManager.GetQuotaManager(); qm != nil {
-				qm.Stop()
-			}
-		}
 		if s.watcher != nil {
 			if err := s.watcher.Stop(); err != nil {
 				log.Errorf("failed to stop file watcher: %v", err)
-				shutdownErr = err
+				if shutdownErr == nil {
+					shutdownErr = err
+				}
 			}
 		}
 		if s.wsGateway != nil {
@@ -551,23 +733,30 @@ func (s *Service) Shutdown(ctx context.Context) error {
 			s.authQueueStop()
 			s.authQueueStop = nil
 		}
-
-		if s.server != nil {
-			shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-			defer cancel()
-			if err := s.server.Stop(shutdownCtx); err != nil {
-				log.Errorf("error stopping API server: %v", err)
-				if shutdownErr == nil {
-					shutdownErr = err
-				}
-			}
+		if s.coreManager != nil {
+			// Stop also flushes pending auth state from request results.
+			s.coreManager.StopAutoRefresh()
+			s.coreManager.Stop()
 		}
 
 		usage.StopDefault()
+
+		if s.stopTracing != nil {
+			if err := s.stopTracing(ctx); err != nil {
+				log.Warnf("failed to flush traces: %v", err)
+			}
+		}
 	})
 	return shutdownErr
 }
 
+// shutdownGrace returns the grace period of the current config.
+func (s *Service) shutdownGrace() time.Duration {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg.ShutdownGrace()
+}
+
 func (s *Service) ensureAuthDir() error {
 	authDir, err := util.ResolveAuthDir(s.cfg.AuthDir)
 	if err != nil {
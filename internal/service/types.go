@@ -80,6 +80,7 @@ type WatcherWrapper struct {
 	setUpdateQueue        func(queue chan<- watcher.AuthUpdate)
 	dispatchRuntimeUpdate func(update watcher.AuthUpdate) bool
 	markPendingWrite      func(path string)
+	disableConfigWatch    func()
 }
 
 // Start proxies to the underlying watcher Start implementation.
@@ -136,6 +137,14 @@ func (w *WatcherWrapper) SetAuthUpdateQueue(queue chan<- watcher.AuthUpdate) {
 	w.setUpdateQueue(queue)
 }
 
+// DisableConfigWatch turns off config file reloading; it must be called before Start.
+func (w *WatcherWrapper) DisableConfigWatch() {
+	if w == nil || w.disableConfigWatch == nil {
+		return
+	}
+	w.disableConfigWatch()
+}
+
 func (w *WatcherWrapper) MarkPendingWrite(path string) {
 	if w == nil || w.markPendingWrite == nil {
 		return
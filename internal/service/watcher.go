@@ -34,5 +34,6 @@ func defaultWatcherFactory(configPath, authDir string, reload func(*config.Confi
 		markPendingWrite: func(path string) {
 			w.MarkPendingWrite(path)
 		},
+		disableConfigWatch: w.DisableConfigWatch,
 	}, nil
 }
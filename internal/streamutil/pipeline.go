@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/metrics"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -112,6 +113,10 @@ func (p *Pipeline) Send(chunk Chunk) bool {
 	if p.onChunk != nil {
 		p.onChunk(chunk)
 	}
+	if len(chunk.Data) > 0 {
+		metrics.StreamChunks.Inc(metrics.Labels{})
+		metrics.StreamBytes.Add(float64(len(chunk.Data)), metrics.Labels{})
+	}
 
 	select {
 	case p.output <- chunk:
@@ -3,6 +3,8 @@ package streamutil
 import (
 	"context"
 	"sync"
+
+	"github.com/nghyane/llm-mux/internal/metrics"
 )
 
 // ResultRecorder provides async result recording to avoid blocking the hot path.
@@ -14,6 +16,7 @@ type ResultRecorder[T any] struct {
 	wg       sync.WaitGroup
 	workers  int
 	queueLen int
+	labels   metrics.Labels
 }
 
 // ResultRecorderConfig configures the result recorder.
@@ -22,6 +25,8 @@ type ResultRecorderConfig struct {
 	QueueSize int
 	// Workers is the number of worker goroutines (default: 4)
 	Workers int
+	// Name labels the queue depth metric (default: "result_recorder")
+	Name string
 }
 
 // DefaultResultRecorderConfig returns sensible defaults.
@@ -41,6 +46,9 @@ func NewResultRecorder[T any](cfg ResultRecorderConfig, handler func(T)) *Result
 	if cfg.Workers <= 0 {
 		cfg.Workers = 4
 	}
+	if cfg.Name == "" {
+		cfg.Name = "result_recorder"
+	}
 
 	r := &ResultRecorder[T]{
 		queue:    make(chan T, cfg.QueueSize),
@@ -48,6 +56,7 @@ func NewResultRecorder[T any](cfg ResultRecorderConfig, handler func(T)) *Result
 		stopCh:   make(chan struct{}),
 		workers:  cfg.Workers,
 		queueLen: cfg.QueueSize,
+		labels:   metrics.L(cfg.Name),
 	}
 
 	// Start worker goroutines
@@ -64,6 +73,7 @@ func NewResultRecorder[T any](cfg ResultRecorderConfig, handler func(T)) *Result
 func (r *ResultRecorder[T]) Record(result T) bool {
 	select {
 	case r.queue <- result:
+		r.reportDepth()
 		return true
 	case <-r.stopCh:
 		return false
@@ -71,6 +81,7 @@ func (r *ResultRecorder[T]) Record(result T) bool {
 		// Queue full - try non-blocking send with context awareness
 		select {
 		case r.queue <- result:
+			r.reportDepth()
 			return true
 		case <-r.stopCh:
 			return false
@@ -82,6 +93,7 @@ func (r *ResultRecorder[T]) Record(result T) bool {
 func (r *ResultRecorder[T]) RecordWithContext(ctx context.Context, result T) bool {
 	select {
 	case r.queue <- result:
+		r.reportDepth()
 		return true
 	case <-ctx.Done():
 		return false
@@ -98,6 +110,7 @@ func (r *ResultRecorder[T]) worker() {
 			if !ok {
 				return
 			}
+			r.reportDepth()
 			r.handler(result)
 		case <-r.stopCh:
 			// Drain remaining items
@@ -123,6 +136,10 @@ func (r *ResultRecorder[T]) Stop() {
 	r.wg.Wait()
 }
 
+func (r *ResultRecorder[T]) reportDepth() {
+	metrics.AsyncQueueDepth.Set(float64(len(r.queue)), r.labels)
+}
+
 // Pending returns the number of pending results in the queue.
 func (r *ResultRecorder[T]) Pending() int {
 	return len(r.queue)
@@ -0,0 +1,34 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+)
+
+const (
+	defaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+	otlpExportTimeout   = 10 * time.Second
+)
+
+// newOTLPExporter returns an OTLP/HTTP exporter that posts gzip-compressed
+// spans to endpoint, a full traces URL, retrying transient collector failures
+// with backoff.
+func newOTLPExporter(ctx context.Context, endpoint string, headers map[string]string) (*otlptrace.Exporter, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		endpoint = defaultOTLPEndpoint
+	}
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithCompression(otlptracehttp.GzipCompression),
+		otlptracehttp.WithTimeout(otlpExportTimeout),
+	}
+	if len(headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/buildinfo"
+	"github.com/nghyane/llm-mux/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const defaultServiceName = "llm-mux"
+
+// Setup installs a global tracer provider for cfg and enables span recording.
+// It returns a shutdown function that flushes pending spans. When tracing is
+// disabled Setup does nothing and the shutdown function is a no-op.
+func Setup(cfg config.TracingConfig) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.Enable {
+		return noop, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return noop, err
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.ExporterName() {
+	case config.TracingExporterStdout:
+		exp, err := stdouttrace.New()
+		if err != nil {
+			return noop, fmt.Errorf("tracing: create stdout exporter: %w", err)
+		}
+		exporter = exp
+	default:
+		exp, err := newOTLPExporter(context.Background(), cfg.Endpoint, cfg.Headers)
+		if err != nil {
+			return noop, fmt.Errorf("tracing: create otlp exporter: %w", err)
+		}
+		exporter = exp
+	}
+
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res := resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("service.version", buildinfo.Version),
+	)
+
+	ratio := cfg.SampleRatio
+	if ratio == 0 {
+		ratio = 1
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	install(tp)
+
+	return func(ctx context.Context) error {
+		enabled.Store(false)
+		return tp.Shutdown(ctx)
+	}, nil
+}
+
+// install makes tp the global tracer provider and enables span recording.
+func install(tp *sdktrace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	enabled.Store(true)
+}
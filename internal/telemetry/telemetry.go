@@ -0,0 +1,196 @@
+// Package telemetry records OpenTelemetry spans across the request lifecycle.
+// Spans are only created after Setup enables tracing; otherwise every helper
+// is a cheap no-op.
+package telemetry
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/nghyane/llm-mux"
+
+// Span names for each stage of a request.
+const (
+	SpanParse      = "llm-mux.parse"
+	SpanPreprocess = "llm-mux.preprocess"
+	SpanSelect     = "llm-mux.select"
+	SpanExecute    = "llm-mux.execute"
+	SpanTranslate  = "llm-mux.translate"
+)
+
+// Span attribute keys.
+const (
+	AttrProvider     = attribute.Key("llm_mux.provider")
+	AttrModel        = attribute.Key("llm_mux.model")
+	AttrAuthID       = attribute.Key("llm_mux.auth_id")
+	AttrRequestID    = attribute.Key("llm_mux.request_id")
+	AttrFormat       = attribute.Key("llm_mux.format")
+	AttrStream       = attribute.Key("llm_mux.stream")
+	AttrDurationMs   = attribute.Key("llm_mux.duration_ms")
+	AttrTTFBMs       = attribute.Key("llm_mux.stream.time_to_first_byte_ms")
+	AttrInputTokens  = attribute.Key("gen_ai.usage.input_tokens")
+	AttrOutputTokens = attribute.Key("gen_ai.usage.output_tokens")
+	AttrTotalTokens  = attribute.Key("llm_mux.usage.total_tokens")
+	AttrFinishReason = attribute.Key("gen_ai.response.finish_reason")
+)
+
+var enabled atomic.Bool
+
+// Enabled reports whether spans are being recorded.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Span wraps an OpenTelemetry span. All methods are safe on a disabled span.
+type Span struct {
+	span trace.Span
+}
+
+// End completes the span.
+func (s *Span) End() {
+	if s != nil && s.span != nil {
+		s.span.End()
+	}
+}
+
+// SetAttributes records attributes on the span.
+func (s *Span) SetAttributes(attrs ...attribute.KeyValue) {
+	if s != nil && s.span != nil {
+		s.span.SetAttributes(attrs...)
+	}
+}
+
+// StartSpan starts a child span of the span in ctx.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, *Span) {
+	if !enabled.Load() {
+		return ctx, &Span{}
+	}
+	ctx, span := tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, &Span{span: span}
+}
+
+// StartProviderSpan starts the execute span for one provider attempt.
+func StartProviderSpan(ctx context.Context, provider, model string) (context.Context, *Span) {
+	return StartSpan(ctx, SpanExecute, AttrProvider.String(provider), AttrModel.String(model))
+}
+
+// RecordLatency records the time elapsed since start as the span's duration.
+func RecordLatency(span *Span, start time.Time) {
+	span.SetAttributes(AttrDurationMs.Int64(time.Since(start).Milliseconds()))
+}
+
+// RecordFirstByte records the time from start until the first streamed chunk.
+func RecordFirstByte(span *Span, start time.Time) {
+	if span == nil || span.span == nil {
+		return
+	}
+	span.span.AddEvent("first_byte")
+	span.span.SetAttributes(AttrTTFBMs.Int64(time.Since(start).Milliseconds()))
+}
+
+// RecordError marks the span as failed with err.
+func RecordError(span *Span, err error) {
+	if span == nil || span.span == nil || err == nil {
+		return
+	}
+	span.span.RecordError(err)
+	span.span.SetStatus(codes.Error, err.Error())
+}
+
+// RecordAuth records the credential chosen for the request.
+func RecordAuth(span *Span, authID string) {
+	span.SetAttributes(AttrAuthID.String(authID))
+}
+
+// RecordUsage records token counts on the span in ctx.
+func RecordUsage(ctx context.Context, input, output, total int64) {
+	if !enabled.Load() {
+		return
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		AttrInputTokens.Int64(input),
+		AttrOutputTokens.Int64(output),
+		AttrTotalTokens.Int64(total),
+	)
+}
+
+// RecordFinishReason records why generation stopped on the span in ctx.
+func RecordFinishReason(ctx context.Context, reason string) {
+	if !enabled.Load() || reason == "" {
+		return
+	}
+	trace.SpanFromContext(ctx).SetAttributes(AttrFinishReason.String(reason))
+}
+
+// MetadataKey is the request metadata key carrying the active span context to
+// translation code that has no access to the request context. Translators
+// strip "__" keys before forwarding metadata upstream.
+const MetadataKey = "__telemetry_span"
+
+// WithMetadata returns a copy of meta carrying the span context of ctx. The
+// original map is returned unchanged when tracing is disabled.
+func WithMetadata(meta map[string]any, ctx context.Context) map[string]any {
+	if !enabled.Load() {
+		return meta
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return meta
+	}
+	out := make(map[string]any, len(meta)+1)
+	for k, v := range meta {
+		out[k] = v
+	}
+	out[MetadataKey] = sc
+	return out
+}
+
+// StartSpanFromMetadata starts a child of the span carried in meta. Without a
+// carried span it returns a no-op span rather than starting a new trace.
+func StartSpanFromMetadata(meta map[string]any, name string, attrs ...attribute.KeyValue) (context.Context, *Span) {
+	ctx := context.Background()
+	if !enabled.Load() || meta == nil {
+		return ctx, &Span{}
+	}
+	sc, ok := meta[MetadataKey].(trace.SpanContext)
+	if !ok || !sc.IsValid() {
+		return ctx, &Span{}
+	}
+	return StartSpan(trace.ContextWithSpanContext(ctx, sc), name, attrs...)
+}
+
+// finishReasonPaths locate the finish reason in OpenAI, Claude, Gemini,
+// Gemini CLI and Responses API payloads.
+var finishReasonPaths = []string{
+	"choices.0.finish_reason",
+	"stop_reason",
+	"candidates.0.finishReason",
+	"response.candidates.0.finishReason",
+	"status",
+}
+
+// RecordResponse records the finish reason found in a non-streaming upstream
+// response payload.
+func RecordResponse(span *Span, payload []byte) {
+	if span == nil || span.span == nil || len(payload) == 0 {
+		return
+	}
+	for _, path := range finishReasonPaths {
+		if v := gjson.GetBytes(payload, path); v.Type == gjson.String && v.Str != "" {
+			span.span.SetAttributes(AttrFinishReason.String(v.Str))
+			return
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package telemetry
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func installRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	install(tp)
+	t.Cleanup(func() {
+		enabled.Store(false)
+		_ = tp.Shutdown(context.Background())
+	})
+	return rec
+}
+
+func attrValue(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestDisabledIsNoop(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), SpanExecute)
+	span.SetAttributes(AttrModel.String("m"))
+	RecordError(span, io.EOF)
+	span.End()
+	if ctx != context.Background() {
+		t.Error("Expected context unchanged while tracing is disabled")
+	}
+	meta := map[string]any{"k": "v"}
+	if got := WithMetadata(meta, ctx); len(got) != 1 {
+		t.Errorf("Expected metadata unchanged while disabled, got %v", got)
+	}
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	rec := installRecorder(t)
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(Middleware())
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		ctx, exec := StartProviderSpan(c.Request.Context(), "claude", "claude-sonnet")
+		RecordAuth(exec, "auth-1")
+		meta := WithMetadata(nil, ctx)
+		_, parse := StartSpanFromMetadata(meta, SpanParse)
+		parse.End()
+		RecordUsage(ctx, 10, 5, 15)
+		RecordFinishReason(ctx, "stop")
+		exec.End()
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range spans {
+		if got := s.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("Span %s: expected incoming trace ID, got %s", s.Name(), got)
+		}
+		byName[s.Name()] = s
+	}
+
+	server := byName["POST /v1/chat/completions"]
+	exec := byName[SpanExecute]
+	parse := byName[SpanParse]
+	if server == nil || exec == nil || parse == nil {
+		t.Fatalf("Missing expected spans, got %v", byName)
+	}
+	if exec.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("Expected execute span to be a child of the server span")
+	}
+	if parse.Parent().SpanID() != exec.SpanContext().SpanID() {
+		t.Error("Expected parse span to be a child of the execute span via metadata")
+	}
+	if v, _ := attrValue(exec, AttrAuthID); v.AsString() != "auth-1" {
+		t.Errorf("Expected auth ID attribute, got %q", v.AsString())
+	}
+	if v, _ := attrValue(exec, AttrOutputTokens); v.AsInt64() != 5 {
+		t.Errorf("Expected output tokens 5, got %d", v.AsInt64())
+	}
+	if v, _ := attrValue(exec, AttrFinishReason); v.AsString() != "stop" {
+		t.Errorf("Expected finish reason stop, got %q", v.AsString())
+	}
+	if v, _ := attrValue(server, "http.response.status_code"); v.AsInt64() != http.StatusOK {
+		t.Errorf("Expected status code attribute 200, got %d", v.AsInt64())
+	}
+}
+
+func TestRecordResponseFinishReason(t *testing.T) {
+	rec := installRecorder(t)
+	payloads := map[string]string{
+		"openai": `{"choices":[{"finish_reason":"length"}]}`,
+		"claude": `{"stop_reason":"end_turn"}`,
+		"gemini": `{"candidates":[{"finishReason":"STOP"}]}`,
+	}
+	want := map[string]string{"openai": "length", "claude": "end_turn", "gemini": "STOP"}
+	for name, payload := range payloads {
+		_, span := StartSpan(context.Background(), name)
+		RecordResponse(span, []byte(payload))
+		span.End()
+	}
+	for _, s := range rec.Ended() {
+		if v, _ := attrValue(s, AttrFinishReason); v.AsString() != want[s.Name()] {
+			t.Errorf("%s: expected finish reason %q, got %q", s.Name(), want[s.Name()], v.AsString())
+		}
+	}
+}
+
+func TestOTLPExporterPostsSpans(t *testing.T) {
+	var got coltracepb.ExportTraceServiceRequest
+	var auth, encoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		encoding = r.Header.Get("Content-Encoding")
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(zr)
+		if err := proto.Unmarshal(data, &got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer srv.Close()
+
+	rec := installRecorder(t)
+	_, span := StartProviderSpan(context.Background(), "gemini", "gemini-2.5-pro")
+	RecordError(span, io.ErrUnexpectedEOF)
+	span.End()
+
+	exp, err := newOTLPExporter(context.Background(), srv.URL+"/v1/traces", map[string]string{"Authorization": "Bearer token"})
+	if err != nil {
+		t.Fatalf("newOTLPExporter failed: %v", err)
+	}
+	defer exp.Shutdown(context.Background())
+	if err := exp.ExportSpans(context.Background(), rec.Ended()); err != nil {
+		t.Fatalf("ExportSpans failed: %v", err)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("Expected configured header, got %q", auth)
+	}
+	if encoding != "gzip" {
+		t.Errorf("Expected gzip-compressed export, got %q", encoding)
+	}
+
+	spans := got.GetResourceSpans()[0].GetScopeSpans()[0].GetSpans()
+	if spans[0].GetName() != SpanExecute || len(spans[0].GetTraceId()) != 16 {
+		t.Errorf("Unexpected span: %v", spans[0])
+	}
+	if code := spans[0].GetStatus().GetCode(); code != tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("Expected error status, got %v", code)
+	}
+}
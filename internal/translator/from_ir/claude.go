@@ -3,8 +3,10 @@ package from_ir
 import (
 	"bytes"
 	"fmt"
+	"slices"
 
 	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 )
 
@@ -23,13 +25,21 @@ type ClaudeStreamState struct {
 	ParserState      *ir.ClaudeStreamParserState
 }
 
+// claudeSupportsLogprobs reports whether the registry lists logprobs among
+// the model's supported parameters. Claude rejects unknown request fields, so
+// logprobs are dropped for every other model.
+func claudeSupportsLogprobs(model string) bool {
+	info := registry.GetGlobalRegistry().GetModelInfo(model)
+	return info != nil && slices.Contains(info.SupportedParameters, "logprobs")
+}
+
 func NewClaudeStreamState() *ClaudeStreamState {
 	return &ClaudeStreamState{TextBlockIndex: 0, ParserState: ir.NewClaudeStreamParserState()}
 }
 
 func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	userID := "llm-mux-user"
-	if v, ok := req.Metadata[ir.MetaOpenAIUser].(string); ok && v != "" {
+	if v := ir.EndUserID(req.Metadata); v != "" {
 		userID = v
 	}
 
@@ -43,11 +53,14 @@ func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, err
 	if req.TopP != nil {
 		root["top_p"] = *req.TopP
 	}
-	if req.TopK != nil {
-		root["top_k"] = *req.TopK
+	if stop := ir.LimitStopSequences(req.StopSequences, 0); len(stop) > 0 {
+		root["stop_sequences"] = stop
 	}
-	if len(req.StopSequences) > 0 {
-		root["stop_sequences"] = req.StopSequences
+	if req.Logprobs != nil && *req.Logprobs && claudeSupportsLogprobs(req.Model) {
+		root["logprobs"] = true
+		if req.TopLogprobs != nil {
+			root["top_logprobs"] = *req.TopLogprobs
+		}
 	}
 
 	thinkingEnabled := false
@@ -63,14 +76,19 @@ func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, err
 			root["thinking"] = map[string]any{"type": "disabled"}
 		}
 	}
+	// Extended thinking rejects top_k.
+	if req.TopK != nil && !thinkingEnabled {
+		if k, ok := ir.ClampTopK(*req.TopK, 0); ok {
+			root["top_k"] = k
+		}
+	}
 
+	if text := ir.SystemText(req.Messages); text != "" {
+		root["system"] = text
+	}
 	var msgs []any
 	for _, m := range req.Messages {
 		switch m.Role {
-		case ir.RoleSystem:
-			if text := ir.CombineTextParts(m); text != "" {
-				root["system"] = text
-			}
 		case ir.RoleUser:
 			if ps := ir.BuildClaudeContentParts(m, false, false); len(ps) > 0 {
 				obj := map[string]any{"role": ir.ClaudeRoleUser, "content": ps}
@@ -361,6 +379,9 @@ func ToClaudeResponse(ms []ir.Message, us *ir.Usage, model, mid string) ([]byte,
 		if cacheReadTokens > 0 {
 			um["cache_read_input_tokens"] = cacheReadTokens
 		}
+		if us.Estimated {
+			um["estimated"] = true
+		}
 		res["usage"] = um
 	}
 	return json.Marshal(res)
@@ -497,6 +518,9 @@ func emitFinishTo(buf *bytes.Buffer, us *ir.Usage, s *ClaudeStreamState) {
 		if us.CacheCreationInputTokens > 0 {
 			um["cache_creation_input_tokens"] = us.CacheCreationInputTokens
 		}
+		if us.Estimated {
+			um["estimated"] = true
+		}
 	}
 	writeSSE(buf, ir.ClaudeSSEMessageDelta, map[string]any{"type": ir.ClaudeSSEMessageDelta, "delta": map[string]any{"stop_reason": sr}, "usage": um})
 	writeSSE(buf, ir.ClaudeSSEMessageStop, map[string]any{"type": ir.ClaudeSSEMessageStop})
@@ -3,7 +3,9 @@ package from_ir
 import (
 	"testing"
 
+	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/tidwall/gjson"
 )
 
@@ -116,3 +118,150 @@ func TestClaudeProvider_NormalMessagesKeepCacheControl(t *testing.T) {
 		t.Error("assistant message without thinking should have cache_control")
 	}
 }
+
+func TestClaudeProvider_LogprobsRequiresSupport(t *testing.T) {
+	newReq := func(model string) *ir.UnifiedChatRequest {
+		return &ir.UnifiedChatRequest{
+			Model:       model,
+			Logprobs:    ir.Ptr(true),
+			TopLogprobs: ir.Ptr(3),
+			Messages:    []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Hello"}}}},
+		}
+	}
+
+	out, err := (&ClaudeProvider{}).ConvertRequest(newReq("claude-no-logprobs"))
+	if err != nil {
+		t.Fatalf("ConvertRequest failed: %v", err)
+	}
+	if gjson.GetBytes(out, "logprobs").Exists() || gjson.GetBytes(out, "top_logprobs").Exists() {
+		t.Errorf("logprobs sent to a model without support: %s", out)
+	}
+
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("logprobs-test", "claude", []*registry.ModelInfo{{ID: "claude-logprobs", SupportedParameters: []string{"logprobs"}}})
+	t.Cleanup(func() { reg.UnregisterClient("logprobs-test") })
+
+	out, err = (&ClaudeProvider{}).ConvertRequest(newReq("claude-logprobs"))
+	if err != nil {
+		t.Fatalf("ConvertRequest failed: %v", err)
+	}
+	if !gjson.GetBytes(out, "logprobs").Bool() || gjson.GetBytes(out, "top_logprobs").Int() != 3 {
+		t.Errorf("logprobs missing for a supporting model: %s", out)
+	}
+}
+
+func TestClaudeProvider_MergesSystemAndDeveloperMessages(t *testing.T) {
+	req := &ir.UnifiedChatRequest{
+		Model: "claude-sonnet-4-5",
+		Messages: []ir.Message{
+			{Role: ir.RoleSystem, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Be brief."}}},
+			{Role: ir.RoleSystem, Developer: true, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Answer in French."}}},
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Hello"}}},
+		},
+	}
+	out, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest failed: %v", err)
+	}
+	if got := gjson.GetBytes(out, "system").String(); got != "Be brief.\n\nAnswer in French." {
+		t.Errorf("system = %q, want both instructions", got)
+	}
+
+	out, err = (&GeminiProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("Gemini ConvertRequest failed: %v", err)
+	}
+	if got := gjson.GetBytes(out, "systemInstruction.parts.0.text").String(); got != "Be brief.\n\nAnswer in French." {
+		t.Errorf("gemini systemInstruction = %q, want both instructions", got)
+	}
+}
+
+func TestEndUserMetadata_SurvivesTranslation(t *testing.T) {
+	openaiReq, err := to_ir.ParseOpenAIRequest([]byte(`{"model":"m","user":"u-1","metadata":{"team":"a"},"messages":[{"role":"user","content":"hi"}]}`))
+	if err != nil {
+		t.Fatalf("parse openai: %v", err)
+	}
+	claudeReq, err := to_ir.ParseClaudeRequest([]byte(`{"model":"m","max_tokens":16,"metadata":{"user_id":"u-2"},"messages":[{"role":"user","content":"hi"}]}`))
+	if err != nil {
+		t.Fatalf("parse claude: %v", err)
+	}
+	tests := []struct {
+		name    string
+		req     *ir.UnifiedChatRequest
+		convert func(*ir.UnifiedChatRequest) ([]byte, error)
+		want    map[string]string
+	}{
+		{"openai to openai", openaiReq, ToOpenAIRequest, map[string]string{"user": "u-1", "metadata.team": "a"}},
+		{"openai to claude", openaiReq, (&ClaudeProvider{}).ConvertRequest, map[string]string{"metadata.user_id": "u-1", "metadata.team": ""}},
+		{"claude to claude", claudeReq, (&ClaudeProvider{}).ConvertRequest, map[string]string{"metadata.user_id": "u-2"}},
+		{"claude to openai", claudeReq, ToOpenAIRequest, map[string]string{"user": "u-2", "metadata": ""}},
+		{"openai to gemini", openaiReq, (&GeminiProvider{}).ConvertRequest, map[string]string{"user": "", "metadata": "", "labels": ""}},
+		{"claude to gemini", claudeReq, (&GeminiProvider{}).ConvertRequest, map[string]string{"metadata": "", "labels": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.convert(tt.req)
+			if err != nil {
+				t.Fatalf("convert: %v", err)
+			}
+			for path, want := range tt.want {
+				if got := gjson.GetBytes(out, path).String(); got != want {
+					t.Errorf("%s = %q, want %q in %s", path, got, want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestToolResultIsError_RoundTrip(t *testing.T) {
+	claudeIn := `{"model":"m","max_tokens":16,"messages":[
+		{"role":"user","content":"read it"},
+		{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"read_file","input":{"path":"a"}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","is_error":true,"content":"file not found"}]}]}`
+	req, err := to_ir.ParseClaudeRequest([]byte(claudeIn))
+	if err != nil {
+		t.Fatalf("parse claude: %v", err)
+	}
+
+	claudeOut, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("claude convert: %v", err)
+	}
+	if !gjson.GetBytes(claudeOut, "messages.2.content.0.is_error").Bool() {
+		t.Errorf("claude tool_result lost is_error: %s", claudeOut)
+	}
+
+	// Chat Completions has no error flag, but some clients send one.
+	openaiIn := []byte(`{"model":"m","messages":[
+		{"role":"assistant","tool_calls":[{"id":"toolu_1","type":"function","function":{"name":"read_file","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"toolu_1","is_error":true,"content":"file not found"}]}`)
+
+	geminiOut, err := (&GeminiProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("gemini convert: %v", err)
+	}
+	if got := gjson.GetBytes(geminiOut, "contents.#.parts.#(functionResponse).functionResponse.response.error").Array(); len(got) != 1 || got[0].String() != "file not found" {
+		t.Errorf("gemini functionResponse = %s, want response.error", geminiOut)
+	}
+
+	for name, parse := range map[string]func() (*ir.UnifiedChatRequest, error){
+		"openai": func() (*ir.UnifiedChatRequest, error) { return to_ir.ParseOpenAIRequest(openaiIn) },
+		"gemini": func() (*ir.UnifiedChatRequest, error) { return to_ir.ParseGeminiRequest(geminiOut) },
+	} {
+		back, err := parse()
+		if err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		var found *ir.ToolResultPart
+		for _, msg := range back.Messages {
+			for _, part := range msg.Content {
+				if part.ToolResult != nil {
+					found = part.ToolResult
+				}
+			}
+		}
+		if found == nil || !found.IsError || found.Result != "file not found" {
+			t.Errorf("%s tool result = %+v, want an errored result", name, found)
+		}
+	}
+}
@@ -30,15 +30,10 @@ func buildClaudeVertexRequest(req *ir.UnifiedChatRequest) map[string]any {
 		"contents": buildClaudeContents(req),
 	}
 
-	for _, m := range req.Messages {
-		if m.Role == ir.RoleSystem {
-			if text := ir.CombineTextParts(m); text != "" {
-				root["systemInstruction"] = map[string]any{
-					"role":  "user",
-					"parts": []any{map[string]any{"text": text}},
-				}
-				break
-			}
+	if text := ir.SystemText(req.Messages); text != "" {
+		root["systemInstruction"] = map[string]any{
+			"role":  "user",
+			"parts": []any{map[string]any{"text": text}},
 		}
 	}
 
@@ -331,13 +326,15 @@ func buildClaudeGenerationConfig(req *ir.UnifiedChatRequest) map[string]any {
 		gc["topP"] = *req.TopP
 	}
 	if req.TopK != nil {
-		gc["topK"] = *req.TopK
+		if k, ok := ir.ClampTopK(*req.TopK, 0); ok {
+			gc["topK"] = k
+		}
 	}
 	if req.MaxTokens != nil && *req.MaxTokens > 0 {
 		gc["maxOutputTokens"] = *req.MaxTokens
 	}
-	if len(req.StopSequences) > 0 {
-		gc["stopSequences"] = req.StopSequences
+	if stop := ir.LimitStopSequences(req.StopSequences, ir.GeminiMaxStopSequences); len(stop) > 0 {
+		gc["stopSequences"] = stop
 	}
 
 	if req.Thinking != nil && req.Thinking.IncludeThoughts {
@@ -0,0 +1,81 @@
+package from_ir
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ToGeminiEmbeddingRequest builds a Gemini batchEmbedContents request body.
+func ToGeminiEmbeddingRequest(req *ir.EmbeddingRequest, model string) ([]byte, error) {
+	requests := make([]map[string]any, 0, len(req.Input))
+	for _, text := range req.Input {
+		r := map[string]any{
+			"model":   "models/" + model,
+			"content": map[string]any{"parts": []any{map[string]any{"text": text}}},
+		}
+		if req.Dimensions != nil {
+			r["outputDimensionality"] = *req.Dimensions
+		}
+		requests = append(requests, r)
+	}
+	return json.Marshal(map[string]any{"requests": requests})
+}
+
+// ToOpenAIEmbeddingRequest builds an OpenAI embeddings request body. Vectors
+// are always requested as floats and re-encoded for the client afterwards.
+func ToOpenAIEmbeddingRequest(req *ir.EmbeddingRequest, model string) ([]byte, error) {
+	m := map[string]any{
+		"model":           model,
+		"input":           req.Input,
+		"encoding_format": ir.EmbeddingEncodingFloat,
+	}
+	if req.Dimensions != nil {
+		m["dimensions"] = *req.Dimensions
+	}
+	if req.User != "" {
+		m["user"] = req.User
+	}
+	return json.Marshal(m)
+}
+
+// ToOpenAIEmbeddingResponse renders resp in the OpenAI embeddings response
+// shape. With EmbeddingEncodingBase64 each vector is encoded as little-endian
+// float32 values, matching the OpenAI API.
+func ToOpenAIEmbeddingResponse(resp *ir.EmbeddingResponse, model, encodingFormat string) ([]byte, error) {
+	data := make([]map[string]any, 0, len(resp.Embeddings))
+	for i, vec := range resp.Embeddings {
+		item := map[string]any{"object": "embedding", "index": i}
+		if encodingFormat == ir.EmbeddingEncodingBase64 {
+			item["embedding"] = encodeEmbeddingBase64(vec)
+		} else {
+			item["embedding"] = vec
+		}
+		data = append(data, item)
+	}
+
+	var prompt, total int64
+	if resp.Usage != nil {
+		prompt, total = resp.Usage.PromptTokens, resp.Usage.TotalTokens
+		if total == 0 {
+			total = prompt
+		}
+	}
+	return json.Marshal(map[string]any{
+		"object": "list",
+		"data":   data,
+		"model":  model,
+		"usage":  map[string]any{"prompt_tokens": prompt, "total_tokens": total},
+	})
+}
+
+func encodeEmbeddingBase64(vec []float64) string {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
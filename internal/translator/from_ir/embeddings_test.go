@@ -0,0 +1,77 @@
+package from_ir
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestToGeminiEmbeddingRequest(t *testing.T) {
+	dims := 128
+	body, err := ToGeminiEmbeddingRequest(&ir.EmbeddingRequest{Input: []string{"a", "b"}, Dimensions: &dims}, "gemini-embedding-001")
+	if err != nil {
+		t.Fatalf("ToGeminiEmbeddingRequest failed: %v", err)
+	}
+	reqs := gjson.GetBytes(body, "requests").Array()
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %d, want 2", len(reqs))
+	}
+	if got := reqs[1].Get("content.parts.0.text").String(); got != "b" {
+		t.Errorf("text = %q, want b", got)
+	}
+	if got := reqs[0].Get("model").String(); got != "models/gemini-embedding-001" {
+		t.Errorf("model = %q", got)
+	}
+	if got := reqs[0].Get("outputDimensionality").Int(); got != 128 {
+		t.Errorf("outputDimensionality = %d, want 128", got)
+	}
+}
+
+func TestToOpenAIEmbeddingResponse_Float(t *testing.T) {
+	resp := &ir.EmbeddingResponse{
+		Embeddings: [][]float64{{0.5, -1}, {2}},
+		Usage:      &ir.Usage{PromptTokens: 7},
+	}
+	body, err := ToOpenAIEmbeddingResponse(resp, "m", ir.EmbeddingEncodingFloat)
+	if err != nil {
+		t.Fatalf("ToOpenAIEmbeddingResponse failed: %v", err)
+	}
+	root := gjson.ParseBytes(body)
+	if root.Get("object").String() != "list" || root.Get("model").String() != "m" {
+		t.Errorf("unexpected envelope: %s", body)
+	}
+	if got := root.Get("data.1.index").Int(); got != 1 {
+		t.Errorf("data.1.index = %d, want 1", got)
+	}
+	if got := root.Get("data.0.embedding.1").Float(); got != -1 {
+		t.Errorf("data.0.embedding.1 = %v, want -1", got)
+	}
+	if got := root.Get("usage.total_tokens").Int(); got != 7 {
+		t.Errorf("usage.total_tokens = %d, want 7", got)
+	}
+}
+
+func TestToOpenAIEmbeddingResponse_Base64(t *testing.T) {
+	resp := &ir.EmbeddingResponse{Embeddings: [][]float64{{0.25, -3}}}
+	body, err := ToOpenAIEmbeddingResponse(resp, "m", ir.EmbeddingEncodingBase64)
+	if err != nil {
+		t.Fatalf("ToOpenAIEmbeddingResponse failed: %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(gjson.GetBytes(body, "data.0.embedding").String())
+	if err != nil {
+		t.Fatalf("embedding is not base64: %v", err)
+	}
+	if len(raw) != 8 {
+		t.Fatalf("decoded %d bytes, want 8", len(raw))
+	}
+	for i, want := range []float32{0.25, -3} {
+		if got := math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:])); got != want {
+			t.Errorf("value %d = %v, want %v", i, got, want)
+		}
+	}
+}
@@ -51,19 +51,21 @@ func (p *GeminiProvider) applyGenerationConfig(root map[string]any, req *ir.Unif
 		gc["topP"] = *req.TopP
 	}
 	if req.TopK != nil {
-		gc["topK"] = *req.TopK
+		if k, ok := ir.ClampTopK(*req.TopK, ir.GeminiMaxTopK); ok {
+			gc["topK"] = k
+		}
 	}
 	if req.MaxTokens != nil && *req.MaxTokens > 0 {
 		gc["maxOutputTokens"] = *req.MaxTokens
 	}
-	if len(req.StopSequences) > 0 {
-		gc["stopSequences"] = req.StopSequences
+	if stop := ir.LimitStopSequences(req.StopSequences, ir.GeminiMaxStopSequences); len(stop) > 0 {
+		gc["stopSequences"] = stop
 	}
 	if req.FrequencyPenalty != nil {
-		gc["frequencyPenalty"] = *req.FrequencyPenalty
+		gc["frequencyPenalty"] = ir.ClampPenalty(*req.FrequencyPenalty, ir.GeminiMaxPenalty)
 	}
 	if req.PresencePenalty != nil {
-		gc["presencePenalty"] = *req.PresencePenalty
+		gc["presencePenalty"] = ir.ClampPenalty(*req.PresencePenalty, ir.GeminiMaxPenalty)
 	}
 	if req.Logprobs != nil && *req.Logprobs {
 		gc["responseLogprobs"] = true
@@ -130,13 +132,12 @@ func (p *GeminiProvider) applyMessages(root map[string]any, req *ir.UnifiedChatR
 	toolIDToName, toolResults := ir.BuildToolMaps(req.Messages)
 	coalescer := ir.GetContentCoalescer(len(req.Messages) * 2)
 
+	if text := ir.SystemText(req.Messages); text != "" {
+		root["systemInstruction"] = map[string]any{"role": "user", "parts": []any{map[string]any{"text": text}}}
+	}
 	for i := range req.Messages {
 		msg := &req.Messages[i]
 		switch msg.Role {
-		case ir.RoleSystem:
-			if text := p.extractSystemText(msg); text != "" {
-				root["systemInstruction"] = map[string]any{"role": "user", "parts": []any{map[string]any{"text": text}}}
-			}
 		case ir.RoleUser:
 			coalescer.Emit("user", parts.BuildUserParts(msg.Content))
 		case ir.RoleAssistant:
@@ -153,31 +154,38 @@ func (p *GeminiProvider) applyMessages(root map[string]any, req *ir.UnifiedChatR
 	return nil
 }
 
-func (p *GeminiProvider) extractSystemText(msg *ir.Message) string {
-	for i := range msg.Content {
-		if msg.Content[i].Type == ir.ContentTypeText {
-			return msg.Content[i].Text
-		}
-	}
-	return ""
-}
-
+// buildAssistantAndToolParts builds the model turn of msg and the user turn
+// answering its tool calls. Gemini rejects thinking turns whose thoughts do
+// not lead, so model parts are ordered thoughts, then text, then function
+// calls, keeping the message order within each group. A signature carried by
+// a reasoning part without text moves to the first function call lacking
+// one, or else to the last part, instead of being dropped.
 func (p *GeminiProvider) buildAssistantAndToolParts(msg *ir.Message, toolIDToName map[string]string, toolResults map[string]*ir.ToolResultPart, model string) (modelParts, responseParts []any) {
+	var thoughts, texts []any
+	var pendingSignature string
 	for i := range msg.Content {
 		cp := &msg.Content[i]
-		part := map[string]any{}
-		if cp.Type == ir.ContentTypeReasoning && cp.Reasoning != "" {
-			part = map[string]any{"text": cp.Reasoning, "thought": true}
-		} else if cp.Type == ir.ContentTypeText && cp.Text != "" {
-			part = map[string]any{"text": cp.Text}
-		}
-		if len(part) > 0 {
-			if ir.IsValidThoughtSignature(cp.ThoughtSignature) {
+		validSignature := ir.IsValidThoughtSignature(cp.ThoughtSignature)
+		switch {
+		case cp.Type == ir.ContentTypeReasoning && cp.Reasoning != "":
+			part := map[string]any{"text": cp.Reasoning, "thought": true}
+			if validSignature {
 				part["thoughtSignature"] = string(cp.ThoughtSignature)
 			}
-			modelParts = append(modelParts, part)
+			thoughts = append(thoughts, part)
+		case cp.Type == ir.ContentTypeReasoning && validSignature:
+			if pendingSignature == "" {
+				pendingSignature = string(cp.ThoughtSignature)
+			}
+		case cp.Type == ir.ContentTypeText && cp.Text != "":
+			part := map[string]any{"text": cp.Text}
+			if validSignature {
+				part["thoughtSignature"] = string(cp.ThoughtSignature)
+			}
+			texts = append(texts, part)
 		}
 	}
+	modelParts = append(thoughts, texts...)
 
 	for i := range msg.ToolCalls {
 		tc := &msg.ToolCalls[i]
@@ -188,6 +196,9 @@ func (p *GeminiProvider) buildAssistantAndToolParts(msg *ir.Message, toolIDToNam
 		part := map[string]any{"functionCall": map[string]any{"name": tc.Name, "args": ir.ArgsAsRaw(tc.Args), "id": id}}
 		if ir.IsValidThoughtSignature(tc.ThoughtSignature) {
 			part["thoughtSignature"] = string(tc.ThoughtSignature)
+		} else if pendingSignature != "" {
+			part["thoughtSignature"] = pendingSignature
+			pendingSignature = ""
 		} else if ir.IsGemini3(model) {
 			part["thoughtSignature"] = ir.DummyThoughtSignature
 		}
@@ -206,6 +217,11 @@ func (p *GeminiProvider) buildAssistantAndToolParts(msg *ir.Message, toolIDToNam
 			}
 		}
 	}
+	if pendingSignature != "" && len(modelParts) > 0 {
+		if last := modelParts[len(modelParts)-1].(map[string]any); last["thoughtSignature"] == nil {
+			last["thoughtSignature"] = pendingSignature
+		}
+	}
 	return
 }
 
@@ -352,13 +368,17 @@ func ToGeminiResponseMeta(messages []ir.Message, usage *ir.Usage, model string,
 		if usage.ToolUsePromptTokens > 0 {
 			um["toolUsePromptTokenCount"] = usage.ToolUsePromptTokens
 		}
+		if usage.Estimated {
+			um["estimated"] = true
+		}
 		response["usageMetadata"] = um
 	}
 	return json.Marshal(response)
 }
 
 func ToGeminiChunk(event ir.UnifiedEvent, model string) ([]byte, error) {
-	if event.Type == ir.EventTypeStreamMeta {
+	// Argument fragments are covered by the complete tool call event.
+	if event.Type == ir.EventTypeStreamMeta || event.Type == ir.EventTypeToolCallDelta {
 		return nil, nil
 	}
 	candidate := map[string]any{"content": map[string]any{"role": "model", "parts": []any{}}}
@@ -420,6 +440,9 @@ func ToGeminiChunk(event ir.UnifiedEvent, model string) ([]byte, error) {
 			if usage.ToolUsePromptTokens > 0 {
 				um["toolUsePromptTokenCount"] = usage.ToolUsePromptTokens
 			}
+			if usage.Estimated {
+				um["estimated"] = true
+			}
 			chunk["usageMetadata"] = um
 		}
 	case ir.EventTypeError:
@@ -512,14 +535,21 @@ func (p *GeminiProvider) applyThinkingConfig(gc map[string]any, req *ir.UnifiedC
 	if req.Thinking == nil && !auto {
 		return
 	}
-	eb, ei := budget, include
+	eb, ei, explicit := budget, include, false
 	if req.Thinking != nil {
 		if req.Thinking.ThinkingBudget != nil {
-			eb = int(*req.Thinking.ThinkingBudget)
+			eb, explicit = int(*req.Thinking.ThinkingBudget), true
 		}
 		ei = req.Thinking.IncludeThoughts
 	}
-	if eb <= 0 {
+	// preprocess has already replaced 0 and -1 for models whose ThinkingSupport
+	// lacks ZeroAllowed or DynamicAllowed, so explicit values pass through.
+	switch {
+	case explicit && eb == 0:
+		ei = false // thinking disabled
+	case explicit && eb == -1:
+		// dynamic budget
+	case eb <= 0:
 		eb = ir.DefaultThinkingBudgetTokens
 	}
 	if isG3 {
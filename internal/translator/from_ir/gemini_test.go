@@ -0,0 +1,334 @@
+package from_ir
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/preprocess"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestGeminiProvider_ThinkingConfigBudget(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("thinking-budget-test", "gemini", []*registry.ModelInfo{
+		{ID: "gemini-budget-flexible", Thinking: &registry.ThinkingSupport{Min: 0, Max: 24576, ZeroAllowed: true, DynamicAllowed: true}},
+		{ID: "gemini-budget-strict", Thinking: &registry.ThinkingSupport{Min: 128, Max: 32768}},
+	})
+	t.Cleanup(func() { reg.UnregisterClient("thinking-budget-test") })
+
+	tests := []struct {
+		name    string
+		model   string
+		budget  int32
+		want    int64
+		include bool
+	}{
+		{"disabled when zero allowed", "gemini-budget-flexible", 0, 0, false},
+		{"dynamic when allowed", "gemini-budget-flexible", -1, -1, true},
+		{"clamped to max", "gemini-budget-flexible", 50000, 24576, true},
+		{"zero raised to min", "gemini-budget-strict", 0, 128, true},
+		{"dynamic replaced by midpoint", "gemini-budget-strict", -1, 16448, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &ir.UnifiedChatRequest{
+				Model:    tt.model,
+				Messages: []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Hello"}}}},
+				Thinking: &ir.ThinkingConfig{ThinkingBudget: ir.Ptr(tt.budget), IncludeThoughts: true},
+			}
+			if err := preprocess.Apply(req); err != nil {
+				t.Fatalf("preprocess.Apply: %v", err)
+			}
+
+			payload, err := (&VertexEnvelopeProvider{}).ConvertRequest(req)
+			if err != nil {
+				t.Fatalf("ConvertRequest: %v", err)
+			}
+			tc := gjson.GetBytes(payload, "request.generationConfig.thinkingConfig")
+			if budget := tc.Get("thinkingBudget"); !budget.Exists() || budget.Int() != tt.want {
+				t.Errorf("thinkingBudget = %s, want %d", budget.Raw, tt.want)
+			}
+			if include := tc.Get("includeThoughts"); !include.Exists() || include.Bool() != tt.include {
+				t.Errorf("includeThoughts = %s, want %v", include.Raw, tt.include)
+			}
+		})
+	}
+}
+
+func TestGeminiProvider_AssistantPartOrder(t *testing.T) {
+	req := &ir.UnifiedChatRequest{
+		Model: "gemini-2.5-pro",
+		Messages: []ir.Message{
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Weather in Paris?"}}},
+			{
+				Role: ir.RoleAssistant,
+				// Text before reasoning, as some clients send it, and a
+				// signature on a reasoning part without text.
+				Content: []ir.ContentPart{
+					{Type: ir.ContentTypeText, Text: "Let me check."},
+					{Type: ir.ContentTypeReasoning, Reasoning: "The user wants the weather.", ThoughtSignature: []byte("sig-thought")},
+					{Type: ir.ContentTypeReasoning, ThoughtSignature: []byte("sig-call")},
+				},
+				ToolCalls: []ir.ToolCall{{ID: "call_1", Name: "get_weather", Args: `{"city":"Paris"}`}},
+			},
+			{Role: ir.RoleTool, Content: []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: "call_1", Result: `{"temp":18}`}}}},
+		},
+	}
+
+	payload, err := (&GeminiProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	parts := gjson.GetBytes(payload, "contents.1.parts").Array()
+	if len(parts) != 3 {
+		t.Fatalf("Expected thought, text and functionCall parts, got %s", gjson.GetBytes(payload, "contents.1").Raw)
+	}
+	if !parts[0].Get("thought").Bool() || parts[0].Get("thoughtSignature").String() != "sig-thought" {
+		t.Errorf("Expected the signed thought first, got %s", parts[0].Raw)
+	}
+	if parts[1].Get("text").String() != "Let me check." || parts[1].Get("thought").Bool() {
+		t.Errorf("Expected the text after the thought, got %s", parts[1].Raw)
+	}
+	if parts[2].Get("functionCall.name").String() != "get_weather" || parts[2].Get("thoughtSignature").String() != "sig-call" {
+		t.Errorf("Expected the functionCall last carrying the orphan signature, got %s", parts[2].Raw)
+	}
+	if resp := gjson.GetBytes(payload, "contents.2.parts.0.functionResponse.name").String(); resp != "get_weather" {
+		t.Errorf("Expected the tool result in the next user turn, got %s", gjson.GetBytes(payload, "contents.2").Raw)
+	}
+}
+
+func TestGeminiProvider_CachedContent(t *testing.T) {
+	const name = "projects/p1/locations/us-central1/cachedContents/abc"
+	tests := []struct {
+		name  string
+		parse func([]byte) (*ir.UnifiedChatRequest, error)
+		body  string
+	}{
+		{"gemini", to_ir.ParseGeminiRequest, `{"cachedContent":"` + name + `","contents":[{"role":"user","parts":[{"text":"hi"}]}]}`},
+		{"openai", to_ir.ParseOpenAIRequest, `{"model":"gemini-2.5-pro","cached_content":"` + name + `","messages":[{"role":"user","content":"hi"}]}`},
+		{"openai extra_body", to_ir.ParseOpenAIRequest, `{"model":"gemini-2.5-pro","extra_body":{"google":{"cached_content":"` + name + `"}},"messages":[{"role":"user","content":"hi"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.parse([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			req.Model = "gemini-2.5-pro"
+			payload, err := (&GeminiProvider{}).ConvertRequest(req)
+			if err != nil {
+				t.Fatalf("ConvertRequest: %v", err)
+			}
+			if got := gjson.GetBytes(payload, "cachedContent").String(); got != name {
+				t.Errorf("cachedContent = %q, want %q", got, name)
+			}
+		})
+	}
+}
+
+func TestGeminiProvider_FileParts(t *testing.T) {
+	const uri = "https://generativelanguage.googleapis.com/v1beta/files/abc"
+	tests := []struct {
+		name  string
+		parse func([]byte) (*ir.UnifiedChatRequest, error)
+		body  string
+		want  string
+	}{
+		{"openai file_id", to_ir.ParseOpenAIRequest, `{"model":"m","messages":[{"role":"user","content":[{"type":"file","file":{"file_id":"` + uri + `"}}]}]}`, `{"fileData":{"fileUri":"` + uri + `"}}`},
+		{"openai file_data", to_ir.ParseOpenAIRequest, `{"model":"m","messages":[{"role":"user","content":[{"type":"file","file":{"filename":"a.pdf","file_data":"data:application/pdf;base64,QUJD"}}]}]}`, `{"inlineData":{"mimeType":"application/pdf","data":"QUJD"}}`},
+		{"gemini pdf fileData", to_ir.ParseGeminiRequest, `{"contents":[{"role":"user","parts":[{"fileData":{"fileUri":"` + uri + `","mimeType":"application/pdf"}}]}]}`, `{"fileData":{"fileUri":"` + uri + `","mimeType":"application/pdf"}}`},
+		{"unreadable reference dropped", to_ir.ParseOpenAIRequest, `{"model":"m","messages":[{"role":"user","content":[{"type":"text","text":"hi"},{"type":"file","file":{"file_id":"file-abc"}}]}]}`, `{"text":"hi"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.parse([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			req.Model = "gemini-2.5-pro"
+			payload, err := (&GeminiProvider{}).ConvertRequest(req)
+			if err != nil {
+				t.Fatalf("ConvertRequest: %v", err)
+			}
+			parts := gjson.GetBytes(payload, "contents.0.parts")
+			if len(parts.Array()) != 1 || !reflect.DeepEqual(parts.Get("0").Value(), gjson.Parse(tt.want).Value()) {
+				t.Errorf("parts = %s, want [%s]", parts.Raw, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopSequences_NativeFieldPerProvider(t *testing.T) {
+	newReq := func(model string) *ir.UnifiedChatRequest {
+		return &ir.UnifiedChatRequest{
+			Model:         model,
+			Messages:      []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Hi"}}}},
+			StopSequences: []string{"a", "", "b", "a", "c", "d", "e", "f"},
+		}
+	}
+	tests := []struct {
+		name    string
+		convert func(*ir.UnifiedChatRequest) ([]byte, error)
+		model   string
+		path    string
+		want    []string
+	}{
+		{"openai", ToOpenAIRequest, "gpt-4o", "stop", []string{"a", "b", "c", "d"}},
+		{"gemini", (&GeminiProvider{}).ConvertRequest, "gemini-2.5-flash", "generationConfig.stopSequences", []string{"a", "b", "c", "d", "e"}},
+		{"claude", (&ClaudeProvider{}).ConvertRequest, "claude-sonnet-4-5", "stop_sequences", []string{"a", "b", "c", "d", "e", "f"}},
+		{"gemini envelope", (&VertexEnvelopeProvider{}).ConvertRequest, "gemini-2.5-flash", "request.generationConfig.stopSequences", []string{"a", "b", "c", "d", "e"}},
+		{"gemini cli claude envelope", (&VertexEnvelopeProvider{}).ConvertRequest, "claude-sonnet-4-5", "request.generationConfig.stopSequences", []string{"a", "b", "c", "d", "e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.convert(newReq(tt.model))
+			if err != nil {
+				t.Fatalf("convert failed: %v", err)
+			}
+			var got []string
+			for _, s := range gjson.GetBytes(out, tt.path).Array() {
+				got = append(got, s.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s = %v, want %v in %s", tt.path, got, tt.want, out)
+			}
+		})
+	}
+
+	out, err := ToOpenAIRequest(&ir.UnifiedChatRequest{Model: "gpt-4o", StopSequences: []string{""}})
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	if gjson.GetBytes(out, "stop").Exists() {
+		t.Errorf("stop should be omitted when only empty sequences are given: %s", out)
+	}
+}
+
+func TestPenalties_ClampedPerProvider(t *testing.T) {
+	newReq := func(model string) *ir.UnifiedChatRequest {
+		return &ir.UnifiedChatRequest{
+			Model:            model,
+			Messages:         []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Hi"}}}},
+			FrequencyPenalty: ir.Ptr(2.5),
+			PresencePenalty:  ir.Ptr(-3.0),
+		}
+	}
+	tests := []struct {
+		name              string
+		convert           func(*ir.UnifiedChatRequest) ([]byte, error)
+		model             string
+		frequency         string
+		presence          string
+		wantFrequency     float64
+		wantPresence      float64
+		wantNoPenaltyKeys bool
+	}{
+		{"openai", ToOpenAIRequest, "gpt-4o", "frequency_penalty", "presence_penalty", 2, -2, false},
+		{"gemini", (&GeminiProvider{}).ConvertRequest, "gemini-2.5-flash", "generationConfig.frequencyPenalty", "generationConfig.presencePenalty", ir.GeminiMaxPenalty, -2, false},
+		{"gemini envelope", (&VertexEnvelopeProvider{}).ConvertRequest, "gemini-2.5-flash", "request.generationConfig.frequencyPenalty", "request.generationConfig.presencePenalty", ir.GeminiMaxPenalty, -2, false},
+		{"claude", (&ClaudeProvider{}).ConvertRequest, "claude-sonnet-4-5", "frequency_penalty", "presence_penalty", 0, 0, true},
+		{"gemini cli claude envelope", (&VertexEnvelopeProvider{}).ConvertRequest, "claude-sonnet-4-5", "request.generationConfig.frequencyPenalty", "request.generationConfig.presencePenalty", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.convert(newReq(tt.model))
+			if err != nil {
+				t.Fatalf("convert failed: %v", err)
+			}
+			frequency, presence := gjson.GetBytes(out, tt.frequency), gjson.GetBytes(out, tt.presence)
+			if tt.wantNoPenaltyKeys {
+				if frequency.Exists() || presence.Exists() {
+					t.Errorf("penalties sent to a provider without them: %s", out)
+				}
+				return
+			}
+			if frequency.Float() != tt.wantFrequency || presence.Float() != tt.wantPresence {
+				t.Errorf("%s = %s, %s = %s, want %v and %v in %s", tt.frequency, frequency.Raw, tt.presence, presence.Raw, tt.wantFrequency, tt.wantPresence, out)
+			}
+		})
+	}
+
+	req, err := to_ir.ParseGeminiRequest([]byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"frequencyPenalty":0.5,"presencePenalty":0.25}}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	req.Model = "gpt-4o"
+	out, err := ToOpenAIRequest(req)
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	if gjson.GetBytes(out, "frequency_penalty").Float() != 0.5 || gjson.GetBytes(out, "presence_penalty").Float() != 0.25 {
+		t.Errorf("Gemini penalties lost on the way to OpenAI: %s", out)
+	}
+}
+
+func TestTopK_PerProvider(t *testing.T) {
+	newReq := func(model string, topK int) *ir.UnifiedChatRequest {
+		return &ir.UnifiedChatRequest{
+			Model:    model,
+			Messages: []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Hi"}}}},
+			TopK:     ir.Ptr(topK),
+		}
+	}
+	tests := []struct {
+		name    string
+		convert func(*ir.UnifiedChatRequest) ([]byte, error)
+		model   string
+		path    string
+		topK    int
+		want    int64 // 0 means the field is absent
+	}{
+		{"gemini", (&GeminiProvider{}).ConvertRequest, "gemini-2.5-flash", "generationConfig.topK", 40, 40},
+		{"gemini clamped", (&GeminiProvider{}).ConvertRequest, "gemini-2.5-flash", "generationConfig.topK", 500, ir.GeminiMaxTopK},
+		{"gemini envelope", (&VertexEnvelopeProvider{}).ConvertRequest, "gemini-2.5-flash", "request.generationConfig.topK", 500, ir.GeminiMaxTopK},
+		{"gemini disabled", (&GeminiProvider{}).ConvertRequest, "gemini-2.5-flash", "generationConfig.topK", 0, 0},
+		{"claude", (&ClaudeProvider{}).ConvertRequest, "claude-sonnet-4-5", "top_k", 250, 250},
+		{"claude disabled", (&ClaudeProvider{}).ConvertRequest, "claude-sonnet-4-5", "top_k", -1, 0},
+		{"openai", ToOpenAIRequest, "gpt-4o", "top_k", 40, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.convert(newReq(tt.model, tt.topK))
+			if err != nil {
+				t.Fatalf("convert failed: %v", err)
+			}
+			got := gjson.GetBytes(out, tt.path)
+			if tt.want == 0 {
+				if got.Exists() {
+					t.Errorf("%s sent: %s", tt.path, out)
+				}
+				return
+			}
+			if got.Int() != tt.want {
+				t.Errorf("%s = %s, want %d in %s", tt.path, got.Raw, tt.want, out)
+			}
+		})
+	}
+
+	// Extended thinking rejects top_k, so Claude drops it.
+	req := newReq("claude-sonnet-4-5", 40)
+	req.Thinking = &ir.ThinkingConfig{IncludeThoughts: true, ThinkingBudget: ir.Ptr(int32(2048))}
+	out, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	if gjson.GetBytes(out, "top_k").Exists() {
+		t.Errorf("top_k sent with extended thinking: %s", out)
+	}
+
+	// OpenAI-format clients may send top_k for providers that take it.
+	parsed, err := to_ir.ParseOpenAIRequest([]byte(`{"model":"gemini-2.5-flash","top_k":20,"messages":[{"role":"user","content":"hi"}]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if out, err = (&GeminiProvider{}).ConvertRequest(parsed); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	if gjson.GetBytes(out, "generationConfig.topK").Int() != 20 {
+		t.Errorf("top_k from an OpenAI request lost on the way to Gemini: %s", out)
+	}
+}
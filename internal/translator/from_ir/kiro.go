@@ -11,7 +11,7 @@ type KiroProvider struct{}
 
 func (p *KiroProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	tools := extractTools(req.Tools)
-	systemPrompt := extractSystemPrompt(req.Messages)
+	systemPrompt := ir.SystemText(req.Messages)
 	history, currentMessage := processMessages(req.Messages, tools, req.Model)
 
 	injectSystemPrompt(systemPrompt, &history, currentMessage, req.Model)
@@ -53,16 +53,6 @@ func extractTools(irTools []ir.ToolDefinition) []any {
 	return tools
 }
 
-func extractSystemPrompt(messages []ir.Message) string {
-	var parts []string
-	for _, msg := range messages {
-		if msg.Role == ir.RoleSystem {
-			parts = append(parts, ir.CombineTextParts(msg))
-		}
-	}
-	return strings.Join(parts, "\n")
-}
-
 func processMessages(messages []ir.Message, tools []any, modelID string) ([]any, map[string]any) {
 	var nonSystem []ir.Message
 	for _, msg := range messages {
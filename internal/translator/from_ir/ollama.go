@@ -142,12 +142,11 @@ func convertToOllamaChatRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 
 func convertToOllamaGenerateRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	m := map[string]any{"model": req.Model, "prompt": "", "stream": req.Metadata["stream"] == true, "options": buildOllamaOptions(req)}
-	var sp, up string
+	sp := ir.SystemText(req.Messages)
+	var up string
 	var imgs []string
 	for _, msg := range req.Messages {
 		switch msg.Role {
-		case ir.RoleSystem:
-			sp = ir.CombineTextParts(msg)
 		case ir.RoleUser:
 			up = ir.CombineTextParts(msg)
 			for _, p := range msg.Content {
@@ -186,7 +185,9 @@ func buildOllamaOptions(req *ir.UnifiedChatRequest) map[string]any {
 		o["top_p"] = *req.TopP
 	}
 	if req.TopK != nil {
-		o["top_k"] = *req.TopK
+		if k, ok := ir.ClampTopK(*req.TopK, 0); ok {
+			o["top_k"] = k
+		}
 	}
 	if req.MaxTokens != nil {
 		o["num_predict"] = *req.MaxTokens
@@ -306,7 +307,8 @@ func ToOllamaGenerateResponse(ms []ir.Message, us *ir.Usage, model string) ([]by
 }
 
 func ToOllamaChatChunk(ev ir.UnifiedEvent, model string) ([]byte, error) {
-	if ev.Type == ir.EventTypeStreamMeta {
+	// Argument fragments are covered by the complete tool call event.
+	if ev.Type == ir.EventTypeStreamMeta || ev.Type == ir.EventTypeToolCallDelta {
 		return nil, nil
 	}
 	if ev.Type == ir.EventTypeError {
@@ -418,6 +420,8 @@ func mapFinishReasonToOllama(r ir.FinishReason) string {
 		return "length"
 	case ir.FinishReasonToolCalls:
 		return "tool_calls"
+	case ir.FinishReasonMinTokens:
+		return "min_tokens"
 	default:
 		return "stop"
 	}
@@ -2,6 +2,8 @@ package from_ir
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,6 +18,30 @@ const (
 	FormatResponsesAPI
 )
 
+// OpenAIMaxTokensField returns the Chat Completions field that carries the
+// output token limit for model. OpenAI reasoning models (o1, o3, o4, gpt-5)
+// reject max_tokens and require max_completion_tokens; other models keep
+// max_tokens, which every OpenAI-compatible server understands.
+func OpenAIMaxTokensField(model string) string {
+	if isOpenAIReasoningModel(model) {
+		return "max_completion_tokens"
+	}
+	return "max_tokens"
+}
+
+func isOpenAIReasoningModel(model string) bool {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, prefix := range []string{"o1", "o3", "o4", "gpt-5"} {
+		if name == prefix || strings.HasPrefix(name, prefix+"-") || strings.HasPrefix(name, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
 func ToOpenAIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	return ToOpenAIRequestFmt(req, FormatChatCompletions)
 }
@@ -36,15 +62,31 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 		m["top_p"] = *req.TopP
 	}
 	if req.MaxTokens != nil {
-		m["max_tokens"] = *req.MaxTokens
+		m[OpenAIMaxTokensField(req.Model)] = *req.MaxTokens
+	}
+	if stop := ir.LimitStopSequences(req.StopSequences, ir.OpenAIMaxStopSequences); len(stop) > 0 {
+		m["stop"] = stop
+	}
+	if req.FrequencyPenalty != nil {
+		m["frequency_penalty"] = ir.ClampPenalty(*req.FrequencyPenalty, ir.OpenAIMaxPenalty)
 	}
-	if len(req.StopSequences) > 0 {
-		m["stop"] = req.StopSequences
+	if req.PresencePenalty != nil {
+		m["presence_penalty"] = ir.ClampPenalty(*req.PresencePenalty, ir.OpenAIMaxPenalty)
+	}
+	if req.Logprobs != nil {
+		m["logprobs"] = *req.Logprobs
+	}
+	if req.TopLogprobs != nil {
+		m["top_logprobs"] = *req.TopLogprobs
 	}
 	if req.Prediction != nil && req.Prediction.Content != "" {
 		m["prediction"] = map[string]any{"type": req.Prediction.Type, "content": req.Prediction.Content}
 	}
-	if req.Thinking != nil && req.Thinking.IncludeThoughts {
+	// An explicit effort is forwarded as sent, so "none", "minimal" and
+	// "xhigh" survive; budgets from other formats are mapped to the closest one.
+	if req.Thinking != nil && req.Thinking.Effort != "" {
+		m["reasoning_effort"] = strings.ToLower(string(req.Thinking.Effort))
+	} else if req.Thinking != nil && req.Thinking.IncludeThoughts {
 		b := 0
 		if req.Thinking.ThinkingBudget != nil {
 			b = int(*req.Thinking.ThinkingBudget)
@@ -53,6 +95,7 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 	}
 
 	var msgs []any
+	reasoning := isOpenAIReasoningModel(req.Model)
 	for _, msg := range req.Messages {
 		if msg.Role == ir.RoleTool {
 			for _, p := range msg.Content {
@@ -62,7 +105,7 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 			}
 			continue
 		}
-		if obj := convertMessageToOpenAI(msg); obj != nil {
+		if obj := convertMessageToOpenAI(msg, reasoning); obj != nil {
 			msgs = append(msgs, obj)
 		}
 	}
@@ -135,11 +178,17 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 	}
 
 	if req.Metadata != nil {
-		for _, k := range []string{ir.MetaOpenAILogprobs, ir.MetaOpenAITopLogprobs, ir.MetaOpenAILogitBias, ir.MetaOpenAISeed, ir.MetaOpenAIUser, ir.MetaOpenAIFrequencyPenalty, ir.MetaOpenAIPresencePenalty} {
+		for _, k := range []string{ir.MetaOpenAILogprobs, ir.MetaOpenAITopLogprobs, ir.MetaOpenAILogitBias, ir.MetaOpenAISeed, ir.MetaOpenAIUser, ir.MetaOpenAIMetadata} {
 			if v, ok := req.Metadata[k]; ok {
 				m[strings.TrimPrefix(k, "openai:")] = v
 			}
 		}
+		// Claude clients identify the end user in metadata.user_id.
+		if _, ok := m["user"]; !ok {
+			if v := ir.EndUserID(req.Metadata); v != "" {
+				m["user"] = v
+			}
+		}
 		if v, ok := req.Metadata["service_tier"]; ok {
 			m["service_tier"] = v
 		}
@@ -182,11 +231,12 @@ func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	}
 
 	var input []any
+	reasoning := isOpenAIReasoningModel(req.Model)
 	for _, msg := range req.Messages {
 		if msg.Role == ir.RoleSystem && req.Instructions != "" {
 			continue
 		}
-		if item := convertMessageToResponsesInput(msg); item != nil {
+		if item := convertMessageToResponsesInput(msg, reasoning); item != nil {
 			input = append(input, item)
 		}
 	}
@@ -269,6 +319,9 @@ func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	if req.PromptCacheKey != "" {
 		m["prompt_cache_key"] = req.PromptCacheKey
 	}
+	if len(req.Include) > 0 {
+		m["include"] = req.Include
+	}
 	if req.Store != nil {
 		m["store"] = *req.Store
 	}
@@ -276,11 +329,11 @@ func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	return json.Marshal(m)
 }
 
-func convertMessageToResponsesInput(msg ir.Message) any {
+func convertMessageToResponsesInput(msg ir.Message, reasoning bool) any {
 	switch msg.Role {
 	case ir.RoleSystem:
 		if t := ir.CombineTextParts(msg); t != "" {
-			return map[string]any{"type": "message", "role": "system", "content": []any{map[string]any{"type": "input_text", "text": t}}}
+			return map[string]any{"type": "message", "role": openAISystemRole(msg, reasoning), "content": []any{map[string]any{"type": "input_text", "text": t}}}
 		}
 	case ir.RoleUser:
 		return buildResponsesUserMessage(msg)
@@ -376,6 +429,16 @@ func ToOpenAIChatCompletionCandidates(cs []ir.CandidateResult, us *ir.Usage, mod
 	var chs []any
 	for _, c := range cs {
 		if len(c.Messages) == 0 {
+			fr := ir.MapFinishReasonToOpenAI(c.FinishReason)
+			if fr != "content_filter" {
+				continue
+			}
+			// Filtered before any output: the choice has no content.
+			co := map[string]any{"index": c.Index, "finish_reason": fr, "message": map[string]any{"role": "assistant", "content": nil}}
+			if c.ContentFilter != nil {
+				co["content_filter_results"] = c.ContentFilter
+			}
+			chs = append(chs, co)
 			continue
 		}
 		b := ir.NewResponseBuilder(c.Messages, us, model, false)
@@ -400,9 +463,15 @@ func ToOpenAIChatCompletionCandidates(cs []ir.CandidateResult, us *ir.Usage, mod
 		if c.Logprobs != nil {
 			co["logprobs"] = c.Logprobs
 		}
+		if c.ContentFilter != nil {
+			co["content_filter_results"] = c.ContentFilter
+		}
 		chs = append(chs, co)
 	}
 	res["choices"] = chs
+	if meta != nil && meta.PromptFilter != nil {
+		res["prompt_filter_results"] = meta.PromptFilter
+	}
 	if us != nil {
 		res["usage"] = buildUsageMap(us, meta)
 	}
@@ -483,6 +552,9 @@ func ToOpenAIChatCompletionMeta(ms []ir.Message, us *ir.Usage, model, mid string
 	if meta != nil && meta.GroundingMetadata != nil {
 		res["grounding_metadata"] = buildOpenAIGroundingMetadata(meta.GroundingMetadata)
 	}
+	if meta != nil && meta.PromptFilter != nil {
+		res["prompt_filter_results"] = meta.PromptFilter
+	}
 	return json.Marshal(res)
 }
 
@@ -533,9 +605,23 @@ func buildUsageMap(us *ir.Usage, meta *ir.OpenAIMeta) map[string]any {
 	if len(cd) > 0 {
 		um["completion_tokens_details"] = cd
 	}
+	if us.Estimated {
+		um["estimated"] = true
+	}
 	return um
 }
 
+// ToOpenAIUsageChunk builds the usage-only chat.completion.chunk (empty
+// choices) that OpenAI sends last when stream_options.include_usage is set.
+func ToOpenAIUsageChunk(us *ir.Usage, model, mid, fingerprint string) []byte {
+	ch := map[string]any{"id": mid, "object": "chat.completion.chunk", "created": time.Now().Unix(), "model": model, "choices": []any{}, "usage": buildUsageMap(us, nil)}
+	if fingerprint != "" {
+		ch["system_fingerprint"] = fingerprint
+	}
+	jb, _ := json.Marshal(ch)
+	return ir.BuildSSEChunk(jb)
+}
+
 func ToOpenAIChunk(ev ir.UnifiedEvent, model, mid string, ci int) ([]byte, error) {
 	return ToOpenAIChunkMeta(ev, model, mid, ci, nil)
 }
@@ -553,31 +639,34 @@ func ToOpenAIChunkMeta(ev ir.UnifiedEvent, model, mid string, ci int, meta *ir.O
 			cr = meta.CreateTime
 		}
 	}
-	// HOT PATH: Simple text delta - use pooled struct for zero-allocation
-	if ev.Type == ir.EventTypeToken && ev.Content != "" && ev.Refusal == "" && ev.Logprobs == nil && ev.SystemFingerprint == "" {
-		return ir.BuildOpenAITextDeltaSSE(rid, model, cr, ev.Content), nil
-	}
-	// HOT PATH: Reasoning delta - use pooled struct
-	if ev.Type == ir.EventTypeReasoning && ev.Reasoning != "" {
-		return ir.BuildOpenAIReasoningDeltaSSE(rid, model, cr, ev.Reasoning, string(ev.ThoughtSignature)), nil
-	}
-	// HOT PATH: Tool call delta - use pooled struct for zero-allocation
-	if ev.Type == ir.EventTypeToolCall && ev.ToolCall != nil {
-		ts := ev.ThoughtSignature
-		if len(ts) == 0 {
-			ts = ev.ToolCall.ThoughtSignature
+	// The pooled builders always write choice 0 and no filter results.
+	if ev.CandidateIndex == 0 && ev.ContentFilter == nil && ev.PromptFilter == nil {
+		// HOT PATH: Simple text delta - use pooled struct for zero-allocation
+		if ev.Type == ir.EventTypeToken && ev.Content != "" && ev.Refusal == "" && ev.Logprobs == nil && ev.SystemFingerprint == "" {
+			return ir.BuildOpenAITextDeltaSSE(rid, model, cr, ev.Content), nil
+		}
+		// HOT PATH: Reasoning delta - use pooled struct
+		if ev.Type == ir.EventTypeReasoning && ev.Reasoning != "" {
+			return ir.BuildOpenAIReasoningDeltaSSE(rid, model, cr, ev.Reasoning, string(ev.ThoughtSignature)), nil
+		}
+		// HOT PATH: Tool call delta - use pooled struct for zero-allocation
+		if ev.Type == ir.EventTypeToolCall && ev.ToolCall != nil {
+			ts := ev.ThoughtSignature
+			if len(ts) == 0 {
+				ts = ev.ToolCall.ThoughtSignature
+			}
+			return ir.BuildOpenAIToolCallDeltaSSE(rid, model, cr, ci, ev.ToolCall.ID, ev.ToolCall.Name, ev.ToolCall.Args, ts), nil
+		}
+		// HOT PATH: Tool call args delta (streaming args) - use pooled struct
+		if ev.Type == ir.EventTypeToolCallDelta && ev.ToolCall != nil {
+			return ir.BuildOpenAIToolCallArgsDeltaSSE(rid, model, cr, ci, ev.ToolCall.Args), nil
 		}
-		return ir.BuildOpenAIToolCallDeltaSSE(rid, model, cr, ci, ev.ToolCall.ID, ev.ToolCall.Name, ev.ToolCall.Args, ts), nil
-	}
-	// HOT PATH: Tool call args delta (streaming args) - use pooled struct
-	if ev.Type == ir.EventTypeToolCallDelta && ev.ToolCall != nil {
-		return ir.BuildOpenAIToolCallArgsDeltaSSE(rid, model, cr, ci, ev.ToolCall.Args), nil
 	}
 	ch := map[string]any{"id": rid, "object": "chat.completion.chunk", "created": cr, "model": model, "choices": []any{}}
 	if ev.SystemFingerprint != "" {
 		ch["system_fingerprint"] = ev.SystemFingerprint
 	}
-	c := map[string]any{"index": 0, "delta": map[string]any{}}
+	c := map[string]any{"index": ev.CandidateIndex, "delta": map[string]any{}}
 	switch ev.Type {
 	case ir.EventTypeToken:
 		d := map[string]any{"role": "assistant"}
@@ -636,32 +725,48 @@ func ToOpenAIChunkMeta(ev ir.UnifiedEvent, model, mid string, ci int, meta *ir.O
 		if ev.Logprobs != nil {
 			c["logprobs"] = ev.Logprobs
 		}
-		if ev.ContentFilter != nil {
-			c["content_filter_results"] = ev.ContentFilter
-		}
 		if ev.Usage != nil {
 			ch["usage"] = buildUsageMap(ev.Usage, meta)
 		}
 		if ev.GroundingMetadata != nil {
 			ch["grounding_metadata"] = buildOpenAIGroundingMetadata(ev.GroundingMetadata)
 		}
+		if ev.FinishReason == ir.FinishReasonError && ev.Error != nil {
+			ch["error"] = map[string]any{"message": ev.Error.Error(), "type": "server_error"}
+		}
 	case ir.EventTypeError:
 		return nil, fmt.Errorf("stream error: %s", ev.ErrorMessage())
 	}
 	if ev.Logprobs != nil && ev.Type != ir.EventTypeFinish {
 		c["logprobs"] = ev.Logprobs
 	}
+	if ev.ContentFilter != nil {
+		c["content_filter_results"] = ev.ContentFilter
+	}
+	if ev.PromptFilter != nil {
+		ch["prompt_filter_results"] = ev.PromptFilter
+	}
 	ch["choices"] = []any{c}
 	jb, _ := json.Marshal(ch)
 	return ir.BuildSSEChunk(jb), nil
 }
 
-func convertMessageToOpenAI(msg ir.Message) map[string]any {
+// openAISystemRole returns the role for a system message. OpenAI reasoning
+// models take instructions in the developer role, and messages that arrived
+// as developer keep it.
+func openAISystemRole(msg ir.Message, reasoning bool) string {
+	if reasoning || msg.Developer {
+		return "developer"
+	}
+	return "system"
+}
+
+func convertMessageToOpenAI(msg ir.Message, reasoning bool) map[string]any {
 	var res map[string]any
 	switch msg.Role {
 	case ir.RoleSystem:
 		if t := ir.CombineTextParts(msg); t != "" {
-			res = map[string]any{"role": "system", "content": t}
+			res = map[string]any{"role": openAISystemRole(msg, reasoning), "content": t}
 		}
 	case ir.RoleUser:
 		res = buildOpenAIUserMessage(msg)
@@ -679,6 +784,31 @@ func convertMessageToOpenAI(msg ir.Message) map[string]any {
 	return res
 }
 
+// buildOpenAIFile renders a chat completions file object, or nil when the
+// part carries neither an ID nor data.
+func buildOpenAIFile(file *ir.FilePart) map[string]any {
+	if file == nil {
+		return nil
+	}
+	f := make(map[string]any, 3)
+	if file.FileID != "" {
+		f["file_id"] = file.FileID
+	}
+	if data := file.FileData; data != "" {
+		if !strings.HasPrefix(data, "data:") && file.MimeType != "" {
+			data = "data:" + file.MimeType + ";base64," + data
+		}
+		f["file_data"] = data
+	}
+	if len(f) == 0 {
+		return nil
+	}
+	if file.Filename != "" {
+		f["filename"] = file.Filename
+	}
+	return f
+}
+
 func buildOpenAIUserMessage(msg ir.Message) map[string]any {
 	ps := make([]any, 0, len(msg.Content))
 	for i := range msg.Content {
@@ -700,6 +830,10 @@ func buildOpenAIUserMessage(msg ir.Message) map[string]any {
 				}
 				ps = append(ps, map[string]any{"type": "input_audio", "input_audio": ia})
 			}
+		case ir.ContentTypeFile:
+			if f := buildOpenAIFile(p.File); f != nil {
+				ps = append(ps, map[string]any{"type": "file", "file": f})
+			}
 		}
 	}
 	if len(ps) == 0 {
@@ -740,8 +874,12 @@ func buildOpenAIAssistantMessage(msg ir.Message) map[string]any {
 	return res
 }
 
-func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *ir.OpenAIMeta) ([]byte, error) {
+// ToResponsesAPIResponse builds a Responses API response. include is the
+// client's "include" list: reasoning items carry the thought signature as
+// encrypted_content and output_text parts carry meta.Logprobs only when asked.
+func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *ir.OpenAIMeta, include []string) ([]byte, error) {
 	rid, cr := fmt.Sprintf("resp_%d", time.Now().UnixNano()), time.Now().Unix()
+	var logprobs any
 	if meta != nil {
 		if meta.ResponseID != "" {
 			rid = meta.ResponseID
@@ -749,7 +887,11 @@ func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *i
 		if meta.CreateTime > 0 {
 			cr = meta.CreateTime
 		}
+		if slices.Contains(include, ir.IncludeOutputTextLogprobs) {
+			logprobs = responsesLogprobs(meta.Logprobs)
+		}
 	}
+	encrypted := slices.Contains(include, ir.IncludeReasoningEncryptedContent)
 	res := map[string]any{"id": rid, "object": "response", "created_at": cr, "status": "completed", "model": model}
 	var out []any
 	var ot string
@@ -759,12 +901,28 @@ func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *i
 			continue
 		}
 		t, r := ir.CombineTextAndReasoning(m)
-		if r != "" {
-			out = append(out, map[string]any{"id": fmt.Sprintf("rs_%s", rid), "type": "reasoning", "summary": []any{map[string]any{"type": "summary_text", "text": r}}})
+		var sig string
+		if encrypted {
+			sig = ir.GetFirstReasoningSignature(m)
+		}
+		if r != "" || sig != "" {
+			summary := []any{}
+			if r != "" {
+				summary = append(summary, map[string]any{"type": "summary_text", "text": r})
+			}
+			item := map[string]any{"id": fmt.Sprintf("rs_%s", rid), "type": "reasoning", "summary": summary}
+			if sig != "" {
+				item["encrypted_content"] = sig
+			}
+			out = append(out, item)
 		}
 		if t != "" {
 			ot = t
-			out = append(out, map[string]any{"id": fmt.Sprintf("msg_%s", rid), "type": "message", "status": "completed", "role": "assistant", "content": []any{map[string]any{"type": "output_text", "text": t, "annotations": []any{}}}})
+			part := map[string]any{"type": "output_text", "text": t, "annotations": []any{}}
+			if logprobs != nil {
+				part["logprobs"] = logprobs
+			}
+			out = append(out, map[string]any{"id": fmt.Sprintf("msg_%s", rid), "type": "message", "status": "completed", "role": "assistant", "content": []any{part}})
 		}
 		for _, tc := range m.ToolCalls {
 			out = append(out, map[string]any{"id": fmt.Sprintf("fc_%s", tc.ID), "type": "function_call", "status": "completed", "call_id": tc.ID, "name": tc.Name, "arguments": tc.Args})
@@ -805,6 +963,9 @@ func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *i
 		if len(od) > 0 {
 			rum["output_tokens_details"] = od
 		}
+		if us != nil && us.Estimated {
+			rum["estimated"] = true
+		}
 		res["usage"] = rum
 	}
 	if meta != nil && meta.GroundingMetadata != nil {
@@ -813,18 +974,42 @@ func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *i
 	return json.Marshal(res)
 }
 
+// responsesLogprobs returns the token entries of OpenAI-style logprobs
+// ({"content": [...]}), which the Responses API lists on output_text parts.
+func responsesLogprobs(v any) any {
+	switch lp := v.(type) {
+	case map[string]any:
+		return lp["content"]
+	case *ir.Logprobs:
+		if m := lp.ToMap(); m != nil {
+			return m["content"]
+		}
+	}
+	return nil
+}
+
+// ResponsesStreamState tracks one Responses API stream. Each output item
+// (reasoning, message or function call) takes the next output_index when it
+// is added, and Output keeps finished items at their index for
+// response.completed.
 type ResponsesStreamState struct {
 	Seq             int
 	ResponseID      string
 	Created         int64
 	Started         bool
 	ReasoningID     string
+	ReasoningIndex  int
 	MsgID           string
+	MsgIndex        int
 	TextBuffer      strings.Builder
 	ReasoningBuffer strings.Builder
-	FuncCallIDs     map[int]string
+	FuncCallIDs     map[int]string // output_index -> item ID
+	CallIDs         map[int]string // output_index -> tool call ID
 	FuncNames       map[int]string
 	FuncArgsBuffer  map[int]*strings.Builder
+	OpenCalls       map[int]int // ToolCallIndex -> output_index of calls streamed as deltas
+	LastCall        int         // output_index of the latest call, -1 before any
+	Output          []any       // nil until the item at that output_index is done
 }
 
 // NewResponsesStreamState creates a new ResponsesStreamState with pre-allocated buffers.
@@ -832,8 +1017,11 @@ type ResponsesStreamState struct {
 func NewResponsesStreamState() *ResponsesStreamState {
 	s := &ResponsesStreamState{
 		FuncCallIDs:    make(map[int]string, 4),
+		CallIDs:        make(map[int]string, 4),
 		FuncNames:      make(map[int]string, 4),
 		FuncArgsBuffer: make(map[int]*strings.Builder, 4),
+		OpenCalls:      make(map[int]int, 4),
+		LastCall:       -1,
 	}
 	// Pre-allocate text buffer for typical response sizes (16KB)
 	s.TextBuffer.Grow(16 * 1024)
@@ -842,8 +1030,44 @@ func NewResponsesStreamState() *ResponsesStreamState {
 	return s
 }
 
+// nextOutputIndex reserves the output_index of a new item.
+func (s *ResponsesStreamState) nextOutputIndex() int {
+	s.Output = append(s.Output, nil)
+	return len(s.Output) - 1
+}
+
+// closeReasoning finishes the open reasoning item. Reasoning that resumes
+// later starts a new item.
+func (s *ResponsesStreamState) closeReasoning(seq int) []byte {
+	r := s.ReasoningBuffer.String()
+	s.Output[s.ReasoningIndex] = ir.ResponsesReasoningItemDone{
+		ID: s.ReasoningID, Type: "reasoning", Status: "completed",
+		Summary: []any{ir.ResponsesSummaryText{Type: "summary_text", Text: r}},
+	}
+	chunk := ir.BuildResponsesOutputItemDoneReasoningSSE(seq, s.ReasoningIndex, s.ReasoningID, r)
+	s.ReasoningID = ""
+	s.ReasoningBuffer.Reset()
+	return chunk
+}
+
+// closeFuncCall finishes the function call at output_index oi. Arguments
+// streamed as deltas win over args.
+func (s *ResponsesStreamState) closeFuncCall(seq, oi int, args string) []byte {
+	if buf := s.FuncArgsBuffer[oi]; buf != nil {
+		args = buf.String()
+	}
+	s.Output[oi] = ir.ResponsesFunctionCallItem{
+		ID: s.FuncCallIDs[oi], Type: "function_call", Status: "completed",
+		CallID: s.CallIDs[oi], Name: s.FuncNames[oi], Arguments: args,
+	}
+	return ir.BuildResponsesOutputItemDoneFunctionCallSSE(seq, s.FuncCallIDs[oi], oi, s.CallIDs[oi], s.FuncNames[oi], args)
+}
+
 // ToResponsesAPIChunk converts a unified event to Responses API SSE chunks.
 // Returns [][]byte for consistency and zero-copy writes to response writer.
+// A stream starts with response.created and response.in_progress, adds each
+// output item with response.output_item.added before its deltas, and ends
+// with response.completed carrying the finished output and usage.
 func ToResponsesAPIChunk(ev ir.UnifiedEvent, model string, s *ResponsesStreamState) ([][]byte, error) {
 	if ev.Type == ir.EventTypeStreamMeta {
 		return nil, nil
@@ -858,89 +1082,144 @@ func ToResponsesAPIChunk(ev ir.UnifiedEvent, model string, s *ResponsesStreamSta
 		out = append(out, ir.BuildResponsesResponseEventSSE("response.in_progress", ns(), s.ResponseID, s.Created, "in_progress"))
 		s.Started = true
 	}
+	// Reasoning ends once the model moves on to text or tool calls.
+	switch ev.Type {
+	case ir.EventTypeToken, ir.EventTypeToolCall, ir.EventTypeToolCallDelta, ir.EventTypeFinish:
+		if s.ReasoningID != "" {
+			out = append(out, s.closeReasoning(ns()))
+		}
+	}
+	openFuncCall := func() int {
+		oi := s.nextOutputIndex()
+		s.FuncCallIDs[oi], s.CallIDs[oi], s.FuncNames[oi] = fmt.Sprintf("fc_%s", ev.ToolCall.ID), ev.ToolCall.ID, ev.ToolCall.Name
+		s.LastCall = oi
+		out = append(out, ir.BuildResponsesOutputItemAddedFunctionCallSSE(ns(), oi, s.FuncCallIDs[oi], ev.ToolCall.ID, ev.ToolCall.Name, "in_progress"))
+		return oi
+	}
 	switch ev.Type {
 	case ir.EventTypeToken:
 		if s.MsgID == "" {
 			s.MsgID = fmt.Sprintf("msg_%s", s.ResponseID)
-			out = append(out, ir.BuildResponsesOutputItemAddedMessageSSE(ns(), 0, s.MsgID, "in_progress"))
-			out = append(out, ir.BuildResponsesContentPartAddedSSE(ns(), s.MsgID, 0, 0))
+			s.MsgIndex = s.nextOutputIndex()
+			out = append(out, ir.BuildResponsesOutputItemAddedMessageSSE(ns(), s.MsgIndex, s.MsgID, "in_progress"))
+			out = append(out, ir.BuildResponsesContentPartAddedSSE(ns(), s.MsgID, s.MsgIndex, 0))
 		}
 		s.TextBuffer.WriteString(ev.Content)
 		// HOT PATH: Use pooled struct for text delta
-		out = append(out, ir.BuildResponsesTextDeltaSSE(ns(), s.MsgID, ev.Content))
+		out = append(out, ir.BuildResponsesTextDeltaSSE(ns(), s.MsgID, s.MsgIndex, ev.Content))
 	case ir.EventTypeReasoning, ir.EventTypeReasoningSummary:
 		t := ev.Reasoning
 		if ev.Type == ir.EventTypeReasoningSummary {
 			t = ev.ReasoningSummary
 		}
 		if s.ReasoningID == "" {
-			s.ReasoningID = fmt.Sprintf("rs_%s", s.ResponseID)
-			out = append(out, ir.BuildResponsesOutputItemAddedReasoningSSE(ns(), 0, s.ReasoningID, "in_progress"))
+			s.ReasoningIndex = s.nextOutputIndex()
+			s.ReasoningID = fmt.Sprintf("rs_%s_%d", s.ResponseID, s.ReasoningIndex)
+			out = append(out, ir.BuildResponsesOutputItemAddedReasoningSSE(ns(), s.ReasoningIndex, s.ReasoningID, "in_progress"))
 		}
 		s.ReasoningBuffer.WriteString(t)
 		// HOT PATH: Use pooled struct for reasoning delta
-		out = append(out, ir.BuildResponsesReasoningDeltaSSE(ns(), s.ReasoningID, t))
+		out = append(out, ir.BuildResponsesReasoningDeltaSSE(ns(), s.ReasoningID, s.ReasoningIndex, t))
 	case ir.EventTypeToolCall:
-		idx := ev.ToolCallIndex
-		if _, ok := s.FuncCallIDs[idx]; !ok {
-			s.FuncCallIDs[idx], s.FuncNames[idx] = fmt.Sprintf("fc_%s", ev.ToolCall.ID), ev.ToolCall.Name
-			out = append(out, ir.BuildResponsesOutputItemAddedFunctionCallSSE(ns(), idx, s.FuncCallIDs[idx], ev.ToolCall.ID, ev.ToolCall.Name, "in_progress"))
+		if ev.ToolCall == nil {
+			break
 		}
-		if ev.ToolCall.Args != "" {
-			out = append(out, ir.BuildResponsesFunctionCallArgsDeltaSSE(ns(), s.FuncCallIDs[idx], idx, ev.ToolCall.Args))
+		// A complete call, or the end of one streamed as deltas.
+		oi, streamed := s.OpenCalls[ev.ToolCallIndex]
+		if streamed {
+			delete(s.OpenCalls, ev.ToolCallIndex)
+		} else {
+			oi = openFuncCall()
 		}
-		out = append(out, ir.BuildResponsesOutputItemDoneFunctionCallSSE(ns(), s.FuncCallIDs[idx], idx, ev.ToolCall.ID, ev.ToolCall.Name, ev.ToolCall.Args))
+		// Arguments already streamed as deltas are not repeated.
+		if s.FuncArgsBuffer[oi] == nil && ev.ToolCall.Args != "" {
+			out = append(out, ir.BuildResponsesFunctionCallArgsDeltaSSE(ns(), s.FuncCallIDs[oi], oi, ev.ToolCall.Args))
+		}
+		out = append(out, s.closeFuncCall(ns(), oi, ev.ToolCall.Args))
 	case ir.EventTypeToolCallDelta:
-		idx := ev.ToolCallIndex
-		if _, ok := s.FuncCallIDs[idx]; !ok {
-			s.FuncCallIDs[idx] = fmt.Sprintf("fc_%s", ev.ToolCall.ID)
-			out = append(out, ir.BuildResponsesOutputItemAddedFunctionCallSSE(ns(), idx, s.FuncCallIDs[idx], ev.ToolCall.ID, "", "in_progress"))
+		if ev.ToolCall == nil {
+			break
+		}
+		oi, ok := s.OpenCalls[ev.ToolCallIndex]
+		if !ok {
+			if ev.ToolCall.ID == "" && ev.ToolCall.Name == "" {
+				// Fragments without an announced call continue the latest
+				// call (Gemini partialArgs restart their index in every chunk).
+				if s.LastCall < 0 || s.Output[s.LastCall] != nil {
+					break
+				}
+				oi = s.LastCall
+			} else {
+				oi = openFuncCall()
+				s.OpenCalls[ev.ToolCallIndex] = oi
+			}
 		}
-		if s.FuncArgsBuffer[idx] == nil {
-			s.FuncArgsBuffer[idx] = &strings.Builder{}
+		if ev.ToolCall.Args == "" {
+			break
 		}
-		s.FuncArgsBuffer[idx].WriteString(ev.ToolCall.Args)
-		out = append(out, ir.BuildResponsesFunctionCallArgsDeltaSSE(ns(), s.FuncCallIDs[idx], idx, ev.ToolCall.Args))
+		if s.FuncArgsBuffer[oi] == nil {
+			s.FuncArgsBuffer[oi] = &strings.Builder{}
+		}
+		s.FuncArgsBuffer[oi].WriteString(ev.ToolCall.Args)
+		out = append(out, ir.BuildResponsesFunctionCallArgsDeltaSSE(ns(), s.FuncCallIDs[oi], oi, ev.ToolCall.Args))
 	case ir.EventTypeFinish:
-		t, r := s.TextBuffer.String(), s.ReasoningBuffer.String()
 		if s.MsgID != "" {
-			out = append(out, ir.BuildResponsesContentPartDoneSSE(ns(), s.MsgID, 0, 0, t))
-			out = append(out, ir.BuildResponsesOutputItemDoneMessageSSE(ns(), 0, s.MsgID, t))
+			t := s.TextBuffer.String()
+			out = append(out, ir.BuildResponsesContentPartDoneSSE(ns(), s.MsgID, s.MsgIndex, 0, t))
+			out = append(out, ir.BuildResponsesOutputItemDoneMessageSSE(ns(), s.MsgIndex, s.MsgID, t))
+			s.Output[s.MsgIndex] = ir.ResponsesMessageItemDone{
+				ID: s.MsgID, Type: "message", Status: "completed", Role: "assistant",
+				Content: []any{ir.ResponsesOutputTextRef{Type: "output_text", Text: t}},
+			}
 		}
-		if s.ReasoningID != "" {
-			out = append(out, ir.BuildResponsesOutputItemDoneReasoningSSE(ns(), 0, s.ReasoningID, r))
+		// Calls streamed only as deltas are finished here, in output order.
+		for _, oi := range slices.Sorted(maps.Values(s.OpenCalls)) {
+			out = append(out, s.closeFuncCall(ns(), oi, ""))
 		}
-		var usage *ir.ResponsesDoneUsage
-		if ev.Usage != nil {
-			usage = &ir.ResponsesDoneUsage{
-				InputTokens:  ev.Usage.PromptTokens,
-				OutputTokens: ev.Usage.CompletionTokens,
-				TotalTokens:  ev.Usage.TotalTokens,
-			}
-			var ct int64
-			if ev.Usage.PromptTokensDetails != nil && ev.Usage.PromptTokensDetails.CachedTokens > 0 {
-				ct = ev.Usage.PromptTokensDetails.CachedTokens
-			} else if ev.Usage.CachedTokens > 0 {
-				ct = ev.Usage.CachedTokens
-			}
-			if ct > 0 {
-				usage.InputTokensDetails = &ir.ResponsesTokensDetails{CachedTokens: ct}
-			}
-			var rt int64
-			if ev.Usage.CompletionTokensDetails != nil && ev.Usage.CompletionTokensDetails.ReasoningTokens > 0 {
-				rt = ev.Usage.CompletionTokensDetails.ReasoningTokens
-			} else if ev.Usage.ThoughtsTokenCount > 0 {
-				rt = int64(ev.Usage.ThoughtsTokenCount)
-			}
-			if rt > 0 {
-				usage.OutputTokensDetails = &ir.ResponsesOutputTokensDetails{ReasoningTokens: rt}
+		clear(s.OpenCalls)
+		output := make([]any, 0, len(s.Output))
+		for _, item := range s.Output {
+			if item != nil {
+				output = append(output, item)
 			}
 		}
-		out = append(out, ir.BuildResponsesDoneSSE(ns(), s.ResponseID, s.Created, usage))
+		out = append(out, ir.BuildResponsesCompletedSSE(ns(), s.ResponseID, s.Created, model, output, responsesStreamUsage(ev.Usage)))
 	}
 	return out, nil
 }
 
+// responsesStreamUsage converts IR usage to the usage of response.completed.
+func responsesStreamUsage(u *ir.Usage) *ir.ResponsesDoneUsage {
+	if u == nil {
+		return nil
+	}
+	usage := &ir.ResponsesDoneUsage{
+		InputTokens:  u.PromptTokens,
+		OutputTokens: u.CompletionTokens,
+		TotalTokens:  u.TotalTokens,
+		Estimated:    u.Estimated,
+	}
+	var ct int64
+	if u.PromptTokensDetails != nil && u.PromptTokensDetails.CachedTokens > 0 {
+		ct = u.PromptTokensDetails.CachedTokens
+	} else if u.CachedTokens > 0 {
+		ct = u.CachedTokens
+	}
+	if ct > 0 {
+		usage.InputTokensDetails = &ir.ResponsesTokensDetails{CachedTokens: ct}
+	}
+	var rt int64
+	if u.CompletionTokensDetails != nil && u.CompletionTokensDetails.ReasoningTokens > 0 {
+		rt = u.CompletionTokensDetails.ReasoningTokens
+	} else if u.ThoughtsTokenCount > 0 {
+		rt = int64(u.ThoughtsTokenCount)
+	}
+	if rt > 0 {
+		usage.OutputTokensDetails = &ir.ResponsesOutputTokensDetails{ReasoningTokens: rt}
+	}
+	return usage
+}
+
 func buildOpenAIGroundingMetadata(gm *ir.GroundingMetadata) map[string]any {
 	if gm == nil {
 		return nil
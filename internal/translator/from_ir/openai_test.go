@@ -0,0 +1,375 @@
+package from_ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestToOpenAIRequest_MaxTokensField(t *testing.T) {
+	tests := []struct {
+		model   string
+		field   string
+		omitted string
+	}{
+		{"gpt-4o", "max_tokens", "max_completion_tokens"},
+		{"llama-3.1-70b", "max_tokens", "max_completion_tokens"},
+		{"o1", "max_completion_tokens", "max_tokens"},
+		{"o3-mini", "max_completion_tokens", "max_tokens"},
+		{"gpt-5.1-codex", "max_completion_tokens", "max_tokens"},
+		{"openai/o4-mini", "max_completion_tokens", "max_tokens"},
+	}
+	for _, tt := range tests {
+		req := &ir.UnifiedChatRequest{
+			Model:     tt.model,
+			MaxTokens: ir.Ptr(512),
+			Messages:  []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Hello"}}}},
+		}
+		out, err := ToOpenAIRequest(req)
+		if err != nil {
+			t.Fatalf("%s: ToOpenAIRequest failed: %v", tt.model, err)
+		}
+		if got := gjson.GetBytes(out, tt.field).Int(); got != 512 {
+			t.Errorf("%s: %s = %d, want 512", tt.model, tt.field, got)
+		}
+		if gjson.GetBytes(out, tt.omitted).Exists() {
+			t.Errorf("%s: unexpected %s in %s", tt.model, tt.omitted, out)
+		}
+	}
+}
+
+func TestToOpenAIRequest_FilePart(t *testing.T) {
+	req := &ir.UnifiedChatRequest{
+		Model: "gpt-4o",
+		Messages: []ir.Message{{Role: ir.RoleUser, Content: []ir.ContentPart{
+			{Type: ir.ContentTypeText, Text: "Summarize"},
+			{Type: ir.ContentTypeFile, File: &ir.FilePart{FileID: "file-abc"}},
+			{Type: ir.ContentTypeFile, File: &ir.FilePart{Filename: "a.pdf", FileData: "QUJD", MimeType: "application/pdf"}},
+		}}},
+	}
+	out, err := ToOpenAIRequest(req)
+	if err != nil {
+		t.Fatalf("ToOpenAIRequest failed: %v", err)
+	}
+	content := gjson.GetBytes(out, "messages.0.content")
+	if got := content.Get("1.file.file_id").String(); got != "file-abc" {
+		t.Errorf("file_id = %q, want file-abc in %s", got, content.Raw)
+	}
+	if got := content.Get("2.file.file_data").String(); got != "data:application/pdf;base64,QUJD" {
+		t.Errorf("file_data = %q", got)
+	}
+	if got := content.Get("2.file.filename").String(); got != "a.pdf" {
+		t.Errorf("filename = %q, want a.pdf", got)
+	}
+}
+
+func TestToOpenAIRequest_DeveloperRole(t *testing.T) {
+	text := func(s string) []ir.ContentPart { return []ir.ContentPart{{Type: ir.ContentTypeText, Text: s}} }
+	tests := []struct {
+		model     string
+		developer bool
+		want      string
+	}{
+		{"gpt-4o", false, "system"},
+		{"gpt-4o", true, "developer"},
+		{"o3-mini", false, "developer"},
+		{"gpt-5", false, "developer"},
+	}
+	for _, tt := range tests {
+		req := &ir.UnifiedChatRequest{
+			Model: tt.model,
+			Messages: []ir.Message{
+				{Role: ir.RoleSystem, Developer: tt.developer, Content: text("Be brief.")},
+				{Role: ir.RoleUser, Content: text("Hello")},
+			},
+		}
+		out, err := ToOpenAIRequest(req)
+		if err != nil {
+			t.Fatalf("%s: ToOpenAIRequest failed: %v", tt.model, err)
+		}
+		if got := gjson.GetBytes(out, "messages.0.role").String(); got != tt.want {
+			t.Errorf("%s (developer=%t): chat role = %q, want %q", tt.model, tt.developer, got, tt.want)
+		}
+		out, err = ToOpenAIRequestFmt(req, FormatResponsesAPI)
+		if err != nil {
+			t.Fatalf("%s: ToOpenAIRequestFmt failed: %v", tt.model, err)
+		}
+		if got := gjson.GetBytes(out, "input.0.role").String(); got != tt.want {
+			t.Errorf("%s (developer=%t): responses role = %q, want %q", tt.model, tt.developer, got, tt.want)
+		}
+	}
+}
+
+func TestToResponsesAPIResponse_Include(t *testing.T) {
+	msgs := []ir.Message{{
+		Role: ir.RoleAssistant,
+		Content: []ir.ContentPart{
+			{Type: ir.ContentTypeReasoning, Reasoning: "thinking", ThoughtSignature: []byte("sig-1")},
+			{Type: ir.ContentTypeText, Text: "Hi"},
+		},
+	}}
+	meta := &ir.OpenAIMeta{Logprobs: map[string]any{"content": []any{map[string]any{"token": "Hi", "logprob": -0.1}}}}
+
+	tests := []struct {
+		name          string
+		include       []string
+		wantEncrypted bool
+		wantLogprobs  bool
+	}{
+		{name: "none"},
+		{name: "encrypted content", include: []string{ir.IncludeReasoningEncryptedContent}, wantEncrypted: true},
+		{name: "logprobs", include: []string{ir.IncludeOutputTextLogprobs}, wantLogprobs: true},
+		{name: "both", include: []string{ir.IncludeOutputTextLogprobs, ir.IncludeReasoningEncryptedContent}, wantEncrypted: true, wantLogprobs: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ToResponsesAPIResponse(msgs, nil, "m", meta, tt.include)
+			if err != nil {
+				t.Fatalf("ToResponsesAPIResponse: %v", err)
+			}
+			reasoning := gjson.GetBytes(out, `output.#(type=="reasoning")`)
+			if got := reasoning.Get("encrypted_content").String(); (got == "sig-1") != tt.wantEncrypted {
+				t.Errorf("encrypted_content = %q, want present=%v", got, tt.wantEncrypted)
+			}
+			if got := reasoning.Get("summary.0.text").String(); got != "thinking" {
+				t.Errorf("summary = %q", got)
+			}
+			logprobs := gjson.GetBytes(out, `output.#(type=="message").content.0.logprobs`)
+			if logprobs.Exists() != tt.wantLogprobs || (tt.wantLogprobs && logprobs.Get("0.token").String() != "Hi") {
+				t.Errorf("logprobs = %s, want present=%v", logprobs.Raw, tt.wantLogprobs)
+			}
+		})
+	}
+
+	signatureOnly := []ir.Message{{Role: ir.RoleAssistant, Content: []ir.ContentPart{
+		{Type: ir.ContentTypeReasoning, ThoughtSignature: []byte("sig-2")},
+		{Type: ir.ContentTypeText, Text: "Hi"},
+	}}}
+	out, err := ToResponsesAPIResponse(signatureOnly, nil, "m", nil, []string{ir.IncludeReasoningEncryptedContent})
+	if err != nil {
+		t.Fatalf("ToResponsesAPIResponse: %v", err)
+	}
+	if got := gjson.GetBytes(out, `output.#(type=="reasoning").encrypted_content`).String(); got != "sig-2" {
+		t.Errorf("signature-only reasoning dropped: %s", out)
+	}
+	out, _ = ToResponsesAPIResponse(signatureOnly, nil, "m", nil, nil)
+	if gjson.GetBytes(out, `output.#(type=="reasoning")`).Exists() {
+		t.Errorf("empty reasoning item emitted without include: %s", out)
+	}
+}
+
+func TestToResponsesAPIChunk_ReasoningThenToolCall(t *testing.T) {
+	events := []ir.UnifiedEvent{
+		{Type: ir.EventTypeReasoningSummary, ReasoningSummary: "Need the weather."},
+		{Type: ir.EventTypeToolCallDelta, ToolCallIndex: 0, ToolCall: &ir.ToolCall{ID: "call_1", Name: "get_weather"}},
+		{Type: ir.EventTypeToolCallDelta, ToolCallIndex: 0, ToolCall: &ir.ToolCall{Args: `{"city":`}},
+		{Type: ir.EventTypeToolCallDelta, ToolCallIndex: 0, ToolCall: &ir.ToolCall{Args: `"Paris"}`}},
+		{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonToolCalls, Usage: &ir.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+	}
+	s := NewResponsesStreamState()
+	var types []string
+	var completed gjson.Result
+	seq := int64(0)
+	for _, ev := range events {
+		chunks, err := ToResponsesAPIChunk(ev, "m", s)
+		if err != nil {
+			t.Fatalf("ToResponsesAPIChunk: %v", err)
+		}
+		for _, c := range chunks {
+			_, payload, _ := strings.Cut(string(c), "data: ")
+			data := gjson.Parse(payload)
+			if !strings.HasPrefix(string(c), "event: "+data.Get("type").String()+"\n") {
+				t.Errorf("event line does not match type: %q", c)
+			}
+			if got := data.Get("sequence_number").Int(); got != seq+1 {
+				t.Errorf("sequence_number = %d, want %d", got, seq+1)
+			}
+			seq = data.Get("sequence_number").Int()
+			types = append(types, data.Get("type").String())
+			if data.Get("type").String() == "response.completed" {
+				completed = data.Get("response")
+			}
+		}
+	}
+
+	want := []string{
+		"response.created",
+		"response.in_progress",
+		"response.output_item.added",
+		"response.reasoning_summary_text.delta",
+		"response.output_item.done",
+		"response.output_item.added",
+		"response.function_call_arguments.delta",
+		"response.function_call_arguments.delta",
+		"response.output_item.done",
+		"response.completed",
+	}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("event order:\n got %v\nwant %v", types, want)
+	}
+
+	if !strings.HasPrefix(completed.Get("id").String(), "resp_") || completed.Get("status").String() != "completed" {
+		t.Errorf("completed response = %s", completed.Raw)
+	}
+	if got := completed.Get("output.#").Int(); got != 2 {
+		t.Fatalf("output items = %d, want 2: %s", got, completed.Raw)
+	}
+	if got := completed.Get("output.0.summary.0.text").String(); got != "Need the weather." {
+		t.Errorf("reasoning summary = %q", got)
+	}
+	call := completed.Get("output.1")
+	if call.Get("type").String() != "function_call" || call.Get("call_id").String() != "call_1" || call.Get("arguments").String() != `{"city":"Paris"}` {
+		t.Errorf("function call = %s", call.Raw)
+	}
+	if got := completed.Get("usage.total_tokens").Int(); got != 15 {
+		t.Errorf("usage.total_tokens = %d, want 15", got)
+	}
+}
+
+func TestReasoningEffort_OpenAIRoundTrip(t *testing.T) {
+	for _, effort := range []string{"none", "minimal", "low", "medium", "high", "xhigh"} {
+		t.Run(effort, func(t *testing.T) {
+			req, err := to_ir.ParseOpenAIRequest([]byte(`{"model":"o3","messages":[{"role":"user","content":"hi"}],"reasoning_effort":"` + effort + `"}`))
+			if err != nil {
+				t.Fatalf("ParseOpenAIRequest: %v", err)
+			}
+			if req.Thinking == nil || string(req.Thinking.Effort) != effort {
+				t.Fatalf("Thinking = %+v, want Effort %q", req.Thinking, effort)
+			}
+			chat, err := ToOpenAIRequest(req)
+			if err != nil {
+				t.Fatalf("ToOpenAIRequest: %v", err)
+			}
+			if got := gjson.GetBytes(chat, "reasoning_effort").String(); got != effort {
+				t.Errorf("chat reasoning_effort = %q, want %q", got, effort)
+			}
+			responses, err := ToOpenAIRequestFmt(req, FormatResponsesAPI)
+			if err != nil {
+				t.Fatalf("ToOpenAIRequestFmt: %v", err)
+			}
+			if got := gjson.GetBytes(responses, "reasoning.effort").String(); got != effort {
+				t.Errorf("responses reasoning.effort = %q, want %q", got, effort)
+			}
+		})
+	}
+
+	// Budgets from formats without an effort still map to one.
+	budget := int32(20000)
+	chat, _ := ToOpenAIRequest(&ir.UnifiedChatRequest{Model: "o3", Thinking: &ir.ThinkingConfig{ThinkingBudget: &budget, IncludeThoughts: true}})
+	if got := gjson.GetBytes(chat, "reasoning_effort").String(); got != "high" {
+		t.Errorf("reasoning_effort from budget = %q, want high", got)
+	}
+}
+
+func TestReasoningEffort_CrossProviderBudget(t *testing.T) {
+	tests := []struct {
+		effort       string
+		claudeBudget int64
+		geminiBudget int64
+		geminiLevel  string
+	}{
+		{"low", 1024, 1024, "low"},
+		{"medium", 8192, 8192, "high"},
+		{"high", 32768, 32768, "high"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.effort, func(t *testing.T) {
+			parse := func(model string) *ir.UnifiedChatRequest {
+				req, err := to_ir.ParseOpenAIRequest([]byte(`{"model":"` + model + `","messages":[{"role":"user","content":"hi"}],"reasoning_effort":"` + tt.effort + `"}`))
+				if err != nil {
+					t.Fatalf("ParseOpenAIRequest: %v", err)
+				}
+				return req
+			}
+
+			claude, err := (&ClaudeProvider{}).ConvertRequest(parse("claude-sonnet-4-5"))
+			if err != nil {
+				t.Fatalf("claude convert: %v", err)
+			}
+			if got := gjson.GetBytes(claude, "thinking.budget_tokens").Int(); got != tt.claudeBudget {
+				t.Errorf("claude budget_tokens = %d, want %d: %s", got, tt.claudeBudget, claude)
+			}
+
+			gemini, err := (&GeminiProvider{}).ConvertRequest(parse("gemini-2.5-pro"))
+			if err != nil {
+				t.Fatalf("gemini convert: %v", err)
+			}
+			if got := gjson.GetBytes(gemini, "generationConfig.thinkingConfig.thinkingBudget").Int(); got != tt.geminiBudget {
+				t.Errorf("gemini thinkingBudget = %d, want %d: %s", got, tt.geminiBudget, gemini)
+			}
+
+			gemini3, err := (&GeminiProvider{}).ConvertRequest(parse("gemini-3-pro-preview"))
+			if err != nil {
+				t.Fatalf("gemini 3 convert: %v", err)
+			}
+			if got := gjson.GetBytes(gemini3, "generationConfig.thinkingConfig.thinkingLevel").String(); !strings.EqualFold(got, tt.geminiLevel) {
+				t.Errorf("gemini 3 thinkingLevel = %q, want %q: %s", got, tt.geminiLevel, gemini3)
+			}
+		})
+	}
+}
+
+func TestPrediction_PerProvider(t *testing.T) {
+	parse := func(model string) *ir.UnifiedChatRequest {
+		req, err := to_ir.ParseOpenAIRequest([]byte(`{"model":"` + model + `","messages":[{"role":"user","content":"Rename x to y"}],"prediction":{"type":"content","content":"let y = 1"}}`))
+		if err != nil {
+			t.Fatalf("ParseOpenAIRequest: %v", err)
+		}
+		return req
+	}
+
+	chat, err := ToOpenAIRequest(parse("gpt-4o"))
+	if err != nil {
+		t.Fatalf("ToOpenAIRequest: %v", err)
+	}
+	if p := gjson.GetBytes(chat, "prediction"); p.Get("type").String() != "content" || p.Get("content").String() != "let y = 1" {
+		t.Errorf("openai prediction = %s", p.Raw)
+	}
+
+	responses, err := ToOpenAIRequestFmt(parse("gpt-4o"), FormatResponsesAPI)
+	if err != nil {
+		t.Fatalf("ToOpenAIRequestFmt: %v", err)
+	}
+	if gjson.GetBytes(responses, "prediction").Exists() {
+		t.Errorf("prediction sent to the Responses API: %s", responses)
+	}
+
+	claude, err := (&ClaudeProvider{}).ConvertRequest(parse("claude-sonnet-4-5"))
+	if err != nil {
+		t.Fatalf("claude convert: %v", err)
+	}
+	gemini, err := (&GeminiProvider{}).ConvertRequest(parse("gemini-2.5-pro"))
+	if err != nil {
+		t.Fatalf("gemini convert: %v", err)
+	}
+	for name, out := range map[string][]byte{"claude": claude, "gemini": gemini} {
+		if strings.Contains(string(out), "prediction") || strings.Contains(string(out), "let y = 1") {
+			t.Errorf("%s payload carries the prediction: %s", name, out)
+		}
+	}
+}
+
+func TestPrediction_UsageRoundTrip(t *testing.T) {
+	resp := `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"let y = 1"},"finish_reason":"stop"}],` +
+		`"usage":{"prompt_tokens":20,"completion_tokens":12,"total_tokens":32,"completion_tokens_details":{"accepted_prediction_tokens":4,"rejected_prediction_tokens":3}}}`
+	msgs, usage, err := to_ir.ParseOpenAIResponse([]byte(resp))
+	if err != nil {
+		t.Fatalf("ParseOpenAIResponse: %v", err)
+	}
+	if usage == nil || usage.CompletionTokensDetails == nil ||
+		usage.CompletionTokensDetails.AcceptedPredictionTokens != 4 || usage.CompletionTokensDetails.RejectedPredictionTokens != 3 {
+		t.Fatalf("usage = %+v, want 4 accepted and 3 rejected prediction tokens", usage)
+	}
+
+	out, err := ToOpenAIChatCompletion(msgs, usage, "gpt-4o", "chatcmpl-1")
+	if err != nil {
+		t.Fatalf("ToOpenAIChatCompletion: %v", err)
+	}
+	details := gjson.GetBytes(out, "usage.completion_tokens_details")
+	if details.Get("accepted_prediction_tokens").Int() != 4 || details.Get("rejected_prediction_tokens").Int() != 3 {
+		t.Errorf("completion_tokens_details = %s", details.Raw)
+	}
+}
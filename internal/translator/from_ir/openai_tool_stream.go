@@ -0,0 +1,104 @@
+package from_ir
+
+import (
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// OpenAIToolCallStream assigns OpenAI tool_calls indexes across a streamed
+// response. A call announced by an EventTypeToolCallDelta carrying its ID or
+// name opens a slot keyed by the source ToolCallIndex: the first chunk is the
+// initial frame (id, type, name, empty arguments) and later deltas for the same
+// source index append argument fragments, so parallel calls interleave safely.
+// A complete EventTypeToolCall closes an open slot, or is sent as one chunk
+// when its arguments were never streamed.
+type OpenAIToolCallStream struct {
+	// Created, when set, is reported as the created time of every chunk
+	// instead of the current time.
+	Created int64
+
+	next int
+	open map[int]*openAIToolCallSlot
+}
+
+type openAIToolCallSlot struct {
+	index    int
+	streamed bool
+}
+
+// NewOpenAIToolCallStream creates an empty tool call index tracker.
+func NewOpenAIToolCallStream() *OpenAIToolCallStream {
+	return &OpenAIToolCallStream{open: make(map[int]*openAIToolCallSlot, 2)}
+}
+
+// Chunk converts ev into OpenAI chat.completion.chunk SSE data. Events other
+// than tool calls are converted by ToOpenAIChunk unchanged.
+func (s *OpenAIToolCallStream) Chunk(ev ir.UnifiedEvent, model, mid string) ([]byte, error) {
+	if ev.ToolCall == nil {
+		return ToOpenAIChunkMeta(ev, model, mid, 0, s.meta())
+	}
+	switch ev.Type {
+	case ir.EventTypeToolCallDelta:
+		return s.delta(ev, model, mid), nil
+	case ir.EventTypeToolCall:
+		if slot := s.open[ev.ToolCallIndex]; slot != nil {
+			delete(s.open, ev.ToolCallIndex)
+			if slot.streamed || ev.ToolCall.Args == "" {
+				return nil, nil
+			}
+			return ir.BuildOpenAIToolCallArgsDeltaSSE(mid, model, s.created(), slot.index, ev.ToolCall.Args), nil
+		}
+		idx := s.next
+		s.next++
+		return ToOpenAIChunkMeta(ev, model, mid, idx, s.meta())
+	}
+	return ToOpenAIChunkMeta(ev, model, mid, 0, s.meta())
+}
+
+func (s *OpenAIToolCallStream) created() int64 {
+	if s.Created > 0 {
+		return s.Created
+	}
+	return time.Now().Unix()
+}
+
+func (s *OpenAIToolCallStream) meta() *ir.OpenAIMeta {
+	if s.Created == 0 {
+		return nil
+	}
+	return &ir.OpenAIMeta{CreateTime: s.Created}
+}
+
+func (s *OpenAIToolCallStream) delta(ev ir.UnifiedEvent, model, mid string) []byte {
+	tc, cr := ev.ToolCall, s.created()
+	slot := s.open[ev.ToolCallIndex]
+	if slot == nil && tc.ID == "" && tc.Name == "" {
+		// Argument fragments without an announced call continue the latest
+		// call (Gemini partialArgs restart their index in every chunk).
+		if tc.Args == "" {
+			return nil
+		}
+		idx := 0
+		if s.next > 0 {
+			idx = s.next - 1
+		}
+		return ir.BuildOpenAIToolCallArgsDeltaSSE(mid, model, cr, idx, tc.Args)
+	}
+	var out []byte
+	if slot == nil {
+		slot = &openAIToolCallSlot{index: s.next}
+		s.next++
+		s.open[ev.ToolCallIndex] = slot
+		ts := ev.ThoughtSignature
+		if len(ts) == 0 {
+			ts = tc.ThoughtSignature
+		}
+		out = ir.BuildOpenAIToolCallDeltaSSE(mid, model, cr, slot.index, tc.ID, tc.Name, "", ts)
+	}
+	if tc.Args != "" {
+		slot.streamed = true
+		out = append(out, ir.BuildOpenAIToolCallArgsDeltaSSE(mid, model, cr, slot.index, tc.Args)...)
+	}
+	return out
+}
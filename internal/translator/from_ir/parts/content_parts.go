@@ -35,7 +35,7 @@ func BuildImagePart(img *ir.ImagePart) map[string]any {
 			},
 		}
 	}
-	if u := img.URL; strings.HasPrefix(u, "files/") || strings.HasPrefix(u, "gs://") {
+	if u := img.URL; isFileReference(u) {
 		return map[string]any{
 			"fileData": map[string]any{
 				"mimeType": img.MimeType,
@@ -46,6 +46,54 @@ func BuildImagePart(img *ir.ImagePart) map[string]any {
 	return nil
 }
 
+// BuildFilePart creates a document content part from IR. Inline data needs a
+// known MIME type; references must point at the Gemini Files API or Cloud
+// Storage.
+func BuildFilePart(file *ir.FilePart) map[string]any {
+	if file == nil {
+		return nil
+	}
+	if data := file.FileData; data != "" {
+		mimeType := file.MimeType
+		if rest, ok := strings.CutPrefix(data, "data:"); ok {
+			if header, payload, found := strings.Cut(rest, ","); found {
+				data = payload
+				if mt, _, _ := strings.Cut(header, ";"); mt != "" {
+					mimeType = mt
+				}
+			}
+		}
+		if mimeType == "" {
+			return nil
+		}
+		return map[string]any{
+			"inlineData": map[string]any{
+				"mimeType": mimeType,
+				"data":     data,
+			},
+		}
+	}
+	ref := file.FileURL
+	if ref == "" {
+		ref = file.FileID
+	}
+	if !isFileReference(ref) {
+		return nil
+	}
+	fileData := map[string]any{"fileUri": ref}
+	if file.MimeType != "" {
+		fileData["mimeType"] = file.MimeType
+	}
+	return map[string]any{"fileData": fileData}
+}
+
+// isFileReference reports whether u names a Gemini Files API file or a Cloud
+// Storage object.
+func isFileReference(u string) bool {
+	return strings.HasPrefix(u, "files/") || strings.HasPrefix(u, "gs://") ||
+		strings.HasPrefix(u, "https://generativelanguage.googleapis.com/")
+}
+
 // BuildAudioPart creates an audio content part from IR.
 func BuildAudioPart(audio *ir.AudioPart) map[string]any {
 	if audio == nil {
@@ -145,6 +193,10 @@ func BuildUserParts(content []ir.ContentPart) []any {
 			if p := BuildVideoPart(part.Video); p != nil {
 				parts = append(parts, p)
 			}
+		case ir.ContentTypeFile:
+			if p := BuildFilePart(part.File); p != nil {
+				parts = append(parts, p)
+			}
 		}
 	}
 	return parts
@@ -0,0 +1,51 @@
+package from_ir
+
+import (
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ToRerankRequest builds a Cohere/Jina style rerank request body. Documents
+// are sent as plain strings and echoed back from the original request.
+func ToRerankRequest(req *ir.RerankRequest, model string) ([]byte, error) {
+	m := map[string]any{
+		"model":            model,
+		"query":            req.Query,
+		"documents":        req.Documents,
+		"return_documents": false,
+	}
+	if req.TopN != nil {
+		m["top_n"] = *req.TopN
+	}
+	return json.Marshal(m)
+}
+
+// ToRerankResponse renders resp in the Cohere/Jina rerank response shape,
+// applying req.TopN and echoing documents when req.ReturnDocuments is set.
+func ToRerankResponse(resp *ir.RerankResponse, req *ir.RerankRequest, model string) ([]byte, error) {
+	results := resp.Results
+	if req.TopN != nil && len(results) > *req.TopN {
+		results = results[:*req.TopN]
+	}
+	data := make([]map[string]any, 0, len(results))
+	for _, r := range results {
+		item := map[string]any{"index": r.Index, "relevance_score": r.RelevanceScore}
+		if req.ReturnDocuments {
+			item["document"] = map[string]any{"text": req.Documents[r.Index]}
+		}
+		data = append(data, item)
+	}
+
+	var total int64
+	if resp.Usage != nil {
+		total = resp.Usage.TotalTokens
+		if total == 0 {
+			total = resp.Usage.PromptTokens
+		}
+	}
+	return json.Marshal(map[string]any{
+		"model":   model,
+		"results": data,
+		"usage":   map[string]any{"total_tokens": total},
+	})
+}
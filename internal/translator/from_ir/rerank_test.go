@@ -0,0 +1,35 @@
+package from_ir
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestToRerankResponse_TopNAndDocuments(t *testing.T) {
+	topN := 1
+	req := &ir.RerankRequest{Documents: []string{"a", "b"}, TopN: &topN, ReturnDocuments: true}
+	resp := &ir.RerankResponse{
+		Results: []ir.RerankResult{{Index: 1, RelevanceScore: 0.8}, {Index: 0, RelevanceScore: 0.2}},
+		Usage:   &ir.Usage{PromptTokens: 9},
+	}
+	body, err := ToRerankResponse(resp, req, "m")
+	if err != nil {
+		t.Fatalf("ToRerankResponse failed: %v", err)
+	}
+	root := gjson.ParseBytes(body)
+	if n := len(root.Get("results").Array()); n != 1 {
+		t.Fatalf("results = %d, want 1", n)
+	}
+	if got := root.Get("results.0.index").Int(); got != 1 {
+		t.Errorf("results.0.index = %d, want 1", got)
+	}
+	if got := root.Get("results.0.document.text").String(); got != "b" {
+		t.Errorf("results.0.document.text = %q, want b", got)
+	}
+	if got := root.Get("usage.total_tokens").Int(); got != 9 {
+		t.Errorf("usage.total_tokens = %d, want 9", got)
+	}
+}
@@ -295,12 +295,13 @@ func ParseClaudeStreamDeltaWithState(parsed gjson.Result, state *ClaudeStreamPar
 	switch delta.Get("type").String() {
 	case ClaudeDeltaText:
 		if text := delta.Get("text").String(); text != "" {
+			event := UnifiedEvent{Type: EventTypeToken, Content: text, Logprobs: ParseClaudeLogprobs(delta.Get("logprobs"))}
 			if state != nil {
 				if pending := state.FlushPending(); pending != nil {
-					return []UnifiedEvent{*pending, {Type: EventTypeToken, Content: text}}
+					return []UnifiedEvent{*pending, event}
 				}
 			}
-			return []UnifiedEvent{{Type: EventTypeToken, Content: text}}
+			return []UnifiedEvent{event}
 		}
 	case ClaudeDeltaThinking:
 		if thinking := delta.Get("thinking").String(); thinking != "" {
@@ -337,8 +338,9 @@ func ParseClaudeStreamDeltaWithState(parsed gjson.Result, state *ClaudeStreamPar
 			if state.ToolUseArgs[idx] == nil {
 				state.ToolUseArgs[idx] = GetStringBuilder()
 			}
-			if pj := delta.Get("partial_json"); pj.Exists() {
-				state.ToolUseArgs[idx].WriteString(pj.String())
+			if pj := delta.Get("partial_json").String(); pj != "" {
+				state.ToolUseArgs[idx].WriteString(pj)
+				return []UnifiedEvent{{Type: EventTypeToolCallDelta, ToolCall: &ToolCall{Args: pj}, ToolCallIndex: idx}}
 			}
 		}
 	}
@@ -356,6 +358,13 @@ func ParseClaudeContentBlockStart(parsed gjson.Result, state *ClaudeStreamParser
 	if cb.Get("type").String() == ClaudeBlockToolUse {
 		state.ToolUseNames[idx] = cb.Get("name").String()
 		state.ToolUseIDs[idx] = cb.Get("id").String()
+		// Announce the call so arguments can be streamed as they arrive; the
+		// complete call still follows at content_block_stop.
+		event := UnifiedEvent{Type: EventTypeToolCallDelta, ToolCall: &ToolCall{ID: state.ToolUseIDs[idx], Name: state.ToolUseNames[idx]}, ToolCallIndex: idx}
+		if pending := state.FlushPending(); pending != nil {
+			return []UnifiedEvent{*pending, event}
+		}
+		return []UnifiedEvent{event}
 	} else if cb.Get("type").String() == ClaudeBlockThinking {
 		if sig := cb.Get("signature").String(); sig != "" {
 			state.CurrentThinkingSignature = sig
@@ -367,7 +376,7 @@ func ParseClaudeContentBlockStart(parsed gjson.Result, state *ClaudeStreamParser
 	return nil
 }
 
-// ParseClaudeContentBlockStop parses content_block_stop event and emits tool call if applicable.
+// ParseClaudeContentBlockStop parses content_block_stop event and emits the complete tool call if applicable.
 func ParseClaudeContentBlockStop(parsed gjson.Result, state *ClaudeStreamParserState) []UnifiedEvent {
 	if state == nil {
 		return nil
@@ -401,8 +410,9 @@ func ParseClaudeContentBlockStop(parsed gjson.Result, state *ClaudeStreamParserS
 	delete(state.BlockTypes, idx)
 
 	events = append(events, UnifiedEvent{
-		Type:     EventTypeToolCall,
-		ToolCall: &ToolCall{ID: id, Name: name, Args: args},
+		Type:          EventTypeToolCall,
+		ToolCall:      &ToolCall{ID: id, Name: name, Args: args},
+		ToolCallIndex: idx,
 	})
 	return events
 }
@@ -1,6 +1,10 @@
 package ir
 
-import "github.com/tidwall/gjson"
+import (
+	"slices"
+
+	"github.com/tidwall/gjson"
+)
 
 // ExtractTemperature extracts temperature from gjson.Result, returns nil if not present.
 func ExtractTemperature(root gjson.Result, keys ...string) *float64 {
@@ -42,9 +46,11 @@ func ExtractTopK(root gjson.Result, keys ...string) *int {
 }
 
 // ExtractMaxTokens extracts max tokens from gjson.Result using multiple key variants.
+// OpenAI's max_completion_tokens takes precedence over the deprecated max_tokens
+// when a request sets both.
 func ExtractMaxTokens(root gjson.Result, keys ...string) *int {
 	if len(keys) == 0 {
-		keys = []string{"max_tokens", "max_output_tokens", "max_completion_tokens", "maxOutputTokens"}
+		keys = []string{"max_completion_tokens", "max_tokens", "max_output_tokens", "maxOutputTokens"}
 	}
 	for _, k := range keys {
 		if v := root.Get(k); v.Exists() {
@@ -54,6 +60,26 @@ func ExtractMaxTokens(root gjson.Result, keys ...string) *int {
 	return nil
 }
 
+// ExtractMinTokens extracts the requested minimum output tokens. Non-positive
+// values are ignored.
+func ExtractMinTokens(root gjson.Result) *int {
+	if v := root.Get("min_tokens"); v.Exists() && v.Int() > 0 {
+		return Ptr(int(v.Int()))
+	}
+	return nil
+}
+
+// ExtractIncludeThoughts extracts the llm-mux include_thoughts override, top
+// level or in extra_body, or nil when the request does not set it.
+func ExtractIncludeThoughts(root gjson.Result) *bool {
+	for _, k := range []string{"include_thoughts", "extra_body.include_thoughts"} {
+		if v := root.Get(k); v.IsBool() {
+			return Ptr(v.Bool())
+		}
+	}
+	return nil
+}
+
 // ExtractStopSequences extracts stop sequences as array or single string.
 func ExtractStopSequences(root gjson.Result, keys ...string) []string {
 	if len(keys) == 0 {
@@ -76,22 +102,85 @@ func ExtractStopSequences(root gjson.Result, keys ...string) []string {
 	return nil
 }
 
+// Per-provider caps on the number of stop sequences. Claude documents no cap.
+const (
+	OpenAIMaxStopSequences = 4
+	GeminiMaxStopSequences = 5
+)
+
+// LimitStopSequences drops empty and duplicate stop sequences and keeps at
+// most limit of them in order. A limit of 0 means no cap. It returns nil when
+// nothing is left so callers can omit the field.
+func LimitStopSequences(seqs []string, limit int) []string {
+	var out []string
+	for _, s := range seqs {
+		if s == "" || slices.Contains(out, s) {
+			continue
+		}
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
 // ExtractFrequencyPenalty extracts frequency_penalty from gjson.Result.
-func ExtractFrequencyPenalty(root gjson.Result) *float64 {
-	if v := root.Get("frequency_penalty"); v.Exists() {
-		return Ptr(v.Float())
+func ExtractFrequencyPenalty(root gjson.Result, keys ...string) *float64 {
+	if len(keys) == 0 {
+		keys = []string{"frequency_penalty"}
+	}
+	for _, k := range keys {
+		if v := root.Get(k); v.Exists() {
+			return Ptr(v.Float())
+		}
 	}
 	return nil
 }
 
 // ExtractPresencePenalty extracts presence_penalty from gjson.Result.
-func ExtractPresencePenalty(root gjson.Result) *float64 {
-	if v := root.Get("presence_penalty"); v.Exists() {
-		return Ptr(v.Float())
+func ExtractPresencePenalty(root gjson.Result, keys ...string) *float64 {
+	if len(keys) == 0 {
+		keys = []string{"presence_penalty"}
+	}
+	for _, k := range keys {
+		if v := root.Get(k); v.Exists() {
+			return Ptr(v.Float())
+		}
 	}
 	return nil
 }
 
+// Valid frequency and presence penalty ranges. OpenAI accepts [-2, 2];
+// Gemini requires the value to stay below 2.
+const (
+	MinPenalty       = -2.0
+	OpenAIMaxPenalty = 2.0
+	GeminiMaxPenalty = 1.99
+)
+
+// ClampPenalty limits a frequency or presence penalty to [MinPenalty, limit].
+func ClampPenalty(v, limit float64) float64 {
+	return min(max(v, MinPenalty), limit)
+}
+
+// GeminiMaxTopK is the largest top_k Gemini models accept; their topK
+// metadata is at most 64.
+const GeminiMaxTopK = 64
+
+// ClampTopK limits top_k to [1, limit], or to at least 1 when limit is zero.
+// ok is false for zero or negative values, which disable top-k sampling and
+// are not sent.
+func ClampTopK(v, limit int) (k int, ok bool) {
+	if v <= 0 {
+		return 0, false
+	}
+	if limit > 0 {
+		v = min(v, limit)
+	}
+	return v, true
+}
+
 // ExtractLogprobs extracts logprobs boolean from gjson.Result.
 func ExtractLogprobs(root gjson.Result) *bool {
 	if v := root.Get("logprobs"); v.Exists() {
@@ -117,12 +206,13 @@ func ExtractCandidateCount(root gjson.Result) *int {
 }
 
 // ApplyCommonParams applies common LLM parameters to UnifiedChatRequest.
-// This is a convenience function that applies temperature, top_p, top_k, max_tokens, and stop sequences.
+// This is a convenience function that applies temperature, top_p, top_k, max_tokens, min_tokens and stop sequences.
 func ApplyCommonParams(req *UnifiedChatRequest, root gjson.Result) {
 	req.Temperature = ExtractTemperature(root)
 	req.TopP = ExtractTopP(root)
 	req.TopK = ExtractTopK(root)
 	req.MaxTokens = ExtractMaxTokens(root)
+	req.MinTokens = ExtractMinTokens(root)
 	req.StopSequences = ExtractStopSequences(root)
 }
 
@@ -134,3 +224,15 @@ func ApplyOpenAIExtendedParams(req *UnifiedChatRequest, root gjson.Result) {
 	req.TopLogprobs = ExtractTopLogprobs(root)
 	req.CandidateCount = ExtractCandidateCount(root)
 }
+
+// EndUserID returns the end-user identifier of a request: Claude's
+// metadata.user_id, or else OpenAI's user field. Returns "" when neither is set.
+func EndUserID(meta map[string]any) string {
+	if md, ok := meta[MetaClaudeMetadata].(map[string]any); ok {
+		if v, ok := md["user_id"].(string); ok && v != "" {
+			return v
+		}
+	}
+	v, _ := meta[MetaOpenAIUser].(string)
+	return v
+}
@@ -1,5 +1,7 @@
 package ir
 
+import "strings"
+
 // ContentFilterResult contains content safety filtering results.
 // This replaces the `any` type for type safety.
 type ContentFilterResult struct {
@@ -123,3 +125,86 @@ func (c *ContentFilterResult) IsBlocked() bool {
 	}
 	return false
 }
+
+// OpenAIContentFilterResults maps safety ratings to the Azure OpenAI
+// content_filter_results shape, one entry per category with "filtered" and
+// "severity". Returns nil when there are no ratings.
+func OpenAIContentFilterResults(ratings []*SafetyRating) map[string]any {
+	out := make(map[string]any, len(ratings))
+	for _, r := range ratings {
+		if r == nil || r.Category == "" {
+			continue
+		}
+		entry := map[string]any{"filtered": r.Blocked}
+		if sev := filterSeverity(r); sev != "" {
+			entry["severity"] = sev
+		}
+		out[filterCategory(r.Category)] = entry
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// OpenAIPromptFilterResults maps prompt feedback to the Azure OpenAI
+// prompt_filter_results shape. A block that no rating accounts for is reported
+// under the lower-cased block reason. Returns nil when there is nothing to report.
+func OpenAIPromptFilterResults(pf *PromptFeedback) []any {
+	if pf == nil {
+		return nil
+	}
+	results := OpenAIContentFilterResults(pf.SafetyRatings)
+	if pf.BlockReason != "" && pf.BlockReason != "BLOCK_REASON_UNSPECIFIED" && !anyBlocked(pf.SafetyRatings) {
+		if results == nil {
+			results = map[string]any{}
+		}
+		results[strings.ToLower(pf.BlockReason)] = map[string]any{"filtered": true}
+	}
+	if results == nil {
+		return nil
+	}
+	return []any{map[string]any{"prompt_index": 0, "content_filter_results": results}}
+}
+
+func anyBlocked(ratings []*SafetyRating) bool {
+	for _, r := range ratings {
+		if r != nil && r.Blocked {
+			return true
+		}
+	}
+	return false
+}
+
+// filterCategory maps a Gemini HarmCategory to its content filter category.
+func filterCategory(category string) string {
+	switch category {
+	case "HARM_CATEGORY_HATE_SPEECH":
+		return FilterCategoryHateSpeech
+	case "HARM_CATEGORY_SEXUALLY_EXPLICIT", "HARM_CATEGORY_SEXUAL":
+		return FilterCategorySexual
+	case "HARM_CATEGORY_HARASSMENT":
+		return FilterCategoryHarassment
+	case "HARM_CATEGORY_DANGEROUS_CONTENT", "HARM_CATEGORY_DANGEROUS":
+		return FilterCategoryDangerous
+	case "HARM_CATEGORY_VIOLENCE":
+		return FilterCategoryViolence
+	}
+	return strings.ToLower(strings.TrimPrefix(category, "HARM_CATEGORY_"))
+}
+
+// filterSeverity maps a rating's severity, or its probability when no
+// severity is given, to the Azure levels safe, low, medium and high.
+func filterSeverity(r *SafetyRating) string {
+	level := strings.TrimPrefix(r.Severity, "HARM_SEVERITY_")
+	if level == "" || level == "UNSPECIFIED" {
+		level = strings.TrimPrefix(r.Probability, "HARM_PROBABILITY_")
+	}
+	switch level {
+	case "", "UNSPECIFIED":
+		return ""
+	case "NEGLIGIBLE":
+		return "safe"
+	}
+	return strings.ToLower(level)
+}
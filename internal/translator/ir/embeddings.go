@@ -0,0 +1,28 @@
+package ir
+
+// Embedding encoding formats accepted on the OpenAI embeddings API.
+const (
+	EmbeddingEncodingFloat  = "float"
+	EmbeddingEncodingBase64 = "base64"
+)
+
+// EmbeddingRequest is the provider-neutral form of an embeddings request.
+type EmbeddingRequest struct {
+	Model string
+	// Input holds one entry per text to embed; a single string input becomes
+	// a one-element slice.
+	Input []string
+	// Dimensions requests truncated output vectors when the model supports it.
+	Dimensions *int
+	// EncodingFormat is EmbeddingEncodingFloat (default) or EmbeddingEncodingBase64.
+	EncodingFormat string
+	User           string
+}
+
+// EmbeddingResponse is the provider-neutral form of an embeddings response.
+type EmbeddingResponse struct {
+	Model string
+	// Embeddings are in the same order as EmbeddingRequest.Input.
+	Embeddings [][]float64
+	Usage      *Usage
+}
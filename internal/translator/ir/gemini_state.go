@@ -28,6 +28,15 @@ type GeminiStreamParserState struct {
 	// ActualCacheTokens stores the cachedContentTokenCount from Gemini's usageMetadata.
 	// Used to calculate: input_tokens = promptTokenCount - cachedContentTokenCount
 	ActualCacheTokens int64
+
+	// ContentFilterResults maps safety ratings and prompt feedback to OpenAI
+	// content filter results on finish events.
+	ContentFilterResults bool
+
+	// ResponseID and CreateTime hold the responseId and createTime of the
+	// first chunk that reports them.
+	ResponseID string
+	CreateTime int64
 }
 
 // NewGeminiStreamParserState creates a new state for parsing Gemini streams.
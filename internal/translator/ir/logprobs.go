@@ -1,5 +1,7 @@
 package ir
 
+import "github.com/tidwall/gjson"
+
 // Logprobs contains token log probability information.
 // This replaces the `any` type for type safety.
 type Logprobs struct {
@@ -95,3 +97,22 @@ func ParseLogprobs(data any) *Logprobs {
 
 	return result
 }
+
+// ParseClaudeLogprobs converts the logprobs attached to a Claude response or
+// text delta to the OpenAI {"content": [...]} shape. Claude reports them
+// either as that object or as a bare array of token entries. Returns nil when
+// v carries no token entries.
+func ParseClaudeLogprobs(v gjson.Result) any {
+	content := v
+	if v.IsObject() {
+		content = v.Get("content")
+	}
+	if !content.IsArray() {
+		return nil
+	}
+	entries, ok := content.Value().([]any)
+	if !ok || len(entries) == 0 {
+		return nil
+	}
+	return map[string]any{"content": entries}
+}
@@ -116,6 +116,25 @@ func CombineTextParts(msg Message) string {
 	return b.String()
 }
 
+// SystemText joins the text of every system message, including those sent
+// with the developer role, in order. Formats with a single system field use it
+// so no instruction is dropped when a request carries several.
+func SystemText(messages []Message) string {
+	var texts []string
+	for i := range messages {
+		if messages[i].Role != RoleSystem {
+			continue
+		}
+		if t := CombineTextParts(messages[i]); t != "" {
+			texts = append(texts, t)
+		}
+	}
+	if len(texts) == 1 {
+		return texts[0]
+	}
+	return strings.Join(texts, "\n\n")
+}
+
 // CombineReasoningParts combines all reasoning content parts from a message.
 // Optimized to avoid allocations for single-part messages.
 func CombineReasoningParts(msg Message) string {
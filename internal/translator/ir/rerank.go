@@ -0,0 +1,26 @@
+package ir
+
+// RerankRequest is the provider-neutral form of a rerank request.
+type RerankRequest struct {
+	Model     string
+	Query     string
+	Documents []string
+	// TopN limits the number of results returned; nil returns every document.
+	TopN *int
+	// ReturnDocuments echoes each document's text in the results.
+	ReturnDocuments bool
+}
+
+// RerankResult scores a single document from RerankRequest.Documents.
+type RerankResult struct {
+	Index          int
+	RelevanceScore float64
+}
+
+// RerankResponse is the provider-neutral form of a rerank response.
+type RerankResponse struct {
+	Model string
+	// Results are ordered by descending relevance score.
+	Results []RerankResult
+	Usage   *Usage
+}
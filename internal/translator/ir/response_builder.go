@@ -509,23 +509,30 @@ func (b *ResponseBuilder) BuildUsageMap() map[string]any {
 		"total_tokens":      b.usage.TotalTokens,
 	}
 
-	// Add prompt_tokens_details if available
+	// Add prompt_tokens_details if available, falling back to the flat
+	// CachedTokens count that some parsers fill without details
+	promptDetails := make(map[string]any)
 	if b.usage.PromptTokensDetails != nil {
-		promptDetails := make(map[string]any)
 		if b.usage.PromptTokensDetails.CachedTokens > 0 {
 			promptDetails["cached_tokens"] = b.usage.PromptTokensDetails.CachedTokens
 		}
 		if b.usage.PromptTokensDetails.AudioTokens > 0 {
 			promptDetails["audio_tokens"] = b.usage.PromptTokensDetails.AudioTokens
 		}
-		if len(promptDetails) > 0 {
-			usageMap["prompt_tokens_details"] = promptDetails
-		}
+	} else if b.usage.CachedTokens > 0 {
+		promptDetails["cached_tokens"] = b.usage.CachedTokens
+	}
+	if len(promptDetails) > 0 {
+		usageMap["prompt_tokens_details"] = promptDetails
 	}
 
-	// Add completion_tokens_details if available
+	// Add completion_tokens_details if available; Gemini thoughtsTokenCount
+	// counts as reasoning tokens when no explicit breakdown was given
+	completionDetails := make(map[string]any)
+	if b.usage.CompletionTokensDetails == nil && b.usage.ThoughtsTokenCount > 0 {
+		completionDetails["reasoning_tokens"] = int64(b.usage.ThoughtsTokenCount)
+	}
 	if b.usage.CompletionTokensDetails != nil {
-		completionDetails := make(map[string]any)
 		if b.usage.CompletionTokensDetails.ReasoningTokens > 0 {
 			completionDetails["reasoning_tokens"] = b.usage.CompletionTokensDetails.ReasoningTokens
 		}
@@ -538,9 +545,9 @@ func (b *ResponseBuilder) BuildUsageMap() map[string]any {
 		if b.usage.CompletionTokensDetails.RejectedPredictionTokens > 0 {
 			completionDetails["rejected_prediction_tokens"] = b.usage.CompletionTokensDetails.RejectedPredictionTokens
 		}
-		if len(completionDetails) > 0 {
-			usageMap["completion_tokens_details"] = completionDetails
-		}
+	}
+	if len(completionDetails) > 0 {
+		usageMap["completion_tokens_details"] = completionDetails
 	}
 
 	return usageMap
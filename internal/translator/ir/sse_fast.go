@@ -189,12 +189,13 @@ func PutResponsesTextDelta(d *ResponsesTextDelta) {
 
 // BuildResponsesTextDeltaSSE builds an SSE event for Responses API text delta.
 // Returns []byte for consistency with other Build*SSE functions and zero-copy writes.
-func BuildResponsesTextDeltaSSE(seqNum int, itemID string, delta string) []byte {
+func BuildResponsesTextDeltaSSE(seqNum int, itemID string, outputIndex int, delta string) []byte {
 	d := GetResponsesTextDelta()
 	defer PutResponsesTextDelta(d)
 
 	d.SequenceNumber = seqNum
 	d.ItemID = itemID
+	d.OutputIndex = outputIndex
 	d.Delta = delta
 
 	jb, _ := json.Marshal(d)
@@ -234,12 +235,13 @@ func PutResponsesReasoningDelta(d *ResponsesReasoningDelta) {
 
 // BuildResponsesReasoningDeltaSSE builds an SSE event for Responses API reasoning delta.
 // Returns []byte for consistency with other Build*SSE functions and zero-copy writes.
-func BuildResponsesReasoningDeltaSSE(seqNum int, itemID string, delta string) []byte {
+func BuildResponsesReasoningDeltaSSE(seqNum int, itemID string, outputIndex int, delta string) []byte {
 	d := GetResponsesReasoningDelta()
 	defer PutResponsesReasoningDelta(d)
 
 	d.SequenceNumber = seqNum
 	d.ItemID = itemID
+	d.OutputIndex = outputIndex
 	d.Delta = delta
 
 	jb, _ := json.Marshal(d)
@@ -477,7 +479,7 @@ type OpenAIToolCallEntry struct {
 
 type OpenAIToolCallFunction struct {
 	Name      string `json:"name,omitempty"`
-	Arguments string `json:"arguments,omitempty"`
+	Arguments string `json:"arguments"`
 }
 
 type OpenAIToolCallExtraContent struct {
@@ -1106,7 +1108,7 @@ func BuildResponsesContentPartDoneSSE(seqNum int, itemID string, outputIndex, co
 	return formatResponsesSSEBytes("response.content_part.done", jb)
 }
 
-// ResponsesDoneEvent is used for response.done.
+// ResponsesDoneEvent is used for response.completed.
 type ResponsesDoneEvent struct {
 	Type           string                  `json:"type"`
 	SequenceNumber int                     `json:"sequence_number"`
@@ -1118,6 +1120,8 @@ type ResponsesDoneEventInner struct {
 	Object    string              `json:"object"`
 	CreatedAt int64               `json:"created_at"`
 	Status    string              `json:"status"`
+	Model     string              `json:"model,omitempty"`
+	Output    []any               `json:"output"`
 	Usage     *ResponsesDoneUsage `json:"usage,omitempty"`
 }
 
@@ -1127,6 +1131,7 @@ type ResponsesDoneUsage struct {
 	TotalTokens         int64                         `json:"total_tokens"`
 	InputTokensDetails  *ResponsesTokensDetails       `json:"input_tokens_details,omitempty"`
 	OutputTokensDetails *ResponsesOutputTokensDetails `json:"output_tokens_details,omitempty"`
+	Estimated           bool                          `json:"estimated,omitempty"`
 }
 
 type ResponsesTokensDetails struct {
@@ -1140,7 +1145,7 @@ type ResponsesOutputTokensDetails struct {
 var responsesDoneEventPool = sync.Pool{
 	New: func() any {
 		return &ResponsesDoneEvent{
-			Type: "response.done",
+			Type: "response.completed",
 			Response: ResponsesDoneEventInner{
 				Object: "response",
 				Status: "completed",
@@ -1157,20 +1162,28 @@ func PutResponsesDoneEvent(d *ResponsesDoneEvent) {
 	d.SequenceNumber = 0
 	d.Response.ID = ""
 	d.Response.CreatedAt = 0
+	d.Response.Model = ""
+	d.Response.Output = nil
 	d.Response.Usage = nil
 	responsesDoneEventPool.Put(d)
 }
 
-// BuildResponsesDoneSSE builds SSE for response.done.
-func BuildResponsesDoneSSE(seqNum int, respID string, createdAt int64, usage *ResponsesDoneUsage) []byte {
+// BuildResponsesCompletedSSE builds SSE for response.completed, the last event
+// of a stream. output holds the finished items in output_index order.
+func BuildResponsesCompletedSSE(seqNum int, respID string, createdAt int64, model string, output []any, usage *ResponsesDoneUsage) []byte {
 	d := GetResponsesDoneEvent()
 	defer PutResponsesDoneEvent(d)
 
 	d.SequenceNumber = seqNum
 	d.Response.ID = respID
 	d.Response.CreatedAt = createdAt
+	d.Response.Model = model
+	d.Response.Output = output
+	if d.Response.Output == nil {
+		d.Response.Output = []any{}
+	}
 	d.Response.Usage = usage
 
 	jb, _ := json.Marshal(d)
-	return formatResponsesSSEBytes("response.done", jb)
+	return formatResponsesSSEBytes("response.completed", jb)
 }
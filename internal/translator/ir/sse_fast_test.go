@@ -52,7 +52,7 @@ func TestBuildOpenAIReasoningDeltaSSE(t *testing.T) {
 }
 
 func TestBuildResponsesTextDeltaSSE(t *testing.T) {
-	result := BuildResponsesTextDeltaSSE(1, "msg_123", "Hello world")
+	result := BuildResponsesTextDeltaSSE(1, "msg_123", 0, "Hello world")
 
 	if !containsString(string(result), "event: response.output_text.delta") {
 		t.Errorf("Result doesn't contain expected event type: %s", string(result))
@@ -177,7 +177,7 @@ func BenchmarkBuildOpenAITextDeltaSSE_OldMap(b *testing.B) {
 func BenchmarkBuildResponsesTextDeltaSSE_Pooled(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		result := BuildResponsesTextDeltaSSE(i%1000, "msg_123", "Hello world token")
+		result := BuildResponsesTextDeltaSSE(i%1000, "msg_123", 0, "Hello world token")
 		_ = result
 	}
 }
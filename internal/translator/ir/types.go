@@ -22,13 +22,14 @@ const (
 	MetaOpenAILogitBias        = "openai:logit_bias"
 	MetaOpenAISeed             = "openai:seed"
 	MetaOpenAIUser             = "openai:user"
+	MetaOpenAIMetadata         = "openai:metadata"
 	MetaOpenAIFrequencyPenalty = "openai:frequency_penalty"
 	MetaOpenAIPresencePenalty  = "openai:presence_penalty"
 
 	MetaGeminiCachedContent = "gemini:cachedContent"
 	MetaGeminiLabels        = "gemini:labels"
 
-	MetaClaudeMetadata = "claude:metadata"
+	MetaClaudeMetadata = "claude:metadata" // Messages API metadata object (user_id)
 
 	// Internal flags (prefixed with _ to indicate internal use)
 	MetaForceDisableThinking = "_force_disable_thinking" // Set by translator_wrapper for non-streaming Claude via Antigravity
@@ -73,6 +74,8 @@ const (
 	FinishReasonSPII              FinishReason = "spii"               // Sensitive PII detected
 	FinishReasonImageSafety       FinishReason = "image_safety"       // Image safety issue
 	FinishReasonRecitation        FinishReason = "recitation"         // Recitation/copyright issue
+	// Set by llm-mux, never by a provider:
+	FinishReasonMinTokens FinishReason = "min_tokens" // Stopped before the requested min_tokens (OpenAI "min_tokens")
 )
 
 // ThinkingLevel represents the level of thinking tokens for thinking models.
@@ -156,9 +159,11 @@ type UnifiedEvent struct {
 	FinishReason      FinishReason
 	Refusal           string
 	Logprobs          any
-	ContentFilter     any
+	ContentFilter     any // OpenAI content_filter_results for the choice
+	PromptFilter      any // OpenAI prompt_filter_results
 	SystemFingerprint string
 	RedactedData      string
+	CandidateIndex    int // Candidate (choice) the event belongs to when n > 1
 }
 
 // ErrorMessage returns the error message safely, handling nil Error.
@@ -184,6 +189,7 @@ type Usage struct {
 	ToolUsePromptTokens      int64 // Gemini: tokens used for tool/function call context
 	PromptTokensDetails      *PromptTokensDetails
 	CompletionTokensDetails  *CompletionTokensDetails
+	Estimated                bool // Counted locally because the provider reported no usage
 }
 
 type PromptTokensDetails struct {
@@ -210,6 +216,7 @@ type OpenAIMeta struct {
 	GroundingMetadata  *GroundingMetadata // Google Search grounding metadata
 	PromptFeedback     *PromptFeedback    // Prompt-level safety feedback
 	ServiceTier        string             // OpenAI service tier used for the request
+	PromptFilter       any                // OpenAI prompt_filter_results
 }
 
 // SafetyRating represents content safety evaluation
@@ -235,6 +242,7 @@ type CandidateResult struct {
 	Logprobs          any                // Log probabilities for this candidate (OpenAI format)
 	GroundingMetadata *GroundingMetadata // Google Search grounding metadata for this candidate
 	SafetyRatings     []*SafetyRating    // Safety evaluation results
+	ContentFilter     any                // OpenAI content_filter_results for this candidate
 }
 
 // ToolCall represents a request from the model to execute a tool.
@@ -467,6 +475,7 @@ type Message struct {
 	ToolCalls    []ToolCall
 	CacheControl *CacheControl
 	Refusal      string
+	Developer    bool // System message sent with the OpenAI "developer" role
 }
 
 // ToolDefinition represents a tool capability exposed to the model.
@@ -494,6 +503,7 @@ type UnifiedChatRequest struct {
 	TopP             *float64
 	TopK             *int
 	MaxTokens        *int
+	MinTokens        *int // Minimum output tokens; best effort, see FinishReasonMinTokens
 	StopSequences    []string
 	FrequencyPenalty *float64
 	PresencePenalty  *float64
@@ -516,6 +526,7 @@ type UnifiedChatRequest struct {
 	PromptVersion        string         // Prompt template version (Responses API)
 	PromptVariables      map[string]any // Variables for prompt template (Responses API)
 	PromptCacheKey       string         // Cache key for prompt caching (Responses API)
+	Include              []string       // Additional output requested by the client (Responses API "include")
 	Store                *bool          // Whether to store the response (Responses API)
 	ParallelToolCalls    *bool          // Whether to allow parallel tool calls (Responses API)
 	ToolChoice           string         // Tool choice mode: "auto", "none", "required", "any"
@@ -531,6 +542,13 @@ type UnifiedChatRequest struct {
 	StreamOptions *StreamOptionsConfig // Stream configuration options
 }
 
+// Values of the Responses API "include" list that llm-mux produces itself
+// when translating a response from another format.
+const (
+	IncludeReasoningEncryptedContent = "reasoning.encrypted_content"
+	IncludeOutputTextLogprobs        = "message.output_text.logprobs"
+)
+
 // FunctionCallingConfig controls function calling behavior.
 type FunctionCallingConfig struct {
 	Mode                        string   // "AUTO", "ANY", "NONE"
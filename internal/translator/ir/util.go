@@ -510,6 +510,8 @@ func MapFinishReasonToOpenAI(reason FinishReason) string {
 		return "content_filter"
 	case FinishReasonError:
 		return "error"
+	case FinishReasonMinTokens:
+		return "min_tokens"
 	default:
 		return "stop"
 	}
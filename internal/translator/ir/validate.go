@@ -0,0 +1,127 @@
+package ir
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// ValidationError reports a request body field that is missing or has the
+// wrong JSON type. Its StatusCode makes handlers answer with 400.
+type ValidationError struct {
+	Path    string // JSON path of the field, e.g. messages[2].content
+	Message string // what is wrong, e.g. "must be string or array"
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid request: " + e.Path + " " + e.Message
+}
+
+// StatusCode reports the HTTP status for the error.
+func (e *ValidationError) StatusCode() int { return http.StatusBadRequest }
+
+// JSONKind is a set of JSON value types accepted for a field.
+type JSONKind uint8
+
+const (
+	KindString JSONKind = 1 << iota
+	KindNumber
+	KindBool
+	KindArray
+	KindObject
+	KindNull
+)
+
+var kindNames = []struct {
+	kind JSONKind
+	name string
+}{
+	{KindString, "string"},
+	{KindNumber, "number"},
+	{KindBool, "boolean"},
+	{KindArray, "array"},
+	{KindObject, "object"},
+	{KindNull, "null"},
+}
+
+func (k JSONKind) String() string {
+	var names []string
+	for _, n := range kindNames {
+		if k&n.kind != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func kindOf(v gjson.Result) JSONKind {
+	switch v.Type {
+	case gjson.String:
+		return KindString
+	case gjson.Number:
+		return KindNumber
+	case gjson.True, gjson.False:
+		return KindBool
+	case gjson.Null:
+		return KindNull
+	}
+	if v.IsArray() {
+		return KindArray
+	}
+	return KindObject
+}
+
+// HasKind reports whether v is missing or one of kinds. Check presence
+// separately for required fields.
+func HasKind(v gjson.Result, kinds JSONKind) bool {
+	return !v.Exists() || kindOf(v)&kinds != 0
+}
+
+// InvalidField returns the error for a field that is not one of kinds.
+func InvalidField(path string, kinds JSONKind) *ValidationError {
+	return &ValidationError{Path: path, Message: "must be " + kinds.String()}
+}
+
+// MissingField returns the error for a required field that is absent.
+func MissingField(path string) *ValidationError {
+	return &ValidationError{Path: path, Message: "is required"}
+}
+
+// CheckField validates a field whose path is known up front. A required
+// field must be present and not null.
+func CheckField(v gjson.Result, path string, kinds JSONKind, required bool) error {
+	if required && (!v.Exists() || v.Type == gjson.Null) {
+		return MissingField(path)
+	}
+	if !HasKind(v, kinds) {
+		return InvalidField(path, kinds)
+	}
+	return nil
+}
+
+// ElemPath formats the path of field inside element i of the array at path,
+// e.g. ElemPath("messages", 2, "content") is "messages[2].content". Callers
+// build paths only once a check fails to keep the happy path allocation free.
+func ElemPath(path string, i int, field string) string {
+	if field == "" {
+		return fmt.Sprintf("%s[%d]", path, i)
+	}
+	return fmt.Sprintf("%s[%d].%s", path, i, field)
+}
+
+// CheckParams validates the types of the top-level fields of root listed in
+// kinds in a single walk over its keys. Null values count as unset.
+func CheckParams(root gjson.Result, kinds map[string]JSONKind) error {
+	var err error
+	root.ForEach(func(key, value gjson.Result) bool {
+		want, ok := kinds[key.String()]
+		if ok && value.Type != gjson.Null && !HasKind(value, want) {
+			err = InvalidField(key.String(), want)
+			return false
+		}
+		return true
+	})
+	return err
+}
@@ -19,6 +19,7 @@ func Apply(req *ir.UnifiedChatRequest) error {
 	applyThinkingNormalization(req, info)
 	applyLimits(req, info)
 	applyProviderDefaults(req, info)
+	fitThinkingBudget(req, info)
 
 	return nil
 }
@@ -70,3 +70,39 @@ func normalizeThinkingBudget(req *ir.UnifiedChatRequest, info *registry.ModelInf
 	b := int32(budget)
 	req.Thinking.ThinkingBudget = &b
 }
+
+// thinkingAnswerReserve is the output kept for the answer when a thinking
+// budget shares max_tokens with it.
+const thinkingAnswerReserve = 1024
+
+// fitThinkingBudget keeps a positive thinking budget below max_tokens with
+// room for the answer, since providers reject or truncate budgets that use
+// up the output. The budget shrinks to max_tokens minus the reserve; when
+// that is below the model's minimum budget, the budget becomes the minimum
+// and max_tokens is raised to the minimum plus the reserve.
+func fitThinkingBudget(req *ir.UnifiedChatRequest, info *registry.ModelInfo) {
+	if req.Thinking == nil || req.Thinking.ThinkingBudget == nil || req.MaxTokens == nil {
+		return
+	}
+
+	budget := int(*req.Thinking.ThinkingBudget)
+	maxTokens := *req.MaxTokens
+	if budget <= 0 || maxTokens <= 0 || budget+thinkingAnswerReserve <= maxTokens {
+		return
+	}
+
+	minBudget := 1
+	if info != nil && info.Thinking != nil && info.Thinking.Min > 0 {
+		minBudget = info.Thinking.Min
+	}
+
+	if fitted := maxTokens - thinkingAnswerReserve; fitted >= minBudget {
+		budget = fitted
+	} else {
+		budget = minBudget
+		req.MaxTokens = ir.Ptr(minBudget + thinkingAnswerReserve)
+	}
+
+	b := int32(budget)
+	req.Thinking.ThinkingBudget = &b
+}
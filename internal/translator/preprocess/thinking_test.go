@@ -0,0 +1,46 @@
+package preprocess
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestFitThinkingBudget(t *testing.T) {
+	info := &registry.ModelInfo{Thinking: &registry.ThinkingSupport{Min: 1024, Max: 32768}}
+
+	tests := []struct {
+		name          string
+		budget        int32
+		maxTokens     int
+		wantBudget    int32
+		wantMaxTokens int
+	}{
+		{"room for the answer", 4096, 8192, 4096, 8192},
+		{"exactly the reserve left", 7168, 8192, 7168, 8192},
+		{"just under max_tokens", 8000, 8192, 7168, 8192},
+		{"above max_tokens", 16384, 8192, 7168, 8192},
+		{"max_tokens below minimum budget", 4096, 1500, 1024, 2048},
+		{"dynamic budget untouched", -1, 1000, -1, 1000},
+		{"disabled thinking untouched", 0, 1000, 0, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &ir.UnifiedChatRequest{
+				MaxTokens: ir.Ptr(tt.maxTokens),
+				Thinking:  &ir.ThinkingConfig{ThinkingBudget: ir.Ptr(tt.budget), IncludeThoughts: true},
+			}
+			fitThinkingBudget(req, info)
+			if *req.Thinking.ThinkingBudget != tt.wantBudget || *req.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("budget = %d, max_tokens = %d; want %d, %d", *req.Thinking.ThinkingBudget, *req.MaxTokens, tt.wantBudget, tt.wantMaxTokens)
+			}
+		})
+	}
+
+	req := &ir.UnifiedChatRequest{Thinking: &ir.ThinkingConfig{ThinkingBudget: ir.Ptr(int32(50000))}}
+	fitThinkingBudget(req, info)
+	if req.MaxTokens != nil || *req.Thinking.ThinkingBudget != 50000 {
+		t.Errorf("request without max_tokens changed: budget %d", *req.Thinking.ThinkingBudget)
+	}
+}
@@ -2,6 +2,7 @@ package to_ir
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 
 	"github.com/nghyane/llm-mux/internal/json"
@@ -16,16 +17,22 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := ir.CheckParams(parsed, claudeParamKinds); err != nil {
+		return nil, err
+	}
 
 	req := &ir.UnifiedChatRequest{
 		Model: parsed.Get("model").String(),
 	}
 
 	req.MaxTokens = ir.ExtractMaxTokens(parsed, "max_tokens")
+	req.MinTokens = ir.ExtractMinTokens(parsed)
 	req.Temperature = ir.ExtractTemperature(parsed)
 	req.TopP = ir.ExtractTopP(parsed)
 	req.TopK = ir.ExtractTopK(parsed)
 	req.StopSequences = ir.ExtractStopSequences(parsed, "stop_sequences")
+	req.Logprobs = ir.ExtractLogprobs(parsed)
+	req.TopLogprobs = ir.ExtractTopLogprobs(parsed)
 
 	if system := parsed.Get("system"); system.Exists() {
 		var text string
@@ -48,12 +55,24 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		}
 	}
 
-	for _, m := range parsed.Get("messages").Array() {
-		req.Messages = append(req.Messages, parseClaudeMessage(m))
+	for i, m := range parsed.Get("messages").Array() {
+		msg, err := parseClaudeMessage(m, i)
+		if err != nil {
+			return nil, err
+		}
+		req.Messages = append(req.Messages, msg)
 	}
 
 	req.Metadata = make(map[string]any)
-	for _, t := range parsed.Get("tools").Array() {
+	for i, t := range parsed.Get("tools").Array() {
+		if !t.IsObject() {
+			return nil, ir.InvalidField(ir.ElemPath("tools", i, ""), ir.KindObject)
+		}
+		if t.Get("input_schema").Exists() {
+			if name := t.Get("name"); name.Type != gjson.String || name.String() == "" {
+				return nil, ir.MissingField(ir.ElemPath("tools", i, "name"))
+			}
+		}
 		toolType := t.Get("type").String()
 		toolName := t.Get("name").String()
 
@@ -162,19 +181,41 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 
 	if meta := parsed.Get("metadata"); meta.IsObject() {
 		var m map[string]any
-		if err := json.Unmarshal([]byte(meta.Raw), &m); err == nil {
-			for k, v := range m {
-				req.Metadata[k] = v
-			}
+		if err := json.Unmarshal([]byte(meta.Raw), &m); err == nil && len(m) > 0 {
+			req.Metadata[ir.MetaClaudeMetadata] = m
 		}
 	}
 
 	return req, nil
 }
 
-func parseClaudeMessage(m gjson.Result) ir.Message {
+// claudeParamKinds lists the JSON types accepted for top-level Messages API
+// request fields.
+var claudeParamKinds = map[string]ir.JSONKind{
+	"model":          ir.KindString,
+	"messages":       ir.KindArray,
+	"system":         ir.KindString | ir.KindArray,
+	"tools":          ir.KindArray,
+	"max_tokens":     ir.KindNumber,
+	"temperature":    ir.KindNumber,
+	"top_p":          ir.KindNumber,
+	"top_k":          ir.KindNumber,
+	"stop_sequences": ir.KindArray,
+	"stream":         ir.KindBool,
+	"thinking":       ir.KindObject,
+	"tool_choice":    ir.KindObject,
+}
+
+func parseClaudeMessage(m gjson.Result, index int) (ir.Message, error) {
+	if !m.IsObject() {
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("messages", index, ""), ir.KindObject)
+	}
+	roleField := m.Get("role")
+	if !ir.HasKind(roleField, ir.KindString) {
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("messages", index, "role"), ir.KindString)
+	}
 	role := ir.RoleUser
-	if m.Get("role").String() == "assistant" {
+	if roleField.String() == "assistant" {
 		role = ir.RoleAssistant
 	}
 
@@ -187,10 +228,16 @@ func parseClaudeMessage(m gjson.Result) ir.Message {
 	}
 
 	content := m.Get("content")
+	if !ir.HasKind(content, ir.KindString|ir.KindArray) {
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("messages", index, "content"), ir.KindString|ir.KindArray)
+	}
 	if content.Type == gjson.String {
 		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: content.String()})
 	} else {
-		for _, block := range content.Array() {
+		for j, block := range content.Array() {
+			if !block.IsObject() || block.Get("type").Type != gjson.String {
+				return ir.Message{}, invalidContentPart(block, fmt.Sprintf("messages[%d].content[%d]", index, j))
+			}
 			switch t := block.Get("type").String(); t {
 			case "text":
 				msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: block.Get("text").String()})
@@ -282,7 +329,7 @@ func parseClaudeMessage(m gjson.Result) ir.Message {
 			msg.Role = ir.RoleTool
 		}
 	}
-	return msg
+	return msg, nil
 }
 
 func ParseClaudeResponse(rawJSON []byte) ([]ir.Message, *ir.Usage, error) {
@@ -0,0 +1,113 @@
+package to_ir
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ParseOpenAIEmbeddingRequest parses an OpenAI /v1/embeddings request body.
+// Token-array inputs are rejected since they cannot be routed across providers.
+func ParseOpenAIEmbeddingRequest(rawJSON []byte) (*ir.EmbeddingRequest, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &ir.EmbeddingRequest{
+		Model:          root.Get("model").String(),
+		EncodingFormat: root.Get("encoding_format").String(),
+		User:           root.Get("user").String(),
+	}
+	switch req.EncodingFormat {
+	case "":
+		req.EncodingFormat = ir.EmbeddingEncodingFloat
+	case ir.EmbeddingEncodingFloat, ir.EmbeddingEncodingBase64:
+	default:
+		return nil, fmt.Errorf("encoding_format must be %q or %q", ir.EmbeddingEncodingFloat, ir.EmbeddingEncodingBase64)
+	}
+
+	input := root.Get("input")
+	switch {
+	case input.Type == gjson.String:
+		req.Input = []string{input.Str}
+	case input.IsArray():
+		for _, item := range input.Array() {
+			if item.Type != gjson.String {
+				return nil, errors.New("input must be a string or an array of strings")
+			}
+			req.Input = append(req.Input, item.Str)
+		}
+	default:
+		return nil, errors.New("input must be a string or an array of strings")
+	}
+	if len(req.Input) == 0 {
+		return nil, errors.New("input must not be empty")
+	}
+
+	if dims := root.Get("dimensions"); dims.Exists() {
+		d := int(dims.Int())
+		if d <= 0 {
+			return nil, errors.New("dimensions must be a positive integer")
+		}
+		req.Dimensions = &d
+	}
+	return req, nil
+}
+
+// ParseOpenAIEmbeddingResponse parses an OpenAI embeddings response. Base64
+// encoded vectors are not accepted; upstream requests always ask for floats.
+func ParseOpenAIEmbeddingResponse(rawJSON []byte) (*ir.EmbeddingResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	data := root.Get("data").Array()
+	resp := &ir.EmbeddingResponse{
+		Model:      root.Get("model").String(),
+		Embeddings: make([][]float64, len(data)),
+	}
+	for i, item := range data {
+		idx := i
+		if v := item.Get("index"); v.Exists() {
+			idx = int(v.Int())
+		}
+		if idx < 0 || idx >= len(data) {
+			return nil, fmt.Errorf("embedding index %d out of range", idx)
+		}
+		resp.Embeddings[idx] = floatValues(item.Get("embedding"))
+	}
+	if usage := root.Get("usage"); usage.Exists() {
+		resp.Usage = &ir.Usage{
+			PromptTokens: usage.Get("prompt_tokens").Int(),
+			TotalTokens:  usage.Get("total_tokens").Int(),
+		}
+	}
+	return resp, nil
+}
+
+// ParseGeminiEmbeddingResponse parses a Gemini batchEmbedContents response.
+func ParseGeminiEmbeddingResponse(rawJSON []byte) (*ir.EmbeddingResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	items := root.Get("embeddings").Array()
+	resp := &ir.EmbeddingResponse{Embeddings: make([][]float64, 0, len(items))}
+	for _, item := range items {
+		resp.Embeddings = append(resp.Embeddings, floatValues(item.Get("values")))
+	}
+	return resp, nil
+}
+
+func floatValues(arr gjson.Result) []float64 {
+	values := arr.Array()
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = v.Float()
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package to_ir
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestParseOpenAIEmbeddingRequest_StringInput(t *testing.T) {
+	req, err := ParseOpenAIEmbeddingRequest([]byte(`{"model":"text-embedding-3-small","input":"hello"}`))
+	if err != nil {
+		t.Fatalf("ParseOpenAIEmbeddingRequest failed: %v", err)
+	}
+	if len(req.Input) != 1 || req.Input[0] != "hello" {
+		t.Errorf("Input = %v, want [hello]", req.Input)
+	}
+	if req.EncodingFormat != ir.EmbeddingEncodingFloat {
+		t.Errorf("EncodingFormat = %q, want %q", req.EncodingFormat, ir.EmbeddingEncodingFloat)
+	}
+	if req.Dimensions != nil {
+		t.Errorf("Dimensions = %v, want nil", *req.Dimensions)
+	}
+}
+
+func TestParseOpenAIEmbeddingRequest_ArrayInput(t *testing.T) {
+	req, err := ParseOpenAIEmbeddingRequest([]byte(`{"model":"m","input":["a","b"],"dimensions":256,"encoding_format":"base64"}`))
+	if err != nil {
+		t.Fatalf("ParseOpenAIEmbeddingRequest failed: %v", err)
+	}
+	if len(req.Input) != 2 || req.Input[1] != "b" {
+		t.Errorf("Input = %v, want [a b]", req.Input)
+	}
+	if req.Dimensions == nil || *req.Dimensions != 256 {
+		t.Errorf("Dimensions = %v, want 256", req.Dimensions)
+	}
+	if req.EncodingFormat != ir.EmbeddingEncodingBase64 {
+		t.Errorf("EncodingFormat = %q, want base64", req.EncodingFormat)
+	}
+}
+
+func TestParseOpenAIEmbeddingRequest_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"token array":     `{"model":"m","input":[[1,2,3]]}`,
+		"empty array":     `{"model":"m","input":[]}`,
+		"missing input":   `{"model":"m"}`,
+		"bad encoding":    `{"model":"m","input":"x","encoding_format":"int8"}`,
+		"zero dimensions": `{"model":"m","input":"x","dimensions":0}`,
+	}
+	for name, body := range cases {
+		if _, err := ParseOpenAIEmbeddingRequest([]byte(body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseOpenAIEmbeddingResponse_OrdersByIndex(t *testing.T) {
+	body := `{"model":"m","data":[{"index":1,"embedding":[0.5]},{"index":0,"embedding":[0.25,1]}],"usage":{"prompt_tokens":4,"total_tokens":4}}`
+	resp, err := ParseOpenAIEmbeddingResponse([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseOpenAIEmbeddingResponse failed: %v", err)
+	}
+	if len(resp.Embeddings) != 2 || len(resp.Embeddings[0]) != 2 || resp.Embeddings[1][0] != 0.5 {
+		t.Errorf("Embeddings = %v, want [[0.25 1] [0.5]]", resp.Embeddings)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 4 {
+		t.Errorf("Usage = %+v, want 4 prompt tokens", resp.Usage)
+	}
+}
+
+func TestParseGeminiEmbeddingResponse(t *testing.T) {
+	resp, err := ParseGeminiEmbeddingResponse([]byte(`{"embeddings":[{"values":[0.1,0.2]},{"values":[0.3]}]}`))
+	if err != nil {
+		t.Fatalf("ParseGeminiEmbeddingResponse failed: %v", err)
+	}
+	if len(resp.Embeddings) != 2 || resp.Embeddings[0][1] != 0.2 || resp.Embeddings[1][0] != 0.3 {
+		t.Errorf("Embeddings = %v, want [[0.1 0.2] [0.3]]", resp.Embeddings)
+	}
+}
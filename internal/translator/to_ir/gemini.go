@@ -1,6 +1,7 @@
 package to_ir
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -28,6 +29,9 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 	if requestWrapper := parsed.Get("request"); requestWrapper.Exists() {
 		parsed = requestWrapper
 	}
+	if err := ir.CheckParams(parsed, geminiParamKinds); err != nil {
+		return nil, err
+	}
 
 	req := &ir.UnifiedChatRequest{
 		Model: parsed.Get("model").String(),
@@ -39,6 +43,8 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		req.TopP = ir.ExtractTopP(gc, "topP")
 		req.TopK = ir.ExtractTopK(gc, "topK")
 		req.StopSequences = ir.ExtractStopSequences(gc, "stopSequences")
+		req.FrequencyPenalty = ir.ExtractFrequencyPenalty(gc, "frequencyPenalty")
+		req.PresencePenalty = ir.ExtractPresencePenalty(gc, "presencePenalty")
 
 		if tc := gc.Get("thinkingConfig"); tc.Exists() {
 			req.Thinking = &ir.ThinkingConfig{
@@ -73,8 +79,12 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		}
 	}
 
-	for _, c := range parsed.Get("contents").Array() {
-		if msg := parseGeminiContent(c); msg.Role != "" {
+	for i, c := range parsed.Get("contents").Array() {
+		msg, err := parseGeminiContent(c, i)
+		if err != nil {
+			return nil, err
+		}
+		if msg.Role != "" {
 			req.Messages = append(req.Messages, msg)
 		}
 	}
@@ -168,16 +178,38 @@ func parseGeminiSystemInstruction(si gjson.Result) string {
 	return strings.Join(texts, "\n")
 }
 
-func parseGeminiContent(c gjson.Result) ir.Message {
+// geminiParamKinds lists the JSON types accepted for top-level
+// generateContent request fields.
+var geminiParamKinds = map[string]ir.JSONKind{
+	"contents":          ir.KindArray,
+	"systemInstruction": ir.KindObject | ir.KindString,
+	"tools":             ir.KindArray,
+	"toolConfig":        ir.KindObject,
+	"generationConfig":  ir.KindObject,
+	"safetySettings":    ir.KindArray,
+}
+
+func parseGeminiContent(c gjson.Result, index int) (ir.Message, error) {
+	if !c.IsObject() {
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("contents", index, ""), ir.KindObject)
+	}
+	roleField := c.Get("role")
+	if !ir.HasKind(roleField, ir.KindString) {
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("contents", index, "role"), ir.KindString)
+	}
 	role := ir.RoleUser
-	if c.Get("role").String() == "model" {
+	if roleField.String() == "model" {
 		role = ir.RoleAssistant
 	}
 
 	msg := ir.Message{Role: role}
-	parts := c.Get("parts").Array()
+	partsField := c.Get("parts")
+	if !ir.HasKind(partsField, ir.KindArray) {
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("contents", index, "parts"), ir.KindArray)
+	}
+	parts := partsField.Array()
 	if len(parts) == 0 {
-		return msg
+		return msg, nil
 	}
 
 	type funcResponseInfo struct {
@@ -188,6 +220,9 @@ func parseGeminiContent(c gjson.Result) ir.Message {
 	var funcResponses []funcResponseInfo
 
 	for i, part := range parts {
+		if !part.IsObject() {
+			return ir.Message{}, ir.InvalidField(fmt.Sprintf("contents[%d].parts[%d]", index, i), ir.KindObject)
+		}
 		ts := ir.ExtractThoughtSignature(part)
 		text := part.Get("text").String()
 		isThought := part.Get("thought").Bool()
@@ -243,6 +278,8 @@ func parseGeminiContent(c gjson.Result) ir.Message {
 					msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeAudio, Audio: &ir.AudioPart{MimeType: mimeType, FileURI: uri}})
 				} else if strings.HasPrefix(mimeType, "video/") {
 					msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeVideo, Video: &ir.VideoPart{MimeType: mimeType, FileURI: uri}})
+				} else {
+					msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeFile, File: &ir.FilePart{FileURL: uri, MimeType: mimeType}})
 				}
 			}
 		}
@@ -276,6 +313,14 @@ func parseGeminiContent(c gjson.Result) ir.Message {
 
 	for i, fr := range funcResponses {
 		toolResult := &ir.ToolResultPart{ToolCallID: fr.id, Result: fr.response}
+		// Gemini has no error flag; a response holding only "error" is how
+		// failed tool calls are reported.
+		if resp := gjson.Parse(fr.response); resp.IsObject() && len(resp.Map()) == 1 {
+			if errVal := resp.Get("error"); errVal.Exists() {
+				toolResult.IsError = true
+				toolResult.Result = errVal.String()
+			}
+		}
 		start := fr.idx + 1
 		end := len(parts)
 		if i+1 < len(funcResponses) {
@@ -300,7 +345,7 @@ func parseGeminiContent(c gjson.Result) ir.Message {
 		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: toolResult})
 	}
 
-	return msg
+	return msg, nil
 }
 
 func ParseGeminiResponse(rawJSON []byte) (*ir.UnifiedChatRequest, []ir.Message, *ir.Usage, error) {
@@ -319,13 +364,17 @@ func ParseGeminiResponseCandidates(rawJSON []byte, schemaCtx *ir.ToolSchemaConte
 
 	candidates := parsed.Get("candidates").Array()
 	if len(candidates) == 0 {
+		if meta.PromptFeedback != nil && meta.PromptFeedback.BlockReason != "" {
+			// Blocked prompt: report an empty filtered choice.
+			return []ir.CandidateResult{{FinishReason: ir.FinishReasonContentFilter}}, usage, meta, nil
+		}
 		return nil, usage, meta, nil
 	}
 
 	var results []ir.CandidateResult
 	for i, candidate := range candidates {
 		msg := parseGeminiCandidate(candidate, schemaCtx)
-		if msg == nil {
+		if msg == nil && !candidate.Get("finishReason").Exists() {
 			continue
 		}
 
@@ -345,13 +394,19 @@ func ParseGeminiResponseCandidates(rawJSON []byte, schemaCtx *ir.ToolSchemaConte
 			groundingMeta.CitationMetadata = cm
 		}
 
+		var messages []ir.Message
+		if msg != nil {
+			messages = []ir.Message{*msg}
+		}
+		ratings := parseGeminiSafetyRatings(candidate)
 		results = append(results, ir.CandidateResult{
 			Index:             i,
-			Messages:          []ir.Message{*msg},
+			Messages:          messages,
 			FinishReason:      finishReason,
 			Logprobs:          parseGeminiLogprobs(candidate),
 			GroundingMetadata: groundingMeta,
-			SafetyRatings:     parseGeminiSafetyRatings(candidate),
+			SafetyRatings:     ratings,
+			ContentFilter:     geminiContentFilter(ratings),
 		})
 	}
 
@@ -445,8 +500,6 @@ func ParseGeminiResponseMetaWithContext(rawJSON []byte, schemaCtx *ir.ToolSchema
 		meta.GroundingMetadata = parseGroundingMetadata(gm)
 	}
 
-	meta.PromptFeedback = parsePromptFeedback(parsed)
-
 	msg := parseGeminiCandidate(candidates[0], schemaCtx)
 	if msg == nil {
 		return nil, usage, meta, nil
@@ -487,145 +540,41 @@ func ParseGeminiChunkWithStateContext(rawJSON []byte, state *ir.GeminiStreamPars
 
 	var events []ir.UnifiedEvent
 	var finishReason ir.FinishReason
-	var toolCallIndex int
 
 	usage := parseGeminiUsage(parsed)
 
 	if state != nil && state.ActualInputTokens == 0 && usage != nil && usage.PromptTokens > 0 {
 		state.ActualInputTokens = usage.PromptTokens
 	}
-
-	if candidates := parsed.Get("candidates").Array(); len(candidates) > 0 {
-		candidate := candidates[0]
-
-		for _, part := range candidate.Get("content.parts").Array() {
-			ts := ir.ExtractThoughtSignature(part)
-			isThought := part.Get("thought").Bool() || part.Get("thoughtSummary").Exists()
-			text := part.Get("text")
-			hasText := text.Exists() && text.String() != ""
-
-			// Orphan signature: thinking with signature but no text
-			// Attach to buffered thinking event and emit it
-			if isThought && len(ts) > 0 && !hasText {
-				if state != nil {
-					if completed := state.AttachSignature(ts); completed != nil {
-						events = append(events, *completed)
-					}
-				}
-				continue
-			}
-
-			if hasText {
-				if isThought {
-					thinkingEvent := &ir.UnifiedEvent{Type: ir.EventTypeReasoning, Reasoning: text.String(), ThoughtSignature: ts}
-					if state != nil && len(ts) == 0 {
-						// Buffer this thinking event - signature may come in next chunk
-						if prev := state.BufferThinkingEvent(thinkingEvent); prev != nil {
-							events = append(events, *prev)
-						}
-					} else {
-						// Has signature already, emit directly
-						events = append(events, *thinkingEvent)
-					}
-				} else {
-					// Non-thinking text: flush any buffered thinking first
-					if state != nil {
-						if pending := state.FlushPending(); pending != nil {
-							events = append(events, *pending)
-						}
-					}
-					events = append(events, ir.UnifiedEvent{Type: ir.EventTypeToken, Content: text.String(), ThoughtSignature: ts})
-				}
-			} else if fc := part.Get("functionCall"); fc.Exists() {
-				if state != nil {
-					if pending := state.FlushPending(); pending != nil {
-						events = append(events, *pending)
-					}
-				}
-				name := fc.Get("name").String()
-				if name != "" {
-					id := ensureToolCallID(fc)
-					args := fc.Get("args").Raw
-					if args == "" {
-						args = "{}"
-					}
-					if schemaCtx != nil {
-						args = schemaCtx.NormalizeToolCallArgs(name, args)
-					}
-					var partialArgs string
-					if pa := fc.Get("partialArgs"); pa.Exists() {
-						partialArgs = pa.Raw
-					}
-
-					events = append(events, ir.UnifiedEvent{
-						Type:             ir.EventTypeToolCall,
-						ToolCall:         &ir.ToolCall{ID: id, Name: name, Args: args, PartialArgs: partialArgs, ThoughtSignature: ts},
-						ToolCallIndex:    toolCallIndex,
-						ThoughtSignature: ts,
-					})
-					toolCallIndex++
-				} else if pa := fc.Get("partialArgs"); pa.Exists() {
-					events = append(events, ir.UnifiedEvent{
-						Type:          ir.EventTypeToolCallDelta,
-						ToolCall:      &ir.ToolCall{Args: pa.Raw},
-						ToolCallIndex: toolCallIndex,
-					})
-				}
-			} else if ec := part.Get("executableCode"); ec.Exists() {
-				if state != nil {
-					if pending := state.FlushPending(); pending != nil {
-						events = append(events, *pending)
-					}
-				}
-				events = append(events, ir.UnifiedEvent{
-					Type: ir.EventTypeCodeExecution,
-					CodeExecution: &ir.CodeExecutionPart{
-						Language: ir.Language(ec.Get("language").String()),
-						Code:     ec.Get("code").String(),
-					},
-					ThoughtSignature: ts,
-				})
-			} else if cer := part.Get("codeExecutionResult"); cer.Exists() {
-				if state != nil {
-					if pending := state.FlushPending(); pending != nil {
-						events = append(events, *pending)
-					}
-				}
-				events = append(events, ir.UnifiedEvent{
-					Type: ir.EventTypeCodeExecution,
-					CodeExecution: &ir.CodeExecutionPart{
-						Outcome: ir.Outcome(cer.Get("outcome").String()),
-						Output:  cer.Get("output").String(),
-					},
-					ThoughtSignature: ts,
-				})
-			}
+	if state != nil && state.ResponseID == "" {
+		if id := parsed.Get("responseId").String(); id != "" {
+			state.ResponseID, state.CreateTime = id, geminiCreateTime(parsed)
 		}
+	}
 
-		if fr := candidate.Get("finishReason"); fr.Exists() {
-			frStr := fr.String()
-			finishReason = ir.MapGeminiFinishReason(frStr)
-
-			if frStr == "MALFORMED_FUNCTION_CALL" {
-				if fm := candidate.Get("finishMessage"); fm.Exists() {
-					if funcName, argsJSON, ok := ir.ParseMalformedFunctionCall(fm.String()); ok {
-						if schemaCtx != nil {
-							argsJSON = schemaCtx.NormalizeToolCallArgs(funcName, argsJSON)
-						}
-						events = append(events, ir.UnifiedEvent{
-							Type: ir.EventTypeToolCall,
-							ToolCall: &ir.ToolCall{
-								ID:   ir.GenToolCallID(),
-								Name: funcName,
-								Args: argsJSON,
-							},
-							ToolCallIndex: toolCallIndex,
-						})
-						toolCallIndex++
-					}
-				}
+	candidates := parsed.Get("candidates").Array()
+	if len(candidates) > 0 {
+		events, finishReason = geminiCandidateEvents(candidates[0], state, schemaCtx)
+	}
+	// Further candidates (candidateCount > 1) carry their own index and
+	// finish reason; thinking signatures are only buffered for the first.
+	var others []ir.UnifiedEvent
+	for i := 1; i < len(candidates); i++ {
+		idx := int(candidates[i].Get("index").Int())
+		if idx == 0 {
+			idx = i
+		}
+		evs, fr := geminiCandidateEvents(candidates[i], nil, schemaCtx)
+		if fr != "" {
+			if fr == ir.FinishReasonStop && hasToolCallEvent(evs) {
+				fr = ir.FinishReasonToolCalls
 			}
+			evs = append(evs, ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: fr, Logprobs: parseGeminiLogprobs(candidates[i]), ContentFilter: streamContentFilter(state, candidates[i])})
 		}
+		for j := range evs {
+			evs[j].CandidateIndex = idx
+		}
+		others = append(others, evs...)
 	}
 
 	var groundingMeta *ir.GroundingMetadata
@@ -637,22 +586,45 @@ func ParseGeminiChunkWithStateContext(rawJSON []byte, state *ir.GeminiStreamPars
 		}
 	}
 
+	if finishReason == "" && usage == nil {
+		// Intermediate chunks carry the logprobs of their own tokens; the
+		// final chunk's logprobs go on the finish event below.
+		if candidates := parsed.Get("candidates").Array(); len(candidates) > 0 && candidates[0].Get("logprobsResult").Exists() {
+			for i := len(events) - 1; i >= 0; i-- {
+				if events[i].Type == ir.EventTypeToken {
+					events[i].Logprobs = parseGeminiLogprobs(candidates[0])
+					break
+				}
+			}
+		}
+	}
+
+	// The first candidate finishes last: its finish event ends the stream.
+	toolCalls := hasToolCallEvent(events)
+	events = append(events, others...)
+
+	var promptFilter any
+	if pf := parsePromptFeedback(parsed); pf != nil {
+		if pf.BlockReason != "" && finishReason == "" {
+			finishReason = ir.FinishReasonContentFilter
+		}
+		if results := ir.OpenAIPromptFilterResults(pf); results != nil && state != nil && state.ContentFilterResults {
+			promptFilter = results
+		}
+	}
+
 	if finishReason != "" || usage != nil {
 		if finishReason == "" {
 			finishReason = ir.FinishReasonStop
 		}
-		if finishReason == ir.FinishReasonStop {
-			for _, ev := range events {
-				if ev.Type == ir.EventTypeToolCall {
-					finishReason = ir.FinishReasonToolCalls
-					break
-				}
-			}
+		if finishReason == ir.FinishReasonStop && toolCalls {
+			finishReason = ir.FinishReasonToolCalls
 		}
 
-		var logprobs any
+		var logprobs, contentFilter any
 		if candidates := parsed.Get("candidates").Array(); len(candidates) > 0 {
 			logprobs = parseGeminiLogprobs(candidates[0])
+			contentFilter = streamContentFilter(state, candidates[0])
 		}
 
 		events = append(events, ir.UnifiedEvent{
@@ -661,12 +633,162 @@ func ParseGeminiChunkWithStateContext(rawJSON []byte, state *ir.GeminiStreamPars
 			FinishReason:      finishReason,
 			GroundingMetadata: groundingMeta,
 			Logprobs:          logprobs,
+			ContentFilter:     contentFilter,
+			PromptFilter:      promptFilter,
 		})
 	}
 
 	return events, nil
 }
 
+// geminiCandidateEvents converts the parts of one streamed candidate to IR
+// events and returns its mapped finish reason, if any. state may be nil.
+func geminiCandidateEvents(candidate gjson.Result, state *ir.GeminiStreamParserState, schemaCtx *ir.ToolSchemaContext) ([]ir.UnifiedEvent, ir.FinishReason) {
+	var events []ir.UnifiedEvent
+	var finishReason ir.FinishReason
+	var toolCallIndex int
+
+	for _, part := range candidate.Get("content.parts").Array() {
+		ts := ir.ExtractThoughtSignature(part)
+		isThought := part.Get("thought").Bool() || part.Get("thoughtSummary").Exists()
+		text := part.Get("text")
+		hasText := text.Exists() && text.String() != ""
+
+		// Orphan signature: thinking with signature but no text
+		// Attach to buffered thinking event and emit it
+		if isThought && len(ts) > 0 && !hasText {
+			if state != nil {
+				if completed := state.AttachSignature(ts); completed != nil {
+					events = append(events, *completed)
+				}
+			}
+			continue
+		}
+
+		if hasText {
+			if isThought {
+				thinkingEvent := &ir.UnifiedEvent{Type: ir.EventTypeReasoning, Reasoning: text.String(), ThoughtSignature: ts}
+				if state != nil && len(ts) == 0 {
+					// Buffer this thinking event - signature may come in next chunk
+					if prev := state.BufferThinkingEvent(thinkingEvent); prev != nil {
+						events = append(events, *prev)
+					}
+				} else {
+					// Has signature already, emit directly
+					events = append(events, *thinkingEvent)
+				}
+			} else {
+				// Non-thinking text: flush any buffered thinking first
+				if state != nil {
+					if pending := state.FlushPending(); pending != nil {
+						events = append(events, *pending)
+					}
+				}
+				events = append(events, ir.UnifiedEvent{Type: ir.EventTypeToken, Content: text.String(), ThoughtSignature: ts})
+			}
+		} else if fc := part.Get("functionCall"); fc.Exists() {
+			if state != nil {
+				if pending := state.FlushPending(); pending != nil {
+					events = append(events, *pending)
+				}
+			}
+			name := fc.Get("name").String()
+			if name != "" {
+				id := ensureToolCallID(fc)
+				args := fc.Get("args").Raw
+				if args == "" {
+					args = "{}"
+				}
+				if schemaCtx != nil {
+					args = schemaCtx.NormalizeToolCallArgs(name, args)
+				}
+				var partialArgs string
+				if pa := fc.Get("partialArgs"); pa.Exists() {
+					partialArgs = pa.Raw
+				}
+
+				events = append(events, ir.UnifiedEvent{
+					Type:             ir.EventTypeToolCall,
+					ToolCall:         &ir.ToolCall{ID: id, Name: name, Args: args, PartialArgs: partialArgs, ThoughtSignature: ts},
+					ToolCallIndex:    toolCallIndex,
+					ThoughtSignature: ts,
+				})
+				toolCallIndex++
+			} else if pa := fc.Get("partialArgs"); pa.Exists() {
+				events = append(events, ir.UnifiedEvent{
+					Type:          ir.EventTypeToolCallDelta,
+					ToolCall:      &ir.ToolCall{Args: pa.Raw},
+					ToolCallIndex: toolCallIndex,
+				})
+			}
+		} else if ec := part.Get("executableCode"); ec.Exists() {
+			if state != nil {
+				if pending := state.FlushPending(); pending != nil {
+					events = append(events, *pending)
+				}
+			}
+			events = append(events, ir.UnifiedEvent{
+				Type: ir.EventTypeCodeExecution,
+				CodeExecution: &ir.CodeExecutionPart{
+					Language: ir.Language(ec.Get("language").String()),
+					Code:     ec.Get("code").String(),
+				},
+				ThoughtSignature: ts,
+			})
+		} else if cer := part.Get("codeExecutionResult"); cer.Exists() {
+			if state != nil {
+				if pending := state.FlushPending(); pending != nil {
+					events = append(events, *pending)
+				}
+			}
+			events = append(events, ir.UnifiedEvent{
+				Type: ir.EventTypeCodeExecution,
+				CodeExecution: &ir.CodeExecutionPart{
+					Outcome: ir.Outcome(cer.Get("outcome").String()),
+					Output:  cer.Get("output").String(),
+				},
+				ThoughtSignature: ts,
+			})
+		}
+	}
+
+	if fr := candidate.Get("finishReason"); fr.Exists() {
+		frStr := fr.String()
+		finishReason = ir.MapGeminiFinishReason(frStr)
+
+		if frStr == "MALFORMED_FUNCTION_CALL" {
+			if fm := candidate.Get("finishMessage"); fm.Exists() {
+				if funcName, argsJSON, ok := ir.ParseMalformedFunctionCall(fm.String()); ok {
+					if schemaCtx != nil {
+						argsJSON = schemaCtx.NormalizeToolCallArgs(funcName, argsJSON)
+					}
+					events = append(events, ir.UnifiedEvent{
+						Type: ir.EventTypeToolCall,
+						ToolCall: &ir.ToolCall{
+							ID:   ir.GenToolCallID(),
+							Name: funcName,
+							Args: argsJSON,
+						},
+						ToolCallIndex: toolCallIndex,
+					})
+					toolCallIndex++
+				}
+			}
+		}
+	}
+	return events, finishReason
+}
+
+// hasToolCallEvent reports whether events contain a complete tool call.
+func hasToolCallEvent(events []ir.UnifiedEvent) bool {
+	for _, ev := range events {
+		if ev.Type == ir.EventTypeToolCall {
+			return true
+		}
+	}
+	return false
+}
+
 func parseGeminiSafetyRatings(candidate gjson.Result) []*ir.SafetyRating {
 	ratings := candidate.Get("safetyRatings").Array()
 	if len(ratings) == 0 {
@@ -684,6 +806,24 @@ func parseGeminiSafetyRatings(candidate gjson.Result) []*ir.SafetyRating {
 	return result
 }
 
+// geminiContentFilter maps safety ratings to OpenAI content_filter_results,
+// or nil when there are none.
+func geminiContentFilter(ratings []*ir.SafetyRating) any {
+	if results := ir.OpenAIContentFilterResults(ratings); results != nil {
+		return results
+	}
+	return nil
+}
+
+// streamContentFilter returns the content filter results of a streamed
+// candidate when state asks for them.
+func streamContentFilter(state *ir.GeminiStreamParserState, candidate gjson.Result) any {
+	if state == nil || !state.ContentFilterResults {
+		return nil
+	}
+	return geminiContentFilter(parseGeminiSafetyRatings(candidate))
+}
+
 func parsePromptFeedback(parsed gjson.Result) *ir.PromptFeedback {
 	pf := parsed.Get("promptFeedback")
 	if !pf.Exists() {
@@ -696,6 +836,7 @@ func parsePromptFeedback(parsed gjson.Result) *ir.PromptFeedback {
 		feedback.SafetyRatings = append(feedback.SafetyRatings, &ir.SafetyRating{
 			Category:    r.Get("category").String(),
 			Probability: r.Get("probability").String(),
+			Blocked:     r.Get("blocked").Bool(),
 		})
 	}
 	return feedback
@@ -787,16 +928,27 @@ func parseGroundingMetadata(gm gjson.Result) *ir.GroundingMetadata {
 	return meta
 }
 
-func parseGeminiMeta(parsed gjson.Result) *ir.OpenAIMeta {
-	meta := &ir.OpenAIMeta{
-		ResponseID: parsed.Get("responseId").String(),
-	}
+// geminiCreateTime returns the createTime of a response as Unix seconds, or
+// zero when it is missing or malformed.
+func geminiCreateTime(parsed gjson.Result) int64 {
 	if ct := parsed.Get("createTime").String(); ct != "" {
 		if t, err := time.Parse(time.RFC3339Nano, ct); err == nil {
-			meta.CreateTime = t.Unix()
+			return t.Unix()
 		}
 	}
+	return 0
+}
+
+func parseGeminiMeta(parsed gjson.Result) *ir.OpenAIMeta {
+	meta := &ir.OpenAIMeta{
+		ResponseID: parsed.Get("responseId").String(),
+		CreateTime: geminiCreateTime(parsed),
+	}
 	meta.ServiceTier = parsed.Get("service_tier").String()
+	meta.PromptFeedback = parsePromptFeedback(parsed)
+	if pf := ir.OpenAIPromptFilterResults(meta.PromptFeedback); pf != nil {
+		meta.PromptFilter = pf
+	}
 	if candidates := parsed.Get("candidates").Array(); len(candidates) > 0 {
 		meta.NativeFinishReason = candidates[0].Get("finishReason").String()
 		meta.Logprobs = parseGeminiLogprobs(candidates[0])
@@ -818,6 +970,7 @@ func parseGeminiUsage(parsed gjson.Result) *ir.Usage {
 	}
 
 	if tokens := u.Get("cachedContentTokenCount").Int(); tokens > 0 {
+		usage.CachedTokens = tokens
 		usage.PromptTokensDetails = &ir.PromptTokensDetails{CachedTokens: tokens}
 	}
 	if tokens := u.Get("toolUsePromptTokenCount").Int(); tokens > 0 {
@@ -139,6 +139,44 @@ func TestParseGeminiResponse_Basic(t *testing.T) {
 	}
 }
 
+func TestParseGeminiResponse_UsageDetails(t *testing.T) {
+	input := `{
+		"candidates": [{"content": {"role": "model", "parts": [{"text": "Hi"}]}, "finishReason": "STOP"}],
+		"usageMetadata": {
+			"promptTokenCount": 120,
+			"candidatesTokenCount": 8,
+			"totalTokenCount": 178,
+			"thoughtsTokenCount": 50,
+			"cachedContentTokenCount": 96
+		}
+	}`
+
+	_, messages, usage, err := ParseGeminiResponse([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseGeminiResponse failed: %v", err)
+	}
+	if usage == nil {
+		t.Fatal("Usage should not be nil")
+	}
+	if usage.ThoughtsTokenCount != 50 {
+		t.Errorf("ThoughtsTokenCount = %d, want 50", usage.ThoughtsTokenCount)
+	}
+	if usage.CachedTokens != 96 {
+		t.Errorf("CachedTokens = %d, want 96", usage.CachedTokens)
+	}
+	if usage.PromptTokensDetails == nil || usage.PromptTokensDetails.CachedTokens != 96 {
+		t.Errorf("PromptTokensDetails = %+v, want 96 cached tokens", usage.PromptTokensDetails)
+	}
+
+	um := ir.NewResponseBuilder(messages, usage, "gemini-2.5-pro", false).BuildUsageMap()
+	if got := um["prompt_tokens_details"].(map[string]any)["cached_tokens"]; got != int64(96) {
+		t.Errorf("prompt_tokens_details.cached_tokens = %v, want 96", got)
+	}
+	if got := um["completion_tokens_details"].(map[string]any)["reasoning_tokens"]; got != int64(50) {
+		t.Errorf("completion_tokens_details.reasoning_tokens = %v, want 50", got)
+	}
+}
+
 func TestParseGeminiResponse_WithThinking(t *testing.T) {
 	input := `{
 		"candidates": [{
@@ -1014,3 +1052,18 @@ func TestGeminiStreamParserState_Finalize(t *testing.T) {
 		t.Error("second finalize should return nil")
 	}
 }
+
+func TestParseGeminiChunk_LogprobsOnTokenEvent(t *testing.T) {
+	chunk := []byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"logprobsResult":{"chosenCandidates":[{"token":"Hi","logProbability":-0.2}],"topCandidates":[]}}]}`)
+	events, err := ParseGeminiChunk(chunk)
+	if err != nil {
+		t.Fatalf("ParseGeminiChunk failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != ir.EventTypeToken {
+		t.Fatalf("events = %+v, want one token event", events)
+	}
+	lp, _ := events[0].Logprobs.(map[string]any)
+	if content, _ := lp["content"].([]any); len(content) != 1 {
+		t.Errorf("token event logprobs = %v, want one entry", events[0].Logprobs)
+	}
+}
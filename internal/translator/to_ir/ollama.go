@@ -23,6 +23,7 @@ func ParseOllamaRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		req.TopP = ir.ExtractTopP(opts)
 		req.TopK = ir.ExtractTopK(opts)
 		req.MaxTokens = ir.ExtractMaxTokens(opts, "num_predict")
+		req.MinTokens = ir.ExtractMinTokens(opts)
 		req.StopSequences = ir.ExtractStopSequences(opts, "stop")
 		if v := opts.Get("seed"); v.Exists() {
 			req.Metadata["ollama_seed"] = v.Int()
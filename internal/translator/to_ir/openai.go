@@ -3,6 +3,8 @@ package to_ir
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/tidwall/gjson"
@@ -17,6 +19,9 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := ir.CheckParams(root, openAIParamKinds); err != nil {
+		return nil, err
+	}
 
 	req := &ir.UnifiedChatRequest{
 		Model:    root.Get("model").String(),
@@ -26,15 +31,34 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 	ir.ApplyCommonParams(req, root)
 	ir.ApplyOpenAIExtendedParams(req, root)
 
-	if input := root.Get("input"); input.Exists() && !root.Get("messages").Exists() {
+	messages := root.Get("messages")
+	if input := root.Get("input"); input.Exists() && !messages.Exists() {
+		if !ir.HasKind(input, ir.KindString|ir.KindArray) {
+			return nil, ir.InvalidField("input", ir.KindString|ir.KindArray)
+		}
 		parseResponsesAPIFields(root, req)
 	} else {
-		for _, m := range root.Get("messages").Array() {
-			req.Messages = append(req.Messages, parseOpenAIMessage(m))
+		for i, m := range messages.Array() {
+			msg, err := parseOpenAIMessage(m, i)
+			if err != nil {
+				return nil, err
+			}
+			req.Messages = append(req.Messages, msg)
 		}
 	}
 
-	for _, t := range root.Get("tools").Array() {
+	for i, t := range root.Get("tools").Array() {
+		if !t.IsObject() {
+			return nil, ir.InvalidField(ir.ElemPath("tools", i, ""), ir.KindObject)
+		}
+		if fn := t.Get("function"); fn.Exists() {
+			if !fn.IsObject() {
+				return nil, ir.InvalidField(ir.ElemPath("tools", i, "function"), ir.KindObject)
+			}
+			if name := fn.Get("name"); name.Type != gjson.String || name.String() == "" {
+				return nil, ir.MissingField(ir.ElemPath("tools", i, "function.name"))
+			}
+		}
 		toolType := t.Get("type").String()
 		if !t.Get("function").Exists() {
 			if strings.HasPrefix(toolType, "web_search") {
@@ -107,7 +131,7 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		}
 	}
 	if v := root.Get("prediction"); v.IsObject() && v.Get("type").String() == "content" {
-		req.Prediction = &ir.PredictionConfig{Type: "content", Content: v.Get("content").String()}
+		req.Prediction = &ir.PredictionConfig{Type: "content", Content: predictionContent(v.Get("content"))}
 	}
 	if v := root.Get("stream_options"); v.IsObject() {
 		req.StreamOptions = &ir.StreamOptionsConfig{IncludeUsage: v.Get("include_usage").Bool()}
@@ -142,6 +166,17 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 	if v := root.Get("user").String(); v != "" {
 		req.Metadata[ir.MetaOpenAIUser] = v
 	}
+	if v := root.Get("metadata"); v.IsObject() {
+		var md map[string]any
+		if json.Unmarshal([]byte(v.Raw), &md) == nil && len(md) > 0 {
+			req.Metadata[ir.MetaOpenAIMetadata] = md
+		}
+	}
+	if v := root.Get("cached_content").String(); v != "" {
+		req.Metadata[ir.MetaGeminiCachedContent] = v
+	} else if v := root.Get("extra_body.google.cached_content").String(); v != "" {
+		req.Metadata[ir.MetaGeminiCachedContent] = v
+	}
 	if v := root.Get("service_tier").String(); v != "" {
 		req.ServiceTier = ir.ServiceTier(v)
 	}
@@ -193,6 +228,12 @@ func parseResponsesAPIFields(root gjson.Result, req *ir.UnifiedChatRequest) {
 		}
 	}
 	req.PromptCacheKey = root.Get("prompt_cache_key").String()
+	for _, v := range root.Get("include").Array() {
+		req.Include = append(req.Include, v.String())
+	}
+	if slices.Contains(req.Include, ir.IncludeOutputTextLogprobs) && req.Logprobs == nil {
+		req.Logprobs = ir.Ptr(true)
+	}
 	if v := root.Get("store"); v.Exists() {
 		req.Store = ir.Ptr(v.Bool())
 	}
@@ -205,7 +246,8 @@ func parseResponsesInputItem(item gjson.Result) *ir.Message {
 	}
 	switch t {
 	case "message":
-		msg := &ir.Message{Role: ir.MapStandardRole(item.Get("role").String())}
+		role := item.Get("role").String()
+		msg := &ir.Message{Role: ir.MapStandardRole(role), Developer: role == "developer"}
 		c := item.Get("content")
 		if c.Type == gjson.String {
 			msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: c.String()})
@@ -220,7 +262,7 @@ func parseResponsesInputItem(item gjson.Result) *ir.Message {
 	case "function_call":
 		return &ir.Message{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: item.Get("call_id").String(), Name: item.Get("name").String(), Args: item.Get("arguments").String()}}}
 	case "function_call_output":
-		return &ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: item.Get("call_id").String(), Result: item.Get("output").String()}}}}
+		return &ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: item.Get("call_id").String(), Result: item.Get("output").String(), IsError: item.Get("is_error").Bool()}}}}
 	}
 	return nil
 }
@@ -351,8 +393,8 @@ func ParseOpenAIChunk(rawJSON []byte) ([]ir.UnifiedEvent, error) {
 		return parseResponsesStreamEvent(et, root)
 	}
 
-	choice := root.Get("choices.0")
-	if !choice.Exists() {
+	choices := root.Get("choices").Array()
+	if len(choices) == 0 {
 		if u := root.Get("usage"); u.Exists() {
 			usage := ir.ParseOpenAIUsage(u)
 			return []ir.UnifiedEvent{{Type: ir.EventTypeFinish, Usage: usage, SystemFingerprint: root.Get("system_fingerprint").String()}}, nil
@@ -360,6 +402,20 @@ func ParseOpenAIChunk(rawJSON []byte) ([]ir.UnifiedEvent, error) {
 		return nil, nil
 	}
 
+	var evs []ir.UnifiedEvent
+	fingerprint := root.Get("system_fingerprint").String()
+	for _, choice := range choices {
+		evs = append(evs, parseOpenAIChoiceDelta(choice, fingerprint)...)
+	}
+	if v := root.Get("prompt_filter_results"); v.Exists() && len(evs) > 0 {
+		evs[0].PromptFilter = v.Value()
+	}
+	return evs, nil
+}
+
+// parseOpenAIChoiceDelta converts one streamed choice to IR events tagged with
+// its candidate index. Requests with n > 1 interleave several choices.
+func parseOpenAIChoiceDelta(choice gjson.Result, fingerprint string) []ir.UnifiedEvent {
 	var evs []ir.UnifiedEvent
 	d := choice.Get("delta")
 	if v := d.Get("content").String(); v != "" {
@@ -379,7 +435,7 @@ func ParseOpenAIChunk(rawJSON []byte) ([]ir.UnifiedEvent, error) {
 	}
 
 	if fr := choice.Get("finish_reason").String(); fr != "" {
-		ev := ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.MapOpenAIFinishReason(fr), SystemFingerprint: root.Get("system_fingerprint").String()}
+		ev := ir.UnifiedEvent{Type: ir.EventTypeFinish, FinishReason: ir.MapOpenAIFinishReason(fr), SystemFingerprint: fingerprint}
 		if v := choice.Get("logprobs"); v.Exists() {
 			ev.Logprobs = v.Value()
 		}
@@ -388,12 +444,20 @@ func ParseOpenAIChunk(rawJSON []byte) ([]ir.UnifiedEvent, error) {
 		}
 		evs = append(evs, ev)
 	} else if len(evs) > 0 {
-		evs[0].SystemFingerprint = root.Get("system_fingerprint").String()
+		evs[0].SystemFingerprint = fingerprint
 		if v := choice.Get("logprobs"); v.Exists() {
 			evs[0].Logprobs = v.Value()
 		}
+		if v := choice.Get("content_filter_results"); v.Exists() {
+			evs[0].ContentFilter = v.Value()
+		}
 	}
-	return evs, nil
+	if idx := int(choice.Get("index").Int()); idx > 0 {
+		for i := range evs {
+			evs[i].CandidateIndex = idx
+		}
+	}
+	return evs
 }
 
 func parseResponsesStreamEvent(et string, root gjson.Result) ([]ir.UnifiedEvent, error) {
@@ -436,9 +500,37 @@ func parseResponsesStreamEvent(et string, root gjson.Result) ([]ir.UnifiedEvent,
 	return nil, nil
 }
 
-func parseOpenAIMessage(m gjson.Result) ir.Message {
-	role := m.Get("role").String()
-	msg := ir.Message{Role: ir.MapStandardRole(role)}
+// openAIParamKinds lists the JSON types accepted for top-level chat request
+// fields.
+var openAIParamKinds = map[string]ir.JSONKind{
+	"model":                 ir.KindString,
+	"messages":              ir.KindArray,
+	"tools":                 ir.KindArray,
+	"temperature":           ir.KindNumber,
+	"top_p":                 ir.KindNumber,
+	"max_tokens":            ir.KindNumber,
+	"max_output_tokens":     ir.KindNumber,
+	"n":                     ir.KindNumber,
+	"stream":                ir.KindBool,
+	"stop":                  ir.KindString | ir.KindArray,
+	"stream_options":        ir.KindObject,
+	"response_format":       ir.KindObject,
+	"max_completion_tokens": ir.KindNumber,
+}
+
+func parseOpenAIMessage(m gjson.Result, index int) (ir.Message, error) {
+	if !m.IsObject() {
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("messages", index, ""), ir.KindObject)
+	}
+	roleField := m.Get("role")
+	if roleField.Type != gjson.String {
+		if !roleField.Exists() {
+			return ir.Message{}, ir.MissingField(ir.ElemPath("messages", index, "role"))
+		}
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("messages", index, "role"), ir.KindString)
+	}
+	role := roleField.String()
+	msg := ir.Message{Role: ir.MapStandardRole(role), Developer: role == "developer"}
 	if cc := m.Get("cache_control"); cc.IsObject() {
 		msg.CacheControl = &ir.CacheControl{Type: cc.Get("type").String()}
 		if v := cc.Get("ttl"); v.Exists() {
@@ -451,10 +543,16 @@ func parseOpenAIMessage(m gjson.Result) ir.Message {
 		}
 	}
 	c := m.Get("content")
+	if !ir.HasKind(c, ir.KindString|ir.KindArray|ir.KindNull) {
+		return ir.Message{}, ir.InvalidField(ir.ElemPath("messages", index, "content"), ir.KindString|ir.KindArray)
+	}
 	if c.Type == gjson.String && role != "tool" {
 		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: c.String()})
-	} else {
-		for _, item := range c.Array() {
+	} else if c.IsArray() {
+		for j, item := range c.Array() {
+			if !item.IsObject() || item.Get("type").Type != gjson.String {
+				return ir.Message{}, invalidContentPart(item, fmt.Sprintf("messages[%d].content[%d]", index, j))
+			}
 			if p := parseOpenAIContentPart(item, &msg); p != nil {
 				msg.Content = append(msg.Content, *p)
 			}
@@ -476,9 +574,21 @@ func parseOpenAIMessage(m gjson.Result) ir.Message {
 		if id == "" {
 			id = m.Get("tool_use_id").String()
 		}
-		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: id, Result: ir.SanitizeText(extractContentString(c))}})
+		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: id, Result: ir.SanitizeText(extractContentString(c)), IsError: m.Get("is_error").Bool()}})
+	}
+	return msg, nil
+}
+
+// invalidContentPart reports a content block that is not an object with a
+// string type.
+func invalidContentPart(part gjson.Result, path string) error {
+	if !part.IsObject() {
+		return ir.InvalidField(path, ir.KindObject)
+	}
+	if !part.Get("type").Exists() {
+		return ir.MissingField(path + ".type")
 	}
-	return msg
+	return ir.InvalidField(path+".type", ir.KindString)
 }
 
 func parseOpenAIContentPart(item gjson.Result, msg *ir.Message) *ir.ContentPart {
@@ -539,7 +649,7 @@ func parseOpenAIContentPart(item gjson.Result, msg *ir.Message) *ir.ContentPart
 		msg.ToolCalls = append(msg.ToolCalls, ir.ToolCall{ID: item.Get("id").String(), Name: item.Get("name").String(), Args: args})
 	case "tool_result":
 		msg.Role = ir.RoleTool
-		return &ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: item.Get("tool_use_id").String(), Result: ir.SanitizeText(extractContentString(item.Get("content")))}}
+		return &ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: item.Get("tool_use_id").String(), Result: ir.SanitizeText(extractContentString(item.Get("content"))), IsError: item.Get("is_error").Bool()}}
 	}
 	return nil
 }
@@ -571,6 +681,21 @@ func parseOpenAITool(t gjson.Result) *ir.ToolDefinition {
 	return &ir.ToolDefinition{Name: n, Description: d, Parameters: params}
 }
 
+// predictionContent returns the predicted output, given as a string or as an
+// array of text parts.
+func predictionContent(v gjson.Result) string {
+	if !v.IsArray() {
+		return v.String()
+	}
+	var sb strings.Builder
+	for _, part := range v.Array() {
+		if part.Get("type").String() == "text" {
+			sb.WriteString(part.Get("text").String())
+		}
+	}
+	return sb.String()
+}
+
 func parseThinkingConfig(root gjson.Result) *ir.ThinkingConfig {
 	var tc *ir.ThinkingConfig
 	if re := root.Get("reasoning_effort"); re.Exists() {
@@ -604,6 +729,16 @@ func parseThinkingConfig(root gjson.Result) *ir.ThinkingConfig {
 			tc.IncludeThoughts = i.Bool()
 		}
 	}
+	// include_thoughts: true asks for reasoning even without an effort, at the
+	// model's dynamic budget. false leaves thinking as configured; the
+	// reasoning is dropped from the response instead, since Claude cannot
+	// think without returning its thoughts.
+	if include := ir.ExtractIncludeThoughts(root); include != nil && *include {
+		if tc == nil {
+			tc = &ir.ThinkingConfig{ThinkingBudget: ir.Ptr(int32(-1))}
+		}
+		tc.IncludeThoughts = true
+	}
 	return tc
 }
 
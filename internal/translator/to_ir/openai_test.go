@@ -141,6 +141,26 @@ func TestParseOpenAIRequest_Prediction_EmptyContent(t *testing.T) {
 	}
 }
 
+func TestParseOpenAIRequest_Prediction_TextParts(t *testing.T) {
+	input := `{
+		"model": "gpt-4o",
+		"messages": [{"role": "user", "content": "Hello"}],
+		"prediction": {
+			"type": "content",
+			"content": [{"type": "text", "text": "func main() {"}, {"type": "text", "text": "}"}]
+		}
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+
+	if req.Prediction == nil || req.Prediction.Content != "func main() {}" {
+		t.Errorf("Prediction = %+v, want the joined text parts", req.Prediction)
+	}
+}
+
 func TestParseOpenAIRequest_NoPrediction(t *testing.T) {
 	input := `{
 		"model": "gpt-4o",
@@ -273,6 +293,44 @@ func TestParseOpenAIRequest_ReasoningEffort(t *testing.T) {
 	}
 }
 
+func TestParseOpenAIRequest_IncludeThoughts(t *testing.T) {
+	tests := []struct {
+		name        string
+		extra       string
+		wantInclude bool
+		wantBudget  int32
+	}{
+		{"top level", `"include_thoughts": true`, true, -1},
+		{"extra_body", `"extra_body": {"include_thoughts": true}`, true, -1},
+		{"with effort", `"reasoning_effort": "low", "include_thoughts": true`, true, 1024},
+		{"false keeps effort", `"reasoning_effort": "low", "include_thoughts": false`, true, 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := `{"model": "gemini-2.5-flash", "messages": [{"role": "user", "content": "Hello"}], ` + tt.extra + `}`
+			req, err := ParseOpenAIRequest([]byte(input))
+			if err != nil {
+				t.Fatalf("ParseOpenAIRequest failed: %v", err)
+			}
+			if req.Thinking == nil || req.Thinking.ThinkingBudget == nil {
+				t.Fatalf("Thinking = %+v, want a budget", req.Thinking)
+			}
+			if req.Thinking.IncludeThoughts != tt.wantInclude || *req.Thinking.ThinkingBudget != tt.wantBudget {
+				t.Errorf("IncludeThoughts = %v, budget = %d; want %v, %d", req.Thinking.IncludeThoughts, *req.Thinking.ThinkingBudget, tt.wantInclude, tt.wantBudget)
+			}
+		})
+	}
+
+	req, err := ParseOpenAIRequest([]byte(`{"model": "gemini-2.5-flash", "messages": [{"role": "user", "content": "Hello"}], "include_thoughts": false}`))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+	if req.Thinking != nil {
+		t.Errorf("include_thoughts false configured thinking: %+v", req.Thinking)
+	}
+}
+
 // ==================== Content Part Parsing Tests ====================
 
 func TestParseOpenAIRequest_ThinkingBlock(t *testing.T) {
@@ -614,3 +672,77 @@ func TestParseOpenAIRequest_MaxCompletionTokens(t *testing.T) {
 		t.Errorf("MaxTokens = %v, want 300", req.MaxTokens)
 	}
 }
+
+func TestParseOpenAIRequest_MaxCompletionTokensPrecedence(t *testing.T) {
+	input := `{
+		"model": "o3",
+		"messages": [{"role": "user", "content": "Hello"}],
+		"max_tokens": 100,
+		"max_completion_tokens": 400
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+
+	if req.MaxTokens == nil || *req.MaxTokens != 400 {
+		t.Errorf("MaxTokens = %v, want 400", req.MaxTokens)
+	}
+}
+
+func TestParseOpenAIRequest_DeveloperRole(t *testing.T) {
+	input := `{
+		"model": "o3",
+		"messages": [
+			{"role": "developer", "content": "Answer in French."},
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": "Hello"}
+		]
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+	if len(req.Messages) != 3 {
+		t.Fatalf("got %d messages, want 3", len(req.Messages))
+	}
+	if m := req.Messages[0]; m.Role != ir.RoleSystem || !m.Developer {
+		t.Errorf("developer message = role %q developer %t, want system role marked developer", m.Role, m.Developer)
+	}
+	if m := req.Messages[1]; m.Role != ir.RoleSystem || m.Developer {
+		t.Errorf("system message = role %q developer %t", m.Role, m.Developer)
+	}
+
+	req, err = ParseOpenAIRequest([]byte(`{"model":"gpt-5","input":[{"role":"developer","content":"Be brief."},{"role":"user","content":"Hi"}]}`))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest (responses) failed: %v", err)
+	}
+	if m := req.Messages[0]; m.Role != ir.RoleSystem || !m.Developer {
+		t.Errorf("responses developer item = role %q developer %t", m.Role, m.Developer)
+	}
+}
+
+func TestParseOpenAIRequest_ResponsesInclude(t *testing.T) {
+	input := `{"model":"gpt-5","input":"Hi","include":["reasoning.encrypted_content","message.output_text.logprobs"]}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+	if len(req.Include) != 2 || req.Include[0] != ir.IncludeReasoningEncryptedContent || req.Include[1] != ir.IncludeOutputTextLogprobs {
+		t.Errorf("Include = %v", req.Include)
+	}
+	if req.Logprobs == nil || !*req.Logprobs {
+		t.Errorf("Logprobs = %v, want true when output_text logprobs are included", req.Logprobs)
+	}
+
+	req, err = ParseOpenAIRequest([]byte(`{"model":"gpt-5","input":"Hi"}`))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+	if req.Include != nil || req.Logprobs != nil {
+		t.Errorf("Include = %v, Logprobs = %v, want both unset", req.Include, req.Logprobs)
+	}
+}
@@ -0,0 +1,92 @@
+package to_ir
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ParseRerankRequest parses a Cohere/Jina style rerank request body. Documents
+// may be strings or objects with a "text" field.
+func ParseRerankRequest(rawJSON []byte) (*ir.RerankRequest, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &ir.RerankRequest{
+		Model:           root.Get("model").String(),
+		Query:           root.Get("query").String(),
+		ReturnDocuments: root.Get("return_documents").Bool(),
+	}
+	if req.Query == "" {
+		return nil, errors.New("query must be a non-empty string")
+	}
+
+	docs := root.Get("documents")
+	if !docs.IsArray() {
+		return nil, errors.New("documents must be an array")
+	}
+	for _, doc := range docs.Array() {
+		switch {
+		case doc.Type == gjson.String:
+			req.Documents = append(req.Documents, doc.Str)
+		case doc.IsObject() && doc.Get("text").Type == gjson.String:
+			req.Documents = append(req.Documents, doc.Get("text").Str)
+		default:
+			return nil, errors.New("documents must be strings or objects with a text field")
+		}
+	}
+	if len(req.Documents) == 0 {
+		return nil, errors.New("documents must not be empty")
+	}
+
+	if topN := root.Get("top_n"); topN.Exists() {
+		n := int(topN.Int())
+		if n <= 0 {
+			return nil, errors.New("top_n must be a positive integer")
+		}
+		req.TopN = &n
+	}
+	return req, nil
+}
+
+// ParseRerankResponse parses a Cohere/Jina style rerank response for a request
+// with numDocuments documents. Results are sorted by descending score.
+func ParseRerankResponse(rawJSON []byte, numDocuments int) (*ir.RerankResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	items := root.Get("results").Array()
+	resp := &ir.RerankResponse{
+		Model:   root.Get("model").String(),
+		Results: make([]ir.RerankResult, 0, len(items)),
+	}
+	for _, item := range items {
+		idx := int(item.Get("index").Int())
+		if idx < 0 || idx >= numDocuments {
+			return nil, fmt.Errorf("rerank index %d out of range", idx)
+		}
+		resp.Results = append(resp.Results, ir.RerankResult{
+			Index:          idx,
+			RelevanceScore: item.Get("relevance_score").Float(),
+		})
+	}
+	sort.SliceStable(resp.Results, func(i, j int) bool {
+		return resp.Results[i].RelevanceScore > resp.Results[j].RelevanceScore
+	})
+	if usage := root.Get("usage"); usage.Exists() {
+		total := usage.Get("total_tokens").Int()
+		prompt := usage.Get("prompt_tokens").Int()
+		if prompt == 0 {
+			prompt = total
+		}
+		resp.Usage = &ir.Usage{PromptTokens: prompt, TotalTokens: total}
+	}
+	return resp, nil
+}
@@ -0,0 +1,50 @@
+package to_ir
+
+import "testing"
+
+func TestParseRerankRequest(t *testing.T) {
+	body := `{"model":"rerank-v3.5","query":"q","documents":["a",{"text":"b"}],"top_n":1,"return_documents":true}`
+	req, err := ParseRerankRequest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseRerankRequest failed: %v", err)
+	}
+	if req.Query != "q" || len(req.Documents) != 2 || req.Documents[1] != "b" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.TopN == nil || *req.TopN != 1 || !req.ReturnDocuments {
+		t.Errorf("TopN = %v, ReturnDocuments = %v", req.TopN, req.ReturnDocuments)
+	}
+}
+
+func TestParseRerankRequest_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"missing query":   `{"model":"m","documents":["a"]}`,
+		"no documents":    `{"model":"m","query":"q","documents":[]}`,
+		"numeric doc":     `{"model":"m","query":"q","documents":[1]}`,
+		"zero top_n":      `{"model":"m","query":"q","documents":["a"],"top_n":0}`,
+		"documents field": `{"model":"m","query":"q","documents":"a"}`,
+	}
+	for name, body := range cases {
+		if _, err := ParseRerankRequest([]byte(body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseRerankResponse_SortsByScore(t *testing.T) {
+	body := `{"results":[{"index":0,"relevance_score":0.1},{"index":2,"relevance_score":0.9}],"usage":{"total_tokens":12}}`
+	resp, err := ParseRerankResponse([]byte(body), 3)
+	if err != nil {
+		t.Fatalf("ParseRerankResponse failed: %v", err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Index != 2 {
+		t.Errorf("Results = %+v, want index 2 first", resp.Results)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 12 {
+		t.Errorf("Usage = %+v, want 12 total tokens", resp.Usage)
+	}
+
+	if _, err := ParseRerankResponse([]byte(`{"results":[{"index":5,"relevance_score":1}]}`), 3); err == nil {
+		t.Error("expected an error for an out of range index")
+	}
+}
@@ -0,0 +1,66 @@
+package to_ir
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestParseRequestValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) (*ir.UnifiedChatRequest, error)
+		body  string
+		want  string
+	}{
+		{"openai messages not array", ParseOpenAIRequest, `{"model":"m","messages":{"role":"user"}}`, "invalid request: messages must be array"},
+		{"openai message not object", ParseOpenAIRequest, `{"messages":["hi"]}`, "invalid request: messages[0] must be object"},
+		{"openai missing role", ParseOpenAIRequest, `{"messages":[{"role":"user","content":"a"},{"content":"b"}]}`, "invalid request: messages[1].role is required"},
+		{"openai content number", ParseOpenAIRequest, `{"messages":[{"role":"user","content":"a"},{"role":"assistant","content":"b"},{"role":"user","content":42}]}`, "invalid request: messages[2].content must be string or array"},
+		{"openai content part without type", ParseOpenAIRequest, `{"messages":[{"role":"user","content":[{"text":"hi"}]}]}`, "invalid request: messages[0].content[0].type is required"},
+		{"openai temperature string", ParseOpenAIRequest, `{"messages":[],"temperature":"0.5"}`, "invalid request: temperature must be number"},
+		{"openai tool without name", ParseOpenAIRequest, `{"messages":[],"tools":[{"type":"function","function":{"parameters":{}}}]}`, "invalid request: tools[0].function.name is required"},
+		{"claude content object", ParseClaudeRequest, `{"messages":[{"role":"user","content":{"type":"text","text":"hi"}}]}`, "invalid request: messages[0].content must be string or array"},
+		{"claude block type number", ParseClaudeRequest, `{"messages":[{"role":"user","content":[{"type":1}]}]}`, "invalid request: messages[0].content[0].type must be string"},
+		{"claude max_tokens string", ParseClaudeRequest, `{"max_tokens":"1024","messages":[]}`, "invalid request: max_tokens must be number"},
+		{"claude system number", ParseClaudeRequest, `{"system":7,"messages":[]}`, "invalid request: system must be string or array"},
+		{"gemini contents object", ParseGeminiRequest, `{"contents":{"parts":[{"text":"hi"}]}}`, "invalid request: contents must be array"},
+		{"gemini parts string", ParseGeminiRequest, `{"contents":[{"role":"user","parts":"hi"}]}`, "invalid request: contents[0].parts must be array"},
+		{"gemini part not object", ParseGeminiRequest, `{"contents":[{"role":"user","parts":[{"text":"a"},"b"]}]}`, "invalid request: contents[0].parts[1] must be object"},
+		{"gemini generationConfig array", ParseGeminiRequest, `{"contents":[],"generationConfig":[]}`, "invalid request: generationConfig must be object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse([]byte(tt.body))
+			var verr *ir.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("error = %v, want a validation error", err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("error = %q, want %q", err.Error(), tt.want)
+			}
+			if verr.StatusCode() != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", verr.StatusCode())
+			}
+		})
+	}
+}
+
+func TestParseRequestValidationAcceptsValidBodies(t *testing.T) {
+	bodies := []struct {
+		parse func([]byte) (*ir.UnifiedChatRequest, error)
+		body  string
+	}{
+		{ParseOpenAIRequest, `{"model":"m","temperature":null,"messages":[{"role":"user","content":[{"type":"text","text":"hi"}]},{"role":"assistant","content":null,"tool_calls":[{"id":"c","type":"function","function":{"name":"f","arguments":"{}"}}]},{"role":"tool","tool_call_id":"c","content":"ok"}]}`},
+		{ParseOpenAIRequest, `{"model":"m","input":"hi"}`},
+		{ParseClaudeRequest, `{"model":"m","max_tokens":10,"system":[{"type":"text","text":"s"}],"messages":[{"role":"user","content":"hi"}]}`},
+		{ParseGeminiRequest, `{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"temperature":0.2}}`},
+	}
+	for i, b := range bodies {
+		if _, err := b.parse([]byte(b.body)); err != nil {
+			t.Errorf("body %d: unexpected error %v", i, err)
+		}
+	}
+}
@@ -35,11 +35,11 @@ var Config = struct {
 }{
 	// Connection pool settings - optimized for high concurrency
 	MaxIdleConns:        1000, // Total idle connections across all hosts
-	MaxIdleConnsPerHost: 100,  // Idle connections per host (default is 2)
+	MaxIdleConnsPerHost: 256,  // Idle connections per host (default is 2)
 	MaxConnsPerHost:     0,    // 0 = no limit, let HTTP/2 multiplex
 
 	// Timeout settings
-	IdleConnTimeout:       90 * time.Second,  // How long idle connections stay in pool
+	IdleConnTimeout:       300 * time.Second, // Keep warm connections across bursty streaming traffic
 	TLSHandshakeTimeout:   10 * time.Second,  // TLS handshake timeout
 	ExpectContinueTimeout: 1 * time.Second,   // 100-continue timeout
 	ResponseHeaderTimeout: 600 * time.Second, // 10 minutes for large context processing
@@ -91,6 +91,31 @@ var sharedTransport = sync.OnceValue(func() *http.Transport {
 	return t
 })
 
+// Apply copies the pool, timeout, and dialer settings of Config onto t.
+// It must run before t serves requests; http.Transport fields are not safe
+// to mutate while connections are in flight.
+func Apply(t *http.Transport) {
+	if t == nil {
+		return
+	}
+	t.MaxIdleConns = Config.MaxIdleConns
+	t.MaxIdleConnsPerHost = Config.MaxIdleConnsPerHost
+	t.MaxConnsPerHost = Config.MaxConnsPerHost
+	t.IdleConnTimeout = Config.IdleConnTimeout
+	t.TLSHandshakeTimeout = Config.TLSHandshakeTimeout
+	t.ResponseHeaderTimeout = Config.ResponseHeaderTimeout
+	t.DialContext = (&net.Dialer{
+		Timeout:   Config.DialTimeout,
+		KeepAlive: Config.KeepAlive,
+		DualStack: true,
+	}).DialContext
+}
+
+// ApplyShared re-applies Config to the transport behind SharedClient.
+func ApplyShared() {
+	Apply(sharedTransport())
+}
+
 var SharedClient = &http.Client{
 	Transport: sharedTransport(),
 	Timeout:   30 * time.Second,
@@ -0,0 +1,42 @@
+package usage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// apiKeyIDPrefix marks identifiers produced by APIKeyID.
+const apiKeyIDPrefix = "key-"
+
+type apiKeyIDKey struct{}
+
+// APIKeyID returns a stable identifier for a client API key: a truncated
+// SHA-256 digest, so usage can be attributed per key without the key itself
+// ever being stored or logged. Empty keys yield "".
+func APIKeyID(key string) string {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return apiKeyIDPrefix + hex.EncodeToString(sum[:6])
+}
+
+// WithAPIKeyID returns a copy of ctx carrying the caller's API key ID.
+func WithAPIKeyID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyIDKey{}, id)
+}
+
+// APIKeyIDFromContext returns the API key ID carried by ctx, or "".
+func APIKeyIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(apiKeyIDKey{}).(string)
+	return id
+}
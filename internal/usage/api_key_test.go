@@ -0,0 +1,35 @@
+package usage
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAPIKeyID(t *testing.T) {
+	const key = "sk-secret-team-key"
+	id := APIKeyID(key)
+	if id == "" || strings.Contains(id, key) || strings.Contains(id, "secret") {
+		t.Fatalf("APIKeyID(%q) = %q, want an opaque identifier", key, id)
+	}
+	if again := APIKeyID(" " + key + " "); again != id {
+		t.Errorf("APIKeyID is not stable: %q vs %q", id, again)
+	}
+	if other := APIKeyID("sk-other"); other == id {
+		t.Errorf("distinct keys share the ID %q", id)
+	}
+	if got := APIKeyID(""); got != "" {
+		t.Errorf("APIKeyID(\"\") = %q, want empty", got)
+	}
+}
+
+func TestAPIKeyIDContext(t *testing.T) {
+	ctx := context.Background()
+	if got := APIKeyIDFromContext(ctx); got != "" {
+		t.Errorf("empty context returned %q", got)
+	}
+	ctx = WithAPIKeyID(ctx, "key-abc")
+	if got := APIKeyIDFromContext(ctx); got != "key-abc" {
+		t.Errorf("APIKeyIDFromContext = %q, want key-abc", got)
+	}
+}
@@ -34,9 +34,17 @@ type Backend interface {
 	// QueryAuthStats returns per-auth-account statistics since the given time.
 	QueryAuthStats(ctx context.Context, since time.Time) ([]AuthStats, error)
 
-	// QueryModelStats returns per-model statistics since the given time.
+	// QueryModelStats returns per-model, per-provider, per-operation statistics
+	// since the given time.
 	QueryModelStats(ctx context.Context, since time.Time) ([]ModelStats, error)
 
+	// QueryOperationStats returns per-operation statistics since the given time.
+	QueryOperationStats(ctx context.Context, since time.Time) ([]OperationStats, error)
+
+	// QueryKeyStats returns per-client-API-key statistics since the given time.
+	// Requests without an authenticated key are not included.
+	QueryKeyStats(ctx context.Context, since time.Time) ([]KeyStats, error)
+
 	// Cleanup removes records older than the given time.
 	Cleanup(ctx context.Context, before time.Time) (int64, error)
 
@@ -91,6 +91,9 @@ func (p *LoggerPlugin) HandleUsage(ctx context.Context, record Record) {
 			CacheCreationInputTokens: tokens.CacheCreationInputTokens,
 			CacheReadInputTokens:     tokens.CacheReadInputTokens,
 			ToolUsePromptTokens:      tokens.ToolUsePromptTokens,
+			RequestID:                record.RequestID,
+			Operation:                usageOperation(record.Operation),
+			APIKeyID:                 record.APIKeyID,
 		})
 	}
 }
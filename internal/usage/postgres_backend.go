@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/metrics"
 )
 
 // PostgresBackend implements the Backend interface using PostgreSQL with pgx.
@@ -33,6 +34,8 @@ const (
 	pgDefaultChannelBufferSize = 1000
 )
 
+var postgresQueueLabels = metrics.L("usage_postgres")
+
 // NewPostgresBackend creates a new PostgreSQL-backed persistence layer.
 // The backend must be started with Start() before use.
 func NewPostgresBackend(dsn string, cfg BackendConfig) (*PostgresBackend, error) {
@@ -109,9 +112,16 @@ func ensurePostgresSchema(ctx context.Context, pool *pgxpool.Pool) error {
 		cache_creation_input_tokens BIGINT NOT NULL DEFAULT 0,
 		cache_read_input_tokens BIGINT NOT NULL DEFAULT 0,
 		tool_use_prompt_tokens BIGINT NOT NULL DEFAULT 0,
+		request_id TEXT NOT NULL DEFAULT '',
+		operation TEXT NOT NULL DEFAULT 'chat',
+		api_key_id TEXT NOT NULL DEFAULT '',
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 	);
 
+	ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS operation TEXT NOT NULL DEFAULT 'chat';
+	ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS api_key_id TEXT NOT NULL DEFAULT '';
+
 	CREATE INDEX IF NOT EXISTS idx_usage_requested_at ON usage_records(requested_at);
 	CREATE INDEX IF NOT EXISTS idx_usage_api_key ON usage_records(api_key);
 	CREATE INDEX IF NOT EXISTS idx_usage_provider_model ON usage_records(provider, model);
@@ -164,8 +174,10 @@ func (b *PostgresBackend) Enqueue(record UsageRecord) {
 	select {
 	case b.recordChan <- record:
 		// Successfully enqueued
+		metrics.AsyncQueueDepth.Set(float64(len(b.recordChan)), postgresQueueLabels)
 	default:
 		// Channel full, drop record with warning
+		metrics.AsyncQueueDropped.Inc(postgresQueueLabels)
 		log.Warnf("Usage persistence queue full, dropping record for %s/%s", record.Provider, record.Model)
 	}
 }
@@ -355,6 +367,7 @@ func (b *PostgresBackend) QueryModelStats(ctx context.Context, since time.Time)
 		SELECT 
 			COALESCE(NULLIF(model, ''), 'unknown') as model,
 			COALESCE(NULLIF(provider, ''), 'unknown') as provider,
+			COALESCE(NULLIF(operation, ''), 'chat') as operation,
 			COUNT(*) as requests,
 			SUM(CASE WHEN failed = false THEN 1 ELSE 0 END) as success_count,
 			SUM(CASE WHEN failed = true THEN 1 ELSE 0 END) as failure_count,
@@ -364,7 +377,7 @@ func (b *PostgresBackend) QueryModelStats(ctx context.Context, since time.Time)
 			COALESCE(SUM(total_tokens), 0) as total_tokens
 		FROM usage_records
 		WHERE requested_at >= $1
-		GROUP BY model, provider
+		GROUP BY 1, 2, 3
 		ORDER BY requests DESC
 	`, since)
 	if err != nil {
@@ -376,7 +389,7 @@ func (b *PostgresBackend) QueryModelStats(ctx context.Context, since time.Time)
 	for rows.Next() {
 		var ms ModelStats
 		if err := rows.Scan(
-			&ms.Model, &ms.Provider, &ms.Requests, &ms.SuccessCount, &ms.FailureCount,
+			&ms.Model, &ms.Provider, &ms.Operation, &ms.Requests, &ms.SuccessCount, &ms.FailureCount,
 			&ms.InputTokens, &ms.OutputTokens, &ms.ReasoningTokens, &ms.TotalTokens,
 		); err != nil {
 			return nil, err
@@ -386,6 +399,76 @@ func (b *PostgresBackend) QueryModelStats(ctx context.Context, since time.Time)
 	return results, rows.Err()
 }
 
+func (b *PostgresBackend) QueryOperationStats(ctx context.Context, since time.Time) ([]OperationStats, error) {
+	rows, err := b.pool.Query(ctx, `
+		SELECT 
+			COALESCE(NULLIF(operation, ''), 'chat') as operation,
+			COUNT(*) as requests,
+			SUM(CASE WHEN failed = false THEN 1 ELSE 0 END) as success_count,
+			SUM(CASE WHEN failed = true THEN 1 ELSE 0 END) as failure_count,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(total_tokens), 0) as total_tokens
+		FROM usage_records
+		WHERE requested_at >= $1
+		GROUP BY 1
+		ORDER BY requests DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query operation stats: %w", err)
+	}
+	defer rows.Close()
+
+	var results []OperationStats
+	for rows.Next() {
+		var op OperationStats
+		if err := rows.Scan(
+			&op.Operation, &op.Requests, &op.SuccessCount, &op.FailureCount,
+			&op.InputTokens, &op.OutputTokens, &op.TotalTokens,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, op)
+	}
+	return results, rows.Err()
+}
+
+// QueryKeyStats returns per-client-API-key statistics since the given time.
+func (b *PostgresBackend) QueryKeyStats(ctx context.Context, since time.Time) ([]KeyStats, error) {
+	rows, err := b.pool.Query(ctx, `
+		SELECT 
+			api_key_id,
+			COUNT(*) as requests,
+			SUM(CASE WHEN failed = false THEN 1 ELSE 0 END) as success_count,
+			SUM(CASE WHEN failed = true THEN 1 ELSE 0 END) as failure_count,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(reasoning_tokens), 0) as reasoning_tokens,
+			COALESCE(SUM(total_tokens), 0) as total_tokens
+		FROM usage_records
+		WHERE requested_at >= $1 AND api_key_id != ''
+		GROUP BY api_key_id
+		ORDER BY requests DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query key stats: %w", err)
+	}
+	defer rows.Close()
+
+	var results []KeyStats
+	for rows.Next() {
+		var ks KeyStats
+		if err := rows.Scan(
+			&ks.APIKeyID, &ks.Requests, &ks.SuccessCount, &ks.FailureCount,
+			&ks.InputTokens, &ks.OutputTokens, &ks.ReasoningTokens, &ks.TotalTokens,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, ks)
+	}
+	return results, rows.Err()
+}
+
 // Cleanup removes records older than the given time.
 func (b *PostgresBackend) Cleanup(ctx context.Context, before time.Time) (int64, error) {
 	result, err := b.pool.Exec(ctx, `
@@ -404,6 +487,7 @@ func (b *PostgresBackend) writeLoop() {
 	batch := make([]UsageRecord, 0, b.batchSize)
 
 	flush := func() {
+		metrics.AsyncQueueDepth.Set(float64(len(b.recordChan)), postgresQueueLabels)
 		if len(batch) == 0 {
 			return
 		}
@@ -453,7 +537,7 @@ func (b *PostgresBackend) writeBatch(ctx context.Context, records []UsageRecord)
 		"requested_at", "failed", "input_tokens", "output_tokens",
 		"reasoning_tokens", "cached_tokens", "total_tokens",
 		"audio_tokens", "cache_creation_input_tokens", "cache_read_input_tokens",
-		"tool_use_prompt_tokens",
+		"tool_use_prompt_tokens", "request_id", "operation", "api_key_id",
 	}
 
 	_, err := b.pool.CopyFrom(
@@ -480,6 +564,9 @@ func (b *PostgresBackend) writeBatch(ctx context.Context, records []UsageRecord)
 				r.CacheCreationInputTokens,
 				r.CacheReadInputTokens,
 				r.ToolUsePromptTokens,
+				r.RequestID,
+				usageOperation(r.Operation),
+				r.APIKeyID,
 			}, nil
 		}),
 	)
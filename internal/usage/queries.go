@@ -55,6 +55,31 @@ type AuthStats struct {
 type ModelStats struct {
 	Model           string `json:"model"`
 	Provider        string `json:"provider"`
+	Operation       string `json:"operation"`
+	Requests        int64  `json:"requests"`
+	SuccessCount    int64  `json:"success_count"`
+	FailureCount    int64  `json:"failure_count"`
+	InputTokens     int64  `json:"input_tokens"`
+	OutputTokens    int64  `json:"output_tokens"`
+	ReasoningTokens int64  `json:"reasoning_tokens"`
+	TotalTokens     int64  `json:"total_tokens"`
+}
+
+// OperationStats represents aggregated metrics per operation type.
+type OperationStats struct {
+	Operation    string `json:"operation"`
+	Requests     int64  `json:"requests"`
+	SuccessCount int64  `json:"success_count"`
+	FailureCount int64  `json:"failure_count"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+	TotalTokens  int64  `json:"total_tokens"`
+}
+
+// KeyStats represents aggregated metrics per client API key. APIKeyID is the
+// hashed identifier from APIKeyID, never the key itself.
+type KeyStats struct {
+	APIKeyID        string `json:"api_key_id"`
 	Requests        int64  `json:"requests"`
 	SuccessCount    int64  `json:"success_count"`
 	FailureCount    int64  `json:"failure_count"`
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/metrics"
 	_ "modernc.org/sqlite"
 )
 
@@ -37,6 +38,8 @@ const (
 	sqliteDefaultChannelBufferSize = 1000
 )
 
+var sqliteQueueLabels = metrics.L("usage_sqlite")
+
 func initSchema(db *sql.DB) error {
 	schema := `
 	CREATE TABLE IF NOT EXISTS usage_records (
@@ -58,6 +61,9 @@ func initSchema(db *sql.DB) error {
 		cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0,
 		cache_read_input_tokens INTEGER NOT NULL DEFAULT 0,
 		tool_use_prompt_tokens INTEGER NOT NULL DEFAULT 0,
+		request_id TEXT NOT NULL DEFAULT '',
+		operation TEXT NOT NULL DEFAULT 'chat',
+		api_key_id TEXT NOT NULL DEFAULT '',
 		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -79,6 +85,9 @@ func migrateSchema(db *sql.DB) error {
 		"cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0",
 		"cache_read_input_tokens INTEGER NOT NULL DEFAULT 0",
 		"tool_use_prompt_tokens INTEGER NOT NULL DEFAULT 0",
+		"request_id TEXT NOT NULL DEFAULT ''",
+		"operation TEXT NOT NULL DEFAULT 'chat'",
+		"api_key_id TEXT NOT NULL DEFAULT ''",
 	}
 
 	for _, colDef := range migrations {
@@ -207,8 +216,10 @@ func (b *SQLiteBackend) Enqueue(record UsageRecord) {
 	select {
 	case b.recordChan <- record:
 		// Successfully enqueued
+		metrics.AsyncQueueDepth.Set(float64(len(b.recordChan)), sqliteQueueLabels)
 	default:
 		// Channel full, drop record with warning
+		metrics.AsyncQueueDropped.Inc(sqliteQueueLabels)
 		log.Warnf("Usage persistence queue full, dropping record for %s/%s", record.Provider, record.Model)
 	}
 }
@@ -404,6 +415,7 @@ func (b *SQLiteBackend) QueryModelStats(ctx context.Context, since time.Time) ([
 		SELECT 
 			COALESCE(NULLIF(model, ''), 'unknown') as model,
 			COALESCE(NULLIF(provider, ''), 'unknown') as provider,
+			COALESCE(NULLIF(operation, ''), 'chat') as operation,
 			COUNT(*) as requests,
 			SUM(CASE WHEN failed = 0 THEN 1 ELSE 0 END) as success_count,
 			SUM(CASE WHEN failed = 1 THEN 1 ELSE 0 END) as failure_count,
@@ -413,7 +425,7 @@ func (b *SQLiteBackend) QueryModelStats(ctx context.Context, since time.Time) ([
 			COALESCE(SUM(total_tokens), 0) as total_tokens
 		FROM usage_records
 		WHERE requested_at >= ?
-		GROUP BY model, provider
+		GROUP BY 1, 2, 3
 		ORDER BY requests DESC
 	`, since)
 	if err != nil {
@@ -425,7 +437,7 @@ func (b *SQLiteBackend) QueryModelStats(ctx context.Context, since time.Time) ([
 	for rows.Next() {
 		var ms ModelStats
 		if err := rows.Scan(
-			&ms.Model, &ms.Provider, &ms.Requests, &ms.SuccessCount, &ms.FailureCount,
+			&ms.Model, &ms.Provider, &ms.Operation, &ms.Requests, &ms.SuccessCount, &ms.FailureCount,
 			&ms.InputTokens, &ms.OutputTokens, &ms.ReasoningTokens, &ms.TotalTokens,
 		); err != nil {
 			return nil, err
@@ -435,6 +447,76 @@ func (b *SQLiteBackend) QueryModelStats(ctx context.Context, since time.Time) ([
 	return results, rows.Err()
 }
 
+func (b *SQLiteBackend) QueryOperationStats(ctx context.Context, since time.Time) ([]OperationStats, error) {
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT 
+			COALESCE(NULLIF(operation, ''), 'chat') as operation,
+			COUNT(*) as requests,
+			SUM(CASE WHEN failed = 0 THEN 1 ELSE 0 END) as success_count,
+			SUM(CASE WHEN failed = 1 THEN 1 ELSE 0 END) as failure_count,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(total_tokens), 0) as total_tokens
+		FROM usage_records
+		WHERE requested_at >= ?
+		GROUP BY 1
+		ORDER BY requests DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query operation stats: %w", err)
+	}
+	defer rows.Close()
+
+	var results []OperationStats
+	for rows.Next() {
+		var op OperationStats
+		if err := rows.Scan(
+			&op.Operation, &op.Requests, &op.SuccessCount, &op.FailureCount,
+			&op.InputTokens, &op.OutputTokens, &op.TotalTokens,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, op)
+	}
+	return results, rows.Err()
+}
+
+// QueryKeyStats returns per-client-API-key statistics since the given time.
+func (b *SQLiteBackend) QueryKeyStats(ctx context.Context, since time.Time) ([]KeyStats, error) {
+	rows, err := b.db.QueryContext(ctx, `
+		SELECT 
+			api_key_id,
+			COUNT(*) as requests,
+			SUM(CASE WHEN failed = 0 THEN 1 ELSE 0 END) as success_count,
+			SUM(CASE WHEN failed = 1 THEN 1 ELSE 0 END) as failure_count,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(reasoning_tokens), 0) as reasoning_tokens,
+			COALESCE(SUM(total_tokens), 0) as total_tokens
+		FROM usage_records
+		WHERE requested_at >= ? AND api_key_id != ''
+		GROUP BY api_key_id
+		ORDER BY requests DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query key stats: %w", err)
+	}
+	defer rows.Close()
+
+	var results []KeyStats
+	for rows.Next() {
+		var ks KeyStats
+		if err := rows.Scan(
+			&ks.APIKeyID, &ks.Requests, &ks.SuccessCount, &ks.FailureCount,
+			&ks.InputTokens, &ks.OutputTokens, &ks.ReasoningTokens, &ks.TotalTokens,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, ks)
+	}
+	return results, rows.Err()
+}
+
 // Cleanup removes records older than the given time.
 func (b *SQLiteBackend) Cleanup(ctx context.Context, before time.Time) (int64, error) {
 	result, err := b.db.ExecContext(ctx, `
@@ -461,6 +543,7 @@ func (b *SQLiteBackend) writeLoop() {
 	batch := make([]UsageRecord, 0, b.batchSize)
 
 	flush := func() {
+		metrics.AsyncQueueDepth.Set(float64(len(b.recordChan)), sqliteQueueLabels)
 		if len(batch) == 0 {
 			return
 		}
@@ -515,8 +598,9 @@ func (b *SQLiteBackend) writeBatch(ctx context.Context, records []UsageRecord) e
 			provider, model, api_key, auth_id, auth_index, source,
 			requested_at, failed, input_tokens, output_tokens,
 			reasoning_tokens, cached_tokens, total_tokens,
-			audio_tokens, cache_creation_input_tokens, cache_read_input_tokens, tool_use_prompt_tokens
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			audio_tokens, cache_creation_input_tokens, cache_read_input_tokens, tool_use_prompt_tokens,
+			request_id, operation, api_key_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		_ = tx.Rollback()
@@ -543,6 +627,9 @@ func (b *SQLiteBackend) writeBatch(ctx context.Context, records []UsageRecord) e
 			record.CacheCreationInputTokens,
 			record.CacheReadInputTokens,
 			record.ToolUsePromptTokens,
+			record.RequestID,
+			usageOperation(record.Operation),
+			record.APIKeyID,
 		)
 		if err != nil {
 			_ = tx.Rollback()
@@ -0,0 +1,105 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteBackend_StatsSplitByOperation(t *testing.T) {
+	backend, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "usage.db"), BackendConfig{})
+	if err != nil {
+		t.Fatalf("NewSQLiteBackend failed: %v", err)
+	}
+	t.Cleanup(func() { _ = backend.db.Close() })
+
+	now := time.Now()
+	records := []UsageRecord{
+		{Provider: "claude", Model: "m", RequestedAt: now, InputTokens: 10, TotalTokens: 15, Operation: OperationChat},
+		{Provider: "claude", Model: "m", RequestedAt: now, InputTokens: 10, TotalTokens: 15},
+		{Provider: "claude", Model: "m", RequestedAt: now, Operation: OperationCountTokens},
+		{Provider: "openai", Model: "e", RequestedAt: now, InputTokens: 4, TotalTokens: 4, Operation: OperationEmbeddings},
+	}
+	ctx := context.Background()
+	if err := backend.writeBatch(ctx, records); err != nil {
+		t.Fatalf("writeBatch failed: %v", err)
+	}
+
+	ops, err := backend.QueryOperationStats(ctx, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("QueryOperationStats failed: %v", err)
+	}
+	byOp := make(map[string]OperationStats, len(ops))
+	for _, op := range ops {
+		byOp[op.Operation] = op
+	}
+	if got := byOp[OperationChat]; got.Requests != 2 || got.TotalTokens != 30 {
+		t.Errorf("chat = %+v, want 2 requests and 30 tokens", got)
+	}
+	if got := byOp[OperationCountTokens]; got.Requests != 1 || got.TotalTokens != 0 {
+		t.Errorf("count_tokens = %+v, want 1 request and no tokens", got)
+	}
+	if got := byOp[OperationEmbeddings]; got.Requests != 1 {
+		t.Errorf("embeddings = %+v, want 1 request", got)
+	}
+
+	models, err := backend.QueryModelStats(ctx, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("QueryModelStats failed: %v", err)
+	}
+	var chatRows, countRows int64
+	for _, ms := range models {
+		if ms.Model != "m" {
+			continue
+		}
+		switch ms.Operation {
+		case OperationChat:
+			chatRows += ms.Requests
+		case OperationCountTokens:
+			countRows += ms.Requests
+		}
+	}
+	if chatRows != 2 || countRows != 1 {
+		t.Errorf("model m: chat=%d count_tokens=%d, want 2 and 1", chatRows, countRows)
+	}
+}
+
+func TestSQLiteBackend_KeyStats(t *testing.T) {
+	backend, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "usage.db"), BackendConfig{})
+	if err != nil {
+		t.Fatalf("NewSQLiteBackend failed: %v", err)
+	}
+	t.Cleanup(func() { _ = backend.db.Close() })
+
+	teamA, teamB := APIKeyID("sk-team-a"), APIKeyID("sk-team-b")
+	now := time.Now()
+	records := []UsageRecord{
+		{Provider: "claude", Model: "m", RequestedAt: now, InputTokens: 10, OutputTokens: 5, TotalTokens: 15, APIKeyID: teamA},
+		{Provider: "claude", Model: "m", RequestedAt: now, Failed: true, APIKeyID: teamA},
+		{Provider: "gemini", Model: "g", RequestedAt: now, TotalTokens: 7, APIKeyID: teamB},
+		{Provider: "gemini", Model: "g", RequestedAt: now, TotalTokens: 3},
+	}
+	ctx := context.Background()
+	if err := backend.writeBatch(ctx, records); err != nil {
+		t.Fatalf("writeBatch failed: %v", err)
+	}
+
+	stats, err := backend.QueryKeyStats(ctx, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("QueryKeyStats failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d keys, want 2 (unauthenticated requests excluded): %+v", len(stats), stats)
+	}
+	byKey := make(map[string]KeyStats, len(stats))
+	for _, ks := range stats {
+		byKey[ks.APIKeyID] = ks
+	}
+	if got := byKey[teamA]; got.Requests != 2 || got.SuccessCount != 1 || got.FailureCount != 1 || got.TotalTokens != 15 || got.InputTokens != 10 {
+		t.Errorf("team A = %+v, want 2 requests, 1 failure and 15 tokens", got)
+	}
+	if got := byKey[teamB]; got.Requests != 1 || got.TotalTokens != 7 {
+		t.Errorf("team B = %+v, want 1 request and 7 tokens", got)
+	}
+}
@@ -9,6 +9,14 @@ import (
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 )
 
+// Operation types distinguish the kind of request a usage record belongs to.
+const (
+	OperationChat        = "chat"
+	OperationCountTokens = "count_tokens"
+	OperationEmbeddings  = "embeddings"
+	OperationRerank      = "rerank"
+)
+
 // Record contains the usage statistics captured for a single provider request.
 type Record struct {
 	Provider    string
@@ -19,7 +27,16 @@ type Record struct {
 	Source      string
 	RequestedAt time.Time
 	Failed      bool
-	Usage       *ir.Usage
+	// Canceled marks a stream the client abandoned; Usage then holds what was
+	// consumed before the disconnect. Canceled records are not failures.
+	Canceled bool
+	Usage    *ir.Usage
+	// RequestID is the client request ID (X-Request-ID) the usage belongs to.
+	RequestID string
+	// Operation is one of the Operation* constants; empty means OperationChat.
+	Operation string
+	// APIKeyID identifies the calling client key (see APIKeyID); never the raw key.
+	APIKeyID string
 }
 
 // UsageRecord represents a single usage record for persistence.
@@ -41,6 +58,9 @@ type UsageRecord struct {
 	CacheCreationInputTokens int64
 	CacheReadInputTokens     int64
 	ToolUsePromptTokens      int64
+	RequestID                string
+	Operation                string
+	APIKeyID                 string
 }
 
 // Plugin consumes usage records emitted by the proxy runtime.
@@ -58,6 +78,7 @@ type Manager struct {
 	once     sync.Once
 	stopOnce sync.Once
 	cancel   context.CancelFunc
+	done     chan struct{}
 
 	mu     sync.Mutex
 	cond   *sync.Cond
@@ -86,16 +107,21 @@ func (m *Manager) Start(ctx context.Context) {
 		}
 		var workerCtx context.Context
 		workerCtx, m.cancel = context.WithCancel(ctx)
+		m.done = make(chan struct{})
 		go m.run(workerCtx)
 	})
 }
 
-// Stop stops the dispatcher and drains the queue.
+// Stop stops the dispatcher and waits for it to deliver the queued records.
 func (m *Manager) Stop() {
 	if m == nil {
 		return
 	}
 	m.stopOnce.Do(func() {
+		// Claiming once also waits out a concurrent Start and keeps a late
+		// Publish from starting a dispatcher that nothing would wait for.
+		started := true
+		m.once.Do(func() { started = false })
 		if m.cancel != nil {
 			m.cancel()
 		}
@@ -103,6 +129,9 @@ func (m *Manager) Stop() {
 		m.closed = true
 		m.mu.Unlock()
 		m.cond.Broadcast()
+		if started {
+			<-m.done
+		}
 	})
 }
 
@@ -116,10 +145,28 @@ func (m *Manager) Register(plugin Plugin) {
 	m.pluginsMu.Unlock()
 }
 
+type suppressedKey struct{}
+
+// WithoutRecording returns a copy of ctx whose usage records are dropped, for
+// internal traffic such as health probes that must not count as usage.
+func WithoutRecording(ctx context.Context) context.Context {
+	return context.WithValue(ctx, suppressedKey{}, true)
+}
+
+// RecordingSuppressed reports whether ctx was marked by WithoutRecording.
+func RecordingSuppressed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	suppressed, _ := ctx.Value(suppressedKey{}).(bool)
+	return suppressed
+}
+
 // Publish enqueues a usage record for processing. If no plugin is registered
-// the record will be discarded downstream.
+// the record will be discarded downstream. Records published under a context
+// marked by WithoutRecording are dropped.
 func (m *Manager) Publish(ctx context.Context, record Record) {
-	if m == nil {
+	if m == nil || RecordingSuppressed(ctx) {
 		return
 	}
 	// ensure worker is running even if Start was not called explicitly
@@ -135,6 +182,7 @@ func (m *Manager) Publish(ctx context.Context, record Record) {
 }
 
 func (m *Manager) run(ctx context.Context) {
+	defer close(m.done)
 	for {
 		m.mu.Lock()
 		for !m.closed && len(m.queue) == 0 {
@@ -178,6 +226,14 @@ func safeInvoke(ctx context.Context, plugin Plugin, record Record) {
 
 var defaultUsageManager = NewManager(512)
 
+// usageOperation defaults an empty operation to OperationChat.
+func usageOperation(op string) string {
+	if op == "" {
+		return OperationChat
+	}
+	return op
+}
+
 // DefaultManager returns the global usage manager instance.
 func DefaultManager() *Manager { return defaultUsageManager }
 
@@ -0,0 +1,35 @@
+package usage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+type countingPlugin struct{ n atomic.Int64 }
+
+func (p *countingPlugin) HandleUsage(context.Context, Record) { p.n.Add(1) }
+
+func TestManager_StopDeliversQueuedRecords(t *testing.T) {
+	m := NewManager(0)
+	plugin := &countingPlugin{}
+	m.Register(plugin)
+	for i := 0; i < 100; i++ {
+		m.Publish(context.Background(), Record{Model: "m"})
+	}
+	m.Stop()
+	if got := plugin.n.Load(); got != 100 {
+		t.Errorf("delivered %d records before Stop returned, want 100", got)
+	}
+
+	m.Publish(context.Background(), Record{Model: "late"})
+	if got := plugin.n.Load(); got != 100 {
+		t.Errorf("record published after Stop was delivered")
+	}
+}
+
+func TestManager_StopWithoutStart(t *testing.T) {
+	m := NewManager(0)
+	m.Stop()
+	m.Publish(context.Background(), Record{})
+}
@@ -3,6 +3,7 @@ package watcher
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/nghyane/llm-mux/internal/json"
 	"os"
@@ -10,7 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/auth/atrest"
 	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/runtime/geminicli"
 )
@@ -55,6 +58,17 @@ func createProviderAuth(idGen *stableIDGenerator, providerName, label, key, base
 	return a
 }
 
+// applyClientCertAttrs records a mutual TLS client certificate pair on attrs.
+// Half-configured pairs are ignored.
+func applyClientCertAttrs(attrs map[string]string, certFile, keyFile string) {
+	certFile, keyFile = strings.TrimSpace(certFile), strings.TrimSpace(keyFile)
+	if certFile == "" || keyFile == "" {
+		return
+	}
+	attrs["tls_client_cert"] = certFile
+	attrs["tls_client_key"] = keyFile
+}
+
 // SnapshotCoreAuths converts current clients snapshot into core auth entries.
 func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 	out := make([]*provider.Auth, 0, 32)
@@ -94,6 +108,7 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 					proxy = strings.TrimSpace(prov.ProxyURL)
 				}
 				auth := createProviderAuth(idGen, pName, lbl, key, strings.TrimSpace(prov.BaseURL), proxy, prov.Headers, prov.Models, prov.ExcludedModels, cfg, now)
+				applyClientCertAttrs(auth.Attributes, prov.TLSClientCert, prov.TLSClientKey)
 				out = append(out, auth)
 			}
 		}
@@ -110,8 +125,14 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 			continue
 		}
 		full := filepath.Join(w.authDir, name)
-		data, err := os.ReadFile(full)
-		if err != nil || len(data) == 0 {
+		data, err := atrest.ReadFile(full)
+		if err != nil {
+			if errors.Is(err, atrest.ErrNoPassphrase) || errors.Is(err, atrest.ErrWrongPassphrase) {
+				log.Warnf("skipping auth file: %v", err)
+			}
+			continue
+		}
+		if len(data) == 0 {
 			continue
 		}
 		var metadata map[string]any
@@ -155,6 +176,9 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 			CreatedAt: now,
 			UpdatedAt: now,
 		}
+		certFile, _ := metadata["tls_client_cert"].(string)
+		keyFile, _ := metadata["tls_client_key"].(string)
+		applyClientCertAttrs(a.Attributes, certFile, keyFile)
 		applyAuthExcludedModelsMeta(a, cfg, nil, "oauth")
 		if prov == "gemini-cli" {
 			if virtuals := synthesizeGeminiVirtualAuths(a, metadata, now); len(virtuals) > 0 {
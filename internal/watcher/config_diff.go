@@ -43,9 +43,15 @@ func buildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.RequestRetry != newCfg.RequestRetry {
 		changes = append(changes, fmt.Sprintf("request-retry: %d -> %d", oldCfg.RequestRetry, newCfg.RequestRetry))
 	}
+	if oldCfg.RetryJitter != newCfg.RetryJitter {
+		changes = append(changes, fmt.Sprintf("retry-jitter: %d -> %d", oldCfg.RetryJitter, newCfg.RetryJitter))
+	}
 	if oldCfg.MaxRetryInterval != newCfg.MaxRetryInterval {
 		changes = append(changes, fmt.Sprintf("max-retry-interval: %d -> %d", oldCfg.MaxRetryInterval, newCfg.MaxRetryInterval))
 	}
+	if oldCfg.AccountSelection.Strategy != newCfg.AccountSelection.Strategy {
+		changes = append(changes, fmt.Sprintf("account-selection.strategy: %s -> %s", oldCfg.AccountSelection.Strategy, newCfg.AccountSelection.Strategy))
+	}
 	if oldCfg.ProxyURL != newCfg.ProxyURL {
 		changes = append(changes, fmt.Sprintf("proxy-url: %s -> %s", oldCfg.ProxyURL, newCfg.ProxyURL))
 	}
@@ -53,6 +59,68 @@ func buildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 		changes = append(changes, fmt.Sprintf("ws-auth: %t -> %t", oldCfg.WebsocketAuth, newCfg.WebsocketAuth))
 	}
 
+	if oldCfg.HealthProbe != newCfg.HealthProbe {
+		changes = append(changes, fmt.Sprintf("health-probe: enable=%t interval=%d include-suspended=%t -> enable=%t interval=%d include-suspended=%t",
+			oldCfg.HealthProbe.Enable, oldCfg.HealthProbe.Interval, oldCfg.HealthProbe.IncludeSuspended,
+			newCfg.HealthProbe.Enable, newCfg.HealthProbe.Interval, newCfg.HealthProbe.IncludeSuspended))
+	}
+	// The webhook URL may embed a token, so it is never printed
+	oldHook := strings.TrimSpace(oldCfg.StateWebhook.URL)
+	newHook := strings.TrimSpace(newCfg.StateWebhook.URL)
+	switch {
+	case oldHook == "" && newHook != "":
+		changes = append(changes, "state-webhook.url: added")
+	case oldHook != "" && newHook == "":
+		changes = append(changes, "state-webhook.url: removed")
+	case oldHook != newHook:
+		changes = append(changes, "state-webhook.url: updated")
+	}
+	if oldCfg.StateWebhook.Debounce != newCfg.StateWebhook.Debounce {
+		changes = append(changes, fmt.Sprintf("state-webhook.debounce: %d -> %d", oldCfg.StateWebhook.Debounce, newCfg.StateWebhook.Debounce))
+	}
+	if oldCfg.ImageFetch.Enable != newCfg.ImageFetch.Enable {
+		changes = append(changes, fmt.Sprintf("image-fetch.enable: %t -> %t", oldCfg.ImageFetch.Enable, newCfg.ImageFetch.Enable))
+	}
+	if oldCfg.ImageFetch.MaxBytes != newCfg.ImageFetch.MaxBytes {
+		changes = append(changes, fmt.Sprintf("image-fetch.max-bytes: %d -> %d", oldCfg.ImageFetch.MaxBytes, newCfg.ImageFetch.MaxBytes))
+	}
+	if oldCfg.ImageFetch.Timeout != newCfg.ImageFetch.Timeout {
+		changes = append(changes, fmt.Sprintf("image-fetch.timeout: %d -> %d", oldCfg.ImageFetch.Timeout, newCfg.ImageFetch.Timeout))
+	}
+	if oldCfg.ImageFetch.CacheMaxBytes != newCfg.ImageFetch.CacheMaxBytes {
+		changes = append(changes, fmt.Sprintf("image-fetch.cache-max-bytes: %d -> %d", oldCfg.ImageFetch.CacheMaxBytes, newCfg.ImageFetch.CacheMaxBytes))
+	}
+	if !reflect.DeepEqual(oldCfg.ModelInfo, newCfg.ModelInfo) {
+		changes = append(changes, fmt.Sprintf("model-info: %d -> %d entries", len(oldCfg.ModelInfo), len(newCfg.ModelInfo)))
+	}
+	if oldCfg.RequestTimeout.Default != newCfg.RequestTimeout.Default {
+		changes = append(changes, fmt.Sprintf("request-timeout.default: %d -> %d", oldCfg.RequestTimeout.Default, newCfg.RequestTimeout.Default))
+	}
+	if !reflect.DeepEqual(oldCfg.RequestTimeout.Providers, newCfg.RequestTimeout.Providers) {
+		changes = append(changes, fmt.Sprintf("request-timeout.providers: %d -> %d entries", len(oldCfg.RequestTimeout.Providers), len(newCfg.RequestTimeout.Providers)))
+	}
+	if !reflect.DeepEqual(oldCfg.UpstreamHeaders, newCfg.UpstreamHeaders) {
+		changes = append(changes, fmt.Sprintf("upstream-headers: %d -> %d providers", len(oldCfg.UpstreamHeaders), len(newCfg.UpstreamHeaders)))
+	}
+	if oldCfg.Cassette != newCfg.Cassette {
+		changes = append(changes, fmt.Sprintf("cassette.mode: %q -> %q", oldCfg.Cassette.Mode, newCfg.Cassette.Mode))
+	}
+	if oldCfg.Chaos.Enabled != newCfg.Chaos.Enabled || oldCfg.Chaos.Rate != newCfg.Chaos.Rate {
+		changes = append(changes, fmt.Sprintf("chaos: enabled %t -> %t, rate %g -> %g", oldCfg.Chaos.Enabled, newCfg.Chaos.Enabled, oldCfg.Chaos.Rate, newCfg.Chaos.Rate))
+	}
+	if oldCfg.CoalesceRequests != newCfg.CoalesceRequests {
+		changes = append(changes, fmt.Sprintf("coalesce-requests: %t -> %t", oldCfg.CoalesceRequests, newCfg.CoalesceRequests))
+	}
+	if oldCfg.Idempotency != newCfg.Idempotency {
+		changes = append(changes, fmt.Sprintf("idempotency: disable %t -> %t, ttl %d -> %d, max-entries %d -> %d", oldCfg.Idempotency.Disable, newCfg.Idempotency.Disable, oldCfg.Idempotency.TTL, newCfg.Idempotency.TTL, oldCfg.Idempotency.MaxEntries, newCfg.Idempotency.MaxEntries))
+	}
+	if oldCfg.ToolLoopGuard.MaxRounds != newCfg.ToolLoopGuard.MaxRounds {
+		changes = append(changes, fmt.Sprintf("tool-loop-guard.max-rounds: %d -> %d", oldCfg.ToolLoopGuard.MaxRounds, newCfg.ToolLoopGuard.MaxRounds))
+	}
+	if oldCfg.ThoughtSignatures != newCfg.ThoughtSignatures {
+		changes = append(changes, fmt.Sprintf("thought-signatures: enabled %t -> %t, ttl %d -> %d", oldCfg.ThoughtSignatures.Enabled, newCfg.ThoughtSignatures.Enabled, oldCfg.ThoughtSignatures.TTL, newCfg.ThoughtSignatures.TTL))
+	}
+
 	// Quota-exceeded behavior
 	if oldCfg.QuotaExceeded.SwitchProject != newCfg.QuotaExceeded.SwitchProject {
 		changes = append(changes, fmt.Sprintf("quota-exceeded.switch-project: %t -> %t", oldCfg.QuotaExceeded.SwitchProject, newCfg.QuotaExceeded.SwitchProject))
@@ -111,3 +179,46 @@ func buildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 
 	return changes
 }
+
+// restartRequiredChanges lists the changed settings that are only read at
+// startup, so a live reload cannot apply them.
+func restartRequiredChanges(oldCfg, newCfg *config.Config) []string {
+	if oldCfg == nil || newCfg == nil {
+		return nil
+	}
+	var fields []string
+	if oldCfg.Port != newCfg.Port {
+		fields = append(fields, "port")
+	}
+	if oldCfg.TLS != newCfg.TLS {
+		fields = append(fields, "tls")
+	}
+	if oldCfg.MaxRequestSize != newCfg.MaxRequestSize {
+		fields = append(fields, "max-request-size")
+	}
+	if !reflect.DeepEqual(oldCfg.Usage, newCfg.Usage) {
+		fields = append(fields, "usage")
+	}
+	if oldCfg.Transport != newCfg.Transport {
+		fields = append(fields, "transport")
+	}
+	if !reflect.DeepEqual(oldCfg.Tracing, newCfg.Tracing) {
+		fields = append(fields, "tracing")
+	}
+	if oldCfg.Metrics != newCfg.Metrics {
+		fields = append(fields, "metrics")
+	}
+	if oldCfg.Batch != newCfg.Batch {
+		fields = append(fields, "batch")
+	}
+	if oldCfg.Files != newCfg.Files {
+		fields = append(fields, "files")
+	}
+	if oldCfg.AuthEncryption != newCfg.AuthEncryption {
+		fields = append(fields, "auth-encryption")
+	}
+	if !reflect.DeepEqual(oldCfg.OAuthCallback, newCfg.OAuthCallback) {
+		fields = append(fields, "oauth-callback")
+	}
+	return fields
+}
@@ -0,0 +1,29 @@
+package watcher
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestRestartRequiredChanges(t *testing.T) {
+	oldCfg := &config.Config{Port: 8317, RequestRetry: 1}
+	oldCfg.Tracing.Headers = map[string]string{"a": "1"}
+
+	live := *oldCfg
+	live.RequestRetry = 3
+	live.RateLimit.GlobalRPS = 10
+	if got := restartRequiredChanges(oldCfg, &live); len(got) != 0 {
+		t.Errorf("live-reloadable edits reported as restart-only: %v", got)
+	}
+
+	restart := *oldCfg
+	restart.Port = 9000
+	restart.Usage.DSN = "sqlite://usage.db"
+	restart.Tracing.Headers = map[string]string{"a": "2"}
+	want := []string{"port", "usage", "tracing"}
+	if got := restartRequiredChanges(oldCfg, &restart); !reflect.DeepEqual(got, want) {
+		t.Errorf("restartRequiredChanges = %v, want %v", got, want)
+	}
+}
@@ -123,6 +123,10 @@ func (w *Watcher) reloadConfig() bool {
 		}
 	}
 
+	if restart := restartRequiredChanges(oldConfig, newConfig); len(restart) > 0 {
+		log.Warnf("config changes to %s take effect after a restart", strings.Join(restart, ", "))
+	}
+
 	authDirChanged := oldConfig == nil || oldConfig.AuthDir != newConfig.AuthDir
 
 	log.Infof("config successfully reloaded, triggering client reload")
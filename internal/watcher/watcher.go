@@ -67,6 +67,8 @@ type Watcher struct {
 	storePersister    storePersister
 	mirroredAuthDir   string
 	oldConfigYaml     []byte
+	// ignoreConfig disables config file watching; the config is then only read at startup.
+	ignoreConfig bool
 	// pendingWrites tracks files being written by the application itself.
 	// Key: absolute file path, Value: expiry time for the pending write marker.
 	// This prevents the watcher from reacting to its own writes.
@@ -165,10 +167,16 @@ func NewWatcher(configPath, authDir string, reloadCallback func(*config.Config))
 
 // Start begins watching the configuration file and authentication directory
 func (w *Watcher) Start(ctx context.Context) error {
-	// Watch the config file only if it exists (zero-config mode support)
-	if _, err := os.Stat(w.configPath); err == nil {
-		if errAddConfig := w.watcher.Add(w.configPath); errAddConfig != nil {
-			log.Errorf("failed to watch config file %s: %v", w.configPath, errAddConfig)
+	// Watch the config file only if it exists (zero-config mode support).
+	// The parent directory is watched rather than the file itself, so editors
+	// that save by writing a temp file and renaming it over the config keep
+	// being picked up after the original inode is gone.
+	if w.ignoreConfig {
+		log.Infof("config file watching disabled; changes to %s apply on restart", w.configPath)
+	} else if _, err := os.Stat(w.configPath); err == nil {
+		configDir := filepath.Dir(w.configPath)
+		if errAddConfig := w.watcher.Add(configDir); errAddConfig != nil {
+			log.Errorf("failed to watch config directory %s: %v", configDir, errAddConfig)
 			return errAddConfig
 		}
 		log.Debugf("watching config file: %s", w.configPath)
@@ -202,6 +210,12 @@ func (w *Watcher) Stop() error {
 	return w.watcher.Close()
 }
 
+// DisableConfigWatch stops the watcher from reloading the config file when it
+// changes. It must be called before Start; the auth directory is still watched.
+func (w *Watcher) DisableConfigWatch() {
+	w.ignoreConfig = true
+}
+
 // SetConfig updates the current configuration
 func (w *Watcher) SetConfig(cfg *config.Config) {
 	w.clientsMutex.Lock()
@@ -262,7 +276,7 @@ func (w *Watcher) isKnownAuthFile(path string) bool {
 func (w *Watcher) handleEvent(event fsnotify.Event) {
 	// Filter only relevant events: config file or auth-dir JSON files.
 	configOps := fsnotify.Write | fsnotify.Create | fsnotify.Rename
-	isConfigEvent := event.Name == w.configPath && event.Op&configOps != 0
+	isConfigEvent := !w.ignoreConfig && filepath.Clean(event.Name) == filepath.Clean(w.configPath) && event.Op&configOps != 0
 	authOps := fsnotify.Create | fsnotify.Write | fsnotify.Remove | fsnotify.Rename
 	isAuthJSON := strings.HasPrefix(event.Name, w.authDir) && strings.HasSuffix(event.Name, ".json") && event.Op&authOps != 0
 	if !isConfigEvent && !isAuthJSON {
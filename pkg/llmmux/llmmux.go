@@ -8,6 +8,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/access"
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/metrics"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/service"
 )
@@ -27,6 +28,10 @@ type Config = config.Config
 // Auth represents a single credential entry.
 type Auth = provider.Auth
 
+// Result describes one upstream call or response cache hit, as delivered to
+// Hooks.OnResult.
+type Result = provider.Result
+
 // Manager orchestrates auth lifecycle, selection, execution, and persistence.
 type Manager = provider.Manager
 
@@ -39,6 +44,21 @@ type LoginOptions = login.LoginOptions
 // AccessManager handles API key validation for incoming requests.
 type AccessManager = access.Manager
 
+// Metrics is the backend internal metrics are emitted through.
+type Metrics = metrics.Metrics
+
+// MetricLabels holds label values for a metric sample.
+type MetricLabels = metrics.Labels
+
+// MetricDesc describes a metric to a Metrics backend.
+type MetricDesc = metrics.Desc
+
+// SetMetrics installs a metrics backend such as a StatsD or OpenTelemetry
+// adapter. It takes precedence over the built-in Prometheus backend.
+func SetMetrics(m Metrics) {
+	metrics.Set(m)
+}
+
 // NewBuilder creates a new service builder with default dependencies.
 func NewBuilder() *Builder {
 	return service.NewBuilder()